@@ -6,6 +6,7 @@ package main
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/goki/gi/gi"
 	"github.com/goki/gi/gimain"
@@ -71,7 +72,7 @@ func mainrun() {
 
 	oswin.TheApp.SetName("svg")
 	oswin.TheApp.SetAbout(`This is a demo of the SVG rendering (and start on editing) in the <b>GoGi</b> graphical interface system, within the <b>GoKi</b> tree framework.  See <a href="https://github.com/goki">GoKi on GitHub</a>
-<p>You can drag the image around and use the scroll wheel to zoom.</p>`)
+<p>In Select mode, click or drag a box to select elements, and drag them or their handles to move, resize and rotate them.  In Nodes mode, drag the nodes of a selected path to edit it.  In Pan mode, you can drag the image around.  Use the scroll wheel to zoom.</p>`)
 
 	win := gi.NewWindow2D("gogi-svg-viewer", "GoGi SVG Viewer", width, height, true)
 
@@ -94,6 +95,8 @@ func mainrun() {
 	svge := svgrow.AddNewChild(svg.KiT_Editor, "svg").(*svg.Editor)
 	TheSVG = svge
 	svge.InitScale()
+	svge.Mode = svg.EditSelect
+	svge.Snap = true
 	svge.Fill = true
 	svge.SetProp("background-color", "white")
 	svge.SetProp("width", units.NewValue(float32(width-20), units.Px))
@@ -105,6 +108,23 @@ func mainrun() {
 	loads.SetText("Open SVG")
 	loads.StartFocus()
 
+	modes := []svg.EditorModes{svg.EditSelect, svg.EditNodes, svg.EditPan}
+	for _, md := range modes {
+		mode := md
+		ma := tbar.AddNewChild(gi.KiT_Action, mode.String()).(*gi.Action)
+		ma.SetText(strings.TrimPrefix(mode.String(), "Edit"))
+		ma.ActionSig.Connect(win.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			TheSVG.SetMode(mode)
+		})
+	}
+
+	undo := tbar.AddNewChild(gi.KiT_Action, "undo").(*gi.Action)
+	undo.SetText("Undo")
+	undo.Tooltip = "undo the last edit"
+	redo := tbar.AddNewChild(gi.KiT_Action, "redo").(*gi.Action)
+	redo.SetText("Redo")
+	redo.Tooltip = "redo the last undone edit"
+
 	fnm := tbar.AddNewChild(gi.KiT_TextField, "cur-fname").(*gi.TextField)
 	TheFile = fnm
 	fnm.SetMinPrefWidth(units.NewValue(60, units.Ch))
@@ -159,6 +179,14 @@ func mainrun() {
 		FileViewOpenSVG(vp)
 	})
 
+	undo.ActionSig.Connect(win.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		TheSVG.Undo()
+	})
+
+	redo.ActionSig.Connect(win.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		TheSVG.Redo()
+	})
+
 	fnm.TextFieldSig.Connect(win.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
 		if sig == int64(gi.TextFieldDone) {
 			tf := send.(*gi.TextField)
@@ -202,6 +230,15 @@ func mainrun() {
 
 	emen := win.MainMenu.ChildByName("Edit", 1).(*gi.Action)
 	emen.Menu = make(gi.Menu, 0, 10)
+	emen.Menu.AddAction(gi.ActOpts{Label: "Undo", Shortcut: "Command+Z"},
+		win.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			TheSVG.Undo()
+		})
+	emen.Menu.AddAction(gi.ActOpts{Label: "Redo", Shortcut: "Shift+Command+Z"},
+		win.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			TheSVG.Redo()
+		})
+	emen.Menu.AddSeparator("undosep")
 	emen.Menu.AddCopyCutPaste(win)
 
 	// note: Command in shortcuts is automatically translated into Control for
@@ -0,0 +1,487 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gi
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unsafe"
+
+	"github.com/goki/gi/units"
+	"github.com/goki/ki"
+)
+
+// AnimFPS is the rate at which animations are updated, in frames per second
+var AnimFPS = 60
+
+////////////////////////////////////////////////////////////////////////////////////////
+//  Easing
+
+// Easing is a timing function for animations and transitions, mapping the
+// proportion of the duration elapsed, from 0 to 1, to the proportion of the
+// change made at that point, which starts at 0 and ends at 1
+type Easing func(t float32) float32
+
+// EaseLinear makes changes at a constant rate
+func EaseLinear(t float32) float32 {
+	return t
+}
+
+var (
+	// Ease starts changes quickly and ends them slowly -- the CSS default
+	Ease = CubicBezierEasing(0.25, 0.1, 0.25, 1)
+
+	// EaseIn starts changes slowly
+	EaseIn = CubicBezierEasing(0.42, 0, 1, 1)
+
+	// EaseOut ends changes slowly
+	EaseOut = CubicBezierEasing(0, 0, 0.58, 1)
+
+	// EaseInOut starts and ends changes slowly
+	EaseInOut = CubicBezierEasing(0.42, 0, 0.58, 1)
+)
+
+// CubicBezierEasing returns the easing of given CSS cubic-bezier(x1, y1, x2,
+// y2) curve, from (0, 0) to (1, 1) with those control points
+func CubicBezierEasing(x1, y1, x2, y2 float32) Easing {
+	bez := func(a, b, t float32) float32 {
+		mt := 1 - t
+		return 3*mt*mt*t*a + 3*mt*t*t*b + t*t*t
+	}
+	return func(t float32) float32 {
+		if t <= 0 || t >= 1 {
+			return t
+		}
+		// solve for the curve parameter at which x = t, by bisection
+		lo, hi := float32(0), float32(1)
+		u := t
+		for i := 0; i < 20; i++ {
+			x := bez(x1, x2, u)
+			if math.Abs(float64(x-t)) < 1.0e-5 {
+				break
+			}
+			if x < t {
+				lo = u
+			} else {
+				hi = u
+			}
+			u = 0.5 * (lo + hi)
+		}
+		return bez(y1, y2, u)
+	}
+}
+
+// StepsEasing returns the easing of CSS steps(n), which makes changes in n
+// equal jumps, at the end of each of n equal intervals
+func StepsEasing(n int) Easing {
+	if n < 1 {
+		n = 1
+	}
+	return func(t float32) float32 {
+		if t >= 1 {
+			return 1
+		}
+		return float32(math.Floor(float64(t)*float64(n))) / float32(n)
+	}
+}
+
+// EasingFor returns the easing of given CSS name: linear, ease, ease-in,
+// ease-out, ease-in-out, cubic-bezier(x1, y1, x2, y2) or steps(n) -- nil
+// if not recognized
+func EasingFor(name string) Easing {
+	name = strings.ToLower(strings.TrimSpace(name))
+	switch name {
+	case "linear":
+		return EaseLinear
+	case "ease":
+		return Ease
+	case "ease-in":
+		return EaseIn
+	case "ease-out":
+		return EaseOut
+	case "ease-in-out":
+		return EaseInOut
+	}
+	if !strings.HasSuffix(name, ")") {
+		return nil
+	}
+	switch {
+	case strings.HasPrefix(name, "cubic-bezier("):
+		args := strings.Split(name[len("cubic-bezier("):len(name)-1], ",")
+		if len(args) != 4 {
+			return nil
+		}
+		var vals [4]float32
+		for i, a := range args {
+			v, err := strconv.ParseFloat(strings.TrimSpace(a), 32)
+			if err != nil {
+				return nil
+			}
+			vals[i] = float32(v)
+		}
+		return CubicBezierEasing(vals[0], vals[1], vals[2], vals[3])
+	case strings.HasPrefix(name, "steps("):
+		n, err := strconv.Atoi(strings.TrimSpace(name[len("steps(") : len(name)-1]))
+		if err != nil {
+			return nil
+		}
+		return StepsEasing(n)
+	}
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////////////
+//  Anim
+
+// Anim is an animation of a node, which is stepped on the event loop of its
+// window on each tick of its animation ticker, from its start until its
+// duration has elapsed -- see Window.StartAnim and Animate
+type Anim struct {
+	Node  Node2D          `desc:"node being animated -- the animation stops if it is deleted"`
+	Prop  string          `desc:"what is being animated on the node -- starting an animation stops any other one of the node with the same Prop"`
+	Start time.Time       `desc:"time at which the animation starts"`
+	Dur   time.Duration   `desc:"duration of the animation"`
+	Ease  Easing          `desc:"easing function applied to the progress of the animation -- linear if nil"`
+	Step  func(t float32) `desc:"function called on each frame with the eased progress of the animation, from 0 to 1, and called with 1 on the last frame"`
+	Done  func()          `desc:"optional function called after the last frame, unless the animation is stopped before then"`
+	win   *Window
+	stop  bool
+}
+
+// Progress returns the proportion of the duration of the animation elapsed
+// at given time, from 0 to 1
+func (an *Anim) Progress(now time.Time) float32 {
+	if an.Dur <= 0 {
+		return 1
+	}
+	t := float32(now.Sub(an.Start)) / float32(an.Dur)
+	return InRange32(t, 0, 1)
+}
+
+// Stop stops the animation, leaving the node as it is
+func (an *Anim) Stop() {
+	if an.win == nil {
+		return
+	}
+	an.win.AnimMu.Lock()
+	an.stop = true
+	an.win.AnimMu.Unlock()
+}
+
+// step steps the animation at given time, returning false when it is over
+func (an *Anim) step(now time.Time) bool {
+	nb := an.Node.AsNode2D()
+	if nb == nil || nb.IsDeleted() || nb.IsDestroyed() {
+		return false
+	}
+	if now.Before(an.Start) {
+		return true
+	}
+	t := an.Progress(now)
+	e := t
+	if an.Ease != nil && t < 1 {
+		e = an.Ease(t)
+	}
+	an.Step(e)
+	return t < 1
+}
+
+// StartAnim starts given animation in the window, stopping any other
+// animation of the same node and Prop, and starting the animation ticker of
+// the window if it is not running
+func (w *Window) StartAnim(an *Anim) {
+	w.AnimMu.Lock()
+	for _, oa := range w.Anims {
+		if oa.Node == an.Node && oa.Prop == an.Prop {
+			oa.stop = true
+		}
+	}
+	an.win = w
+	an.stop = false
+	w.Anims = append(w.Anims, an)
+	if w.animTicker == nil {
+		w.animTicker = time.NewTicker(time.Second / time.Duration(AnimFPS))
+		go w.AnimTick(w.animTicker)
+	}
+	w.AnimMu.Unlock()
+}
+
+// StopAnims stops all the animations of given node in the window
+func (w *Window) StopAnims(node Node2D) {
+	w.AnimMu.Lock()
+	for _, an := range w.Anims {
+		if an.Node == node {
+			an.stop = true
+		}
+	}
+	w.AnimMu.Unlock()
+}
+
+// AnimTick is the function that sends the window an event to step its
+// animations on each tick of given ticker, until there are none left -- the
+// animations are stepped on the event loop of the window, by StepAnims, and
+// ticks are skipped while the last one is still waiting to be handled
+func (w *Window) AnimTick(tick *time.Ticker) {
+	for range tick.C {
+		w.AnimMu.Lock()
+		if len(w.Anims) == 0 || w.IsClosed() {
+			tick.Stop()
+			w.animTicker = nil
+			w.Anims = nil
+			w.animPending = false
+			w.AnimMu.Unlock()
+			return
+		}
+		if w.animPending {
+			w.AnimMu.Unlock()
+			continue
+		}
+		w.animPending = true
+		w.AnimMu.Unlock()
+		w.PostFunc(func() {
+			w.StepAnims(time.Now())
+		})
+	}
+}
+
+// StepAnims steps the animations of the window at given time, removing those
+// that are over -- must only be called on the event loop of the window, as
+// AnimTick does
+func (w *Window) StepAnims(now time.Time) {
+	w.AnimMu.Lock()
+	w.animPending = false
+	if len(w.Anims) == 0 || w.IsResizing() || w.IsUpdating() {
+		w.AnimMu.Unlock()
+		return
+	}
+	anims := make([]*Anim, len(w.Anims))
+	copy(anims, w.Anims)
+	w.AnimMu.Unlock()
+	var over, done []*Anim
+	for _, an := range anims {
+		w.AnimMu.Lock()
+		stop := an.stop
+		w.AnimMu.Unlock()
+		switch {
+		case stop:
+			over = append(over, an)
+		case !an.step(now):
+			over = append(over, an)
+			done = append(done, an)
+		}
+	}
+	if len(over) == 0 {
+		return
+	}
+	w.AnimMu.Lock()
+	for _, an := range over {
+		for i, oa := range w.Anims {
+			if oa == an {
+				w.Anims = append(w.Anims[:i], w.Anims[i+1:]...)
+				break
+			}
+		}
+	}
+	w.AnimMu.Unlock()
+	for _, an := range done {
+		if an.Done != nil {
+			an.Done()
+		}
+	}
+}
+
+// Animate animates property prop of node from its current value to given
+// value, over given duration, with given easing function (Ease if nil), and
+// returns the Anim, or nil with an error if the property cannot be
+// animated.  prop is either the name of a field of the node, of type
+// float32, float64, int, []float32, []float64, units.Value, Color, ColorSpec
+// or Vec2D (e.g., "Splits" of a SplitView), or the name of a style property
+// (e.g., "max-height" or "background-color"), which is animated from its
+// current style value by setting it in the Props of the node.  The node is
+// fully re-rendered on each frame, so that changes in layout are applied.
+func Animate(node Node2D, prop string, to interface{}, dur time.Duration, easing Easing) (*Anim, error) {
+	nb := node.AsNode2D()
+	win := nb.ParentWindow()
+	if win == nil {
+		return nil, fmt.Errorf("gi.Animate: node %v is not in a window", nb.PathUnique())
+	}
+	if easing == nil {
+		easing = Ease
+	}
+	an := &Anim{Node: node, Prop: prop, Start: time.Now(), Dur: dur, Ease: easing}
+	cur := reflect.ValueOf(node).Elem().FieldByName(prop)
+	if cur.IsValid() && cur.CanSet() {
+		from := reflect.New(cur.Type())
+		from.Elem().Set(cur)
+		if sl, ok := from.Interface().(*[]float32); ok {
+			*sl = append([]float32(nil), *sl...)
+		}
+		if sl, ok := from.Interface().(*[]float64); ok {
+			*sl = append([]float64(nil), *sl...)
+		}
+		tov := reflect.New(cur.Type())
+		if err := animSetValue(tov.Interface(), to, nb.Viewport); err != nil {
+			return nil, fmt.Errorf("gi.Animate: field %v of node %v: %v", prop, nb.PathUnique(), err)
+		}
+		fi, ti := from.Interface(), tov.Interface()
+		ci := cur.Addr().Interface()
+		an.Step = func(t float32) {
+			updt := node.UpdateStart()
+			lerpValue(ci, fi, ti, t)
+			nb.SetFullReRender()
+			node.UpdateEnd(updt)
+		}
+		win.StartAnim(an)
+		return an, nil
+	}
+	wb := node.AsWidget()
+	fld, ok := StyleFields.Fields[prop]
+	if wb == nil || !ok {
+		return nil, fmt.Errorf("gi.Animate: node %v has no field or style property named: %v", nb.PathUnique(), prop)
+	}
+	fsty := wb.Sty
+	tsty := wb.Sty
+	StyleFields.Style(&tsty, nil, ki.Props{prop: to}, wb.Viewport)
+	fi := fld.FieldIface(uintptr(unsafe.Pointer(&fsty)))
+	ti := fld.FieldIface(uintptr(unsafe.Pointer(&tsty)))
+	if uv, ok := ti.(*units.Value); ok {
+		uv.ToDots(&wb.Sty.UnContext)
+	}
+	csty := wb.Sty
+	ci := fld.FieldIface(uintptr(unsafe.Pointer(&csty)))
+	if !lerpValue(ci, fi, ti, 0) {
+		return nil, fmt.Errorf("gi.Animate: style property %v of type %v cannot be animated", prop, fld.Field.Type)
+	}
+	an.Step = func(t float32) {
+		lerpValue(ci, fi, ti, t)
+		updt := node.UpdateStart()
+		switch cv := ci.(type) {
+		case *units.Value:
+			node.SetProp(prop, units.NewValue(cv.Dots, units.Dot))
+		case *ColorSpec:
+			node.SetProp(prop, cv.Color)
+		default:
+			node.SetProp(prop, reflect.ValueOf(ci).Elem().Interface())
+		}
+		nb.SetFullReRender()
+		node.UpdateEnd(updt)
+	}
+	win.StartAnim(an)
+	return an, nil
+}
+
+// animSetValue sets the value that val points to from given value, for the
+// types supported by Animate
+func animSetValue(val, from interface{}, vp *Viewport2D) error {
+	switch v := val.(type) {
+	case *units.Value:
+		return v.SetIFace(from)
+	case *Color:
+		return animSetColor(v, from, vp)
+	case *ColorSpec:
+		v.Source = SolidColor
+		return animSetColor(&v.Color, from, vp)
+	}
+	fv := reflect.ValueOf(from)
+	vv := reflect.ValueOf(val).Elem()
+	if !fv.IsValid() || !fv.Type().ConvertibleTo(vv.Type()) {
+		return fmt.Errorf("cannot set value of type %v from: %v", vv.Type(), from)
+	}
+	vv.Set(fv.Convert(vv.Type()))
+	return nil
+}
+
+// animSetColor sets given color from a color value or string
+func animSetColor(clr *Color, from interface{}, vp *Viewport2D) error {
+	switch fv := from.(type) {
+	case string:
+		return clr.SetStringStyle(fv, nil, vp)
+	case *Color:
+		*clr = *fv
+	case color.Color:
+		clr.SetColor(fv)
+	default:
+		return fmt.Errorf("cannot set Color from: %v type: %T", from, from)
+	}
+	return nil
+}
+
+// lerpValue sets the value that cur points to, to the value a proportion t
+// of the way from that of from to that of to, which must point to the same
+// type as cur: float32, float64, int, []float32, []float64, units.Value,
+// Color, ColorSpec or Vec2D -- returns false for other types
+func lerpValue(cur, from, to interface{}, t float32) bool {
+	lerp := func(a, b float32) float32 {
+		return a + t*(b-a)
+	}
+	switch cv := cur.(type) {
+	case *float32:
+		*cv = lerp(*from.(*float32), *to.(*float32))
+	case *float64:
+		fv, tv := *from.(*float64), *to.(*float64)
+		*cv = fv + float64(t)*(tv-fv)
+	case *int:
+		*cv = int(math.Round(float64(lerp(float32(*from.(*int)), float32(*to.(*int))))))
+	case *[]float32:
+		fv, tv := *from.(*[]float32), *to.(*[]float32)
+		nv := make([]float32, len(tv))
+		for i := range tv {
+			nv[i] = tv[i]
+			if i < len(fv) {
+				nv[i] = lerp(fv[i], tv[i])
+			}
+		}
+		*cv = nv
+	case *[]float64:
+		fv, tv := *from.(*[]float64), *to.(*[]float64)
+		nv := make([]float64, len(tv))
+		for i := range tv {
+			nv[i] = tv[i]
+			if i < len(fv) {
+				nv[i] = fv[i] + float64(t)*(tv[i]-fv[i])
+			}
+		}
+		*cv = nv
+	case *units.Value:
+		fv, tv := from.(*units.Value), to.(*units.Value)
+		*cv = *tv
+		cv.Dots = lerp(fv.Dots, tv.Dots)
+		if fv.Un == tv.Un && fv.Calc == nil && tv.Calc == nil {
+			cv.Val = lerp(fv.Val, tv.Val)
+		} else {
+			cv.Set(cv.Dots, units.Dot)
+		}
+	case *Color:
+		lerpColor(cv, from.(*Color), to.(*Color), t)
+	case *ColorSpec:
+		fv, tv := from.(*ColorSpec), to.(*ColorSpec)
+		*cv = *tv
+		if fv.Source == SolidColor && tv.Source == SolidColor {
+			lerpColor(&cv.Color, &fv.Color, &tv.Color, t)
+		}
+	case *Vec2D:
+		fv, tv := from.(*Vec2D), to.(*Vec2D)
+		*cv = Vec2D{lerp(fv.X, tv.X), lerp(fv.Y, tv.Y)}
+	default:
+		return false
+	}
+	return true
+}
+
+// lerpColor sets cur to the color a proportion t of the way from from to to
+func lerpColor(cur, from, to *Color, t float32) {
+	lerp := func(a, b uint8) uint8 {
+		return uint8(math.Round(float64(float32(a) + t*(float32(b)-float32(a)))))
+	}
+	cur.R = lerp(from.R, to.R)
+	cur.G = lerp(from.G, to.G)
+	cur.B = lerp(from.B, to.B)
+	cur.A = lerp(from.A, to.A)
+}
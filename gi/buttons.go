@@ -218,7 +218,7 @@ func (bb *ButtonBase) SetButtonState(state ButtonStates) bool {
 		}
 	}
 	bb.State = state
-	bb.Sty = bb.StateStyles[state]
+	bb.TransitionStyle(&bb.StateStyles[state])
 	if prev != bb.State {
 		bb.SetFullReRenderIconLabel() // needs full rerender to update text, icon
 		return true
@@ -247,7 +247,7 @@ func (bb *ButtonBase) UpdateButtonStyle() bool {
 			bb.State = ButtonActive
 		}
 	}
-	bb.Sty = bb.StateStyles[bb.State]
+	bb.TransitionStyle(&bb.StateStyles[bb.State])
 	bb.This().(ButtonWidget).ConfigPartsIfNeeded()
 	if prev != bb.State {
 		bb.SetFullReRenderIconLabel() // needs full rerender
@@ -572,12 +572,15 @@ func (bb *ButtonBase) StyleButton() {
 	}
 	for i := 0; i < int(ButtonStatesN); i++ {
 		bb.StateStyles[i].CopyFrom(&bb.Sty)
+		bb.StateStyles[i].StyleCSS(bb.This().(Node2D), Prefs.DefaultCSS(), ButtonSelectors[i], bb.Viewport)
 		bb.StateStyles[i].SetStyleProps(pst, bb.StyleProps(ButtonSelectors[i]), bb.Viewport)
 		if clsp != nil {
 			if stclsp, ok := ki.SubProps(clsp, ButtonSelectors[i]); ok {
 				bb.StateStyles[i].SetStyleProps(pst, stclsp, bb.Viewport)
 			}
 		}
+		bb.StateStyles[i].StyleCSS(bb.This().(Node2D), bb.CSSAgg, ButtonSelectors[i], bb.Viewport)
+		bb.StateStyles[i].StyleCSS(bb.This().(Node2D), Prefs.OverrideCSS(), ButtonSelectors[i], bb.Viewport)
 		bb.StateStyles[i].CopyUnitContext(&bb.Sty.UnContext)
 	}
 }
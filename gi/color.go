@@ -26,9 +26,10 @@ import (
 // ColorSpec fully specifies the color for rendering -- used in FillStyle and
 // StrokeStyle
 type ColorSpec struct {
-	Source   ColorSources      `desc:"source of color (solid, gradient)"`
+	Source   ColorSources      `desc:"source of color (solid, gradient, pattern)"`
 	Color    Color             `desc:"color for solid color source"`
 	Gradient *rasterx.Gradient `desc:"gradient parameters for gradient color source"`
+	Pattern  *TilePattern      `json:"-" xml:"-" desc:"image pattern for pattern color source"`
 }
 
 var KiT_ColorSpec = kit.Types.AddType(&ColorSpec{}, nil)
@@ -42,6 +43,7 @@ const (
 	SolidColor ColorSources = iota
 	LinearGradient
 	RadialGradient
+	ImagePattern
 	ColorSourcesN
 )
 
@@ -63,10 +65,13 @@ const (
 	GradientPointsN
 )
 
-// IsNil tests for nil solid or gradient colors
+// IsNil tests for nil solid, gradient or pattern colors
 func (cs *ColorSpec) IsNil() bool {
-	if cs.Source == SolidColor {
+	switch cs.Source {
+	case SolidColor:
 		return cs.Color.IsNil()
+	case ImagePattern:
+		return cs.Pattern == nil
 	}
 	return cs.Gradient == nil
 }
@@ -85,6 +90,7 @@ func (cs *ColorSpec) SetColor(cl color.Color) {
 	cs.Color.SetColor(cl)
 	cs.Source = SolidColor
 	cs.Gradient = nil
+	cs.Pattern = nil
 }
 
 // Copy copies a gradient, making new copies of the stops instead of
@@ -133,6 +139,12 @@ func CopyGradient(dst, src *rasterx.Gradient) {
 // RenderColor gets the color for rendering, applying opacity and bounds for
 // gradients
 func (cs *ColorSpec) RenderColor(opacity float32, bounds image.Rectangle, xform Matrix2D) interface{} {
+	if cs.Source == ImagePattern {
+		if cs.Pattern == nil || cs.Pattern.Image == nil {
+			return color.Transparent
+		}
+		return cs.Pattern.ColorFunc(opacity)
+	}
 	if cs.Source == SolidColor || cs.Gradient == nil {
 		return rasterx.ApplyOpacity(cs.Color, float64(opacity))
 	} else {
@@ -146,6 +158,51 @@ func (cs *ColorSpec) RenderColor(opacity float32, bounds image.Rectangle, xform
 	}
 }
 
+// TilePattern is a color source that tiles an image, in render image
+// pixel coordinates -- the image is rendered by the Server, e.g., an SVG
+// pattern element, prior to rendering the node using the pattern
+type TilePattern struct {
+	Image  *image.RGBA   `desc:"image tile -- nil if not yet rendered"`
+	Origin image.Point   `desc:"position in render image pixels of the top-left of the tile"`
+	Server PatternServer `desc:"node that renders the tile image"`
+}
+
+// ColorFunc returns the rasterx color function for rendering the pattern
+// with given opacity
+func (ip *TilePattern) ColorFunc(opacity float32) rasterx.ColorFunc {
+	tile := ip.Image
+	org := ip.Origin
+	sz := tile.Bounds().Size()
+	op := uint32(opacity * 255)
+	return func(x, y int) color.Color {
+		tx := (x - org.X) % sz.X
+		if tx < 0 {
+			tx += sz.X
+		}
+		ty := (y - org.Y) % sz.Y
+		if ty < 0 {
+			ty += sz.Y
+		}
+		c := tile.RGBAAt(tx, ty)
+		if op < 255 {
+			c.R = uint8(uint32(c.R) * op / 255)
+			c.G = uint8(uint32(c.G) * op / 255)
+			c.B = uint8(uint32(c.B) * op / 255)
+			c.A = uint8(uint32(c.A) * op / 255)
+		}
+		return c
+	}
+}
+
+// PatternServer is implemented by nodes that can be referred to by url() in
+// fill and stroke to render image patterns, e.g., the SVG pattern element
+type PatternServer interface {
+	// RenderPattern renders the tile image of the pattern for given node,
+	// which is about to be rendered with given bounding box (in render
+	// image pixels) and transform
+	RenderPattern(pat *TilePattern, node Node2D, bbox image.Rectangle, xform Matrix2D)
+}
+
 // Color extends image/color.RGBA with more methods for converting to / from
 // strings etc -- it has standard uint8 0..255 color values
 type Color struct {
@@ -198,6 +255,12 @@ func (c *Color) IsBlack() bool {
 	return false
 }
 
+// IsDark checks if color is dark, i.e., has an HSL lightness below .5
+func (c *Color) IsDark() bool {
+	_, _, l, _ := c.ToHSLA()
+	return l < 0.5
+}
+
 func (c *Color) String() string {
 	if c == nil {
 		return "nil"
@@ -205,6 +268,15 @@ func (c *Color) String() string {
 	return fmt.Sprintf("R: %v G: %v B: %v A: %v", c.R, c.G, c.B, c.A)
 }
 
+// HexString returns the color as a standard "#rrggbb" hex string, or
+// "#rrggbbaa" if it is not fully opaque -- see ParseHex
+func (c *Color) HexString() string {
+	if c.A == 255 {
+		return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+	}
+	return fmt.Sprintf("#%02x%02x%02x%02x", c.R, c.G, c.B, c.A)
+}
+
 func (c *Color) SetToNil() {
 	c.R = 0
 	c.G = 0
@@ -50,6 +50,12 @@ func (cs *ColorSpec) SetString(clrstr string, vp *Viewport2D) bool {
 					*cs = grad.Grad
 					return true
 				}
+				if ps, ok := ne.(PatternServer); ok {
+					cs.Source = ImagePattern
+					cs.Gradient = nil
+					cs.Pattern = &TilePattern{Server: ps}
+					return true
+				}
 			}
 		}
 		fmt.Printf("gi.Color Warning: Not able to find url: %v\n", val)
@@ -450,6 +456,68 @@ func (cs *ColorSpec) UnmarshalXML(decoder *xml.Decoder, se xml.StartElement) err
 	return nil
 }
 
+// MarshalXML writes the gradient of the color specification as an XML
+// linearGradient or radialGradient element, including its stops -- any
+// attributes in se (e.g., id) are written too.  Solid colors have no XML
+// element form and return an error.
+func (cs *ColorSpec) MarshalXML(enc *xml.Encoder, se xml.StartElement) error {
+	gr := cs.Gradient
+	if gr == nil || cs.Source == SolidColor {
+		return fmt.Errorf("gi.ColorSpec.MarshalXML: not a gradient")
+	}
+	ff := func(f float64) string { return strconv.FormatFloat(f, 'g', -1, 64) }
+	attr := func(nm, val string) {
+		se.Attr = append(se.Attr, xml.Attr{Name: xml.Name{Local: nm}, Value: val})
+	}
+	if gr.IsRadial {
+		se.Name.Local = "radialGradient"
+		attr("cx", ff(gr.Points[0]))
+		attr("cy", ff(gr.Points[1]))
+		attr("fx", ff(gr.Points[2]))
+		attr("fy", ff(gr.Points[3]))
+		attr("r", ff(gr.Points[4]))
+	} else {
+		se.Name.Local = "linearGradient"
+		attr("x1", ff(gr.Points[0]))
+		attr("y1", ff(gr.Points[1]))
+		attr("x2", ff(gr.Points[2]))
+		attr("y2", ff(gr.Points[3]))
+	}
+	if gr.Units == rasterx.UserSpaceOnUse {
+		attr("gradientUnits", "userSpaceOnUse")
+	}
+	switch gr.Spread {
+	case rasterx.ReflectSpread:
+		attr("spreadMethod", "reflect")
+	case rasterx.RepeatSpread:
+		attr("spreadMethod", "repeat")
+	}
+	if gr.Matrix != rasterx.Identity {
+		mx := gr.Matrix
+		attr("gradientTransform", fmt.Sprintf("matrix(%v %v %v %v %v %v)", ff(mx.A), ff(mx.B), ff(mx.C), ff(mx.D), ff(mx.E), ff(mx.F)))
+	}
+	if err := enc.EncodeToken(se); err != nil {
+		return err
+	}
+	for _, st := range gr.Stops {
+		sse := xml.StartElement{Name: xml.Name{Local: "stop"}}
+		var clr Color
+		clr.SetColor(st.StopColor)
+		sse.Attr = []xml.Attr{
+			{Name: xml.Name{Local: "offset"}, Value: ff(st.Offset)},
+			{Name: xml.Name{Local: "stop-color"}, Value: clr.HexString()},
+			{Name: xml.Name{Local: "stop-opacity"}, Value: ff(st.Opacity)},
+		}
+		if err := enc.EncodeToken(sse); err != nil {
+			return err
+		}
+		if err := enc.EncodeToken(sse.End()); err != nil {
+			return err
+		}
+	}
+	return enc.EncodeToken(se.End())
+}
+
 func readFraction(v string) (f float64, err error) {
 	v = strings.TrimSpace(v)
 	d := 1.0
@@ -9,9 +9,9 @@ import (
 
 var _ = errors.New("dummy error")
 
-const _ColorSources_name = "SolidColorLinearGradientRadialGradientColorSourcesN"
+const _ColorSources_name = "SolidColorLinearGradientRadialGradientImagePatternColorSourcesN"
 
-var _ColorSources_index = [...]uint8{0, 10, 24, 38, 51}
+var _ColorSources_index = [...]uint8{0, 10, 24, 38, 50, 63}
 
 func (i ColorSources) String() string {
 	if i < 0 || i >= ColorSources(len(_ColorSources_index)-1) {
@@ -6,6 +6,7 @@ package gi
 
 import (
 	"log"
+	"strings"
 
 	"github.com/aymerick/douceur/css"
 	"github.com/aymerick/douceur/parser"
@@ -37,7 +38,10 @@ func (ss *StyleSheet) ParseString(str string) error {
 }
 
 // CSSProps returns the properties for each of the rules in this style sheet,
-// suitable for setting the CSS value of a node -- returns nil if empty sheet
+// keyed by selector (see CSSSelector), suitable for setting the CSS value of
+// a node, with their source order under CSSOrderKey -- @media rules become
+// props keyed by "@media " plus their condition (see CSSMedia), containing
+// their rules -- returns nil if empty sheet
 func (ss *StyleSheet) CSSProps() ki.Props {
 	if ss.Sheet == nil {
 		return nil
@@ -47,9 +51,27 @@ func (ss *StyleSheet) CSSProps() ki.Props {
 		return nil
 	}
 	pr := make(ki.Props, sz)
-	for _, r := range ss.Sheet.Rules {
+	cssRulesProps(pr, ss.Sheet.Rules)
+	return pr
+}
+
+// cssRulesProps adds the properties for each of given rules to pr, keyed by
+// selector, as in StyleSheet.CSSProps
+func cssRulesProps(pr ki.Props, rules []*css.Rule) {
+	for _, r := range rules {
 		if r.Kind == css.AtRule {
-			continue // not supported
+			if r.Name != "@media" || len(r.Rules) == 0 {
+				continue // not supported
+			}
+			key := "@media " + strings.TrimSpace(r.Prelude)
+			mp, ok := pr[key].(ki.Props)
+			if !ok {
+				mp = make(ki.Props, len(r.Rules))
+				pr[key] = mp
+			}
+			cssAddOrder(pr, key)
+			cssRulesProps(mp, r.Rules)
+			continue
 		}
 		nd := len(r.Declarations)
 		if nd == 0 {
@@ -61,7 +83,7 @@ func (ss *StyleSheet) CSSProps() ki.Props {
 				sp[de.Property] = de.Value
 			}
 			pr[sel] = sp
+			cssAddOrder(pr, sel)
 		}
 	}
-	return pr
 }
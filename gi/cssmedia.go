@@ -0,0 +1,242 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gi
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/goki/gi/units"
+)
+
+// CSSMedia describes the display that @media conditions are evaluated
+// against: the size of the window, its resolution, and whether the color
+// preferences are dark
+type CSSMedia struct {
+	Width  float32 `desc:"width of the window in dots"`
+	Height float32 `desc:"height of the window in dots"`
+	DPI    float32 `desc:"logical dots-per-inch of the window"`
+	Dark   bool    `desc:"true if the background color preference is dark"`
+}
+
+// CSSMediaFor returns the media for given viewport: the window it is in, or
+// the viewport itself if it is not in a window
+func CSSMediaFor(vp *Viewport2D) CSSMedia {
+	md := CSSMedia{DPI: units.PxPerInch, Dark: Prefs.Colors.Background.IsDark()}
+	if vp == nil {
+		return md
+	}
+	sz := vp.Geom.Size
+	if vp.Win != nil {
+		md.DPI = vp.Win.LogicalDPI()
+		if vp.Win.Viewport != nil {
+			sz = vp.Win.Viewport.Geom.Size
+		}
+	}
+	md.Width = float32(sz.X)
+	md.Height = float32(sz.Y)
+	return md
+}
+
+// IsCSSMediaKey returns true if given key of a props map is an @media
+// condition, whose value is a props map that applies only when the
+// condition matches -- e.g., "@media (max-width: 600px)"
+func IsCSSMediaKey(key string) bool {
+	return strings.HasPrefix(key, "@media")
+}
+
+// Matches returns true if given @media condition (with or without the
+// @media prefix) matches the media -- supported are the media types all,
+// screen and print, and the features width, height, resolution (each with
+// min- and max- variants), orientation and prefers-color-scheme, which can
+// be combined with and, not, only and commas (any of which matches).  A
+// condition that fails to parse never matches, and is logged the first time.
+func (md *CSSMedia) Matches(cond string) bool {
+	mq := cssMediaQueryFor(cond)
+	for _, alt := range mq {
+		if alt.matches(md) != alt.not {
+			return true
+		}
+	}
+	return false
+}
+
+// cssMediaAlt is one of the comma-separated queries of a @media condition
+type cssMediaAlt struct {
+	not   bool
+	typ   string
+	feats []cssMediaFeat
+}
+
+// cssMediaFeat is one (feature: value) test of a media query
+type cssMediaFeat struct {
+	name string
+	val  string
+}
+
+var (
+	cssMediaCache   = map[string][]cssMediaAlt{}
+	cssMediaCacheMu sync.RWMutex
+)
+
+// cssMediaQueryFor returns the parsed, cached, queries of given condition
+func cssMediaQueryFor(cond string) []cssMediaAlt {
+	cssMediaCacheMu.RLock()
+	mq, ok := cssMediaCache[cond]
+	cssMediaCacheMu.RUnlock()
+	if ok {
+		return mq
+	}
+	mq, err := parseCSSMedia(cond)
+	if err != nil {
+		log.Println(err)
+	}
+	cssMediaCacheMu.Lock()
+	cssMediaCache[cond] = mq
+	cssMediaCacheMu.Unlock()
+	return mq
+}
+
+// parseCSSMedia parses a @media condition into its queries
+func parseCSSMedia(cond string) ([]cssMediaAlt, error) {
+	str := strings.ToLower(strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(cond), "@media")))
+	if str == "" {
+		return []cssMediaAlt{{typ: "all"}}, nil
+	}
+	var mq []cssMediaAlt
+	for _, qs := range cssSplitList(str) {
+		var alt cssMediaAlt
+		qs = strings.TrimSpace(qs)
+		for qs != "" {
+			var item string
+			if qs[0] == '(' {
+				ci := strings.IndexByte(qs, ')')
+				if ci < 0 {
+					return nil, fmt.Errorf("gi.CSSMedia: missing ')' in: %v", cond)
+				}
+				item, qs = qs[:ci+1], qs[ci+1:]
+			} else {
+				fs := strings.IndexAny(qs, " \t\n(")
+				if fs < 0 {
+					fs = len(qs)
+				}
+				item, qs = qs[:fs], qs[fs:]
+			}
+			qs = strings.TrimSpace(qs)
+			switch {
+			case item == "and":
+			case item == "not" && alt.typ == "" && len(alt.feats) == 0:
+				alt.not = true
+			case item == "only" && alt.typ == "" && len(alt.feats) == 0:
+			case item[0] == '(':
+				nm, val := item[1:len(item)-1], ""
+				if ci := strings.IndexByte(nm, ':'); ci >= 0 {
+					nm, val = nm[:ci], strings.TrimSpace(nm[ci+1:])
+				}
+				alt.feats = append(alt.feats, cssMediaFeat{strings.TrimSpace(nm), val})
+			case alt.typ == "" && len(alt.feats) == 0:
+				alt.typ = item
+			default:
+				return nil, fmt.Errorf("gi.CSSMedia: unexpected %q in: %v", item, cond)
+			}
+		}
+		mq = append(mq, alt)
+	}
+	return mq, nil
+}
+
+// matches returns true if the query, ignoring its not, matches the media
+func (alt *cssMediaAlt) matches(md *CSSMedia) bool {
+	switch alt.typ {
+	case "", "all", "screen":
+	default:
+		return false
+	}
+	for i := range alt.feats {
+		if !alt.feats[i].matches(md) {
+			return false
+		}
+	}
+	return true
+}
+
+// matches returns true if the feature test matches the media -- unknown
+// features do not match
+func (ft *cssMediaFeat) matches(md *CSSMedia) bool {
+	nm := ft.name
+	cmp := 0
+	switch {
+	case strings.HasPrefix(nm, "min-"):
+		nm, cmp = nm[4:], 1
+	case strings.HasPrefix(nm, "max-"):
+		nm, cmp = nm[4:], -1
+	}
+	var have, want float32
+	switch nm {
+	case "width", "height":
+		have = md.Width
+		if nm == "height" {
+			have = md.Height
+		}
+		if ft.val == "" {
+			return have > 0
+		}
+		ctxt := units.Context{}
+		ctxt.Defaults()
+		ctxt.DPI = md.DPI
+		v := units.StringToValue(ft.val)
+		want = v.ToDots(&ctxt)
+	case "resolution":
+		have = md.DPI
+		if ft.val == "" {
+			return have > 0
+		}
+		rv, ok := cssParseResolution(ft.val)
+		if !ok {
+			return false
+		}
+		want = rv
+	case "orientation":
+		land := md.Width > md.Height
+		return cmp == 0 && ((ft.val == "landscape" && land) || (ft.val == "portrait" && !land))
+	case "prefers-color-scheme":
+		return cmp == 0 && (ft.val == "" || (ft.val == "dark") == md.Dark)
+	default:
+		return false
+	}
+	switch cmp {
+	case 1:
+		return have >= want
+	case -1:
+		return have <= want
+	}
+	return have == want
+}
+
+// cssParseResolution parses a resolution in dpi, dpcm, dppx or x units,
+// returning dpi
+func cssParseResolution(str string) (float32, bool) {
+	fact := float32(1)
+	switch {
+	case strings.HasSuffix(str, "dpi"):
+		str = str[:len(str)-3]
+	case strings.HasSuffix(str, "dpcm"):
+		str, fact = str[:len(str)-4], units.CmPerInch
+	case strings.HasSuffix(str, "dppx"):
+		str, fact = str[:len(str)-4], units.PxPerInch
+	case strings.HasSuffix(str, "x"):
+		str, fact = str[:len(str)-1], units.PxPerInch
+	default:
+		return 0, false
+	}
+	val, err := strconv.ParseFloat(strings.TrimSpace(str), 32)
+	if err != nil {
+		return 0, false
+	}
+	return float32(val) * fact, true
+}
@@ -0,0 +1,880 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gi
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/goki/ki"
+	"github.com/goki/ki/kit"
+)
+
+// CSSSelector is a parsed CSS selector, which matches nodes in the ki tree:
+// a sequence of compound selectors joined by combinators, e.g.,
+// "frame > button.primary:hover" or ".toolbar label".  Types, classes and
+// names (#id) are matched case-insensitively, as lower-case, and attributes
+// are matched against the name, class and properties of the node.  The
+// widget state pseudo-classes (:hover, :focus, :active, :inactive, :down,
+// :selected etc) on the last compound select the style of that state of the
+// node, as for the state sub-properties of a rule (see SubProps) -- on other
+// compounds, only :focus, :active and :inactive are supported, matching the
+// current state of the node.
+type CSSSelector struct {
+	Str   string        `desc:"the selector as written"`
+	Parts []CSSCompound `desc:"the compound selectors, from left to right -- the last one is the subject that the selector matches"`
+	State string        `desc:"widget state pseudo-class of the subject, e.g., :hover -- the properties of a rule with this selector apply only to that state of the node"`
+	Spec  int           `desc:"specificity of the selector: 10000 per #id, 100 per class, attribute and pseudo-class, and 1 per type"`
+}
+
+// CSSCompound is a compound selector, matching a single node, e.g.,
+// button.primary[tooltip]:first-child
+type CSSCompound struct {
+	Comb    byte             `desc:"combinator that relates this compound to the previous one: ' ' for a descendant, '>' for a child, '+' for the next sibling, '~' for a later sibling -- 0 for the first compound"`
+	Type    string           `desc:"type name, empty or * for any"`
+	ID      string           `desc:"name of the node (#id), if non-empty"`
+	Classes []string         `desc:"classes that the node must have"`
+	Attrs   []CSSAttrSel     `desc:"attribute selectors"`
+	Pseudos []CSSPseudoClass `desc:"structural and state pseudo-classes"`
+}
+
+// CSSAttrSel is an attribute selector, e.g., [type=checkbox]
+type CSSAttrSel struct {
+	Name string `desc:"attribute name"`
+	Op   string `desc:"operator: empty for presence, or one of = ~= |= ^= $= *="`
+	Val  string `desc:"value to compare with"`
+}
+
+// CSSPseudoClass is a pseudo-class within a compound selector, e.g.,
+// :nth-child(2n+1) or :not(.hidden)
+type CSSPseudoClass struct {
+	Name string       `desc:"name, without the colon"`
+	A, B int          `desc:"for the nth- pseudo-classes: matches positions A*n+B, for n >= 0, counting from 1"`
+	Not  *CSSCompound `desc:"for :not(), the compound that must not match"`
+}
+
+// CSSStateSelectors are the pseudo-classes for widget states, which select
+// the style of a state of a node when they are on the subject of a selector
+// (see CSSSelector)
+var CSSStateSelectors = map[string]bool{
+	"active":   true,
+	"inactive": true,
+	"hover":    true,
+	"focus":    true,
+	"down":     true,
+	"selected": true,
+	"disabled": true,
+	"checked":  true,
+}
+
+// ParseCSSSelectors parses a comma-separated list of selectors
+func ParseCSSSelectors(str string) ([]*CSSSelector, error) {
+	var sels []*CSSSelector
+	for _, s := range cssSplitList(str) {
+		sel, err := ParseCSSSelector(s)
+		if err != nil {
+			return nil, err
+		}
+		sels = append(sels, sel)
+	}
+	return sels, nil
+}
+
+// cssSplitList splits a selector list at the commas that are not within
+// brackets, parentheses or quotes
+func cssSplitList(str string) []string {
+	var strs []string
+	depth := 0
+	var quote rune
+	st := 0
+	for i, r := range str {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == '(' || r == '[':
+			depth++
+		case r == ')' || r == ']':
+			depth--
+		case r == ',' && depth == 0:
+			strs = append(strs, str[st:i])
+			st = i + 1
+		}
+	}
+	return append(strs, str[st:])
+}
+
+// cssSelParser is the state of parsing one selector
+type cssSelParser struct {
+	str string
+	pos int
+}
+
+func (sp *cssSelParser) eof() bool {
+	return sp.pos >= len(sp.str)
+}
+
+func (sp *cssSelParser) peek() byte {
+	if sp.eof() {
+		return 0
+	}
+	return sp.str[sp.pos]
+}
+
+func (sp *cssSelParser) skipSpace() bool {
+	st := sp.pos
+	for !sp.eof() && strings.IndexByte(" \t\n\r\f", sp.peek()) >= 0 {
+		sp.pos++
+	}
+	return sp.pos > st
+}
+
+// ident reads an identifier -- everything up to the next special character
+func (sp *cssSelParser) ident() string {
+	st := sp.pos
+	for !sp.eof() && strings.IndexByte(" \t\n\r\f.#[]:>+~,()=*|^$\"'", sp.peek()) < 0 {
+		sp.pos++
+	}
+	return sp.str[st:sp.pos]
+}
+
+// ParseCSSSelector parses a single selector (with no commas)
+func ParseCSSSelector(str string) (*CSSSelector, error) {
+	sel := &CSSSelector{Str: strings.TrimSpace(str)}
+	sp := &cssSelParser{str: sel.Str}
+	if sp.eof() {
+		return nil, errors.New("gi.ParseCSSSelector: empty selector")
+	}
+	for !sp.eof() {
+		var comb byte
+		if len(sel.Parts) > 0 {
+			if sp.skipSpace() {
+				comb = ' '
+			}
+			if c := sp.peek(); c == '>' || c == '+' || c == '~' {
+				comb = c
+				sp.pos++
+				sp.skipSpace()
+			}
+		}
+		cp, err := sp.compound()
+		if err != nil {
+			return nil, fmt.Errorf("gi.ParseCSSSelector: %v in selector: %v", err, str)
+		}
+		cp.Comb = comb
+		sel.Parts = append(sel.Parts, *cp)
+	}
+	// pull the state of the subject out of its pseudo-classes
+	subj := &sel.Parts[len(sel.Parts)-1]
+	for i := 0; i < len(subj.Pseudos); i++ {
+		ps := subj.Pseudos[i]
+		if !CSSStateSelectors[ps.Name] {
+			continue
+		}
+		if sel.State != "" {
+			return nil, fmt.Errorf("gi.ParseCSSSelector: more than one state in selector: %v", str)
+		}
+		sel.State = ":" + ps.Name
+		subj.Pseudos = append(subj.Pseudos[:i], subj.Pseudos[i+1:]...)
+		i--
+	}
+	sel.Spec = sel.specificity()
+	if sel.State != "" {
+		sel.Spec += 100
+	}
+	return sel, nil
+}
+
+// compound parses a compound selector at the current position
+func (sp *cssSelParser) compound() (*CSSCompound, error) {
+	cp := &CSSCompound{}
+	st := sp.pos
+	if sp.peek() == '*' {
+		sp.pos++
+		cp.Type = "*"
+	} else {
+		cp.Type = strings.ToLower(sp.ident())
+	}
+	for !sp.eof() {
+		c := sp.peek()
+		switch c {
+		case '.':
+			sp.pos++
+			cl := sp.ident()
+			if cl == "" {
+				return nil, errors.New("missing class name")
+			}
+			cp.Classes = append(cp.Classes, strings.ToLower(cl))
+		case '#':
+			sp.pos++
+			id := sp.ident()
+			if id == "" {
+				return nil, errors.New("missing id")
+			}
+			cp.ID = strings.ToLower(id)
+		case '[':
+			sp.pos++
+			at, err := sp.attr()
+			if err != nil {
+				return nil, err
+			}
+			cp.Attrs = append(cp.Attrs, at)
+		case ':':
+			sp.pos++
+			if sp.peek() == ':' {
+				return nil, errors.New("pseudo-elements are not supported")
+			}
+			ps, err := sp.pseudo()
+			if err != nil {
+				return nil, err
+			}
+			cp.Pseudos = append(cp.Pseudos, ps)
+		default:
+			if sp.pos == st {
+				return nil, fmt.Errorf("unexpected character: %q", c)
+			}
+			return cp, nil
+		}
+	}
+	if sp.pos == st {
+		return nil, errors.New("missing selector")
+	}
+	return cp, nil
+}
+
+// attr parses an attribute selector, after the [
+func (sp *cssSelParser) attr() (CSSAttrSel, error) {
+	at := CSSAttrSel{}
+	sp.skipSpace()
+	at.Name = strings.ToLower(sp.ident())
+	if at.Name == "" {
+		return at, errors.New("missing attribute name")
+	}
+	sp.skipSpace()
+	if c := sp.peek(); c != ']' {
+		op := ""
+		if strings.IndexByte("~|^$*", c) >= 0 {
+			op = string(c)
+			sp.pos++
+		}
+		if sp.peek() != '=' {
+			return at, errors.New("invalid attribute operator")
+		}
+		sp.pos++
+		at.Op = op + "="
+		sp.skipSpace()
+		if q := sp.peek(); q == '"' || q == '\'' {
+			end := strings.IndexByte(sp.str[sp.pos+1:], q)
+			if end < 0 {
+				return at, errors.New("unterminated string")
+			}
+			at.Val = sp.str[sp.pos+1 : sp.pos+1+end]
+			sp.pos += end + 2
+		} else {
+			at.Val = sp.ident()
+		}
+		sp.skipSpace()
+	}
+	if sp.peek() != ']' {
+		return at, errors.New("missing ]")
+	}
+	sp.pos++
+	return at, nil
+}
+
+// pseudo parses a pseudo-class, after the :
+func (sp *cssSelParser) pseudo() (CSSPseudoClass, error) {
+	ps := CSSPseudoClass{Name: strings.ToLower(sp.ident())}
+	if ps.Name == "" {
+		return ps, errors.New("missing pseudo-class name")
+	}
+	var arg string
+	if sp.peek() == '(' {
+		depth := 0
+		st := sp.pos + 1
+		for ; !sp.eof(); sp.pos++ {
+			if c := sp.peek(); c == '(' {
+				depth++
+			} else if c == ')' {
+				depth--
+				if depth == 0 {
+					break
+				}
+			}
+		}
+		if sp.eof() {
+			return ps, errors.New("missing )")
+		}
+		arg = strings.TrimSpace(sp.str[st:sp.pos])
+		sp.pos++
+	}
+	switch ps.Name {
+	case "nth-child", "nth-last-child", "nth-of-type", "nth-last-of-type":
+		a, b, err := cssParseNth(arg)
+		if err != nil {
+			return ps, err
+		}
+		ps.A, ps.B = a, b
+	case "first-child", "last-child", "first-of-type", "last-of-type":
+		ps.A, ps.B = 0, 1
+	case "only-child", "only-of-type", "empty", "root":
+	case "not":
+		nsp := &cssSelParser{str: arg}
+		cp, err := nsp.compound()
+		if err != nil {
+			return ps, err
+		}
+		if !nsp.eof() {
+			return ps, errors.New(":not() only supports a compound selector")
+		}
+		ps.Not = cp
+	default:
+		if !CSSStateSelectors[ps.Name] {
+			return ps, fmt.Errorf("unsupported pseudo-class: %v", ps.Name)
+		}
+	}
+	return ps, nil
+}
+
+// cssParseNth parses the argument of an nth- pseudo-class: odd, even, or
+// An+B in any of its forms
+func cssParseNth(arg string) (a, b int, err error) {
+	arg = strings.ToLower(strings.Replace(arg, " ", "", -1))
+	switch arg {
+	case "odd":
+		return 2, 1, nil
+	case "even":
+		return 2, 0, nil
+	}
+	ni := strings.IndexByte(arg, 'n')
+	if ni < 0 {
+		b, err = strconv.Atoi(arg)
+		return 0, b, err
+	}
+	switch as := arg[:ni]; as {
+	case "", "+":
+		a = 1
+	case "-":
+		a = -1
+	default:
+		if a, err = strconv.Atoi(as); err != nil {
+			return
+		}
+	}
+	if bs := arg[ni+1:]; bs != "" {
+		b, err = strconv.Atoi(strings.TrimPrefix(bs, "+"))
+	}
+	return
+}
+
+// specificity returns the specificity of the selector, not counting its
+// state
+func (sel *CSSSelector) specificity() int {
+	spec := 0
+	for i := range sel.Parts {
+		spec += sel.Parts[i].specificity()
+	}
+	return spec
+}
+
+func (cp *CSSCompound) specificity() int {
+	spec := 0
+	if cp.ID != "" {
+		spec += 10000
+	}
+	spec += 100 * (len(cp.Classes) + len(cp.Attrs))
+	for _, ps := range cp.Pseudos {
+		if ps.Not != nil {
+			spec += ps.Not.specificity()
+		} else {
+			spec += 100
+		}
+	}
+	if cp.Type != "" && cp.Type != "*" {
+		spec++
+	}
+	return spec
+}
+
+/////////////////////////////////////////////////////////////////////////////
+//   Matching
+
+// Matches returns true if the selector matches given node, regardless of the
+// state of the node selected by its State, if any
+func (sel *CSSSelector) Matches(k ki.Ki) bool {
+	return sel.matchFrom(k, len(sel.Parts)-1)
+}
+
+// matchFrom matches the compounds up to index pi, with compound pi matching
+// node k
+func (sel *CSSSelector) matchFrom(k ki.Ki, pi int) bool {
+	cp := &sel.Parts[pi]
+	if !cp.Matches(k) {
+		return false
+	}
+	if pi == 0 {
+		return true
+	}
+	switch cp.Comb {
+	case '>':
+		par := k.Parent()
+		return par != nil && sel.matchFrom(par, pi-1)
+	case '+':
+		sibs, idx := cssSiblings(k)
+		return idx > 0 && sel.matchFrom(sibs[idx-1], pi-1)
+	case '~':
+		sibs, idx := cssSiblings(k)
+		for i := idx - 1; i >= 0; i-- {
+			if sel.matchFrom(sibs[i], pi-1) {
+				return true
+			}
+		}
+		return false
+	default:
+		for par := k.Parent(); par != nil; par = par.Parent() {
+			if sel.matchFrom(par, pi-1) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// cssSiblings returns the children of the parent of given node, and the
+// index of the node among them -- nil, -1 if it has no parent
+func cssSiblings(k ki.Ki) ([]ki.Ki, int) {
+	par := k.Parent()
+	if par == nil {
+		return nil, -1
+	}
+	idx, ok := k.IndexInParent()
+	if !ok {
+		return nil, -1
+	}
+	return *par.Children(), idx
+}
+
+// cssTypeName returns the type name of given node, as used in selectors
+func cssTypeName(k ki.Ki) string {
+	return strings.ToLower(k.Type().Name())
+}
+
+// cssClass returns the class of given node, lower-cased
+func cssClass(k ki.Ki) string {
+	if nb, ok := k.Embed(KiT_NodeBase).(*NodeBase); ok {
+		return strings.ToLower(nb.Class)
+	}
+	return ""
+}
+
+// cssAttr returns the value of given attribute of given node: the id (name),
+// class or type of the node, or otherwise the value of the property of that
+// name
+func cssAttr(k ki.Ki, name string) (string, bool) {
+	switch name {
+	case "id", "name":
+		return k.Name(), true
+	case "class":
+		if cl := cssClass(k); cl != "" {
+			return cl, true
+		}
+		return "", false
+	case "type":
+		return cssTypeName(k), true
+	}
+	pv, ok := k.Prop(name)
+	if !ok {
+		return "", false
+	}
+	return kit.ToString(pv), true
+}
+
+// Matches returns true if the compound matches given node
+func (cp *CSSCompound) Matches(k ki.Ki) bool {
+	if cp.Type != "" && cp.Type != "*" && cssTypeName(k) != cp.Type {
+		return false
+	}
+	if cp.ID != "" && strings.ToLower(k.Name()) != cp.ID {
+		return false
+	}
+	if len(cp.Classes) > 0 {
+		cls := strings.Fields(cssClass(k))
+		for _, cl := range cp.Classes {
+			has := false
+			for _, c := range cls {
+				if c == cl {
+					has = true
+					break
+				}
+			}
+			if !has {
+				return false
+			}
+		}
+	}
+	for _, at := range cp.Attrs {
+		if !at.Matches(k) {
+			return false
+		}
+	}
+	for i := range cp.Pseudos {
+		if !cp.Pseudos[i].Matches(k) {
+			return false
+		}
+	}
+	return true
+}
+
+// Matches returns true if the attribute selector matches given node
+func (at *CSSAttrSel) Matches(k ki.Ki) bool {
+	v, ok := cssAttr(k, at.Name)
+	if !ok {
+		return false
+	}
+	switch at.Op {
+	case "":
+		return true
+	case "=":
+		return v == at.Val
+	case "~=":
+		for _, f := range strings.Fields(v) {
+			if f == at.Val {
+				return true
+			}
+		}
+		return false
+	case "|=":
+		return v == at.Val || strings.HasPrefix(v, at.Val+"-")
+	case "^=":
+		return at.Val != "" && strings.HasPrefix(v, at.Val)
+	case "$=":
+		return at.Val != "" && strings.HasSuffix(v, at.Val)
+	case "*=":
+		return at.Val != "" && strings.Contains(v, at.Val)
+	}
+	return false
+}
+
+// nthMatch returns true if 1-based position pos is A*n+B for some n >= 0
+func (ps *CSSPseudoClass) nthMatch(pos int) bool {
+	if ps.A == 0 {
+		return pos == ps.B
+	}
+	d := pos - ps.B
+	return d%ps.A == 0 && d/ps.A >= 0
+}
+
+// Matches returns true if the pseudo-class matches given node
+func (ps *CSSPseudoClass) Matches(k ki.Ki) bool {
+	switch ps.Name {
+	case "not":
+		return !ps.Not.Matches(k)
+	case "root":
+		return k.Parent() == nil
+	case "empty":
+		return !k.HasChildren()
+	}
+	if CSSStateSelectors[ps.Name] {
+		return cssInState(k, ps.Name)
+	}
+	sibs, idx := cssSiblings(k)
+	if sibs == nil {
+		return false
+	}
+	ofType := strings.HasSuffix(ps.Name, "-of-type")
+	tn := cssTypeName(k)
+	pos, n := 0, 0 // 1-based position from start, and count
+	for i, s := range sibs {
+		if ofType && cssTypeName(s) != tn {
+			continue
+		}
+		n++
+		if i == idx {
+			pos = n
+		}
+	}
+	switch ps.Name {
+	case "nth-child", "first-child", "nth-of-type", "first-of-type":
+		return ps.nthMatch(pos)
+	case "nth-last-child", "last-child", "nth-last-of-type", "last-of-type":
+		return ps.nthMatch(n - pos + 1)
+	case "only-child", "only-of-type":
+		return n == 1
+	}
+	return false
+}
+
+// cssInState returns true if given node is in given state, one of the
+// CSSStateSelectors, e.g., as tested within :not() or on the ancestors of
+// the subject of a selector
+func cssInState(k ki.Ki, state string) bool {
+	nb, ok := k.Embed(KiT_NodeBase).(*NodeBase)
+	if !ok {
+		return false
+	}
+	switch state {
+	case "focus":
+		return nb.HasFocus()
+	case "active":
+		return !nb.IsInactive()
+	case "inactive", "disabled":
+		return nb.IsInactive()
+	case "hover":
+		return nb.HasFlag(int(MouseHasEntered))
+	case "selected":
+		return nb.IsSelected()
+	case "down", "checked":
+		bb, ok := k.Embed(KiT_ButtonBase).(*ButtonBase)
+		if !ok {
+			return false
+		}
+		if state == "down" {
+			return bb.State == ButtonDown
+		}
+		return bb.IsChecked()
+	}
+	return false
+}
+
+/////////////////////////////////////////////////////////////////////////////
+//   Applying css
+
+var (
+	cssSelCache   = map[string][]*CSSSelector{}
+	cssSelCacheMu sync.RWMutex
+)
+
+// CSSSelectorsFor returns the parsed selectors for given key of css
+// properties, which are cached -- nil if it fails to parse, which is logged
+// the first time
+func CSSSelectorsFor(key string) []*CSSSelector {
+	cssSelCacheMu.RLock()
+	sels, ok := cssSelCache[key]
+	cssSelCacheMu.RUnlock()
+	if ok {
+		return sels
+	}
+	sels, err := ParseCSSSelectors(key)
+	if err != nil {
+		log.Println(err)
+	}
+	cssSelCacheMu.Lock()
+	cssSelCache[key] = sels
+	cssSelCacheMu.Unlock()
+	return sels
+}
+
+// CSSOrderKey is the key of the source order of the rules in a css props
+// map, a *CSSOrder, as recorded by StyleSheet.CSSProps and combined by
+// AggCSS -- rules of equal specificity apply in this order, after any rules
+// that are not in it, which apply in order of selector
+const CSSOrderKey = "@order"
+
+// CSSOrder is the source order of the rules of a css props map, stored under
+// CSSOrderKey, with an index of the position of each rule for matching
+type CSSOrder struct {
+	Keys []string       `desc:"the selectors of the rules, in source order"`
+	idx  map[string]int // index of each key in Keys
+}
+
+// NewCSSOrder returns a new source order of given rule selectors
+func NewCSSOrder(keys []string) *CSSOrder {
+	co := &CSSOrder{Keys: keys}
+	co.reindex()
+	return co
+}
+
+// reindex rebuilds the index of the keys
+func (co *CSSOrder) reindex() {
+	co.idx = make(map[string]int, len(co.Keys))
+	for i, k := range co.Keys {
+		co.idx[k] = i
+	}
+}
+
+// Index returns the index of the rule of given key in the source order -- -1
+// if not in it
+func (co *CSSOrder) Index(key string) int {
+	if co == nil {
+		return -1
+	}
+	if co.idx == nil {
+		co.reindex()
+	}
+	if i, ok := co.idx[key]; ok {
+		return i
+	}
+	return -1
+}
+
+// Add adds given key at the end of the source order, moving it there if it
+// is already in it
+func (co *CSSOrder) Add(key string) {
+	if i := co.Index(key); i >= 0 {
+		co.Keys = append(co.Keys[:i], co.Keys[i+1:]...)
+		co.Keys = append(co.Keys, key)
+		co.reindex()
+		return
+	}
+	co.idx[key] = len(co.Keys)
+	co.Keys = append(co.Keys, key)
+}
+
+// cssOrderOf returns the source order of given css (see CSSOrderKey) -- nil
+// if not recorded
+func cssOrderOf(css ki.Props) *CSSOrder {
+	co, _ := css[CSSOrderKey].(*CSSOrder)
+	return co
+}
+
+// cssRuleOrder returns the index of the rule of given key in the source order
+// of given css (see CSSOrderKey) -- -1 if not recorded
+func cssRuleOrder(css ki.Props, key string) int {
+	return cssOrderOf(css).Index(key)
+}
+
+// cssAddOrder adds given key at the end of the source order of given css
+// (see CSSOrderKey), moving it there if it is already in it
+func cssAddOrder(css ki.Props, key string) {
+	co := cssOrderOf(css)
+	if co == nil {
+		co = NewCSSOrder(nil)
+		css[CSSOrderKey] = co
+	}
+	co.Add(key)
+}
+
+// cssMergeOrder returns the source order (see CSSOrderKey) of the rules of
+// given agg with those of given css added on top: the rules of agg that are
+// not in css, followed by those of css that have no source order, in order of
+// selector, and then those of css in their source order
+func cssMergeOrder(agg, css ki.Props) *CSSOrder {
+	var aord, cord []string
+	if co := cssOrderOf(agg); co != nil {
+		aord = co.Keys
+	}
+	cco := cssOrderOf(css)
+	if cco != nil {
+		cord = cco.Keys
+	}
+	ord := make([]string, 0, len(aord)+len(css))
+	for _, k := range aord {
+		if _, has := css[k]; !has {
+			ord = append(ord, k)
+		}
+	}
+	st := len(ord)
+	for k := range css {
+		if k != CSSOrderKey && cco.Index(k) < 0 {
+			ord = append(ord, k)
+		}
+	}
+	sort.Strings(ord[st:])
+	return NewCSSOrder(append(ord, cord...))
+}
+
+// cssMatch is a rule that matches a node
+type cssMatch struct {
+	key   string
+	spec  int
+	order []int
+	props ki.Props
+}
+
+// cssOrderLess returns true if source order a (indexes of the rule within
+// each level of @media nesting, see CSSOrderKey) comes before b
+func cssOrderLess(a, b []int) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return len(a) < len(b)
+}
+
+// CSSMatchingProps returns the properties of the rules in given css (a map
+// of selectors to ki.Props of properties) whose selectors match given node,
+// for the style of given state of the node (e.g., ":hover"), or its base
+// style if state is empty.  For a state, rules whose subject has that state
+// apply, along with the state sub-properties of matching rules with no
+// state.  The properties are returned in increasing order of specificity,
+// and then of source order (see CSSOrderKey), so that they can be applied in
+// order.  The rules within @media conditions (see CSSMedia) are included if
+// the condition matches the viewport of the node.
+func CSSMatchingProps(node ki.Ki, css ki.Props, state string) []ki.Props {
+	var ms []cssMatch
+	cssMatchRules(node, css, state, nil, nil, &ms)
+	sort.Slice(ms, func(i, j int) bool {
+		if ms[i].spec != ms[j].spec {
+			return ms[i].spec < ms[j].spec
+		}
+		if cssOrderLess(ms[i].order, ms[j].order) {
+			return true
+		}
+		if cssOrderLess(ms[j].order, ms[i].order) {
+			return false
+		}
+		return ms[i].key < ms[j].key
+	})
+	props := make([]ki.Props, len(ms))
+	for i := range ms {
+		props[i] = ms[i].props
+	}
+	return props
+}
+
+// cssMatchRules adds the rules in css that match given node to ms, looking
+// within @media conditions that match -- order is the source order of the
+// @media rule containing css, if any, and md is the media of the node, which
+// is computed when first needed if nil
+func cssMatchRules(node ki.Ki, css ki.Props, state string, md *CSSMedia, order []int, ms *[]cssMatch) {
+	for key, val := range css {
+		pmap, ok := val.(ki.Props) // must be a props map
+		if !ok {
+			continue
+		}
+		kord := append(order[:len(order):len(order)], cssRuleOrder(css, key))
+		if IsCSSMediaKey(key) {
+			if md == nil {
+				var vp *Viewport2D
+				if _, nb := KiToNode2D(node); nb != nil {
+					vp = nb.Viewport
+				}
+				nmd := CSSMediaFor(vp)
+				md = &nmd
+			}
+			if md.Matches(key) {
+				cssMatchRules(node, pmap, state, md, kord, ms)
+			}
+			continue
+		}
+		best := -1
+		var bprops ki.Props
+		for _, sel := range CSSSelectorsFor(key) {
+			if sel.Spec <= best || !sel.Matches(node) {
+				continue
+			}
+			switch {
+			case sel.State == state:
+				best, bprops = sel.Spec, pmap
+			case sel.State == "":
+				if sp, ok := SubProps(pmap, state); ok {
+					best, bprops = sel.Spec, sp
+				}
+			}
+		}
+		if best >= 0 {
+			*ms = append(*ms, cssMatch{key, best, kord, bprops})
+		}
+	}
+}
@@ -0,0 +1,330 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gi
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/goki/ki"
+)
+
+// testCSSTree returns a tree of widgets for testing selectors, by name:
+//
+//	frame#top
+//	  label#title.big
+//	  button#b1
+//	  button#b2.primary
+//	  layout#lay
+//	    button#b3.primary
+//	  label#note
+func testCSSTree() map[string]ki.Ki {
+	top := &Frame{}
+	top.InitName(top, "top")
+	nds := map[string]ki.Ki{"top": top}
+	add := func(par ki.Ki, typ reflect.Type, nm, cls string) ki.Ki {
+		k := par.AddNewChild(typ, nm)
+		k.Embed(KiT_NodeBase).(*NodeBase).Class = cls
+		nds[nm] = k
+		return k
+	}
+	add(top, KiT_Label, "title", "big")
+	add(top, KiT_Button, "b1", "")
+	add(top, KiT_Button, "b2", "primary zeta alpha")
+	lay := add(top, KiT_Layout, "lay", "")
+	add(lay, KiT_Button, "b3", "primary")
+	add(top, KiT_Label, "note", "")
+	return nds
+}
+
+type cssMatchTest struct {
+	sel  string
+	node string
+	want bool
+}
+
+func testCSSMatches(t *testing.T, tests []cssMatchTest) {
+	t.Helper()
+	nds := testCSSTree()
+	for _, tst := range tests {
+		sel, err := ParseCSSSelector(tst.sel)
+		if err != nil {
+			t.Errorf("ParseCSSSelector(%q): %v", tst.sel, err)
+			continue
+		}
+		nd, ok := nds[tst.node]
+		if !ok {
+			t.Fatalf("node not found: %v", tst.node)
+		}
+		if got := sel.Matches(nd); got != tst.want {
+			t.Errorf("selector %q matches %v: %v, want: %v", tst.sel, tst.node, got, tst.want)
+		}
+	}
+}
+
+func TestCSSCombinators(t *testing.T) {
+	testCSSMatches(t, []cssMatchTest{
+		{"frame > button", "b1", true},
+		{"frame > button", "b3", false},
+		{"frame button", "b3", true},
+		{"frame  >  layout > button", "b3", true},
+		{"layout button", "b1", false},
+		{"label + button", "b1", true},
+		{"label + button", "b2", false},
+		{".big + #b1", "b1", true},
+		{"label ~ button", "b2", true},
+		{"label ~ button", "b3", false},
+		{"#title ~ label", "note", true},
+		{"#note ~ label", "title", false},
+		{"layout > .primary", "b3", true},
+		{"layout > .primary", "b2", false},
+		{"frame .primary:hover", "b3", true},
+		{"#top #lay > *", "b3", true},
+		{"button:not(.primary)", "b1", true},
+		{"button:not(.primary)", "b2", false},
+	})
+}
+
+func TestCSSNthChild(t *testing.T) {
+	testCSSMatches(t, []cssMatchTest{
+		{":nth-child(2)", "b1", true},
+		{":nth-child(2)", "b2", false},
+		{":nth-child(odd)", "title", true},
+		{":nth-child(odd)", "b2", true},
+		{":nth-child(odd)", "lay", false},
+		{":nth-child(even)", "lay", true},
+		{":nth-child(2n)", "b1", true},
+		{":nth-child(3n+1)", "title", true},
+		{":nth-child(3n+1)", "lay", true},
+		{":nth-child(3n+1)", "b1", false},
+		{":nth-child(-n+2)", "b1", true},
+		{":nth-child(-n+2)", "b2", false},
+		{":nth-last-child(1)", "note", true},
+		{":nth-last-child(2)", "lay", true},
+		{"button:nth-of-type(2)", "b2", true},
+		{"button:nth-of-type(2)", "b1", false},
+		{"label:last-of-type", "note", true},
+		{"label:last-of-type", "title", false},
+		{":first-child", "title", true},
+		{":first-child", "b3", true},
+		{":only-child", "b3", true},
+		{":only-child", "b1", false},
+		{":nth-child(1)", "top", false}, // no parent
+	})
+}
+
+func TestCSSNotState(t *testing.T) {
+	nds := testCSSTree()
+	nds["b1"].SetFlag(int(MouseHasEntered))
+	nds["b2"].SetFlag(int(Selected))
+	nds["b2"].(*Button).SetChecked(true)
+	nds["b3"].SetFlag(int(Inactive))
+	tests := []struct {
+		sel  string
+		node string
+		want bool
+	}{
+		{"button:not(:hover)", "b1", false},
+		{"button:not(:hover)", "b2", true},
+		{"button:not(:selected)", "b2", false},
+		{"button:not(:selected)", "b1", true},
+		{"button:not(:checked)", "b2", false},
+		{"button:not(:checked)", "b3", true},
+		{"button:not(:down)", "b1", true},
+		{"button:not(:disabled)", "b3", false},
+		{"button:not(:inactive)", "b3", false},
+		{"button:not(:active)", "b3", true},
+		{"button:not(:active)", "b1", false},
+		{"button:not(:focus)", "b1", true},
+		{"label:not(:checked)", "title", true},
+		{"frame:not(:hover) > button", "b1", true},
+	}
+	for _, tst := range tests {
+		sel, err := ParseCSSSelector(tst.sel)
+		if err != nil {
+			t.Errorf("ParseCSSSelector(%q): %v", tst.sel, err)
+			continue
+		}
+		if got := sel.Matches(nds[tst.node]); got != tst.want {
+			t.Errorf("selector %q matches %v: %v, want: %v", tst.sel, tst.node, got, tst.want)
+		}
+	}
+}
+
+func TestCSSParseNth(t *testing.T) {
+	tests := []struct {
+		arg  string
+		a, b int
+	}{
+		{"odd", 2, 1},
+		{"even", 2, 0},
+		{"3", 0, 3},
+		{"n", 1, 0},
+		{"2n+1", 2, 1},
+		{"-n+3", -1, 3},
+		{"+n-1", 1, -1},
+		{"4n - 2", 4, -2},
+	}
+	for _, tst := range tests {
+		a, b, err := cssParseNth(tst.arg)
+		if err != nil {
+			t.Errorf("cssParseNth(%q): %v", tst.arg, err)
+			continue
+		}
+		if a != tst.a || b != tst.b {
+			t.Errorf("cssParseNth(%q) = %v, %v, want: %v, %v", tst.arg, a, b, tst.a, tst.b)
+		}
+	}
+	if _, _, err := cssParseNth("2x+1"); err == nil {
+		t.Errorf("cssParseNth(%q) did not fail", "2x+1")
+	}
+}
+
+func TestCSSSpecificity(t *testing.T) {
+	tests := []struct {
+		sel   string
+		spec  int
+		state string
+	}{
+		{"button", 1, ""},
+		{"*", 0, ""},
+		{".primary", 100, ""},
+		{"#ok", 10000, ""},
+		{"button.primary", 101, ""},
+		{"frame > button.primary:hover", 202, ":hover"},
+		{"[tooltip]", 100, ""},
+		{"button:nth-child(2)", 101, ""},
+		{"button:not(.primary)", 101, ""},
+		{"#top .a.b label", 10201, ""},
+	}
+	for _, tst := range tests {
+		sel, err := ParseCSSSelector(tst.sel)
+		if err != nil {
+			t.Errorf("ParseCSSSelector(%q): %v", tst.sel, err)
+			continue
+		}
+		if sel.Spec != tst.spec || sel.State != tst.state {
+			t.Errorf("ParseCSSSelector(%q) spec: %v state: %q, want: %v %q", tst.sel, sel.Spec, sel.State, tst.spec, tst.state)
+		}
+	}
+}
+
+// testCSSColors returns the colors of the props matching given node of the
+// tree, in order
+func testCSSColors(t *testing.T, css ki.Props, node string) []string {
+	t.Helper()
+	nd := testCSSTree()[node]
+	var cls []string
+	for _, pm := range CSSMatchingProps(nd, css, "") {
+		cls = append(cls, pm["color"].(string))
+	}
+	return cls
+}
+
+func testCSSOrder(t *testing.T, got, want []string) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Errorf("matching rules: %v, want: %v", got, want)
+		return
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			t.Errorf("matching rules: %v, want: %v", got, want)
+			return
+		}
+	}
+}
+
+func TestCSSMatchingOrder(t *testing.T) {
+	ss := &StyleSheet{}
+	err := ss.ParseString(`
+#b2 { color: id; }
+.zeta { color: zeta; }
+button { color: button; }
+.alpha { color: alpha; }
+frame > .primary { color: child; }
+.nomatch { color: none; }
+@media all {
+	.primary { color: media; }
+}
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	css := ss.CSSProps()
+	testCSSOrder(t, testCSSColors(t, css, "b2"), []string{"button", "zeta", "alpha", "media", "child", "id"})
+	testCSSOrder(t, testCSSColors(t, css, "b1"), []string{"button"})
+
+	// rules aggregated later come after earlier ones of the same
+	// specificity, including props with no source order
+	outer := &StyleSheet{}
+	outer.ParseString(".zeta { color: outer-zeta; }")
+	inner := &StyleSheet{}
+	inner.ParseString(".alpha { color: inner-alpha; } .zeta { color: inner-zeta; }")
+	var agg ki.Props
+	AggCSS(&agg, outer.CSSProps())
+	AggCSS(&agg, ki.Props{".primary": ki.Props{"color": "props"}})
+	AggCSS(&agg, inner.CSSProps())
+	testCSSOrder(t, testCSSColors(t, agg, "b2"), []string{"props", "inner-alpha", "inner-zeta"})
+	AggCSS(&agg, outer.CSSProps())
+	testCSSOrder(t, testCSSColors(t, agg, "b2"), []string{"props", "inner-alpha", "outer-zeta"})
+}
+
+func TestCSSOrder(t *testing.T) {
+	co := NewCSSOrder(nil)
+	for _, k := range []string{"a", "b", "c", "a"} {
+		co.Add(k)
+	}
+	want := []string{"b", "c", "a"}
+	if !reflect.DeepEqual(co.Keys, want) {
+		t.Errorf("CSSOrder keys: %v, want: %v", co.Keys, want)
+	}
+	for i, k := range want {
+		if got := co.Index(k); got != i {
+			t.Errorf("CSSOrder.Index(%v) = %v, want: %v", k, got, i)
+		}
+	}
+	if got := co.Index("d"); got != -1 {
+		t.Errorf("CSSOrder.Index(d) = %v, want: -1", got)
+	}
+	var nilco *CSSOrder
+	if got := nilco.Index("a"); got != -1 {
+		t.Errorf("nil CSSOrder.Index = %v, want: -1", got)
+	}
+
+	agg := ki.Props{"x": ki.Props{}, "y": ki.Props{}, CSSOrderKey: NewCSSOrder([]string{"x", "y"})}
+	css := ki.Props{"z": ki.Props{}, "x": ki.Props{}, "w": ki.Props{}, CSSOrderKey: NewCSSOrder([]string{"z", "x"})}
+	mo := cssMergeOrder(agg, css)
+	want = []string{"y", "w", "z", "x"}
+	if !reflect.DeepEqual(mo.Keys, want) {
+		t.Errorf("cssMergeOrder keys: %v, want: %v", mo.Keys, want)
+	}
+	if got := mo.Index("z"); got != 2 {
+		t.Errorf("merged CSSOrder.Index(z) = %v, want: 2", got)
+	}
+}
+
+func TestApplyCSS(t *testing.T) {
+	nds := testCSSTree()
+	css := ki.Props{
+		"button":   ki.Props{"margin": "3px", ":hover": ki.Props{"margin": "5px"}},
+		".primary": ki.Props{"margin": "4px"},
+	}
+	b1 := nds["b1"].(Node2D)
+	var s Style
+	s.Defaults()
+	if s.ApplyCSS(b1, css, "label", "", nil) {
+		t.Errorf("ApplyCSS of a missing key returned true")
+	}
+	if s.ApplyCSS(b1, css, ".primary", "", nil) {
+		t.Errorf("ApplyCSS of a rule that does not match returned true")
+	}
+	if !s.ApplyCSS(b1, css, "button", "", nil) || s.Layout.Margin.Val != 3 {
+		t.Errorf("ApplyCSS of button: margin: %v, want: 3", s.Layout.Margin.Val)
+	}
+	if !s.ApplyCSS(b1, css, "button", ":hover", nil) || s.Layout.Margin.Val != 5 {
+		t.Errorf("ApplyCSS of button :hover: margin: %v, want: 5", s.Layout.Margin.Val)
+	}
+}
@@ -0,0 +1,180 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gi
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/goki/ki"
+	"github.com/goki/ki/kit"
+)
+
+// CSSVarMaxDepth is the maximum depth of var() references within the values
+// of custom properties -- deeper references, e.g., from cycles, are not
+// resolved
+var CSSVarMaxDepth = 16
+
+// IsCSSVarKey returns true if given key of a props map defines a custom
+// property (CSS variable), e.g., "--accent-color", which can then be used in
+// the values of other properties with var(--accent-color), in the element
+// and all of its children
+func IsCSSVarKey(key string) bool {
+	return strings.HasPrefix(key, "--")
+}
+
+// CSSResolveProps returns given properties as they apply to an element in
+// given viewport, with its custom properties in vars: custom properties
+// defined in props are added to vars (as a new map, so that maps shared with
+// other elements are not affected), the properties of any @media conditions
+// in props that match the viewport (see CSSMedia) are applied over the
+// others, and var(--name, fallback) references in the values are replaced by
+// the values of the custom properties -- properties referring to undefined
+// variables without a fallback are dropped.  Returns props itself if it uses
+// none of these.
+func CSSResolveProps(props ki.Props, vars *ki.Props, vp *Viewport2D) ki.Props {
+	var vkeys, mkeys []string
+	uses := false
+	for key, val := range props {
+		switch {
+		case IsCSSVarKey(key):
+			vkeys = append(vkeys, key)
+		case IsCSSMediaKey(key):
+			mkeys = append(mkeys, key)
+		default:
+			if vs, ok := val.(string); ok && strings.Contains(vs, "var(") {
+				uses = true
+			}
+		}
+	}
+	if len(vkeys) == 0 && len(mkeys) == 0 && !uses {
+		return props
+	}
+	if len(vkeys) > 0 {
+		nv := make(ki.Props, len(*vars)+len(vkeys))
+		for key, val := range *vars {
+			nv[key] = val
+		}
+		for _, key := range vkeys {
+			nv[key] = props[key]
+		}
+		for _, key := range vkeys {
+			if val, ok := cssSubstVars(props[key], nv, 0); ok {
+				nv[key] = val
+			} else {
+				delete(nv, key)
+			}
+		}
+		*vars = nv
+	}
+	rp := make(ki.Props, len(props))
+	for key, val := range props {
+		if IsCSSVarKey(key) || IsCSSMediaKey(key) {
+			continue
+		}
+		if val, ok := cssSubstVars(val, *vars, 0); ok {
+			rp[key] = val
+		}
+	}
+	if len(mkeys) > 0 {
+		sort.Strings(mkeys)
+		md := CSSMediaFor(vp)
+		for _, key := range mkeys {
+			mp, ok := props[key].(ki.Props)
+			if !ok || !md.Matches(key) {
+				continue
+			}
+			for mk, mv := range CSSResolveProps(mp, vars, vp) {
+				rp[mk] = mv
+			}
+		}
+	}
+	return rp
+}
+
+// cssSubstVars returns given property value with its var() references
+// replaced by the values of the custom properties in vars -- a value that is
+// just one var() reference is replaced by the value of the variable as such,
+// which need not be a string -- returns false if a reference cannot be
+// resolved
+func cssSubstVars(val interface{}, vars ki.Props, depth int) (interface{}, bool) {
+	vs, ok := val.(string)
+	if !ok || !strings.Contains(vs, "var(") {
+		return val, true
+	}
+	if depth >= CSSVarMaxDepth {
+		return nil, false
+	}
+	var sb strings.Builder
+	for {
+		st := strings.Index(vs, "var(")
+		if st < 0 {
+			break
+		}
+		ed := cssCloseParen(vs, st+4)
+		if ed < 0 {
+			return nil, false
+		}
+		args := cssSplitList(vs[st+4 : ed])
+		nm := strings.TrimSpace(args[0])
+		rv, has := vars[nm]
+		switch {
+		case has:
+		case len(args) > 1:
+			rv = strings.TrimSpace(strings.Join(args[1:], ","))
+		default:
+			return nil, false
+		}
+		rv, ok = cssSubstVars(rv, vars, depth+1)
+		if !ok {
+			return nil, false
+		}
+		if st == 0 && ed == len(vs)-1 && sb.Len() == 0 {
+			return rv, true
+		}
+		sb.WriteString(vs[:st])
+		sb.WriteString(kit.ToString(rv))
+		vs = vs[ed+1:]
+	}
+	sb.WriteString(vs)
+	return sb.String(), true
+}
+
+// cssCloseParen returns the index of the parenthesis closing the one just
+// before st in str, or -1 if there is none
+func cssCloseParen(str string, st int) int {
+	depth := 1
+	for i := st; i < len(str); i++ {
+		switch str[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// inheritCSSVars returns the custom properties of an element with given own
+// variables, inheriting those of its parent
+func inheritCSSVars(own, par ki.Props) ki.Props {
+	if len(own) == 0 {
+		return par
+	}
+	if len(par) == 0 {
+		return own
+	}
+	nv := make(ki.Props, len(own)+len(par))
+	for key, val := range par {
+		nv[key] = val
+	}
+	for key, val := range own {
+		nv[key] = val
+	}
+	return nv
+}
@@ -13,6 +13,13 @@ The 2D scenegraph supports:
 	* Widget nodes for GUI actions (Buttons, Menus etc) -- render directly via Paint
 	* Layouts for placing widgets, which are also container nodes
 	* CSS-based styling, directly on Node Props (properties), and CSS StyleSheet
+      rules, with full CSS selectors (see CSSSelector), --custom properties
+      with var() (see CSSResolveProps), calc() lengths, and @media conditions
+      on window size, resolution and dark colors (see CSSMedia)
+	* Style transitions between widget states (see Transition), and animation
+      of node fields and style properties on a window ticker (see Animate)
+	* Themes bundling colors, a style sheet and a highlighting style, with
+      built-in light, dark and high-contrast ones (see Theme, Prefs.SetTheme)
 	* svg sub-package with SVG Viewport and shapes, paths, etc -- full SVG support
 	* Icon is a wrapper around an SVG -- any SVG icon can be used
 
@@ -21,7 +28,13 @@ Layout Logic
 All 2D scenegraphs are controlled by the Layout, which provides the logic for
 organizing widgets / elements within the constraints of the display.
 Typically start with a vertical LayoutVert in the viewport, with LayoutHoriz's
-within that, or a LayoutGrid for more complex layouts:
+within that, or a LayoutGrid for more complex layouts -- LayoutGridIrreg
+supports CSS-grid-style spans, track sizes and named areas, and LayoutFlex
+arranges items as a CSS flexbox, with wrapping, grow / shrink factors, gap and
+justify-content / align-content distribution.  Within any of these, children
+can be taken out of the flow or offset from it with the CSS position property
+(absolute, relative or sticky), top / right / bottom / left, z-index, and an
+anchor sibling that absolutely positioned children attach to:
 
 	win := gi.NewWindow2D("test-window", "Test Window", width, height, true)
 	vp := win.WinViewport2D()
@@ -97,6 +110,20 @@ SVG (Structured Vector Graphics) is used icons, and for rendering any kind of
 graphical output (drawing a graph, dial, etc).  See svg sub-package, and
 examples/svg for an svg viewer, and examples/marbles for an svg animation.
 
+Vector Output
+
+Paint draws through the PaintBackend of the RenderState -- the RasterBackend
+renders into the viewport image, and the VectorBackend sends the same paths,
+images and text to a VectorWriter instead.  Viewport2D.SaveVector and
+SaveNodeVector use this to save any viewport or widget (e.g., a TableView or
+a chart) as PDF or SVG, for crisp printable output.
+
+Not everything has a vector form: gradients are drawn in the average color of
+their stops, svg elements with a clip-path, mask or filter are drawn into a
+raster layer that is embedded as an image (see RenderState.DrawLayer), and
+PDF text uses the standard PDF fonts, which only have Latin-1 characters --
+any other characters are embedded as images of their glyphs.
+
 Overlay
 
 The gi.Window contains an OverlayVp viewport with nodes that are rendered on
@@ -796,6 +796,28 @@ func (fl *FontLib) DeleteFont(fontnm string) {
 	}
 }
 
+// FaceInfo returns the information about the font of given font face, and
+// its integer dots size -- the face must have been loaded through the library
+// -- ok is false if it was not found
+func (fl *FontLib) FaceInfo(face font.Face) (fi FontInfo, size int, ok bool) {
+	loadFontMu.RLock()
+	defer loadFontMu.RUnlock()
+	for nm, facemap := range fl.Faces {
+		for sz, f := range facemap {
+			if f != face {
+				continue
+			}
+			for _, fli := range fl.FontInfo {
+				if strings.ToLower(fli.Name) == nm {
+					return fli, sz, true
+				}
+			}
+			return FontInfo{Name: nm}, sz, true
+		}
+	}
+	return FontInfo{}, 0, false
+}
+
 // OpenAllFonts attempts to load all fonts that were found -- call this before
 // displaying the font chooser to eliminate any bad fonts.
 func (fl *FontLib) OpenAllFonts(size int) {
@@ -78,7 +78,7 @@ func (fr *Frame) FrameStdRender() {
 		pc.FillStrokeClear(rs)
 	}
 
-	if fr.Lay == LayoutGrid && fr.Stripes != NoStripes {
+	if (fr.Lay == LayoutGrid || fr.Lay == LayoutGridIrreg) && fr.Stripes != NoStripes {
 		fr.RenderStripes()
 	}
 
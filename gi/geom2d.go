@@ -658,6 +658,21 @@ func (a Matrix2D) ExtractScale() (scx, scy float32) {
 	return
 }
 
+// Inverse returns the inverse of the matrix, which undoes its transform --
+// returns the identity if the matrix is not invertible
+func (a Matrix2D) Inverse() Matrix2D {
+	det := a.XX*a.YY - a.XY*a.YX
+	if det == 0 {
+		return Identity2D()
+	}
+	id := 1 / det
+	return Matrix2D{
+		a.YY * id, -a.YX * id,
+		-a.XY * id, a.XX * id,
+		(a.XY*a.Y0 - a.YY*a.X0) * id, (a.YX*a.X0 - a.XX*a.Y0) * id,
+	}
+}
+
 // ParseFloat32 logs any strconv.ParseFloat errors
 func ParseFloat32(pstr string) (float32, error) {
 	r, err := strconv.ParseFloat(pstr, 32)
@@ -778,7 +793,7 @@ func (a *Matrix2D) SetString(str string) error {
 				log.Println(err)
 				return err
 			}
-			*a = Matrix2D{pts[0], pts[1], pts[2], pts[3], pts[4], pts[5]}
+			*a = Matrix2D{pts[0], pts[1], pts[2], pts[3], pts[4], pts[5]}.Multiply(*a)
 		case "translate":
 			if err := PointsCheckN(pts, 2, errmsg); err != nil {
 				log.Println(err)
@@ -82,6 +82,9 @@ const (
 	KeyFunHistPrev
 	KeyFunHistNext
 	KeyFunWinFocusNext
+	KeyFunCursorNextMatch // add a cursor at the next match of the selection
+	KeyFunSplitCursors    // split the selection into a cursor on each line
+	KeyFunRectSelect      // toggle rectangular block selection
 	// Below are menu specific functions -- use these as shortcuts for menu actions
 	// allows uniqueness of mapping and easy customization of all key actions
 	KeyFunMenuNew
@@ -586,6 +589,9 @@ var StdKeyMaps = KeyMaps{
 		"Meta+[":                  KeyFunHistPrev,
 		"Meta+]":                  KeyFunHistNext,
 		"Meta+`":                  KeyFunWinFocusNext,
+		"Meta+D":                  KeyFunCursorNextMatch,
+		"Shift+Meta+L":            KeyFunSplitCursors,
+		"Alt+Meta+B":              KeyFunRectSelect,
 		"Meta+N":                  KeyFunMenuNew,
 		"Shift+Meta+N":            KeyFunMenuNewAlt1,
 		"Alt+Meta+N":              KeyFunMenuNewAlt2,
@@ -714,6 +720,9 @@ var StdKeyMaps = KeyMaps{
 		"Meta+[":                  KeyFunHistPrev,
 		"Meta+]":                  KeyFunHistNext,
 		"Meta+`":                  KeyFunWinFocusNext,
+		"Meta+D":                  KeyFunCursorNextMatch,
+		"Shift+Meta+L":            KeyFunSplitCursors,
+		"Alt+Meta+B":              KeyFunRectSelect,
 		"Meta+N":                  KeyFunMenuNew,
 		"Shift+Meta+N":            KeyFunMenuNewAlt1,
 		"Alt+Meta+N":              KeyFunMenuNewAlt2,
@@ -827,6 +836,9 @@ var StdKeyMaps = KeyMaps{
 		"Control+[":               KeyFunHistPrev,
 		"Control+]":               KeyFunHistNext,
 		"Alt+F6":                  KeyFunWinFocusNext,
+		"Control+Alt+D":           KeyFunCursorNextMatch,
+		"Shift+Alt+I":             KeyFunSplitCursors,
+		"Control+Alt+B":           KeyFunRectSelect,
 		"Alt+N":                   KeyFunMenuNew, // ctrl keys conflict..
 		"Shift+Alt+N":             KeyFunMenuNewAlt1,
 		"Control+Alt+N":           KeyFunMenuNewAlt2,
@@ -910,6 +922,9 @@ var StdKeyMaps = KeyMaps{
 		"Control+]":               KeyFunHistNext,
 		"Control+N":               KeyFunMenuNew,
 		"Alt+F6":                  KeyFunWinFocusNext,
+		"Control+Alt+D":           KeyFunCursorNextMatch,
+		"Shift+Alt+I":             KeyFunSplitCursors,
+		"Control+Alt+B":           KeyFunRectSelect,
 		"Shift+Control+N":         KeyFunMenuNewAlt1,
 		"Control+Alt+N":           KeyFunMenuNewAlt2,
 		"Control+O":               KeyFunMenuOpen,
@@ -991,6 +1006,9 @@ var StdKeyMaps = KeyMaps{
 		"Control+[":               KeyFunHistPrev,
 		"Control+]":               KeyFunHistNext,
 		"Alt+F6":                  KeyFunWinFocusNext,
+		"Control+Alt+D":           KeyFunCursorNextMatch,
+		"Shift+Alt+I":             KeyFunSplitCursors,
+		"Control+Alt+B":           KeyFunRectSelect,
 		"Control+N":               KeyFunMenuNew,
 		"Shift+Control+N":         KeyFunMenuNewAlt1,
 		"Control+Alt+N":           KeyFunMenuNewAlt2,
@@ -1073,6 +1091,9 @@ var StdKeyMaps = KeyMaps{
 		"Control+[":               KeyFunHistPrev,
 		"Control+]":               KeyFunHistNext,
 		"Alt+F6":                  KeyFunWinFocusNext,
+		"Control+Alt+D":           KeyFunCursorNextMatch,
+		"Shift+Alt+I":             KeyFunSplitCursors,
+		"Control+Alt+B":           KeyFunRectSelect,
 		"Control+N":               KeyFunMenuNew,
 		"Shift+Control+N":         KeyFunMenuNewAlt1,
 		"Control+Alt+N":           KeyFunMenuNewAlt2,
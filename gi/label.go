@@ -163,14 +163,14 @@ func (lb *Label) Label() string {
 // SetStateStyle sets the style based on the inactive, selected flags
 func (lb *Label) SetStateStyle() {
 	if lb.IsInactive() {
-		lb.Sty = lb.StateStyles[LabelInactive]
+		lb.TransitionStyle(&lb.StateStyles[LabelInactive])
 		if lb.Redrawable && !lb.CurBgColor.IsNil() {
 			lb.Sty.Font.BgColor.SetColor(lb.CurBgColor)
 		}
 	} else if lb.IsSelected() {
-		lb.Sty = lb.StateStyles[LabelSelected]
+		lb.TransitionStyle(&lb.StateStyles[LabelSelected])
 	} else {
-		lb.Sty = lb.StateStyles[LabelActive]
+		lb.TransitionStyle(&lb.StateStyles[LabelActive])
 		if (lb.Selectable || lb.Redrawable) && !lb.CurBgColor.IsNil() {
 			lb.Sty.Font.BgColor.SetColor(lb.CurBgColor)
 		}
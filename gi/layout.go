@@ -24,10 +24,7 @@ import (
 
 // todo: for style
 // Align = layouts
-// Flex -- flexbox -- https://www.w3schools.com/css/css3_flexbox.asp -- key to look at further for layout ideas
-// as is Position -- absolute, sticky, etc
 // Resize: user-resizability
-// z-index
 
 // CSS vs. Layout alignment
 //
@@ -42,26 +39,49 @@ import (
 
 // LayoutStyle contains style preferences on the layout of the element.
 type LayoutStyle struct {
-	ZIndex         int         `xml:"z-index" desc:"prop: z-index = ordering factor for rendering depth -- lower numbers rendered first -- sort children according to this factor"`
-	AlignH         Align       `xml:"horizontal-align" desc:"prop: horizontal-align = horizontal alignment -- for widget layouts -- not a standard css property"`
-	AlignV         Align       `xml:"vertical-align" desc:"prop: vertical-align = vertical alignment -- for widget layouts -- not a standard css property"`
-	PosX           units.Value `xml:"x" desc:"prop: x = horizontal position -- often superseded by layout but otherwise used"`
-	PosY           units.Value `xml:"y" desc:"prop: y = vertical position -- often superseded by layout but otherwise used"`
-	Width          units.Value `xml:"width" desc:"prop: width = specified size of element -- 0 if not specified"`
-	Height         units.Value `xml:"height" desc:"prop: height = specified size of element -- 0 if not specified"`
-	MaxWidth       units.Value `xml:"max-width" desc:"prop: max-width = specified maximum size of element -- 0  means just use other values, negative means stretch"`
-	MaxHeight      units.Value `xml:"max-height" desc:"prop: max-height = specified maximum size of element -- 0 means just use other values, negative means stretch"`
-	MinWidth       units.Value `xml:"min-width" desc:"prop: min-width = specified minimum size of element -- 0 if not specified"`
-	MinHeight      units.Value `xml:"min-height" desc:"prop: min-height = specified minimum size of element -- 0 if not specified"`
-	Margin         units.Value `xml:"margin" desc:"prop: margin = outer-most transparent space around box element -- todo: can be specified per side"`
-	Padding        units.Value `xml:"padding" desc:"prop: padding = transparent space around central content of box -- todo: if 4 values it is top, right, bottom, left; 3 is top, right&left, bottom; 2 is top & bottom, right and left"`
-	Overflow       Overflow    `xml:"overflow" desc:"prop: overflow = what to do with content that overflows -- default is Auto add of scrollbars as needed -- todo: can have separate -x -y values"`
-	Columns        int         `xml:"columns" alt:"grid-cols" desc:"prop: columns = number of columns to use in a grid layout -- used as a constraint in layout if individual elements do not specify their row, column positions"`
-	Row            int         `xml:"row" desc:"prop: row = specifies the row that this element should appear within a grid layout"`
-	Col            int         `xml:"col" desc:"prop: col = specifies the column that this element should appear within a grid layout"`
-	RowSpan        int         `xml:"row-span" desc:"prop: row-span = specifies the number of sequential rows that this element should occupy within a grid layout (todo: not currently supported)"`
-	ColSpan        int         `xml:"col-span" desc:"prop: col-span = specifies the number of sequential columns that this element should occupy within a grid layout"`
-	ScrollBarWidth units.Value `xml:"scrollbar-width" desc:"prop: scrollbar-width = width of a layout scrollbar"`
+	ZIndex              int            `xml:"z-index" desc:"prop: z-index = ordering factor for rendering depth -- lower numbers rendered first -- sort children according to this factor"`
+	Position            Positions      `xml:"position" desc:"prop: position = how the element is positioned within its parent layout: static (in flow), relative (in flow, then offset by top, left etc), absolute (out of flow, at top, left etc within the layout, or its anchor) or sticky (in flow, but kept at least top, left etc within the visible part of the layout as it scrolls) -- positioned elements render above static ones at the same z-index"`
+	Top                 string         `xml:"top" desc:"prop: top = offset of the top edge of a positioned element from the top of its containing block (or the visible part of the layout, if sticky), as a length or percent of the height of the containing block -- empty or auto if not set"`
+	Right               string         `xml:"right" desc:"prop: right = offset of the right edge of a positioned element from the right of its containing block, as for top"`
+	Bottom              string         `xml:"bottom" desc:"prop: bottom = offset of the bottom edge of a positioned element from the bottom of its containing block, as for top"`
+	Left                string         `xml:"left" desc:"prop: left = offset of the left edge of a positioned element from the left of its containing block, as for top"`
+	Anchor              string         `xml:"anchor" desc:"prop: anchor = name of a sibling element that an element with absolute position is attached to -- its top, left etc are then relative to the box of the anchor instead of the layout, e.g., top: 100% places it just below the anchor, and top: 0; right: 0 at its top right corner, as for badges -- it still scrolls and clips with the layout"`
+	AlignH              Align          `xml:"horizontal-align" desc:"prop: horizontal-align = horizontal alignment -- for widget layouts -- not a standard css property"`
+	AlignV              Align          `xml:"vertical-align" desc:"prop: vertical-align = vertical alignment -- for widget layouts -- not a standard css property"`
+	PosX                units.Value    `xml:"x" desc:"prop: x = horizontal position -- often superseded by layout but otherwise used"`
+	PosY                units.Value    `xml:"y" desc:"prop: y = vertical position -- often superseded by layout but otherwise used"`
+	Width               units.Value    `xml:"width" desc:"prop: width = specified size of element -- 0 if not specified"`
+	Height              units.Value    `xml:"height" desc:"prop: height = specified size of element -- 0 if not specified"`
+	MaxWidth            units.Value    `xml:"max-width" desc:"prop: max-width = specified maximum size of element -- 0  means just use other values, negative means stretch"`
+	MaxHeight           units.Value    `xml:"max-height" desc:"prop: max-height = specified maximum size of element -- 0 means just use other values, negative means stretch"`
+	MinWidth            units.Value    `xml:"min-width" desc:"prop: min-width = specified minimum size of element -- 0 if not specified"`
+	MinHeight           units.Value    `xml:"min-height" desc:"prop: min-height = specified minimum size of element -- 0 if not specified"`
+	Margin              units.Value    `xml:"margin" desc:"prop: margin = outer-most transparent space around box element -- todo: can be specified per side"`
+	Padding             units.Value    `xml:"padding" desc:"prop: padding = transparent space around central content of box -- todo: if 4 values it is top, right, bottom, left; 3 is top, right&left, bottom; 2 is top & bottom, right and left"`
+	Overflow            Overflow       `xml:"overflow" desc:"prop: overflow = what to do with content that overflows -- default is Auto add of scrollbars as needed -- todo: can have separate -x -y values"`
+	Columns             int            `xml:"columns" alt:"grid-cols" desc:"prop: columns = number of columns to use in a grid layout -- used as a constraint in layout if individual elements do not specify their row, column positions"`
+	Row                 int            `xml:"row" desc:"prop: row = specifies the row that this element should appear within a grid layout"`
+	Col                 int            `xml:"col" desc:"prop: col = specifies the column that this element should appear within a grid layout"`
+	RowSpan             int            `xml:"row-span" desc:"prop: row-span = specifies the number of sequential rows that this element should occupy within a grid layout (only supported in LayoutGridIrreg)"`
+	ColSpan             int            `xml:"col-span" desc:"prop: col-span = specifies the number of sequential columns that this element should occupy within a grid layout"`
+	GridTemplateColumns string         `xml:"grid-template-columns" desc:"prop: grid-template-columns = sizes of the columns of an irregular grid layout (LayoutGridIrreg), as a space-separated list of lengths, auto, fractions of the leftover space in fr units, minmax(min, max) and repeat(n, sizes), e.g., 10em 1fr minmax(100px, 2fr) -- columns beyond those listed are auto"`
+	GridTemplateRows    string         `xml:"grid-template-rows" desc:"prop: grid-template-rows = sizes of the rows of an irregular grid layout (LayoutGridIrreg), as for grid-template-columns -- rows beyond those listed are auto"`
+	GridTemplateAreas   string         `xml:"grid-template-areas" desc:"prop: grid-template-areas = named areas of an irregular grid layout (LayoutGridIrreg), as one quoted string per row, with the area name of each column, or . for none, e.g., 'head head' 'side main' -- elements are placed in an area by naming it in their grid-area, grid-row or grid-column"`
+	GridRow             string         `xml:"grid-row" desc:"prop: grid-row = rows that this element occupies within an irregular grid layout, as start / end grid lines counting from 1 (negative counts back from the end), either of which can be span n, or an area name, e.g., 2 / span 3 -- overrides row and row-span"`
+	GridColumn          string         `xml:"grid-column" desc:"prop: grid-column = columns that this element occupies within an irregular grid layout, as for grid-row -- overrides col and col-span"`
+	GridArea            string         `xml:"grid-area" desc:"prop: grid-area = name of the grid-template-areas area that this element occupies within an irregular grid layout, or its row-start / column-start / row-end / column-end grid lines -- overrides grid-row and grid-column"`
+	FlexDirection       FlexDirections `xml:"flex-direction" desc:"prop: flex-direction = main axis of a flex layout (LayoutFlex) along which items are placed: row, row-reverse, column or column-reverse"`
+	FlexWrap            FlexWraps      `xml:"flex-wrap" desc:"prop: flex-wrap = whether the items of a flex layout wrap onto multiple lines when they do not fit along the main axis: nowrap, wrap or wrap-reverse"`
+	JustifyContent      Align          `xml:"justify-content" desc:"prop: justify-content = how extra space along the main axis of a flex layout is distributed, when no item grows: flex-start, flex-end, center, space-between, space-around or space-evenly"`
+	AlignItems          Align          `xml:"align-items" desc:"prop: align-items = default alignment of the items of a flex layout within their line, along the cross axis: stretch, flex-start, flex-end, center or baseline"`
+	AlignContent        Align          `xml:"align-content" desc:"prop: align-content = how extra space along the cross axis of a wrapping flex layout is distributed among its lines: stretch, flex-start, flex-end, center, space-between, space-around or space-evenly"`
+	Gap                 units.Value    `xml:"gap" desc:"prop: gap = space between the items and lines of a flex layout -- if 0, the spacing of the layout is used"`
+	FlexGrow            float32        `xml:"flex-grow" desc:"prop: flex-grow = share of the extra space along the main axis of a flex layout that this element grows by, relative to the other items -- if no item grows, those with stretchy max sizes grow equally"`
+	FlexShrink          float32        `xml:"flex-shrink" desc:"prop: flex-shrink = share of the missing space along the main axis of a flex layout that this element shrinks by, relative to the other items, weighted by their sizes -- never below its min size"`
+	FlexBasis           units.Value    `xml:"flex-basis" desc:"prop: flex-basis = initial size of this element along the main axis of a flex layout, before growing or shrinking -- 0 for its preferred size"`
+	AlignSelf           Align          `xml:"align-self" desc:"prop: align-self = alignment of this element within its line of a flex layout, along the cross axis -- auto for the align-items of the layout"`
+	Order               int            `xml:"order" desc:"prop: order = order of this element within a flex layout, relative to the other items, which otherwise keep their order as children"`
+	ScrollBarWidth      units.Value    `xml:"scrollbar-width" desc:"prop: scrollbar-width = width of a layout scrollbar"`
 }
 
 func (ls *LayoutStyle) Defaults() {
@@ -69,6 +89,10 @@ func (ls *LayoutStyle) Defaults() {
 	ls.MinWidth.Set(2.0, units.Px)
 	ls.MinHeight.Set(2.0, units.Px)
 	ls.ScrollBarWidth.Set(16.0, units.Px)
+	ls.AlignItems = AlignStretch
+	ls.AlignContent = AlignStretch
+	ls.FlexShrink = 1
+	ls.AlignSelf = AlignAuto
 }
 
 func (ls *LayoutStyle) SetStylePost(props ki.Props) {
@@ -130,6 +154,14 @@ const (
 	AlignSub
 	// align to superscript
 	AlignSuper
+	// same as AlignJustify -- CSS name, for justify-content and align-content
+	AlignSpaceBetween
+	// equal space between items and before the first and after the last one
+	AlignSpaceEvenly
+	// stretch to fill the available space, for align-items and align-content
+	AlignStretch
+	// use the alignment of the container, for align-self
+	AlignAuto
 	AlignN
 )
 
@@ -214,18 +246,18 @@ func (sp SizePrefs) CanStretchNeed(d Dims2D) bool {
 // within a layout -- includes computed values of style prefs -- everything is
 // concrete and specified here, whereas style may not be fully resolved
 type LayoutData struct {
-	Size          SizePrefs `desc:"size constraints for this item -- from layout style"`
-	AllocSize     Vec2D     `desc:"allocated size of this item, by the parent layout"`
-	AllocPos      Vec2D     `desc:"position of this item, computed by adding in the AllocPosRel to parent position"`
-	AllocPosRel   Vec2D     `desc:"allocated relative position of this item, computed by the parent layout"`
-	AllocSizeOrig Vec2D     `desc:"original copy of allocated size of this item, by the parent layout -- some widgets will resize themselves within a given layout (e.g., a TextView), but still need access to their original allocated size"`
-	AllocPosOrig  Vec2D     `desc:"original copy of allocated relative position of this item, by the parent layout -- need for scrolling which can update AllocPos"`
+	Size          SizePrefs   `desc:"size constraints for this item -- from layout style"`
+	AllocSize     Vec2D       `desc:"allocated size of this item, by the parent layout"`
+	AllocPos      Vec2D       `desc:"position of this item, computed by adding in the AllocPosRel to parent position"`
+	AllocPosRel   Vec2D       `desc:"allocated relative position of this item, computed by the parent layout"`
+	AllocSizeOrig Vec2D       `desc:"original copy of allocated size of this item, by the parent layout -- some widgets will resize themselves within a given layout (e.g., a TextView), but still need access to their original allocated size"`
+	AllocPosOrig  Vec2D       `desc:"original copy of allocated relative position of this item, by the parent layout -- need for scrolling which can update AllocPos"`
+	GridPos       image.Point `desc:"position within an irregular grid layout, column in X and row in Y -- computed by the parent layout"`
+	GridSpan      image.Point `desc:"number of grid columns (X) and rows (Y) that we take up within an irregular grid layout -- computed by the parent layout"`
 }
 
 // todo: not using yet:
 // Margins Margins   `desc:"margins around this item"`
-// GridPos      image.Point `desc:"position within a grid"`
-// GridSpan     image.Point `desc:"number of grid elements that we take up in each direction"`
 
 func (ld *LayoutData) Defaults() {
 }
@@ -294,20 +326,21 @@ var LayoutFocusNameTabMSec = 2000
 // can automatically add scrollbars depending on the Overflow layout style.
 type Layout struct {
 	WidgetBase
-	Lay           Layouts             `xml:"lay" desc:"type of layout to use"`
-	Spacing       units.Value         `xml:"spacing" desc:"extra space to add between elements in the layout"`
-	StackTop      int                 `desc:"for Stacked layout, index of node to use as the top of the stack -- only node at this index is rendered -- if not a valid index, nothing is rendered"`
-	ChildSize     Vec2D               `json:"-" xml:"-" desc:"total max size of children as laid out"`
-	ExtraSize     Vec2D               `json:"-" xml:"-" desc:"extra size in each dim due to scrollbars we add"`
-	HasScroll     [Dims2DN]bool       `json:"-" xml:"-" desc:"whether scrollbar is used for given dim"`
-	Scrolls       [Dims2DN]*ScrollBar `json:"-" xml:"-" desc:"scroll bars -- we fully manage them as needed"`
-	GridSize      image.Point         `json:"-" xml:"-" desc:"computed size of a grid layout based on all the constraints -- computed during Size2D pass"`
-	GridData      [RowColN][]GridData `json:"-" xml:"-" desc:"grid data for rows in [0] and cols in [1]"`
-	NeedsRedo     bool                `json:"-" xml:"-" desc:"true if this layout got a redo = true on previous iteration -- otherwise it just skips any re-layout on subsequent iteration"`
-	FocusName     string              `json:"-" xml:"-" desc:"accumulated name to search for when keys are typed"`
-	FocusNameTime time.Time           `json:"-" xml:"-" desc:"time of last focus name event -- for timeout"`
-	FocusNameLast ki.Ki               `json:"-" xml:"-" desc:"last element focused on -- used as a starting point if name is the same"`
-	ScrollsOff    bool                `json:"-" xml:"-" desc:"scrollbars have been manually turned off due to layout being invisible -- must be reactivated when re-visible"`
+	Lay           Layouts              `xml:"lay" desc:"type of layout to use"`
+	Spacing       units.Value          `xml:"spacing" desc:"extra space to add between elements in the layout"`
+	StackTop      int                  `desc:"for Stacked layout, index of node to use as the top of the stack -- only node at this index is rendered -- if not a valid index, nothing is rendered"`
+	ChildSize     Vec2D                `json:"-" xml:"-" desc:"total max size of children as laid out"`
+	ExtraSize     Vec2D                `json:"-" xml:"-" desc:"extra size in each dim due to scrollbars we add"`
+	HasScroll     [Dims2DN]bool        `json:"-" xml:"-" desc:"whether scrollbar is used for given dim"`
+	Scrolls       [Dims2DN]*ScrollBar  `json:"-" xml:"-" desc:"scroll bars -- we fully manage them as needed"`
+	GridSize      image.Point          `json:"-" xml:"-" desc:"computed size of a grid layout based on all the constraints -- computed during Size2D pass"`
+	GridData      [RowColN][]GridData  `json:"-" xml:"-" desc:"grid data for rows in [0] and cols in [1]"`
+	GridTracks    [RowColN][]GridTrack `json:"-" xml:"-" desc:"track sizes of an irregular grid layout, for rows in [0] and cols in [1] -- computed from the grid-template-rows and -columns styles"`
+	NeedsRedo     bool                 `json:"-" xml:"-" desc:"true if this layout got a redo = true on previous iteration -- otherwise it just skips any re-layout on subsequent iteration"`
+	FocusName     string               `json:"-" xml:"-" desc:"accumulated name to search for when keys are typed"`
+	FocusNameTime time.Time            `json:"-" xml:"-" desc:"time of last focus name event -- for timeout"`
+	FocusNameLast ki.Ki                `json:"-" xml:"-" desc:"last element focused on -- used as a starting point if name is the same"`
+	ScrollsOff    bool                 `json:"-" xml:"-" desc:"scrollbars have been manually turned off due to layout being invisible -- must be reactivated when re-visible"`
 }
 
 var KiT_Layout = kit.Types.AddType(&Layout{}, nil)
@@ -325,9 +358,6 @@ const (
 	// LayoutGrid arranges items according to a regular grid
 	LayoutGrid
 
-	// todo: add LayoutGridIrreg that deals with irregular grids with spans etc -- keep
-	// the basic grid for fully regular cases -- need high performance for large grids
-
 	// LayoutHorizFlow arranges items horizontally across a row, overflowing
 	// vertically as needed
 	LayoutHorizFlow
@@ -345,6 +375,21 @@ const (
 	// parent wants to take over the job of the layout
 	LayoutNil
 
+	// LayoutGridIrreg arranges items in an irregular grid, as in a CSS grid:
+	// items can span several rows and columns (grid-row, grid-column), or be
+	// placed in named areas (grid-template-areas), and the rows and columns
+	// can have fixed, content, fractional or min / max sizes
+	// (grid-template-rows, grid-template-columns) -- use LayoutGrid for
+	// fully regular cases, which is faster for large grids
+	LayoutGridIrreg
+
+	// LayoutFlex arranges items as in a CSS flexbox, along a row or column
+	// (flex-direction), optionally wrapping onto multiple lines (flex-wrap),
+	// with items growing and shrinking to fill the space (flex-grow,
+	// flex-shrink, flex-basis), and any extra space distributed according to
+	// justify-content, align-items and align-content, with gap between them
+	LayoutFlex
+
 	LayoutsN
 )
 
@@ -415,13 +460,14 @@ func (ly *Layout) SummedDim() Dims2D {
 
 // GatherSizes is size first pass: gather the size information from the children
 func (ly *Layout) GatherSizes() {
-	sz := len(ly.Kids)
+	kids := ly.FlowKids()
+	sz := len(kids)
 	if sz == 0 {
 		return
 	}
 
 	var sumPref, sumNeed, maxPref, maxNeed Vec2D
-	for _, c := range ly.Kids {
+	for _, c := range kids {
 		ni := c.(Node2D).AsWidget()
 		if ni == nil {
 			continue
@@ -479,16 +525,17 @@ func (ly *Layout) GatherSizes() {
 // GatherSizesGrid is size first pass: gather the size information from the
 // children, grid version
 func (ly *Layout) GatherSizesGrid() {
-	if len(ly.Kids) == 0 {
+	kids := ly.FlowKids()
+	if len(kids) == 0 {
 		return
 	}
 
 	cols := ly.Sty.Layout.Columns
 	rows := 0
 
-	sz := len(ly.Kids)
+	sz := len(kids)
 	// collect overall size
-	for _, c := range ly.Kids {
+	for _, c := range kids {
 		ni := c.(Node2D).AsWidget()
 		if ni == nil {
 			continue
@@ -535,7 +582,7 @@ func (ly *Layout) GatherSizesGrid() {
 
 	col := 0
 	row := 0
-	for _, c := range ly.Kids {
+	for _, c := range kids {
 		ni := c.(Node2D).AsWidget()
 		if ni == nil {
 			continue
@@ -721,7 +768,7 @@ func (ly *Layout) LayoutSharedDimImpl(avail, need, pref, max, spc float32, al Al
 func (ly *Layout) LayoutSharedDim(dim Dims2D) {
 	spc := ly.Sty.BoxSpace()
 	avail := ly.LayData.AllocSize.Dim(dim) - 2.0*spc
-	for _, c := range ly.Kids {
+	for _, c := range ly.FlowKids() {
 		ni := c.(Node2D).AsWidget()
 		if ni == nil {
 			continue
@@ -739,7 +786,8 @@ func (ly *Layout) LayoutSharedDim(dim Dims2D) {
 // LayoutAlongDim lays out all children along given dim -- only affects that dim --
 // e.g., use LayoutSharedDim for other dim.
 func (ly *Layout) LayoutAlongDim(dim Dims2D) {
-	sz := len(ly.Kids)
+	kids := ly.FlowKids()
+	sz := len(kids)
 	if sz == 0 {
 		return
 	}
@@ -768,7 +816,7 @@ func (ly *Layout) LayoutAlongDim(dim Dims2D) {
 	stretchMax := false         // only stretch Max = neg
 	addSpace := false           // apply extra toward spacing -- for justify
 	if usePref && extra > 0.0 { // have some stretch extra
-		for _, c := range ly.Kids {
+		for _, c := range kids {
 			ni := c.(Node2D).AsWidget()
 			if ni == nil {
 				continue
@@ -782,7 +830,7 @@ func (ly *Layout) LayoutAlongDim(dim Dims2D) {
 			stretchMax = true // only stretch those marked as infinitely stretchy
 		}
 	} else if extra > 0.0 { // extra relative to Need
-		for _, c := range ly.Kids {
+		for _, c := range kids {
 			ni := c.(Node2D).AsWidget()
 			if ni == nil {
 				continue
@@ -816,7 +864,7 @@ func (ly *Layout) LayoutAlongDim(dim Dims2D) {
 		fmt.Printf("Layout: %v Along dim %v, avail: %v elspc: %v need: %v pref: %v targ: %v, extra %v, strMax: %v, strNeed: %v, nstr %v, strTot %v\n", ly.PathUnique(), dim, avail, elspc, need, pref, targ, extra, stretchMax, stretchNeed, nstretch, stretchTot)
 	}
 
-	for i, c := range ly.Kids {
+	for i, c := range kids {
 		ni := c.(Node2D).AsWidget()
 		if ni == nil {
 			continue
@@ -952,7 +1000,8 @@ func (ly *Layout) LayoutGridDim(rowcol RowCol, dim Dims2D) {
 
 // LayoutGrid manages overall grid layout of children
 func (ly *Layout) LayoutGrid() {
-	sz := len(ly.Kids)
+	kids := ly.FlowKids()
+	sz := len(kids)
 	if sz == 0 {
 		return
 	}
@@ -964,7 +1013,7 @@ func (ly *Layout) LayoutGrid() {
 	row := 0
 	cols := ly.GridSize.X
 	rows := ly.GridSize.Y
-	for _, c := range ly.Kids {
+	for _, c := range kids {
 		ni := c.(Node2D).AsWidget()
 		if ni == nil {
 			continue
@@ -1281,7 +1330,7 @@ func (ly *Layout) Render2DChildren() {
 		}
 		// note: all nodes need to render to disconnect b/c of invisible
 	}
-	for _, kid := range ly.Kids {
+	for _, kid := range ly.RenderOrder() {
 		nii, _ := KiToNode2D(kid)
 		if nii != nil {
 			nii.Render2D()
@@ -1301,7 +1350,12 @@ func (ly *Layout) Move2DChildren(delta image.Point) {
 	} else {
 		for _, kid := range ly.Kids {
 			nii, _ := KiToNode2D(kid)
-			if nii != nil {
+			if nii == nil {
+				continue
+			}
+			if wb := nii.AsWidget(); wb != nil && wb.Sty.Layout.Position == PositionSticky {
+				nii.Move2D(ly.StickyDelta(wb, delta, cbb), cbb)
+			} else {
 				nii.Move2D(delta, cbb)
 			}
 		}
@@ -1560,6 +1614,11 @@ func (ly *Layout) FocusNextChild(updn bool) bool {
 	if ly.Lay == LayoutGrid && updn {
 		nxti = idx + ly.Sty.Layout.Columns
 	}
+	if ly.Lay == LayoutGridIrreg && updn {
+		if nxti = ly.GridIrregVertChild(idx, false); nxti < 0 {
+			nxti = sz
+		}
+	}
 	did := false
 	if nxti < sz {
 		did = win.FocusOnOrNext(ly.Child(nxti))
@@ -1589,6 +1648,9 @@ func (ly *Layout) FocusPrevChild(updn bool) bool {
 	if ly.Lay == LayoutGrid && updn {
 		nxti = idx - ly.Sty.Layout.Columns
 	}
+	if ly.Lay == LayoutGridIrreg && updn {
+		nxti = ly.GridIrregVertChild(idx, true)
+	}
 	did := false
 	if nxti >= 0 {
 		did = win.FocusOnOrPrev(ly.Child(nxti))
@@ -1610,7 +1672,7 @@ func (ly *Layout) LayoutKeys(kt *key.ChordEvent) {
 		fmt.Printf("Layout KeyInput: %v\n", ly.PathUnique())
 	}
 	kf := KeyFun(kt.Chord())
-	if ly.Lay == LayoutHoriz || ly.Lay == LayoutGrid || ly.Lay == LayoutHorizFlow {
+	if ly.Lay == LayoutHoriz || ly.Lay == LayoutGrid || ly.Lay == LayoutGridIrreg || ly.Lay == LayoutHorizFlow || ly.Lay == LayoutFlex {
 		switch kf {
 		case KeyFunMoveRight:
 			if ly.FocusNextChild(false) { // allow higher layers to try..
@@ -1624,7 +1686,7 @@ func (ly *Layout) LayoutKeys(kt *key.ChordEvent) {
 			return
 		}
 	}
-	if ly.Lay == LayoutVert || ly.Lay == LayoutGrid || ly.Lay == LayoutVertFlow {
+	if ly.Lay == LayoutVert || ly.Lay == LayoutGrid || ly.Lay == LayoutGridIrreg || ly.Lay == LayoutVertFlow || ly.Lay == LayoutFlex {
 		switch kf {
 		case KeyFunMoveDown:
 			if ly.FocusNextChild(true) {
@@ -1819,9 +1881,14 @@ func (ly *Layout) Style2D() {
 
 func (ly *Layout) Size2D(iter int) {
 	ly.InitLayout2D()
-	if ly.Lay == LayoutGrid {
+	switch ly.Lay {
+	case LayoutGrid:
 		ly.GatherSizesGrid()
-	} else {
+	case LayoutGridIrreg:
+		ly.GatherSizesGridIrreg()
+	case LayoutFlex:
+		ly.GatherSizesFlex()
+	default:
 		ly.GatherSizes()
 	}
 }
@@ -1834,6 +1901,7 @@ func (ly *Layout) Layout2D(parBBox image.Rectangle, iter int) bool {
 	//}
 	ly.AllocFromParent()                 // in case we didn't get anything
 	ly.Layout2DBase(parBBox, true, iter) // init style
+	flexRedo := false
 	switch ly.Lay {
 	case LayoutHoriz:
 		ly.LayoutAlongDim(X)
@@ -1843,19 +1911,26 @@ func (ly *Layout) Layout2D(parBBox image.Rectangle, iter int) bool {
 		ly.LayoutSharedDim(X)
 	case LayoutGrid:
 		ly.LayoutGrid()
+	case LayoutGridIrreg:
+		ly.LayoutGridIrreg()
+	case LayoutFlex:
+		flexRedo = ly.LayoutFlex() && iter == 0 // wrapped lines need more room
 	case LayoutStacked:
 		ly.LayoutSharedDim(X)
 		ly.LayoutSharedDim(Y)
 	case LayoutNil:
 		// nothing
 	}
+	if ly.Lay != LayoutNil {
+		ly.LayoutPositioned()
+	}
 	ly.FinalizeLayout()
 	ly.ManageOverflow()
-	ly.NeedsRedo = ly.Layout2DChildren(iter) // layout done with canonical positions
+	ly.NeedsRedo = ly.Layout2DChildren(iter) || flexRedo // layout done with canonical positions
 
 	if !ly.NeedsRedo || iter == 1 {
 		delta := ly.Move2DDelta(image.ZP)
-		if delta != image.ZP {
+		if delta != image.ZP || ly.HasPosition(PositionSticky) {
 			ly.Move2DChildren(delta) // move is a separate step
 		}
 	}
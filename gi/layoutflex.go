@@ -0,0 +1,454 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gi
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/goki/ki/kit"
+)
+
+////////////////////////////////////////////////////////////////////////////////////////
+//  Flex styles
+
+// FlexDirections are the directions of the main axis of a flex layout
+// (LayoutFlex), along which its items are placed, as for the flex-direction
+// CSS property
+type FlexDirections int32
+
+const (
+	// place items from left to right
+	FlexRow FlexDirections = iota
+	// place items from right to left
+	FlexRowReverse
+	// place items from top to bottom
+	FlexColumn
+	// place items from bottom to top
+	FlexColumnReverse
+	FlexDirectionsN
+)
+
+//go:generate stringer -type=FlexDirections
+
+var KiT_FlexDirections = kit.Enums.AddEnumAltLower(FlexDirectionsN, false, StylePropProps, "Flex")
+
+func (ev FlexDirections) MarshalJSON() ([]byte, error)  { return kit.EnumMarshalJSON(ev) }
+func (ev *FlexDirections) UnmarshalJSON(b []byte) error { return kit.EnumUnmarshalJSON(ev, b) }
+
+// IsReverse returns true if items are placed in the reverse direction
+func (ev FlexDirections) IsReverse() bool {
+	return ev == FlexRowReverse || ev == FlexColumnReverse
+}
+
+// Dims returns the main dimension, along which items are placed, and the
+// cross dimension, along which the lines of wrapped items are placed
+func (ev FlexDirections) Dims() (main, cross Dims2D) {
+	if ev == FlexColumn || ev == FlexColumnReverse {
+		return Y, X
+	}
+	return X, Y
+}
+
+// FlexWraps determine whether the items of a flex layout (LayoutFlex) wrap
+// onto multiple lines, as for the flex-wrap CSS property
+type FlexWraps int32
+
+const (
+	// all items are on one line, shrinking as needed
+	FlexNowrap FlexWraps = iota
+	// items wrap onto new lines when they do not fit, placed along the cross
+	// axis from the start
+	FlexWrap
+	// items wrap onto new lines when they do not fit, placed along the cross
+	// axis from the end
+	FlexWrapReverse
+	FlexWrapsN
+)
+
+//go:generate stringer -type=FlexWraps
+
+var KiT_FlexWraps = kit.Enums.AddEnumAltLower(FlexWrapsN, false, StylePropProps, "Flex")
+
+func (ev FlexWraps) MarshalJSON() ([]byte, error)  { return kit.EnumMarshalJSON(ev) }
+func (ev *FlexWraps) UnmarshalJSON(b []byte) error { return kit.EnumUnmarshalJSON(ev, b) }
+
+////////////////////////////////////////////////////////////////////////////////////////
+//  Flex layout
+
+// flexItem is the state of one item of a flex layout during layout
+type flexItem struct {
+	ni     *WidgetBase
+	base   float32 // hypothetical main size: flex-basis or pref, within min and max
+	min    float32 // min main size
+	max    float32 // max main size -- 0 for none
+	size   float32 // resolved main size
+	frozen bool    // size is final while resolving flexible sizes
+}
+
+// flexLine is one line of items of a flex layout during layout
+type flexLine struct {
+	items []*flexItem
+	cross float32 // size of the line along the cross axis
+	need  float32 // min size of the line along the cross axis
+	pos   float32 // position of the line along the cross axis
+}
+
+// FlexGap returns the space between the items and lines of a flex layout
+func (ly *Layout) FlexGap() float32 {
+	if ly.Sty.Layout.Gap.Dots > 0 {
+		return ly.Sty.Layout.Gap.Dots
+	}
+	return ly.Spacing.Dots
+}
+
+// flexItems returns the items of a flex layout, sorted by their order style,
+// and otherwise in the order of the children
+func (ly *Layout) flexItems(main Dims2D) []*flexItem {
+	kids := ly.FlowKids()
+	items := make([]*flexItem, 0, len(kids))
+	for _, c := range kids {
+		ni := c.(Node2D).AsWidget()
+		if ni == nil {
+			continue
+		}
+		ni.LayData.UpdateSizes()
+		it := &flexItem{ni: ni}
+		it.min = ni.LayData.Size.Need.Dim(main)
+		it.base = ni.LayData.Size.Pref.Dim(main)
+		if ni.Sty.Layout.FlexBasis.Dots > 0 {
+			it.base = ni.Sty.Layout.FlexBasis.Dots
+		}
+		if mx := ni.LayData.Size.Max.Dim(main); mx > 0 {
+			it.max = Max32(mx, it.min)
+			it.base = Min32(it.base, it.max)
+		}
+		it.base = Max32(it.base, it.min)
+		it.size = it.base
+		items = append(items, it)
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].ni.Sty.Layout.Order < items[j].ni.Sty.Layout.Order
+	})
+	return items
+}
+
+// flexLines breaks items into lines that fit within avail main size, if
+// wrap, and otherwise returns one line with all of them
+func flexLines(items []*flexItem, avail, gap float32, wrap bool) []flexLine {
+	if !wrap {
+		return []flexLine{{items: items}}
+	}
+	var lines []flexLine
+	st := 0
+	sum := float32(0)
+	for i, it := range items {
+		if i > st && sum+gap+it.base > avail {
+			lines = append(lines, flexLine{items: items[st:i]})
+			st = i
+			sum = 0
+		}
+		if i > st {
+			sum += gap
+		}
+		sum += it.base
+	}
+	if st < len(items) {
+		lines = append(lines, flexLine{items: items[st:]})
+	}
+	return lines
+}
+
+// crossSizes sets the cross and need sizes of the line from the sizes of
+// its items
+func (ln *flexLine) crossSizes(cross Dims2D) {
+	ln.cross, ln.need = 0, 0
+	for _, it := range ln.items {
+		ln.cross = Max32(ln.cross, it.ni.LayData.Size.Pref.Dim(cross))
+		ln.need = Max32(ln.need, it.ni.LayData.Size.Need.Dim(cross))
+	}
+}
+
+// resolve sets the main sizes of the items of the line to fill avail, by
+// growing them according to their flex-grow (or their max stretch if none
+// grows), or shrinking them according to their flex-shrink weighted by
+// their base sizes -- sizes stay within min and max, freezing items at
+// their limits and distributing what remains among the others -- returns
+// the main size of the line
+func (ln *flexLine) resolve(main Dims2D, avail, gap float32) float32 {
+	n := len(ln.items)
+	gaps := float32(n-1) * gap
+	sum := float32(0)
+	for _, it := range ln.items {
+		it.size = it.base
+		it.frozen = false
+		sum += it.base
+	}
+	free := avail - gaps - sum
+	if free == 0 {
+		return sum + gaps
+	}
+	grow := free > 0
+	anyGrow := false
+	if grow {
+		for _, it := range ln.items {
+			if it.ni.Sty.Layout.FlexGrow > 0 {
+				anyGrow = true
+				break
+			}
+		}
+	}
+	factor := func(it *flexItem) float32 {
+		ls := &it.ni.Sty.Layout
+		switch {
+		case !grow:
+			return ls.FlexShrink * it.base
+		case anyGrow:
+			return ls.FlexGrow
+		case it.ni.LayData.Size.HasMaxStretch(main):
+			return 1
+		}
+		return 0
+	}
+	for iter := 0; iter <= n; iter++ {
+		rem := avail - gaps
+		tot := float32(0)
+		for _, it := range ln.items {
+			if it.frozen {
+				rem -= it.size
+			} else {
+				rem -= it.base
+				tot += factor(it)
+			}
+		}
+		if tot <= 0 {
+			break
+		}
+		if grow {
+			rem = Max32(rem, 0)
+		} else {
+			rem = Min32(rem, 0)
+		}
+		clamped := false
+		for _, it := range ln.items {
+			if it.frozen {
+				continue
+			}
+			sz := it.base + rem*factor(it)/tot
+			switch {
+			case grow && it.max > 0 && sz > it.max:
+				sz = it.max
+				it.frozen = true
+				clamped = true
+			case !grow && sz < it.min:
+				sz = it.min
+				it.frozen = true
+				clamped = true
+			}
+			it.size = sz
+		}
+		if !clamped {
+			break
+		}
+	}
+	sum = 0
+	for _, it := range ln.items {
+		sum += it.size
+	}
+	return sum + gaps
+}
+
+// flexDistribute returns the start position and added space between n
+// items or lines that distributes extra space according to given
+// justify-content or align-content alignment
+func flexDistribute(al Align, extra float32, n int) (start, between float32) {
+	if extra <= 0 || n == 0 {
+		return 0, 0
+	}
+	switch {
+	case IsAlignMiddle(al):
+		start = 0.5 * extra
+	case IsAlignEnd(al):
+		start = extra
+	case al == AlignJustify || al == AlignSpaceBetween:
+		if n > 1 {
+			between = extra / float32(n-1)
+		}
+	case al == AlignSpaceAround:
+		between = extra / float32(n)
+		start = 0.5 * between
+	case al == AlignSpaceEvenly:
+		between = extra / float32(n+1)
+		start = between
+	}
+	return
+}
+
+// GatherSizesFlex gathers sizes for a flex layout -- along the main axis,
+// the need is the sum of the item needs if not wrapping, and the largest
+// item need otherwise -- along the cross axis, wrapped lines are computed
+// from the size allocated along the main axis on a previous pass, if any
+func (ly *Layout) GatherSizesFlex() {
+	if len(ly.Kids) == 0 {
+		return
+	}
+	ls := &ly.Sty.Layout
+	main, cross := ls.FlexDirection.Dims()
+	gap := ly.FlexGap()
+	spc := ly.Sty.BoxSpace()
+	wrap := ls.FlexWrap != FlexNowrap
+	items := ly.flexItems(main)
+	if len(items) == 0 {
+		return
+	}
+	gaps := float32(len(items)-1) * gap
+
+	var need, pref Vec2D
+	for _, it := range items {
+		if wrap {
+			need.SetMaxDim(main, it.min)
+		} else {
+			need.SetAddDim(main, it.min)
+		}
+		pref.SetAddDim(main, it.base)
+	}
+	if !wrap {
+		need.SetAddDim(main, gaps)
+	}
+	pref.SetAddDim(main, gaps)
+
+	availMain := ly.LayData.AllocSizeOrig.Dim(main) - 2.0*spc
+	if !wrap || availMain <= 0 {
+		ln := flexLine{items: items}
+		ln.crossSizes(cross)
+		need.SetDim(cross, ln.need)
+		pref.SetDim(cross, ln.cross)
+	} else {
+		lines := flexLines(items, availMain, gap, wrap)
+		for i := range lines {
+			ln := &lines[i]
+			ln.crossSizes(cross)
+			need.SetAddDim(cross, ln.cross)
+			pref.SetAddDim(cross, ln.cross)
+		}
+		need.SetAddDim(cross, float32(len(lines)-1)*gap)
+		pref.SetAddDim(cross, float32(len(lines)-1)*gap)
+	}
+
+	for d := X; d <= Y; d++ {
+		if ly.LayData.Size.Pref.Dim(d) == 0 {
+			ly.LayData.Size.Need.SetMaxDim(d, need.Dim(d))
+			ly.LayData.Size.Pref.SetMaxDim(d, pref.Dim(d))
+		} else { // use target size from style
+			ly.LayData.Size.Need.SetDim(d, ly.LayData.Size.Pref.Dim(d))
+		}
+	}
+
+	ly.LayData.Size.Need.SetAddVal(2.0 * spc)
+	ly.LayData.Size.Pref.SetAddVal(2.0 * spc)
+
+	ly.LayData.UpdateSizes() // enforce max and normal ordering, etc
+	if Layout2DTrace {
+		fmt.Printf("Size:   %v gather sizes flex need: %v, pref: %v, gap: %v\n", ly.PathUnique(), ly.LayData.Size.Need, ly.LayData.Size.Pref, gap)
+	}
+}
+
+// LayoutFlex lays out the items of a flex layout: breaking them into lines
+// if wrapping, resolving their flexible sizes along the main axis,
+// distributing any extra space along it according to justify-content, and
+// along the cross axis according to align-content among the lines, and
+// align-items or align-self within each line -- returns true if the lines
+// need more space along the cross axis than was allocated, so the sizes
+// must be gathered again with the new main size
+func (ly *Layout) LayoutFlex() bool {
+	if len(ly.Kids) == 0 {
+		return false
+	}
+	ls := &ly.Sty.Layout
+	main, cross := ls.FlexDirection.Dims()
+	gap := ly.FlexGap()
+	spc := ly.Sty.BoxSpace()
+	wrap := ls.FlexWrap != FlexNowrap
+	availMain := Max32(ly.LayData.AllocSize.Dim(main)-2.0*spc, 0)
+	availCross := Max32(ly.LayData.AllocSize.Dim(cross)-2.0*spc, 0)
+	items := ly.flexItems(main)
+	if len(items) == 0 {
+		return false
+	}
+	lines := flexLines(items, availMain, gap, wrap)
+	nl := len(lines)
+
+	crossSum := float32(nl-1) * gap
+	for i := range lines {
+		ln := &lines[i]
+		ln.crossSizes(cross)
+		crossSum += ln.cross
+	}
+	redo := wrap && crossSum > availCross+0.1
+
+	extra := availCross - crossSum
+	if !wrap {
+		lines[0].cross = Max32(availCross, lines[0].need)
+		extra = 0
+	} else if extra > 0 && ls.AlignContent == AlignStretch {
+		for i := range lines {
+			lines[i].cross += extra / float32(nl)
+		}
+		extra = 0
+	}
+	start, between := flexDistribute(ls.AlignContent, extra, nl)
+	pos := start
+	for i := range lines {
+		ln := &lines[i]
+		ln.pos = pos
+		if ls.FlexWrap == FlexWrapReverse {
+			ln.pos = Max32(availCross-pos-ln.cross, 0)
+		}
+		pos += ln.cross + gap + between
+	}
+
+	if Layout2DTrace {
+		fmt.Printf("Layout: %v flex main: %v avail: %v x %v lines: %v gap: %v\n", ly.PathUnique(), main, availMain, availCross, nl, gap)
+	}
+
+	for i := range lines {
+		ln := &lines[i]
+		lsz := ln.resolve(main, availMain, gap)
+		start, between := flexDistribute(ls.JustifyContent, availMain-lsz, len(ln.items))
+		pos := start
+		for _, it := range ln.items {
+			ni := it.ni
+			mpos := pos
+			if ls.FlexDirection.IsReverse() {
+				mpos = Max32(availMain-pos-it.size, 0)
+			}
+			pos += it.size + gap + between
+			ni.LayData.AllocSize.SetDim(main, it.size)
+			ni.LayData.AllocPosRel.SetDim(main, spc+mpos)
+
+			al := ni.Sty.Layout.AlignSelf
+			if al == AlignAuto {
+				al = ls.AlignItems
+			}
+			need := ni.LayData.Size.Need.Dim(cross)
+			max := ni.LayData.Size.Max.Dim(cross)
+			var cpos, csize float32
+			if al == AlignStretch && ni.Sty.Layout.SizeDots().Dim(cross) == 0 {
+				csize = Max32(ln.cross, need)
+				if max > 0 {
+					csize = Max32(Min32(csize, max), need)
+				}
+			} else {
+				cpos, csize = ly.LayoutSharedDimImpl(ln.cross, need, ni.LayData.Size.Pref.Dim(cross), max, 0, al)
+			}
+			ni.LayData.AllocSize.SetDim(cross, csize)
+			ni.LayData.AllocPosRel.SetDim(cross, spc+ln.pos+cpos)
+			if Layout2DTrace {
+				fmt.Printf("Layout: %v Child: %v, flex pos: %v, size: %v\n", ly.PathUnique(), ni.UniqueNm, ni.LayData.AllocPosRel, ni.LayData.AllocSize)
+			}
+		}
+	}
+	return redo
+}
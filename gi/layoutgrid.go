@@ -0,0 +1,893 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gi
+
+import (
+	"fmt"
+	"image"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/chewxy/math32"
+	"github.com/goki/gi/units"
+	"github.com/goki/ki/ints"
+)
+
+////////////////////////////////////////////////////////////////////////////////////////
+//  Irregular grid tracks
+
+// GridTrackSize is one of the limits of the size of a grid track (row or
+// column) in an irregular grid layout -- a fixed length, the size of the
+// content (auto), or a fraction of the space left over (fr units)
+type GridTrackSize struct {
+	Len  units.Value `desc:"fixed length, used if not Auto and Fr is 0"`
+	Fr   float32     `desc:"fraction of the leftover space, in fr units -- only for the max size"`
+	Auto bool        `desc:"size of the content of the track"`
+}
+
+// IsFixed returns true if the size is a fixed length
+func (ts *GridTrackSize) IsFixed() bool {
+	return !ts.Auto && ts.Fr == 0
+}
+
+// String returns the CSS form of the size
+func (ts GridTrackSize) String() string {
+	switch {
+	case ts.Auto:
+		return "auto"
+	case ts.Fr > 0:
+		return fmt.Sprintf("%gfr", ts.Fr)
+	}
+	return ts.Len.String()
+}
+
+// GridTrack is the size specification of a row or column of an irregular
+// grid layout (LayoutGridIrreg), with a min and max size, as in
+// minmax(min, max) -- a single size s is minmax(s, s), except for fr sizes,
+// which are minmax(auto, s)
+type GridTrack struct {
+	Min GridTrackSize `desc:"minimum size of the track"`
+	Max GridTrackSize `desc:"maximum size of the track"`
+}
+
+// String returns the CSS form of the track size
+func (tr GridTrack) String() string {
+	if tr.Min == tr.Max || (tr.Min.Auto && tr.Max.Fr > 0) {
+		return tr.Max.String()
+	}
+	return "minmax(" + tr.Min.String() + ", " + tr.Max.String() + ")"
+}
+
+var (
+	gridTracksCache   = map[string][]GridTrack{}
+	gridTracksCacheMu sync.RWMutex
+)
+
+// ParseGridTracks returns the tracks of given value of the
+// grid-template-columns or grid-template-rows style property: a
+// space-separated list of lengths, auto (also min-content, max-content),
+// fractions of the leftover space in fr units, minmax(min, max) and
+// repeat(n, tracks...), e.g., "10em 1fr minmax(100px, 2fr) repeat(2, auto)"
+// -- [line-name] tokens are skipped -- results are cached, and parse errors
+// are logged the first time
+func ParseGridTracks(str string) []GridTrack {
+	gridTracksCacheMu.RLock()
+	trs, ok := gridTracksCache[str]
+	gridTracksCacheMu.RUnlock()
+	if ok {
+		return trs
+	}
+	trs, err := parseGridTracks(str)
+	if err != nil {
+		log.Println(err)
+	}
+	gridTracksCacheMu.Lock()
+	gridTracksCache[str] = trs
+	gridTracksCacheMu.Unlock()
+	return trs
+}
+
+func parseGridTracks(str string) ([]GridTrack, error) {
+	str = strings.ToLower(strings.TrimSpace(str))
+	if str == "" || str == "none" {
+		return nil, nil
+	}
+	var trs []GridTrack
+	for _, tok := range transTokens(str) {
+		switch {
+		case strings.HasPrefix(tok, "["):
+			continue
+		case strings.HasPrefix(tok, "repeat("):
+			args := cssSplitList(strings.TrimSuffix(tok[7:], ")"))
+			if len(args) != 2 {
+				return nil, fmt.Errorf("gi.ParseGridTracks: repeat needs a count and tracks in: %v", str)
+			}
+			n, err := strconv.Atoi(strings.TrimSpace(args[0]))
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("gi.ParseGridTracks: invalid repeat count %q in: %v", args[0], str)
+			}
+			rtrs, err := parseGridTracks(args[1])
+			if err != nil {
+				return nil, err
+			}
+			for i := 0; i < n; i++ {
+				trs = append(trs, rtrs...)
+			}
+		case strings.HasPrefix(tok, "minmax("):
+			args := cssSplitList(strings.TrimSuffix(tok[7:], ")"))
+			if len(args) != 2 {
+				return nil, fmt.Errorf("gi.ParseGridTracks: minmax needs two sizes in: %v", str)
+			}
+			mn, err := parseGridTrackSize(strings.TrimSpace(args[0]))
+			if err != nil || mn.Fr > 0 {
+				return nil, fmt.Errorf("gi.ParseGridTracks: invalid minmax min %q in: %v", args[0], str)
+			}
+			mx, err := parseGridTrackSize(strings.TrimSpace(args[1]))
+			if err != nil {
+				return nil, fmt.Errorf("gi.ParseGridTracks: invalid minmax max %q in: %v", args[1], str)
+			}
+			trs = append(trs, GridTrack{Min: mn, Max: mx})
+		default:
+			ts, err := parseGridTrackSize(tok)
+			if err != nil {
+				return nil, fmt.Errorf("gi.ParseGridTracks: invalid size %q in: %v", tok, str)
+			}
+			tr := GridTrack{Min: ts, Max: ts}
+			if ts.Fr > 0 {
+				tr.Min = GridTrackSize{Auto: true}
+			}
+			trs = append(trs, tr)
+		}
+	}
+	return trs, nil
+}
+
+// parseGridTrackSize parses one track size
+func parseGridTrackSize(str string) (GridTrackSize, error) {
+	switch str {
+	case "auto", "min-content", "max-content":
+		return GridTrackSize{Auto: true}, nil
+	}
+	if strings.HasSuffix(str, "fr") {
+		fr, err := strconv.ParseFloat(str[:len(str)-2], 32)
+		if err != nil || fr <= 0 {
+			return GridTrackSize{}, fmt.Errorf("invalid fr")
+		}
+		return GridTrackSize{Fr: float32(fr)}, nil
+	}
+	var ts GridTrackSize
+	if str == "" || !(units.IsCalc(str) || strings.IndexByte("0123456789.-+", str[0]) >= 0) {
+		return ts, fmt.Errorf("invalid length")
+	}
+	ts.Len.SetString(str)
+	return ts, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////////////
+//  Irregular grid areas and lines
+
+var (
+	gridAreasCache   = map[string]map[string]image.Rectangle{}
+	gridAreasCacheMu sync.RWMutex
+)
+
+// ParseGridAreas returns the named areas of given value of the
+// grid-template-areas style property: one quoted string per row, each with
+// the names of the areas of its columns, or . for none, e.g.,
+// "'head head' 'side main'" -- each area must be a rectangle.  Areas are
+// returned as rectangles of column (X) and row (Y) indexes, from 0 --
+// results are cached, and parse errors are logged the first time.
+func ParseGridAreas(str string) map[string]image.Rectangle {
+	gridAreasCacheMu.RLock()
+	ars, ok := gridAreasCache[str]
+	gridAreasCacheMu.RUnlock()
+	if ok {
+		return ars
+	}
+	ars, err := parseGridAreas(str)
+	if err != nil {
+		log.Println(err)
+	}
+	gridAreasCacheMu.Lock()
+	gridAreasCache[str] = ars
+	gridAreasCacheMu.Unlock()
+	return ars
+}
+
+func parseGridAreas(str string) (map[string]image.Rectangle, error) {
+	var rows [][]string
+	rest := strings.TrimSpace(str)
+	for rest != "" {
+		q := rest[0]
+		if q != '"' && q != '\'' {
+			return nil, fmt.Errorf("gi.ParseGridAreas: expected a quoted row at %q in: %v", rest, str)
+		}
+		ed := strings.IndexByte(rest[1:], q)
+		if ed < 0 {
+			return nil, fmt.Errorf("gi.ParseGridAreas: unterminated row in: %v", str)
+		}
+		flds := strings.Fields(strings.ToLower(rest[1 : ed+1]))
+		if len(rows) > 0 && len(flds) != len(rows[0]) {
+			return nil, fmt.Errorf("gi.ParseGridAreas: rows have different numbers of columns in: %v", str)
+		}
+		rows = append(rows, flds)
+		rest = strings.TrimSpace(rest[ed+2:])
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	ars := map[string]image.Rectangle{}
+	for r, row := range rows {
+		for c, nm := range row {
+			if strings.Trim(nm, ".") == "" {
+				continue
+			}
+			cell := image.Rect(c, r, c+1, r+1)
+			if ar, has := ars[nm]; has {
+				ars[nm] = ar.Union(cell)
+			} else {
+				ars[nm] = cell
+			}
+		}
+	}
+	for nm, ar := range ars {
+		for r := ar.Min.Y; r < ar.Max.Y; r++ {
+			for c := ar.Min.X; c < ar.Max.X; c++ {
+				if rows[r][c] != nm {
+					return nil, fmt.Errorf("gi.ParseGridAreas: area %v is not a rectangle in: %v", nm, str)
+				}
+			}
+		}
+	}
+	return ars, nil
+}
+
+// gridLineSpan is the placement of an element along one dimension of an
+// irregular grid, in track indexes from 0 -- start is -1 if it is to be
+// placed automatically
+type gridLineSpan struct {
+	start, span int
+}
+
+// parseGridLines parses the value of the grid-row or grid-column style
+// property, for a grid with given number of explicit lines (tracks + 1) in
+// that dimension, and given areas, taking the dim (X = columns) of the
+// areas: start / end, each of which is a line number from 1 (negative
+// counting back from the last explicit line), span n, auto, or an area
+// name, which is its start and end line respectively
+func parseGridLines(str string, nlines int, areas map[string]image.Rectangle, dim Dims2D) (gridLineSpan, bool) {
+	ls := gridLineSpan{start: -1, span: 1}
+	str = strings.ToLower(strings.TrimSpace(str))
+	if str == "" || str == "auto" {
+		return ls, false
+	}
+	parts := strings.Split(str, "/")
+	if len(parts) > 2 {
+		return ls, false
+	}
+	ends := [2]int{-1, -1}
+	spans := [2]int{0, 0}
+	for i, p := range parts {
+		p = strings.TrimSpace(p)
+		switch {
+		case p == "auto":
+		case strings.HasPrefix(p, "span"):
+			n, err := strconv.Atoi(strings.TrimSpace(p[4:]))
+			if err != nil || n < 1 {
+				return ls, false
+			}
+			spans[i] = n
+		default:
+			if ar, ok := areas[p]; ok {
+				st, ed := ar.Min.Y, ar.Max.Y
+				if dim == X {
+					st, ed = ar.Min.X, ar.Max.X
+				}
+				switch {
+				case i > 0:
+					ends[1] = ed
+				case len(parts) == 1:
+					ends[0], ends[1] = st, ed
+				default:
+					ends[0] = st
+				}
+				continue
+			}
+			n, err := strconv.Atoi(p)
+			if err != nil || n == 0 {
+				return ls, false
+			}
+			if n < 0 {
+				n = nlines + n + 1
+			}
+			ends[i] = ints.MaxInt(n-1, 0)
+		}
+	}
+	switch {
+	case ends[0] >= 0 && ends[1] >= 0:
+		st, ed := ends[0], ends[1]
+		if ed < st {
+			st, ed = ed, st
+		}
+		ls.start, ls.span = st, ints.MaxInt(ed-st, 1)
+	case ends[0] >= 0:
+		ls.start = ends[0]
+		if spans[1] > 0 {
+			ls.span = spans[1]
+		}
+	case ends[1] >= 0:
+		ls.span = ints.MaxInt(spans[0], 1)
+		ls.start = ints.MaxInt(ends[1]-ls.span, 0)
+	default:
+		ls.span = ints.MaxInt(spans[0], 1)
+	}
+	return ls, ls.start >= 0 || ls.span > 1
+}
+
+// gridAreaLines returns the row and column placement of an element from
+// the value of its grid-area style property: an area name or row-start /
+// column-start / row-end / column-end lines
+func gridAreaLines(str string, nlines image.Point, areas map[string]image.Rectangle) (row, col gridLineSpan, ok bool) {
+	str = strings.ToLower(strings.TrimSpace(str))
+	if ar, has := areas[str]; has {
+		return gridLineSpan{ar.Min.Y, ar.Dy()}, gridLineSpan{ar.Min.X, ar.Dx()}, true
+	}
+	parts := strings.Split(str, "/")
+	if len(parts) < 2 {
+		return
+	}
+	rs, cs := parts[0], parts[1]
+	if len(parts) > 2 {
+		rs += "/" + parts[2]
+	}
+	if len(parts) > 3 {
+		cs += "/" + parts[3]
+	}
+	row, rok := parseGridLines(rs, nlines.Y, areas, Y)
+	col, cok := parseGridLines(cs, nlines.X, areas, X)
+	return row, col, rok || cok
+}
+
+////////////////////////////////////////////////////////////////////////////////////////
+//  Irregular grid layout
+
+// gridOcc records the cells of an irregular grid that are occupied
+type gridOcc struct {
+	cols  int
+	cells [][]bool
+}
+
+// fits returns true if an element of given span fits at given cell
+func (oc *gridOcc) fits(row, col, rspan, cspan int) bool {
+	if col+cspan > oc.cols {
+		return false
+	}
+	for r := row; r < row+rspan && r < len(oc.cells); r++ {
+		for c := col; c < col+cspan; c++ {
+			if oc.cells[r][c] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// set marks the cells of an element as occupied, adding rows as needed
+func (oc *gridOcc) set(row, col, rspan, cspan int) {
+	for len(oc.cells) < row+rspan {
+		oc.cells = append(oc.cells, make([]bool, oc.cols))
+	}
+	for r := row; r < row+rspan; r++ {
+		for c := col; c < col+cspan && c < oc.cols; c++ {
+			oc.cells[r][c] = true
+		}
+	}
+}
+
+// PlaceGridIrreg places the children of an irregular grid layout
+// (LayoutGridIrreg) in the grid, setting their GridPos and GridSpan, and
+// sets the GridSize and GridTracks of the layout.  Elements are placed
+// according to their grid-area, grid-row and grid-column style properties,
+// falling back on row, col, row-span and col-span, and the rest are placed
+// in order in the first free cells, row by row.
+func (ly *Layout) PlaceGridIrreg() {
+	lst := &ly.Sty.Layout
+	ctrs := ParseGridTracks(lst.GridTemplateColumns)
+	rtrs := ParseGridTracks(lst.GridTemplateRows)
+	areas := ParseGridAreas(lst.GridTemplateAreas)
+	var asz image.Point
+	for _, ar := range areas {
+		asz.X = ints.MaxInt(asz.X, ar.Max.X)
+		asz.Y = ints.MaxInt(asz.Y, ar.Max.Y)
+	}
+	cols := ints.MaxInt(ints.MaxInt(len(ctrs), asz.X), lst.Columns)
+	rows := ints.MaxInt(len(rtrs), asz.Y)
+	nlines := image.Point{cols + 1, rows + 1}
+
+	type place struct {
+		ni       *WidgetBase
+		row, col gridLineSpan
+	}
+	kids := ly.FlowKids()
+	pls := make([]place, 0, len(kids))
+	for _, c := range kids {
+		ni := c.(Node2D).AsWidget()
+		if ni == nil {
+			continue
+		}
+		nl := &ni.Sty.Layout
+		pl := place{ni: ni, row: gridLineSpan{-1, 1}, col: gridLineSpan{-1, 1}}
+		if ar, ac, ok := gridAreaLines(nl.GridArea, nlines, areas); ok {
+			pl.row, pl.col = ar, ac
+		} else {
+			if ls, ok := parseGridLines(nl.GridRow, nlines.Y, areas, Y); ok {
+				pl.row = ls
+			} else {
+				if nl.Row > 0 {
+					pl.row.start = nl.Row
+				}
+				pl.row.span = ints.MaxInt(nl.RowSpan, 1)
+			}
+			if ls, ok := parseGridLines(nl.GridColumn, nlines.X, areas, X); ok {
+				pl.col = ls
+			} else {
+				if nl.Col > 0 {
+					pl.col.start = nl.Col
+				}
+				pl.col.span = ints.MaxInt(nl.ColSpan, 1)
+			}
+		}
+		if pl.col.start >= 0 {
+			cols = ints.MaxInt(cols, pl.col.start+pl.col.span)
+		} else {
+			cols = ints.MaxInt(cols, pl.col.span)
+		}
+		pls = append(pls, pl)
+	}
+	if cols == 0 {
+		cols = ints.MaxInt(int(math32.Sqrt(float32(len(pls)))), 1) // as in LayoutGrid
+	}
+
+	occ := gridOcc{cols: cols}
+	// first those with fixed rows, then the rest in order
+	for pass := 0; pass < 2; pass++ {
+		crow, ccol := 0, 0 // auto-placement cursor
+		for i := range pls {
+			pl := &pls[i]
+			if (pass == 0) != (pl.row.start >= 0) {
+				continue
+			}
+			switch {
+			case pl.row.start >= 0 && pl.col.start >= 0:
+			case pl.row.start >= 0:
+				pl.col.start = 0
+				for c := 0; c+pl.col.span <= cols; c++ {
+					if occ.fits(pl.row.start, c, pl.row.span, pl.col.span) {
+						pl.col.start = c
+						break
+					}
+				}
+			default:
+				for {
+					if pl.col.start >= 0 { // fixed column: find row
+						if pl.col.start < ccol {
+							crow++
+						}
+						ccol = pl.col.start
+						if occ.fits(crow, ccol, pl.row.span, pl.col.span) {
+							break
+						}
+						crow++
+						continue
+					}
+					if ccol+pl.col.span > cols {
+						ccol = 0
+						crow++
+						continue
+					}
+					if occ.fits(crow, ccol, pl.row.span, pl.col.span) {
+						break
+					}
+					ccol++
+				}
+				pl.row.start, pl.col.start = crow, ccol
+				ccol += pl.col.span
+			}
+			occ.set(pl.row.start, pl.col.start, pl.row.span, pl.col.span)
+			rows = ints.MaxInt(rows, pl.row.start+pl.row.span)
+		}
+	}
+
+	for _, pl := range pls {
+		pl.ni.LayData.GridPos = image.Point{pl.col.start, pl.row.start}
+		pl.ni.LayData.GridSpan = image.Point{pl.col.span, pl.row.span}
+	}
+	ly.GridSize = image.Point{cols, rows}
+	ly.GridTracks[Col] = gridTracksN(ly.GridTracks[Col], ctrs, cols)
+	ly.GridTracks[Row] = gridTracksN(ly.GridTracks[Row], rtrs, rows)
+}
+
+// gridPointDim returns the column (X) or row (Y) of a grid point
+func gridPointDim(pt image.Point, dim Dims2D) int {
+	if dim == X {
+		return pt.X
+	}
+	return pt.Y
+}
+
+// gridTracksN returns n tracks in trs, copied from the explicit tracks in
+// exp, with auto tracks for the rest
+func gridTracksN(trs, exp []GridTrack, n int) []GridTrack {
+	if cap(trs) < n {
+		trs = make([]GridTrack, n)
+	}
+	trs = trs[:n]
+	for i := range trs {
+		if i < len(exp) {
+			trs[i] = exp[i]
+		} else {
+			trs[i] = GridTrack{Min: GridTrackSize{Auto: true}, Max: GridTrackSize{Auto: true}}
+		}
+	}
+	return trs
+}
+
+// GatherSizesGridIrreg is size first pass: gather the size information from
+// the children, irregular grid version -- each track gets the fixed sizes
+// of its GridTrack, or else the largest size of the elements within it,
+// with the sizes of elements spanning several tracks shared among them
+func (ly *Layout) GatherSizesGridIrreg() {
+	if len(ly.Kids) == 0 {
+		return
+	}
+	ly.PlaceGridIrreg()
+
+	kids := make([]*WidgetBase, 0, len(ly.Kids))
+	for _, c := range ly.FlowKids() {
+		ni := c.(Node2D).AsWidget()
+		if ni == nil {
+			continue
+		}
+		ni.LayData.UpdateSizes()
+		kids = append(kids, ni)
+	}
+	// single spans first, so multiple spans only add what is still missing
+	sort.SliceStable(kids, func(i, j int) bool {
+		return kids[i].LayData.GridSpan.X+kids[i].LayData.GridSpan.Y < kids[j].LayData.GridSpan.X+kids[j].LayData.GridSpan.Y
+	})
+
+	var sumPref, sumNeed Vec2D
+	for rc := Row; rc < RowColN; rc++ {
+		dim := X
+		if rc == Row {
+			dim = Y
+		}
+		trs := ly.GridTracks[rc]
+		n := len(trs)
+		if len(ly.GridData[rc]) != n {
+			ly.GridData[rc] = make([]GridData, n)
+		}
+		gds := ly.GridData[rc]
+		for i := range trs {
+			tr := &trs[i]
+			gd := &gds[i]
+			*gd = GridData{}
+			if tr.Min.IsFixed() {
+				gd.SizeNeed = tr.Min.Len.ToDots(&ly.Sty.UnContext)
+				gd.SizePref = gd.SizeNeed
+			}
+			if tr.Max.IsFixed() {
+				gd.SizeMax = Max32(tr.Max.Len.ToDots(&ly.Sty.UnContext), gd.SizeNeed)
+				gd.SizePref = gd.SizeMax
+			} else if tr.Max.Fr > 0 {
+				gd.SizeMax = -1
+			}
+		}
+		for _, ni := range kids {
+			st := gridPointDim(ni.LayData.GridPos, dim)
+			span := gridPointDim(ni.LayData.GridSpan, dim)
+			if st < 0 || st+span > n {
+				continue
+			}
+			ly.gridIrregAddSize(trs[st:st+span], gds[st:st+span], ni.LayData.Size.Need.Dim(dim), true)
+			ly.gridIrregAddSize(trs[st:st+span], gds[st:st+span], ni.LayData.Size.Pref.Dim(dim), false)
+			if ni.LayData.Size.HasMaxStretch(dim) {
+				for i := st; i < st+span; i++ {
+					if !trs[i].Max.IsFixed() {
+						gds[i].SizeMax = -1
+					}
+				}
+			}
+		}
+		for i := range gds {
+			gd := &gds[i]
+			if gd.SizeMax > 0 && !trs[i].Min.IsFixed() {
+				gd.SizeNeed = Min32(gd.SizeNeed, gd.SizeMax)
+			}
+			SetMax32(&gd.SizePref, gd.SizeNeed)
+			sumNeed.SetAddDim(dim, gd.SizeNeed)
+			sumPref.SetAddDim(dim, gd.SizePref)
+		}
+	}
+
+	for d := X; d <= Y; d++ {
+		if ly.LayData.Size.Pref.Dim(d) == 0 {
+			ly.LayData.Size.Need.SetMaxDim(d, sumNeed.Dim(d))
+			ly.LayData.Size.Pref.SetMaxDim(d, sumPref.Dim(d))
+		} else { // use target size from style otherwise
+			ly.LayData.Size.Need.SetDim(d, ly.LayData.Size.Pref.Dim(d))
+		}
+	}
+
+	spc := ly.Sty.BoxSpace()
+	ly.LayData.Size.Need.SetAddVal(2.0 * spc)
+	ly.LayData.Size.Pref.SetAddVal(2.0 * spc)
+
+	cols, rows := ly.GridSize.X, ly.GridSize.Y
+	ly.LayData.Size.Need.X += float32(ints.MaxInt(cols-1, 0)) * ly.Spacing.Dots
+	ly.LayData.Size.Pref.X += float32(ints.MaxInt(cols-1, 0)) * ly.Spacing.Dots
+	ly.LayData.Size.Need.Y += float32(ints.MaxInt(rows-1, 0)) * ly.Spacing.Dots
+	ly.LayData.Size.Pref.Y += float32(ints.MaxInt(rows-1, 0)) * ly.Spacing.Dots
+
+	ly.LayData.UpdateSizes() // enforce max and normal ordering, etc
+	if Layout2DTrace {
+		fmt.Printf("Size:   %v gather sizes grid irreg: %v need: %v, pref: %v\n", ly.PathUnique(), ly.GridSize, ly.LayData.Size.Need, ly.LayData.Size.Pref)
+	}
+}
+
+// gridIrregAddSize makes the given tracks, spanned by an element, at least
+// as big as given size of the element, need or pref -- sizes that are
+// missing are shared equally among the tracks that are sized by their
+// content, preferring those without fr max sizes, as in CSS
+func (ly *Layout) gridIrregAddSize(trs []GridTrack, gds []GridData, sz float32, need bool) {
+	if len(trs) == 1 {
+		tr, gd := &trs[0], &gds[0]
+		if need && !tr.Min.IsFixed() {
+			SetMax32(&gd.SizeNeed, sz)
+		} else if !need && !tr.Max.IsFixed() {
+			SetMax32(&gd.SizePref, sz)
+		}
+		return
+	}
+	have := float32(len(trs)-1) * ly.Spacing.Dots
+	for i := range gds {
+		if need {
+			have += gds[i].SizeNeed
+		} else {
+			have += gds[i].SizePref
+		}
+	}
+	miss := sz - have
+	if miss <= 0 {
+		return
+	}
+	grow := func(tr *GridTrack, nofr bool) bool {
+		if nofr && tr.Max.Fr > 0 {
+			return false
+		}
+		if need {
+			return !tr.Min.IsFixed()
+		}
+		return !tr.Max.IsFixed()
+	}
+	for _, nofr := range []bool{true, false} {
+		n := 0
+		for i := range trs {
+			if grow(&trs[i], nofr) {
+				n++
+			}
+		}
+		if n == 0 {
+			continue
+		}
+		add := miss / float32(n)
+		for i := range trs {
+			if !grow(&trs[i], nofr) {
+				continue
+			}
+			if need {
+				gds[i].SizeNeed += add
+			} else {
+				gds[i].SizePref += add
+			}
+		}
+		return
+	}
+}
+
+// LayoutGridIrregDim lays out the tracks of an irregular grid along each
+// dimension (row, Y; col, X): the tracks get their preferred sizes if
+// there is room, and otherwise their needed sizes -- any extra space goes
+// to the tracks with fr max sizes, in proportion to those, or else to
+// tracks with stretchy elements, in proportion to their preferred sizes, or
+// else is used to align the tracks according to the alignment of the layout
+func (ly *Layout) LayoutGridIrregDim(rowcol RowCol, dim Dims2D) {
+	gds := ly.GridData[rowcol]
+	trs := ly.GridTracks[rowcol]
+	sz := len(gds)
+	if sz == 0 || len(trs) != sz {
+		return
+	}
+	elspc := float32(sz-1) * ly.Spacing.Dots
+	al := ly.Sty.Layout.AlignDim(dim)
+	spc := ly.Sty.BoxSpace()
+	avail := ly.LayData.AllocSize.Dim(dim) - 2.0*spc - elspc
+
+	var sumPref, sumNeed, totFr float32
+	for i := range gds {
+		sumPref += gds[i].SizePref
+		sumNeed += gds[i].SizeNeed
+		totFr += trs[i].Max.Fr
+	}
+	usePref := avail-sumPref >= -0.1
+	tot := sumNeed
+	for i := range gds {
+		gd := &gds[i]
+		gd.AllocSize = gd.SizeNeed
+		if usePref {
+			gd.AllocSize = gd.SizePref
+		}
+	}
+	if usePref {
+		tot = sumPref
+	}
+	extra := Max32(avail-tot, 0)
+
+	stretched := false
+	if extra > 0 && totFr > 0 {
+		// fr tracks share the leftover space, except those whose size is
+		// already larger than their share, which keep their size
+		left := avail
+		flex := make([]bool, sz)
+		for i := range gds {
+			if trs[i].Max.Fr > 0 {
+				flex[i] = true
+			} else {
+				left -= gds[i].AllocSize
+			}
+		}
+		for {
+			unit := left / totFr
+			fixed := false
+			for i := range gds {
+				if flex[i] && gds[i].AllocSize > trs[i].Max.Fr*unit {
+					flex[i] = false
+					left -= gds[i].AllocSize
+					totFr -= trs[i].Max.Fr
+					fixed = true
+				}
+			}
+			if !fixed || totFr <= 0 {
+				break
+			}
+		}
+		if totFr > 0 {
+			unit := Max32(left, 0) / totFr
+			for i := range gds {
+				if flex[i] {
+					gds[i].AllocSize = trs[i].Max.Fr * unit
+				}
+			}
+		}
+		stretched = true
+	} else if extra > 0 {
+		var stretchTot float32
+		for i := range gds {
+			if gds[i].SizeMax < 0 {
+				stretchTot += Max32(gds[i].AllocSize, 1)
+			}
+		}
+		if stretchTot > 0 {
+			for i := range gds {
+				if gds[i].SizeMax < 0 {
+					gds[i].AllocSize += extra * Max32(gds[i].AllocSize, 1) / stretchTot
+				}
+			}
+			stretched = true
+		}
+	}
+
+	pos := spc
+	extraSpace := float32(0)
+	if !stretched && extra > 0 {
+		switch {
+		case IsAlignMiddle(al):
+			pos += 0.5 * extra
+		case IsAlignEnd(al):
+			pos += extra
+		case al == AlignJustify && sz > 1:
+			extraSpace = extra / float32(sz-1)
+		}
+	}
+
+	if Layout2DTrace {
+		fmt.Printf("Layout Grid Irreg Dim: %v All on dim %v, avail: %v need: %v pref: %v extra %v, stretched: %v\n", ly.PathUnique(), dim, avail, sumNeed, sumPref, extra, stretched)
+	}
+
+	for i := range gds {
+		gd := &gds[i]
+		gd.AllocPosRel = pos
+		if Layout2DTrace {
+			fmt.Printf("Grid %v %v pos: %v, size: %v\n", rowcol, trs[i], pos, gd.AllocSize)
+		}
+		pos += gd.AllocSize + ly.Spacing.Dots + extraSpace
+	}
+}
+
+// LayoutGridIrreg manages overall irregular grid layout of children -- each
+// child is laid out within the tracks it spans
+func (ly *Layout) LayoutGridIrreg() {
+	if len(ly.Kids) == 0 {
+		return
+	}
+
+	ly.LayoutGridIrregDim(Row, Y)
+	ly.LayoutGridIrregDim(Col, X)
+
+	for _, c := range ly.FlowKids() {
+		ni := c.(Node2D).AsWidget()
+		if ni == nil {
+			continue
+		}
+		for _, rc := range []RowCol{Col, Row} {
+			dim := X
+			if rc == Row {
+				dim = Y
+			}
+			gds := ly.GridData[rc]
+			st := gridPointDim(ni.LayData.GridPos, dim)
+			ed := st + gridPointDim(ni.LayData.GridSpan, dim) - 1
+			if st < 0 || ed >= len(gds) {
+				continue
+			}
+			avail := gds[ed].AllocPosRel + gds[ed].AllocSize - gds[st].AllocPosRel
+			al := ni.Sty.Layout.AlignDim(dim)
+			pref := ni.LayData.Size.Pref.Dim(dim)
+			need := ni.LayData.Size.Need.Dim(dim)
+			max := ni.LayData.Size.Max.Dim(dim)
+			pos, size := ly.LayoutSharedDimImpl(avail, need, pref, max, 0, al)
+			ni.LayData.AllocSize.SetDim(dim, size)
+			ni.LayData.AllocPosRel.SetDim(dim, pos+gds[st].AllocPosRel)
+		}
+		if Layout2DTrace {
+			fmt.Printf("Layout: %v grid irreg pos: %v span: %v pos: %v size: %v\n", ly.PathUnique(), ni.LayData.GridPos, ni.LayData.GridSpan, ni.LayData.AllocPosRel, ni.LayData.AllocSize)
+		}
+	}
+}
+
+// GridIrregVertChild returns the index of the child of an irregular grid
+// layout that is nearest below (or above, if up) the child at given index,
+// overlapping its first column, or -1 if there is none
+func (ly *Layout) GridIrregVertChild(idx int, up bool) int {
+	cur := ly.Child(idx).(Node2D).AsWidget()
+	if cur == nil {
+		return -1
+	}
+	cp := cur.LayData.GridPos
+	best, bestRow := -1, 0
+	for i, c := range ly.Kids {
+		ni := c.(Node2D).AsWidget()
+		if ni == nil || i == idx || ni.Sty.Layout.IsOutOfFlow() {
+			continue
+		}
+		np, ns := ni.LayData.GridPos, ni.LayData.GridSpan
+		if cp.X < np.X || cp.X >= np.X+ns.X {
+			continue
+		}
+		if up {
+			if np.Y+ns.Y <= cp.Y && (best < 0 || np.Y > bestRow) {
+				best, bestRow = i, np.Y
+			}
+		} else {
+			if np.Y >= cp.Y+cur.LayData.GridSpan.Y && (best < 0 || np.Y < bestRow) {
+				best, bestRow = i, np.Y
+			}
+		}
+	}
+	return best
+}
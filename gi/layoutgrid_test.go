@@ -0,0 +1,158 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gi
+
+import (
+	"image"
+	"testing"
+
+	"github.com/goki/gi/units"
+)
+
+func TestParseGridTracks(t *testing.T) {
+	px := func(v float32) GridTrackSize { return GridTrackSize{Len: units.NewValue(v, units.Px)} }
+	em := func(v float32) GridTrackSize { return GridTrackSize{Len: units.NewValue(v, units.Em)} }
+	fr := func(v float32) GridTrackSize { return GridTrackSize{Fr: v} }
+	auto := GridTrackSize{Auto: true}
+	tests := []struct {
+		str  string
+		want []GridTrack
+	}{
+		{"", nil},
+		{"none", nil},
+		{"10px", []GridTrack{{px(10), px(10)}}},
+		{"10px 1fr auto", []GridTrack{{px(10), px(10)}, {auto, fr(1)}, {auto, auto}}},
+		{"minmax(100px, 2fr) minmax(auto, 50px)", []GridTrack{{px(100), fr(2)}, {auto, px(50)}}},
+		{"repeat(2, 1fr 20px) 2em", []GridTrack{{auto, fr(1)}, {px(20), px(20)}, {auto, fr(1)}, {px(20), px(20)}, {em(2), em(2)}}},
+		{"[start] 2em [mid] max-content [end]", []GridTrack{{em(2), em(2)}, {auto, auto}}},
+		{" Repeat(3, Min-Content) ", []GridTrack{{auto, auto}, {auto, auto}, {auto, auto}}},
+		{"repeat(2, minmax(10px, 0.5fr))", []GridTrack{{px(10), fr(0.5)}, {px(10), fr(0.5)}}},
+	}
+	for _, tst := range tests {
+		got := ParseGridTracks(tst.str)
+		if len(got) != len(tst.want) {
+			t.Errorf("ParseGridTracks(%q) = %v, want: %v", tst.str, got, tst.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tst.want[i] {
+				t.Errorf("ParseGridTracks(%q) = %v, want: %v", tst.str, got, tst.want)
+				break
+			}
+		}
+	}
+	errs := []string{
+		"repeat(0, 1fr)",
+		"repeat(2)",
+		"repeat(x, 1fr)",
+		"minmax(1fr, 2fr)",
+		"minmax(10px)",
+		"10px bogus",
+		"0fr",
+		"-1fr",
+	}
+	for _, str := range errs {
+		if trs, err := parseGridTracks(str); err == nil {
+			t.Errorf("parseGridTracks(%q) = %v, did not fail", str, trs)
+		}
+	}
+}
+
+func TestParseGridAreas(t *testing.T) {
+	tests := []struct {
+		str  string
+		want map[string]image.Rectangle
+	}{
+		{"", nil},
+		{"'head head' 'side main'", map[string]image.Rectangle{
+			"head": image.Rect(0, 0, 2, 1), "side": image.Rect(0, 1, 1, 2), "main": image.Rect(1, 1, 2, 2)}},
+		{`"a a ." "a a b" ". .. b"`, map[string]image.Rectangle{
+			"a": image.Rect(0, 0, 2, 2), "b": image.Rect(2, 1, 3, 3)}},
+		{"'Head HEAD'\n'. foot'", map[string]image.Rectangle{
+			"head": image.Rect(0, 0, 2, 1), "foot": image.Rect(1, 1, 2, 2)}},
+	}
+	for _, tst := range tests {
+		got := ParseGridAreas(tst.str)
+		if len(got) != len(tst.want) {
+			t.Errorf("ParseGridAreas(%q) = %v, want: %v", tst.str, got, tst.want)
+			continue
+		}
+		for nm, ar := range tst.want {
+			if got[nm] != ar {
+				t.Errorf("ParseGridAreas(%q) area %v = %v, want: %v", tst.str, nm, got[nm], ar)
+			}
+		}
+	}
+	errs := []string{
+		"head",
+		"'a b' 'a'",
+		"'a b a'",
+		"'a .' 'b a'",
+		"'a b",
+		"'a' b",
+	}
+	for _, str := range errs {
+		if ars, err := parseGridAreas(str); err == nil {
+			t.Errorf("parseGridAreas(%q) = %v, did not fail", str, ars)
+		}
+	}
+}
+
+func TestParseGridLines(t *testing.T) {
+	// 3 columns and 2 rows, so 4 column lines and 3 row lines
+	areas := ParseGridAreas("'head head head' 'side main main'")
+	auto := gridLineSpan{-1, 1}
+	tests := []struct {
+		str  string
+		dim  Dims2D
+		want gridLineSpan
+		ok   bool
+	}{
+		{"", X, auto, false},
+		{"auto", X, auto, false},
+		{"auto / auto", X, auto, false},
+		{"2", X, gridLineSpan{1, 1}, true},
+		{"1 / 3", X, gridLineSpan{0, 2}, true},
+		{"3 / 1", X, gridLineSpan{0, 2}, true},
+		{"2 / 2", X, gridLineSpan{1, 1}, true},
+		{"1 / -1", X, gridLineSpan{0, 3}, true},
+		{"-2 / -1", X, gridLineSpan{2, 1}, true},
+		{"1 / -1", Y, gridLineSpan{0, 2}, true},
+		{"2 / span 2", X, gridLineSpan{1, 2}, true},
+		{"span 2", X, gridLineSpan{-1, 2}, true},
+		{"span 2 / 4", X, gridLineSpan{1, 2}, true},
+		{"span 3 / 2", X, gridLineSpan{0, 3}, true},
+		{"head", X, gridLineSpan{0, 3}, true},
+		{"head", Y, gridLineSpan{0, 1}, true},
+		{"Main", X, gridLineSpan{1, 2}, true},
+		{"main", Y, gridLineSpan{1, 1}, true},
+		{"side / main", X, gridLineSpan{0, 3}, true},
+		{"2 / main", X, gridLineSpan{1, 2}, true},
+		{"0", X, auto, false},
+		{"span 0", X, auto, false},
+		{"span x", X, auto, false},
+		{"1 / 2 / 3", X, auto, false},
+		{"nowhere", X, auto, false},
+	}
+	for _, tst := range tests {
+		nlines := 4
+		if tst.dim == Y {
+			nlines = 3
+		}
+		got, ok := parseGridLines(tst.str, nlines, areas, tst.dim)
+		if got != tst.want || ok != tst.ok {
+			t.Errorf("parseGridLines(%q, %v) = %v, %v, want: %v, %v", tst.str, tst.dim, got, ok, tst.want, tst.ok)
+		}
+	}
+
+	row, col, ok := gridAreaLines("side", image.Point{4, 3}, areas)
+	if !ok || row != (gridLineSpan{1, 1}) || col != (gridLineSpan{0, 1}) {
+		t.Errorf("gridAreaLines(side) = %v, %v, %v", row, col, ok)
+	}
+	row, col, ok = gridAreaLines("1 / 2 / 3 / 4", image.Point{4, 3}, areas)
+	if !ok || row != (gridLineSpan{0, 2}) || col != (gridLineSpan{1, 2}) {
+		t.Errorf("gridAreaLines(1 / 2 / 3 / 4) = %v, %v, %v", row, col, ok)
+	}
+}
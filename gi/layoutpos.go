@@ -0,0 +1,241 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gi
+
+import (
+	"image"
+	"sort"
+	"strings"
+
+	"github.com/goki/gi/units"
+	"github.com/goki/ki"
+	"github.com/goki/ki/kit"
+)
+
+////////////////////////////////////////////////////////////////////////////////////////
+//  Position styles
+
+// Positions are the ways that an element can be positioned within its
+// parent layout, as for the position CSS property
+type Positions int32
+
+const (
+	// laid out in flow by the layout -- top, left etc are ignored
+	PositionStatic Positions = iota
+	// laid out in flow by the layout, and then offset by top or bottom, and
+	// left or right
+	PositionRelative
+	// taken out of the flow, and placed at top or bottom, and left or right,
+	// within the content of the layout, or the box of its anchor sibling
+	PositionAbsolute
+	// laid out in flow by the layout, but kept at least top or bottom, and
+	// left or right, within the visible part of the layout as it scrolls,
+	// for as long as the content of the layout allows
+	PositionSticky
+	PositionsN
+)
+
+//go:generate stringer -type=Positions
+
+var KiT_Positions = kit.Enums.AddEnumAltLower(PositionsN, false, StylePropProps, "Position")
+
+func (ev Positions) MarshalJSON() ([]byte, error)  { return kit.EnumMarshalJSON(ev) }
+func (ev *Positions) UnmarshalJSON(b []byte) error { return kit.EnumUnmarshalJSON(ev, b) }
+
+// IsPositioned returns true if the element is not laid out with the default
+// static position, so that it renders above those that are, at the same
+// z-index
+func (ls *LayoutStyle) IsPositioned() bool {
+	return ls.Position != PositionStatic
+}
+
+// IsOutOfFlow returns true if the element is not laid out in flow by its
+// parent layout, i.e., it has absolute position
+func (ls *LayoutStyle) IsOutOfFlow() bool {
+	return ls.Position == PositionAbsolute
+}
+
+// InsetDots returns the value in dots of one of the top, right, bottom or
+// left styles of a positioned element, with percentages relative to given
+// size of its containing block -- returns false if it is auto or empty
+func InsetDots(str string, size float32, ctxt *units.Context) (float32, bool) {
+	str = strings.TrimSpace(str)
+	if str == "" || str == "auto" {
+		return 0, false
+	}
+	v := units.StringToValue(str)
+	if v.Un == units.Pct && v.Calc == nil {
+		return 0.01 * v.Val * size, true
+	}
+	return v.ToDots(ctxt), true
+}
+
+// InsetsDim returns the start (top or left) and end (bottom or right)
+// insets of a positioned element along given dimension, in dots relative to
+// given size of its containing block, and whether each is set
+func (ls *LayoutStyle) InsetsDim(dim Dims2D, size float32, ctxt *units.Context) (st, ed float32, hasSt, hasEd bool) {
+	if dim == X {
+		st, hasSt = InsetDots(ls.Left, size, ctxt)
+		ed, hasEd = InsetDots(ls.Right, size, ctxt)
+	} else {
+		st, hasSt = InsetDots(ls.Top, size, ctxt)
+		ed, hasEd = InsetDots(ls.Bottom, size, ctxt)
+	}
+	return
+}
+
+////////////////////////////////////////////////////////////////////////////////////////
+//  Positioned children of layouts
+
+// FlowKids returns the children that are laid out in flow by the layout,
+// i.e., all of them except those with absolute position, which are placed
+// afterward by LayoutPositioned
+func (ly *Layout) FlowKids() ki.Slice {
+	for i, c := range ly.Kids {
+		ni := c.(Node2D).AsWidget()
+		if ni == nil || !ni.Sty.Layout.IsOutOfFlow() {
+			continue
+		}
+		kids := make(ki.Slice, i, len(ly.Kids)-1)
+		copy(kids, ly.Kids[:i])
+		for _, c := range ly.Kids[i+1:] {
+			ni := c.(Node2D).AsWidget()
+			if ni != nil && ni.Sty.Layout.IsOutOfFlow() {
+				continue
+			}
+			kids = append(kids, c)
+		}
+		return kids
+	}
+	return ly.Kids
+}
+
+// HasPosition returns true if any of the children of the layout has given
+// position
+func (ly *Layout) HasPosition(pos Positions) bool {
+	for _, c := range ly.Kids {
+		ni := c.(Node2D).AsWidget()
+		if ni != nil && ni.Sty.Layout.Position == pos {
+			return true
+		}
+	}
+	return false
+}
+
+// LayoutPositioned offsets the children of the layout with relative
+// position from where the flow layout put them, and places those with
+// absolute position within the content of the layout, or the box of their
+// anchor sibling -- these have their preferred size, unless both of their
+// insets along a dimension are set and they have no size style along it, in
+// which case they fill the space between them
+func (ly *Layout) LayoutPositioned() {
+	spc := ly.Sty.BoxSpace()
+	for _, c := range ly.Kids {
+		ni := c.(Node2D).AsWidget()
+		if ni == nil {
+			continue
+		}
+		ls := &ni.Sty.Layout
+		switch ls.Position {
+		case PositionRelative:
+			for d := X; d <= Y; d++ {
+				st, ed, hasSt, hasEd := ls.InsetsDim(d, ly.LayData.AllocSize.Dim(d)-2.0*spc, &ni.Sty.UnContext)
+				if hasSt {
+					ni.LayData.AllocPosRel.SetAddDim(d, st)
+				} else if hasEd {
+					ni.LayData.AllocPosRel.SetAddDim(d, -ed)
+				}
+			}
+		case PositionAbsolute:
+			cpos := NewVec2D(spc, spc)
+			csz := ly.LayData.AllocSize.SubVal(2.0 * spc)
+			if ls.Anchor != "" {
+				if an := ly.ChildByName(ls.Anchor, 0); an != nil {
+					if aw := an.(Node2D).AsWidget(); aw != nil {
+						cpos = aw.LayData.AllocPosRel
+						csz = aw.LayData.AllocSize
+					}
+				}
+			}
+			ni.LayData.UpdateSizes()
+			for d := X; d <= Y; d++ {
+				cs := csz.Dim(d)
+				st, ed, hasSt, hasEd := ls.InsetsDim(d, cs, &ni.Sty.UnContext)
+				size := ni.LayData.Size.Pref.Dim(d)
+				if hasSt && hasEd && ls.SizeDots().Dim(d) == 0 {
+					size = Max32(cs-st-ed, ni.LayData.Size.Need.Dim(d))
+				}
+				pos := st
+				if !hasSt && hasEd {
+					pos = cs - ed - size
+				}
+				ni.LayData.AllocSize.SetDim(d, size)
+				ni.LayData.AllocPosRel.SetDim(d, cpos.Dim(d)+pos)
+			}
+		}
+	}
+}
+
+// StickyDelta returns the delta to move a child of the layout with sticky
+// position by, from the delta of its flow position, so that it stays at
+// least its insets within the visible part of the layout, given by its
+// children bounding box, while staying within the content of the layout
+func (ly *Layout) StickyDelta(ni *WidgetBase, delta image.Point, cbb image.Rectangle) image.Point {
+	if cbb == image.ZR {
+		return delta
+	}
+	spc := ly.Sty.BoxSpace()
+	ls := &ni.Sty.Layout
+	pos := ni.LayData.AllocPosOrig.Add(NewVec2DFmPoint(delta))
+	org := pos.Sub(ni.LayData.AllocPosRel)
+	ext := ly.ChildSize.Max(ly.LayData.AllocSize.SubVal(spc))
+	vmin := NewVec2DFmPoint(cbb.Min)
+	vmax := NewVec2DFmPoint(cbb.Max)
+	for d := X; d <= Y; d++ {
+		st, ed, hasSt, hasEd := ls.InsetsDim(d, vmax.Dim(d)-vmin.Dim(d), &ni.Sty.UnContext)
+		p := pos.Dim(d)
+		sz := ni.LayData.AllocSize.Dim(d)
+		if hasSt {
+			p = Min32(Max32(p, vmin.Dim(d)+st), org.Dim(d)+ext.Dim(d)-sz)
+		} else if hasEd {
+			p = Max32(Min32(p, vmax.Dim(d)-ed-sz), org.Dim(d)+spc)
+		}
+		pos.SetDim(d, p)
+	}
+	return pos.Sub(ni.LayData.AllocPosOrig).ToPoint()
+}
+
+// RenderOrder returns the children of the layout in the order that they
+// are rendered, so that later ones render above earlier ones: by their
+// z-index, then those in flow before positioned ones, and otherwise in order
+func (ly *Layout) RenderOrder() ki.Slice {
+	key := func(k ki.Ki) (int, bool) {
+		if ni := k.(Node2D).AsWidget(); ni != nil {
+			return ni.Sty.Layout.ZIndex, ni.Sty.Layout.IsPositioned()
+		}
+		return 0, false
+	}
+	sorted := true
+	for _, c := range ly.Kids {
+		if z, pos := key(c); z != 0 || pos {
+			sorted = false
+			break
+		}
+	}
+	if sorted {
+		return ly.Kids
+	}
+	kids := make(ki.Slice, len(ly.Kids))
+	copy(kids, ly.Kids)
+	sort.SliceStable(kids, func(i, j int) bool {
+		zi, pi := key(kids[i])
+		zj, pj := key(kids[j])
+		if zi != zj {
+			return zi < zj
+		}
+		return !pi && pj
+	})
+	return kids
+}
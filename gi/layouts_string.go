@@ -9,9 +9,9 @@ import (
 
 var _ = errors.New("dummy error")
 
-const _Layouts_name = "LayoutHorizLayoutVertLayoutGridLayoutHorizFlowLayoutVertFlowLayoutStackedLayoutNilLayoutsN"
+const _Layouts_name = "LayoutHorizLayoutVertLayoutGridLayoutHorizFlowLayoutVertFlowLayoutStackedLayoutNilLayoutGridIrregLayoutFlexLayoutsN"
 
-var _Layouts_index = [...]uint8{0, 11, 21, 31, 46, 60, 73, 82, 90}
+var _Layouts_index = [...]uint8{0, 11, 21, 31, 46, 60, 73, 82, 97, 107, 115}
 
 func (i Layouts) String() string {
 	if i < 0 || i >= Layouts(len(_Layouts_index)-1) {
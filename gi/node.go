@@ -312,13 +312,21 @@ func (nb *NodeBase) StyleProps(selector string) ki.Props {
 	return nil
 }
 
-// AggCSS aggregates css properties
+// AggCSS aggregates css properties -- the rules of css come after those of
+// agg in the combined source order (see CSSOrderKey), if either has one
 func AggCSS(agg *ki.Props, css ki.Props) {
 	if *agg == nil {
 		*agg = make(ki.Props, len(css))
 	}
+	_, aord := (*agg)[CSSOrderKey]
+	_, cord := css[CSSOrderKey]
+	if aord || cord {
+		(*agg)[CSSOrderKey] = cssMergeOrder(*agg, css)
+	}
 	for key, val := range css {
-		(*agg)[key] = val
+		if key != CSSOrderKey {
+			(*agg)[key] = val
+		}
 	}
 }
 
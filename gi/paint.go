@@ -69,6 +69,7 @@ type Paint struct {
 	TextStyle   TextStyle    `desc:"font also has global opacity setting, along with generic color, background-color settings, which can be copied into stroke / fill as needed"`
 	VecEff      VectorEffect `xml:"vector-effect" desc:"prop: vector-effect = various rendering special effects settings"`
 	XForm       Matrix2D     `xml:"transform" desc:"prop: transform = our additions to transform -- pushed to render state"`
+	Vars        ki.Props     `xml:"-" json:"-" view:"-" desc:"custom properties (CSS variables) defined on this element or inherited from its parents -- see Style.Vars"`
 	dotsSet     bool
 	lastUnCtxt  units.Context
 }
@@ -125,16 +126,19 @@ func (pc *Paint) CopyStyleFrom(cp *Paint) {
 func (pc *Paint) InheritFields(par *Paint) {
 	pc.FontStyle.InheritFields(&par.FontStyle)
 	pc.TextStyle.InheritFields(&par.TextStyle)
+	pc.Vars = par.Vars
 }
 
 // SetStyleProps sets paint values based on given property map (name: value
 // pairs), inheriting elements as appropriate from parent, and also having a
-// default style for the "initial" setting
+// default style for the "initial" setting -- custom properties, var()
+// references and @media conditions are resolved as for Style
 func (pc *Paint) SetStyleProps(par *Paint, props ki.Props, vp *Viewport2D) {
 	if !pc.StyleSet && par != nil { // first time
 		// PaintFields.Inherit(pc, par) // very slow..
 		pc.InheritFields(par)
 	}
+	props = CSSResolveProps(props, &pc.Vars, vp)
 	PaintFields.Style(pc, par, props, vp)
 	pc.StrokeStyle.SetStylePost(props)
 	pc.FillStyle.SetStylePost(props)
@@ -242,7 +246,10 @@ type RenderState struct {
 	XFormStack     []Matrix2D        `desc:"stack of transforms"`
 	BoundsStack    []image.Rectangle `desc:"stack of bounds -- every render starts with a push onto this stack, and finishes with a pop"`
 	ClipStack      []*image.Alpha    `desc:"stack of clips, if needed"`
+	ImageStack     []*image.RGBA     `desc:"stack of images being rendered into -- see PushImage"`
 	PaintBack      Paint             `desc:"backup of paint -- don't need a full stack but sometimes safer to backup and restore"`
+	Backend        PaintBackend      `desc:"backend that paths, images and text are drawn through -- nil uses RasterBackend, to render into Image -- see VectorBackend for vector output"`
+	BackendStack   []PaintBackend    `desc:"stack of backends, saved by PushImage"`
 	RenderMu       sync.Mutex        `desc:"mutex for overall rendering"`
 	RasterMu       sync.Mutex        `desc:"mutex for final rasterx rendering -- only one at a time"`
 }
@@ -379,6 +386,46 @@ func (rs *RenderState) PopClip() {
 	rs.ClipStack = rs.ClipStack[:sz-1]
 }
 
+// PushImage pushes the current Image onto the stack and redirects all
+// rendering into given image, which must have the same bounds, through the
+// RasterBackend -- used for rendering into offscreen layers that are then
+// composited into the original image, e.g., for clipping and masking (see
+// DrawLayer).  Protects within render mutex lock.
+func (rs *RenderState) PushImage(img *image.RGBA) {
+	rs.RenderMu.Lock()
+	defer rs.RenderMu.Unlock()
+
+	if rs.ImageStack == nil {
+		rs.ImageStack = make([]*image.RGBA, 0, 10)
+	}
+	rs.ImageStack = append(rs.ImageStack, rs.Image)
+	rs.BackendStack = append(rs.BackendStack, rs.Backend)
+	rs.Image = img
+	rs.Backend = nil
+	rs.ImgSpanner.SetImage(img)
+}
+
+// PopImage pops Image off the stack, restoring rendering into it -- must be
+// equally balanced with corresponding PushImage.  Protects within render
+// mutex lock.
+func (rs *RenderState) PopImage() {
+	rs.RenderMu.Lock()
+	defer rs.RenderMu.Unlock()
+
+	sz := len(rs.ImageStack)
+	if sz == 0 {
+		log.Printf("gi.RenderState PopImage: stack is empty -- programmer error\n")
+		return
+	}
+	rs.Image = rs.ImageStack[sz-1]
+	rs.ImageStack[sz-1] = nil
+	rs.ImageStack = rs.ImageStack[:sz-1]
+	rs.Backend = rs.BackendStack[sz-1]
+	rs.BackendStack[sz-1] = nil
+	rs.BackendStack = rs.BackendStack[:sz-1]
+	rs.ImgSpanner.SetImage(rs.Image)
+}
+
 // BackupPaint copies style settings from Paint to PaintBack
 func (rs *RenderState) BackupPaint() {
 	rs.PaintBack.CopyStyleFrom(&rs.Paint)
@@ -561,77 +608,13 @@ func (pc *Paint) StrokeWidth(rs *RenderState) float32 {
 
 func (pc *Paint) stroke(rs *RenderState) {
 	pr := prof.Start("Paint.stroke")
-
-	rs.RasterMu.Lock()
-	defer rs.RasterMu.Unlock()
-
-	dash := pc.StrokeStyle.Dashes
-	if dash != nil {
-		scx, scy := rs.XForm.ExtractScale()
-		sc := 0.5 * (math.Abs(float64(scx)) + math.Abs(float64(scy)))
-		hasZero := false
-		for i := range dash {
-			dash[i] *= sc
-			if dash[i] < 1 {
-				hasZero = true
-				break
-			}
-		}
-		if hasZero {
-			dash = nil
-		}
-	}
-
-	rs.Raster.SetStroke(
-		Float32ToFixed(pc.StrokeWidth(rs)),
-		Float32ToFixed(pc.StrokeStyle.MiterLimit),
-		pc.capfunc(), nil, nil, pc.joinmode(), // todo: supports leading / trailing caps, and "gaps"
-		dash, 0)
-	rs.Scanner.SetClip(rs.Bounds)
-	rs.Path.AddTo(rs.Raster)
-	fbox := rs.Raster.Scanner.GetPathExtent()
-	// fmt.Printf("node: %v fbox: %v\n", g.Nm, fbox)
-	rs.LastRenderBBox = image.Rectangle{Min: image.Point{fbox.Min.X.Floor(), fbox.Min.Y.Floor()},
-		Max: image.Point{fbox.Max.X.Ceil(), fbox.Max.Y.Ceil()}}
-	rs.Raster.SetColor(pc.StrokeStyle.Color.RenderColor(pc.FontStyle.Opacity*pc.StrokeStyle.Opacity, rs.LastRenderBBox, rs.XForm))
-	rs.Raster.Draw()
-	rs.Raster.Clear()
-
-	/*
-		rs.CompSpanner.DrawToImage(rs.Image)
-		rs.CompSpanner.Clear()
-	*/
-
+	rs.PaintBackend().Stroke(rs, pc)
 	pr.End()
 }
 
 func (pc *Paint) fill(rs *RenderState) {
 	pr := prof.Start("Paint.fill")
-
-	rs.RasterMu.Lock()
-	defer rs.RasterMu.Unlock()
-
-	rf := &rs.Raster.Filler
-	rf.SetWinding(pc.FillStyle.Rule == FillRuleNonZero)
-	rs.Scanner.SetClip(rs.Bounds)
-	rs.Path.AddTo(rf)
-	fbox := rs.Scanner.GetPathExtent()
-	// fmt.Printf("node: %v fbox: %v\n", g.Nm, fbox)
-	rs.LastRenderBBox = image.Rectangle{Min: image.Point{fbox.Min.X.Floor(), fbox.Min.Y.Floor()},
-		Max: image.Point{fbox.Max.X.Ceil(), fbox.Max.Y.Ceil()}}
-	if pc.FillStyle.Color.Source == RadialGradient {
-		rf.SetColor(pc.FillStyle.Color.RenderColor(pc.FontStyle.Opacity*pc.FillStyle.Opacity, rs.LastRenderBBox, rs.XForm))
-	} else {
-		rf.SetColor(pc.FillStyle.Color.RenderColor(pc.FontStyle.Opacity*pc.FillStyle.Opacity, rs.LastRenderBBox, rs.XForm))
-	}
-	rf.Draw()
-	rf.Clear()
-
-	/*
-		rs.CompSpanner.DrawToImage(rs.Image)
-		rs.CompSpanner.Clear()
-	*/
-
+	rs.PaintBackend().Fill(rs, pc)
 	pr.End()
 }
 
@@ -668,7 +651,7 @@ func (pc *Paint) Fill(rs *RenderState) {
 func (pc *Paint) FillBox(rs *RenderState, pos, size Vec2D, clr *ColorSpec) {
 	if clr.Source == SolidColor {
 		b := rs.Bounds.Intersect(RectFromPosSizeMax(pos, size))
-		draw.Draw(rs.Image, b, &image.Uniform{clr.Color}, image.ZP, draw.Src)
+		rs.PaintBackend().FillBox(rs, b, clr.Color)
 	} else {
 		pc.FillStyle.SetColorSpec(clr)
 		pc.DrawRectangle(rs, pos.X, pos.Y, size.X, size.Y)
@@ -679,7 +662,7 @@ func (pc *Paint) FillBox(rs *RenderState, pos, size Vec2D, clr *ColorSpec) {
 // FillBoxColor is an optimized fill of a square region with given uniform color
 func (pc *Paint) FillBoxColor(rs *RenderState, pos, size Vec2D, clr color.Color) {
 	b := rs.Bounds.Intersect(RectFromPosSizeMax(pos, size))
-	draw.Draw(rs.Image, b, &image.Uniform{clr}, image.ZP, draw.Src)
+	rs.PaintBackend().FillBox(rs, b, clr)
 }
 
 // ClipPreserve updates the clipping region by intersecting the current
@@ -737,8 +720,7 @@ func (pc *Paint) ResetClip(rs *RenderState) {
 
 // Clear fills the entire image with the current fill color.
 func (pc *Paint) Clear(rs *RenderState) {
-	src := image.NewUniform(&pc.FillStyle.Color.Color)
-	draw.Draw(rs.Image, rs.Image.Bounds(), src, image.ZP, draw.Src)
+	rs.PaintBackend().FillBox(rs, rs.Image.Bounds(), &pc.FillStyle.Color.Color)
 }
 
 // SetPixel sets the color of the specified pixel using the current stroke color.
@@ -1012,18 +994,8 @@ func (pc *Paint) DrawImageAnchored(rs *RenderState, fmIm image.Image, x, y int,
 	s := rs.Image.Bounds().Size()
 	x -= int(ax * float32(s.X))
 	y -= int(ay * float32(s.Y))
-	transformer := draw.BiLinear
 	fx, fy := float32(x), float32(y)
-	m := rs.XForm.Translate(fx, fy)
-	s2d := f64.Aff3{float64(m.XX), float64(m.XY), float64(m.X0), float64(m.YX), float64(m.YY), float64(m.Y0)}
-	if rs.Mask == nil {
-		transformer.Transform(rs.Image, s2d, fmIm, fmIm.Bounds(), draw.Over, nil)
-	} else {
-		transformer.Transform(rs.Image, s2d, fmIm, fmIm.Bounds(), draw.Over, &draw.Options{
-			DstMask:  rs.Mask,
-			DstMaskP: image.ZP,
-		})
-	}
+	rs.PaintBackend().DrawImage(rs, fmIm, rs.XForm.Translate(fx, fy))
 }
 
 //////////////////////////////////////////////////////////////////////////////////
@@ -0,0 +1,227 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gi
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/f64"
+)
+
+// PaintBackend is the interface through which Paint and TextRender draw the
+// results of rendering: the current path of the RenderState (rs.Path, in
+// transformed image coordinates) is filled or stroked according to the
+// styles of the Paint, and boxes, images and glyphs are drawn.  All methods
+// must set rs.LastRenderBBox where noted, as SVG nodes use it for their
+// bounding boxes.  RasterBackend is the default, rendering into the Image of
+// the RenderState, and VectorBackend sends everything to a VectorWriter,
+// e.g., for PDF or SVG output.
+type PaintBackend interface {
+	// Fill fills the current path with the fill style of given Paint, setting
+	// rs.LastRenderBBox to the bounds of the path
+	Fill(rs *RenderState, pc *Paint)
+
+	// Stroke strokes the current path with the stroke style of given Paint,
+	// setting rs.LastRenderBBox to the bounds of the stroke
+	Stroke(rs *RenderState, pc *Paint)
+
+	// FillBox fills given box, in image coordinates, with given uniform
+	// color, replacing what was there
+	FillBox(rs *RenderState, b image.Rectangle, clr color.Color)
+
+	// DrawImage draws given image, with given transform from image pixel
+	// coordinates to the image coordinates of the RenderState
+	DrawImage(rs *RenderState, img image.Image, xf Matrix2D)
+
+	// DrawGlyph draws rune r in given font face and color, with its
+	// lower-left baseline at given position in image coordinates, rotated by
+	// given angle in radians, and scaled by scx in the X dimension
+	DrawGlyph(rs *RenderState, face font.Face, r rune, clr color.Color, pos Vec2D, rot, scx float32)
+}
+
+// PaintBackend returns the backend to render through -- Backend if set, and
+// otherwise the RasterBackend
+func (rs *RenderState) PaintBackend() PaintBackend {
+	if rs.Backend != nil {
+		return rs.Backend
+	}
+	return TheRasterBackend
+}
+
+// DrawLayer draws given layer, an image the size of the render image that
+// has been rendered into via PushImage, over the current bounds of the
+// render image, through given alpha mask of the same size, if non-nil.  The
+// RasterBackend draws it directly, and other backends are given the region of
+// the masked layer that has any content as an image, via DrawImage -- e.g.,
+// the VectorBackend embeds it as a raster image.  Must be called under the
+// render lock.
+func (rs *RenderState) DrawLayer(layer *image.RGBA, mask *image.Alpha) {
+	if rs.Backend == nil {
+		if mask == nil {
+			draw.Draw(rs.Image, rs.Bounds, layer, rs.Bounds.Min, draw.Over)
+		} else {
+			draw.DrawMask(rs.Image, rs.Bounds, layer, rs.Bounds.Min, mask, rs.Bounds.Min, draw.Over)
+		}
+		return
+	}
+	b := layerBounds(layer, mask, rs.Bounds)
+	if b.Empty() {
+		return
+	}
+	img := image.NewRGBA(image.Rectangle{Max: b.Size()})
+	if mask == nil {
+		draw.Draw(img, img.Bounds(), layer, b.Min, draw.Src)
+	} else {
+		draw.DrawMask(img, img.Bounds(), layer, b.Min, mask, b.Min, draw.Over)
+	}
+	rs.Backend.DrawImage(rs, img, Translate2D(float32(b.Min.X), float32(b.Min.Y)))
+}
+
+// layerBounds returns the bounds of the pixels of given layer within given
+// region that are visible through given mask, if non-nil
+func layerBounds(layer *image.RGBA, mask *image.Alpha, region image.Rectangle) image.Rectangle {
+	region = region.Intersect(layer.Bounds())
+	var b image.Rectangle
+	for y := region.Min.Y; y < region.Max.Y; y++ {
+		for x := region.Min.X; x < region.Max.X; x++ {
+			if layer.Pix[layer.PixOffset(x, y)+3] == 0 {
+				continue
+			}
+			if mask != nil && mask.AlphaAt(x, y).A == 0 {
+				continue
+			}
+			b = b.Union(image.Rect(x, y, x+1, y+1))
+		}
+	}
+	return b
+}
+
+// RasterBackend is the default PaintBackend, which rasterizes paths using
+// rasterx, and draws everything into the Image of the RenderState
+type RasterBackend struct {
+}
+
+// TheRasterBackend is the RasterBackend used for all RenderStates that do not
+// have another Backend set
+var TheRasterBackend = &RasterBackend{}
+
+func (rb *RasterBackend) Stroke(rs *RenderState, pc *Paint) {
+	rs.RasterMu.Lock()
+	defer rs.RasterMu.Unlock()
+
+	dash := pc.StrokeStyle.Dashes
+	if dash != nil {
+		scx, scy := rs.XForm.ExtractScale()
+		sc := 0.5 * (math.Abs(float64(scx)) + math.Abs(float64(scy)))
+		hasZero := false
+		for i := range dash {
+			dash[i] *= sc
+			if dash[i] < 1 {
+				hasZero = true
+				break
+			}
+		}
+		if hasZero {
+			dash = nil
+		}
+	}
+
+	rs.Raster.SetStroke(
+		Float32ToFixed(pc.StrokeWidth(rs)),
+		Float32ToFixed(pc.StrokeStyle.MiterLimit),
+		pc.capfunc(), nil, nil, pc.joinmode(), // todo: supports leading / trailing caps, and "gaps"
+		dash, 0)
+	rs.Scanner.SetClip(rs.Bounds)
+	rs.Path.AddTo(rs.Raster)
+	fbox := rs.Raster.Scanner.GetPathExtent()
+	// fmt.Printf("node: %v fbox: %v\n", g.Nm, fbox)
+	rs.LastRenderBBox = image.Rectangle{Min: image.Point{fbox.Min.X.Floor(), fbox.Min.Y.Floor()},
+		Max: image.Point{fbox.Max.X.Ceil(), fbox.Max.Y.Ceil()}}
+	rs.Raster.SetColor(pc.StrokeStyle.Color.RenderColor(pc.FontStyle.Opacity*pc.StrokeStyle.Opacity, rs.LastRenderBBox, rs.XForm))
+	rs.Raster.Draw()
+	rs.Raster.Clear()
+
+	/*
+		rs.CompSpanner.DrawToImage(rs.Image)
+		rs.CompSpanner.Clear()
+	*/
+}
+
+func (rb *RasterBackend) Fill(rs *RenderState, pc *Paint) {
+	rs.RasterMu.Lock()
+	defer rs.RasterMu.Unlock()
+
+	rf := &rs.Raster.Filler
+	rf.SetWinding(pc.FillStyle.Rule == FillRuleNonZero)
+	rs.Scanner.SetClip(rs.Bounds)
+	rs.Path.AddTo(rf)
+	fbox := rs.Scanner.GetPathExtent()
+	// fmt.Printf("node: %v fbox: %v\n", g.Nm, fbox)
+	rs.LastRenderBBox = image.Rectangle{Min: image.Point{fbox.Min.X.Floor(), fbox.Min.Y.Floor()},
+		Max: image.Point{fbox.Max.X.Ceil(), fbox.Max.Y.Ceil()}}
+	rf.SetColor(pc.FillStyle.Color.RenderColor(pc.FontStyle.Opacity*pc.FillStyle.Opacity, rs.LastRenderBBox, rs.XForm))
+	rf.Draw()
+	rf.Clear()
+
+	/*
+		rs.CompSpanner.DrawToImage(rs.Image)
+		rs.CompSpanner.Clear()
+	*/
+}
+
+func (rb *RasterBackend) FillBox(rs *RenderState, b image.Rectangle, clr color.Color) {
+	draw.Draw(rs.Image, b, &image.Uniform{clr}, image.ZP, draw.Src)
+}
+
+func (rb *RasterBackend) DrawImage(rs *RenderState, img image.Image, xf Matrix2D) {
+	transformer := draw.BiLinear
+	s2d := f64.Aff3{float64(xf.XX), float64(xf.XY), float64(xf.X0), float64(xf.YX), float64(xf.YY), float64(xf.Y0)}
+	if rs.Mask == nil {
+		transformer.Transform(rs.Image, s2d, img, img.Bounds(), draw.Over, nil)
+	} else {
+		transformer.Transform(rs.Image, s2d, img, img.Bounds(), draw.Over, &draw.Options{
+			DstMask:  rs.Mask,
+			DstMaskP: image.ZP,
+		})
+	}
+}
+
+func (rb *RasterBackend) DrawGlyph(rs *RenderState, face font.Face, r rune, clr color.Color, pos Vec2D, rot, scx float32) {
+	src := image.NewUniform(clr)
+	dr, mask, maskp, _, ok := face.Glyph(pos.Fixed(), r)
+	if !ok {
+		// fmt.Printf("not ok rendering rune: %v\n", string(r))
+		return
+	}
+	if rot == 0 && scx == 1 {
+		idr := dr.Intersect(rs.Bounds)
+		soff := image.ZP
+		if dr.Min.X < rs.Bounds.Min.X {
+			soff.X = rs.Bounds.Min.X - dr.Min.X
+			maskp.X += rs.Bounds.Min.X - dr.Min.X
+		}
+		if dr.Min.Y < rs.Bounds.Min.Y {
+			soff.Y = rs.Bounds.Min.Y - dr.Min.Y
+			maskp.Y += rs.Bounds.Min.Y - dr.Min.Y
+		}
+		draw.DrawMask(rs.Image, idr, src, soff, mask, maskp, draw.Over)
+		return
+	}
+	srect := dr.Sub(dr.Min)
+	dbase := Vec2D{pos.X - float32(dr.Min.X), pos.Y - float32(dr.Min.Y)}
+
+	transformer := draw.BiLinear
+	fx, fy := float32(dr.Min.X), float32(dr.Min.Y)
+	m := Translate2D(fx+dbase.X, fy+dbase.Y).Scale(scx, 1).Rotate(rot).Translate(-dbase.X, -dbase.Y)
+	s2d := f64.Aff3{float64(m.XX), float64(m.XY), float64(m.X0), float64(m.YX), float64(m.YY), float64(m.Y0)}
+	transformer.Transform(rs.Image, s2d, src, srect, draw.Over, &draw.Options{
+		SrcMask:  mask,
+		SrcMaskP: maskp,
+	})
+}
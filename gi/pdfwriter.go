@@ -0,0 +1,346 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gi
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// PDFDotsPerPt is the number of page coordinate units (image pixels) per PDF
+// point, which is 1/72 inch -- the default of 96 / 72 gives the standard
+// size for 96 DPI rendering
+var PDFDotsPerPt = float32(96.0 / 72.0)
+
+// PDFWriter is a VectorWriter that writes a single-page PDF document -- text
+// is written in the standard PDF fonts (Helvetica, Times and Courier), chosen
+// to match the rendered font, which only have Latin-1 characters -- others
+// are drawn as images of their glyphs (see HasRune)
+type PDFWriter struct {
+	Size    image.Point       `desc:"size of the page, in page coordinates"`
+	content bytes.Buffer      `desc:"page content stream"`
+	objs    [][]byte          `desc:"extra objects, numbered from pdfFirstObj"`
+	fonts   map[string]string `desc:"resource names of base fonts used"`
+	alphas  map[uint8]string  `desc:"resource names of graphics states for alpha values used"`
+	images  []string          `desc:"resource entries for images"`
+}
+
+// pdfFirstObj is the number of the first extra object, after the catalog,
+// pages, page and content stream objects
+const pdfFirstObj = 5
+
+// NewPDFWriter returns a new PDFWriter for a page of given size
+func NewPDFWriter(size image.Point) *PDFWriter {
+	pw := &PDFWriter{Size: size, fonts: map[string]string{}, alphas: map[uint8]string{}}
+	sc := 1 / PDFDotsPerPt
+	fmt.Fprintf(&pw.content, "%v 0 0 %v 0 %v cm\n", pdfNum(sc), pdfNum(-sc), pdfNum(float32(size.Y)*sc))
+	return pw
+}
+
+// pdfNum formats a number for PDF
+func pdfNum(v float32) string {
+	return strconv.FormatFloat(float64(v), 'f', -1, 32)
+}
+
+// addObj adds an object, returning its number
+func (pw *PDFWriter) addObj(obj []byte) int {
+	pw.objs = append(pw.objs, obj)
+	return pdfFirstObj + len(pw.objs) - 1
+}
+
+// addStream adds a stream object with given dictionary entries, compressing
+// the data, and returns its number
+func (pw *PDFWriter) addStream(dict string, data []byte) int {
+	var zb bytes.Buffer
+	zw := zlib.NewWriter(&zb)
+	zw.Write(data)
+	zw.Close()
+	var ob bytes.Buffer
+	fmt.Fprintf(&ob, "<< %v /Filter /FlateDecode /Length %v >>\nstream\n", dict, zb.Len())
+	ob.Write(zb.Bytes())
+	ob.WriteString("\nendstream")
+	return pw.addObj(ob.Bytes())
+}
+
+// begin starts a drawing operation clipped to given rectangle, with given
+// color set for fill or stroke
+func (pw *PDFWriter) begin(clip image.Rectangle, clr color.Color, stroke bool) {
+	c := &pw.content
+	c.WriteString("q\n")
+	fmt.Fprintf(c, "%v %v %v %v re W n\n", clip.Min.X, clip.Min.Y, clip.Dx(), clip.Dy())
+	nc := color.NRGBAModel.Convert(clr).(color.NRGBA)
+	op := "rg"
+	if stroke {
+		op = "RG"
+	}
+	fmt.Fprintf(c, "%v %v %v %v\n", pdfNum(float32(nc.R)/255), pdfNum(float32(nc.G)/255), pdfNum(float32(nc.B)/255), op)
+	if nc.A < 255 {
+		fmt.Fprintf(c, "/%v gs\n", pw.alpha(nc.A))
+	}
+}
+
+// alpha returns the resource name of the graphics state for given alpha
+func (pw *PDFWriter) alpha(a uint8) string {
+	if nm, ok := pw.alphas[a]; ok {
+		return nm
+	}
+	nm := fmt.Sprintf("GS%v", len(pw.alphas))
+	pw.alphas[a] = nm
+	return nm
+}
+
+// path writes the operations of given path
+func (pw *PDFWriter) path(path VecPath) {
+	c := &pw.content
+	var cur Vec2D
+	for _, op := range path {
+		pts := op.Pts
+		switch op.Op {
+		case 'M':
+			fmt.Fprintf(c, "%v %v m\n", pdfNum(pts[0].X), pdfNum(pts[0].Y))
+		case 'L':
+			fmt.Fprintf(c, "%v %v l\n", pdfNum(pts[0].X), pdfNum(pts[0].Y))
+		case 'Q': // as cubic
+			c1 := cur.Add(pts[0].Sub(cur).MulVal(2.0 / 3.0))
+			c2 := pts[1].Add(pts[0].Sub(pts[1]).MulVal(2.0 / 3.0))
+			fmt.Fprintf(c, "%v %v %v %v %v %v c\n", pdfNum(c1.X), pdfNum(c1.Y), pdfNum(c2.X), pdfNum(c2.Y), pdfNum(pts[1].X), pdfNum(pts[1].Y))
+		case 'C':
+			fmt.Fprintf(c, "%v %v %v %v %v %v c\n", pdfNum(pts[0].X), pdfNum(pts[0].Y), pdfNum(pts[1].X), pdfNum(pts[1].Y), pdfNum(pts[2].X), pdfNum(pts[2].Y))
+		case 'Z':
+			c.WriteString("h\n")
+		}
+		if len(pts) > 0 {
+			cur = pts[len(pts)-1]
+		}
+	}
+}
+
+func (pw *PDFWriter) FillPath(path VecPath, clr color.Color, nonZero bool, clip image.Rectangle) {
+	pw.begin(clip, clr, false)
+	pw.path(path)
+	if nonZero {
+		pw.content.WriteString("f\nQ\n")
+	} else {
+		pw.content.WriteString("f*\nQ\n")
+	}
+}
+
+func (pw *PDFWriter) StrokePath(path VecPath, clr color.Color, st *VecStroke, clip image.Rectangle) {
+	pw.begin(clip, clr, true)
+	c := &pw.content
+	cp := 0
+	switch st.Cap {
+	case LineCapRound:
+		cp = 1
+	case LineCapSquare:
+		cp = 2
+	}
+	jn := 0
+	switch st.Join {
+	case LineJoinRound, LineJoinArcs, LineJoinArcsClip:
+		jn = 1
+	case LineJoinBevel:
+		jn = 2
+	}
+	fmt.Fprintf(c, "%v w %v J %v j %v M\n", pdfNum(st.Width), cp, jn, pdfNum(Max32(st.MiterLimit, 1)))
+	if len(st.Dashes) > 0 {
+		ds := make([]string, len(st.Dashes))
+		for i, d := range st.Dashes {
+			ds[i] = pdfNum(float32(d))
+		}
+		fmt.Fprintf(c, "[%v] 0 d\n", strings.Join(ds, " "))
+	}
+	pw.path(path)
+	c.WriteString("S\nQ\n")
+}
+
+func (pw *PDFWriter) DrawImage(img image.Image, xf Matrix2D, clip image.Rectangle) {
+	b := img.Bounds()
+	if b.Empty() {
+		return
+	}
+	rgb := make([]byte, 0, 3*b.Dx()*b.Dy())
+	alpha := make([]byte, 0, b.Dx()*b.Dy())
+	opaque := true
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			nc := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			rgb = append(rgb, nc.R, nc.G, nc.B)
+			alpha = append(alpha, nc.A)
+			if nc.A != 255 {
+				opaque = false
+			}
+		}
+	}
+	dict := fmt.Sprintf("/Type /XObject /Subtype /Image /Width %v /Height %v /ColorSpace /DeviceRGB /BitsPerComponent 8", b.Dx(), b.Dy())
+	if !opaque {
+		smask := pw.addStream(fmt.Sprintf("/Type /XObject /Subtype /Image /Width %v /Height %v /ColorSpace /DeviceGray /BitsPerComponent 8", b.Dx(), b.Dy()), alpha)
+		dict += fmt.Sprintf(" /SMask %v 0 R", smask)
+	}
+	obj := pw.addStream(dict, rgb)
+	nm := fmt.Sprintf("Im%v", len(pw.images))
+	pw.images = append(pw.images, fmt.Sprintf("/%v %v 0 R", nm, obj))
+	// image space is the unit square, with the first row at the top
+	m := Matrix2D{XX: float32(b.Dx()), YY: -float32(b.Dy()), X0: float32(b.Min.X), Y0: float32(b.Max.Y)}.Multiply(xf)
+	c := &pw.content
+	c.WriteString("q\n")
+	fmt.Fprintf(c, "%v %v %v %v re W n\n", clip.Min.X, clip.Min.Y, clip.Dx(), clip.Dy())
+	fmt.Fprintf(c, "%v %v %v %v %v %v cm\n", pdfNum(m.XX), pdfNum(m.YX), pdfNum(m.XY), pdfNum(m.YY), pdfNum(m.X0), pdfNum(m.Y0))
+	fmt.Fprintf(c, "/%v Do\nQ\n", nm)
+}
+
+// PDFBaseFont returns the name of the standard PDF font to use for given
+// font
+func PDFBaseFont(fi FontInfo) string {
+	nm := strings.ToLower(fi.Name)
+	bold := fi.Weight >= Weight600 || strings.Contains(nm, "bold")
+	ital := fi.Style != FontNormal || strings.Contains(nm, "italic") || strings.Contains(nm, "oblique")
+	switch {
+	case strings.Contains(nm, "mono") || strings.Contains(nm, "courier"):
+		switch {
+		case bold && ital:
+			return "Courier-BoldOblique"
+		case bold:
+			return "Courier-Bold"
+		case ital:
+			return "Courier-Oblique"
+		}
+		return "Courier"
+	case strings.Contains(nm, "times") || (strings.Contains(nm, "serif") && !strings.Contains(nm, "sans")):
+		switch {
+		case bold && ital:
+			return "Times-BoldItalic"
+		case bold:
+			return "Times-Bold"
+		case ital:
+			return "Times-Italic"
+		}
+		return "Times-Roman"
+	}
+	switch {
+	case bold && ital:
+		return "Helvetica-BoldOblique"
+	case bold:
+		return "Helvetica-Bold"
+	case ital:
+		return "Helvetica-Oblique"
+	}
+	return "Helvetica"
+}
+
+// font returns the resource name for given base font
+func (pw *PDFWriter) font(base string) string {
+	if nm, ok := pw.fonts[base]; ok {
+		return nm
+	}
+	nm := fmt.Sprintf("F%v", len(pw.fonts))
+	pw.fonts[base] = nm
+	return nm
+}
+
+// HasRune returns true if given rune is in the Latin-1 subset of
+// WinAnsiEncoding, which the standard PDF fonts have
+func (pw *PDFWriter) HasRune(r rune) bool {
+	return r >= 0x20 && r <= 0xff && (r < 0x7f || r >= 0xa0)
+}
+
+// pdfString returns the PDF literal string for given rune, in the Latin-1
+// subset of WinAnsiEncoding (see HasRune) -- others are written as ?
+func pdfString(r rune) string {
+	switch {
+	case r == '(' || r == ')' || r == '\\':
+		return `(\` + string(r) + `)`
+	case r < 0x20 || (r >= 0x7f && r < 0xa0) || r > 0xff:
+		return "(?)"
+	case r >= 0x80:
+		return fmt.Sprintf(`(\%03o)`, r)
+	}
+	return "(" + string(r) + ")"
+}
+
+func (pw *PDFWriter) DrawText(tr *VecTextRun, clip image.Rectangle) {
+	pw.begin(clip, tr.Color, false)
+	c := &pw.content
+	fmt.Fprintf(c, "BT\n/%v 1 Tf\n", pw.font(PDFBaseFont(tr.Font)))
+	for i, r := range tr.Runes {
+		m := tr.GlyphXForm(i)
+		fmt.Fprintf(c, "%v %v %v %v %v %v Tm %v Tj\n", pdfNum(m.XX), pdfNum(m.YX), pdfNum(m.XY), pdfNum(m.YY), pdfNum(m.X0), pdfNum(m.Y0), pdfString(r))
+	}
+	c.WriteString("ET\nQ\n")
+}
+
+func (pw *PDFWriter) Encode(w io.Writer) error {
+	objs := make([][]byte, pdfFirstObj-1, pdfFirstObj-1+len(pw.objs)+len(pw.fonts)+len(pw.alphas))
+	objs = append(objs, pw.objs...)
+	addObj := func(obj string) int {
+		objs = append(objs, []byte(obj))
+		return len(objs)
+	}
+
+	var res bytes.Buffer
+	res.WriteString("<<")
+	if len(pw.fonts) > 0 {
+		res.WriteString(" /Font <<")
+		bases := make([]string, 0, len(pw.fonts))
+		for base := range pw.fonts {
+			bases = append(bases, base)
+		}
+		sort.Strings(bases)
+		for _, base := range bases {
+			obj := addObj(fmt.Sprintf("<< /Type /Font /Subtype /Type1 /BaseFont /%v /Encoding /WinAnsiEncoding >>", base))
+			fmt.Fprintf(&res, " /%v %v 0 R", pw.fonts[base], obj)
+		}
+		res.WriteString(" >>")
+	}
+	if len(pw.alphas) > 0 {
+		res.WriteString(" /ExtGState <<")
+		as := make([]int, 0, len(pw.alphas))
+		for a := range pw.alphas {
+			as = append(as, int(a))
+		}
+		sort.Ints(as)
+		for _, a := range as {
+			av := pdfNum(float32(a) / 255)
+			obj := addObj(fmt.Sprintf("<< /Type /ExtGState /ca %v /CA %v >>", av, av))
+			fmt.Fprintf(&res, " /%v %v 0 R", pw.alphas[uint8(a)], obj)
+		}
+		res.WriteString(" >>")
+	}
+	if len(pw.images) > 0 {
+		fmt.Fprintf(&res, " /XObject << %v >>", strings.Join(pw.images, " "))
+	}
+	res.WriteString(" >>")
+
+	sz := NewVec2DFmPoint(pw.Size).DivVal(PDFDotsPerPt)
+	objs[0] = []byte("<< /Type /Catalog /Pages 2 0 R >>")
+	objs[1] = []byte("<< /Type /Pages /Kids [3 0 R] /Count 1 >>")
+	objs[2] = []byte(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %v %v] /Contents 4 0 R /Resources %v >>", pdfNum(sz.X), pdfNum(sz.Y), res.String()))
+	objs[3] = []byte(fmt.Sprintf("<< /Length %v >>\nstream\n%vendstream", pw.content.Len(), pw.content.String()))
+
+	var out bytes.Buffer
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	offs := make([]int, len(objs))
+	for i, obj := range objs {
+		offs[i] = out.Len()
+		fmt.Fprintf(&out, "%v 0 obj\n", i+1)
+		out.Write(obj)
+		out.WriteString("\nendobj\n")
+	}
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %v\n0000000000 65535 f \n", len(objs)+1)
+	for _, off := range offs {
+		fmt.Fprintf(&out, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %v /Root 1 0 R >>\nstartxref\n%v\n%%%%EOF\n", len(objs)+1, xref)
+	_, err := w.Write(out.Bytes())
+	return err
+}
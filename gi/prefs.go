@@ -72,12 +72,16 @@ type User struct {
 type Preferences struct {
 	LogicalDPIScale      float32                `min:"0.1" step:"0.1" desc:"overall scaling factor for Logical DPI as a multiplier on Physical DPI -- smaller numbers produce smaller font sizes etc"`
 	ScreenPrefs          map[string]ScreenPrefs `desc:"screen-specific preferences -- will override overall defaults if set"`
-	Colors               ColorPrefs             `desc:"color preferences"`
+	Colors               ColorPrefs             `view:"-" json:"-" xml:"-" desc:"current color preferences, set from the current theme -- edit the theme to change them"`
+	Theme                ThemeName              `desc:"select the active theme from list of available themes, which sets the colors and default styles -- see Edit Themes for editing / saving / loading that list"`
+	DarkTheme            ThemeName              `desc:"theme to use instead of Theme when FollowOSTheme is set and the OS is in dark mode"`
+	FollowOSTheme        bool                   `desc:"if set, the DarkTheme is used whenever the OS is set to dark mode, and the regular Theme otherwise -- the OS setting is checked periodically, and the display is updated when it changes"`
+	SaveThemes           bool                   `desc:"if set, the current available set of themes is saved to your preferences directory, and automatically loaded at startup -- this should be set if you are using custom themes"`
 	Params               ParamPrefs             `desc:"parameters controlling GUI behavior"`
 	KeyMap               KeyMapName             `desc:"select the active keymap from list of available keymaps -- see Edit KeyMaps for editing / saving / loading that list"`
 	SaveKeyMaps          bool                   `desc:"if set, the current available set of key maps is saved to your preferences directory, and automatically loaded at startup -- this should be set if you are using custom key maps, but it may be safer to keep it <i>OFF</i> if you are <i>not</i> using custom key maps, so that you'll always have the latest compiled-in standard key maps with all the current key functions bound to standard key chords"`
 	SaveDetailed         bool                   `desc:"if set, the detailed preferences are saved and loaded at startup -- only "`
-	CustomStyles         ki.Props               `desc:"a custom style sheet -- add a separate Props entry for each type of object, e.g., button, or class using .classname, or specific named element using #name -- all are case insensitive"`
+	CustomStyles         ki.Props               `desc:"a custom style sheet, added to that of the current theme -- add a separate Props entry for each type of object, e.g., button, or class using .classname, or specific named element using #name -- all are case insensitive"`
 	CustomStylesOverride bool                   `desc:"if true my custom styles override other styling (i.e., they come <i>last</i> in styling process -- otherwise they provide defaults that can be overridden by app-specific styling (i.e, they come first)."`
 	FontFamily           FontName               `desc:"default font family when otherwise not specified"`
 	FontPaths            []string               `desc:"extra font paths, beyond system defaults -- searched first"`
@@ -147,6 +151,8 @@ func (pf *Preferences) Defaults() {
 	pf.FontFamily = "Go"
 	pf.SavedPathsMax = 20
 	pf.KeyMap = DefaultKeyMap
+	pf.Theme = DefaultTheme
+	pf.DarkTheme = DefaultDarkTheme
 	pf.UpdateUser()
 }
 
@@ -166,6 +172,9 @@ func (pf *Preferences) Open() error {
 	if pf.SaveKeyMaps {
 		AvailKeyMaps.OpenPrefs()
 	}
+	if pf.SaveThemes {
+		AvailThemes.OpenPrefs()
+	}
 	if pf.SaveDetailed {
 		PrefsDet.Open()
 	}
@@ -175,6 +184,9 @@ func (pf *Preferences) Open() error {
 	}
 
 	pf.Changed = false
+	if err == nil && pf.migrateColors(b) {
+		err = pf.Save() // so they are only migrated once
+	}
 	return err
 }
 
@@ -194,6 +206,9 @@ func (pf *Preferences) Save() error {
 	if pf.SaveKeyMaps {
 		AvailKeyMaps.SavePrefs()
 	}
+	if pf.SaveThemes {
+		AvailThemes.SavePrefs()
+	}
 	if pf.SaveDetailed {
 		PrefsDet.Save()
 	}
@@ -201,9 +216,15 @@ func (pf *Preferences) Save() error {
 	return err
 }
 
-// OpenColors colors from a JSON-formatted file.
+// OpenColors colors from a JSON-formatted file, into the current theme,
+// which is then saved with the themes.
 func (pf *Preferences) OpenColors(filename FileName) error {
 	err := pf.Colors.OpenJSON(filename)
+	if th := pf.CurTheme(); err == nil && th != nil {
+		th.Colors = pf.Colors
+		pf.SaveThemes = true
+		AvailThemesChanged = true
+	}
 	// if err == nil {
 	// 	pf.Update() // no!  this recolors the dialog as it is closing!  do it separately
 	// }
@@ -231,6 +252,7 @@ func (pf *Preferences) Apply() {
 	mouse.ScrollWheelRate = pf.Params.ScrollWheelRate
 	LocalMainMenu = pf.Params.LocalMainMenu
 
+	pf.ApplyTheme()
 	if pf.KeyMap != "" {
 		SetActiveKeyMapName(pf.KeyMap) // fills in missing pieces
 	}
@@ -399,6 +421,14 @@ var PreferencesProps = ki.Props{
 				},
 			}},
 			{"sep-color", ki.BlankProp{}},
+			{"SetTheme", ki.Props{
+				"desc": "set the theme, which sets the colors and default styles, and update all open windows to use it",
+				"Args": ki.PropSlice{
+					{"Theme", ki.Props{
+						"default-field": "Theme",
+					}},
+				},
+			}},
 			{"OpenColors", ki.Props{
 				"desc": "open set of colors from a json-formatted file",
 				"Args": ki.PropSlice{
@@ -451,6 +481,15 @@ var PreferencesProps = ki.Props{
 			},
 		}},
 		{"sep-color", ki.BlankProp{}},
+		{"SetTheme", ki.Props{
+			"icon": "color",
+			"desc": "set the theme, which sets the colors and default styles, and update all open windows to use it",
+			"Args": ki.PropSlice{
+				{"Theme", ki.Props{
+					"default-field": "Theme",
+				}},
+			},
+		}},
 		{"Colors", ki.PropSlice{ // sub-menu
 			{"OpenColors", ki.Props{
 				"icon": "file-open",
@@ -498,6 +537,10 @@ var PreferencesProps = ki.Props{
 			"icon": "keyboard",
 			"desc": "opens the KeyMapsView editor to create new keymaps / save / load from other files, etc.  Current keymaps are saved and loaded with preferences automatically if SaveKeyMaps is clicked (will be turned on automatically if you open this editor).",
 		}},
+		{"EditThemes", ki.Props{
+			"icon": "color",
+			"desc": "opens the ThemesView editor to create new themes / save / load from other files, etc.  Current themes are saved and loaded with preferences automatically if SaveThemes is clicked (will be turned on automatically if you open this editor).",
+		}},
 		{"EditDetailed", ki.Props{
 			"icon": "file-binary",
 			"desc": "opens the PrefsDetView editor to edit detailed params that are not typically user-modified, but can be if you really care..  Turns on the SaveDetailed flag so these will be saved and loaded automatically -- can toggle that back off if you don't actually want to.",
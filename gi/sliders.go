@@ -138,7 +138,7 @@ func (sb *SliderBase) SetSliderState(state SliderStates) {
 		state = SliderFocus
 	}
 	sb.State = state
-	sb.Sty = sb.StateStyles[state] // get relevant styles
+	sb.TransitionStyle(&sb.StateStyles[state]) // get relevant styles
 }
 
 // SliderPressed sets the slider in the down state -- mouse clicked down but
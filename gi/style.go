@@ -40,15 +40,15 @@ type Style struct {
 	Text          TextStyle     `desc:"text parameters -- no xml prefix"`
 	Outline       BorderStyle   `xml:"outline" desc:"prop: outline = draw an outline around an element -- mostly same styles as border -- default to none"`
 	PointerEvents bool          `xml:"pointer-events" desc:"prop: pointer-events = does this element respond to pointer events -- default is true"`
+	Transition    string        `xml:"transition" desc:"prop: transition = style properties that change gradually when the style changes between states, e.g., hover, with their durations, easings and delays: background-color 150ms ease, color 0.1s -- see ParseTransitions"`
 	UnContext     units.Context `xml:"-" desc:"units context -- parameters necessary for anchoring relative units"`
+	Vars          ki.Props      `xml:"-" json:"-" view:"-" desc:"custom properties (CSS variables, e.g., --accent-color) defined on this element or inherited from its parents, which are substituted for var() references in property values -- see CSSResolveProps"`
 	IsSet         bool          `desc:"has this style been set from object values yet?"`
 	PropsNil      bool          `desc:"set to true if parent node has no props -- allows optimization of styling"`
 	dotsSet       bool
 	lastUnCtxt    units.Context
 }
 
-// todo: Animation
-
 // Clear -- no floating elements
 
 // Clip -- clip images
@@ -65,8 +65,6 @@ type Style struct {
 
 // visibility -- support more than just hidden  inherit:"true"
 
-// transition -- animation of hover, etc
-
 // RebuildDefaultStyles is a global state var used by Prefs to trigger rebuild
 // of all the default styles, which are otherwise compiled and not updated
 var RebuildDefaultStyles bool
@@ -223,15 +221,19 @@ func (s *Style) CopyFrom(cp *Style) {
 func (s *Style) InheritFields(par *Style) {
 	s.Font.InheritFields(&par.Font)
 	s.Text.InheritFields(&par.Text)
+	s.Vars = inheritCSSVars(s.Vars, par.Vars)
 }
 
 // SetStyleProps sets style values based on given property map (name: value pairs),
-// inheriting elements as appropriate from parent
+// inheriting elements as appropriate from parent -- custom properties,
+// var() references and @media conditions in props are resolved first (see
+// CSSResolveProps)
 func (s *Style) SetStyleProps(par *Style, props ki.Props, vp *Viewport2D) {
 	if !s.IsSet && par != nil { // first time
 		// StyleFields.Inherit(s, par) // very slow for some mysterious reason
 		s.InheritFields(par)
 	}
+	props = CSSResolveProps(props, &s.Vars, vp)
 	StyleFields.Style(s, par, props, vp)
 	s.Text.AlignV = s.Layout.AlignV
 	if s.Layout.Margin.Val > 0 && s.Text.ParaSpacing.Val == 0 {
@@ -314,40 +316,37 @@ func (s *Style) BoxSpace() float32 {
 
 // ApplyCSS applies css styles for given node, using key to select sub-props
 // from overall properties list, and optional selector to select a further
-// :name selector within that key
+// :name selector within that key -- the rule of key only applies if its
+// selector matches the node (see CSSMatchingProps)
 func (s *Style) ApplyCSS(node Node2D, css ki.Props, key, selector string, vp *Viewport2D) bool {
 	pp, got := css[key]
 	if !got {
 		return false
 	}
-	pmap, ok := pp.(ki.Props) // must be a props map
-	if !ok {
+	pmaps := CSSMatchingProps(node, ki.Props{key: pp}, selector)
+	if len(pmaps) == 0 {
 		return false
 	}
-	if selector != "" {
-		pmap, ok = SubProps(pmap, selector)
-		if !ok {
-			return false
-		}
-	}
 	parSty := node.AsNode2D().ParentStyle()
-	s.SetStyleProps(parSty, pmap, vp)
+	for _, pmap := range pmaps {
+		s.SetStyleProps(parSty, pmap, vp)
+	}
 	return true
 }
 
-// StyleCSS applies css style properties to given Widget node, parsing out
-// type, .class, and #name selectors, along with optional sub-selector
-// (:hover, :active etc)
+// StyleCSS applies css style properties to given Widget node, from the rules
+// whose selectors match it (see CSSSelector), in order of specificity --
+// optional selector selects the style of a state of the node (:hover,
+// :active etc), from the rules for that state and the state sub-properties
+// of the other rules
 func (s *Style) StyleCSS(node Node2D, css ki.Props, selector string, vp *Viewport2D) {
-	tyn := strings.ToLower(node.Type().Name()) // type is most general, first
-	s.ApplyCSS(node, css, tyn, selector, vp)
-	classes := strings.Split(strings.ToLower(node.AsNode2D().Class), " ")
-	for _, cl := range classes {
-		cln := "." + strings.TrimSpace(cl)
-		s.ApplyCSS(node, css, cln, selector, vp)
+	if len(css) == 0 {
+		return
+	}
+	parSty := node.AsNode2D().ParentStyle()
+	for _, pmap := range CSSMatchingProps(node, css, selector) {
+		s.SetStyleProps(parSty, pmap, vp)
 	}
-	idnm := "#" + strings.ToLower(node.Name()) // then name
-	s.ApplyCSS(node, css, idnm, selector, vp)
 }
 
 // SubProps returns a sub-property map from given prop map for a given styling
@@ -723,7 +722,8 @@ func (fld *StyledField) FromProps(fields map[string]*StyledField, objptr, parptr
 			case string:
 				tn := kit.FullTypeName(fld.Field.Type)
 				if kit.Enums.Enum(tn) != nil {
-					kit.Enums.SetAnyEnumIfaceFromString(fi, valv)
+					// css names are hyphenated, e.g., space-between, but enum names are not
+					kit.Enums.SetAnyEnumIfaceFromString(fi, strings.Replace(valv, "-", "", -1))
 				} else if tn == "..int" {
 					kit.SetRobust(fi, val)
 				} else {
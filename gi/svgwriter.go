@@ -0,0 +1,191 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gi
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"strings"
+)
+
+// SVGWriter is a VectorWriter that writes an SVG document
+type SVGWriter struct {
+	Size  image.Point             `desc:"size of the page, in page coordinates, which are the svg user units"`
+	body  bytes.Buffer            `desc:"the elements"`
+	defs  bytes.Buffer            `desc:"the clip path definitions"`
+	clips map[image.Rectangle]int `desc:"index of the clip path for each clip rectangle"`
+}
+
+// NewSVGWriter returns a new SVGWriter for a page of given size
+func NewSVGWriter(size image.Point) *SVGWriter {
+	return &SVGWriter{Size: size, clips: map[image.Rectangle]int{}}
+}
+
+// svgNum formats a number for svg
+func svgNum(v float32) string {
+	return pdfNum(v)
+}
+
+// clipAttr returns the clip-path attribute for given clip rectangle -- none
+// if it includes the entire page
+func (sw *SVGWriter) clipAttr(clip image.Rectangle) string {
+	if clip.Min.X <= 0 && clip.Min.Y <= 0 && clip.Max.X >= sw.Size.X && clip.Max.Y >= sw.Size.Y {
+		return ""
+	}
+	idx, ok := sw.clips[clip]
+	if !ok {
+		idx = len(sw.clips)
+		sw.clips[clip] = idx
+		fmt.Fprintf(&sw.defs, "<clipPath id=\"clip%v\"><rect x=\"%v\" y=\"%v\" width=\"%v\" height=\"%v\"/></clipPath>\n", idx, clip.Min.X, clip.Min.Y, clip.Dx(), clip.Dy())
+	}
+	return fmt.Sprintf(" clip-path=\"url(#clip%v)\"", idx)
+}
+
+// svgColorAttrs returns the attributes for given color, with given attribute
+// name (fill or stroke)
+func svgColorAttrs(nm string, clr color.Color) string {
+	nc := color.NRGBAModel.Convert(clr).(color.NRGBA)
+	s := fmt.Sprintf(" %v=\"#%02x%02x%02x\"", nm, nc.R, nc.G, nc.B)
+	if nc.A < 255 {
+		s += fmt.Sprintf(" %v-opacity=\"%v\"", nm, svgNum(float32(nc.A)/255))
+	}
+	return s
+}
+
+// SVGPathData returns the svg path data for given path
+func SVGPathData(path VecPath) string {
+	var sb strings.Builder
+	for i, op := range path {
+		if i > 0 {
+			sb.WriteByte(' ')
+		}
+		sb.WriteByte(op.Op)
+		for _, p := range op.Pts {
+			fmt.Fprintf(&sb, " %v,%v", svgNum(p.X), svgNum(p.Y))
+		}
+	}
+	return sb.String()
+}
+
+func (sw *SVGWriter) FillPath(path VecPath, clr color.Color, nonZero bool, clip image.Rectangle) {
+	rule := ""
+	if !nonZero {
+		rule = " fill-rule=\"evenodd\""
+	}
+	fmt.Fprintf(&sw.body, "<path d=\"%v\"%v%v%v/>\n", SVGPathData(path), svgColorAttrs("fill", clr), rule, sw.clipAttr(clip))
+}
+
+func (sw *SVGWriter) StrokePath(path VecPath, clr color.Color, st *VecStroke, clip image.Rectangle) {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, " stroke-width=\"%v\"", svgNum(st.Width))
+	switch st.Cap {
+	case LineCapRound:
+		sb.WriteString(" stroke-linecap=\"round\"")
+	case LineCapSquare:
+		sb.WriteString(" stroke-linecap=\"square\"")
+	}
+	switch st.Join {
+	case LineJoinRound, LineJoinArcs, LineJoinArcsClip:
+		sb.WriteString(" stroke-linejoin=\"round\"")
+	case LineJoinBevel:
+		sb.WriteString(" stroke-linejoin=\"bevel\"")
+	case LineJoinMiterClip:
+		sb.WriteString(" stroke-linejoin=\"miter-clip\"")
+	}
+	if st.MiterLimit > 0 && st.MiterLimit != 4 {
+		fmt.Fprintf(&sb, " stroke-miterlimit=\"%v\"", svgNum(st.MiterLimit))
+	}
+	if len(st.Dashes) > 0 {
+		ds := make([]string, len(st.Dashes))
+		for i, d := range st.Dashes {
+			ds[i] = svgNum(float32(d))
+		}
+		fmt.Fprintf(&sb, " stroke-dasharray=\"%v\"", strings.Join(ds, ","))
+	}
+	fmt.Fprintf(&sw.body, "<path d=\"%v\" fill=\"none\"%v%v%v/>\n", SVGPathData(path), svgColorAttrs("stroke", clr), sb.String(), sw.clipAttr(clip))
+}
+
+func (sw *SVGWriter) DrawImage(img image.Image, xf Matrix2D, clip image.Rectangle) {
+	b := img.Bounds()
+	if b.Empty() {
+		return
+	}
+	var pb bytes.Buffer
+	if err := png.Encode(&pb, img); err != nil {
+		return
+	}
+	m := Translate2D(float32(b.Min.X), float32(b.Min.Y)).Multiply(xf)
+	fmt.Fprintf(&sw.body, "<image width=\"%v\" height=\"%v\" transform=\"matrix(%v,%v,%v,%v,%v,%v)\"%v href=\"data:image/png;base64,%v\"/>\n", b.Dx(), b.Dy(), svgNum(m.XX), svgNum(m.YX), svgNum(m.XY), svgNum(m.YY), svgNum(m.X0), svgNum(m.Y0), sw.clipAttr(clip), base64.StdEncoding.EncodeToString(pb.Bytes()))
+}
+
+// SVGFontAttrs returns the svg font attributes for given font
+func SVGFontAttrs(fi FontInfo) string {
+	basenm, _, wt, sty := FontNameToMods(fi.Name)
+	if fi.Weight != WeightNormal {
+		wt = fi.Weight
+	}
+	if fi.Style != FontNormal {
+		sty = fi.Style
+	}
+	var sb strings.Builder
+	sb.WriteString(" font-family=\"")
+	xml.EscapeText(&sb, []byte(basenm))
+	sb.WriteString("\"")
+	if wt != WeightNormal && wt != Weight400 {
+		fmt.Fprintf(&sb, " font-weight=\"%v\"", FontWeightToNameMap[wt])
+	}
+	switch sty {
+	case FontItalic:
+		sb.WriteString(" font-style=\"italic\"")
+	case FontOblique:
+		sb.WriteString(" font-style=\"oblique\"")
+	}
+	return sb.String()
+}
+
+func (sw *SVGWriter) DrawText(tr *VecTextRun, clip image.Rectangle) {
+	var txt bytes.Buffer
+	xml.EscapeText(&txt, []byte(string(tr.Runes)))
+	attrs := fmt.Sprintf("%v font-size=\"%v\"%v%v", SVGFontAttrs(tr.Font), svgNum(tr.Size), svgColorAttrs("fill", tr.Color), sw.clipAttr(clip))
+	if tr.Rot == 0 && tr.ScaleX == 1 {
+		xs := make([]string, len(tr.Pos))
+		ys := make([]string, len(tr.Pos))
+		for i, p := range tr.Pos {
+			xs[i] = svgNum(p.X)
+			ys[i] = svgNum(p.Y)
+		}
+		fmt.Fprintf(&sw.body, "<text x=\"%v\" y=\"%v\"%v>%v</text>\n", strings.Join(xs, " "), strings.Join(ys, " "), attrs, txt.String())
+		return
+	}
+	fmt.Fprintf(&sw.body, "<g%v>\n", attrs)
+	for i, r := range tr.Runes {
+		var rt bytes.Buffer
+		xml.EscapeText(&rt, []byte(string(r)))
+		m := tr.GlyphXForm(i).Scale(1/tr.Size, -1/tr.Size) // text is drawn at font size, with Y down
+		fmt.Fprintf(&sw.body, "<text transform=\"matrix(%v,%v,%v,%v,%v,%v)\">%v</text>\n", svgNum(m.XX), svgNum(m.YX), svgNum(m.XY), svgNum(m.YY), svgNum(m.X0), svgNum(m.Y0), rt.String())
+	}
+	sw.body.WriteString("</g>\n")
+}
+
+func (sw *SVGWriter) Encode(w io.Writer) error {
+	var out bytes.Buffer
+	out.WriteString(xml.Header)
+	fmt.Fprintf(&out, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%v\" height=\"%v\" viewBox=\"0 0 %v %v\">\n", sw.Size.X, sw.Size.Y, sw.Size.X, sw.Size.Y)
+	if sw.defs.Len() > 0 {
+		out.WriteString("<defs>\n")
+		out.Write(sw.defs.Bytes())
+		out.WriteString("</defs>\n")
+	}
+	out.Write(sw.body.Bytes())
+	out.WriteString("</svg>\n")
+	_, err := w.Write(out.Bytes())
+	return err
+}
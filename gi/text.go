@@ -697,6 +697,7 @@ func (tr *TextRender) Render(rs *RenderState, pos Vec2D) {
 	TextFontRenderMu.Lock()
 	defer TextFontRenderMu.Unlock()
 
+	be := rs.PaintBackend()
 	for _, sr := range tr.Spans {
 		if sr.IsValid() != nil {
 			continue
@@ -705,12 +706,6 @@ func (tr *TextRender) Render(rs *RenderState, pos Vec2D) {
 		curColor := sr.Render[0].Color
 		tpos := pos.Add(sr.RelPos)
 
-		d := &font.Drawer{
-			Dst:  rs.Image,
-			Src:  image.NewUniform(curColor),
-			Face: curFace,
-		}
-
 		// todo: cache flags if these are actually needed
 		if bitflag.Has32(int32(sr.HasDeco), int(DecoBgColor)) {
 			sr.RenderBg(rs, tpos)
@@ -726,7 +721,6 @@ func (tr *TextRender) Render(rs *RenderState, pos Vec2D) {
 			rr := &(sr.Render[i])
 			if rr.Color != nil {
 				curColor = rr.Color
-				d.Src = image.NewUniform(curColor)
 			}
 			curFace = rr.CurFace(curFace)
 			if !unicode.IsPrint(r) {
@@ -745,38 +739,7 @@ func (tr *TextRender) Render(rs *RenderState, pos Vec2D) {
 				int(math32.Ceil(ur.X)) < rs.Bounds.Min.X || int(math32.Ceil(ll.Y)) < rs.Bounds.Min.Y {
 				continue
 			}
-			d.Face = curFace
-			d.Dot = rp.Fixed()
-			dr, mask, maskp, _, ok := d.Face.Glyph(d.Dot, r)
-			if !ok {
-				// fmt.Printf("not ok rendering rune: %v\n", string(r))
-				continue
-			}
-			if rr.RotRad == 0 && (rr.ScaleX == 0 || rr.ScaleX == 1) {
-				idr := dr.Intersect(rs.Bounds)
-				soff := image.ZP
-				if dr.Min.X < rs.Bounds.Min.X {
-					soff.X = rs.Bounds.Min.X - dr.Min.X
-					maskp.X += rs.Bounds.Min.X - dr.Min.X
-				}
-				if dr.Min.Y < rs.Bounds.Min.Y {
-					soff.Y = rs.Bounds.Min.Y - dr.Min.Y
-					maskp.Y += rs.Bounds.Min.Y - dr.Min.Y
-				}
-				draw.DrawMask(d.Dst, idr, d.Src, soff, mask, maskp, draw.Over)
-			} else {
-				srect := dr.Sub(dr.Min)
-				dbase := Vec2D{rp.X - float32(dr.Min.X), rp.Y - float32(dr.Min.Y)}
-
-				transformer := draw.BiLinear
-				fx, fy := float32(dr.Min.X), float32(dr.Min.Y)
-				m := Translate2D(fx+dbase.X, fy+dbase.Y).Scale(scx, 1).Rotate(rr.RotRad).Translate(-dbase.X, -dbase.Y)
-				s2d := f64.Aff3{float64(m.XX), float64(m.XY), float64(m.X0), float64(m.YX), float64(m.YY), float64(m.Y0)}
-				transformer.Transform(d.Dst, s2d, d.Src, srect, draw.Over, &draw.Options{
-					SrcMask:  mask,
-					SrcMaskP: maskp,
-				})
-			}
+			be.DrawGlyph(rs, curFace, r, curColor, rp, rr.RotRad, scx)
 		}
 		if bitflag.Has32(int32(sr.HasDeco), int(DecoLineThrough)) {
 			sr.RenderLine(rs, tpos, DecoLineThrough, 0.25)
@@ -1586,21 +1549,22 @@ func (tx *TextRender) RuneEndPos(idx int) (pos Vec2D, si, ri int, ok bool) {
 // FontStyle contains all the lower-level text rendering info used in SVG --
 // most of these are inherited
 type TextStyle struct {
-	Align            Align          `xml:"text-align" inherit:"true" desc:"prop: text-align = how to align text, horizontally"`
-	AlignV           Align          `xml:"-" json:"-" desc:"prop: vertical-align = vertical alignment of text -- copied from layout style AlignV"`
-	Anchor           TextAnchors    `xml:"text-anchor" inherit:"true" desc:"prop: text-anchor = for svg rendering only: determines the alignment relative to text position coordinate: for RTL start is right, not left, and start is top for TB"`
-	LetterSpacing    units.Value    `xml:"letter-spacing" desc:"prop: letter-spacing = spacing between characters and lines"`
-	WordSpacing      units.Value    `xml:"word-spacing" inherit:"true" desc:"prop: word-spacing = extra space to add between words"`
-	LineHeight       float32        `xml:"line-height" inherit:"true" desc:"prop: line-height = specified height of a line of text, in proportion to default font height, 0 = 1 = normal (todo: specific values such as pixels are not supported, in order to properly support percentage) -- text is centered within the overall lineheight"`
-	WhiteSpace       WhiteSpaces    `xml:"white-space" inherit:"true" desc:"prop: white-space = specifies how white space is processed, and how lines are wrapped"`
-	UnicodeBidi      UnicodeBidi    `xml:"unicode-bidi" inherit:"true" desc:"prop: unicode-bidi = determines how to treat unicode bidirectional information"`
-	Direction        TextDirections `xml:"direction" inherit:"true" desc:"prop: direction = direction of text -- only applicable for unicode-bidi = bidi-override or embed -- applies to all text elements"`
-	WritingMode      TextDirections `xml:"writing-mode" inherit:"true" desc:"prop: writing-mode = overall writing mode -- only for text elements, not tspan"`
-	OrientationVert  float32        `xml:"glyph-orientation-vertical" inherit:"true" desc:"prop: glyph-orientation-vertical = for TBRL writing mode (only), determines orientation of alphabetic characters -- 90 is default (rotated) -- 0 means keep upright"`
-	OrientationHoriz float32        `xml:"glyph-orientation-horizontal" inherit:"true" desc:"prop: glyph-orientation-horizontal = for horizontal LR/RL writing mode (only), determines orientation of all characters -- 0 is default (upright)"`
-	Indent           units.Value    `xml:"text-indent" inherit:"true" desc:"prop: text-indent = how much to indent the first line in a paragraph"`
-	ParaSpacing      units.Value    `xml:"para-spacing" inherit:"true" desc:"prop: para-spacing = extra spacing between paragraphs -- copied from Style.Layout.Margin per CSS spec if that is non-zero, else can be set directly with para-spacing"`
-	TabSize          int            `xml:"tab-size" inherit:"true" desc:"prop: tab-size = tab size, in number of characters"`
+	Align            Align             `xml:"text-align" inherit:"true" desc:"prop: text-align = how to align text, horizontally"`
+	AlignV           Align             `xml:"-" json:"-" desc:"prop: vertical-align = vertical alignment of text -- copied from layout style AlignV"`
+	Anchor           TextAnchors       `xml:"text-anchor" inherit:"true" desc:"prop: text-anchor = for svg rendering only: determines the alignment relative to text position coordinate: for RTL start is right, not left, and start is top for TB"`
+	DominantBaseline DominantBaselines `xml:"dominant-baseline" inherit:"true" desc:"prop: dominant-baseline = for svg rendering only: determines which baseline of the font is aligned with the text position coordinate"`
+	LetterSpacing    units.Value       `xml:"letter-spacing" desc:"prop: letter-spacing = spacing between characters and lines"`
+	WordSpacing      units.Value       `xml:"word-spacing" inherit:"true" desc:"prop: word-spacing = extra space to add between words"`
+	LineHeight       float32           `xml:"line-height" inherit:"true" desc:"prop: line-height = specified height of a line of text, in proportion to default font height, 0 = 1 = normal (todo: specific values such as pixels are not supported, in order to properly support percentage) -- text is centered within the overall lineheight"`
+	WhiteSpace       WhiteSpaces       `xml:"white-space" inherit:"true" desc:"prop: white-space = specifies how white space is processed, and how lines are wrapped"`
+	UnicodeBidi      UnicodeBidi       `xml:"unicode-bidi" inherit:"true" desc:"prop: unicode-bidi = determines how to treat unicode bidirectional information"`
+	Direction        TextDirections    `xml:"direction" inherit:"true" desc:"prop: direction = direction of text -- only applicable for unicode-bidi = bidi-override or embed -- applies to all text elements"`
+	WritingMode      TextDirections    `xml:"writing-mode" inherit:"true" desc:"prop: writing-mode = overall writing mode -- only for text elements, not tspan"`
+	OrientationVert  float32           `xml:"glyph-orientation-vertical" inherit:"true" desc:"prop: glyph-orientation-vertical = for TBRL writing mode (only), determines orientation of alphabetic characters -- 90 is default (rotated) -- 0 means keep upright"`
+	OrientationHoriz float32           `xml:"glyph-orientation-horizontal" inherit:"true" desc:"prop: glyph-orientation-horizontal = for horizontal LR/RL writing mode (only), determines orientation of all characters -- 0 is default (upright)"`
+	Indent           units.Value       `xml:"text-indent" inherit:"true" desc:"prop: text-indent = how much to indent the first line in a paragraph"`
+	ParaSpacing      units.Value       `xml:"para-spacing" inherit:"true" desc:"prop: para-spacing = extra spacing between paragraphs -- copied from Style.Layout.Margin per CSS spec if that is non-zero, else can be set directly with para-spacing"`
+	TabSize          int               `xml:"tab-size" inherit:"true" desc:"prop: tab-size = tab size, in number of characters"`
 	// todo:
 	// page-break options
 	// text-justify  inherit:"true" -- how to justify text
@@ -1667,6 +1631,72 @@ var KiT_TextAnchors = kit.Enums.AddEnumAltLower(TextAnchorsN, false, StylePropPr
 func (ev TextAnchors) MarshalJSON() ([]byte, error)  { return kit.EnumMarshalJSON(ev) }
 func (ev *TextAnchors) UnmarshalJSON(b []byte) error { return kit.EnumUnmarshalJSON(ev, b) }
 
+// DominantBaselines determine which baseline of the font is aligned with the
+// text position coordinate, for svg text
+type DominantBaselines int32
+
+const (
+	// BaselineAuto is the same as BaselineAlphabetic for horizontal text
+	BaselineAuto DominantBaselines = iota
+
+	// BaselineAlphabetic aligns the standard alphabetic baseline
+	BaselineAlphabetic
+
+	// BaselineIdeographic aligns the bottom of ideographic glyphs, i.e., the
+	// font descent
+	BaselineIdeographic
+
+	// BaselineMiddle aligns the middle of lower-case letters, i.e., half the
+	// x-height above the baseline
+	BaselineMiddle
+
+	// BaselineCentral aligns the center between the font ascent and descent
+	BaselineCentral
+
+	// BaselineMathematical aligns the middle of math symbols, i.e., half the
+	// ascent above the baseline
+	BaselineMathematical
+
+	// BaselineHanging aligns the hanging baseline used in Indic scripts
+	BaselineHanging
+
+	// BaselineTextTop aligns the top of the font ascent (text-before-edge)
+	BaselineTextTop
+
+	// BaselineTextBottom aligns the bottom of the font descent (text-after-edge)
+	BaselineTextBottom
+
+	DominantBaselinesN
+)
+
+//go:generate stringer -type=DominantBaselines
+
+var KiT_DominantBaselines = kit.Enums.AddEnumAltLower(DominantBaselinesN, false, StylePropProps, "Baseline")
+
+func (ev DominantBaselines) MarshalJSON() ([]byte, error)  { return kit.EnumMarshalJSON(ev) }
+func (ev *DominantBaselines) UnmarshalJSON(b []byte) error { return kit.EnumUnmarshalJSON(ev, b) }
+
+// BaselineOffset returns the amount to shift the alphabetic baseline down
+// by, so that given baseline is at the text position, for a font with given
+// ascent and descent (both positive)
+func (db DominantBaselines) BaselineOffset(ascent, descent float32) float32 {
+	switch db {
+	case BaselineIdeographic, BaselineTextBottom:
+		return -descent
+	case BaselineMiddle:
+		return 0.25 * ascent // x-height is roughly half the ascent
+	case BaselineCentral:
+		return 0.5 * (ascent - descent)
+	case BaselineMathematical:
+		return 0.5 * ascent
+	case BaselineHanging:
+		return 0.8 * ascent
+	case BaselineTextTop:
+		return ascent
+	}
+	return 0
+}
+
 // WhiteSpaces determine how white space is processed
 type WhiteSpaces int32
 
@@ -1744,6 +1774,15 @@ func (ts *TextStyle) Defaults() {
 
 // SetStylePost applies any updates after generic xml-tag property setting
 func (ts *TextStyle) SetStylePost(props ki.Props) {
+	if db, ok := props["dominant-baseline"].(string); ok {
+		// hyphenated css values are not handled by generic enum setting
+		switch db {
+		case "text-before-edge", "text-top":
+			ts.DominantBaseline = BaselineTextTop
+		case "text-after-edge", "text-bottom":
+			ts.DominantBaseline = BaselineTextBottom
+		}
+	}
 }
 
 // InheritFields from parent: Manual inheriting of values is much faster than
@@ -1751,6 +1790,7 @@ func (ts *TextStyle) SetStylePost(props ki.Props) {
 func (ts *TextStyle) InheritFields(par *TextStyle) {
 	ts.Align = par.Align
 	ts.Anchor = par.Anchor
+	ts.DominantBaseline = par.DominantBaseline
 	ts.WordSpacing = par.WordSpacing
 	ts.LineHeight = par.LineHeight
 	// ts.WhiteSpace = par.WhiteSpace // todo: we can't inherit this b/c label base default then gets overwritten
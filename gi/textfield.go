@@ -1358,8 +1358,10 @@ func (tf *TextField) StyleTextField() {
 	pst := &(tf.Par.(Node2D).AsWidget().Sty)
 	for i := 0; i < int(TextFieldStatesN); i++ {
 		tf.StateStyles[i].CopyFrom(&tf.Sty)
+		tf.StateStyles[i].StyleCSS(tf.This().(Node2D), Prefs.DefaultCSS(), TextFieldSelectors[i], tf.Viewport)
 		tf.StateStyles[i].SetStyleProps(pst, tf.StyleProps(TextFieldSelectors[i]), tf.Viewport)
 		tf.StateStyles[i].StyleCSS(tf.This().(Node2D), tf.CSSAgg, TextFieldSelectors[i], tf.Viewport)
+		tf.StateStyles[i].StyleCSS(tf.This().(Node2D), Prefs.OverrideCSS(), TextFieldSelectors[i], tf.Viewport)
 		tf.StateStyles[i].CopyUnitContext(&tf.Sty.UnContext)
 	}
 	tf.CursorWidth.SetFmInheritProp("cursor-width", tf.This(), true, true) // get type defaults
@@ -1436,20 +1438,20 @@ func (tf *TextField) Render2D() {
 		tf.AutoScroll() // inits paint with our style
 		if tf.IsInactive() {
 			if tf.IsSelected() {
-				tf.Sty = tf.StateStyles[TextFieldSel]
+				tf.TransitionStyle(&tf.StateStyles[TextFieldSel])
 			} else {
-				tf.Sty = tf.StateStyles[TextFieldInactive]
+				tf.TransitionStyle(&tf.StateStyles[TextFieldInactive])
 			}
 		} else if tf.HasFocus() {
 			if tf.IsFocusActive() {
-				tf.Sty = tf.StateStyles[TextFieldFocus]
+				tf.TransitionStyle(&tf.StateStyles[TextFieldFocus])
 			} else {
-				tf.Sty = tf.StateStyles[TextFieldActive]
+				tf.TransitionStyle(&tf.StateStyles[TextFieldActive])
 			}
 		} else if tf.IsSelected() {
-			tf.Sty = tf.StateStyles[TextFieldSel]
+			tf.TransitionStyle(&tf.StateStyles[TextFieldSel])
 		} else {
-			tf.Sty = tf.StateStyles[TextFieldActive]
+			tf.TransitionStyle(&tf.StateStyles[TextFieldActive])
 		}
 		st := &tf.Sty
 		st.Font.OpenFont(&st.UnContext)
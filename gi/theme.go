@@ -0,0 +1,521 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/goki/gi/oswin"
+	"github.com/goki/ki"
+	"github.com/goki/ki/kit"
+)
+
+////////////////////////////////////////////////////////////////////////////////////////
+// Themes -- list of Theme's
+
+// ThemeName has an associated ValueView for selecting from the list of
+// available theme names, for use in preferences etc.
+type ThemeName string
+
+// DefaultTheme is the overall default theme
+var DefaultTheme = ThemeName("Light")
+
+// DefaultDarkTheme is the default theme used when following the dark mode
+// setting of the OS (see Preferences.FollowOSTheme)
+var DefaultDarkTheme = ThemeName("Dark")
+
+// Theme bundles the color palette used in the default styles, a style sheet
+// applied to all widgets, and the default syntax highlighting style, so that
+// the overall look of the GUI can be switched all at once
+type Theme struct {
+	Name    string     `width:"20" desc:"name of theme"`
+	Desc    string     `desc:"description of theme"`
+	Dark    bool       `desc:"true if this is a dark theme, for use with the dark mode setting of the OS"`
+	Colors  ColorPrefs `desc:"color palette of the theme, used in the default styles"`
+	Styles  ki.Props   `desc:"style sheet of the theme -- add a separate Props entry for each type of object, e.g., button, or class using .classname, or specific named element using #name -- all are case insensitive"`
+	HiStyle string     `desc:"name of the default syntax highlighting style (see histyle) for this theme -- leave empty to keep the current one"`
+}
+
+// Label satisfies the Labeler interface
+func (th Theme) Label() string {
+	return th.Name
+}
+
+// Themes is a list of Theme's -- users can edit these in Prefs -- to create a
+// custom one, just duplicate an existing theme, rename, and customize
+type Themes []Theme
+
+var KiT_Themes = kit.Types.AddType(&Themes{}, ThemesProps)
+
+// AvailThemes is the current list of available themes for use -- can be
+// loaded / saved / edited with preferences.  This is set to StdThemes at
+// startup.
+var AvailThemes Themes
+
+func init() {
+	AvailThemes.CopyFrom(StdThemes)
+}
+
+// ThemeByName returns a theme and index by name -- returns false if not
+// found
+func (th *Themes) ThemeByName(name ThemeName) (*Theme, int, bool) {
+	for i := range *th {
+		if (*th)[i].Name == string(name) {
+			return &(*th)[i], i, true
+		}
+	}
+	return nil, -1, false
+}
+
+// PrefsThemesFileName is the name of the preferences file in GoGi prefs
+// directory for saving / loading the default AvailThemes themes list
+var PrefsThemesFileName = "themes_prefs.json"
+
+// OpenJSON opens themes from a JSON-formatted file.
+func (th *Themes) OpenJSON(filename FileName) error {
+	b, err := ioutil.ReadFile(string(filename))
+	if err != nil {
+		PromptDialog(nil, DlgOpts{Title: "File Not Found", Prompt: err.Error()}, true, false, nil, nil)
+		log.Println(err)
+		return err
+	}
+	*th = make(Themes, 0, 10) // reset
+	return json.Unmarshal(b, th)
+}
+
+// SaveJSON saves themes to a JSON-formatted file.
+func (th *Themes) SaveJSON(filename FileName) error {
+	b, err := json.MarshalIndent(th, "", "  ")
+	if err != nil {
+		log.Println(err) // unlikely
+		return err
+	}
+	err = ioutil.WriteFile(string(filename), b, 0644)
+	if err != nil {
+		PromptDialog(nil, DlgOpts{Title: "Could not Save to File", Prompt: err.Error()}, true, false, nil, nil)
+		log.Println(err)
+	}
+	return err
+}
+
+// OpenPrefs opens Themes from GoGi standard prefs directory, using PrefsThemesFileName
+func (th *Themes) OpenPrefs() error {
+	pdir := oswin.TheApp.GoGiPrefsDir()
+	pnm := filepath.Join(pdir, PrefsThemesFileName)
+	AvailThemesChanged = false
+	return th.OpenJSON(FileName(pnm))
+}
+
+// SavePrefs saves Themes to GoGi standard prefs directory, using PrefsThemesFileName
+func (th *Themes) SavePrefs() error {
+	pdir := oswin.TheApp.GoGiPrefsDir()
+	pnm := filepath.Join(pdir, PrefsThemesFileName)
+	AvailThemesChanged = false
+	return th.SaveJSON(FileName(pnm))
+}
+
+// CopyFrom copies themes from given other list
+func (th *Themes) CopyFrom(cp Themes) {
+	*th = make(Themes, 0, len(cp)) // reset
+	b, _ := json.Marshal(cp)
+	json.Unmarshal(b, th)
+}
+
+// RevertToStd reverts this list to the StdThemes that are compiled into the
+// program.
+func (th *Themes) RevertToStd() {
+	th.CopyFrom(StdThemes)
+	AvailThemesChanged = true
+}
+
+// ViewStd shows the standard themes that are compiled into the program.
+// Useful for comparing against custom themes.
+func (th *Themes) ViewStd() {
+	TheViewIFace.ThemesView(&StdThemes)
+}
+
+// AvailThemesChanged is used to update giv.ThemesView toolbars via following
+// menu, toolbar props update methods -- not accurate if editing any other
+// list but works for now..
+var AvailThemesChanged = false
+
+// StdThemes are the standard themes compiled into the program
+var StdThemes = Themes{
+	{"Light", "standard light theme, with dark text on a white background", false, LightColors(), nil, "emacs"},
+	{"Dark", "standard dark theme, with light text on a dark background", true, DarkColors(), nil, "monokai"},
+	{"HighContrast", "light theme with black text and borders and thicker borders on controls, for maximum legibility", false, HighContrastColors(), ki.Props{
+		"button": ki.Props{
+			"border-width": "2px",
+		},
+		"textfield": ki.Props{
+			"border-width": "2px",
+		},
+		"spinbox": ki.Props{
+			"border-width": "2px",
+		},
+	}, "bw"},
+}
+
+// LightColors returns the color palette of the standard light theme
+func LightColors() ColorPrefs {
+	var cp ColorPrefs
+	cp.Defaults()
+	return cp
+}
+
+// DarkColors returns the color palette of the standard dark theme
+func DarkColors() ColorPrefs {
+	var cp ColorPrefs
+	cp.Font.SetString("#DDD", nil)
+	cp.Border.SetString("#888", nil)
+	cp.Background.SetString("#222", nil)
+	cp.Shadow.SetString("darker-10", &cp.Background)
+	cp.Control.SetString("#3A3A3A", nil)
+	cp.Icon.SetString("highlight-30", cp.Control)
+	cp.Select.SetString("#264F26", nil)
+	cp.Highlight.SetString("#664", nil)
+	cp.Link.SetString("#8AF", nil)
+	return cp
+}
+
+// HighContrastColors returns the color palette of the standard high-contrast
+// theme
+func HighContrastColors() ColorPrefs {
+	var cp ColorPrefs
+	cp.Font.SetString("#000", nil)
+	cp.Border.SetString("#000", nil)
+	cp.Background.SetString("#FFF", nil)
+	cp.Shadow.SetString("darker-20", &cp.Background)
+	cp.Control.SetString("#FFF", nil)
+	cp.Icon.SetString("#000", nil)
+	cp.Select.SetString("#0FF", nil)
+	cp.Highlight.SetString("#FF0", nil)
+	cp.Link.SetString("#00E", nil)
+	return cp
+}
+
+////////////////////////////////////////////////////////////////////////////////////////
+// OS dark mode
+
+// OSDarkModeFunc returns true if the OS is set to dark mode -- the default
+// queries the system settings on mac, windows and linux (GTK / GNOME), and
+// can be replaced by platform drivers that know better
+var OSDarkModeFunc = OSDarkMode
+
+// OSThemeCheckInterval is how often the dark mode setting of the OS is
+// checked when Preferences.FollowOSTheme is on
+var OSThemeCheckInterval = 5 * time.Second
+
+// OSDarkMode returns true if the OS is set to dark mode, as reported by the
+// system settings tools -- false if it cannot be determined
+func OSDarkMode() bool {
+	switch runtime.GOOS {
+	case "darwin":
+		out, err := exec.Command("defaults", "read", "-g", "AppleInterfaceStyle").Output()
+		return err == nil && strings.Contains(strings.ToLower(string(out)), "dark")
+	case "windows":
+		out, err := exec.Command("reg", "query", `HKCU\Software\Microsoft\Windows\CurrentVersion\Themes\Personalize`, "/v", "AppsUseLightTheme").Output()
+		return err == nil && strings.Contains(string(out), "0x0")
+	}
+	if gt := strings.ToLower(os.Getenv("GTK_THEME")); gt != "" {
+		return strings.HasSuffix(gt, ":dark")
+	}
+	out, err := exec.Command("gsettings", "get", "org.gnome.desktop.interface", "color-scheme").Output()
+	if err == nil {
+		return strings.Contains(string(out), "dark")
+	}
+	out, err = exec.Command("gsettings", "get", "org.gnome.desktop.interface", "gtk-theme").Output()
+	return err == nil && strings.Contains(strings.ToLower(string(out)), "dark")
+}
+
+var (
+	osThemeWatch   sync.Once
+	osThemeDarkMu  sync.Mutex
+	osThemeDark    bool
+	osThemeChecked bool
+	osThemeFollow  bool
+)
+
+// osDarkMode returns the current dark mode setting of the OS, checking it
+// only once until watchOSTheme updates it
+func osDarkMode() bool {
+	osThemeDarkMu.Lock()
+	defer osThemeDarkMu.Unlock()
+	if !osThemeChecked {
+		osThemeDark = OSDarkModeFunc()
+		osThemeChecked = true
+	}
+	return osThemeDark
+}
+
+// watchOSTheme starts checking the dark mode setting of the OS every
+// OSThemeCheckInterval while FollowOSTheme is on (as last applied by
+// ApplyTheme), and updating the preferences when it changes -- which is
+// done on the event loop of the first window, so the change is only
+// recorded when there is one
+func (pf *Preferences) watchOSTheme() {
+	osThemeWatch.Do(func() {
+		go func() {
+			for range time.Tick(OSThemeCheckInterval) {
+				osThemeDarkMu.Lock()
+				follow := osThemeFollow
+				osThemeDarkMu.Unlock()
+				if !follow {
+					continue
+				}
+				win := AllWindows.Win(0)
+				if win == nil {
+					continue
+				}
+				dark := OSDarkModeFunc()
+				osThemeDarkMu.Lock()
+				chg := dark != osThemeDark
+				osThemeDark = dark
+				osThemeDarkMu.Unlock()
+				if chg {
+					win.PostFunc(func() {
+						if pf.FollowOSTheme {
+							pf.Update()
+						}
+					})
+				}
+			}
+		}()
+	})
+}
+
+////////////////////////////////////////////////////////////////////////////////////////
+// Preferences themes
+
+// ThemeCSS is the style sheet of the current theme combined with the
+// CustomStyles preferences, as set by Preferences.ApplyTheme -- see
+// Preferences.DefaultCSS and OverrideCSS for its use in styling
+var ThemeCSS ki.Props
+
+// CurTheme returns the theme in effect according to the preferences: the
+// DarkTheme if following the OS setting and it is in dark mode, and
+// otherwise the Theme -- falls back on the DefaultTheme and then the first
+// available one -- nil if there are no themes at all
+func (pf *Preferences) CurTheme() *Theme {
+	nm := pf.Theme
+	if pf.FollowOSTheme && pf.DarkTheme != "" && osDarkMode() {
+		nm = pf.DarkTheme
+	}
+	if nm != "" {
+		if th, _, ok := AvailThemes.ThemeByName(nm); ok {
+			return th
+		}
+		log.Printf("gi.Preferences: theme named: %v not found, using the default theme\n", nm)
+	}
+	if th, _, ok := AvailThemes.ThemeByName(DefaultTheme); ok {
+		return th
+	}
+	if len(AvailThemes) > 0 {
+		return &AvailThemes[0]
+	}
+	return nil
+}
+
+// ApplyTheme applies the current theme (see CurTheme): its colors become
+// the current Colors used in the default styles, its style sheet is combined
+// with the CustomStyles, and its highlighting style becomes the default --
+// call Update to restyle all open windows.
+func (pf *Preferences) ApplyTheme() {
+	osThemeDarkMu.Lock()
+	osThemeFollow = pf.FollowOSTheme
+	osThemeDarkMu.Unlock()
+	if pf.FollowOSTheme {
+		pf.watchOSTheme()
+	}
+	th := pf.CurTheme()
+	if th == nil {
+		pf.Colors.Defaults()
+		ThemeCSS = pf.CustomStyles
+		return
+	}
+	pf.Colors = th.Colors
+	switch {
+	case len(th.Styles) == 0:
+		ThemeCSS = pf.CustomStyles
+	case len(pf.CustomStyles) == 0:
+		ThemeCSS = th.Styles
+	default:
+		ThemeCSS = nil // new map, not the theme's
+		AggCSS(&ThemeCSS, th.Styles)
+		AggCSS(&ThemeCSS, pf.CustomStyles)
+	}
+	if th.HiStyle != "" && TheViewIFace != nil {
+		TheViewIFace.SetHiStyleDefault(th.HiStyle)
+	}
+}
+
+// migrateColors moves the Colors saved in given preferences file from
+// before there were themes, if any, into a new custom theme, based on the
+// current one, which becomes the Theme -- unless they are the colors of the
+// current theme anyway -- returns true if it did
+func (pf *Preferences) migrateColors(b []byte) bool {
+	var old struct {
+		Colors *ColorPrefs
+	}
+	if err := json.Unmarshal(b, &old); err != nil || old.Colors == nil {
+		return false
+	}
+	nth := Theme{Name: "Custom", Desc: "custom theme with the colors from your preferences from before themes"}
+	if th := pf.CurTheme(); th != nil {
+		if th.Colors == *old.Colors {
+			return false
+		}
+		nth.Styles = th.Styles
+		nth.HiStyle = th.HiStyle
+	}
+	nth.Colors = *old.Colors
+	for i := 2; ; i++ {
+		if _, _, has := AvailThemes.ThemeByName(ThemeName(nth.Name)); !has {
+			break
+		}
+		nth.Name = fmt.Sprintf("Custom %d", i)
+	}
+	AvailThemes = append(AvailThemes, nth)
+	pf.Theme = ThemeName(nth.Name)
+	pf.SaveThemes = true
+	AvailThemesChanged = true
+	return true
+}
+
+// SetTheme sets the theme to the one of given name and updates all open
+// windows to use it.
+func (pf *Preferences) SetTheme(name ThemeName) {
+	pf.Theme = name
+	pf.Changed = true
+	pf.Update()
+}
+
+// EditThemes opens the ThemesView editor to create new themes / save / load
+// from other files, etc.  Current avail themes are saved and loaded with
+// preferences automatically.
+func (pf *Preferences) EditThemes() {
+	pf.SaveThemes = true
+	pf.Changed = true
+	TheViewIFace.ThemesView(&AvailThemes)
+}
+
+// DefaultCSS returns the style sheet of the current theme, including the
+// CustomStyles, if it provides defaults that come before any other styling,
+// and nil otherwise (see CustomStylesOverride)
+func (pf *Preferences) DefaultCSS() ki.Props {
+	if pf.CustomStylesOverride {
+		return nil
+	}
+	return ThemeCSS
+}
+
+// OverrideCSS returns the style sheet of the current theme, including the
+// CustomStyles, if it overrides other styling, coming last, and nil
+// otherwise (see CustomStylesOverride)
+func (pf *Preferences) OverrideCSS() ki.Props {
+	if !pf.CustomStylesOverride {
+		return nil
+	}
+	return ThemeCSS
+}
+
+// ThemesProps define the ToolBar and MenuBar for TableView of Themes, e.g., giv.ThemesView
+var ThemesProps = ki.Props{
+	"MainMenu": ki.PropSlice{
+		{"AppMenu", ki.BlankProp{}},
+		{"File", ki.PropSlice{
+			{"OpenPrefs", ki.Props{}},
+			{"SavePrefs", ki.Props{
+				"shortcut": KeyFunMenuSave,
+				"updtfunc": func(thi interface{}, act *Action) {
+					act.SetActiveState(AvailThemesChanged && thi.(*Themes) == &AvailThemes)
+				},
+			}},
+			{"sep-file", ki.BlankProp{}},
+			{"OpenJSON", ki.Props{
+				"label":    "Open from file",
+				"desc":     "You can save and open themes to / from files to share, experiment, transfer, etc",
+				"shortcut": KeyFunMenuOpen,
+				"Args": ki.PropSlice{
+					{"File Name", ki.Props{
+						"ext": ".json",
+					}},
+				},
+			}},
+			{"SaveJSON", ki.Props{
+				"label":    "Save to file",
+				"desc":     "You can save and open themes to / from files to share, experiment, transfer, etc",
+				"shortcut": KeyFunMenuSaveAs,
+				"Args": ki.PropSlice{
+					{"File Name", ki.Props{
+						"ext": ".json",
+					}},
+				},
+			}},
+			{"RevertToStd", ki.Props{
+				"desc":    "This reverts the themes to the StdThemes that are compiled into the program.  <b>Your current theme edits will be lost if you proceed!</b>  Continue?",
+				"confirm": true,
+			}},
+		}},
+		{"Edit", "Copy Cut Paste Dupe"},
+		{"Window", "Windows"},
+	},
+	"ToolBar": ki.PropSlice{
+		{"SavePrefs", ki.Props{
+			"desc": "saves Themes to GoGi standard prefs directory, in file themes_prefs.json, which will be loaded automatically at startup if prefs SaveThemes is checked (should be if you're using custom themes)",
+			"icon": "file-save",
+			"updtfunc": func(thi interface{}, act *Action) {
+				act.SetActiveState(AvailThemesChanged && thi.(*Themes) == &AvailThemes)
+			},
+		}},
+		{"sep-file", ki.BlankProp{}},
+		{"OpenJSON", ki.Props{
+			"label": "Open from file",
+			"icon":  "file-open",
+			"desc":  "You can save and open themes to / from files to share, experiment, transfer, etc",
+			"Args": ki.PropSlice{
+				{"File Name", ki.Props{
+					"ext": ".json",
+				}},
+			},
+		}},
+		{"SaveJSON", ki.Props{
+			"label": "Save to file",
+			"icon":  "file-save",
+			"desc":  "You can save and open themes to / from files to share, experiment, transfer, etc",
+			"Args": ki.PropSlice{
+				{"File Name", ki.Props{
+					"ext": ".json",
+				}},
+			},
+		}},
+		{"sep-std", ki.BlankProp{}},
+		{"ViewStd", ki.Props{
+			"desc":    "Shows the standard themes that are compiled into the program.  Useful for comparing against custom themes.",
+			"confirm": true,
+			"updtfunc": func(thi interface{}, act *Action) {
+				act.SetActiveStateUpdt(thi.(*Themes) != &StdThemes)
+			},
+		}},
+		{"RevertToStd", ki.Props{
+			"icon":    "update",
+			"desc":    "This reverts the themes to the StdThemes that are compiled into the program.  <b>Your current theme edits will be lost if you proceed!</b>  Continue?",
+			"confirm": true,
+			"updtfunc": func(thi interface{}, act *Action) {
+				act.SetActiveStateUpdt(thi.(*Themes) != &StdThemes)
+			},
+		}},
+	},
+}
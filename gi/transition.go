@@ -0,0 +1,247 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gi
+
+import (
+	"fmt"
+	"log"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unsafe"
+
+	"github.com/goki/gi/units"
+)
+
+// Transition is one item of the transition style property, which makes
+// changes in a style property, e.g., between the hover and normal styles of
+// a button, gradually over a duration, instead of all at once
+type Transition struct {
+	Prop  string        `desc:"style property that is transitioned, e.g., background-color -- all transitions all properties, and a prefix such as border transitions all of border-color, border-width etc"`
+	Dur   time.Duration `desc:"duration of the transition"`
+	Delay time.Duration `desc:"delay before the transition starts"`
+	Ease  Easing        `desc:"easing function of the transition"`
+}
+
+var (
+	transCache   = map[string][]Transition{}
+	transCacheMu sync.RWMutex
+)
+
+// ParseTransitions returns the transitions of given value of the transition
+// style property: a comma-separated list of a property name, duration,
+// optional easing (see EasingFor -- default is ease) and optional delay,
+// e.g., "background-color 150ms ease, color 0.1s linear 50ms" -- results are
+// cached, and parse errors are logged the first time
+func ParseTransitions(str string) []Transition {
+	transCacheMu.RLock()
+	trs, ok := transCache[str]
+	transCacheMu.RUnlock()
+	if ok {
+		return trs
+	}
+	trs, err := parseTransitions(str)
+	if err != nil {
+		log.Println(err)
+	}
+	transCacheMu.Lock()
+	transCache[str] = trs
+	transCacheMu.Unlock()
+	return trs
+}
+
+func parseTransitions(str string) ([]Transition, error) {
+	str = strings.TrimSpace(str)
+	if str == "" || str == "none" {
+		return nil, nil
+	}
+	var trs []Transition
+	for _, item := range cssSplitList(str) {
+		tr := Transition{Ease: Ease}
+		ntimes := 0
+		for _, tok := range transTokens(item) {
+			if d, ok := transParseTime(tok); ok {
+				if ntimes == 0 {
+					tr.Dur = d
+				} else {
+					tr.Delay = d
+				}
+				ntimes++
+				continue
+			}
+			if ez := EasingFor(tok); ez != nil {
+				tr.Ease = ez
+				continue
+			}
+			if tr.Prop != "" {
+				return nil, fmt.Errorf("gi.ParseTransitions: unexpected %q in: %v", tok, str)
+			}
+			tr.Prop = strings.ToLower(tok)
+		}
+		if tr.Prop == "" {
+			tr.Prop = "all"
+		}
+		trs = append(trs, tr)
+	}
+	return trs, nil
+}
+
+// transTokens splits a transition item at spaces outside of parentheses
+func transTokens(str string) []string {
+	var flds []string
+	depth := 0
+	st := -1
+	for i, r := range str {
+		switch {
+		case r == '(':
+			depth++
+		case r == ')':
+			depth--
+		case (r == ' ' || r == '\t' || r == '\n') && depth == 0:
+			if st >= 0 {
+				flds = append(flds, str[st:i])
+				st = -1
+			}
+			continue
+		}
+		if st < 0 {
+			st = i
+		}
+	}
+	if st >= 0 {
+		flds = append(flds, str[st:])
+	}
+	return flds
+}
+
+// transParseTime parses a CSS time in s or ms units
+func transParseTime(str string) (time.Duration, bool) {
+	un := time.Second
+	switch {
+	case strings.HasSuffix(str, "ms"):
+		str, un = str[:len(str)-2], time.Millisecond
+	case strings.HasSuffix(str, "s"):
+		str = str[:len(str)-1]
+	default:
+		return 0, false
+	}
+	val, err := strconv.ParseFloat(str, 64)
+	if err != nil {
+		return 0, false
+	}
+	return time.Duration(val * float64(un)), true
+}
+
+// transFieldTypes are the types of style fields that can be transitioned
+var transFieldTypes = map[reflect.Type]bool{
+	reflect.TypeOf(float32(0)): true,
+	reflect.TypeOf(float64(0)): true,
+	units.KiT_Value:            true,
+	KiT_Color:                  true,
+	KiT_ColorSpec:              true,
+}
+
+// TransFields returns the style fields transitioned by a transition of given
+// property
+func TransFields(prop string) []*StyledField {
+	var flds []*StyledField
+	has := map[uintptr]bool{}
+	for tag, fld := range StyleFields.Fields {
+		if prop != "all" && tag != prop && !strings.HasPrefix(tag, prop+"-") {
+			continue
+		}
+		if !transFieldTypes[fld.Field.Type] || has[fld.NetOff] {
+			continue
+		}
+		has[fld.NetOff] = true
+		flds = append(flds, fld)
+	}
+	return flds
+}
+
+// StyleTransition is the state of a widget's transition to one of its state
+// styles (see WidgetBase.TransitionStyle)
+type StyleTransition struct {
+	To     *Style           `desc:"style being transitioned to"`
+	From   Style            `desc:"style at the start of the transition"`
+	Start  time.Time        `desc:"time at which the transition started"`
+	Trans  []Transition     `desc:"transitions of the To style"`
+	Fields [][]*StyledField `desc:"style fields of each of the transitions"`
+	Active bool             `desc:"true while the transition is in progress"`
+}
+
+// Apply sets sty to the style of the transition at given time, returning
+// false if the transition is over
+func (st *StyleTransition) Apply(sty *Style, now time.Time) bool {
+	*sty = *st.To
+	fp := uintptr(unsafe.Pointer(&st.From))
+	tp := uintptr(unsafe.Pointer(st.To))
+	cp := uintptr(unsafe.Pointer(sty))
+	active := false
+	for i, tr := range st.Trans {
+		t := float32(1)
+		if tr.Dur > 0 {
+			t = InRange32(float32(now.Sub(st.Start)-tr.Delay)/float32(tr.Dur), 0, 1)
+		}
+		if t >= 1 {
+			continue
+		}
+		active = true
+		e := tr.Ease(t)
+		for _, fld := range st.Fields[i] {
+			lerpValue(fld.FieldIface(cp), fld.FieldIface(fp), fld.FieldIface(tp), e)
+		}
+	}
+	return active
+}
+
+// TransitionStyle sets the style of the widget to given style, typically
+// one of its state styles -- if the transition property of that style is
+// set, and the widget was previously set to another style, the change is
+// made gradually, by a window animation that re-renders the widget on
+// each frame until the transition is over, so this must be called again
+// in Render2D, as it is when setting the style from the current state there
+func (wb *WidgetBase) TransitionStyle(to *Style) {
+	if to.Transition == "" {
+		wb.Sty = *to
+		wb.StyTrans = nil
+		return
+	}
+	st := wb.StyTrans
+	if st == nil {
+		wb.Sty = *to
+		wb.StyTrans = &StyleTransition{To: to}
+		return
+	}
+	if st.To != to {
+		st.From = wb.Sty
+		st.To = to
+		st.Start = time.Now()
+		st.Trans = ParseTransitions(to.Transition)
+		st.Fields = make([][]*StyledField, len(st.Trans))
+		var dur time.Duration
+		for i, tr := range st.Trans {
+			st.Fields[i] = TransFields(tr.Prop)
+			if tr.Delay+tr.Dur > dur {
+				dur = tr.Delay + tr.Dur
+			}
+		}
+		st.Active = false
+		if win := wb.ParentWindow(); win != nil && dur > 0 {
+			st.Active = true
+			win.StartAnim(&Anim{Node: wb.This().(Node2D), Prop: "transition", Start: st.Start, Dur: dur, Step: func(t float32) {
+				updt := wb.UpdateStart()
+				wb.UpdateEnd(updt)
+			}})
+		}
+	}
+	if st.Active {
+		st.Active = st.Apply(&wb.Sty, time.Now())
+		return
+	}
+	wb.Sty = *to
+}
@@ -0,0 +1,450 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gi
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/chewxy/math32"
+	"github.com/goki/ki"
+	"github.com/srwiley/rasterx"
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// VecPathOp is one operation in a VecPath: Op is M (move to), L (line to),
+// Q (quadratic bezier to), C (cubic bezier to) or Z (close path), with the
+// end point last in Pts, after any control points
+type VecPathOp struct {
+	Op  byte
+	Pts []Vec2D
+}
+
+// VecPath is a path of absolute-coordinate operations, as sent to a
+// VectorWriter
+type VecPath []VecPathOp
+
+// VecStroke has the parameters for stroking a path in a VectorWriter, with
+// all distances in page coordinates
+type VecStroke struct {
+	Width      float32   `desc:"line width"`
+	Cap        LineCap   `desc:"how to draw the end cap of lines"`
+	Join       LineJoin  `desc:"how to join line segments"`
+	MiterLimit float32   `desc:"limit of how far to miter"`
+	Dashes     []float64 `desc:"dash pattern, as alternating on and off distances -- nil for solid"`
+}
+
+// VecTextRun is a run of glyphs in the same font, color and orientation,
+// for drawing in a VectorWriter
+type VecTextRun struct {
+	Font   FontInfo    `desc:"the font -- Name is the regularized font name, which includes any weight and style modifiers"`
+	Size   float32     `desc:"font size, in page coordinates"`
+	Color  color.Color `desc:"color of the glyphs"`
+	Rot    float32     `desc:"rotation of each glyph, in radians, about its baseline position"`
+	ScaleX float32     `desc:"scaling of each glyph in the X dimension"`
+	Runes  []rune      `desc:"the runes to draw"`
+	Pos    []Vec2D     `desc:"lower-left baseline position of each rune, in page coordinates"`
+}
+
+// GlyphXForm returns the transform from the glyph space of the i'th rune
+// (in units of the font size, with Y up) to page coordinates
+func (tr *VecTextRun) GlyphXForm(i int) Matrix2D {
+	p := tr.Pos[i]
+	return Translate2D(p.X, p.Y).Scale(tr.ScaleX, 1).Rotate(tr.Rot).Scale(tr.Size, -tr.Size)
+}
+
+// VectorWriter is implemented by vector graphics output formats, e.g.,
+// PDFWriter and SVGWriter, which are written through a VectorBackend -- all
+// coordinates are in the page coordinates of the writer, which are image
+// pixels of the rendered region, with 0,0 at the top-left, and all drawing
+// is restricted to the given clip rectangle
+type VectorWriter interface {
+	// FillPath fills given path with given color, using the non-zero
+	// winding rule if nonZero is true, and otherwise the even-odd rule
+	FillPath(path VecPath, clr color.Color, nonZero bool, clip image.Rectangle)
+
+	// StrokePath strokes given path with given color and stroke parameters
+	StrokePath(path VecPath, clr color.Color, st *VecStroke, clip image.Rectangle)
+
+	// DrawImage draws given image, with given transform from its pixel
+	// coordinates to page coordinates
+	DrawImage(img image.Image, xf Matrix2D, clip image.Rectangle)
+
+	// DrawText draws given run of glyphs
+	DrawText(tr *VecTextRun, clip image.Rectangle)
+
+	// Encode writes the output to given writer
+	Encode(w io.Writer) error
+}
+
+// VecRuneChecker is implemented by a VectorWriter that can only draw some
+// runes as text, e.g., PDFWriter -- the VectorBackend draws the glyphs of
+// other runes as images instead
+type VecRuneChecker interface {
+	// HasRune returns true if given rune can be drawn as text
+	HasRune(r rune) bool
+}
+
+// VectorBackend is a PaintBackend that sends everything rendered to a
+// VectorWriter, instead of rasterizing it, for vector output.  Gradients are
+// drawn as the average color of their stops, image patterns are not
+// supported, and layers with clipping, masks or filters are drawn as images
+// (see RenderState.DrawLayer).  Glyphs are drawn as text in the font they
+// were rendered in -- see VecTextRun -- or as images if the writer cannot
+// draw them as text (see VecRuneChecker).
+type VectorBackend struct {
+	Writer VectorWriter    `desc:"writer to send output to"`
+	Offset image.Point     `desc:"offset added to the image coordinates of the RenderState to get page coordinates of the writer"`
+	Clip   image.Rectangle `desc:"region of the page, in page coordinates, that the RenderState is visible in -- all output is clipped to it"`
+	text   *vecTextPend    `desc:"pending text, which can be shared among backends for the same writer"`
+}
+
+// vecTextPend is a pending run of glyphs
+type vecTextPend struct {
+	run  *VecTextRun
+	clip image.Rectangle
+	wr   VectorWriter
+}
+
+// NewVectorBackend returns a new VectorBackend for given writer, for
+// rendering the whole of a RenderState at given offset in the page, clipped
+// to given region of the page
+func NewVectorBackend(vw VectorWriter, off image.Point, clip image.Rectangle) *VectorBackend {
+	return &VectorBackend{Writer: vw, Offset: off, Clip: clip, text: &vecTextPend{}}
+}
+
+// Flush draws any pending text -- must be called when done rendering
+func (vb *VectorBackend) Flush() {
+	if vb.text == nil || vb.text.run == nil {
+		return
+	}
+	vb.text.wr.DrawText(vb.text.run, vb.text.clip)
+	vb.text.run = nil
+}
+
+// clip returns the page clip for the current bounds of the RenderState
+func (vb *VectorBackend) clip(rs *RenderState) image.Rectangle {
+	return rs.Bounds.Add(vb.Offset).Intersect(vb.Clip)
+}
+
+// vecPathAdder is a rasterx.Adder that records a VecPath, in page
+// coordinates, and its bounds in image coordinates
+type vecPathAdder struct {
+	path     VecPath
+	off      Vec2D
+	min, max Vec2D
+	n        int
+}
+
+func (pa *vecPathAdder) add(op byte, pts ...fixed.Point26_6) {
+	vo := VecPathOp{Op: op, Pts: make([]Vec2D, len(pts))}
+	for i, p := range pts {
+		v := NewVec2DFmFixed(p)
+		if pa.n == 0 {
+			pa.min, pa.max = v, v
+		} else {
+			pa.min.SetMin(v)
+			pa.max.SetMax(v)
+		}
+		pa.n++
+		vo.Pts[i] = v.Add(pa.off)
+	}
+	pa.path = append(pa.path, vo)
+}
+
+func (pa *vecPathAdder) Start(a fixed.Point26_6) {
+	pa.add('M', a)
+}
+
+func (pa *vecPathAdder) Line(b fixed.Point26_6) {
+	pa.add('L', b)
+}
+
+func (pa *vecPathAdder) QuadBezier(b, c fixed.Point26_6) {
+	pa.add('Q', b, c)
+}
+
+func (pa *vecPathAdder) CubeBezier(b, c, d fixed.Point26_6) {
+	pa.add('C', b, c, d)
+}
+
+func (pa *vecPathAdder) Stop(closeLoop bool) {
+	if closeLoop {
+		pa.add('Z')
+	}
+}
+
+// path returns the current path of the RenderState, in page coordinates,
+// setting LastRenderBBox to its bounds expanded by given margin
+func (vb *VectorBackend) path(rs *RenderState, margin float32) VecPath {
+	pa := &vecPathAdder{off: NewVec2DFmPoint(vb.Offset)}
+	rs.Path.AddTo(pa)
+	if pa.n == 0 {
+		rs.LastRenderBBox = image.ZR
+		return nil
+	}
+	rs.LastRenderBBox = image.Rectangle{Min: pa.min.SubVal(margin).ToPointFloor(), Max: pa.max.AddVal(margin).ToPointCeil()}
+	return pa.path
+}
+
+// VecColor returns the uniform color to use for given color spec in vector
+// output, with given opacity applied -- gradients use the average color of
+// their stops
+func VecColor(cs *ColorSpec, opacity float32) color.NRGBA {
+	var c color.NRGBA
+	if cs.Source == SolidColor || cs.Gradient == nil || len(cs.Gradient.Stops) == 0 {
+		c = color.NRGBAModel.Convert(cs.Color).(color.NRGBA)
+	} else {
+		var r, g, b, a float64
+		for _, st := range cs.Gradient.Stops {
+			sc := color.NRGBAModel.Convert(st.StopColor).(color.NRGBA)
+			r += float64(sc.R)
+			g += float64(sc.G)
+			b += float64(sc.B)
+			a += float64(sc.A) * st.Opacity
+		}
+		n := float64(len(cs.Gradient.Stops))
+		c = color.NRGBA{uint8(r / n), uint8(g / n), uint8(b / n), uint8(a / n)}
+	}
+	c.A = uint8(math32.Round(float32(c.A) * opacity))
+	return c
+}
+
+func (vb *VectorBackend) Fill(rs *RenderState, pc *Paint) {
+	vb.Flush()
+	path := vb.path(rs, 0)
+	clip := vb.clip(rs)
+	if len(path) == 0 || clip.Empty() || pc.FillStyle.Color.Source == ImagePattern {
+		return
+	}
+	clr := VecColor(&pc.FillStyle.Color, pc.FontStyle.Opacity*pc.FillStyle.Opacity)
+	if clr.A == 0 {
+		return
+	}
+	vb.Writer.FillPath(path, clr, pc.FillStyle.Rule == FillRuleNonZero, clip)
+}
+
+func (vb *VectorBackend) Stroke(rs *RenderState, pc *Paint) {
+	vb.Flush()
+	st := &VecStroke{Width: pc.StrokeWidth(rs), Cap: pc.StrokeStyle.Cap, Join: pc.StrokeStyle.Join, MiterLimit: pc.StrokeStyle.MiterLimit}
+	path := vb.path(rs, 0.5*st.Width)
+	clip := vb.clip(rs)
+	if len(path) == 0 || clip.Empty() || st.Width == 0 || pc.StrokeStyle.Color.Source == ImagePattern {
+		return
+	}
+	if dash := pc.StrokeStyle.Dashes; dash != nil { // same scaling as RasterBackend
+		scx, scy := rs.XForm.ExtractScale()
+		sc := 0.5 * (math.Abs(float64(scx)) + math.Abs(float64(scy)))
+		st.Dashes = make([]float64, len(dash))
+		for i := range dash {
+			st.Dashes[i] = dash[i] * sc
+			if st.Dashes[i] < 1 {
+				st.Dashes = nil
+				break
+			}
+		}
+	}
+	clr := VecColor(&pc.StrokeStyle.Color, pc.FontStyle.Opacity*pc.StrokeStyle.Opacity)
+	if clr.A == 0 {
+		return
+	}
+	vb.Writer.StrokePath(path, clr, st, clip)
+}
+
+func (vb *VectorBackend) FillBox(rs *RenderState, b image.Rectangle, clr color.Color) {
+	vb.Flush()
+	b = b.Add(vb.Offset)
+	clip := b.Intersect(vb.Clip)
+	nc := color.NRGBAModel.Convert(clr).(color.NRGBA)
+	if clip.Empty() || nc.A == 0 {
+		return
+	}
+	mn, mx := NewVec2DFmPoint(b.Min), NewVec2DFmPoint(b.Max)
+	path := VecPath{{'M', []Vec2D{mn}}, {'L', []Vec2D{{mx.X, mn.Y}}}, {'L', []Vec2D{mx}}, {'L', []Vec2D{{mn.X, mx.Y}}}, {'Z', nil}}
+	vb.Writer.FillPath(path, nc, true, clip)
+}
+
+func (vb *VectorBackend) DrawImage(rs *RenderState, img image.Image, xf Matrix2D) {
+	vb.Flush()
+	clip := vb.clip(rs)
+	if clip.Empty() {
+		return
+	}
+	off := NewVec2DFmPoint(vb.Offset)
+	vb.Writer.DrawImage(img, xf.Multiply(Translate2D(off.X, off.Y)), clip)
+}
+
+func (vb *VectorBackend) DrawGlyph(rs *RenderState, face font.Face, r rune, clr color.Color, pos Vec2D, rot, scx float32) {
+	clip := vb.clip(rs)
+	if clip.Empty() {
+		return
+	}
+	if rc, ok := vb.Writer.(VecRuneChecker); ok && !rc.HasRune(r) {
+		vb.drawGlyphImage(rs, face, r, clr, pos, rot, scx)
+		return
+	}
+	if vb.text == nil {
+		vb.text = &vecTextPend{}
+	}
+	fi, sz, ok := FontLibrary.FaceInfo(face)
+	size := float32(sz)
+	if !ok {
+		fi = FontInfo{Name: "Go"}
+		size = FixedToFloat32(face.Metrics().Ascent + face.Metrics().Descent)
+	}
+	pos = pos.Add(NewVec2DFmPoint(vb.Offset))
+	tp := vb.text
+	if run := tp.run; run != nil {
+		if tp.wr != vb.Writer || tp.clip != clip || run.Font.Name != fi.Name || run.Size != size || run.Color != clr || run.Rot != rot || run.ScaleX != scx {
+			vb.Flush()
+		}
+	}
+	if tp.run == nil {
+		tp.run = &VecTextRun{Font: fi, Size: size, Color: clr, Rot: rot, ScaleX: scx}
+		tp.clip = clip
+		tp.wr = vb.Writer
+	}
+	tp.run.Runes = append(tp.run.Runes, r)
+	tp.run.Pos = append(tp.run.Pos, pos)
+}
+
+// drawGlyphImage draws the glyph of given rune as an image, with the same
+// geometry as the RasterBackend
+func (vb *VectorBackend) drawGlyphImage(rs *RenderState, face font.Face, r rune, clr color.Color, pos Vec2D, rot, scx float32) {
+	dr, mask, maskp, _, ok := face.Glyph(pos.Fixed(), r)
+	if !ok || dr.Empty() {
+		return
+	}
+	img := image.NewRGBA(dr.Sub(dr.Min))
+	draw.DrawMask(img, img.Bounds(), image.NewUniform(clr), image.ZP, mask, maskp, draw.Src)
+	dbase := Vec2D{pos.X - float32(dr.Min.X), pos.Y - float32(dr.Min.Y)}
+	xf := Translate2D(pos.X, pos.Y).Scale(scx, 1).Rotate(rot).Translate(-dbase.X, -dbase.Y)
+	vb.DrawImage(rs, img, xf)
+}
+
+//////////////////////////////////////////////////////////////////////////////////
+//  Rendering viewports to vector output
+
+// RenderVector renders given region of the viewport, in its image
+// coordinates, with all of its sub-viewports, into given vector writer, with
+// the top-left of the region at 0,0 in the page of the writer -- the
+// viewport is then rendered again as usual.  Popups are not included.
+func (vp *Viewport2D) RenderVector(vw VectorWriter, region image.Rectangle) {
+	text := &vecTextPend{}
+	bes := map[*Viewport2D]*VectorBackend{}
+	vp.FuncDownMeFirst(0, vp.This(), func(k ki.Ki, level int, d interface{}) bool {
+		nii, _ := KiToNode2D(k)
+		if nii == nil {
+			return false
+		}
+		sv := nii.AsViewport2D()
+		if sv == nil {
+			return true
+		}
+		be := &VectorBackend{Writer: vw, text: text}
+		if sv == vp {
+			be.Offset = region.Min.Mul(-1)
+			be.Clip = image.Rectangle{Max: region.Size()}
+		} else {
+			pbe := bes[sv.Viewport]
+			if pbe == nil || sv.IsPopup() || sv.IsOverlay() {
+				return false
+			}
+			r := sv.Geom.Bounds() // as in DrawIntoParent
+			if pni, _ := KiToNode2D(sv.Par); pni != nil {
+				r = r.Intersect(pni.ChildrenBBox2D())
+			}
+			be.Offset = pbe.Offset.Add(sv.Geom.Pos)
+			be.Clip = r.Add(pbe.Offset).Intersect(pbe.Clip)
+		}
+		bes[sv] = be
+		return true
+	})
+	for sv, be := range bes {
+		sv.Render.Backend = be
+	}
+	vp.FullRender2DTree()
+	for sv, be := range bes {
+		be.Flush()
+		sv.Render.Backend = nil
+	}
+	vp.FullRender2DTree()
+}
+
+// NewVectorWriter returns a new VectorWriter for given format (pdf or svg),
+// with given page size
+func NewVectorWriter(format string, size image.Point) (VectorWriter, error) {
+	switch strings.ToLower(strings.TrimPrefix(format, ".")) {
+	case "pdf":
+		return NewPDFWriter(size), nil
+	case "svg":
+		return NewSVGWriter(size), nil
+	}
+	return nil, fmt.Errorf("gi.NewVectorWriter: unsupported vector format: %v", format)
+}
+
+// EncodeVector renders given region of the viewport (see RenderVector) in
+// given vector format (pdf or svg), writing to given writer
+func (vp *Viewport2D) EncodeVector(w io.Writer, format string, region image.Rectangle) error {
+	vw, err := NewVectorWriter(format, region.Size())
+	if err != nil {
+		return err
+	}
+	vp.RenderVector(vw, region)
+	return vw.Encode(w)
+}
+
+// SaveVector saves the entire viewport to given file, in the vector format
+// given by its extension (.pdf or .svg) -- see RenderVector
+func (vp *Viewport2D) SaveVector(path string) error {
+	return saveVector(vp, vp.Pixels.Bounds(), path)
+}
+
+// SaveNodeVector saves given node (e.g., a TableView or a chart) to given
+// file, in the vector format given by its extension (.pdf or .svg) -- the
+// region of its viewport that the node occupies is rendered -- see
+// RenderVector
+func SaveNodeVector(nii Node2D, path string) error {
+	nb := nii.AsNode2D()
+	if nb.Viewport == nil {
+		return fmt.Errorf("gi.SaveNodeVector: node %v has no viewport", nb.PathUnique())
+	}
+	region := nb.VpBBox
+	if vp := nii.AsViewport2D(); vp != nil {
+		return saveVector(vp, vp.Pixels.Bounds(), path)
+	}
+	return saveVector(nb.Viewport, region, path)
+}
+
+func saveVector(vp *Viewport2D, region image.Rectangle, path string) error {
+	if region.Empty() {
+		return fmt.Errorf("gi.SaveVector: nothing to save -- region is empty")
+	}
+	vw, err := NewVectorWriter(filepath.Ext(path), region.Size())
+	if err != nil {
+		return err
+	}
+	vp.RenderVector(vw, region)
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := vw.Encode(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// vecPathAdder must satisfy the rasterx.Adder interface for rs.Path.AddTo
+var _ rasterx.Adder = (*vecPathAdder)(nil)
@@ -31,6 +31,9 @@ type ViewIFace interface {
 	// KeyMapsView opens an interactive view of KeyMaps object
 	KeyMapsView(maps *KeyMaps)
 
+	// ThemesView opens an interactive view of Themes object
+	ThemesView(themes *Themes)
+
 	// PrefsDetView opens an interactive view of given detailed preferences object
 	PrefsDetView(prefs *PrefsDetailed)
 
@@ -44,6 +47,10 @@ type ViewIFace interface {
 	// PrefsDetApply applies detailed preferences within giv scope
 	PrefsDetApply(prefs *PrefsDetailed)
 
+	// SetHiStyleDefault sets the default syntax highlighting style to the
+	// one of given name, e.g., from the current theme
+	SetHiStyleDefault(style string)
+
 	// PrefsDbgView opens an interactive view of given debugging preferences object
 	PrefsDbgView(prefs *PrefsDebug)
 }
@@ -26,12 +26,13 @@ import (
 // includes toggling selection on left mouse press.
 type WidgetBase struct {
 	Node2DBase
-	Tooltip      string       `desc:"text for tooltip for this widget -- can use HTML formatting"`
-	Sty          Style        `json:"-" xml:"-" desc:"styling settings for this widget -- set in SetStyle2D during an initialization step, and when the structure changes"`
-	DefStyle     *Style       `view:"-" json:"-" xml:"-" desc:"default style values computed by a parent widget for us -- if set, we are a part of a parent widget and should use these as our starting styles instead of type-based defaults"`
-	LayData      LayoutData   `json:"-" xml:"-" desc:"all the layout information for this item"`
-	WidgetSig    ki.Signal    `json:"-" xml:"-" view:"-" desc:"general widget signals supported by all widgets, including select, focus, and context menu (right mouse button) events, which can be used by views and other compound widgets"`
-	CtxtMenuFunc CtxtMenuFunc `view:"-" json:"-" xml:"-" desc:"optional context menu function called by MakeContextMenu AFTER any native items are added -- this function can decide where to insert new elements -- typically add a separator to disambiguate"`
+	Tooltip      string           `desc:"text for tooltip for this widget -- can use HTML formatting"`
+	Sty          Style            `json:"-" xml:"-" desc:"styling settings for this widget -- set in SetStyle2D during an initialization step, and when the structure changes"`
+	DefStyle     *Style           `view:"-" json:"-" xml:"-" desc:"default style values computed by a parent widget for us -- if set, we are a part of a parent widget and should use these as our starting styles instead of type-based defaults"`
+	LayData      LayoutData       `json:"-" xml:"-" desc:"all the layout information for this item"`
+	WidgetSig    ki.Signal        `json:"-" xml:"-" view:"-" desc:"general widget signals supported by all widgets, including select, focus, and context menu (right mouse button) events, which can be used by views and other compound widgets"`
+	CtxtMenuFunc CtxtMenuFunc     `view:"-" json:"-" xml:"-" desc:"optional context menu function called by MakeContextMenu AFTER any native items are added -- this function can decide where to insert new elements -- typically add a separator to disambiguate"`
+	StyTrans     *StyleTransition `view:"-" json:"-" xml:"-" desc:"state of the transition of the style to one of its state styles, if the transition style property is set -- see TransitionStyle"`
 }
 
 var KiT_WidgetBase = kit.Types.AddType(&WidgetBase{}, WidgetBaseProps)
@@ -140,6 +141,7 @@ func (wb *WidgetBase) Style2DWidget() {
 	if wb.Viewport == nil { // robust
 		gii.Init2D()
 	}
+	wb.Sty.StyleCSS(gii, Prefs.DefaultCSS(), "", wb.Viewport) // theme defaults
 	styprops := *wb.Properties()
 	parSty := wb.ParentStyle()
 	wb.Sty.SetStyleProps(parSty, styprops, wb.Viewport)
@@ -165,6 +167,7 @@ func (wb *WidgetBase) Style2DWidget() {
 	}
 	AggCSS(&wb.CSSAgg, wb.CSS)
 	wb.Sty.StyleCSS(gii, wb.CSSAgg, "", wb.Viewport)
+	wb.Sty.StyleCSS(gii, Prefs.OverrideCSS(), "", wb.Viewport)
 
 	wb.Sty.SetUnitContext(wb.Viewport, Vec2DZero) // todo: test for use of el-relative
 	if wb.Sty.Inactive {                          // inactive can only set, not clear
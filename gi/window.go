@@ -153,6 +153,10 @@ type Window struct {
 	DelPopup          ki.Ki                                   `json:"-" xml:"-" desc:"this popup will be popped at the end of the current event cycle -- use SetDelPopup"`
 	PopMu             sync.RWMutex                            `json:"-" xml:"-" view:"-" desc:"read-write mutex that protects popup updating and access"`
 	TimerMu           sync.Mutex                              `json:"-" xml:"-" view:"-" desc:"mutex that protects timer variable updates (e.g., hover AfterFunc's)"`
+	Anims             []*Anim                                 `json:"-" xml:"-" view:"-" desc:"animations currently running in the window -- use StartAnim"`
+	AnimMu            sync.Mutex                              `json:"-" xml:"-" view:"-" desc:"mutex that protects the animations"`
+	animTicker        *time.Ticker
+	animPending       bool
 	lastWinMenuUpdate time.Time
 }
 
@@ -755,6 +759,18 @@ func (w *Window) SendCustomEvent(data interface{}) {
 	oswin.SendCustomEvent(w.OSWin, data)
 }
 
+// winFuncEvent is the data of a custom event that calls the function on the
+// event loop of the window -- see PostFunc
+type winFuncEvent func()
+
+// PostFunc sends a custom event to this window that calls given function on
+// its event loop, after any events already sent to it -- this is how other
+// goroutines (e.g., timers) can safely update the nodes in the window.  The
+// event is not sent to any widgets.
+func (w *Window) PostFunc(fun func()) {
+	oswin.SendCustomEvent(w.OSWin, winFuncEvent(fun))
+}
+
 /////////////////////////////////////////////////////////////////////////////
 //                   Rendering
 
@@ -1257,6 +1273,12 @@ mainloop:
 			fmt.Printf("Win: %v got out-of-range event: %v\n", w.Nm, et)
 			continue
 		}
+		if ce, ok := evi.(*oswin.CustomEvent); ok {
+			if fun, ok := ce.Data.(winFuncEvent); ok {
+				fun() // before any other processing, which it must not affect
+				continue
+			}
+		}
 
 		{ // popup delete check
 			w.PopMu.RLock()
@@ -20,6 +20,9 @@ var dummyGi gi.Vec2D
 var dummSvg svg.Line
 var dummyVV giv.ValueViewBase
 
+// Main runs the given function under the oswin driver for the platform.  Set
+// the GOGI_DRIVER environment variable to headless (or build with the
+// headless build tag) to run without any display, e.g., for tests on CI.
 func Main(mainrun func()) {
 	DebugEnumSizes()
 
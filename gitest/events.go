@@ -0,0 +1,291 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gitest
+
+import (
+	"fmt"
+	"image"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/key"
+	"github.com/goki/gi/oswin/mouse"
+)
+
+// DragSteps is the default number of intermediate drag events sent by Drag
+var DragSteps = 4
+
+// Send sends given event to the window, exactly as the driver would, and
+// waits for it to be processed -- all the other event methods use this.
+func (ts *Tester) Send(ev oswin.Event) {
+	ev.Init()
+	ts.Win.OSWin.Send(ev)
+	ts.WaitIdle()
+}
+
+// sendAged sends given event with its time set to given age in the past --
+// used for events where the window measures delays, e.g., start of dragging
+func (ts *Tester) sendAged(ev oswin.Event, age time.Duration) {
+	ev.Init()
+	switch e := ev.(type) {
+	case *mouse.DragEvent:
+		e.GenTime.SetTime(time.Now().Add(-age))
+	}
+	ts.Win.OSWin.Send(ev)
+	ts.WaitIdle()
+}
+
+// mods converts list of modifiers into bits
+func modBits(mods []key.Modifiers) int32 {
+	var bits int32
+	key.SetModifierBits(&bits, mods...)
+	return bits
+}
+
+/////////////////////////////////////////////////////////////////////////////
+//   Mouse
+
+// MoveTo moves the mouse to given position in window coordinates
+func (ts *Tester) MoveTo(pos image.Point, mods ...key.Modifiers) {
+	ts.Send(&mouse.MoveEvent{
+		Event: mouse.Event{
+			Where:     pos,
+			Button:    mouse.NoButton,
+			Action:    mouse.Move,
+			Modifiers: modBits(mods),
+		},
+		From: ts.Win.LastMousePos,
+	})
+}
+
+// Press presses given mouse button at given position
+func (ts *Tester) Press(pos image.Point, but mouse.Buttons, mods ...key.Modifiers) {
+	ts.Send(&mouse.Event{
+		Where:     pos,
+		Button:    but,
+		Action:    mouse.Press,
+		Modifiers: modBits(mods),
+	})
+}
+
+// Release releases given mouse button at given position
+func (ts *Tester) Release(pos image.Point, but mouse.Buttons, mods ...key.Modifiers) {
+	ts.Send(&mouse.Event{
+		Where:     pos,
+		Button:    but,
+		Action:    mouse.Release,
+		Modifiers: modBits(mods),
+	})
+}
+
+// ClickButton moves to given position and clicks given mouse button there
+func (ts *Tester) ClickButton(pos image.Point, but mouse.Buttons, mods ...key.Modifiers) {
+	ts.MoveTo(pos, mods...)
+	ts.Press(pos, but, mods...)
+	ts.Release(pos, but, mods...)
+}
+
+// Click clicks the left mouse button at given position
+func (ts *Tester) Click(pos image.Point, mods ...key.Modifiers) {
+	ts.ClickButton(pos, mouse.Left, mods...)
+}
+
+// RightClick clicks the right mouse button at given position
+func (ts *Tester) RightClick(pos image.Point, mods ...key.Modifiers) {
+	ts.ClickButton(pos, mouse.Right, mods...)
+}
+
+// DoubleClick double-clicks the left mouse button at given position
+func (ts *Tester) DoubleClick(pos image.Point, mods ...key.Modifiers) {
+	ts.Click(pos, mods...)
+	ts.Send(&mouse.Event{
+		Where:     pos,
+		Button:    mouse.Left,
+		Action:    mouse.DoubleClick,
+		Modifiers: modBits(mods),
+	})
+	ts.Release(pos, mouse.Left, mods...)
+}
+
+// ClickNode clicks the left mouse button in the center of the node at given
+// path (see Node)
+func (ts *Tester) ClickNode(path string, mods ...key.Modifiers) {
+	ts.Click(ts.Center(ts.Node(path)), mods...)
+}
+
+// RightClickNode clicks the right mouse button in the center of the node at
+// given path (see Node)
+func (ts *Tester) RightClickNode(path string, mods ...key.Modifiers) {
+	ts.RightClick(ts.Center(ts.Node(path)), mods...)
+}
+
+// DoubleClickNode double-clicks the left mouse button in the center of the
+// node at given path (see Node)
+func (ts *Tester) DoubleClickNode(path string, mods ...key.Modifiers) {
+	ts.DoubleClick(ts.Center(ts.Node(path)), mods...)
+}
+
+// dragImpl drags with left button from -> to, with first drag event aged by
+// given amount so that the window registers the start of the drag
+func (ts *Tester) dragImpl(from, to image.Point, steps int, age time.Duration, mods []key.Modifiers) {
+	if steps < 1 {
+		steps = 1
+	}
+	bits := modBits(mods)
+	ts.MoveTo(from, mods...)
+	ts.Press(from, mouse.Left, mods...)
+	last := from
+	for i := 0; i <= steps; i++ {
+		pos := image.Point{
+			from.X + ((to.X-from.X)*i)/steps,
+			from.Y + ((to.Y-from.Y)*i)/steps,
+		}
+		ev := &mouse.DragEvent{
+			MoveEvent: mouse.MoveEvent{
+				Event: mouse.Event{
+					Where:     pos,
+					Button:    mouse.Left,
+					Action:    mouse.Drag,
+					Modifiers: bits,
+				},
+				From: last,
+			},
+		}
+		if i == 0 {
+			ts.sendAged(ev, age)
+		} else {
+			ts.Send(ev)
+		}
+		last = pos
+	}
+	ts.Release(to, mouse.Left, mods...)
+}
+
+// Drag drags with the left mouse button from -> to, in given number of
+// steps (DragSteps if 0) -- this is for widget-level dragging, e.g., sliders
+// and scrollbars
+func (ts *Tester) Drag(from, to image.Point, steps int, mods ...key.Modifiers) {
+	if steps == 0 {
+		steps = DragSteps
+	}
+	ts.dragImpl(from, to, steps, time.Duration(gi.DragStartMSec+1)*time.Millisecond, mods)
+}
+
+// DragNode drags the node at given path (see Node) by given delta
+func (ts *Tester) DragNode(path string, delta image.Point, mods ...key.Modifiers) {
+	from := ts.Center(ts.Node(path))
+	ts.Drag(from, from.Add(delta), 0, mods...)
+}
+
+// DragNDrop does a drag-n-drop with the left mouse button from -> to, in
+// given number of steps (DragSteps if 0) -- the first event is aged so that
+// the window starts a drag-n-drop operation
+func (ts *Tester) DragNDrop(from, to image.Point, steps int, mods ...key.Modifiers) {
+	if steps == 0 {
+		steps = DragSteps
+	}
+	ts.dragImpl(from, to, steps, time.Duration(gi.DNDStartMSec+1)*time.Millisecond, mods)
+}
+
+// Scroll sends a scroll wheel event at given position, with given delta
+// (positive Y = scroll down)
+func (ts *Tester) Scroll(pos image.Point, delta image.Point, mods ...key.Modifiers) {
+	ts.MoveTo(pos, mods...)
+	ts.Send(&mouse.ScrollEvent{
+		Event: mouse.Event{
+			Where:     pos,
+			Button:    mouse.NoButton,
+			Action:    mouse.Scroll,
+			Modifiers: modBits(mods),
+		},
+		Delta: delta,
+	})
+}
+
+// ScrollNode scrolls at the center of the node at given path (see Node)
+func (ts *Tester) ScrollNode(path string, delta image.Point, mods ...key.Modifiers) {
+	ts.Scroll(ts.Center(ts.Node(path)), delta, mods...)
+}
+
+/////////////////////////////////////////////////////////////////////////////
+//   Keyboard
+
+// runeCodes are the key codes for runes that have their own keys
+var runeCodes = map[rune]key.Codes{
+	'\n': key.CodeReturnEnter,
+	'\r': key.CodeReturnEnter,
+	'\t': key.CodeTab,
+	' ':  key.CodeSpacebar,
+	'\b': key.CodeDeleteBackspace,
+}
+
+// sendKey sends a key press ChordEvent with given values
+func (ts *Tester) sendKey(r rune, code key.Codes, mods int32) {
+	ts.Send(&key.ChordEvent{
+		Event: key.Event{
+			Rune:      r,
+			Code:      code,
+			Modifiers: mods,
+			Action:    key.Press,
+		},
+	})
+}
+
+// Type types given text into the widget with the keyboard focus, one key
+// chord event per rune -- newline, tab and backspace are sent as the
+// corresponding keys.
+func (ts *Tester) Type(text string) {
+	for _, r := range text {
+		code, has := runeCodes[r]
+		if has && r != ' ' {
+			ts.sendKey(-1, code, 0)
+		} else {
+			ts.sendKey(r, code, 0)
+		}
+	}
+}
+
+// CodeFromString returns the key code for given name, with or without the
+// Code prefix, e.g., "ReturnEnter" or "CodeEscape"
+func CodeFromString(name string) (key.Codes, error) {
+	name = "Code" + strings.TrimPrefix(name, "Code")
+	for c := key.Codes(0); c < 0x10000; c++ {
+		if c.String() == name {
+			return c, nil
+		}
+	}
+	return key.CodeUnknown, fmt.Errorf("gitest: unknown key code name: %v", name)
+}
+
+// KeyChord sends the given key chord, e.g., "Control+A", "Shift+Tab" or
+// "ReturnEnter" -- fails the test if it cannot be decoded.
+func (ts *Tester) KeyChord(ch key.Chord) {
+	mods, rest := key.ModsFmString(string(ch))
+	if utf8.RuneCountInString(rest) == 1 {
+		r, _ := utf8.DecodeRuneInString(rest)
+		ts.sendKey(r, runeCodes[r], mods)
+		return
+	}
+	code, err := CodeFromString(rest)
+	if err != nil {
+		ts.T.Fatal(err)
+		return
+	}
+	ts.sendKey(-1, code, mods)
+}
+
+// KeyFun sends the key chord bound to given key function in the active
+// key map -- fails the test if nothing is bound to it.
+func (ts *Tester) KeyFun(kf gi.KeyFuns) {
+	ch := gi.ActiveKeyMap.ChordForFun(kf)
+	if ch == "" {
+		ts.T.Fatalf("gitest: no key chord for key function: %v", kf)
+		return
+	}
+	ts.KeyChord(ch)
+}
@@ -0,0 +1,77 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gitest
+
+import (
+	"testing"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/ki"
+)
+
+func TestMain(m *testing.M) {
+	Main(m)
+}
+
+func TestClickButton(t *testing.T) {
+	var sigs []gi.ButtonSignals
+	ts := New(t, "click-button", 400, 300, func(mfr *gi.Frame) {
+		but := mfr.AddNewChild(gi.KiT_Button, "but").(*gi.Button)
+		but.SetText("Click")
+		but.ButtonSig.Connect(mfr.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			sigs = append(sigs, gi.ButtonSignals(sig))
+		})
+		mfr.AddNewChild(gi.KiT_Label, "lbl").(*gi.Label).SetText("not a button")
+	})
+	defer ts.Close()
+
+	ts.ClickNode("lbl")
+	if len(sigs) != 0 {
+		t.Errorf("click outside the button gave signals: %v", sigs)
+	}
+	ts.ClickNode("but")
+	clicked := 0
+	for _, sig := range sigs {
+		if sig == gi.ButtonClicked {
+			clicked++
+		}
+	}
+	if clicked != 1 {
+		t.Errorf("click on the button gave signals: %v, want one ButtonClicked", sigs)
+	}
+}
+
+func TestTypeTextField(t *testing.T) {
+	var done []string
+	ts := New(t, "type-textfield", 400, 300, func(mfr *gi.Frame) {
+		tf := mfr.AddNewChild(gi.KiT_TextField, "tf").(*gi.TextField)
+		tf.SetText("")
+		tf.SetStretchMaxWidth()
+		tf.TextFieldSig.Connect(mfr.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			if sig == int64(gi.TextFieldDone) {
+				done = append(done, data.(string))
+			}
+		})
+	})
+	defer ts.Close()
+	tf := ts.Node("tf").(*gi.TextField)
+
+	ts.ClickNode("tf")
+	if ts.Focus() != tf.This() {
+		t.Fatalf("click on the text field did not focus it, focus is: %v", ts.Focus())
+	}
+	ts.Type("hello world")
+	if txt := string(tf.EditTxt); txt != "hello world" {
+		t.Errorf("edit text after typing: %q, want: %q", txt, "hello world")
+	}
+	ts.Type("\b\b")
+	ts.KeyChord("ReturnEnter")
+	if len(done) != 1 || done[0] != "hello wor" {
+		t.Errorf("TextFieldDone signals after return: %q, want: [hello wor]", done)
+	}
+	if txt := tf.Text(); txt != "hello wor" {
+		t.Errorf("text after return: %q, want: %q", txt, "hello wor")
+	}
+}
@@ -0,0 +1,213 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+/*
+Package gitest provides a harness for testing GoGi widgets in a window
+running under the headless oswin driver, with no display required.
+
+Tests call Main from their TestMain function, so that the tests run in the
+context of the headless driver, and then use New to open a window:
+
+	func TestMain(m *testing.M) {
+		gitest.Main(m)
+	}
+
+	func TestButton(t *testing.T) {
+		clicked := false
+		ts := gitest.New(t, "button", 400, 300, func(mfr *gi.Frame) {
+			but := mfr.AddNewChild(gi.KiT_Button, "but").(*gi.Button)
+			but.SetText("Click")
+			but.ButtonSig.Connect(mfr.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+				if sig == int64(gi.ButtonClicked) {
+					clicked = true
+				}
+			})
+		})
+		defer ts.Close()
+		ts.ClickNode("but")
+		if !clicked {
+			t.Error("button was not clicked")
+		}
+	}
+
+All of the event methods (Click, Drag, Scroll, Type etc) send events through
+the OS window, exactly as a driver would, and then wait for the event loop
+to go idle, so widget state can be checked directly after each call.
+*/
+package gitest
+
+import (
+	"image"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/driver/headless"
+	"github.com/goki/ki"
+)
+
+// IdleTimeout is how long to wait for the event loop to go idle before
+// failing the test
+var IdleTimeout = 10 * time.Second
+
+// Main runs the tests under the headless driver -- call it from TestMain
+// in place of m.Run.  It exits with the result of the tests.
+func Main(m *testing.M) {
+	code := 0
+	headless.Main(func(app oswin.App) {
+		code = m.Run()
+	})
+	os.Exit(code)
+}
+
+// Tester wraps a window running under the headless driver, providing methods
+// to send synthetic events to it, and to find and check the widgets in it.
+type Tester struct {
+	T     testing.TB `desc:"the test that this tester reports errors to"`
+	Win   *gi.Window `desc:"the window being tested"`
+	Frame *gi.Frame  `desc:"main frame of the window, which config function populates"`
+}
+
+// New opens a new window of given name and size (in standard 96 DPI
+// pixels), calls config to populate its main frame, starts its event loop,
+// and waits for the initial render to complete.  Call Close when done.
+func New(t testing.TB, name string, width, height int, config func(mfr *gi.Frame)) *Tester {
+	if oswin.TheApp == nil {
+		t.Fatal("gitest: no oswin.TheApp -- call gitest.Main from TestMain")
+		return nil
+	}
+	win := gi.NewWindow2D(name, name, width, height, true)
+	if win == nil {
+		t.Fatalf("gitest: could not open window: %v", name)
+		return nil
+	}
+	ts := &Tester{T: t, Win: win}
+	vp := win.WinViewport2D()
+	updt := vp.UpdateStart()
+	ts.Frame = win.SetMainFrame()
+	if config != nil {
+		config(ts.Frame)
+	}
+	vp.UpdateEndNoSig(updt)
+	win.GoStartEventLoop()
+	ts.WaitIdle()
+	return ts
+}
+
+// Close closes the window and waits for its event loop to finish
+func (ts *Tester) Close() {
+	if ts.Win == nil {
+		return
+	}
+	osw := ts.Win.OSWin
+	ts.Win.Close()
+	headless.WaitIdle(osw, IdleTimeout)
+	ts.Win = nil
+}
+
+// WaitIdle waits until the window has processed all of the events sent to
+// it -- fails the test if that does not happen within IdleTimeout
+func (ts *Tester) WaitIdle() {
+	if !headless.WaitIdle(ts.Win.OSWin, IdleTimeout) {
+		ts.T.Fatalf("gitest: window %v did not go idle within %v", ts.Win.Nm, IdleTimeout)
+	}
+}
+
+// Capture returns an image of the window as last published
+func (ts *Tester) Capture() *image.RGBA {
+	return headless.Capture(ts.Win.OSWin)
+}
+
+// Focus returns the node that currently has the keyboard focus
+func (ts *Tester) Focus() ki.Ki {
+	return ts.Win.CurFocus()
+}
+
+/////////////////////////////////////////////////////////////////////////////
+//   Finding nodes
+
+// NodeByPath returns the node at given path, with elements separated by / --
+// paths starting with / are relative to the window itself (as returned by
+// PathUnique), and otherwise they are relative to the main frame.  Returns
+// nil if not found.
+func (ts *Tester) NodeByPath(path string) ki.Ki {
+	var cur ki.Ki = ts.Frame.This()
+	if strings.HasPrefix(path, "/") {
+		path = strings.TrimPrefix(path, "/")
+		cur = ts.Win.This()
+		wnm := ts.Win.UniqueName() + "/"
+		if strings.HasPrefix(path, wnm) {
+			path = strings.TrimPrefix(path, wnm)
+		} else if wnm = ts.Win.Name() + "/"; strings.HasPrefix(path, wnm) {
+			path = strings.TrimPrefix(path, wnm)
+		}
+	}
+	for _, nm := range strings.Split(path, "/") {
+		if nm == "" {
+			continue
+		}
+		cur = cur.ChildByName(nm, -1)
+		if cur == nil {
+			return nil
+		}
+	}
+	return cur
+}
+
+// NodeByName returns the first node with given name anywhere in the
+// window's viewport, in depth-first order, or nil if not found.
+func (ts *Tester) NodeByName(name string) ki.Ki {
+	var fnd ki.Ki
+	ts.Win.Viewport.FuncDownMeFirst(0, nil, func(k ki.Ki, level int, d interface{}) bool {
+		if fnd != nil {
+			return false
+		}
+		if k.Name() == name {
+			fnd = k
+			return false
+		}
+		return true
+	})
+	return fnd
+}
+
+// Node returns the node at given path (see NodeByPath), or, if there is no
+// such path, the first node with that name (see NodeByName) -- fails the
+// test if not found.
+func (ts *Tester) Node(path string) ki.Ki {
+	k := ts.NodeByPath(path)
+	if k == nil && !strings.Contains(path, "/") {
+		k = ts.NodeByName(path)
+	}
+	if k == nil {
+		ts.T.Fatalf("gitest: node not found: %v", path)
+	}
+	return k
+}
+
+// Center returns the center of the given node in window coordinates -- fails
+// the test if it is not a Node2D.
+func (ts *Tester) Center(k ki.Ki) image.Point {
+	nb := gi.KiToNode2DBase(k)
+	if nb == nil {
+		ts.T.Fatalf("gitest: node is not a Node2D: %v", k.PathUnique())
+		return image.ZP
+	}
+	bb := nb.WinBBox
+	return image.Point{(bb.Min.X + bb.Max.X) / 2, (bb.Min.Y + bb.Max.Y) / 2}
+}
+
+// Widget returns the WidgetBase for node at given path (see Node) -- fails
+// the test if not found or not a widget.
+func (ts *Tester) Widget(path string) *gi.WidgetBase {
+	k := ts.Node(path)
+	wb, ok := k.Embed(gi.KiT_WidgetBase).(*gi.WidgetBase)
+	if !ok || wb == nil {
+		ts.T.Fatalf("gitest: node is not a widget: %v", path)
+	}
+	return wb
+}
@@ -0,0 +1,186 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gitest
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/svg"
+	"github.com/goki/gi/units"
+)
+
+// GoldenDir is the directory where golden images are stored, relative to
+// the directory of the test
+var GoldenDir = "testdata"
+
+// UpdateGolden causes golden images to be written from the current
+// rendering instead of being compared -- it is set if the
+// GOGI_UPDATE_GOLDEN environment variable is set
+var UpdateGolden = os.Getenv("GOGI_UPDATE_GOLDEN") != ""
+
+// DefaultTolerance is the default maximum difference in any one color
+// channel (0-255) for two pixels to be considered the same -- allows for
+// small anti-aliasing differences across platforms
+var DefaultTolerance = 8
+
+// GoldenPath returns the path of the golden image for given name
+func GoldenPath(name string) string {
+	if !strings.HasSuffix(name, ".png") {
+		name += ".png"
+	}
+	return filepath.Join(GoldenDir, name)
+}
+
+// OpenPNG opens a PNG image from given file path
+func OpenPNG(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return png.Decode(f)
+}
+
+// CompareImages compares got with want, returning the number of pixels
+// that differ in any channel by more than tol, and a diff image showing
+// want faded to gray with differing pixels in red.  If the sizes do not
+// match, all pixels are counted as different and diff is nil.
+func CompareImages(got, want image.Image, tol int) (ndiff int, diff *image.RGBA) {
+	gb := got.Bounds()
+	wb := want.Bounds()
+	if gb.Size() != wb.Size() {
+		return gb.Dx() * gb.Dy(), nil
+	}
+	diff = image.NewRGBA(image.Rectangle{Max: wb.Size()})
+	red := color.RGBA{255, 0, 0, 255}
+	for y := 0; y < wb.Dy(); y++ {
+		for x := 0; x < wb.Dx(); x++ {
+			gc := color.RGBAModel.Convert(got.At(gb.Min.X+x, gb.Min.Y+y)).(color.RGBA)
+			wc := color.RGBAModel.Convert(want.At(wb.Min.X+x, wb.Min.Y+y)).(color.RGBA)
+			if channelDiff(gc.R, wc.R) > tol || channelDiff(gc.G, wc.G) > tol ||
+				channelDiff(gc.B, wc.B) > tol || channelDiff(gc.A, wc.A) > tol {
+				ndiff++
+				diff.SetRGBA(x, y, red)
+				continue
+			}
+			gy := uint8((int(wc.R) + int(wc.G) + int(wc.B)) / 3)
+			gy = 192 + gy/4 // faded
+			diff.SetRGBA(x, y, color.RGBA{gy, gy, gy, 255})
+		}
+	}
+	return
+}
+
+func channelDiff(a, b uint8) int {
+	if a > b {
+		return int(a - b)
+	}
+	return int(b - a)
+}
+
+// AssertGolden compares got against the golden image of given name (see
+// GoldenPath), allowing given per-channel tolerance (DefaultTolerance if
+// < 0).  If UpdateGolden is set, the golden image is written instead.
+// Otherwise, if the golden image does not exist, or on any difference, the
+// test fails and the image that was rendered is written to name.got.png, and
+// a diff image to name.diff.png, next to the golden image.  Returns true if
+// the images match.
+func AssertGolden(t testing.TB, got image.Image, name string, tol int) bool {
+	if tol < 0 {
+		tol = DefaultTolerance
+	}
+	path := GoldenPath(name)
+	want, err := OpenPNG(path)
+	if UpdateGolden {
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := gi.SavePNG(path, got); err != nil {
+			t.Errorf("gitest: could not write golden image: %v", err)
+			return false
+		}
+		t.Logf("gitest: wrote golden image: %v", path)
+		return true
+	}
+	base := strings.TrimSuffix(path, ".png")
+	if os.IsNotExist(err) {
+		os.MkdirAll(filepath.Dir(path), 0755)
+		gi.SavePNG(base+".got.png", got)
+		t.Errorf("gitest: golden image: %v does not exist -- see: %v, and set GOGI_UPDATE_GOLDEN to write it", path, base+".got.png")
+		return false
+	}
+	if err != nil {
+		t.Errorf("gitest: could not open golden image: %v", err)
+		return false
+	}
+	ndiff, diff := CompareImages(got, want, tol)
+	if ndiff == 0 {
+		return true
+	}
+	gi.SavePNG(base+".got.png", got)
+	if diff != nil {
+		gi.SavePNG(base+".diff.png", diff)
+		t.Errorf("gitest: %v pixels differ from golden image: %v -- see: %v", ndiff, path, base+".diff.png")
+	} else {
+		t.Errorf("gitest: size %v differs from golden image: %v size %v -- see: %v", got.Bounds().Size(), path, want.Bounds().Size(), base+".got.png")
+	}
+	return false
+}
+
+// AssertSnapshot compares the current rendering of the entire window with
+// the golden image of given name -- see AssertGolden
+func (ts *Tester) AssertSnapshot(name string, tol int) bool {
+	return AssertGolden(ts.T, ts.Win.Viewport.Pixels, name, tol)
+}
+
+// AssertNodeSnapshot compares the current rendering of the node at given
+// path (see Node) with the golden image of given name -- see AssertGolden
+func (ts *Tester) AssertNodeSnapshot(path, name string, tol int) bool {
+	nb := gi.KiToNode2DBase(ts.Node(path))
+	if nb == nil {
+		ts.T.Fatalf("gitest: node is not a Node2D: %v", path)
+		return false
+	}
+	return AssertGolden(ts.T, NodeImage(ts.Win.Viewport.Pixels, nb.WinBBox), name, tol)
+}
+
+// NodeImage returns a copy of the region of img within bbox
+func NodeImage(img *image.RGBA, bbox image.Rectangle) *image.RGBA {
+	bbox = bbox.Intersect(img.Bounds())
+	sub := image.NewRGBA(image.Rectangle{Max: bbox.Size()})
+	draw.Draw(sub, sub.Bounds(), img, bbox.Min, draw.Src)
+	return sub
+}
+
+// NewSVG opens a new window of given name (see New) containing an svg.SVG
+// of given size (in standard 96 DPI pixels) on a white background, which
+// config can populate, e.g., by calling OpenXML.  The svg is rendered into
+// its own Pixels, which can be compared using AssertSVGSnapshot.
+func NewSVG(t testing.TB, name string, width, height int, config func(sv *svg.SVG)) (*Tester, *svg.SVG) {
+	var sv *svg.SVG
+	ts := New(t, name, width+20, height+20, func(mfr *gi.Frame) {
+		sv = mfr.AddNewChild(svg.KiT_SVG, "svg").(*svg.SVG)
+		sv.Fill = true
+		sv.SetProp("background-color", "white")
+		sv.SetProp("width", units.NewValue(float32(width), units.Px))
+		sv.SetProp("height", units.NewValue(float32(height), units.Px))
+		if config != nil {
+			config(sv)
+		}
+	})
+	return ts, sv
+}
+
+// AssertSVGSnapshot compares the current rendering of given svg with the
+// golden image of given name -- see AssertGolden
+func (ts *Tester) AssertSVGSnapshot(sv *svg.SVG, name string, tol int) bool {
+	return AssertGolden(ts.T, sv.Pixels, name, tol)
+}
@@ -0,0 +1,139 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gitest
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// testImage returns an image of given size filled with given gray level
+func testImage(w, h int, gy uint8) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.SetRGBA(x, y, color.RGBA{gy, gy, gy, 255})
+		}
+	}
+	return img
+}
+
+func TestCompareImagesTolerance(t *testing.T) {
+	tests := []struct {
+		delta int
+		tol   int
+		want  int
+	}{
+		{0, 0, 0},
+		{1, 0, 1},
+		{8, 8, 0},
+		{9, 8, 1},
+		{-8, 8, 0},
+		{-9, 8, 1},
+		{100, 99, 1},
+	}
+	for _, tst := range tests {
+		want := testImage(4, 3, 100)
+		got := testImage(4, 3, 100)
+		c := uint8(100 + tst.delta)
+		got.SetRGBA(2, 1, color.RGBA{100, c, 100, 255})
+		ndiff, diff := CompareImages(got, want, tst.tol)
+		if ndiff != tst.want {
+			t.Errorf("CompareImages delta: %v tol: %v = %v pixels differ, want: %v", tst.delta, tst.tol, ndiff, tst.want)
+		}
+		if diff == nil {
+			t.Errorf("CompareImages delta: %v tol: %v returned no diff image", tst.delta, tst.tol)
+		}
+	}
+}
+
+func TestCompareImagesSize(t *testing.T) {
+	ndiff, diff := CompareImages(testImage(4, 3, 0), testImage(4, 4, 0), 0)
+	if ndiff != 12 || diff != nil {
+		t.Errorf("CompareImages of different sizes = %v, %v, want: 12, nil", ndiff, diff)
+	}
+	ndiff, diff = CompareImages(testImage(5, 4, 0), testImage(4, 5, 0), 0)
+	if ndiff != 20 || diff != nil {
+		t.Errorf("CompareImages of different sizes = %v, %v, want: 20, nil", ndiff, diff)
+	}
+}
+
+func TestCompareImagesDiff(t *testing.T) {
+	want := testImage(3, 2, 100)
+	// got is offset, which is compared from its origin
+	big := testImage(5, 5, 100)
+	big.SetRGBA(3, 2, color.RGBA{255, 255, 255, 255})
+	got := big.SubImage(image.Rect(1, 1, 4, 3))
+	ndiff, diff := CompareImages(got, want, DefaultTolerance)
+	if ndiff != 1 {
+		t.Fatalf("CompareImages = %v pixels differ, want: 1", ndiff)
+	}
+	if diff.Bounds() != image.Rect(0, 0, 3, 2) {
+		t.Errorf("diff image bounds: %v, want: %v", diff.Bounds(), image.Rect(0, 0, 3, 2))
+	}
+	faded := uint8(192 + 100/4)
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 3; x++ {
+			wc := color.RGBA{faded, faded, faded, 255}
+			if x == 2 && y == 1 {
+				wc = color.RGBA{255, 0, 0, 255}
+			}
+			if c := diff.RGBAAt(x, y); c != wc {
+				t.Errorf("diff image at %v, %v: %v, want: %v", x, y, c, wc)
+			}
+		}
+	}
+}
+
+// recordTB records the errors reported to it
+type recordTB struct {
+	testing.TB
+	errs []string
+}
+
+func (tb *recordTB) Errorf(format string, args ...interface{}) {
+	tb.errs = append(tb.errs, fmt.Sprintf(format, args...))
+}
+
+func (tb *recordTB) Logf(format string, args ...interface{}) {}
+
+func TestAssertGoldenMissing(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gitest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	sdir, supd := GoldenDir, UpdateGolden
+	defer func() { GoldenDir, UpdateGolden = sdir, supd }()
+	GoldenDir = dir
+	UpdateGolden = false
+
+	img := testImage(4, 4, 50)
+	rt := &recordTB{}
+	if AssertGolden(rt, img, "missing", -1) || len(rt.errs) != 1 {
+		t.Errorf("AssertGolden with no golden image did not fail: %v", rt.errs)
+	}
+	if _, err := os.Stat(GoldenPath("missing")); !os.IsNotExist(err) {
+		t.Errorf("AssertGolden wrote the golden image without UpdateGolden")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "missing.got.png")); err != nil {
+		t.Errorf("AssertGolden did not write the rendered image: %v", err)
+	}
+
+	UpdateGolden = true
+	rt = &recordTB{}
+	if !AssertGolden(rt, img, "missing", -1) || len(rt.errs) != 0 {
+		t.Errorf("AssertGolden with UpdateGolden failed: %v", rt.errs)
+	}
+	UpdateGolden = false
+	if !AssertGolden(rt, img, "missing", -1) || len(rt.errs) != 0 {
+		t.Errorf("AssertGolden with the written golden image failed: %v", rt.errs)
+	}
+}
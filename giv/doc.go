@@ -44,7 +44,10 @@ TableView
 TableView displays a slice-of-struct as a table with columns as the struct fields
 and rows as the elements in the struct.  You can sort by the column headers
 and it supports full editing with drag-n-drop etc.  If set to Inactive, then it
-serves as a chooser, as in the FileView.
+serves as a chooser, as in the FileView.  For large slices (see
+SliceViewVirtualSize), TableView and SliceView use virtual scrolling, only
+creating widgets for the rows that fit in the display, and reconfiguring them
+as it scrolls.
 
 MethodView
 
@@ -46,6 +46,9 @@ type SliceView struct {
 	ShowIndex        bool               `xml:"index" desc:"whether to show index or not -- updated from 'index' property (bool)"`
 	InactKeyNav      bool               `xml:"inact-key-nav" desc:"support key navigation when inactive (default true) -- updated from 'intact-key-nav' property (bool) -- no focus really plausible in inactive case, so it uses a low-pri capture of up / down events"`
 	VisRows          int                `desc:"number of rows visible in display"`
+	Virtual          bool               `desc:"use virtual scrolling, where widgets are only created for the DispRows rows of the slice starting at StartIdx, and reconfigured to show other rows as the display scrolls -- updated from 'virtual' property (bool), and on by default for slices longer than SliceViewVirtualSize"`
+	StartIdx         int                `desc:"in Virtual mode, index of the first row of the slice that is displayed"`
+	DispRows         int                `desc:"in Virtual mode, number of rows of widgets that are created to display the slice"`
 	SelVal           interface{}        `view:"-" json:"-" xml:"-" desc:"current selection value -- initially select this value if set"`
 	SelectedIdx      int                `json:"-" xml:"-" desc:"index of currently-selected item, in Inactive mode only"`
 	SelectMode       bool               `desc:"editing-mode select rows mode"`
@@ -58,7 +61,8 @@ type SliceView struct {
 	BuiltSize        int
 	ToolbarSlice     interface{} `desc:"the slice that we successfully set a toolbar for"`
 	inFocusGrab      bool
-	curRow           int // temp row variable used e.g., in Drop method
+	curRow           int     // temp row variable used e.g., in Drop method
+	rowHeight        float32 // height of a row in Virtual mode, for mouse scrolling
 }
 
 var KiT_SliceView = kit.Types.AddType(&SliceView{}, SliceViewProps)
@@ -78,6 +82,7 @@ func (sv *SliceView) SetSlice(sl interface{}, tmpSave ValueView) {
 		updt = sv.UpdateStart()
 		sv.Slice = sl
 		sv.isArray = kit.NonPtrType(reflect.TypeOf(sl)).Kind() == reflect.Array
+		sv.StartIdx = 0
 		if !sv.IsInactive() {
 			sv.SelectedIdx = -1
 		}
@@ -146,13 +151,20 @@ func (sv *SliceView) UpdateValues() {
 func (sv *SliceView) StdFrameConfig() kit.TypeAndNameList {
 	config := kit.TypeAndNameList{}
 	config.Add(gi.KiT_ToolBar, "toolbar")
-	config.Add(gi.KiT_Frame, "slice-grid")
+	if sv.Virtual {
+		config.Add(gi.KiT_Layout, "slice-frame")
+	} else {
+		config.Add(gi.KiT_Frame, "slice-grid")
+	}
 	return config
 }
 
 // StdConfig configures a standard setup of the overall Frame -- returns mods,
 // updt from ConfigChildren and does NOT call UpdateEnd
 func (sv *SliceView) StdConfig() (mods, updt bool) {
+	if !kit.IfaceIsNil(sv.Slice) {
+		sv.Virtual = SliceIsVirtual(sv.This(), kit.NonPtrValue(reflect.ValueOf(sv.Slice)).Len())
+	}
 	sv.Lay = gi.LayoutVert
 	sv.SetProp("spacing", gi.StdDialogVSpaceUnits)
 	config := sv.StdFrameConfig()
@@ -161,8 +173,17 @@ func (sv *SliceView) StdConfig() (mods, updt bool) {
 }
 
 // SliceGrid returns the SliceGrid grid frame widget, which contains all the
-// fields and values, and its index, within frame -- nil, -1 if not found
+// fields and values, and its index, within frame -- nil, -1 if not found --
+// in Virtual mode it is within a slice-frame layout along with its
+// scrollbar, and the index is that of the slice-frame
 func (sv *SliceView) SliceGrid() (*gi.Frame, int) {
+	if idx, ok := sv.Children().IndexByName("slice-frame", 0); ok {
+		vf := sv.Child(idx)
+		if !vf.HasChildren() {
+			return nil, -1
+		}
+		return vf.Child(0).(*gi.Frame), idx
+	}
 	idx, ok := sv.Children().IndexByName("slice-grid", 0)
 	if !ok {
 		return nil, -1
@@ -170,6 +191,16 @@ func (sv *SliceView) SliceGrid() (*gi.Frame, int) {
 	return sv.Child(idx).(*gi.Frame), idx
 }
 
+// VirtScrollBar returns the scrollbar for the SliceGrid in Virtual mode --
+// nil if not in Virtual mode
+func (sv *SliceView) VirtScrollBar() *gi.ScrollBar {
+	idx, ok := sv.Children().IndexByName("slice-frame", 0)
+	if !ok || !sv.Child(idx).HasChildren() {
+		return nil
+	}
+	return sv.Child(idx).Child(1).(*gi.ScrollBar)
+}
+
 // ToolBar returns the toolbar widget
 func (sv *SliceView) ToolBar() *gi.ToolBar {
 	idx, ok := sv.Children().IndexByName("toolbar", 0)
@@ -213,6 +244,36 @@ func (sv *SliceView) ConfigSliceGrid(forceUpdt bool) {
 	sv.BuiltSlice = sv.Slice
 	sv.BuiltSize = sz
 
+	if SliceIsVirtual(sv.This(), sz) != sv.Virtual { // size crossed the threshold
+		mods, updt := sv.StdConfig()
+		if mods {
+			sv.SetFullReRender()
+			defer sv.UpdateEnd(updt)
+		}
+	}
+
+	nrows := sz
+	if sv.Virtual {
+		idx, ok := sv.Children().IndexByName("slice-frame", 0)
+		if !ok {
+			return
+		}
+		sb := ConfigVirtFrame(sv.Child(idx).(*gi.Layout), "slice-grid")
+		sb.SliderSig.ConnectOnly(sv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			if sig != int64(gi.SliderValueChanged) {
+				return
+			}
+			svv := recv.Embed(KiT_SliceView).(*SliceView)
+			svv.SetStartIdx(int(data.(float32)))
+		})
+		sv.DispRows = VirtDispRows(sv.VisRows, sz)
+		nrows = sv.DispRows
+		if sv.SelVal != nil {
+			sv.SelectedIdx, _ = SliceRowByValue(sv.Slice, sv.SelVal)
+		}
+		sv.StartIdx = VirtStartIdx(sv.StartIdx, sv.SelectedIdx, sv.VirtVisRows(), sz)
+	}
+
 	sg, _ := sv.SliceGrid()
 	if sg == nil {
 		return
@@ -230,18 +291,22 @@ func (sv *SliceView) ConfigSliceGrid(forceUpdt bool) {
 	sg.SetMinPrefWidth(units.NewValue(10, units.Em))
 	sg.SetStretchMaxHeight() // for this to work, ALL layers above need it too
 	sg.SetStretchMaxWidth()  // for this to work, ALL layers above need it too
+	if sv.Virtual {
+		sg.SetProp("overflow", "hidden") // scrolled by reconfiguring rows
+	}
 
-	sv.Values = make([]ValueView, sz)
+	sv.Values = make([]ValueView, nrows)
 
 	sg.DeleteChildren(true)
-	sg.Kids = make(ki.Slice, nWidgPerRow*sz)
+	sg.Kids = make(ki.Slice, nWidgPerRow*nrows)
 
 	sv.ConfigSliceGridRows()
 }
 
 // ConfigSliceGridRows configures the SliceGrid rows for the current slice --
 // assumes .Kids is created at the right size -- only call this for a direct
-// re-render e.g., after sorting
+// re-render e.g., after sorting -- in Virtual mode, it configures the rows
+// starting at StartIdx, reusing the existing widgets
 func (sv *SliceView) ConfigSliceGridRows() {
 	mv := reflect.ValueOf(sv.Slice)
 	mvnp := kit.NonPtrValue(mv)
@@ -252,15 +317,21 @@ func (sv *SliceView) ConfigSliceGridRows() {
 	updt := sg.UpdateStart()
 	defer sg.UpdateEnd(updt)
 
-	for i := 0; i < sz; i++ {
-		ridx := i * nWidgPerRow
+	stIdx, nrows := sv.DispRange(sz)
+	if sv.Virtual {
+		SetVirtGridRows(sg, nrows*nWidgPerRow)
+	}
+
+	for r := 0; r < nrows; r++ {
+		i := stIdx + r
+		ridx := r * nWidgPerRow
 		val := kit.OnePtrValue(mvnp.Index(i)) // deal with pointer lists
 		vv := ToValueView(val.Interface(), "")
 		if vv == nil { // shouldn't happen
 			continue
 		}
 		vv.SetSliceValue(val, sv.Slice, i, sv.TmpSave)
-		sv.Values[i] = vv
+		sv.Values[r] = vv
 		vtyp := vv.WidgetType()
 		idxtxt := fmt.Sprintf("%05d", i)
 		labnm := fmt.Sprintf("index-%v", idxtxt)
@@ -277,6 +348,9 @@ func (sv *SliceView) ConfigSliceGridRows() {
 			idxlab.Text = idxtxt
 			idxlab.SetProp("slv-index", i)
 			idxlab.Selectable = true
+			if sv.Virtual {
+				idxlab.SetSelectedState(sv.dispRowSelected(i))
+			}
 			idxlab.WidgetSig.ConnectOnly(sv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
 				if sig == int64(gi.WidgetSelected) {
 					wbb := send.(gi.Node2D).AsWidget()
@@ -295,13 +369,18 @@ func (sv *SliceView) ConfigSliceGridRows() {
 			sg.SetChild(widg, ridx+idxOff, valnm)
 		}
 		vv.ConfigWidget(widg)
+		if sv.Virtual {
+			widg.AsNode2D().SetSelectedState(sv.dispRowSelected(i))
+		}
 
 		if sv.IsInactive() {
 			widg.AsNode2D().SetInactive()
 			wb := widg.AsWidget()
 			if wb != nil {
 				wb.SetProp("slv-index", i)
-				wb.ClearSelected()
+				if !sv.Virtual {
+					wb.ClearSelected()
+				}
 				wb.WidgetSig.ConnectOnly(sv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
 					if sig == int64(gi.WidgetSelected) || sig == int64(gi.WidgetFocused) {
 						wbb := send.(gi.Node2D).AsWidget()
@@ -321,9 +400,14 @@ func (sv *SliceView) ConfigSliceGridRows() {
 				cidx := ridx + idxOff
 				if !sv.DeleteOnly {
 					addnm := fmt.Sprintf("add-%v", idxtxt)
-					addact := gi.Action{}
 					cidx += 1
-					sg.SetChild(&addact, cidx, addnm)
+					var addact *gi.Action
+					if sg.Kids[cidx] != nil {
+						addact = sg.Kids[cidx].(*gi.Action)
+					} else {
+						addact = &gi.Action{}
+						sg.SetChild(addact, cidx, addnm)
+					}
 
 					addact.SetIcon("plus")
 					addact.Tooltip = "insert a new element at this index"
@@ -337,9 +421,14 @@ func (sv *SliceView) ConfigSliceGridRows() {
 
 				if !sv.AddOnly {
 					delnm := fmt.Sprintf("del-%v", idxtxt)
-					delact := gi.Action{}
 					cidx += 1
-					sg.SetChild(&delact, cidx, delnm)
+					var delact *gi.Action
+					if sg.Kids[cidx] != nil {
+						delact = sg.Kids[cidx].(*gi.Action)
+					} else {
+						delact = &gi.Action{}
+						sg.SetChild(delact, cidx, delnm)
+					}
 
 					delact.SetIcon("minus")
 					delact.Tooltip = "delete this element"
@@ -501,6 +590,21 @@ func (sv *SliceView) Style2D() {
 	sv.Frame.Style2D()
 }
 
+func (sv *SliceView) Layout2D(parBBox image.Rectangle, iter int) bool {
+	redo := sv.Frame.Layout2D(parBBox, iter)
+	if sv.Virtual {
+		if sg, _ := sv.SliceGrid(); sg != nil {
+			if vis, rowHt := VirtGridVisRows(sg); vis > 0 {
+				sv.VisRows = vis
+				sv.rowHeight = rowHt
+				sv.GrowDispRows()
+				UpdateVirtScrollBar(sv.VirtScrollBar(), sv.StartIdx, sv.VirtVisRows(), sv.BuiltSize)
+			}
+		}
+	}
+	return redo
+}
+
 func (sv *SliceView) Render2D() {
 	sv.ToolBar().UpdateActions()
 	if win := sv.ParentWindow(); win != nil {
@@ -512,17 +616,19 @@ func (sv *SliceView) Render2D() {
 		return
 	}
 	if sv.PushBounds() {
-		if sv.Sty.Font.Height > 0 {
-			sv.VisRows = (sv.VpBBox.Max.Y - sv.VpBBox.Min.Y) / int(1.8*sv.Sty.Font.Height)
-		} else {
-			sv.VisRows = 10
+		if !sv.Virtual { // Virtual gets VisRows from the grid in Layout2D
+			if sv.Sty.Font.Height > 0 {
+				sv.VisRows = (sv.VpBBox.Max.Y - sv.VpBBox.Min.Y) / int(1.8*sv.Sty.Font.Height)
+			} else {
+				sv.VisRows = 10
+			}
 		}
 		sv.FrameStdRender()
 		sv.This().(gi.Node2D).ConnectEvents2D()
 		sv.RenderScrolls()
 		sv.Render2DChildren()
 		sv.PopBounds()
-		if sv.SelectedIdx > -1 {
+		if sv.SelectedIdx > -1 && !sv.Virtual { // Virtual shows it in ConfigSliceGrid
 			sv.ScrollToRow(sv.SelectedIdx)
 		}
 	} else {
@@ -558,8 +664,44 @@ func (sv *SliceView) RowVal(row int) interface{} {
 	return vali
 }
 
+// DispRange returns the index of the first row of the slice that is
+// displayed, and the number of rows displayed, for given slice size -- all
+// of them unless in Virtual mode
+func (sv *SliceView) DispRange(sz int) (st, n int) {
+	if !sv.Virtual {
+		return 0, sz
+	}
+	return sv.StartIdx, ints.MaxInt(ints.MinInt(sv.DispRows, sz-sv.StartIdx), 0)
+}
+
+// RowGridIdx returns the index within the SliceGrid of the first widget for
+// given row -- false if the row is not displayed, in Virtual mode
+func (sv *SliceView) RowGridIdx(row int) (int, bool) {
+	nWidgPerRow, _ := sv.RowWidgetNs()
+	if sv.Virtual {
+		row -= sv.StartIdx
+		if row < 0 || row >= sv.DispRows {
+			return -1, false
+		}
+	}
+	sg, _ := sv.SliceGrid()
+	ridx := row * nWidgPerRow
+	if sg == nil || ridx < 0 || ridx >= len(sg.Kids) {
+		return -1, false
+	}
+	return ridx, true
+}
+
+// dispRowSelected returns the selection state to display for given row
+func (sv *SliceView) dispRowSelected(row int) bool {
+	if sv.IsInactive() {
+		return row == sv.SelectedIdx
+	}
+	return sv.RowIsSelected(row)
+}
+
 // RowFirstWidget returns the first widget for given row (could be index or
-// not) -- false if out of range
+// not) -- false if out of range, or not displayed in Virtual mode
 func (sv *SliceView) RowFirstWidget(row int) (*gi.WidgetBase, bool) {
 	if !sv.ShowIndex {
 		return nil, false
@@ -567,12 +709,12 @@ func (sv *SliceView) RowFirstWidget(row int) (*gi.WidgetBase, bool) {
 	if sv.RowVal(row) == nil { // range check
 		return nil, false
 	}
-	nWidgPerRow, _ := sv.RowWidgetNs()
-	sg, _ := sv.SliceGrid()
-	if sg == nil {
+	ridx, ok := sv.RowGridIdx(row)
+	if !ok {
 		return nil, false
 	}
-	widg := sg.Kids[row*nWidgPerRow].(gi.Node2D).AsWidget()
+	sg, _ := sv.SliceGrid()
+	widg := sg.Kids[ridx].(gi.Node2D).AsWidget()
 	return widg, true
 }
 
@@ -583,12 +725,12 @@ func (sv *SliceView) RowGrabFocus(row int) *gi.WidgetBase {
 	if sv.RowVal(row) == nil || sv.inFocusGrab { // range check
 		return nil
 	}
-	nWidgPerRow, idxOff := sv.RowWidgetNs()
-	sg, _ := sv.SliceGrid()
-	if sg == nil {
+	_, idxOff := sv.RowWidgetNs()
+	ridx, ok := sv.RowGridIdx(row)
+	if !ok {
 		return nil
 	}
-	ridx := nWidgPerRow * row
+	sg, _ := sv.SliceGrid()
 	widg := sg.Child(ridx + idxOff).(gi.Node2D).AsWidget()
 	if widg.HasFocus() {
 		return widg
@@ -615,7 +757,8 @@ func (sv *SliceView) RowPos(row int) image.Point {
 // RowFromPos returns the row that contains given vertical position, false if not found
 func (sv *SliceView) RowFromPos(posY int) (int, bool) {
 	// todo: could optimize search to approx loc, and search up / down from there
-	for rw := 0; rw < sv.BuiltSize; rw++ {
+	st, n := sv.DispRange(sv.BuiltSize)
+	for rw := st; rw < st+n; rw++ {
 		widg, ok := sv.RowFirstWidget(rw)
 		if ok {
 			if widg.WinBBox.Min.Y < posY && posY < widg.WinBBox.Max.Y {
@@ -630,6 +773,15 @@ func (sv *SliceView) RowFromPos(posY int) (int, bool) {
 // -- returns true if any scrolling was performed
 func (sv *SliceView) ScrollToRow(row int) bool {
 	row = ints.MinInt(row, sv.BuiltSize-1)
+	if sv.Virtual {
+		if !sv.SetStartIdx(VirtStartIdx(sv.StartIdx, row, sv.VirtVisRows(), sv.BuiltSize)) {
+			return false
+		}
+		if !sv.IsInactive() && row == sv.SelectedIdx {
+			sv.RowGrabFocus(row)
+		}
+		return true
+	}
 	sg, _ := sv.SliceGrid()
 	if widg, ok := sv.RowFirstWidget(row); ok {
 		return sg.ScrollToItem(widg)
@@ -637,6 +789,46 @@ func (sv *SliceView) ScrollToRow(row int) bool {
 	return false
 }
 
+// VirtVisRows returns the number of rows visible in Virtual mode, which
+// can be no more than DispRows
+func (sv *SliceView) VirtVisRows() int {
+	return ints.MinInt(sv.VisRows, sv.DispRows)
+}
+
+// GrowDispRows creates more rows of widgets in Virtual mode, if more rows
+// are visible than DispRows, which is only an initial guess before the
+// first layout -- returns true if it did
+func (sv *SliceView) GrowDispRows() bool {
+	if !sv.Virtual || sv.VisRows <= sv.DispRows || sv.DispRows >= sv.BuiltSize {
+		return false
+	}
+	sv.DispRows = VirtDispRows(sv.VisRows, sv.BuiltSize)
+	sv.StartIdx = VirtStartIdx(sv.StartIdx, -1, sv.VirtVisRows(), sv.BuiltSize)
+	sg, _ := sv.SliceGrid()
+	sg.SetFullReRender()
+	sv.ConfigSliceGridRows()
+	return true
+}
+
+// SetStartIdx sets the index of the first row of the slice that is
+// displayed in Virtual mode, and reconfigures the rows of widgets to show
+// the rows from there -- returns true if it changed
+func (sv *SliceView) SetStartIdx(idx int) bool {
+	if !sv.Virtual {
+		return false
+	}
+	idx = VirtStartIdx(idx, -1, sv.VirtVisRows(), sv.BuiltSize)
+	if idx == sv.StartIdx {
+		return false
+	}
+	sv.StartIdx = idx
+	sg, _ := sv.SliceGrid()
+	sg.SetFullReRender()
+	sv.ConfigSliceGridRows()
+	UpdateVirtScrollBar(sv.VirtScrollBar(), sv.StartIdx, sv.VirtVisRows(), sv.BuiltSize)
+	return true
+}
+
 // SelectVal sets SelVal and attempts to find corresponding row, setting
 // SelectedIdx and selecting row if found -- returns true if found, false
 // otherwise.
@@ -774,9 +966,12 @@ func (sv *SliceView) MovePageUpAction(selMode mouse.SelectModes) int {
 
 // SelectRowWidgets sets the selection state of given row of widgets
 func (sv *SliceView) SelectRowWidgets(idx int, sel bool) {
+	rowidx, ok := sv.RowGridIdx(idx)
+	if !ok {
+		return
+	}
 	sg, _ := sv.SliceGrid()
-	nWidgPerRow, idxOff := sv.RowWidgetNs()
-	rowidx := idx * nWidgPerRow
+	_, idxOff := sv.RowWidgetNs()
 	if sv.ShowIndex {
 		if sg.Kids.IsValidIndex(rowidx) == nil {
 			widg := sg.Child(rowidx).(gi.Node2D).AsNode2D()
@@ -1221,6 +1416,13 @@ func (sv *SliceView) DragNDropStart() {
 	}
 	rws := sv.SelectedRowsList(true) // descending sort
 	widg, ok := sv.RowFirstWidget(rws[0])
+	if !ok && sv.Virtual { // first selected row may not be displayed
+		for _, r := range rws[1:] {
+			if widg, ok = sv.RowFirstWidget(r); ok {
+				break
+			}
+		}
+	}
 	if ok {
 		bi := &gi.Bitmap{}
 		bi.InitName(bi, sv.UniqueName())
@@ -1524,6 +1726,15 @@ func (sv *SliceView) KeyInputInactive(kt *key.ChordEvent) {
 }
 
 func (sv *SliceView) SliceViewEvents() {
+	if sv.Virtual {
+		sv.ConnectEvent(oswin.MouseScrollEvent, gi.LowPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+			me := d.(*mouse.ScrollEvent)
+			svv := recv.Embed(KiT_SliceView).(*SliceView)
+			if svv.SetStartIdx(svv.StartIdx + VirtScrollRows(me.Delta.Y, svv.rowHeight)) {
+				me.SetProcessed()
+			}
+		})
+	}
 	if sv.IsInactive() {
 		if sv.InactKeyNav {
 			sv.ConnectEvent(oswin.KeyChordEvent, gi.LowPri, func(recv, send ki.Ki, sig int64, d interface{}) {
@@ -0,0 +1,155 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import (
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/units"
+	"github.com/goki/ki"
+	"github.com/goki/ki/ints"
+	"github.com/goki/ki/kit"
+)
+
+// Virtual scrolling: for very large slices, SliceView and TableView can
+// create only enough rows of widgets to fill the display, starting at the
+// StartIdx row of the slice, and show other rows by reconfiguring those
+// widgets as the user scrolls, using their own scrollbar -- selection, copy /
+// paste, drag-n-drop etc all operate on slice indexes, so they work on rows
+// that are not shown as well
+
+// SliceViewVirtualSize is the length of the slice above which SliceView and
+// TableView use virtual scrolling, even if their virtual property is not set
+var SliceViewVirtualSize = 10000
+
+// SliceViewVirtualRows is the minimum number of rows of widgets created in
+// virtual scrolling mode -- more are created by GrowDispRows, once layout
+// shows that more are visible
+var SliceViewVirtualRows = 40
+
+// SliceIsVirtual returns whether a slice view should use virtual scrolling
+// for a slice of given size: per its virtual property if set, and otherwise
+// if the slice is longer than SliceViewVirtualSize
+func SliceIsVirtual(sv ki.Ki, sz int) bool {
+	if vp, ok := sv.Prop("virtual"); ok {
+		if virt, ok := kit.ToBool(vp); ok {
+			return virt
+		}
+	}
+	return sz > SliceViewVirtualSize
+}
+
+// VirtStdFrameConfig returns a TypeAndNameList for configuring the frame
+// that holds the grid of a slice view in virtual scrolling mode, along with
+// its scrollbar
+func VirtStdFrameConfig(gridName string) kit.TypeAndNameList {
+	config := kit.TypeAndNameList{}
+	config.Add(gi.KiT_Frame, gridName)
+	config.Add(gi.KiT_ScrollBar, "scrollbar")
+	return config
+}
+
+// ConfigVirtFrame configures the frame that holds the grid of a slice view
+// in virtual scrolling mode, along with its scrollbar, which is returned
+func ConfigVirtFrame(vf *gi.Layout, gridName string) *gi.ScrollBar {
+	vf.Lay = gi.LayoutHoriz
+	vf.SetProp("spacing", 0)
+	vf.SetStretchMaxHeight()
+	vf.SetStretchMaxWidth()
+	mods, updt := vf.ConfigChildren(VirtStdFrameConfig(gridName), false)
+	sb := vf.Child(1).(*gi.ScrollBar)
+	if mods {
+		sb.Defaults()
+		sb.Dim = gi.Y
+		sb.Tracking = true
+		sb.Min = 0
+		sb.Step = 1
+		sb.SetFixedWidth(units.NewValue(16, units.Px)) // default layout scrollbar-width
+		sb.SetStretchMaxHeight()
+		vf.UpdateEnd(updt)
+	}
+	return sb
+}
+
+// SetVirtGridRows sets the number of children of the grid of a slice view in
+// virtual scrolling mode, deleting extra ones, and adding nil ones to be
+// configured
+func SetVirtGridRows(sg *gi.Frame, n int) {
+	for len(sg.Kids) > n {
+		sg.DeleteChildAtIndex(len(sg.Kids)-1, true)
+	}
+	if len(sg.Kids) < n {
+		sg.Kids = append(sg.Kids, make(ki.Slice, n-len(sg.Kids))...)
+	}
+}
+
+// VirtDispRows returns the number of rows of widgets to create in virtual
+// scrolling mode, for given number of visible rows and slice size
+func VirtDispRows(vis, sz int) int {
+	return ints.MinInt(ints.MaxInt(vis, SliceViewVirtualRows), sz)
+}
+
+// VirtStartIdx returns the index of the first row to show in virtual
+// scrolling mode, for given current start, number of visible rows and
+// slice size -- if row >= 0, the start moves as little as needed to show it
+func VirtStartIdx(start, row, vis, sz int) int {
+	vis = ints.MaxInt(vis, 1)
+	if row >= 0 {
+		if row < start {
+			start = row
+		} else if row >= start+vis {
+			start = row - vis + 1
+		}
+	}
+	return ints.MaxInt(ints.MinInt(start, sz-vis), 0)
+}
+
+// UpdateVirtScrollBar updates the range and thumb of the scrollbar of a
+// slice view in virtual scrolling mode, for given start row, number of
+// visible rows and slice size
+func UpdateVirtScrollBar(sb *gi.ScrollBar, start, vis, sz int) {
+	if sb == nil {
+		return
+	}
+	updt := sb.UpdateStart()
+	sb.Max = float32(ints.MaxInt(sz, 1))
+	sb.PageStep = float32(ints.MaxInt(vis-1, 1))
+	sb.SetThumbValue(float32(ints.MinInt(vis, sz)))
+	sb.SetValue(float32(start))
+	sb.UpdatePosFromValue()
+	sb.SetInactiveState(vis >= sz)
+	sb.UpdateEnd(updt)
+}
+
+// VirtScrollRows returns the number of rows to scroll by for given delta of
+// a mouse scroll event, and height of a row -- at least one row in the
+// direction of the delta
+func VirtScrollRows(delta int, rowHt float32) int {
+	if delta == 0 {
+		return 0
+	}
+	if rowHt <= 0 {
+		rowHt = 1
+	}
+	n := int(float32(delta) / rowHt)
+	if n == 0 {
+		if delta < 0 {
+			return -1
+		}
+		return 1
+	}
+	return n
+}
+
+// VirtGridVisRows returns the number of rows of the grid of a slice view
+// that are fully visible, and the height of a row, from its layout
+func VirtGridVisRows(sg *gi.Frame) (int, float32) {
+	gd := sg.GridData[gi.Row]
+	if len(gd) == 0 || gd[0].AllocSize <= 0 {
+		return 0, 0
+	}
+	rowHt := gd[0].AllocSize + sg.Spacing.Dots
+	avail := sg.LayData.AllocSize.Y - 2.0*sg.Sty.BoxSpace()
+	return ints.MaxInt(int((avail+sg.Spacing.Dots)/rowHt), 1), rowHt
+}
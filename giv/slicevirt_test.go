@@ -0,0 +1,82 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import "testing"
+
+func TestVirtStartIdx(t *testing.T) {
+	tests := []struct {
+		start, row, vis, sz int
+		want                int
+	}{
+		{0, -1, 10, 100, 0},
+		{50, -1, 10, 100, 50},
+		{95, -1, 10, 100, 90}, // clamped to show a full page at the end
+		{-5, -1, 10, 100, 0},  // clamped at the start
+		{5, -1, 10, 8, 0},     // all rows fit
+		{20, 25, 10, 100, 20}, // row already visible
+		{20, 29, 10, 100, 20}, // last visible row
+		{20, 30, 10, 100, 21}, // just below: scroll by one
+		{20, 60, 10, 100, 51}, // far below: row at the bottom
+		{20, 19, 10, 100, 19}, // just above: scroll by one
+		{20, 3, 10, 100, 3},   // far above: row at the top
+		{20, 99, 10, 100, 90}, // last row
+		{20, 30, 0, 100, 30},  // no visible rows counts as one
+		{0, 5000, 40, 10000, 4961},
+	}
+	for _, tst := range tests {
+		got := VirtStartIdx(tst.start, tst.row, tst.vis, tst.sz)
+		if got != tst.want {
+			t.Errorf("VirtStartIdx(%v, %v, %v, %v) = %v, want: %v", tst.start, tst.row, tst.vis, tst.sz, got, tst.want)
+		}
+	}
+}
+
+func TestVirtDispRows(t *testing.T) {
+	tests := []struct {
+		vis, sz int
+		want    int
+	}{
+		{0, 100000, SliceViewVirtualRows},
+		{10, 100000, SliceViewVirtualRows},
+		{SliceViewVirtualRows + 25, 100000, SliceViewVirtualRows + 25},
+		{10, 15, 15},
+		{100, 60, 60},
+	}
+	for _, tst := range tests {
+		got := VirtDispRows(tst.vis, tst.sz)
+		if got != tst.want {
+			t.Errorf("VirtDispRows(%v, %v) = %v, want: %v", tst.vis, tst.sz, got, tst.want)
+		}
+	}
+}
+
+func TestDispRange(t *testing.T) {
+	tests := []struct {
+		virtual         bool
+		start, disp, sz int
+		wantSt, wantN   int
+	}{
+		{false, 0, 0, 25, 0, 25},
+		{false, 10, 40, 25, 0, 25},
+		{true, 0, 40, 100, 0, 40},
+		{true, 30, 40, 100, 30, 40},
+		{true, 80, 40, 100, 80, 20},
+		{true, 0, 40, 10, 0, 10},
+		{true, 120, 40, 100, 120, 0},
+	}
+	for _, tst := range tests {
+		sv := &SliceView{Virtual: tst.virtual, StartIdx: tst.start, DispRows: tst.disp}
+		st, n := sv.DispRange(tst.sz)
+		if st != tst.wantSt || n != tst.wantN {
+			t.Errorf("SliceView DispRange(%v) virtual: %v start: %v disp: %v = %v, %v, want: %v, %v", tst.sz, tst.virtual, tst.start, tst.disp, st, n, tst.wantSt, tst.wantN)
+		}
+		tv := &TableView{Virtual: tst.virtual, StartIdx: tst.start, DispRows: tst.disp}
+		st, n = tv.DispRange(tst.sz)
+		if st != tst.wantSt || n != tst.wantN {
+			t.Errorf("TableView DispRange(%v) virtual: %v start: %v disp: %v = %v, %v, want: %v, %v", tst.sz, tst.virtual, tst.start, tst.disp, st, n, tst.wantSt, tst.wantN)
+		}
+	}
+}
@@ -57,6 +57,9 @@ type TableView struct {
 	ShowIndex        bool               `xml:"index" desc:"whether to show index or not (default true) -- updated from 'index' property (bool)"`
 	InactKeyNav      bool               `xml:"inact-key-nav" desc:"support key navigation when inactive (default true) -- updated from 'intact-key-nav' property (bool) -- no focus really plausible in inactive case, so it uses a low-pri capture of up / down events"`
 	VisRows          int                `desc:"number of rows visible in display"`
+	Virtual          bool               `desc:"use virtual scrolling, where widgets are only created for the DispRows rows of the slice starting at StartIdx, and reconfigured to show other rows as the display scrolls -- updated from 'virtual' property (bool), and on by default for slices longer than SliceViewVirtualSize"`
+	StartIdx         int                `desc:"in Virtual mode, index of the first row of the slice that is displayed"`
+	DispRows         int                `desc:"in Virtual mode, number of rows of widgets that are created to display the slice"`
 	SelField         string             `view:"-" json:"-" xml:"-" desc:"current selection field -- initially select value in this field"`
 	SelVal           interface{}        `view:"-" json:"-" xml:"-" desc:"current selection value -- initially select this value in SelField"`
 	SelectedIdx      int                `json:"-" xml:"-" desc:"index (row) of currently-selected item (-1 if none) -- see SelectedRows for full set of selected rows in active editing mode"`
@@ -76,7 +79,8 @@ type TableView struct {
 	NVisFields   int
 	VisFields    []reflect.StructField `view:"-" json:"-" xml:"-" desc:"the visible fields"`
 	inFocusGrab  bool
-	curRow       int // temp row variable used e.g., in Drop method
+	curRow       int     // temp row variable used e.g., in Drop method
+	rowHeight    float32 // height of a row in Virtual mode, for mouse scrolling
 }
 
 var KiT_TableView = kit.Types.AddType(&TableView{}, TableViewProps)
@@ -101,6 +105,7 @@ func (tv *TableView) SetSlice(sl interface{}, tmpSave ValueView) {
 		}
 		tv.SortIdx = -1
 		tv.SortDesc = false
+		tv.StartIdx = 0
 		slpTyp := reflect.TypeOf(sl)
 		if slpTyp.Kind() != reflect.Ptr {
 			log.Printf("TableView requires that you pass a pointer to a slice of struct elements -- type is not a Ptr: %v\n", slpTyp.String())
@@ -242,15 +247,32 @@ func (tv *TableView) SliceFrame() (*gi.Frame, int) {
 }
 
 // SliceGrid returns the SliceGrid grid frame widget, which contains all the
-// fields and values, within SliceFrame
+// fields and values, within SliceFrame -- in Virtual mode it is within a
+// layout along with its scrollbar
 func (tv *TableView) SliceGrid() *gi.Frame {
 	sf, _ := tv.SliceFrame()
 	if sf == nil {
 		return nil
 	}
+	if vf, ok := sf.Child(1).(*gi.Layout); ok {
+		return vf.Child(0).(*gi.Frame)
+	}
 	return sf.Child(1).(*gi.Frame)
 }
 
+// VirtScrollBar returns the scrollbar for the SliceGrid in Virtual mode --
+// nil if not in Virtual mode
+func (tv *TableView) VirtScrollBar() *gi.ScrollBar {
+	sf, _ := tv.SliceFrame()
+	if sf == nil {
+		return nil
+	}
+	if vf, ok := sf.Child(1).(*gi.Layout); ok {
+		return vf.Child(1).(*gi.ScrollBar)
+	}
+	return nil
+}
+
 // SliceHeader returns the Toolbar header for slice grid
 func (tv *TableView) SliceHeader() *gi.ToolBar {
 	sf, _ := tv.SliceFrame()
@@ -273,7 +295,11 @@ func (tv *TableView) ToolBar() *gi.ToolBar {
 func (tv *TableView) StdSliceFrameConfig() kit.TypeAndNameList {
 	config := kit.TypeAndNameList{}
 	config.Add(gi.KiT_ToolBar, "header")
-	config.Add(gi.KiT_Frame, "grid")
+	if tv.Virtual {
+		config.Add(gi.KiT_Layout, "grid-frame")
+	} else {
+		config.Add(gi.KiT_Frame, "grid")
+	}
 	return config
 }
 
@@ -310,10 +336,21 @@ func (tv *TableView) ConfigSliceGrid(forceUpdt bool) {
 
 	nWidgPerRow, idxOff := tv.RowWidgetNs()
 
+	nrows := sz
+	tv.Virtual = SliceIsVirtual(tv.This(), sz)
+	if tv.Virtual {
+		tv.DispRows = VirtDispRows(tv.VisRows, sz)
+		nrows = tv.DispRows
+		if tv.SelField != "" && tv.SelVal != nil {
+			tv.SelectedIdx, _ = StructSliceRowByValue(tv.Slice, tv.SelField, tv.SelVal)
+		}
+		tv.StartIdx = VirtStartIdx(tv.StartIdx, tv.SelectedIdx, tv.VirtVisRows(), sz)
+	}
+
 	// always start fresh!
 	tv.Values = make([][]ValueView, tv.NVisFields)
 	for fli := 0; fli < tv.NVisFields; fli++ {
-		tv.Values[fli] = make([]ValueView, sz)
+		tv.Values[fli] = make([]ValueView, nrows)
 	}
 
 	sg, _ := tv.SliceFrame()
@@ -325,7 +362,7 @@ func (tv *TableView) ConfigSliceGrid(forceUpdt bool) {
 	sg.SetStretchMaxHeight() // for this to work, ALL layers above need it too
 	sg.SetStretchMaxWidth()  // for this to work, ALL layers above need it too
 
-	if sz > TableViewWaitCursorSize {
+	if !tv.Virtual && sz > TableViewWaitCursorSize {
 		oswin.TheApp.Cursor(tv.Viewport.Win.OSWin).Push(cursor.Wait)
 		defer oswin.TheApp.Cursor(tv.Viewport.Win.OSWin).Pop()
 	}
@@ -344,9 +381,23 @@ func (tv *TableView) ConfigSliceGrid(forceUpdt bool) {
 	sgh.SetProp("spacing", 0)
 	// sgh.SetStretchMaxWidth()
 
+	if tv.Virtual {
+		sb := ConfigVirtFrame(sg.Child(1).(*gi.Layout), "grid")
+		sb.SliderSig.ConnectOnly(tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			if sig != int64(gi.SliderValueChanged) {
+				return
+			}
+			tvv := recv.Embed(KiT_TableView).(*TableView)
+			tvv.SetStartIdx(int(data.(float32)))
+		})
+	}
+
 	sgf := tv.SliceGrid()
 	sgf.Lay = gi.LayoutGrid
 	sgf.Stripes = gi.RowStripes
+	if tv.Virtual {
+		sgf.SetProp("overflow", "hidden") // scrolled by reconfiguring rows
+	}
 
 	// setting a pref here is key for giving it a scrollbar in larger context
 	sgf.SetMinPrefHeight(units.NewValue(10, units.Em))
@@ -413,7 +464,7 @@ func (tv *TableView) ConfigSliceGrid(forceUpdt bool) {
 	}
 
 	sgf.DeleteChildren(true)
-	sgf.Kids = make(ki.Slice, nWidgPerRow*sz)
+	sgf.Kids = make(ki.Slice, nWidgPerRow*nrows)
 
 	if tv.SortIdx >= 0 {
 		rawIdx := tv.VisFields[tv.SortIdx].Index
@@ -428,13 +479,14 @@ func (tv *TableView) ConfigSliceGrid(forceUpdt bool) {
 
 // ConfigSliceGridRows configures the SliceGrid rows for the current slice --
 // assumes .Kids is created at the right size -- only call this for a direct
-// re-render e.g., after sorting
+// re-render e.g., after sorting -- in Virtual mode, it configures the rows
+// starting at StartIdx, reusing the existing widgets
 func (tv *TableView) ConfigSliceGridRows() {
 	mv := reflect.ValueOf(tv.Slice)
 	mvnp := kit.NonPtrValue(mv)
 	sz := mvnp.Len()
 
-	if sz > TableViewWaitCursorSize {
+	if !tv.Virtual && sz > TableViewWaitCursorSize {
 		oswin.TheApp.Cursor(tv.Viewport.Win.OSWin).Push(cursor.Wait)
 		defer oswin.TheApp.Cursor(tv.Viewport.Win.OSWin).Pop()
 	}
@@ -445,8 +497,14 @@ func (tv *TableView) ConfigSliceGridRows() {
 	updt := sgf.UpdateStart()
 	defer sgf.UpdateEnd(updt)
 
-	for i := 0; i < sz; i++ {
-		ridx := i * nWidgPerRow
+	stIdx, nrows := tv.DispRange(sz)
+	if tv.Virtual {
+		SetVirtGridRows(sgf, nrows*nWidgPerRow)
+	}
+
+	for r := 0; r < nrows; r++ {
+		i := stIdx + r
+		ridx := r * nWidgPerRow
 		val := kit.OnePtrValue(mvnp.Index(i)) // deal with pointer lists
 		stru := val.Interface()
 		idxtxt := fmt.Sprintf("%05d", i)
@@ -462,6 +520,9 @@ func (tv *TableView) ConfigSliceGridRows() {
 			idxlab.Text = idxtxt
 			idxlab.SetProp("tv-index", i)
 			idxlab.Selectable = true
+			if tv.Virtual {
+				idxlab.SetSelectedState(tv.dispRowSelected(i))
+			}
 			idxlab.WidgetSig.ConnectOnly(tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
 				if sig == int64(gi.WidgetSelected) {
 					wbb := send.(gi.Node2D).AsWidget()
@@ -484,10 +545,10 @@ func (tv *TableView) ConfigSliceGridRows() {
 			valnm := fmt.Sprintf("value-%v.%v", fli, idxtxt)
 			cidx := ridx + idxOff + fli
 			var widg gi.Node2D
+			tv.Values[fli][r] = vv
 			if sgf.Kids[cidx] != nil {
 				widg = sgf.Kids[cidx].(gi.Node2D)
 			} else {
-				tv.Values[fli][i] = vv
 				widg = ki.NewOfType(vtyp).(gi.Node2D)
 				sgf.SetChild(widg, cidx, valnm)
 			}
@@ -495,7 +556,11 @@ func (tv *TableView) ConfigSliceGridRows() {
 			wb := widg.AsWidget()
 			if wb != nil {
 				wb.SetProp("tv-index", i)
-				wb.ClearSelected()
+				if tv.Virtual {
+					wb.SetSelectedState(tv.dispRowSelected(i))
+				} else {
+					wb.ClearSelected()
+				}
 				wb.WidgetSig.ConnectOnly(tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
 					if sig == int64(gi.WidgetSelected) || sig == int64(gi.WidgetFocused) {
 						wbb := send.(gi.Node2D).AsWidget()
@@ -519,10 +584,17 @@ func (tv *TableView) ConfigSliceGridRows() {
 
 				addnm := fmt.Sprintf("add-%v", idxtxt)
 				delnm := fmt.Sprintf("del-%v", idxtxt)
-				addact := gi.Action{}
-				delact := gi.Action{}
-				sgf.SetChild(&addact, ridx+1+tv.NVisFields, addnm)
-				sgf.SetChild(&delact, ridx+1+tv.NVisFields+1, delnm)
+				aidx := ridx + idxOff + tv.NVisFields
+				var addact, delact *gi.Action
+				if sgf.Kids[aidx] != nil {
+					addact = sgf.Kids[aidx].(*gi.Action)
+					delact = sgf.Kids[aidx+1].(*gi.Action)
+				} else {
+					addact = &gi.Action{}
+					delact = &gi.Action{}
+					sgf.SetChild(addact, aidx, addnm)
+					sgf.SetChild(delact, aidx+1, delnm)
+				}
 
 				addact.SetIcon("plus")
 				addact.Tooltip = "insert a new element at this index"
@@ -755,6 +827,14 @@ func (tv *TableView) Layout2D(parBBox image.Rectangle, iter int) bool {
 		sgh.SetMinPrefWidth(units.NewValue(sumwd, units.Dot))
 		sgh.Layout2D(parBBox, iter)
 	}
+	if tv.Virtual {
+		if vis, rowHt := VirtGridVisRows(sgf); vis > 0 {
+			tv.VisRows = vis
+			tv.rowHeight = rowHt
+			tv.GrowDispRows()
+			UpdateVirtScrollBar(tv.VirtScrollBar(), tv.StartIdx, tv.VirtVisRows(), tv.BuiltSize)
+		}
+	}
 	return redo
 }
 
@@ -769,17 +849,19 @@ func (tv *TableView) Render2D() {
 		return
 	}
 	if tv.PushBounds() {
-		if tv.Sty.Font.Height > 0 {
-			tv.VisRows = (tv.VpBBox.Max.Y - tv.VpBBox.Min.Y) / int(1.8*tv.Sty.Font.Height)
-		} else {
-			tv.VisRows = 10
+		if !tv.Virtual { // Virtual gets VisRows from the grid in Layout2D
+			if tv.Sty.Font.Height > 0 {
+				tv.VisRows = (tv.VpBBox.Max.Y - tv.VpBBox.Min.Y) / int(1.8*tv.Sty.Font.Height)
+			} else {
+				tv.VisRows = 10
+			}
 		}
 		tv.FrameStdRender()
 		tv.This().(gi.Node2D).ConnectEvents2D()
 		tv.RenderScrolls()
 		tv.Render2DChildren()
 		tv.PopBounds()
-		if tv.SelectedIdx > -1 {
+		if tv.SelectedIdx > -1 && !tv.Virtual { // Virtual shows it in ConfigSliceGrid
 			tv.ScrollToRow(tv.SelectedIdx)
 		}
 	} else {
@@ -815,19 +897,57 @@ func (tv *TableView) RowStruct(row int) interface{} {
 	return stru
 }
 
+// DispRange returns the index of the first row of the slice that is
+// displayed, and the number of rows displayed, for given slice size -- all
+// of them unless in Virtual mode
+func (tv *TableView) DispRange(sz int) (st, n int) {
+	if !tv.Virtual {
+		return 0, sz
+	}
+	return tv.StartIdx, ints.MaxInt(ints.MinInt(tv.DispRows, sz-tv.StartIdx), 0)
+}
+
+// RowGridIdx returns the index within the SliceGrid of the first widget for
+// given row -- false if the row is not displayed, in Virtual mode
+func (tv *TableView) RowGridIdx(row int) (int, bool) {
+	nWidgPerRow, _ := tv.RowWidgetNs()
+	if tv.Virtual {
+		row -= tv.StartIdx
+		if row < 0 || row >= tv.DispRows {
+			return -1, false
+		}
+	}
+	ridx := row * nWidgPerRow
+	if ridx < 0 || ridx >= len(tv.SliceGrid().Kids) {
+		return -1, false
+	}
+	return ridx, true
+}
+
+// dispRowSelected returns the selection state to display for given row
+func (tv *TableView) dispRowSelected(row int) bool {
+	if tv.IsInactive() {
+		return row == tv.SelectedIdx
+	}
+	return tv.RowIsSelected(row)
+}
+
 // RowFirstWidget returns the first widget for given row (could be index or
-// not) -- false if out of range
+// not) -- false if out of range, or not displayed in Virtual mode
 func (tv *TableView) RowFirstWidget(row int) (*gi.WidgetBase, bool) {
 	if tv.RowStruct(row) == nil { // range check
 		return nil, false
 	}
-	nWidgPerRow, _ := tv.RowWidgetNs()
 	sg, _ := tv.SliceFrame()
 	if sg == nil {
 		return nil, false
 	}
+	ridx, ok := tv.RowGridIdx(row)
+	if !ok {
+		return nil, false
+	}
 	sgf := tv.SliceGrid()
-	widg := sgf.Kids[row*nWidgPerRow].(gi.Node2D).AsWidget()
+	widg := sgf.Kids[ridx].(gi.Node2D).AsWidget()
 	return widg, true
 }
 
@@ -837,17 +957,20 @@ func (tv *TableView) RowFirstVisWidget(row int) (*gi.WidgetBase, bool) {
 	if tv.RowStruct(row) == nil { // range check
 		return nil, false
 	}
-	nWidgPerRow, idxOff := tv.RowWidgetNs()
+	_, idxOff := tv.RowWidgetNs()
 	sg, _ := tv.SliceFrame()
 	if sg == nil {
 		return nil, false
 	}
+	ridx, ok := tv.RowGridIdx(row)
+	if !ok {
+		return nil, false
+	}
 	sgf := tv.SliceGrid()
-	widg := sgf.Kids[row*nWidgPerRow].(gi.Node2D).AsWidget()
+	widg := sgf.Kids[ridx].(gi.Node2D).AsWidget()
 	if widg.VpBBox != image.ZR {
 		return widg, true
 	}
-	ridx := nWidgPerRow * row
 	for fli := 0; fli < tv.NVisFields; fli++ {
 		widg := sgf.Child(ridx + idxOff + fli).(gi.Node2D).AsWidget()
 		if widg.VpBBox != image.ZR {
@@ -865,12 +988,15 @@ func (tv *TableView) RowGrabFocus(row int) *gi.WidgetBase {
 		return nil
 	}
 	// fmt.Printf("grab row focus: %v\n", row)
-	nWidgPerRow, idxOff := tv.RowWidgetNs()
+	_, idxOff := tv.RowWidgetNs()
 	sg, _ := tv.SliceFrame()
 	if sg == nil {
 		return nil
 	}
-	ridx := nWidgPerRow * row
+	ridx, ok := tv.RowGridIdx(row)
+	if !ok {
+		return nil
+	}
 	sgf := tv.SliceGrid()
 	// first check if we already have focus
 	for fli := 0; fli < tv.NVisFields; fli++ {
@@ -904,7 +1030,8 @@ func (tv *TableView) RowPos(row int) image.Point {
 // RowFromPos returns the row that contains given vertical position, false if not found
 func (tv *TableView) RowFromPos(posY int) (int, bool) {
 	// todo: could optimize search to approx loc, and search up / down from there
-	for rw := 0; rw < tv.BuiltSize; rw++ {
+	st, n := tv.DispRange(tv.BuiltSize)
+	for rw := st; rw < st+n; rw++ {
 		widg, ok := tv.RowFirstWidget(rw)
 		if ok {
 			if widg.ObjBBox.Min.Y < posY && posY < widg.ObjBBox.Max.Y {
@@ -919,6 +1046,15 @@ func (tv *TableView) RowFromPos(posY int) (int, bool) {
 // -- returns true if any scrolling was performed
 func (tv *TableView) ScrollToRow(row int) bool {
 	row = ints.MinInt(row, tv.BuiltSize-1)
+	if tv.Virtual {
+		if !tv.SetStartIdx(VirtStartIdx(tv.StartIdx, row, tv.VirtVisRows(), tv.BuiltSize)) {
+			return false
+		}
+		if !tv.IsInactive() && row == tv.SelectedIdx {
+			tv.RowGrabFocus(row)
+		}
+		return true
+	}
 	sgf := tv.SliceGrid()
 	if widg, ok := tv.RowFirstWidget(row); ok {
 		return sgf.ScrollToItem(widg)
@@ -926,6 +1062,46 @@ func (tv *TableView) ScrollToRow(row int) bool {
 	return false
 }
 
+// VirtVisRows returns the number of rows visible in Virtual mode, which
+// can be no more than DispRows
+func (tv *TableView) VirtVisRows() int {
+	return ints.MinInt(tv.VisRows, tv.DispRows)
+}
+
+// GrowDispRows creates more rows of widgets in Virtual mode, if more rows
+// are visible than DispRows, which is only an initial guess before the
+// first layout -- returns true if it did
+func (tv *TableView) GrowDispRows() bool {
+	if !tv.Virtual || tv.VisRows <= tv.DispRows || tv.DispRows >= tv.BuiltSize {
+		return false
+	}
+	tv.DispRows = VirtDispRows(tv.VisRows, tv.BuiltSize)
+	tv.StartIdx = VirtStartIdx(tv.StartIdx, -1, tv.VirtVisRows(), tv.BuiltSize)
+	sgf := tv.SliceGrid()
+	sgf.SetFullReRender()
+	tv.ConfigSliceGridRows()
+	return true
+}
+
+// SetStartIdx sets the index of the first row of the slice that is
+// displayed in Virtual mode, and reconfigures the rows of widgets to show
+// the rows from there -- returns true if it changed
+func (tv *TableView) SetStartIdx(idx int) bool {
+	if !tv.Virtual {
+		return false
+	}
+	idx = VirtStartIdx(idx, -1, tv.VirtVisRows(), tv.BuiltSize)
+	if idx == tv.StartIdx {
+		return false
+	}
+	tv.StartIdx = idx
+	sgf := tv.SliceGrid()
+	sgf.SetFullReRender()
+	tv.ConfigSliceGridRows()
+	UpdateVirtScrollBar(tv.VirtScrollBar(), tv.StartIdx, tv.VirtVisRows(), tv.BuiltSize)
+	return true
+}
+
 // SelectFieldVal sets SelField and SelVal and attempts to find corresponding
 // row, setting SelectedIdx and selecting row if found -- returns true if
 // found, false otherwise
@@ -1076,6 +1252,10 @@ func (tv *TableView) SelectRowWidgets(idx int, sel bool) {
 	if idx < 0 {
 		return
 	}
+	ridx, ok := tv.RowGridIdx(idx)
+	if !ok {
+		return
+	}
 	var win *gi.Window
 	if tv.Viewport != nil {
 		win = tv.Viewport.Win
@@ -1085,8 +1265,7 @@ func (tv *TableView) SelectRowWidgets(idx int, sel bool) {
 		updt = win.UpdateStart()
 	}
 	sgf := tv.SliceGrid()
-	nWidgPerRow, idxOff := tv.RowWidgetNs()
-	ridx := idx * nWidgPerRow
+	_, idxOff := tv.RowWidgetNs()
 	for fli := 0; fli < tv.NVisFields; fli++ {
 		seldx := ridx + idxOff + fli
 		if sgf.Kids.IsValidIndex(seldx) == nil {
@@ -1538,6 +1717,13 @@ func (tv *TableView) DragNDropStart() {
 	}
 	rws := tv.SelectedRowsList(true) // descending sort
 	widg, ok := tv.RowFirstVisWidget(rws[0])
+	if !ok && tv.Virtual { // first selected row may not be displayed
+		for _, r := range rws[1:] {
+			if widg, ok = tv.RowFirstVisWidget(r); ok {
+				break
+			}
+		}
+	}
 	if ok {
 		bi := &gi.Bitmap{}
 		bi.InitName(bi, tv.UniqueName())
@@ -1841,6 +2027,15 @@ func (tv *TableView) KeyInputInactive(kt *key.ChordEvent) {
 }
 
 func (tv *TableView) TableViewEvents() {
+	if tv.Virtual {
+		tv.ConnectEvent(oswin.MouseScrollEvent, gi.LowPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+			me := d.(*mouse.ScrollEvent)
+			tvv := recv.Embed(KiT_TableView).(*TableView)
+			if tvv.SetStartIdx(tvv.StartIdx + VirtScrollRows(me.Delta.Y, tvv.rowHeight)) {
+				me.SetProcessed()
+			}
+		})
+	}
 	if tv.IsInactive() {
 		if tv.InactKeyNav {
 			tv.ConnectEvent(oswin.KeyChordEvent, gi.LowPri, func(recv, send ki.Ki, sig int64, d interface{}) {
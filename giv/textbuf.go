@@ -35,17 +35,22 @@ import (
 // TextBufOpts contains options for TextBufs -- contains everything necessary to
 // conditionalize editing of a given text file
 type TextBufOpts struct {
-	SpaceIndent  bool   `desc:"use spaces, not tabs, for indentation -- tab-size property in TextStyle has the tab size, used for either tabs or spaces"`
-	TabSize      int    `desc:"size of a tab, in chars -- also determines indent level for space indent"`
-	AutoIndent   bool   `desc:"auto-indent on newline (enter) or tab"`
-	LineNos      bool   `desc:"show line numbers at left end of editor"`
-	Completion   bool   `desc:"use the completion system to suggest options while typing"`
-	SpellCorrect bool   `desc:"use spell checking to suggest corrections while typing"`
-	EmacsUndo    bool   `desc:"use emacs-style undo, where after a non-undo command, all the current undo actions are added to the undo stack, such that a subsequent undo is actually a redo"`
-	DepthColor   bool   `desc:"colorize the background according to nesting depth"`
-	CommentLn    string `desc:"character(s) that start a single-line comment -- if empty then multi-line comment syntax will be used"`
-	CommentSt    string `desc:"character(s) that start a multi-line comment or one that requires both start and end"`
-	CommentEd    string `desc:"character(s) that end a multi-line comment or one that requires both start and end"`
+	SpaceIndent  bool          `desc:"use spaces, not tabs, for indentation -- tab-size property in TextStyle has the tab size, used for either tabs or spaces"`
+	TabSize      int           `desc:"size of a tab, in chars -- also determines indent level for space indent"`
+	AutoIndent   bool          `desc:"auto-indent on newline (enter) or tab"`
+	LineNos      bool          `desc:"show line numbers at left end of editor"`
+	Completion   bool          `desc:"use the completion system to suggest options while typing"`
+	SpellCorrect bool          `desc:"use spell checking to suggest corrections while typing"`
+	EmacsUndo    bool          `desc:"use emacs-style undo, where after a non-undo command, all the current undo actions are added to the undo stack, such that a subsequent undo is actually a redo"`
+	DepthColor   bool          `desc:"colorize the background according to nesting depth"`
+	FoldMode     TextFoldModes `desc:"how to find the regions of text that can be folded in a TextView -- none by default"`
+	LineEnds     TextLineEnds  `desc:"line endings of the file, detected when it is opened and used when it is saved -- the text itself always uses LF"`
+	Encoding     TextEncodings `desc:"character encoding of the file, detected when it is opened and used when it is saved -- the text itself is always UTF-8"`
+	BOM          bool          `desc:"whether the file starts with a byte order mark, for UTF encodings -- detected when it is opened and used when it is saved"`
+	UndoFile     bool          `desc:"save the undo history in a file next to the autosave file whenever the file is saved, and restore it when the file is opened again, if unchanged -- see UndoFilename"`
+	CommentLn    string        `desc:"character(s) that start a single-line comment -- if empty then multi-line comment syntax will be used"`
+	CommentSt    string        `desc:"character(s) that start a multi-line comment or one that requires both start and end"`
+	CommentEd    string        `desc:"character(s) that end a multi-line comment or one that requires both start and end"`
 }
 
 // MaxScopeLines	 is the maximum lines to search for a scope marker, e.g. '}'
@@ -102,36 +107,52 @@ func (tb *TextBufOpts) ConfigSupported(sup filecat.Supported) bool {
 // updates, which are then broadcast.  It also has methods for loading and
 // saving buffers to files.  Unlike GUI Widgets, its methods are generally
 // signaling, without an explicit Action suffix.  Internally, the buffer
-// represents new lines using \n = LF, but saving and loading can deal with
-// Windows/DOS CRLF format.
+// represents new lines using \n = LF, in UTF-8, but loading and saving
+// preserve the line endings, encoding and byte order mark of the file, as
+// recorded in Opts -- see DecodeFile.  Text larger than TextBufLargeSize is instead
+// held in the Pieces piece table, without any per-line storage -- see IsLarge.
 type TextBuf struct {
 	ki.Node
-	Txt          []byte           `json:"-" xml:"text" desc:"the current value of the entire text being edited -- using []byte slice for greater efficiency"`
-	Autosave     bool             `desc:"if true, auto-save file after changes (in a separate routine)"`
-	Opts         TextBufOpts      `desc:"options for how text editing / viewing works"`
-	Filename     gi.FileName      `json:"-" xml:"-" desc:"filename of file last loaded or saved"`
-	Info         FileInfo         `desc:"full info about file"`
-	PiState      pi.FileState     `desc:"Pi parsing state info for file"`
-	Hi           HiMarkup         `desc:"syntax highlighting markup parameters (language, style, etc)"`
-	NLines       int              `json:"-" xml:"-" desc:"number of lines"`
-	Lines        [][]rune         `json:"-" xml:"-" desc:"the live lines of text being edited, with latest modifications -- encoded as runes per line, which is necessary for one-to-one rune / glyph rendering correspondence -- all TextPos positions etc are in *rune* indexes, not byte indexes!"`
-	LineBytes    [][]byte         `json:"-" xml:"-" desc:"the live lines of text being edited, with latest modifications -- encoded in bytes per line translated from Lines, and used for input to markup -- essential to use Lines and not LineBytes when dealing with TextPos positions, which are in runes"`
-	Tags         []lex.Line       `json:"extra custom tagged regions for each line"`
-	HiTags       []lex.Line       `json:"syntax highlighting tags -- auto-generated"`
-	Markup       [][]byte         `json:"-" xml:"-" desc:"marked-up version of the edit text lines, after being run through the syntax highlighting process etc -- this is what is actually rendered"`
-	ByteOffs     []int            `json:"-" xml:"-" desc:"offsets for start of each line in Txt []byte slice -- this is NOT updated with edits -- call SetByteOffs to set it when needed -- used for re-generating the Txt in LinesToBytes, and set on initial open in BytesToLines"`
-	TotalBytes   int              `json:"-" xml:"-" desc:"total bytes in document -- see ByteOffs for when it is updated"`
-	LinesMu      sync.RWMutex     `json:"-" xml:"-" desc:"mutex for updating lines"`
-	MarkupMu     sync.RWMutex     `json:"-" xml:"-" desc:"mutex for updating markup"`
-	TextBufSig   ki.Signal        `json:"-" xml:"-" view:"-" desc:"signal for buffer -- see TextBufSignals for the types"`
-	Views        []*TextView      `json:"-" xml:"-" desc:"the TextViews that are currently viewing this buffer"`
-	Undos        []*TextBufEdit   `json:"-" xml:"-" desc:"undo stack of edits"`
-	UndoUndos    []*TextBufEdit   `json:"-" xml:"-" desc:"undo stack of *undo* edits -- added to "`
-	UndoPos      int              `json:"-" xml:"-" desc:"undo position"`
-	PosHistory   []TextPos        `json:"-" xml:"-" desc:"history of cursor positions -- can move back through them"`
-	Complete     *gi.Complete     `json:"-" xml:"-" desc:"functions and data for text completion"`
-	SpellCorrect *gi.SpellCorrect `json:"-" xml:"-" desc:"functions and data for spelling correction"`
-	CurView      *TextView        `json:"-" xml:"-" desc:"current textview -- e.g., the one that initiated Complete or Correct process -- update cursor position in this view -- is reset to nil after usage always"`
+	Txt          []byte               `json:"-" xml:"text" desc:"the current value of the entire text being edited -- using []byte slice for greater efficiency"`
+	Autosave     bool                 `desc:"if true, auto-save file after changes (in a separate routine)"`
+	Opts         TextBufOpts          `desc:"options for how text editing / viewing works"`
+	Filename     gi.FileName          `json:"-" xml:"-" desc:"filename of file last loaded or saved"`
+	Info         FileInfo             `desc:"full info about file"`
+	PiState      pi.FileState         `desc:"Pi parsing state info for file"`
+	Hi           HiMarkup             `desc:"syntax highlighting markup parameters (language, style, etc)"`
+	NLines       int                  `json:"-" xml:"-" desc:"number of lines"`
+	Lines        [][]rune             `json:"-" xml:"-" desc:"the live lines of text being edited, with latest modifications -- encoded as runes per line, which is necessary for one-to-one rune / glyph rendering correspondence -- all TextPos positions etc are in *rune* indexes, not byte indexes!"`
+	LineBytes    [][]byte             `json:"-" xml:"-" desc:"the live lines of text being edited, with latest modifications -- encoded in bytes per line translated from Lines, and used for input to markup -- essential to use Lines and not LineBytes when dealing with TextPos positions, which are in runes"`
+	Tags         []lex.Line           `json:"extra custom tagged regions for each line"`
+	HiTags       []lex.Line           `json:"syntax highlighting tags -- auto-generated"`
+	Markup       [][]byte             `json:"-" xml:"-" desc:"marked-up version of the edit text lines, after being run through the syntax highlighting process etc -- this is what is actually rendered"`
+	ByteOffs     []int                `json:"-" xml:"-" desc:"offsets for start of each line in Txt []byte slice -- this is NOT updated with edits -- call SetByteOffs to set it when needed -- used for re-generating the Txt in LinesToBytes, and set on initial open in BytesToLines"`
+	TotalBytes   int                  `json:"-" xml:"-" desc:"total bytes in document -- see ByteOffs for when it is updated"`
+	LinesMu      sync.RWMutex         `json:"-" xml:"-" desc:"mutex for updating lines"`
+	MarkupMu     sync.RWMutex         `json:"-" xml:"-" desc:"mutex for updating markup"`
+	TextBufSig   ki.Signal            `json:"-" xml:"-" view:"-" desc:"signal for buffer -- see TextBufSignals for the types"`
+	Views        []*TextView          `json:"-" xml:"-" desc:"the TextViews that are currently viewing this buffer"`
+	Undos        []*TextBufEdit       `json:"-" xml:"-" desc:"undo stack of edits"`
+	UndoUndos    []*TextBufEdit       `json:"-" xml:"-" desc:"undo stack of *undo* edits -- added to "`
+	UndoPos      int                  `json:"-" xml:"-" desc:"undo position"`
+	UndoBranches []*TextBufUndoBranch `json:"-" xml:"-" desc:"branches of the undo history: edits that were undone and then replaced on the undo stack by new ones -- see SwitchUndoBranch"`
+	PosHistory   []TextPos            `json:"-" xml:"-" desc:"history of cursor positions -- can move back through them"`
+	Complete     *gi.Complete         `json:"-" xml:"-" desc:"functions and data for text completion"`
+	SpellCorrect *gi.SpellCorrect     `json:"-" xml:"-" desc:"functions and data for spelling correction"`
+	CurView      *TextView            `json:"-" xml:"-" desc:"current textview -- e.g., the one that initiated Complete or Correct process -- update cursor position in this view -- is reset to nil after usage always"`
+	Pieces       *TextPieces          `json:"-" xml:"-" desc:"for large buffers, the piece table holding the text, instead of Lines, LineBytes, Markup, Tags and HiTags, which are all nil -- see IsLarge"`
+	lineCache    map[int][]rune
+	markCache    map[int][]byte
+	cacheMu      sync.Mutex
+	unmap        func() error
+	undoGroup    int          // current undo group, set between BeginGroup and EndGroup
+	undoGroupN   int          // number of undo groups so far, for unique group numbers
+	undoDepth    int          // nesting depth of BeginGroup calls
+	undoBurst    int          // undo group of the current typing burst
+	undoBreak    bool         // next edit starts a new typing burst
+	savePos      int          // undo position of the save point
+	saveEdit     *TextBufEdit // last edit before the save point, nil if at the start
+	saveHash     string       // hash of the text at the save point, for UndoFile
 }
 
 var KiT_TextBuf = kit.Types.AddType(&TextBuf{}, TextBufProps)
@@ -145,6 +166,19 @@ var TextBufProps = ki.Props{
 				}},
 			},
 		}},
+		{"SetLineEnds", ki.Props{
+			"desc": "set the line endings used when saving the file",
+			"Args": ki.PropSlice{
+				{"Line Ends", ki.Props{}},
+			},
+		}},
+		{"SetEncoding", ki.Props{
+			"desc": "set the character encoding used when saving the file, and whether it starts with a byte order mark",
+			"Args": ki.PropSlice{
+				{"Encoding", ki.Props{}},
+				{"Byte Order Mark", ki.Props{}},
+			},
+		}},
 	},
 }
 
@@ -195,6 +229,10 @@ const (
 	// TextBufFileModOk have already asked about fact that file has changed since being
 	// opened, user is ok
 	TextBufFileModOk
+
+	// TextBufReadOnly indicates that the buffer cannot be edited, because it
+	// was opened memory-mapped with OpenMapped
+	TextBufReadOnly
 )
 
 // IsChanged indicates if the text has been changed (edited) relative to
@@ -265,7 +303,7 @@ func (tb *TextBuf) Line(ln int) []rune {
 	if ln >= tb.NLines || ln < 0 {
 		return nil
 	}
-	return tb.Lines[ln]
+	return tb.lineRunes(ln)
 }
 
 // LineLen is the concurrent-safe accessor to length of specific Line of Lines runes
@@ -275,7 +313,7 @@ func (tb *TextBuf) LineLen(ln int) int {
 	if ln >= tb.NLines || ln < 0 {
 		return 0
 	}
-	return len(tb.Lines[ln])
+	return len(tb.lineRunes(ln))
 }
 
 // BytesLine is the concurrent-safe accessor to specific Line of LineBytes
@@ -285,6 +323,9 @@ func (tb *TextBuf) BytesLine(ln int) []byte {
 	if ln >= tb.NLines || ln < 0 {
 		return nil
 	}
+	if tb.Pieces != nil {
+		return tb.Pieces.LineBytes(ln)
+	}
 	return tb.LineBytes[ln]
 }
 
@@ -311,15 +352,14 @@ func (tb *TextBuf) Refresh() {
 	tb.TextBufSig.Emit(tb.This(), int64(TextBufNew), tb.Txt)
 }
 
-// todo: use https://github.com/andybalholm/crlf to deal with cr/lf etc --
-// internally just use lf = \n
-
 // New initializes a new buffer with n blank lines
 func (tb *TextBuf) New(nlines int) {
 	tb.Defaults()
+	tb.ClearUndo()
 	nlines = ints.MaxInt(nlines, 1)
 	tb.LinesMu.Lock()
 	tb.MarkupMu.Lock()
+	tb.clearLarge()
 	tb.Lines = make([][]rune, nlines)
 	tb.LineBytes = make([][]byte, nlines)
 	tb.Tags = make([]lex.Line, nlines)
@@ -364,6 +404,9 @@ func (tb *TextBuf) Stat() error {
 // returns true if supported
 func (tb *TextBuf) ConfigSupported() bool {
 	if tb.Info.Sup != filecat.NoSupport {
+		if tb.Pieces != nil { // not supported for large buffers
+			return tb.Opts.ConfigSupported(tb.Info.Sup)
+		}
 		if tb.SpellCorrect == nil {
 			tb.SetSpellCorrect(tb, SpellCorrectEdit)
 		}
@@ -431,17 +474,34 @@ func (tb *TextBuf) Open(filename gi.FileName) error {
 }
 
 // OpenFile just loads a file into the buffer -- doesn't do any markup or
-// notification -- for temp bufs
+// notification -- for temp bufs.  Files larger than TextBufMapSize are opened
+// read-only using OpenMapped.
 func (tb *TextBuf) OpenFile(filename gi.FileName) error {
+	if lg, err := tb.openLargeFile(filename); lg {
+		return err
+	}
 	fp, err := os.Open(string(filename))
 	if err != nil {
 		return err
 	}
 	tb.Txt, err = ioutil.ReadAll(fp)
 	fp.Close()
+	if err != nil {
+		return err
+	}
+	tb.Txt, err = tb.DecodeFile(tb.Txt)
+	if err != nil {
+		return err
+	}
 	tb.Filename = filename
 	tb.Stat()
 	tb.BytesToLines()
+	if tb.Opts.UndoFile {
+		tb.SetSavePoint()
+		if err := tb.OpenUndoFile(); err != nil {
+			log.Printf("giv.TextBuf: Could not restore undo history from: %v, error: %v\n", tb.UndoFilename(), err)
+		}
+	}
 	return nil
 }
 
@@ -468,6 +528,9 @@ func (tb *TextBuf) Revert() bool {
 			return false
 		}
 		tb.Stat() // "own" the new file..
+		tb.Opts.LineEnds = ob.Opts.LineEnds
+		tb.Opts.Encoding = ob.Opts.Encoding
+		tb.Opts.BOM = ob.Opts.BOM
 		if ob.NLines < 1000 {
 			diffs := tb.DiffBufs(ob)
 			if len(diffs) < 10 {
@@ -481,6 +544,7 @@ func (tb *TextBuf) Revert() bool {
 	}
 	tb.ClearChanged()
 	tb.AutoSaveDelete()
+	tb.SetSavePoint()
 	tb.ReMarkup()
 	return true
 }
@@ -524,7 +588,16 @@ func (tb *TextBuf) SaveAs(filename gi.FileName) {
 
 // SaveFile writes current buffer to file, with no prompting, etc
 func (tb *TextBuf) SaveFile(filename gi.FileName) error {
-	err := ioutil.WriteFile(string(filename), tb.Txt, 0644)
+	var err error
+	if tb.Pieces != nil && tb.Opts.IsPlainFile() {
+		err = tb.saveLarge(filename)
+	} else {
+		var b []byte
+		b, err = tb.EncodeFile(tb.Txt)
+		if err == nil {
+			err = ioutil.WriteFile(string(filename), b, 0644)
+		}
+	}
 	if err != nil {
 		gi.PromptDialog(nil, gi.DlgOpts{Title: "Could not Save to File", Prompt: err.Error()}, true, false, nil, nil)
 		log.Println(err)
@@ -532,6 +605,10 @@ func (tb *TextBuf) SaveFile(filename gi.FileName) error {
 		tb.Filename = filename
 		tb.SetName(string(filename)) // todo: modify in any way?
 		tb.Stat()
+		tb.SetSavePoint()
+		if tb.Opts.UndoFile {
+			tb.SaveUndoFile()
+		}
 	}
 	return err
 }
@@ -652,8 +729,10 @@ func (tb *TextBuf) AutoSave() error {
 	}
 	tb.SetFlag(int(TextBufAutoSaving))
 	asfn := tb.AutoSaveFilename()
-	b := tb.LinesToBytesCopy()
-	err := ioutil.WriteFile(asfn, b, 0644)
+	b, err := tb.EncodeFile(tb.LinesToBytesCopy())
+	if err == nil {
+		err = ioutil.WriteFile(asfn, b, 0644)
+	}
 	if err != nil {
 		log.Printf("giv.TextBuf: Could not AutoSave file: %v, error: %v\n", asfn, err)
 	}
@@ -688,7 +767,7 @@ func (tb *TextBuf) EndPos() TextPos {
 	if tb.NLines == 0 {
 		return TextPosZero
 	}
-	ed := TextPos{tb.NLines - 1, len(tb.Lines[tb.NLines-1])}
+	ed := TextPos{tb.NLines - 1, len(tb.lineRunes(tb.NLines - 1))}
 	return ed
 }
 
@@ -743,7 +822,7 @@ func (tb *TextBuf) AppendTextMarkup(text []byte, markup []byte, saveUndo, signal
 		log.Printf("TextBuf AppendTextMarkup: markup text less than appended text: is: %v, should be: %v\n", len(msplt), sz)
 		el = ints.MinInt(st+len(msplt)-1, el)
 	}
-	for ln := st; ln <= el; ln++ {
+	for ln := st; ln <= el && tb.Pieces == nil; ln++ {
 		tb.Markup[ln] = msplt[ln-st]
 	}
 	if signal {
@@ -774,7 +853,9 @@ func (tb *TextBuf) AppendTextLineMarkup(text []byte, markup []byte, saveUndo, si
 		efft = tcpy
 	}
 	tbe := tb.InsertText(ed, efft, saveUndo, false)
-	tb.Markup[tbe.Reg.Start.Ln] = markup
+	if tb.Pieces == nil {
+		tb.Markup[tbe.Reg.Start.Ln] = markup
+	}
 	if signal {
 		tb.TextBufSig.Emit(tb.This(), int64(TextBufInsert), tbe)
 	}
@@ -867,6 +948,10 @@ func (tb *TextBuf) AddFileNode(fn *FileNode) {
 
 // SetByteOffs sets the byte offsets for each line into the raw text
 func (tb *TextBuf) SetByteOffs() {
+	if tb.Pieces != nil { // not kept for large buffers
+		tb.TotalBytes = tb.Pieces.Len()
+		return
+	}
 	bo := 0
 	for ln, txt := range tb.LineBytes {
 		tb.ByteOffs[ln] = bo
@@ -877,6 +962,12 @@ func (tb *TextBuf) SetByteOffs() {
 
 // LinesToBytes converts current Lines back to the Txt slice of bytes.
 func (tb *TextBuf) LinesToBytes() {
+	if tb.Pieces != nil {
+		tb.LinesMu.RLock()
+		tb.Txt = tb.Pieces.Bytes()
+		tb.LinesMu.RUnlock()
+		return
+	}
 	if tb.NLines == 0 {
 		if tb.Txt != nil {
 			tb.Txt = tb.Txt[:0]
@@ -911,6 +1002,9 @@ func (tb *TextBuf) LinesToBytesCopy() []byte {
 	tb.LinesMu.RLock()
 	defer tb.LinesMu.RUnlock()
 
+	if tb.Pieces != nil {
+		return tb.Pieces.Range(0, tb.Pieces.Len())
+	}
 	txt := bytes.Join(tb.LineBytes, []byte("\n"))
 	txt = append(txt, '\n')
 	return txt
@@ -923,6 +1017,10 @@ func (tb *TextBuf) BytesToLines() {
 		tb.New(1)
 		return
 	}
+	if len(tb.Txt) > TextBufLargeSize {
+		tb.setLarge(tb.Txt, nil)
+		return
+	}
 	tb.LinesMu.Lock()
 	lns := bytes.Split(tb.Txt, []byte("\n"))
 	tb.NLines = len(lns)
@@ -1000,7 +1098,17 @@ func (tb *TextBuf) Search(find []byte, ignoreCase bool) (int, []FileSearchMatch)
 	defer tb.LinesMu.RUnlock()
 	cnt := 0
 	var matches []FileSearchMatch
-	for ln, rn := range tb.Lines {
+	for ln := 0; ln < tb.NLines; ln++ {
+		var rn []rune
+		if tb.Pieces != nil { // decode without caching, skipping lines without a match
+			lb := tb.Pieces.LineBytes(ln)
+			if !ignoreCase && !bytes.Contains(lb, find) {
+				continue
+			}
+			rn = bytes.Runes(lb)
+		} else {
+			rn = tb.Lines[ln]
+		}
 		sz := len(rn)
 		ci := 0
 		for ci < sz {
@@ -1157,6 +1265,13 @@ type TextBufEdit struct {
 	Reg    TextRegion `desc:"region for the edit (start is same for previous and current, end is in original pre-delete text for a delete, and in new lines data for an insert.  Also contains the Time stamp for this edit."`
 	Delete bool       `desc:"action is either a deletion or an insertion"`
 	Text   [][]rune   `desc:"text to be inserted"`
+	Group  int        `desc:"group of edits that are undone and redone together, as one action -- 0 if not in a group -- see BeginGroup"`
+}
+
+// IsTyping returns true if the edit inserts or deletes just one character,
+// as in typing, which is undone together with the rest of its typing burst
+func (te *TextBufEdit) IsTyping() bool {
+	return len(te.Text) == 1 && len(te.Text[0]) == 1
 }
 
 // ToBytes returns the Text of this edit record to a byte string, with
@@ -1397,8 +1512,8 @@ func (tb *TextBuf) ValidPos(pos TextPos) TextPos {
 	if pos.Ln < 0 {
 		pos.Ln = 0
 	}
-	pos.Ln = ints.MinInt(pos.Ln, len(tb.Lines)-1)
-	llen := len(tb.Lines[pos.Ln])
+	pos.Ln = ints.MinInt(pos.Ln, tb.NLines-1)
+	llen := len(tb.lineRunes(pos.Ln))
 	pos.Ch = ints.MinInt(pos.Ch, llen)
 	if pos.Ch < 0 {
 		pos.Ch = 0
@@ -1410,6 +1525,9 @@ func (tb *TextBuf) ValidPos(pos TextPos) TextPos {
 // views after text lines have been updated.  Sets the timestamp on resulting TextBufEdit
 // to now
 func (tb *TextBuf) DeleteText(st, ed TextPos, saveUndo, signal bool) *TextBufEdit {
+	if tb.IsReadOnly() {
+		return nil
+	}
 	st = tb.ValidPos(st)
 	ed = tb.ValidPos(ed)
 	if st == ed {
@@ -1422,6 +1540,17 @@ func (tb *TextBuf) DeleteText(st, ed TextPos, saveUndo, signal bool) *TextBufEdi
 	tb.FileModCheck() // note: could bail if modified but not clear that is better?
 	tbe := tb.Region(st, ed)
 	tb.SetChanged()
+	if tb.Pieces != nil { // note: no autosave of large buffers on every edit
+		tbe.Delete = true
+		tb.deleteLarge(st, ed)
+		if saveUndo {
+			tb.SaveUndo(tbe)
+		}
+		if signal {
+			tb.TextBufSig.Emit(tb.This(), int64(TextBufDelete), tbe)
+		}
+		return tbe
+	}
 	tb.LinesMu.Lock()
 	tbe.Delete = true
 	if ed.Ln == st.Ln {
@@ -1466,14 +1595,26 @@ func (tb *TextBuf) DeleteText(st, ed TextPos, saveUndo, signal bool) *TextBufEdi
 // Insert inserts new text at given starting position, signaling views after
 // text has been inserted.  Sets the timestamp on resulting TextBufEdit to now
 func (tb *TextBuf) InsertText(st TextPos, text []byte, saveUndo, signal bool) *TextBufEdit {
-	if len(text) == 0 {
+	if len(text) == 0 || tb.IsReadOnly() {
 		return nil
 	}
-	if len(tb.Lines) == 0 {
+	if tb.NLines == 0 {
 		tb.New(1)
 	}
 	st = tb.ValidPos(st)
 	tb.FileModCheck()
+	if tb.Pieces != nil { // note: no autosave of large buffers on every edit
+		tb.SetChanged()
+		ed := tb.insertLarge(st, text)
+		tbe := tb.Region(st, ed)
+		if saveUndo {
+			tb.SaveUndo(tbe)
+		}
+		if signal {
+			tb.TextBufSig.Emit(tb.This(), int64(TextBufInsert), tbe)
+		}
+		return tbe
+	}
 	tb.LinesMu.Lock()
 	tb.SetChanged()
 	lns := bytes.Split(text, []byte("\n"))
@@ -1556,32 +1697,32 @@ func (tb *TextBuf) Region(st, ed TextPos) *TextBufEdit {
 		sz := ed.Ch - st.Ch
 		tbe.Text = make([][]rune, 1)
 		tbe.Text[0] = make([]rune, sz)
-		copy(tbe.Text[0][:sz], tb.Lines[st.Ln][st.Ch:ed.Ch])
+		copy(tbe.Text[0][:sz], tb.lineRunes(st.Ln)[st.Ch:ed.Ch])
 	} else {
 		// first get chars on start and end
 		nlns := (ed.Ln - st.Ln) + 1
 		tbe.Text = make([][]rune, nlns)
 		stln := st.Ln
 		if st.Ch > 0 {
-			ec := len(tb.Lines[st.Ln])
+			ec := len(tb.lineRunes(st.Ln))
 			sz := ec - st.Ch
 			if sz > 0 {
 				tbe.Text[0] = make([]rune, sz)
-				copy(tbe.Text[0][0:sz], tb.Lines[st.Ln][st.Ch:])
+				copy(tbe.Text[0][0:sz], tb.lineRunes(st.Ln)[st.Ch:])
 			}
 			stln++
 		}
 		edln := ed.Ln
-		if ed.Ch < len(tb.Lines[ed.Ln]) {
+		if ed.Ch < len(tb.lineRunes(ed.Ln)) {
 			tbe.Text[ed.Ln-st.Ln] = make([]rune, ed.Ch)
-			copy(tbe.Text[ed.Ln-st.Ln], tb.Lines[ed.Ln][:ed.Ch])
+			copy(tbe.Text[ed.Ln-st.Ln], tb.lineRunes(ed.Ln)[:ed.Ch])
 			edln--
 		}
 		for ln := stln; ln <= edln; ln++ {
 			ti := ln - st.Ln
-			sz := len(tb.Lines[ln])
+			sz := len(tb.lineRunes(ln))
 			tbe.Text[ti] = make([]rune, sz)
-			copy(tbe.Text[ti], tb.Lines[ln])
+			copy(tbe.Text[ti], tb.lineRunes(ln))
 		}
 	}
 	return tbe
@@ -1610,6 +1751,10 @@ func (tb *TextBuf) SavePosHistory(pos TextPos) bool {
 // LinesInserted inserts new lines in Markup corresponding to lines
 // inserted in Lines text.  Locks and unlocks the Markup mutex
 func (tb *TextBuf) LinesInserted(tbe *TextBufEdit) {
+	if tb.Pieces != nil {
+		tb.clearLargeCache()
+		return
+	}
 	stln := tbe.Reg.Start.Ln + 1
 	nsz := (tbe.Reg.End.Ln - tbe.Reg.Start.Ln)
 
@@ -1669,6 +1814,10 @@ func (tb *TextBuf) LinesInserted(tbe *TextBufEdit) {
 // LinesDeleted deletes lines in Markup corresponding to lines
 // deleted in Lines text.  Locks and unlocks the Markup mutex.
 func (tb *TextBuf) LinesDeleted(tbe *TextBufEdit) {
+	if tb.Pieces != nil {
+		tb.clearLargeCache()
+		return
+	}
 	tb.LinesMu.Lock()
 	tb.MarkupMu.Lock()
 
@@ -1695,6 +1844,10 @@ func (tb *TextBuf) LinesDeleted(tbe *TextBufEdit) {
 // LinesEdited re-marks-up lines in edit (typically only 1).  Locks and
 // unlocks the Markup mutex.
 func (tb *TextBuf) LinesEdited(tbe *TextBufEdit) {
+	if tb.Pieces != nil {
+		tb.clearLargeCache()
+		return
+	}
 	tb.LinesMu.Lock()
 	tb.MarkupMu.Lock()
 
@@ -1716,7 +1869,7 @@ func (tb *TextBuf) IsMarkingUp() bool {
 
 // ReMarkup runs re-markup on text in background
 func (tb *TextBuf) ReMarkup() {
-	if !tb.Hi.HasHi() || tb.NLines == 0 {
+	if !tb.Hi.HasHi() || tb.NLines == 0 || tb.Pieces != nil {
 		return
 	}
 	if tb.IsMarkingUp() {
@@ -1747,9 +1900,10 @@ func (tb *TextBuf) AdjustedTags(ln int) lex.Line {
 
 // MarkupAllLines does syntax highlighting markup for all lines in buffer,
 // calling MarkupMu mutex when setting the marked-up lines with the result --
-// designed to be called in a separate goroutine
+// designed to be called in a separate goroutine.  Large buffers are only
+// marked up line by line as they are viewed -- see LineMarkup.
 func (tb *TextBuf) MarkupAllLines() {
-	if !tb.Hi.HasHi() || tb.NLines == 0 {
+	if !tb.Hi.HasHi() || tb.NLines == 0 || tb.Pieces != nil {
 		return
 	}
 	if tb.IsMarkingUp() {
@@ -1789,6 +1943,9 @@ func (tb *TextBuf) MarkupAllLines() {
 // running new tagging -- for special case where tagging is under external
 // control
 func (tb *TextBuf) MarkupFromTags() {
+	if tb.Pieces != nil {
+		return
+	}
 	tb.MarkupMu.Lock()
 	// getting the lock means we are in control of the flag
 	tb.SetFlag(int(TextBufMarkingUp))
@@ -1812,6 +1969,10 @@ func (tb *TextBuf) MarkupLines(st, ed int) bool {
 	if ed >= tb.NLines {
 		ed = tb.NLines - 1
 	}
+	if tb.Pieces != nil {
+		tb.markupLarge(st, ed)
+		return true
+	}
 	allgood := true
 	for ln := st; ln <= ed; ln++ {
 		ltxt := tb.LineBytes[ln]
@@ -1837,43 +1998,150 @@ func (tb *TextBuf) MarkupLinesLock(st, ed int) bool {
 /////////////////////////////////////////////////////////////////////////////
 //   Undo
 
-// SaveUndo saves given edit to undo stack
+// UndoBurstTime is the maximum time between typed characters for them to
+// be undone together, as one typing burst
+var UndoBurstTime = 1 * time.Second
+
+// SaveUndo saves given edit to undo stack, as part of the current undo
+// group if within BeginGroup / EndGroup, or of the current typing burst --
+// any edits that were undone are kept as a branch, in UndoBranches
 func (tb *TextBuf) SaveUndo(tbe *TextBufEdit) {
 	if tb.UndoPos < len(tb.Undos) {
 		// fmt.Printf("undo resetting to pos: %v len was: %v\n", tb.UndoPos, len(tb.Undos))
-		tb.Undos = tb.Undos[:tb.UndoPos]
+		tb.undoBranch()
 	}
 	// fmt.Printf("save undo pos: %v: %v\n", tb.UndoPos, string(tbe.ToBytes()))
+	switch {
+	case tb.undoDepth > 0:
+		tbe.Group = tb.undoGroup
+		tb.undoBurst = 0
+	case tb.inUndoBurst(tbe):
+		prv := tb.Undos[len(tb.Undos)-1]
+		if prv.Group == 0 {
+			prv.Group = tb.newUndoGroup()
+		}
+		tbe.Group = prv.Group
+		tb.undoBurst = tbe.Group
+	default:
+		tbe.Group = 0
+		tb.undoBurst = 0
+	}
+	tb.undoBreak = false
 	tb.Undos = append(tb.Undos, tbe)
 	tb.UndoPos = len(tb.Undos)
 }
 
-// Undo undoes next item on the undo stack, and returns that record -- nil if no more
+// UndoBoundary ends the current typing burst, so that the next edit is
+// undone separately -- e.g., when the cursor is moved
+func (tb *TextBuf) UndoBoundary() {
+	tb.undoBreak = true
+}
+
+// inUndoBurst returns true if given edit continues the typing burst of the
+// last edit on the undo stack: both insert or delete just one character, at
+// adjacent positions, within UndoBurstTime of each other
+func (tb *TextBuf) inUndoBurst(tbe *TextBufEdit) bool {
+	if tb.undoBreak || len(tb.Undos) == 0 || !tbe.IsTyping() {
+		return false
+	}
+	prv := tb.Undos[len(tb.Undos)-1]
+	if prv == nil || !prv.IsTyping() || prv.Delete != tbe.Delete {
+		return false
+	}
+	if prv.Group != 0 && prv.Group != tb.undoBurst { // in a BeginGroup group
+		return false
+	}
+	if tbe.Reg.Time.Time().Sub(prv.Reg.Time.Time()) > UndoBurstTime {
+		return false
+	}
+	if tbe.Delete { // backspace or delete
+		return tbe.Reg.End == prv.Reg.Start || tbe.Reg.Start == prv.Reg.Start
+	}
+	return tbe.Reg.Start == prv.Reg.End
+}
+
+// BeginGroup starts a group of edits that are undone and redone together, as
+// one action, until the matching EndGroup -- e.g., the same edit made at
+// multiple cursors.  Groups can be nested, in which case all the edits are
+// in the outermost group.
+func (tb *TextBuf) BeginGroup() {
+	if tb.undoDepth == 0 {
+		tb.undoGroup = tb.newUndoGroup()
+	}
+	tb.undoDepth++
+}
+
+// EndGroup ends a group of edits started by BeginGroup
+func (tb *TextBuf) EndGroup() {
+	if tb.undoDepth == 0 {
+		return
+	}
+	tb.undoDepth--
+	if tb.undoDepth == 0 {
+		tb.undoGroup = 0
+	}
+}
+
+// newUndoGroup returns a new unique undo group number
+func (tb *TextBuf) newUndoGroup() int {
+	tb.undoGroupN++
+	return tb.undoGroupN
+}
+
+// InUndoGroup returns true if the undo edits at given positions on the undo
+// stack are both in the same group
+func (tb *TextBuf) InUndoGroup(pa, pb int) bool {
+	if pa < 0 || pb < 0 || pa >= len(tb.Undos) || pb >= len(tb.Undos) {
+		return false
+	}
+	ta := tb.Undos[pa]
+	tbb := tb.Undos[pb]
+	return ta != nil && tbb != nil && ta.Group != 0 && ta.Group == tbb.Group
+}
+
+// Undo undoes next item on the undo stack, and returns that record -- nil if
+// no more -- all the items in the same group are undone together, and the
+// first of them is returned
 func (tb *TextBuf) Undo() *TextBufEdit {
 	if tb.UndoPos == 0 {
-		tb.ClearChanged()
-		tb.AutoSaveDelete()
 		return nil
 	}
-	tb.UndoPos--
-	tbe := tb.Undos[tb.UndoPos]
-	if tbe == nil {
-		return nil
+	ugp := 0
+	if tb.Opts.EmacsUndo {
+		ugp = tb.newUndoGroup() // undos of a group are redone together too
 	}
+	var tbe *TextBufEdit
+	for {
+		tb.UndoPos--
+		tbe = tb.Undos[tb.UndoPos]
+		if tbe == nil {
+			return nil
+		}
+		tb.undoEdit(tbe, ugp)
+		if !tb.InUndoGroup(tb.UndoPos, tb.UndoPos-1) {
+			break
+		}
+	}
+	tb.undoBreak = true
+	tb.undoChanged()
+	return tbe
+}
+
+// undoEdit undoes given edit, saving the edit that does so to UndoUndos in
+// given group, in EmacsUndo mode
+func (tb *TextBuf) undoEdit(tbe *TextBufEdit, ugp int) {
+	var utbe *TextBufEdit
 	if tbe.Delete {
 		// fmt.Printf("undo pos: %v undoing delete at: %v text: %v\n", tb.UndoPos, tbe.Reg, string(tbe.ToBytes()))
-		tbe := tb.InsertText(tbe.Reg.Start, tbe.ToBytes(), false, true) // don't save to reg und
-		if tb.Opts.EmacsUndo {
-			tb.UndoUndos = append(tb.UndoUndos, tbe)
-		}
+		utbe = tb.InsertText(tbe.Reg.Start, tbe.ToBytes(), false, true) // don't save to reg und
 	} else {
 		// fmt.Printf("undo pos: %v undoing insert at: %v text: %v\n", tb.UndoPos, tbe.Reg, string(tbe.ToBytes()))
-		tbe := tb.DeleteText(tbe.Reg.Start, tbe.Reg.End, false, true)
-		if tb.Opts.EmacsUndo {
-			tb.UndoUndos = append(tb.UndoUndos, tbe)
-		}
+		utbe = tb.DeleteText(tbe.Reg.Start, tbe.Reg.End, false, true)
+	}
+	if tb.Opts.EmacsUndo && utbe != nil {
+		utbe.Group = ugp
+		tb.UndoUndos = append(tb.UndoUndos, utbe)
 	}
-	return tbe
 }
 
 // EmacsUndoSave if EmacsUndo mode is active, saves the UndoUndos to the regular Undo stack
@@ -1888,20 +2156,31 @@ func (tb *TextBuf) EmacsUndoSave() {
 	tb.UndoPos = len(tb.Undos)
 	// fmt.Printf("emacs undo save new pos: %v\n", tb.UndoPos)
 	tb.UndoUndos = nil
+	tb.undoBreak = true
 }
 
-// Redo redoes next item on the undo stack, and returns that record, nil if no more
+// Redo redoes next item on the undo stack, and returns that record, nil if
+// no more -- all the items in the same group are redone together, and the
+// last of them is returned
 func (tb *TextBuf) Redo() *TextBufEdit {
 	if tb.UndoPos >= len(tb.Undos) {
 		return nil
 	}
-	tbe := tb.Undos[tb.UndoPos]
-	if tbe.Delete {
-		tb.DeleteText(tbe.Reg.Start, tbe.Reg.End, false, true)
-	} else {
-		tb.InsertText(tbe.Reg.Start, tbe.ToBytes(), false, true)
+	var tbe *TextBufEdit
+	for {
+		tbe = tb.Undos[tb.UndoPos]
+		if tbe.Delete {
+			tb.DeleteText(tbe.Reg.Start, tbe.Reg.End, false, true)
+		} else {
+			tb.InsertText(tbe.Reg.Start, tbe.ToBytes(), false, true)
+		}
+		tb.UndoPos++
+		if !tb.InUndoGroup(tb.UndoPos-1, tb.UndoPos) {
+			break
+		}
 	}
-	tb.UndoPos++
+	tb.undoBreak = true
+	tb.undoChanged()
 	return tbe
 }
 
@@ -1938,7 +2217,7 @@ func (tb *TextBuf) AdjustReg(reg TextRegion) TextRegion {
 
 // AddTag adds a new custom tag for given line, at given position
 func (tb *TextBuf) AddTag(ln, st, ed int, tag token.Tokens) {
-	if !tb.IsValidLine(ln) {
+	if !tb.IsValidLine(ln) || tb.Pieces != nil {
 		return
 	}
 	tr := lex.NewLex(token.KeyToken{Tok: tag}, st, ed)
@@ -1959,7 +2238,7 @@ func (tb *TextBuf) AddTagEdit(tbe *TextBufEdit, tag token.Tokens) {
 
 // TagAt returns tag at given text position, if one exists -- returns false if not
 func (tb *TextBuf) TagAt(pos TextPos) (reg lex.Lex, ok bool) {
-	if !tb.IsValidLine(pos.Ln) {
+	if !tb.IsValidLine(pos.Ln) || tb.Pieces != nil {
 		return
 	}
 	tb.Tags[pos.Ln] = tb.AdjustedTags(pos.Ln) // re-adjust for current info
@@ -1974,7 +2253,7 @@ func (tb *TextBuf) TagAt(pos TextPos) (reg lex.Lex, ok bool) {
 // RemoveTag removes tag (optionally only given tag if non-zero) at given position
 // if it exists -- returns tag
 func (tb *TextBuf) RemoveTag(pos TextPos, tag token.Tokens) (reg lex.Lex, ok bool) {
-	if !tb.IsValidLine(pos.Ln) {
+	if !tb.IsValidLine(pos.Ln) || tb.Pieces != nil {
 		return
 	}
 	tb.Tags[pos.Ln] = tb.AdjustedTags(pos.Ln) // re-adjust for current info
@@ -2006,11 +2285,11 @@ func (tb *TextBuf) LineIndent(ln int, tabSz int) (n int, ichr indent.Char) {
 	defer tb.LinesMu.RUnlock()
 
 	ichr = indent.Tab
-	sz := len(tb.Lines[ln])
+	txt := tb.lineRunes(ln)
+	sz := len(txt)
 	if sz == 0 {
 		return
 	}
-	txt := tb.Lines[ln]
 	if txt[0] == ' ' {
 		ichr = indent.Space
 		n = 1
@@ -2077,12 +2356,12 @@ func (tb *TextBuf) PrevLineIndent(ln int) (n int, ichr indent.Char, txt string)
 	defer tb.LinesMu.RUnlock()
 	ln--
 	for ln >= 0 {
-		if len(tb.Lines[ln]) == 0 {
+		if len(tb.lineRunes(ln)) == 0 {
 			ln--
 			continue
 		}
 		n, ichr = tb.LineIndent(ln, tabSz)
-		txt = strings.TrimSpace(string(tb.Lines[ln]))
+		txt = strings.TrimSpace(string(tb.lineRunes(ln)))
 		if cmidx := strings.Index(txt, comst); cmidx > 0 {
 			txt = strings.TrimSpace(txt[:cmidx])
 		}
@@ -2105,7 +2384,7 @@ func (tb *TextBuf) AutoIndent(ln int, indents, unindents []string) (tbe *TextBuf
 
 	li, _, prvln := tb.PrevLineIndent(ln)
 	tb.LinesMu.RLock()
-	curln := strings.TrimSpace(string(tb.Lines[ln]))
+	curln := strings.TrimSpace(string(tb.lineRunes(ln)))
 	tb.LinesMu.RUnlock()
 	ind := false
 	und := false
@@ -2220,7 +2499,7 @@ func (tb *TextBuf) CommentRegion(st, ed int) {
 		if doCom {
 			tb.InsertText(TextPos{Ln: ln, Ch: ch}, []byte(comst), true, true)
 			if comed != "" {
-				lln := len(tb.Lines[ln])
+				lln := tb.LineLen(ln)
 				tb.InsertText(TextPos{Ln: ln, Ch: lln}, []byte(comed), true, true)
 			}
 		} else {
@@ -2292,6 +2571,15 @@ func (tb *TextBuf) CompleteText(s string) {
 	c := tb.Complete.GetCompletion(s)
 	pos := TextPos{tb.Complete.SrcLn, tb.Complete.SrcCh}
 	ed := tb.Complete.EditFunc(tb.Complete.Context, tbes, tb.Complete.SrcCh, c, tb.Complete.Seed)
+	if cv := tb.CurView; cv != nil && cv.HasCursors() {
+		// same completion at each cursor, as one undo group
+		seed := tb.Complete.Seed
+		cv.EditAtCursors(func() {
+			cv.completeAtCursor(seed, ed.NewText, ed.ForwardDelete, ed.CursorAdjust)
+		})
+		tb.CurView = nil
+		return
+	}
 	if ed.ForwardDelete > 0 {
 		delEn := TextPos{tb.Complete.SrcLn, tb.Complete.SrcCh + ed.ForwardDelete}
 		tb.DeleteText(pos, delEn, true, false)
@@ -2424,11 +2712,11 @@ func (tb *TextBuf) DiffBufs(ob *TextBuf) TextDiffs {
 	astr := make([]string, tb.NLines)
 	bstr := make([]string, ob.NLines)
 
-	for ai, al := range tb.Lines {
-		astr[ai] = string(al)
+	for ai := range astr {
+		astr[ai] = string(tb.lineRunes(ai))
 	}
-	for bi, bl := range ob.Lines {
-		bstr[bi] = string(bl)
+	for bi := range bstr {
+		bstr[bi] = string(ob.lineRunes(bi))
 	}
 
 	m := difflib.NewMatcherWithJunk(astr, bstr, false, nil) // no junk
@@ -2449,11 +2737,11 @@ func (tb *TextBuf) DiffBufsUnified(ob *TextBuf, context int) []byte {
 	astr := make([]string, tb.NLines)
 	bstr := make([]string, ob.NLines)
 
-	for ai, al := range tb.Lines {
-		astr[ai] = string(al) + "\n"
+	for ai := range astr {
+		astr[ai] = string(tb.lineRunes(ai)) + "\n"
 	}
-	for bi, bl := range ob.Lines {
-		bstr[bi] = string(bl) + "\n"
+	for bi := range bstr {
+		bstr[bi] = string(ob.lineRunes(bi)) + "\n"
 	}
 
 	ud := difflib.UnifiedDiff{A: astr, FromFile: string(tb.Filename), FromDate: tb.Info.ModTime.String(),
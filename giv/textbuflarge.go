@@ -0,0 +1,288 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import (
+	"bytes"
+	"os"
+	"unicode/utf8"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/ki/ints"
+)
+
+// Large buffers: a TextBuf whose text is larger than TextBufLargeSize keeps
+// it in a TextPieces piece table instead of per-line Lines, LineBytes,
+// Markup, Tags and HiTags, so its memory use stays close to the size of the
+// text itself.  Lines are decoded into runes only when they are used, and
+// only the lines that a TextView shows are marked up, one line at a time
+// (with chroma highlighting -- full-file GoPi parsing needs all the Lines,
+// so those files are shown as plain text), and custom tags, completion and
+// spelling are not supported.  Files larger than TextBufMapSize are
+// memory-mapped read-only -- see OpenMapped.
+
+// TextBufLargeSize is the size in bytes of text above which a TextBuf keeps
+// it in a TextPieces piece table, instead of per-line runes and markup
+var TextBufLargeSize = 20 * 1024 * 1024
+
+// TextBufMapSize is the size in bytes of files above which OpenFile opens
+// them read-only and memory-mapped, using OpenMapped -- set to 0 to never
+// do so
+var TextBufMapSize = 100 * 1024 * 1024
+
+// TextBufCacheLines is the maximum number of decoded and marked-up lines
+// cached for a large TextBuf, before the caches are cleared
+var TextBufCacheLines = 2000
+
+// IsLarge returns true if the text is held in the Pieces piece table,
+// because it is larger than TextBufLargeSize
+func (tb *TextBuf) IsLarge() bool {
+	return tb.Pieces != nil
+}
+
+// IsReadOnly returns true if the buffer cannot be edited, because it was
+// opened with OpenMapped
+func (tb *TextBuf) IsReadOnly() bool {
+	return tb.HasFlag(int(TextBufReadOnly))
+}
+
+// SetLargeText sets the text to given bytes, held in a TextPieces piece
+// table regardless of size, without any per-line storage -- the bytes are
+// used directly and must not be modified after
+func (tb *TextBuf) SetLargeText(txt []byte) {
+	tb.Defaults()
+	tb.setLarge(txt, nil)
+}
+
+// OpenMapped opens given file read-only, memory-mapping it where supported
+// (see MapFile), so that only the parts of it that are viewed are read into
+// memory -- the mapping is released by New, Close, or opening another file
+// -- the file must not be modified by other programs while it is open
+func (tb *TextBuf) OpenMapped(filename gi.FileName) error {
+	b, unmap, err := MapFile(string(filename))
+	if err != nil {
+		return err
+	}
+	tb.Defaults()
+	tb.Filename = filename
+	tb.Opts.LineEnds = LineEndsLF
+	tb.Opts.Encoding = EncodingUTF8
+	tb.Opts.BOM = false
+	tb.Stat()
+	tb.setLarge(b, unmap)
+	tb.SetFlag(int(TextBufReadOnly))
+	return nil
+}
+
+// openLargeFile opens given file using OpenMapped if it is larger than
+// TextBufMapSize, returning false if not
+func (tb *TextBuf) openLargeFile(filename gi.FileName) (bool, error) {
+	if TextBufMapSize <= 0 {
+		return false, nil
+	}
+	fi, err := os.Stat(string(filename))
+	if err != nil || fi.Size() <= int64(TextBufMapSize) {
+		return false, nil
+	}
+	return true, tb.OpenMapped(filename)
+}
+
+// setLarge sets the Pieces piece table for given text, releasing all the
+// per-line storage, and any prior mapping, and setting unmap to release the
+// new one if non-nil -- this is the large-buffer version of New
+func (tb *TextBuf) setLarge(txt []byte, unmap func() error) {
+	tb.ClearUndo()
+	tb.LinesMu.Lock()
+	tb.MarkupMu.Lock()
+	tb.clearLarge()
+	tb.Txt = txt
+	tb.unmap = unmap
+	tb.Pieces = NewTextPieces(txt)
+	tb.NLines = tb.Pieces.NumLines()
+	tb.TotalBytes = tb.Pieces.Len()
+	tb.Lines = nil
+	tb.LineBytes = nil
+	tb.Tags = nil
+	tb.HiTags = nil
+	tb.Markup = nil
+	tb.ByteOffs = nil
+	tb.lineCache = make(map[int][]rune)
+	tb.markCache = make(map[int][]byte)
+
+	tb.PiState.SetSrc(&tb.Lines, string(tb.Filename), tb.Info.Sup)
+	tb.Hi.Init(&tb.Info, &tb.PiState)
+
+	tb.MarkupMu.Unlock()
+	tb.LinesMu.Unlock()
+
+	if tb.Complete != nil {
+		tb.SetCompleter(nil, nil, nil)
+	}
+	if tb.SpellCorrect != nil {
+		tb.SetSpellCorrect(nil, nil)
+	}
+}
+
+// clearLarge releases the Pieces piece table and any memory mapping of its
+// text -- must be called with LinesMu and MarkupMu locked
+func (tb *TextBuf) clearLarge() {
+	if tb.Pieces == nil {
+		return
+	}
+	if tb.unmap != nil {
+		if len(tb.Txt) > 0 && len(tb.Pieces.Orig) > 0 && &tb.Txt[0] == &tb.Pieces.Orig[0] {
+			tb.Txt = nil // must not be used after unmapping
+		}
+		tb.unmap()
+		tb.unmap = nil
+	}
+	tb.Pieces = nil
+	tb.lineCache = nil
+	tb.markCache = nil
+	tb.ClearFlag(int(TextBufReadOnly))
+}
+
+// clearLargeCache clears the caches of decoded and marked-up lines, after
+// edits, which can change the numbers of all the following lines
+func (tb *TextBuf) clearLargeCache() {
+	tb.cacheMu.Lock()
+	tb.lineCache = make(map[int][]rune)
+	tb.markCache = make(map[int][]byte)
+	tb.cacheMu.Unlock()
+}
+
+// lineRunes returns the runes of given line, which must be valid, without
+// locking LinesMu -- for large buffers, it is decoded from Pieces and cached
+func (tb *TextBuf) lineRunes(ln int) []rune {
+	if tb.Pieces == nil {
+		return tb.Lines[ln]
+	}
+	tb.cacheMu.Lock()
+	defer tb.cacheMu.Unlock()
+	if rn, ok := tb.lineCache[ln]; ok {
+		return rn
+	}
+	if len(tb.lineCache) >= TextBufCacheLines {
+		tb.lineCache = make(map[int][]rune)
+	}
+	rn := bytes.Runes(tb.Pieces.LineBytes(ln))
+	tb.lineCache[ln] = rn
+	return rn
+}
+
+// LineMarkup returns the marked-up version of given line, for rendering --
+// for large buffers, it is marked up when first needed and cached, and
+// otherwise the MarkupMu mutex must be read-locked by the caller
+func (tb *TextBuf) LineMarkup(ln int) []byte {
+	if tb.Pieces == nil {
+		if ln < 0 || ln >= len(tb.Markup) {
+			return nil
+		}
+		return tb.Markup[ln]
+	}
+	tb.cacheMu.Lock()
+	mu, ok := tb.markCache[ln]
+	tb.cacheMu.Unlock()
+	if ok {
+		return mu
+	}
+	tb.LinesMu.RLock()
+	if ln < 0 || ln >= tb.NLines {
+		tb.LinesMu.RUnlock()
+		return nil
+	}
+	lb := tb.Pieces.LineBytes(ln)
+	tb.LinesMu.RUnlock()
+	mu = nil
+	if tb.Hi.HasHi() && !tb.Hi.UsingPi() {
+		if mt, err := tb.Hi.MarkupTagsLine(ln, lb); err == nil {
+			mu = tb.Hi.MarkupLine(lb, mt, nil)
+		}
+	}
+	if mu == nil {
+		mu = HTMLEscapeBytes(lb)
+	}
+	tb.cacheMu.Lock()
+	if len(tb.markCache) >= TextBufCacheLines {
+		tb.markCache = make(map[int][]byte)
+	}
+	tb.markCache[ln] = mu
+	tb.cacheMu.Unlock()
+	return mu
+}
+
+// markupLarge clears the cached markup of given range of lines, end
+// inclusive, so they are marked up again when next shown -- the large-buffer
+// version of MarkupLines
+func (tb *TextBuf) markupLarge(st, ed int) {
+	tb.cacheMu.Lock()
+	for ln := st; ln <= ed; ln++ {
+		delete(tb.markCache, ln)
+	}
+	tb.cacheMu.Unlock()
+}
+
+// largeOff returns the byte offset in Pieces of given valid position --
+// must be called with LinesMu locked
+func (tb *TextBuf) largeOff(pos TextPos) int {
+	lb := tb.Pieces.LineBytes(pos.Ln)
+	bi := 0
+	for ch := 0; ch < pos.Ch && bi < len(lb); ch++ {
+		_, sz := utf8.DecodeRune(lb[bi:])
+		bi += sz
+	}
+	return tb.Pieces.LineStart(pos.Ln) + bi
+}
+
+// insertLarge inserts given text at given valid position in Pieces, and
+// returns the end of the inserted text
+func (tb *TextBuf) insertLarge(st TextPos, text []byte) TextPos {
+	tb.LinesMu.Lock()
+	tb.Pieces.Insert(tb.largeOff(st), text)
+	tb.NLines = tb.Pieces.NumLines()
+	tb.TotalBytes = tb.Pieces.Len()
+	ed := st
+	if lf := bytes.LastIndexByte(text, '\n'); lf >= 0 {
+		ed.Ln += bytes.Count(text, []byte("\n"))
+		ed.Ch = utf8.RuneCount(text[lf+1:])
+	} else {
+		ed.Ch += utf8.RuneCount(text)
+	}
+	for ln := st.Ln; ln <= ed.Ln; ln++ {
+		lln := tb.Pieces.LineStart(ln+1) - tb.Pieces.LineStart(ln) - 1
+		tb.Pieces.MaxLen = ints.MaxInt(tb.Pieces.MaxLen, lln)
+	}
+	tb.LinesMu.Unlock()
+	tb.clearLargeCache()
+	return ed
+}
+
+// deleteLarge deletes the text between given valid positions in Pieces
+func (tb *TextBuf) deleteLarge(st, ed TextPos) {
+	tb.LinesMu.Lock()
+	tb.Pieces.Delete(tb.largeOff(st), tb.largeOff(ed))
+	tb.NLines = tb.Pieces.NumLines()
+	tb.TotalBytes = tb.Pieces.Len()
+	tb.LinesMu.Unlock()
+	tb.clearLargeCache()
+}
+
+// saveLarge writes the text of a large buffer to given file, piece by piece
+func (tb *TextBuf) saveLarge(filename gi.FileName) error {
+	if tb.unmap != nil && filename == tb.Filename {
+		return nil // read-only, so the file already has the text, and is mapped
+	}
+	f, err := os.Create(string(filename))
+	if err != nil {
+		return err
+	}
+	tb.LinesMu.RLock()
+	_, err = tb.Pieces.WriteTo(f)
+	tb.LinesMu.RUnlock()
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
@@ -0,0 +1,323 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+// Undo history: the Undos stack of a TextBuf holds every edit, in groups
+// that are undone together (see BeginGroup and UndoBurstTime).  Edits that
+// are undone and then replaced by new ones are kept in UndoBranches, and
+// can be switched back to with SwitchUndoBranch.  The save point marks where
+// the file was last opened or saved, so that IsChanged is false whenever
+// undo or redo gets back to it.  With the UndoFile option, the whole
+// history is saved next to the file whenever it is saved, and restored when
+// the file is opened again, if it has not changed since -- see UndoFilename.
+
+// TextBufUndoBranch is a branch of the undo history: a sequence of edits
+// that were undone, and then replaced on the undo stack by new edits.  It
+// follows the first Pos edits of the stack, as long as the last of them is
+// still Parent -- it can also follow edits in other branches, when those are
+// switched to.
+type TextBufUndoBranch struct {
+	Pos    int            `desc:"position in the undo stack at which the branch starts"`
+	Parent *TextBufEdit   `desc:"the edit on the undo stack just before the branch -- nil if it starts at the beginning"`
+	Undos  []*TextBufEdit `desc:"the edits on the branch, in order"`
+}
+
+// ClearUndo clears all of the undo history, including its branches, with
+// the save point at the start
+func (tb *TextBuf) ClearUndo() {
+	tb.Undos = nil
+	tb.UndoUndos = nil
+	tb.UndoPos = 0
+	tb.UndoBranches = nil
+	tb.undoBurst = 0
+	tb.undoBreak = false
+	tb.savePos = 0
+	tb.saveEdit = nil
+	tb.saveHash = ""
+}
+
+// SetSavePoint sets the save point to the current undo position -- called
+// when the file is saved
+func (tb *TextBuf) SetSavePoint() {
+	tb.savePos = tb.UndoPos
+	tb.saveEdit = tb.undoParent(tb.UndoPos)
+	tb.saveHash = ""
+	if tb.Opts.UndoFile && !tb.IsLarge() {
+		tb.saveHash = undoHash(tb.LinesToBytesCopy())
+	}
+}
+
+// AtSavePoint returns true if the current undo position is the save point,
+// where the file was last opened or saved
+func (tb *TextBuf) AtSavePoint() bool {
+	return tb.UndoPos == tb.savePos && tb.onUndoStack(tb.savePos, tb.saveEdit)
+}
+
+// undoChanged sets whether the text has changed, after undo or redo, which
+// it has not if back at the save point
+func (tb *TextBuf) undoChanged() {
+	if tb.AtSavePoint() {
+		tb.ClearChanged()
+		tb.AutoSaveDelete()
+	} else {
+		tb.SetChanged()
+	}
+}
+
+// undoParent returns the edit on the undo stack just before given position
+// -- nil if at the start
+func (tb *TextBuf) undoParent(pos int) *TextBufEdit {
+	if pos <= 0 || pos > len(tb.Undos) {
+		return nil
+	}
+	return tb.Undos[pos-1]
+}
+
+// onUndoStack returns true if given edit is on the undo stack just before
+// given position -- or if it is nil and the position is the start
+func (tb *TextBuf) onUndoStack(pos int, parent *TextBufEdit) bool {
+	if pos < 0 || pos > len(tb.Undos) {
+		return false
+	}
+	return tb.undoParent(pos) == parent
+}
+
+// undoBranch moves the edits after the current undo position to a new
+// branch in UndoBranches
+func (tb *TextBuf) undoBranch() {
+	br := &TextBufUndoBranch{Pos: tb.UndoPos, Parent: tb.undoParent(tb.UndoPos)}
+	br.Undos = append(br.Undos, tb.Undos[tb.UndoPos:]...)
+	tb.UndoBranches = append(tb.UndoBranches, br)
+	tb.Undos = tb.Undos[:tb.UndoPos]
+}
+
+// RedoBranches returns the indexes in UndoBranches of the branches that can
+// be switched to at the current undo position, to redo them instead of the
+// rest of the undo stack
+func (tb *TextBuf) RedoBranches() []int {
+	var bis []int
+	for bi, br := range tb.UndoBranches {
+		if br.Pos == tb.UndoPos && tb.onUndoStack(br.Pos, br.Parent) {
+			bis = append(bis, bi)
+		}
+	}
+	return bis
+}
+
+// SwitchUndoBranch replaces the rest of the undo stack, after the current
+// undo position, with the edits of given branch in UndoBranches, which are
+// then redone by Redo -- the edits that were replaced become that branch
+// instead -- returns false if the branch cannot be switched to here (see
+// RedoBranches)
+func (tb *TextBuf) SwitchUndoBranch(bi int) bool {
+	if bi < 0 || bi >= len(tb.UndoBranches) {
+		return false
+	}
+	br := tb.UndoBranches[bi]
+	if br.Pos != tb.UndoPos || !tb.onUndoStack(br.Pos, br.Parent) {
+		return false
+	}
+	var rest []*TextBufEdit
+	rest = append(rest, tb.Undos[tb.UndoPos:]...)
+	tb.Undos = append(tb.Undos[:tb.UndoPos], br.Undos...)
+	if len(rest) == 0 {
+		tb.UndoBranches = append(tb.UndoBranches[:bi], tb.UndoBranches[bi+1:]...)
+	} else {
+		br.Undos = rest
+	}
+	tb.undoBreak = true
+	return true
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//  Undo file
+
+// textBufUndoFile is the format of the file in which the undo history is
+// saved -- all the edits, on the stack and in branches, are in Edits, and
+// referred to by their indexes there, -1 for none
+type textBufUndoFile struct {
+	Hash     string
+	SavePos  int
+	SaveEdit int
+	Edits    []textBufUndoEdit
+	Undos    []int
+	Branches []textBufUndoBranch
+}
+
+// textBufUndoEdit is a TextBufEdit as saved in the undo file
+type textBufUndoEdit struct {
+	Reg    TextRegion
+	Delete bool
+	Text   string
+	Group  int
+}
+
+// textBufUndoBranch is a TextBufUndoBranch as saved in the undo file
+type textBufUndoBranch struct {
+	Pos    int
+	Parent int
+	Undos  []int
+}
+
+// undoHash returns the hash of given text that identifies it in the undo
+// file
+func undoHash(txt []byte) string {
+	h := sha1.Sum(txt)
+	return hex.EncodeToString(h[:])
+}
+
+// UndoFilename returns the name of the file in which the undo history is
+// saved with the UndoFile option, next to the autosave file -- empty if
+// there is no Filename
+func (tb *TextBuf) UndoFilename() string {
+	if tb.Filename == "" {
+		return ""
+	}
+	path, fn := filepath.Split(string(tb.Filename))
+	return filepath.Join(path, "#"+fn+"#.undo")
+}
+
+// SaveUndoFile saves the undo history to the UndoFilename file, along with
+// the save point, which must be on the undo stack, and the hash of the text
+// there -- it is not saved for large buffers.  It is called by SaveFile, and
+// must not be called concurrently with edits, so not from AutoSave.
+func (tb *TextBuf) SaveUndoFile() error {
+	ufn := tb.UndoFilename()
+	if ufn == "" || tb.IsLarge() || tb.saveHash == "" || !tb.onUndoStack(tb.savePos, tb.saveEdit) {
+		return nil
+	}
+	uf := textBufUndoFile{Hash: tb.saveHash, SavePos: tb.savePos}
+	idxs := make(map[*TextBufEdit]int)
+	idx := func(tbe *TextBufEdit) int {
+		if tbe == nil {
+			return -1
+		}
+		if i, ok := idxs[tbe]; ok {
+			return i
+		}
+		i := len(uf.Edits)
+		idxs[tbe] = i
+		uf.Edits = append(uf.Edits, textBufUndoEdit{Reg: tbe.Reg, Delete: tbe.Delete, Text: string(tbe.ToBytes()), Group: tbe.Group})
+		return i
+	}
+	for _, tbe := range tb.Undos {
+		uf.Undos = append(uf.Undos, idx(tbe))
+	}
+	for _, br := range tb.UndoBranches {
+		ubr := textBufUndoBranch{Pos: br.Pos, Parent: idx(br.Parent)}
+		for _, tbe := range br.Undos {
+			ubr.Undos = append(ubr.Undos, idx(tbe))
+		}
+		uf.Branches = append(uf.Branches, ubr)
+	}
+	uf.SaveEdit = idx(tb.saveEdit)
+	b, err := json.Marshal(&uf)
+	if err == nil {
+		err = ioutil.WriteFile(ufn, b, 0644)
+	}
+	if err != nil {
+		log.Printf("giv.TextBuf: Could not save undo history to: %v, error: %v\n", ufn, err)
+	}
+	return err
+}
+
+// OpenUndoFile restores the undo history from the UndoFilename file, if it
+// exists and was saved for the current text, which becomes its save point
+// -- it is not restored for large buffers
+func (tb *TextBuf) OpenUndoFile() error {
+	ufn := tb.UndoFilename()
+	if ufn == "" || tb.IsLarge() {
+		return nil
+	}
+	b, err := ioutil.ReadFile(ufn)
+	if err != nil {
+		if os.IsNotExist(err) {
+			err = nil
+		}
+		return err
+	}
+	var uf textBufUndoFile
+	if err := json.Unmarshal(b, &uf); err != nil {
+		return err
+	}
+	if uf.Hash != undoHash(tb.LinesToBytesCopy()) {
+		return nil // file has changed since
+	}
+	edits := make([]*TextBufEdit, len(uf.Edits))
+	for i, ue := range uf.Edits {
+		tbe := &TextBufEdit{Reg: ue.Reg, Delete: ue.Delete, Group: ue.Group}
+		if ue.Text != "" {
+			for _, ln := range bytes.Split([]byte(ue.Text), []byte("\n")) {
+				tbe.Text = append(tbe.Text, bytes.Runes(ln))
+			}
+		}
+		edits[i] = tbe
+	}
+	edit := func(i int) (*TextBufEdit, error) {
+		if i < -1 || i >= len(edits) {
+			return nil, fmt.Errorf("invalid edit index: %v", i)
+		}
+		if i < 0 {
+			return nil, nil
+		}
+		return edits[i], nil
+	}
+	var undos []*TextBufEdit
+	for _, i := range uf.Undos {
+		tbe, err := edit(i)
+		if err != nil {
+			return err
+		}
+		undos = append(undos, tbe)
+	}
+	var brs []*TextBufUndoBranch
+	for _, ubr := range uf.Branches {
+		par, err := edit(ubr.Parent)
+		if err != nil {
+			return err
+		}
+		br := &TextBufUndoBranch{Pos: ubr.Pos, Parent: par}
+		for _, i := range ubr.Undos {
+			tbe, err := edit(i)
+			if err != nil {
+				return err
+			}
+			br.Undos = append(br.Undos, tbe)
+		}
+		brs = append(brs, br)
+	}
+	sed, err := edit(uf.SaveEdit)
+	if err != nil {
+		return err
+	}
+	if uf.SavePos < 0 || uf.SavePos > len(undos) || (uf.SavePos > 0 && undos[uf.SavePos-1] != sed) {
+		return fmt.Errorf("invalid save point: %v", uf.SavePos)
+	}
+	tb.ClearUndo()
+	tb.Undos = undos
+	tb.UndoBranches = brs
+	tb.UndoPos = uf.SavePos
+	tb.savePos = uf.SavePos
+	tb.saveEdit = sed
+	tb.saveHash = uf.Hash
+	for _, tbe := range edits {
+		if tbe.Group > tb.undoGroupN {
+			tb.undoGroupN = tbe.Group
+		}
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/goki/gi/gi"
+)
+
+// newTestTextBuf returns a new TextBuf with given text
+func newTestTextBuf(txt string) *TextBuf {
+	tb := &TextBuf{}
+	tb.InitName(tb, "test-buf")
+	tb.SetText([]byte(txt))
+	return tb
+}
+
+// typeText inserts given text one character at a time, as if typed, at
+// given position
+func typeText(tb *TextBuf, pos TextPos, txt string) {
+	for _, r := range txt {
+		tb.InsertText(pos, []byte(string(r)), true, true)
+		pos.Ch++
+	}
+}
+
+func testBufText(t *testing.T, tb *TextBuf, want string) {
+	t.Helper()
+	if got := string(tb.LinesToBytesCopy()); got != want {
+		t.Errorf("text is: %q, want: %q", got, want)
+	}
+}
+
+func TestUndoBurst(t *testing.T) {
+	tb := newTestTextBuf("\n")
+	typeText(tb, TextPos{0, 0}, "abc")
+	testBufText(t, tb, "abc\n")
+	tb.Undo()
+	testBufText(t, tb, "\n")
+	tb.Redo()
+	testBufText(t, tb, "abc\n")
+
+	typeText(tb, TextPos{0, 3}, "de")
+	tb.UndoBoundary()
+	typeText(tb, TextPos{0, 5}, "fg")
+	tb.Undo()
+	testBufText(t, tb, "abcde\n")
+	tb.Undo()
+	testBufText(t, tb, "abc\n")
+
+	tb.BeginGroup()
+	tb.InsertText(TextPos{0, 0}, []byte("xyz\n"), true, true)
+	tb.InsertText(TextPos{1, 3}, []byte("123"), true, true)
+	tb.EndGroup()
+	testBufText(t, tb, "xyz\nabc123\n")
+	tb.Undo()
+	testBufText(t, tb, "abc\n")
+}
+
+func TestUndoSavePoint(t *testing.T) {
+	tb := newTestTextBuf("abc\n")
+	tb.SetSavePoint()
+	if tb.IsChanged() {
+		t.Errorf("buffer is changed before any edits")
+	}
+	typeText(tb, TextPos{0, 3}, "de")
+	if !tb.IsChanged() {
+		t.Errorf("buffer is not changed after edits")
+	}
+	tb.Undo()
+	testBufText(t, tb, "abc\n")
+	if tb.IsChanged() {
+		t.Errorf("buffer is changed after undo back to the save point")
+	}
+	tb.Redo()
+	if !tb.IsChanged() {
+		t.Errorf("buffer is not changed after redo past the save point")
+	}
+	tb.SetSavePoint()
+	tb.Undo()
+	if !tb.IsChanged() {
+		t.Errorf("buffer is not changed after undo before the save point")
+	}
+	tb.Redo()
+	if tb.IsChanged() {
+		t.Errorf("buffer is changed after redo back to the save point")
+	}
+}
+
+func TestUndoBranches(t *testing.T) {
+	tb := newTestTextBuf("\n")
+	typeText(tb, TextPos{0, 0}, "a")
+	tb.Undo()
+	typeText(tb, TextPos{0, 0}, "b")
+	tb.Undo()
+	testBufText(t, tb, "\n")
+
+	bis := tb.RedoBranches()
+	if len(bis) != 1 {
+		t.Fatalf("redo branches: %v, want 1", bis)
+	}
+	if !tb.SwitchUndoBranch(bis[0]) {
+		t.Fatalf("could not switch to undo branch: %v", bis[0])
+	}
+	tb.Redo()
+	testBufText(t, tb, "a\n")
+	if tb.SwitchUndoBranch(bis[0]) {
+		t.Errorf("switched to undo branch away from its position")
+	}
+
+	tb.Undo()
+	if !tb.SwitchUndoBranch(bis[0]) {
+		t.Fatalf("could not switch back to undo branch: %v", bis[0])
+	}
+	tb.Redo()
+	testBufText(t, tb, "b\n")
+	if len(tb.UndoBranches) != 1 {
+		t.Errorf("undo branches: %v, want 1", len(tb.UndoBranches))
+	}
+}
+
+func TestUndoFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "giv-undo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	fn := gi.FileName(filepath.Join(dir, "test.txt"))
+
+	tb := newTestTextBuf("abc\n")
+	tb.Filename = fn
+	tb.Opts.UndoFile = true
+	typeText(tb, TextPos{0, 3}, "de")
+	tb.UndoBoundary()
+	tb.InsertText(TextPos{0, 0}, []byte("xyz\n"), true, true)
+	tb.Undo()
+	typeText(tb, TextPos{0, 0}, "f") // makes a branch
+	tb.SetSavePoint()
+	if err := tb.SaveUndoFile(); err != nil {
+		t.Fatal(err)
+	}
+
+	nb := newTestTextBuf("fabcde\n")
+	nb.Filename = fn
+	if err := nb.OpenUndoFile(); err != nil {
+		t.Fatal(err)
+	}
+	if len(nb.Undos) != len(tb.Undos) || nb.UndoPos != tb.UndoPos || len(nb.UndoBranches) != len(tb.UndoBranches) {
+		t.Fatalf("undo history not restored: %v edits at: %v, %v branches, want: %v at: %v, %v",
+			len(nb.Undos), nb.UndoPos, len(nb.UndoBranches), len(tb.Undos), tb.UndoPos, len(tb.UndoBranches))
+	}
+	if !nb.AtSavePoint() {
+		t.Errorf("undo history not restored at the save point")
+	}
+	nb.Undo()
+	testBufText(t, nb, "abcde\n")
+	nb.Undo()
+	testBufText(t, nb, "abc\n")
+	bis := nb.RedoBranches()
+	if len(bis) != 0 {
+		t.Errorf("redo branches: %v, want none", bis)
+	}
+	nb.Redo()
+	if !nb.SwitchUndoBranch(0) {
+		t.Fatalf("could not switch to restored undo branch")
+	}
+	nb.Redo()
+	testBufText(t, nb, "xyz\nabcde\n")
+
+	cb := newTestTextBuf("changed\n")
+	cb.Filename = fn
+	if err := cb.OpenUndoFile(); err != nil {
+		t.Fatal(err)
+	}
+	if len(cb.Undos) != 0 {
+		t.Errorf("undo history restored for changed text")
+	}
+}
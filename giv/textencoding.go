@@ -0,0 +1,324 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"log"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/ki/kit"
+)
+
+// Text encodings: a TextBuf always holds its text as UTF-8 with LF line
+// endings.  OpenFile detects the encoding, any byte order mark (BOM), and
+// the line endings of a file, records them in the LineEnds, Encoding and BOM
+// fields of its TextBufOpts, and converts the text -- SaveFile converts it
+// back, so files are saved the way they were opened -- text with mixed line
+// endings is kept as it is (see DetectLineEnds).  SetLineEnds and
+// SetEncoding change how the file is saved.  Files opened read-only with
+// OpenMapped are kept as they are.
+
+// TextLineEnds are the ways of ending lines of text in a file
+type TextLineEnds int32
+
+const (
+	// LineEndsLF ends lines with a line feed, as on Unix and MacOS
+	LineEndsLF TextLineEnds = iota
+
+	// LineEndsCRLF ends lines with a carriage return and a line feed, as on
+	// Windows / DOS
+	LineEndsCRLF
+
+	// LineEndsCR ends lines with a carriage return, as on classic MacOS
+	LineEndsCR
+
+	TextLineEndsN
+)
+
+//go:generate stringer -type=TextLineEnds
+
+var KiT_TextLineEnds = kit.Enums.AddEnumAltLower(TextLineEndsN, false, nil, "LineEnds")
+
+func (ev TextLineEnds) MarshalJSON() ([]byte, error)  { return kit.EnumMarshalJSON(ev) }
+func (ev *TextLineEnds) UnmarshalJSON(b []byte) error { return kit.EnumUnmarshalJSON(ev, b) }
+
+// TextEncodings are the character encodings of text in a file
+type TextEncodings int32
+
+const (
+	// EncodingUTF8 is UTF-8, which is also used for plain ASCII
+	EncodingUTF8 TextEncodings = iota
+
+	// EncodingUTF16LE is little-endian UTF-16, as used on Windows
+	EncodingUTF16LE
+
+	// EncodingUTF16BE is big-endian UTF-16
+	EncodingUTF16BE
+
+	// EncodingLatin1 is ISO-8859-1, with one byte per character -- used for
+	// any text that is not valid UTF-8
+	EncodingLatin1
+
+	TextEncodingsN
+)
+
+//go:generate stringer -type=TextEncodings
+
+var KiT_TextEncodings = kit.Enums.AddEnumAltLower(TextEncodingsN, false, nil, "Encoding")
+
+func (ev TextEncodings) MarshalJSON() ([]byte, error)  { return kit.EnumMarshalJSON(ev) }
+func (ev *TextEncodings) UnmarshalJSON(b []byte) error { return kit.EnumUnmarshalJSON(ev, b) }
+
+// byte order marks of the encodings that have them
+var (
+	bomUTF8    = []byte{0xEF, 0xBB, 0xBF}
+	bomUTF16LE = []byte{0xFF, 0xFE}
+	bomUTF16BE = []byte{0xFE, 0xFF}
+)
+
+// BOMBytes returns the byte order mark of given encoding -- nil if it has
+// none
+func (enc TextEncodings) BOMBytes() []byte {
+	switch enc {
+	case EncodingUTF8:
+		return bomUTF8
+	case EncodingUTF16LE:
+		return bomUTF16LE
+	case EncodingUTF16BE:
+		return bomUTF16BE
+	}
+	return nil
+}
+
+// DetectEncoding returns the encoding of given text from a file, and
+// whether it starts with a byte order mark.  Without one, text with many
+// zero bytes at either odd or even offsets is UTF-16, text that is valid
+// UTF-8 is UTF-8, and anything else is Latin-1.
+func DetectEncoding(b []byte) (enc TextEncodings, bom bool) {
+	switch {
+	case bytes.HasPrefix(b, bomUTF8):
+		return EncodingUTF8, true
+	case bytes.HasPrefix(b, bomUTF16LE):
+		return EncodingUTF16LE, true
+	case bytes.HasPrefix(b, bomUTF16BE):
+		return EncodingUTF16BE, true
+	}
+	if len(b)%2 == 0 && bytes.IndexByte(b, 0) >= 0 {
+		ev, od := 0, 0
+		for i := 0; i < len(b); i += 2 {
+			if b[i] == 0 {
+				ev++
+			}
+			if b[i+1] == 0 {
+				od++
+			}
+		}
+		// mostly ASCII text, with zero high bytes
+		switch n := len(b) / 2; {
+		case od > n/2 && ev <= n/10:
+			return EncodingUTF16LE, false
+		case ev > n/2 && od <= n/10:
+			return EncodingUTF16BE, false
+		}
+	}
+	if utf8.Valid(b) {
+		return EncodingUTF8, false
+	}
+	return EncodingLatin1, false
+}
+
+// DecodeText returns given text from a file, in given encoding, converted
+// to UTF-8 without any byte order mark -- it is returned as is if it is
+// already that way
+func DecodeText(b []byte, enc TextEncodings, bom bool) ([]byte, error) {
+	if bom {
+		b = bytes.TrimPrefix(b, enc.BOMBytes())
+	}
+	switch enc {
+	case EncodingUTF16LE, EncodingUTF16BE:
+		if len(b)%2 != 0 {
+			return nil, fmt.Errorf("giv.DecodeText: UTF-16 text has an odd number of bytes: %v", len(b))
+		}
+		var bo binary.ByteOrder = binary.LittleEndian
+		if enc == EncodingUTF16BE {
+			bo = binary.BigEndian
+		}
+		u := make([]uint16, len(b)/2)
+		for i := range u {
+			u[i] = bo.Uint16(b[2*i:])
+		}
+		return []byte(string(utf16.Decode(u))), nil
+	case EncodingLatin1:
+		ub := make([]byte, 0, len(b)+len(b)/8)
+		var rb [utf8.UTFMax]byte
+		for _, c := range b {
+			n := utf8.EncodeRune(rb[:], rune(c))
+			ub = append(ub, rb[:n]...)
+		}
+		return ub, nil
+	}
+	return b, nil
+}
+
+// EncodeText returns given UTF-8 text encoded in given encoding, starting
+// with its byte order mark if bom is true -- it is returned as is if it is
+// already that way.  Characters that Latin-1 cannot encode are an error.
+func EncodeText(b []byte, enc TextEncodings, bom bool) ([]byte, error) {
+	var eb []byte
+	if bom {
+		eb = append(eb, enc.BOMBytes()...)
+	}
+	switch enc {
+	case EncodingUTF8:
+		if !bom {
+			return b, nil
+		}
+		eb = append(eb, b...)
+	case EncodingUTF16LE, EncodingUTF16BE:
+		var bo binary.ByteOrder = binary.LittleEndian
+		if enc == EncodingUTF16BE {
+			bo = binary.BigEndian
+		}
+		var cb [2]byte
+		for _, c := range utf16.Encode(bytes.Runes(b)) {
+			bo.PutUint16(cb[:], c)
+			eb = append(eb, cb[:]...)
+		}
+	case EncodingLatin1:
+		for _, r := range string(b) {
+			if r > 0xFF {
+				return nil, fmt.Errorf("giv.EncodeText: character %q cannot be encoded in Latin-1", r)
+			}
+			eb = append(eb, byte(r))
+		}
+	}
+	return eb, nil
+}
+
+// DetectLineEnds returns the line endings used in given text: CRLF or CR if
+// all of its lines end that way, and otherwise LF -- including text with
+// mixed line endings, which is then kept as it is, with any CR's in the
+// text, so that it is saved unchanged
+func DetectLineEnds(b []byte) TextLineEnds {
+	lf, crlf, cr := 0, 0, 0
+	for i, c := range b {
+		switch {
+		case c == '\n' && i > 0 && b[i-1] == '\r':
+			crlf++
+		case c == '\n':
+			lf++
+		case c == '\r' && (i+1 >= len(b) || b[i+1] != '\n'):
+			cr++
+		}
+	}
+	switch {
+	case crlf > 0 && lf == 0 && cr == 0:
+		return LineEndsCRLF
+	case cr > 0 && lf == 0 && crlf == 0:
+		return LineEndsCR
+	}
+	return LineEndsLF
+}
+
+// ToLF returns given text with lines ending in given way converted to end
+// in LF -- only those line endings are converted, and it is returned as is
+// for LF
+func ToLF(b []byte, le TextLineEnds) []byte {
+	switch le {
+	case LineEndsCRLF:
+		return bytes.Replace(b, []byte("\r\n"), []byte("\n"), -1)
+	case LineEndsCR:
+		lb := make([]byte, len(b))
+		for i, c := range b {
+			if c == '\r' && (i+1 >= len(b) || b[i+1] != '\n') {
+				c = '\n'
+			}
+			lb[i] = c
+		}
+		return lb
+	}
+	return b
+}
+
+// FromLF returns given text with lines ending in LF converted to end in
+// given way -- it is returned as is for LF
+func FromLF(b []byte, le TextLineEnds) []byte {
+	switch le {
+	case LineEndsCRLF:
+		return bytes.Replace(b, []byte("\n"), []byte("\r\n"), -1)
+	case LineEndsCR:
+		return bytes.Replace(b, []byte("\n"), []byte("\r"), -1)
+	}
+	return b
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//  TextBuf encoding
+
+// IsPlainFile returns true if files are saved as they are held, in UTF-8
+// with LF line endings and no byte order mark
+func (tb *TextBufOpts) IsPlainFile() bool {
+	return tb.LineEnds == LineEndsLF && tb.Encoding == EncodingUTF8 && !tb.BOM
+}
+
+// DecodeFile sets the LineEnds, Encoding and BOM options from given text
+// read from a file, and returns it converted to UTF-8 with LF line endings
+func (tb *TextBuf) DecodeFile(b []byte) ([]byte, error) {
+	enc, bom := DetectEncoding(b)
+	ub, err := DecodeText(b, enc, bom)
+	if err != nil {
+		return b, err
+	}
+	tb.Opts.Encoding = enc
+	tb.Opts.BOM = bom
+	tb.Opts.LineEnds = DetectLineEnds(ub)
+	return ToLF(ub, tb.Opts.LineEnds), nil
+}
+
+// EncodeFile returns given UTF-8 text with LF line endings converted to be
+// saved to a file, according to the LineEnds, Encoding and BOM options
+func (tb *TextBuf) EncodeFile(b []byte) ([]byte, error) {
+	if tb.Opts.IsPlainFile() {
+		return b, nil
+	}
+	return EncodeText(FromLF(b, tb.Opts.LineEnds), tb.Opts.Encoding, tb.Opts.BOM)
+}
+
+// SetLineEnds sets the line endings to use when saving the file, which
+// marks the buffer as changed
+func (tb *TextBuf) SetLineEnds(le TextLineEnds) {
+	if tb.Opts.LineEnds == le {
+		return
+	}
+	tb.Opts.LineEnds = le
+	tb.SetChanged()
+}
+
+// SetEncoding sets the encoding, and whether to start with a byte order
+// mark, to use when saving the file, which marks the buffer as changed --
+// if the text cannot be encoded that way, nothing is changed, and the error
+// is reported and returned
+func (tb *TextBuf) SetEncoding(enc TextEncodings, bom bool) error {
+	if enc.BOMBytes() == nil {
+		bom = false
+	}
+	if tb.Opts.Encoding == enc && tb.Opts.BOM == bom {
+		return nil
+	}
+	if _, err := EncodeText(tb.LinesToBytesCopy(), enc, false); err != nil {
+		gi.PromptDialog(tb.ViewportFromView(), gi.DlgOpts{Title: "Could not Set Encoding", Prompt: err.Error()}, true, false, nil, nil)
+		log.Println(err)
+		return err
+	}
+	tb.Opts.Encoding = enc
+	tb.Opts.BOM = bom
+	tb.SetChanged()
+	return nil
+}
@@ -0,0 +1,175 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDetectEncoding(t *testing.T) {
+	tests := []struct {
+		text string
+		enc  TextEncodings
+		bom  bool
+	}{
+		{"", EncodingUTF8, false},
+		{"hello", EncodingUTF8, false},
+		{"café", EncodingUTF8, false},
+		{"\xEF\xBB\xBFhi", EncodingUTF8, true},
+		{"\xFF\xFEh\x00i\x00", EncodingUTF16LE, true},
+		{"\xFE\xFF\x00h\x00i", EncodingUTF16BE, true},
+		{"h\x00e\x00l\x00l\x00o\x00", EncodingUTF16LE, false},
+		{"\x00h\x00e\x00l\x00l\x00o", EncodingUTF16BE, false},
+		{"caf\xE9\n", EncodingLatin1, false},
+		{"a\x00b", EncodingUTF8, false}, // odd length is not UTF-16
+	}
+	for _, tst := range tests {
+		enc, bom := DetectEncoding([]byte(tst.text))
+		if enc != tst.enc || bom != tst.bom {
+			t.Errorf("DetectEncoding(%q) = %v, %v, want: %v, %v", tst.text, enc, bom, tst.enc, tst.bom)
+		}
+	}
+}
+
+func TestEncodeText(t *testing.T) {
+	tests := []struct {
+		text string
+		enc  TextEncodings
+		bom  bool
+		want string
+	}{
+		{"hi\n", EncodingUTF8, false, "hi\n"},
+		{"hi\n", EncodingUTF8, true, "\xEF\xBB\xBFhi\n"},
+		{"hi\n", EncodingUTF16LE, false, "h\x00i\x00\n\x00"},
+		{"hi\n", EncodingUTF16LE, true, "\xFF\xFEh\x00i\x00\n\x00"},
+		{"hi\n", EncodingUTF16BE, false, "\x00h\x00i\x00\n"},
+		{"hi\n", EncodingUTF16BE, true, "\xFE\xFF\x00h\x00i\x00\n"},
+		{"é𝄞", EncodingUTF16LE, false, "\xE9\x00\x34\xD8\x1E\xDD"},
+		{"é𝄞", EncodingUTF16BE, false, "\x00\xE9\xD8\x34\xDD\x1E"},
+		{"café\n", EncodingLatin1, false, "caf\xE9\n"},
+		{"café\n", EncodingLatin1, true, "caf\xE9\n"}, // no bom
+	}
+	for _, tst := range tests {
+		eb, err := EncodeText([]byte(tst.text), tst.enc, tst.bom)
+		if err != nil {
+			t.Errorf("EncodeText(%q, %v, %v): %v", tst.text, tst.enc, tst.bom, err)
+			continue
+		}
+		if string(eb) != tst.want {
+			t.Errorf("EncodeText(%q, %v, %v) = %q, want: %q", tst.text, tst.enc, tst.bom, eb, tst.want)
+		}
+	}
+	if _, err := EncodeText([]byte("5 €"), EncodingLatin1, false); err == nil {
+		t.Errorf("EncodeText of € in Latin-1 did not fail")
+	}
+	if _, err := DecodeText([]byte("h\x00i"), EncodingUTF16LE, false); err == nil {
+		t.Errorf("DecodeText of an odd number of UTF-16 bytes did not fail")
+	}
+}
+
+func TestEncodingRoundTrip(t *testing.T) {
+	tests := []struct {
+		text string
+		enc  TextEncodings
+		bom  bool
+	}{
+		{"héllo, wörld\n€ 𝄞\n", EncodingUTF8, false},
+		{"héllo, wörld\n€ 𝄞\n", EncodingUTF8, true},
+		{"héllo, wörld\n€ 𝄞\n", EncodingUTF16LE, false},
+		{"héllo, wörld\n€ 𝄞\n", EncodingUTF16LE, true},
+		{"héllo, wörld\n€ 𝄞\n", EncodingUTF16BE, false},
+		{"héllo, wörld\n€ 𝄞\n", EncodingUTF16BE, true},
+		{"héllo, wörld\n", EncodingLatin1, false},
+	}
+	for _, tst := range tests {
+		eb, err := EncodeText([]byte(tst.text), tst.enc, tst.bom)
+		if err != nil {
+			t.Errorf("EncodeText(%q, %v, %v): %v", tst.text, tst.enc, tst.bom, err)
+			continue
+		}
+		enc, bom := DetectEncoding(eb)
+		if enc != tst.enc || bom != tst.bom {
+			t.Errorf("DetectEncoding of %v bom: %v = %v, %v", tst.enc, tst.bom, enc, bom)
+			continue
+		}
+		ub, err := DecodeText(eb, enc, bom)
+		if err != nil {
+			t.Errorf("DecodeText of %v bom: %v: %v", tst.enc, tst.bom, err)
+			continue
+		}
+		if string(ub) != tst.text {
+			t.Errorf("DecodeText of %v bom: %v = %q, want: %q", tst.enc, tst.bom, ub, tst.text)
+		}
+	}
+}
+
+func TestDetectLineEnds(t *testing.T) {
+	tests := []struct {
+		text   string
+		le     TextLineEnds
+		wantLF string
+	}{
+		{"", LineEndsLF, ""},
+		{"abc", LineEndsLF, "abc"},
+		{"a\nb\n", LineEndsLF, "a\nb\n"},
+		{"a\r\nb\r\n", LineEndsCRLF, "a\nb\n"},
+		{"a\r\nb", LineEndsCRLF, "a\nb"},
+		{"a\rb\r", LineEndsCR, "a\nb\n"},
+		{"a\r\rb", LineEndsCR, "a\n\nb"},
+		{"a\r\nb\r\nc\n", LineEndsLF, "a\r\nb\r\nc\n"}, // mixed: kept as is
+		{"a\nb\nc\r\n", LineEndsLF, "a\nb\nc\r\n"},
+		{"a\rb\rc\r\n", LineEndsLF, "a\rb\rc\r\n"},
+		{"a\rb\nc", LineEndsLF, "a\rb\nc"},
+	}
+	for _, tst := range tests {
+		b := []byte(tst.text)
+		le := DetectLineEnds(b)
+		if le != tst.le {
+			t.Errorf("DetectLineEnds(%q) = %v, want: %v", tst.text, le, tst.le)
+			continue
+		}
+		lb := ToLF(b, le)
+		if string(lb) != tst.wantLF {
+			t.Errorf("ToLF(%q, %v) = %q, want: %q", tst.text, le, lb, tst.wantLF)
+		}
+		if fb := FromLF(lb, le); !bytes.Equal(fb, b) {
+			t.Errorf("FromLF(%q, %v) = %q, want: %q", lb, le, fb, tst.text)
+		}
+	}
+	// only the given line endings are converted
+	if lb := ToLF([]byte("a\r\nb\rc"), LineEndsCR); string(lb) != "a\r\nb\nc" {
+		t.Errorf("ToLF CR converted CRLF: %q", lb)
+	}
+}
+
+func TestTextBufEncodeFile(t *testing.T) {
+	tests := []string{
+		"abc\ndef\n",
+		"abc\r\ndef\r\n",
+		"abc\rdef\r",
+		"abc\r\ndef\nghi\r\n",
+		"\xEF\xBB\xBFabc\r\n",
+		"\xFF\xFEa\x00\r\x00\n\x00b\x00\r\x00\n\x00",
+		"\x00a\x00\r\x00b\x00\r",
+		"caf\xE9\r\nna\xEFve\r\n",
+	}
+	for _, tst := range tests {
+		tb := &TextBuf{}
+		ub, err := tb.DecodeFile([]byte(tst))
+		if err != nil {
+			t.Errorf("DecodeFile(%q): %v", tst, err)
+			continue
+		}
+		eb, err := tb.EncodeFile(ub)
+		if err != nil {
+			t.Errorf("EncodeFile(%q): %v", ub, err)
+			continue
+		}
+		if string(eb) != tst {
+			t.Errorf("DecodeFile then EncodeFile of %q = %q, opts: %v %v %v", tst, eb, tb.Opts.LineEnds, tb.Opts.Encoding, tb.Opts.BOM)
+		}
+	}
+}
@@ -0,0 +1,528 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import (
+	"fmt"
+	"sort"
+	"unicode"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/ki"
+	"github.com/goki/ki/kit"
+	"github.com/goki/pi/parse"
+)
+
+// Code folding: a TextView can fold (collapse) regions of lines, which are
+// found in its TextBuf according to its Opts.FoldMode -- by indentation, by
+// brace scopes, or by the GoPi parse tree -- folding is off by default
+// (FoldNone).  The regions are only found again after edits when they are
+// next needed, unless any are folded: see FoldsStale.  The first line of each region
+// stays visible, with a fold marker next to its line number, and the rest of
+// it is replaced by a single placeholder line while it is folded.  Moving
+// the cursor skips over folded lines, and moving it into them otherwise
+// (search, JumpToLine, clicking on the placeholder, etc) unfolds them.
+// Large buffers (see TextBuf.IsLarge) cannot be folded.
+
+// TextFoldModes are the ways of finding the regions of text that can be
+// folded in a TextView
+type TextFoldModes int32
+
+const (
+	// FoldNone does not fold any lines
+	FoldNone TextFoldModes = iota
+
+	// FoldIndent folds lines that are indented more than the line before them
+	FoldIndent
+
+	// FoldBraces folds the lines within brace, paren or bracket scopes
+	FoldBraces
+
+	// FoldParse folds the lines of each node of the GoPi parse tree of the
+	// text, for supported languages -- uses brace scopes otherwise
+	FoldParse
+
+	TextFoldModesN
+)
+
+//go:generate stringer -type=TextFoldModes
+
+var KiT_TextFoldModes = kit.Enums.AddEnumAltLower(TextFoldModesN, false, nil, "Fold")
+
+func (ev TextFoldModes) MarshalJSON() ([]byte, error)  { return kit.EnumMarshalJSON(ev) }
+func (ev *TextFoldModes) UnmarshalJSON(b []byte) error { return kit.EnumUnmarshalJSON(ev, b) }
+
+// TextFold is a region of lines that can be folded in a TextView
+type TextFold struct {
+	St     int  `desc:"first line of the region, which stays visible when it is folded"`
+	Ed     int  `desc:"last line of the region, inclusive"`
+	Closed bool `desc:"whether the region is currently folded, hiding all but its first line"`
+}
+
+// NLines returns the number of lines that are hidden when the region is
+// folded
+func (tf *TextFold) NLines() int {
+	return tf.Ed - tf.St
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//  TextBuf fold regions
+
+// FoldRegions returns the regions of lines that can be folded, found in
+// given way, in order of their starting lines -- regions can be nested, but
+// only one starts on any given line -- nil for large buffers and FoldNone
+func (tb *TextBuf) FoldRegions(mode TextFoldModes) []TextFold {
+	if mode == FoldNone || tb.IsLarge() || tb.NumLines() < 2 {
+		return nil
+	}
+	var folds []TextFold
+	switch mode {
+	case FoldIndent:
+		folds = tb.FoldsIndent()
+	case FoldBraces:
+		folds = tb.FoldsBraces()
+	case FoldParse:
+		folds = tb.FoldsParse()
+		if folds == nil {
+			folds = tb.FoldsBraces()
+		}
+	}
+	return folds
+}
+
+// FoldsIndent returns the fold regions found by indentation: each line
+// followed by lines indented more than it is, up to the last of them,
+// ignoring blank lines
+func (tb *TextBuf) FoldsIndent() []TextFold {
+	type indLine struct {
+		ln, ind int
+	}
+	var folds []TextFold
+	var stack []indLine
+	lstnb := -1 // last non-blank line
+	nln := tb.NumLines()
+	for ln := 0; ln <= nln; ln++ {
+		ind := -1 // closes all at end
+		if ln < nln {
+			if tb.lineFirstChar(ln) == 0 {
+				continue
+			}
+			ind, _ = tb.LineIndent(ln, tb.Opts.TabSize)
+		}
+		for len(stack) > 0 && stack[len(stack)-1].ind >= ind {
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if lstnb > top.ln {
+				folds = append(folds, TextFold{St: top.ln, Ed: lstnb})
+			}
+		}
+		stack = append(stack, indLine{ln, ind})
+		lstnb = ln
+	}
+	return tb.foldsSorted(folds)
+}
+
+// FoldsBraces returns the fold regions found by brace, paren and bracket
+// scopes, which are matched in the same way as in FindScopeMatch -- a
+// region ends before the line of its closing brace if that starts with it
+func (tb *TextBuf) FoldsBraces() []TextFold {
+	type openBrace struct {
+		r  rune
+		ln int
+	}
+	var folds []TextFold
+	var stack []openBrace
+	tb.LinesMu.RLock()
+	for ln := 0; ln < tb.NLines; ln++ {
+		for _, r := range tb.lineRunes(ln) {
+			match, right := PunctGpMatch(r)
+			if match == 0 {
+				continue
+			}
+			if !right {
+				stack = append(stack, openBrace{r, ln})
+				continue
+			}
+			if len(stack) == 0 || stack[len(stack)-1].r != match {
+				continue // unbalanced
+			}
+			st := stack[len(stack)-1].ln
+			stack = stack[:len(stack)-1]
+			if ln > st {
+				folds = append(folds, TextFold{St: st, Ed: ln})
+			}
+		}
+	}
+	tb.LinesMu.RUnlock()
+	return tb.foldsSorted(folds)
+}
+
+// FoldsParse returns the fold regions of the nodes of the GoPi parse tree
+// that span multiple lines -- nil if the text is not parsed by GoPi
+func (tb *TextBuf) FoldsParse() []TextFold {
+	if !tb.Hi.UsingPi() {
+		return nil
+	}
+	var folds []TextFold
+	tb.MarkupMu.RLock()
+	tb.PiState.Ast.FuncDownMeFirst(0, nil, func(k ki.Ki, level int, d interface{}) bool {
+		ast, ok := k.(*parse.Ast)
+		if !ok {
+			return true
+		}
+		if st, ed := ast.SrcReg.St.Ln, ast.SrcReg.Ed.Ln; ed > st {
+			folds = append(folds, TextFold{St: st, Ed: ed})
+		}
+		return true
+	})
+	tb.MarkupMu.RUnlock()
+	return tb.foldsSorted(folds)
+}
+
+// foldsSorted returns given fold regions in order of starting line,
+// ending them before the line of their closing brace if that starts with
+// it, and keeping just the largest one that starts on any given line
+func (tb *TextBuf) foldsSorted(folds []TextFold) []TextFold {
+	nln := tb.NumLines()
+	fs := folds[:0]
+	for _, f := range folds {
+		if f.Ed >= nln {
+			f.Ed = nln - 1
+		}
+		if _, right := PunctGpMatch(tb.lineFirstChar(f.Ed)); right {
+			f.Ed--
+		}
+		if f.Ed > f.St {
+			fs = append(fs, f)
+		}
+	}
+	sort.Slice(fs, func(i, j int) bool {
+		if fs[i].St == fs[j].St {
+			return fs[i].Ed > fs[j].Ed
+		}
+		return fs[i].St < fs[j].St
+	})
+	uf := fs[:0]
+	for i, f := range fs {
+		if i == 0 || f.St != uf[len(uf)-1].St {
+			uf = append(uf, f)
+		}
+	}
+	if len(uf) == 0 {
+		return nil
+	}
+	return uf
+}
+
+// lineFirstChar returns the first non-space character of given line -- 0
+// if it is blank
+func (tb *TextBuf) lineFirstChar(ln int) rune {
+	tb.LinesMu.RLock()
+	defer tb.LinesMu.RUnlock()
+	if ln < 0 || ln >= tb.NLines {
+		return 0
+	}
+	for _, r := range tb.lineRunes(ln) {
+		if !unicode.IsSpace(r) {
+			return r
+		}
+	}
+	return 0
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//  TextView folding
+
+// FoldsUpdate updates the Folds regions from the buffer, keeping those that
+// start on the same lines as before closed -- returns false if any closed
+// region is no longer there (and so has been opened)
+func (tv *TextView) FoldsUpdate() bool {
+	tv.foldsStale = false
+	var closed []int
+	for _, f := range tv.Folds {
+		if f.Closed {
+			closed = append(closed, f.St)
+		}
+	}
+	tv.Folds = nil
+	if tv.Buf != nil {
+		tv.Folds = tv.Buf.FoldRegions(tv.Buf.Opts.FoldMode)
+	}
+	nc := 0
+	for _, st := range closed {
+		if fi := tv.FoldIndex(st); fi >= 0 {
+			tv.Folds[fi].Closed = true
+			nc++
+		}
+	}
+	tv.foldsHidden()
+	return nc == len(closed)
+}
+
+// FoldsStale marks the Folds as out of date with the buffer, after it has
+// changed -- they are only updated right away if any are folded, and
+// otherwise when next needed (see FoldsNeeded), so that the buffer is not
+// scanned for them on every edit -- returns false if any folded region is
+// no longer there (see FoldsUpdate)
+func (tv *TextView) FoldsStale() bool {
+	for _, f := range tv.Folds {
+		if f.Closed {
+			return tv.FoldsUpdate()
+		}
+	}
+	tv.foldsStale = true
+	return true
+}
+
+// FoldsNeeded updates the Folds from the buffer if they are out of date --
+// call before using them, other than through the folded lines
+func (tv *TextView) FoldsNeeded() {
+	if tv.foldsStale {
+		tv.FoldsUpdate()
+	}
+}
+
+// FoldsEdited updates the Folds after given edit to the buffer, moving the
+// closed regions after it, and opening any that it changed -- returns true
+// if any were opened, so that all the lines need to be laid out again
+func (tv *TextView) FoldsEdited(tbe *TextBufEdit) bool {
+	st := tbe.Reg.Start.Ln
+	ed := tbe.Reg.End.Ln
+	dl := ed - st
+	opened := false
+	for i := range tv.Folds {
+		f := &tv.Folds[i]
+		if !f.Closed {
+			continue
+		}
+		switch {
+		case tbe.Delete && ed <= f.St:
+			f.St -= dl
+			f.Ed -= dl
+		case !tbe.Delete && st < f.St:
+			f.St += dl
+			f.Ed += dl
+		case st > f.Ed || (st == f.St && dl == 0):
+		default: // changed the hidden lines
+			f.Closed = false
+			opened = true
+		}
+	}
+	if !tv.FoldsStale() {
+		opened = true
+	}
+	return opened
+}
+
+// FoldIndex returns the index in Folds of the region that starts on given
+// line -- -1 if none
+func (tv *TextView) FoldIndex(ln int) int {
+	fi := sort.Search(len(tv.Folds), func(i int) bool { return tv.Folds[i].St >= ln })
+	if fi < len(tv.Folds) && tv.Folds[fi].St == ln {
+		return fi
+	}
+	return -1
+}
+
+// FoldIndexAt returns the index in Folds of the innermost region that
+// contains given line (starting on it, or with it hidden when folded) --
+// -1 if none
+func (tv *TextView) FoldIndexAt(ln int) int {
+	if fi := tv.FoldIndex(ln); fi >= 0 {
+		return fi
+	}
+	in := -1
+	for fi, f := range tv.Folds {
+		if f.St > ln {
+			break
+		}
+		if ln <= f.Ed {
+			in = fi // later ones are inside earlier ones
+		}
+	}
+	return in
+}
+
+// LineHidden returns true if given line is hidden in a folded region --
+// including the line where its placeholder is shown
+func (tv *TextView) LineHidden(ln int) bool {
+	return ln >= 0 && ln < len(tv.hiddenLines) && tv.hiddenLines[ln]
+}
+
+// LineFoldPlaceholder returns the folded region whose placeholder is shown
+// on given line, which is the first of its hidden lines -- false if none
+func (tv *TextView) LineFoldPlaceholder(ln int) (TextFold, bool) {
+	f, ok := tv.foldHolders[ln]
+	return f, ok
+}
+
+// VisibleLine returns given line if it is not hidden in a folded region,
+// and otherwise the nearest line that is not: after the region if dir is
+// positive and there is one, and otherwise the first line of the region
+func (tv *TextView) VisibleLine(ln, dir int) int {
+	if !tv.LineHidden(ln) {
+		return ln
+	}
+	for _, f := range tv.Folds {
+		if f.St > ln {
+			break
+		}
+		if !f.Closed || ln <= f.St || ln > f.Ed {
+			continue
+		}
+		if dir > 0 && f.Ed+1 < tv.NLines {
+			return tv.VisibleLine(f.Ed+1, dir)
+		}
+		return tv.VisibleLine(f.St, -1)
+	}
+	return ln
+}
+
+// FoldToggle folds the region that starts on given line, or the innermost
+// one that contains it, or unfolds it if already folded -- returns false
+// if there is no such region
+func (tv *TextView) FoldToggle(ln int) bool {
+	tv.FoldsNeeded()
+	fi := tv.FoldIndexAt(ln)
+	if fi < 0 {
+		return false
+	}
+	tv.Folds[fi].Closed = !tv.Folds[fi].Closed
+	tv.FoldsChanged()
+	return true
+}
+
+// FoldAll folds all the regions that can be folded
+func (tv *TextView) FoldAll() {
+	tv.FoldsUpdate()
+	for i := range tv.Folds {
+		tv.Folds[i].Closed = true
+	}
+	tv.FoldsChanged()
+}
+
+// UnfoldAll unfolds all the folded regions
+func (tv *TextView) UnfoldAll() {
+	for i := range tv.Folds {
+		tv.Folds[i].Closed = false
+	}
+	tv.FoldsChanged()
+}
+
+// UnfoldLine unfolds all the regions in which given line is hidden
+func (tv *TextView) UnfoldLine(ln int) {
+	if !tv.LineHidden(ln) {
+		return
+	}
+	for i := range tv.Folds {
+		f := &tv.Folds[i]
+		if f.Closed && ln > f.St && ln <= f.Ed {
+			f.Closed = false
+		}
+	}
+	tv.FoldsChanged()
+}
+
+// FoldsChanged updates the display after folding or unfolding regions,
+// moving the cursor out of any that are now folded
+func (tv *TextView) FoldsChanged() {
+	tv.foldsHidden()
+	if tv.Offs != nil {
+		off := float32(0)
+		for ln := 0; ln < tv.NLines; ln++ {
+			tv.Offs[ln] = off
+			off += tv.lineSize(ln)
+		}
+		extraHalf := tv.LineHeight * 0.5 * float32(tv.VisSize.Y)
+		tv.ResizeIfNeeded(gi.Vec2D{float32(tv.LinesSize.X), off + extraHalf}.ToPointCeil())
+	}
+	if tv.LineHidden(tv.CursorPos.Ln) {
+		ln := tv.VisibleLine(tv.CursorPos.Ln, -1)
+		tv.CursorPos = TextPos{Ln: ln, Ch: tv.Buf.LineLen(ln)}
+	}
+	if tv.Viewport == nil || tv.Viewport.Win == nil {
+		return
+	}
+	updt := tv.Viewport.Win.UpdateStart()
+	tv.RenderAllLines()
+	tv.SetCursorShow(tv.CursorPos)
+	tv.Viewport.Win.UpdateEnd(updt)
+}
+
+// foldsHidden records the lines that are hidden in folded regions, and the
+// placeholder line of each outermost one
+func (tv *TextView) foldsHidden() {
+	tv.hiddenLines = nil
+	tv.foldHolders = nil
+	for _, f := range tv.Folds {
+		if !f.Closed || tv.LineHidden(f.St) {
+			continue // within another folded region
+		}
+		if tv.hiddenLines == nil {
+			tv.hiddenLines = make([]bool, tv.Buf.NumLines())
+			tv.foldHolders = make(map[int]TextFold)
+		}
+		if f.Ed >= len(tv.hiddenLines) {
+			continue
+		}
+		for ln := f.St + 1; ln <= f.Ed; ln++ {
+			tv.hiddenLines[ln] = true
+		}
+		tv.foldHolders[f.St+1] = f
+	}
+}
+
+// lineSize returns the height of given line as laid out, which is zero for
+// lines hidden in folded regions, other than their placeholder line
+func (tv *TextView) lineSize(ln int) float32 {
+	if tv.LineHidden(ln) {
+		if _, ok := tv.foldHolders[ln]; ok {
+			return tv.LineHeight
+		}
+		return 0
+	}
+	return gi.Max32(tv.LineRender(ln).Size.Y, tv.LineHeight)
+}
+
+// RenderFoldPlaceholder renders the placeholder shown for the folded region
+// on given line, if any -- called within context of outer RenderLines or
+// RenderAllLines
+func (tv *TextView) RenderFoldPlaceholder(ln int) {
+	f, ok := tv.LineFoldPlaceholder(ln)
+	if !ok {
+		return
+	}
+	sty := &tv.StateStyles[TextViewHighlight]
+	fst := sty.Font
+	fst.BgColor.SetColor(nil)
+	rs := &tv.Viewport.Render
+	var rn gi.TextRender
+	rn.SetString(fmt.Sprintf("... %d lines", f.NLines()), &fst, &sty.UnContext, &sty.Text, true, 0, 0)
+	ich := 0
+	for _, r := range tv.Buf.Line(f.St) {
+		if !unicode.IsSpace(r) {
+			break
+		}
+		ich++
+	}
+	pos := tv.CharStartPos(TextPos{Ln: f.St, Ch: ich})
+	pos.Y = tv.CharStartPos(TextPos{Ln: ln}).Y + gi.FixedToFloat32(tv.Sty.Font.Face.Metrics().Ascent) - gi.FixedToFloat32(tv.Sty.Font.Face.Metrics().Descent)
+	rn.Render(rs, pos)
+}
+
+// FoldMarker returns the marker shown next to the line number of given
+// line: "+" if it starts a folded region, "-" if it starts one that can be
+// folded, and " " otherwise
+func (tv *TextView) FoldMarker(ln int) string {
+	tv.FoldsNeeded()
+	fi := tv.FoldIndex(ln)
+	switch {
+	case fi < 0:
+		return " "
+	case tv.Folds[fi].Closed:
+		return "+"
+	}
+	return "-"
+}
@@ -0,0 +1,25 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd
+
+package giv
+
+import (
+	"io/ioutil"
+)
+
+// TextMapSupported is true if files can be memory-mapped on this platform
+const TextMapSupported = false
+
+// MapFile reads given file, as it cannot be memory-mapped on this platform,
+// returning its contents and a function that does nothing, for
+// compatibility with platforms where it is mapped
+func MapFile(filename string) ([]byte, func() error, error) {
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, nil, err
+	}
+	return b, func() error { return nil }, nil
+}
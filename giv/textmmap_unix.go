@@ -0,0 +1,39 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build darwin dragonfly freebsd linux netbsd openbsd
+
+package giv
+
+import (
+	"os"
+	"syscall"
+)
+
+// TextMapSupported is true if files can be memory-mapped on this platform
+const TextMapSupported = true
+
+// MapFile memory-maps given file read-only, returning its contents and a
+// function to unmap it, which must be called when the contents are no longer
+// used -- the file must not be truncated while it is mapped
+func MapFile(filename string) ([]byte, func() error, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, nil, err
+	}
+	sz := fi.Size()
+	if sz == 0 {
+		return nil, func() error { return nil }, nil
+	}
+	b, err := syscall.Mmap(int(f.Fd()), 0, int(sz), syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, nil, err
+	}
+	return b, func() error { return syscall.Munmap(b) }, nil
+}
@@ -0,0 +1,265 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import (
+	"bytes"
+	"io"
+	"sort"
+
+	"github.com/goki/ki/ints"
+)
+
+// TextPiece is one piece of the text in a TextPieces piece table: a range of
+// either its original or its added text
+type TextPiece struct {
+	Added bool `desc:"if true, the piece is in the added text, else in the original text"`
+	Off   int  `desc:"starting byte offset of the piece within its text"`
+	Len   int  `desc:"length of the piece in bytes"`
+	NLfs  int  `desc:"number of line feeds in the piece"`
+}
+
+// TextPieces is a piece table holding the text of a large TextBuf: the
+// original text is never modified, so it can be memory-mapped from a file,
+// and all edits are appended to a separate added text, with the current text
+// being the sequence of Pieces of the two.  Lines are found through the
+// offsets of the line feeds in each text, so nothing is stored per line, and
+// the text always ends with a line feed, so that the number of lines is the
+// number of line feeds.  All offsets are in bytes.  It is not safe for
+// concurrent use -- TextBuf protects it with its LinesMu mutex.
+type TextPieces struct {
+	Orig    []byte      `desc:"original text, which is never modified"`
+	Add     []byte      `desc:"text added by edits, which is only ever appended to"`
+	Pieces  []TextPiece `desc:"pieces of the original and added text that make up the current text, in order"`
+	MaxLen  int         `desc:"length in bytes of the longest line in the original text, or of any line since edited, if longer"`
+	origLfs []int       // offsets of line feeds in Orig
+	addLfs  []int       // offsets of line feeds in Add
+	cumLen  []int       // total length of pieces before each piece, with overall length at end
+	cumLfs  []int       // total number of line feeds before each piece, with overall number at end
+}
+
+// NewTextPieces returns a new piece table for given original text, which is
+// used directly, not copied, and must not be modified after
+func NewTextPieces(orig []byte) *TextPieces {
+	tp := &TextPieces{Orig: orig}
+	tp.origLfs = LineFeeds(orig, 0, nil)
+	lst := -1
+	for _, lf := range tp.origLfs {
+		tp.MaxLen = ints.MaxInt(tp.MaxLen, lf-lst-1)
+		lst = lf
+	}
+	tp.MaxLen = ints.MaxInt(tp.MaxLen, len(orig)-lst-1)
+	if len(orig) > 0 {
+		tp.Pieces = append(tp.Pieces, tp.newPiece(false, 0, len(orig)))
+	}
+	tp.updateCum()
+	if len(orig) == 0 || orig[len(orig)-1] != '\n' {
+		tp.Insert(len(orig), []byte("\n"))
+	}
+	return tp
+}
+
+// LineFeeds appends the offsets of the line feeds in given text to lfs, with
+// given offset added to each, and returns the result
+func LineFeeds(txt []byte, off int, lfs []int) []int {
+	st := 0
+	for {
+		i := bytes.IndexByte(txt[st:], '\n')
+		if i < 0 {
+			return lfs
+		}
+		lfs = append(lfs, off+st+i)
+		st += i + 1
+	}
+}
+
+// Len returns the total length of the text in bytes
+func (tp *TextPieces) Len() int {
+	return tp.cumLen[len(tp.Pieces)]
+}
+
+// NumLines returns the number of lines in the text
+func (tp *TextPieces) NumLines() int {
+	return tp.cumLfs[len(tp.Pieces)]
+}
+
+// LineStart returns the byte offset of the start of given line -- the
+// length of the text for the line after the last one
+func (tp *TextPieces) LineStart(ln int) int {
+	if ln <= 0 {
+		return 0
+	}
+	if ln >= tp.NumLines() {
+		return tp.Len()
+	}
+	// the start of line ln is just after the ln'th line feed
+	pi := sort.Search(len(tp.Pieces), func(i int) bool { return tp.cumLfs[i+1] >= ln })
+	p := tp.Pieces[pi]
+	lfs := tp.lfs(p.Added)
+	li := sort.SearchInts(lfs, p.Off) + (ln - tp.cumLfs[pi]) - 1
+	return tp.cumLen[pi] + lfs[li] - p.Off + 1
+}
+
+// LineBytes returns the bytes of given line, without its line feed -- this
+// is a slice of the stored text where possible, so it must not be modified
+func (tp *TextPieces) LineBytes(ln int) []byte {
+	if ln < 0 || ln >= tp.NumLines() {
+		return nil
+	}
+	return tp.Range(tp.LineStart(ln), tp.LineStart(ln+1)-1)
+}
+
+// Range returns the bytes of the text between given start and end offsets
+// -- this is a slice of the stored text if they are within a single piece,
+// so it must not be modified
+func (tp *TextPieces) Range(st, ed int) []byte {
+	ed = ints.MinInt(ed, tp.Len())
+	if ed <= st {
+		return nil
+	}
+	pi := tp.pieceAt(st)
+	if ed <= tp.cumLen[pi+1] {
+		p := tp.Pieces[pi]
+		po := p.Off + st - tp.cumLen[pi]
+		return tp.text(p.Added)[po : po+ed-st : po+ed-st]
+	}
+	b := make([]byte, 0, ed-st)
+	for ; pi < len(tp.Pieces) && tp.cumLen[pi] < ed; pi++ {
+		p := tp.Pieces[pi]
+		pst := ints.MaxInt(st-tp.cumLen[pi], 0)
+		ped := ints.MinInt(ed-tp.cumLen[pi], p.Len)
+		b = append(b, tp.text(p.Added)[p.Off+pst:p.Off+ped]...)
+	}
+	return b
+}
+
+// Insert inserts given text at given byte offset
+func (tp *TextPieces) Insert(off int, txt []byte) {
+	if len(txt) == 0 {
+		return
+	}
+	ao := len(tp.Add)
+	tp.addLfs = LineFeeds(txt, ao, tp.addLfs)
+	tp.Add = append(tp.Add, txt...)
+	pi := tp.split(off)
+	if pi > 0 {
+		// typing extends the last added piece rather than adding new ones
+		pp := &tp.Pieces[pi-1]
+		if pp.Added && pp.Off+pp.Len == ao {
+			*pp = tp.newPiece(true, pp.Off, pp.Len+len(txt))
+			tp.updateCum()
+			return
+		}
+	}
+	tp.Pieces = append(tp.Pieces, TextPiece{})
+	copy(tp.Pieces[pi+1:], tp.Pieces[pi:])
+	tp.Pieces[pi] = tp.newPiece(true, ao, len(txt))
+	tp.updateCum()
+}
+
+// Delete deletes the text between given start and end byte offsets
+func (tp *TextPieces) Delete(st, ed int) {
+	if ed <= st {
+		return
+	}
+	si := tp.split(st)
+	ei := tp.split(ed)
+	tp.Pieces = append(tp.Pieces[:si], tp.Pieces[ei:]...)
+	tp.updateCum()
+}
+
+// IsOrig returns true if the text is still just the original text
+func (tp *TextPieces) IsOrig() bool {
+	return len(tp.Add) == 0 && (len(tp.Pieces) == 0 || (len(tp.Pieces) == 1 && tp.Pieces[0].Len == len(tp.Orig)))
+}
+
+// Bytes returns a copy of the entire text, or the original text itself if
+// there have not been any edits
+func (tp *TextPieces) Bytes() []byte {
+	if tp.IsOrig() {
+		return tp.Orig
+	}
+	return tp.Range(0, tp.Len())
+}
+
+// WriteTo writes the entire text to given writer, piece by piece, without
+// copying it
+func (tp *TextPieces) WriteTo(w io.Writer) (int64, error) {
+	var n int64
+	for _, p := range tp.Pieces {
+		pn, err := w.Write(tp.text(p.Added)[p.Off : p.Off+p.Len])
+		n += int64(pn)
+		if err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+// text returns the original or added text
+func (tp *TextPieces) text(added bool) []byte {
+	if added {
+		return tp.Add
+	}
+	return tp.Orig
+}
+
+// lfs returns the line feed offsets of the original or added text
+func (tp *TextPieces) lfs(added bool) []int {
+	if added {
+		return tp.addLfs
+	}
+	return tp.origLfs
+}
+
+// newPiece returns a new piece for given range of the original or added
+// text, counting its line feeds
+func (tp *TextPieces) newPiece(added bool, off, ln int) TextPiece {
+	lfs := tp.lfs(added)
+	nlf := sort.SearchInts(lfs, off+ln) - sort.SearchInts(lfs, off)
+	return TextPiece{Added: added, Off: off, Len: ln, NLfs: nlf}
+}
+
+// pieceAt returns the index of the piece containing given byte offset
+func (tp *TextPieces) pieceAt(off int) int {
+	return sort.Search(len(tp.Pieces), func(i int) bool { return tp.cumLen[i+1] > off })
+}
+
+// split splits the piece containing given byte offset so that a piece
+// starts there, and returns the index of that piece
+func (tp *TextPieces) split(off int) int {
+	if off >= tp.Len() {
+		return len(tp.Pieces)
+	}
+	pi := tp.pieceAt(off)
+	po := off - tp.cumLen[pi]
+	if po == 0 {
+		return pi
+	}
+	p := tp.Pieces[pi]
+	tp.Pieces = append(tp.Pieces, TextPiece{})
+	copy(tp.Pieces[pi+2:], tp.Pieces[pi+1:])
+	tp.Pieces[pi] = tp.newPiece(p.Added, p.Off, po)
+	tp.Pieces[pi+1] = tp.newPiece(p.Added, p.Off+po, p.Len-po)
+	tp.updateCum()
+	return pi + 1
+}
+
+// updateCum updates the cumulative lengths and line feed counts of the
+// pieces, after they have changed
+func (tp *TextPieces) updateCum() {
+	np := len(tp.Pieces)
+	if cap(tp.cumLen) > np {
+		tp.cumLen = tp.cumLen[:np+1]
+		tp.cumLfs = tp.cumLfs[:np+1]
+	} else {
+		tp.cumLen = make([]int, np+1)
+		tp.cumLfs = make([]int, np+1)
+	}
+	for i, p := range tp.Pieces {
+		tp.cumLen[i+1] = tp.cumLen[i] + p.Len
+		tp.cumLfs[i+1] = tp.cumLfs[i] + p.NLfs
+	}
+}
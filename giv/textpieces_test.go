@@ -0,0 +1,167 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+// tpEdit is an edit of a TextPieces: an insert of txt at st if ins, and
+// otherwise a delete of st to ed
+type tpEdit struct {
+	ins    bool
+	st, ed int
+	txt    string
+}
+
+// apply applies the edit to both the piece table and the model text,
+// returning the new model
+func (ed tpEdit) apply(tp *TextPieces, model []byte) []byte {
+	if ed.ins {
+		tp.Insert(ed.st, []byte(ed.txt))
+		nm := append([]byte{}, model[:ed.st]...)
+		nm = append(nm, ed.txt...)
+		return append(nm, model[ed.st:]...)
+	}
+	tp.Delete(ed.st, ed.ed)
+	return append(append([]byte{}, model[:ed.st]...), model[ed.ed:]...)
+}
+
+// testPiecesModel checks all of the ways of getting the text of the piece
+// table against the model text, which must end in a line feed
+func testPiecesModel(t *testing.T, tp *TextPieces, model []byte, desc string) {
+	t.Helper()
+	if got := tp.Bytes(); !bytes.Equal(got, model) {
+		t.Fatalf("%v: Bytes() = %q, want: %q", desc, got, model)
+	}
+	if tp.Len() != len(model) {
+		t.Errorf("%v: Len() = %v, want: %v", desc, tp.Len(), len(model))
+	}
+	var wb bytes.Buffer
+	if n, err := tp.WriteTo(&wb); err != nil || n != int64(len(model)) || !bytes.Equal(wb.Bytes(), model) {
+		t.Errorf("%v: WriteTo() = %v, %v: %q, want: %q", desc, n, err, wb.Bytes(), model)
+	}
+	lines := bytes.Split(model[:len(model)-1], []byte("\n"))
+	if tp.NumLines() != len(lines) {
+		t.Fatalf("%v: NumLines() = %v, want: %v", desc, tp.NumLines(), len(lines))
+	}
+	st := 0
+	for ln, lb := range lines {
+		if got := tp.LineStart(ln); got != st {
+			t.Errorf("%v: LineStart(%v) = %v, want: %v", desc, ln, got, st)
+		}
+		if got := tp.LineBytes(ln); !bytes.Equal(got, lb) {
+			t.Errorf("%v: LineBytes(%v) = %q, want: %q", desc, ln, got, lb)
+		}
+		st += len(lb) + 1
+	}
+	if got := tp.LineStart(len(lines)); got != len(model) {
+		t.Errorf("%v: LineStart past the end = %v, want: %v", desc, got, len(model))
+	}
+	if got := tp.LineBytes(len(lines)); got != nil {
+		t.Errorf("%v: LineBytes past the end = %q, want: nil", desc, got)
+	}
+	for rs := 0; rs <= len(model); rs++ {
+		for re := rs; re <= len(model)+1; re++ {
+			want := model[rs:minInt(re, len(model))]
+			if got := tp.Range(rs, re); !bytes.Equal(got, want) {
+				t.Errorf("%v: Range(%v, %v) = %q, want: %q", desc, rs, re, got, want)
+			}
+		}
+	}
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func TestTextPiecesNew(t *testing.T) {
+	tests := []struct {
+		orig, want string
+		maxLen     int
+	}{
+		{"", "\n", 0},
+		{"abc", "abc\n", 3},
+		{"abc\n", "abc\n", 3},
+		{"a\nbcde\n\nfg", "a\nbcde\n\nfg\n", 4},
+	}
+	for _, tst := range tests {
+		tp := NewTextPieces([]byte(tst.orig))
+		testPiecesModel(t, tp, []byte(tst.want), "new "+tst.orig)
+		if tp.MaxLen != tst.maxLen {
+			t.Errorf("NewTextPieces(%q) MaxLen = %v, want: %v", tst.orig, tp.MaxLen, tst.maxLen)
+		}
+		if tp.IsOrig() != (tst.orig == tst.want) {
+			t.Errorf("NewTextPieces(%q) IsOrig = %v", tst.orig, tp.IsOrig())
+		}
+	}
+}
+
+func TestTextPiecesEdits(t *testing.T) {
+	orig := "abc\ndef\nghi\n"
+	tests := []struct {
+		name  string
+		edits []tpEdit
+	}{
+		{"insert in piece", []tpEdit{{true, 5, 0, "XY\nZ"}}},
+		{"insert at start and end", []tpEdit{{true, 0, 0, "<<\n"}, {true, 14, 0, ">>"}, {true, 17, 0, "\nend\n"}}},
+		{"insert at piece boundaries", []tpEdit{{true, 4, 0, "12"}, {true, 6, 0, "34"}, {true, 4, 0, "\n"}, {true, 7, 0, "5\n6"}}},
+		{"insert into inserted", []tpEdit{{true, 8, 0, "hello\nworld"}, {true, 11, 0, "--\n--"}, {true, 9, 0, "!"}}},
+		{"delete in piece", []tpEdit{{false, 5, 6, ""}, {false, 0, 2, ""}}},
+		{"delete line feeds", []tpEdit{{false, 3, 4, ""}, {false, 6, 7, ""}}},
+		{"delete across pieces", []tpEdit{{true, 5, 0, "XY\nZ"}, {true, 12, 0, "Q\n"}, {false, 2, 14, ""}}},
+		{"delete whole pieces", []tpEdit{{true, 4, 0, "new\n"}, {false, 4, 8, ""}, {true, 8, 0, "more"}, {false, 8, 12, ""}}},
+		{"delete all but last line feed", []tpEdit{{true, 6, 0, "x\ny"}, {false, 0, 14, ""}, {true, 0, 0, "fresh\ntext"}}},
+		{"interleaved", []tpEdit{
+			{true, 2, 0, "1\n2"}, {false, 1, 4, ""}, {true, 9, 0, "333"}, {false, 8, 11, ""},
+			{true, 0, 0, "\n"}, {false, 5, 9, ""}, {true, 5, 0, "abc\ndef"}, {false, 3, 12, ""},
+		}},
+	}
+	for _, tst := range tests {
+		tp := NewTextPieces([]byte(orig))
+		model := []byte(orig)
+		for i, ed := range tst.edits {
+			model = ed.apply(tp, model)
+			testPiecesModel(t, tp, model, fmt.Sprintf("%v edit %v", tst.name, i))
+		}
+	}
+}
+
+func TestTextPiecesRandom(t *testing.T) {
+	rnd := rand.New(rand.NewSource(42))
+	chars := []byte("ab\nc")
+	randText := func(n int) string {
+		b := make([]byte, n)
+		for i := range b {
+			b[i] = chars[rnd.Intn(len(chars))]
+		}
+		return string(b)
+	}
+	orig := randText(40) + "\n"
+	tp := NewTextPieces([]byte(orig))
+	model := []byte(orig)
+	for i := 0; i < 300; i++ {
+		var ed tpEdit
+		if rnd.Intn(2) == 0 || len(model) < 10 {
+			ed = tpEdit{ins: true, st: rnd.Intn(len(model)), txt: randText(1 + rnd.Intn(6))}
+		} else {
+			st := rnd.Intn(len(model) - 1)
+			ed = tpEdit{st: st, ed: st + 1 + rnd.Intn(minInt(8, len(model)-1-st))}
+		}
+		model = ed.apply(tp, model)
+		if i%10 == 0 {
+			testPiecesModel(t, tp, model, "random")
+		} else if got := tp.Bytes(); !bytes.Equal(got, model) {
+			t.Fatalf("random edit %v %+v: Bytes() = %q, want: %q", i, ed, got, model)
+		}
+	}
+	testPiecesModel(t, tp, model, "random")
+}
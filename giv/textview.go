@@ -45,8 +45,8 @@ type TextView struct {
 	CursorWidth    units.Value               `xml:"cursor-width" desc:"width of cursor -- set from cursor-width property (inherited)"`
 	LineIcons      map[int]gi.IconName       `desc:"icons for each line -- use SetLineIcon and DeleteLineIcon"`
 	NLines         int                       `json:"-" xml:"-" desc:"number of lines in the view -- sync'd with the Buf after edits, but always reflects storage size of Renders etc"`
-	Renders        []gi.TextRender           `json:"-" xml:"-" desc:"renders of the text lines, with one render per line (each line could visibly wrap-around, so these are logical lines, not display lines)"`
-	Offs           []float32                 `json:"-" xml:"-" desc:"starting offsets for top of each line"`
+	Renders        []gi.TextRender           `json:"-" xml:"-" desc:"renders of the text lines, with one render per line (each line could visibly wrap-around, so these are logical lines, not display lines) -- nil for large buffers -- use LineRender"`
+	Offs           []float32                 `json:"-" xml:"-" desc:"starting offsets for top of each line -- nil for large buffers -- use LineOff"`
 	LineNoDigs     int                       `json:"-" xml:"-" desc:"number of line number digits needed"`
 	LineNoOff      float32                   `json:"-" xml:"-" desc:"horizontal offset for start of text after line numbers"`
 	LineNoRender   gi.TextRender             `json:"-" xml:"-" desc:"render for line numbers"`
@@ -62,6 +62,9 @@ type TextView struct {
 	Highlights     []TextRegion              `json:"-" xml:"-" desc:"highlighted regions, e.g., for search results"`
 	Scopelights    []TextRegion              `json:"-" xml:"-" desc:"highlighted regions, specific to scope markers"`
 	SelectMode     bool                      `json:"-" xml:"-" desc:"if true, select text as cursor moves"`
+	RectSelect     bool                      `json:"-" xml:"-" desc:"if true, the selection is a rectangular block of text, between the columns of its start and end on each of its lines"`
+	Cursors        []TextRegion              `json:"-" xml:"-" desc:"extra cursors, at which all edits made at CursorPos are also made -- each cursor is at the End of its region, and the text from its Start, if any, is selected -- see EditAtCursors"`
+	Folds          []TextFold                `json:"-" xml:"-" desc:"regions of lines that can be folded, in order of their first lines, from the buffer according to its Opts.FoldMode -- see FoldToggle"`
 	ForceComplete  bool                      `json:"-" xml:"-" desc:"if true, complete regardless of any disqualifying reasons"`
 	ISearch        ISearch                   `json:"-" xml:"-" desc:"interactive search data"`
 	QReplace       QReplace                  `json:"-" xml:"-" desc:"query replace data"`
@@ -77,6 +80,10 @@ type TextView struct {
 	lastRecenter   int
 	lastAutoInsert rune
 	lastFilename   gi.FileName
+	lineRenders    map[int]*gi.TextRender
+	hiddenLines    []bool
+	foldHolders    map[int]TextFold
+	foldsStale     bool
 }
 
 var KiT_TextView = kit.Types.AddType(&TextView{}, TextViewProps)
@@ -287,11 +294,15 @@ func (tv *TextView) Clear() {
 // ResetState resets all the random state variables, when opening a new buffer etc
 func (tv *TextView) ResetState() {
 	tv.SelectReset()
+	tv.Cursors = nil
 	tv.Highlights = nil
 	tv.ISearch.On = false
 	tv.QReplace.On = false
 	if tv.Buf == nil || tv.lastFilename != tv.Buf.Filename { // don't reset if reopening..
 		tv.CursorPos = TextPos{}
+		tv.Folds = nil
+		tv.foldsStale = true
+		tv.foldsHidden()
 	}
 }
 
@@ -309,6 +320,9 @@ func (tv *TextView) SetBuf(buf *TextBuf) {
 	tv.ResetState()
 	if buf != nil {
 		buf.AddView(tv)
+		if buf.IsReadOnly() {
+			tv.SetInactive()
+		}
 		bhl := len(buf.PosHistory)
 		if bhl > 0 {
 			tv.CursorPos = buf.PosHistory[bhl-1]
@@ -323,6 +337,11 @@ func (tv *TextView) SetBuf(buf *TextBuf) {
 
 // LinesInserted inserts new lines of text and reformats them
 func (tv *TextView) LinesInserted(tbe *TextBufEdit) {
+	if tv.lineRenders != nil {
+		tv.LayoutAllLines(false)
+		tv.RenderAllLines()
+		return
+	}
 	stln := tbe.Reg.Start.Ln + 1
 	nsz := (tbe.Reg.End.Ln - tbe.Reg.Start.Ln)
 
@@ -348,6 +367,11 @@ func (tv *TextView) LinesInserted(tbe *TextBufEdit) {
 
 // LinesDeleted deletes lines of text and reformats remaining one
 func (tv *TextView) LinesDeleted(tbe *TextBufEdit) {
+	if tv.lineRenders != nil {
+		tv.LayoutAllLines(false)
+		tv.RenderAllLines()
+		return
+	}
 	stln := tbe.Reg.Start.Ln
 	edln := tbe.Reg.End.Ln
 	dsz := edln - stln
@@ -371,13 +395,22 @@ func TextViewBufSigRecv(rvwki, sbufki ki.Ki, sig int64, data interface{}) {
 	case TextBufDone:
 	case TextBufNew:
 		tv.ResetState()
+		if tv.Buf.IsReadOnly() {
+			tv.SetInactive()
+		}
 		tv.Refresh()
 		tv.SetCursorShow(tv.CursorPos)
 	case TextBufInsert:
-		if tv.Renders == nil { // not init yet
+		if tv.Renders == nil && tv.lineRenders == nil { // not init yet
 			return
 		}
 		tbe := data.(*TextBufEdit)
+		tv.AdjustCursors(tbe)
+		if tv.FoldsEdited(tbe) {
+			tv.LayoutAllLines(false)
+			tv.RenderAllLines()
+			return
+		}
 		// fmt.Printf("tv %v got %v\n", tv.Nm, tbe.Reg.Start)
 		if tbe.Reg.Start.Ln != tbe.Reg.End.Ln {
 			// fmt.Printf("tv %v lines insert %v - %v\n", tv.Nm, tbe.Reg.Start, tbe.Reg.End)
@@ -393,10 +426,16 @@ func TextViewBufSigRecv(rvwki, sbufki ki.Ki, sig int64, data interface{}) {
 			}
 		}
 	case TextBufDelete:
-		if tv.Renders == nil { // not init yet
+		if tv.Renders == nil && tv.lineRenders == nil { // not init yet
 			return
 		}
 		tbe := data.(*TextBufEdit)
+		tv.AdjustCursors(tbe)
+		if tv.FoldsEdited(tbe) {
+			tv.LayoutAllLines(false)
+			tv.RenderAllLines()
+			return
+		}
 		if tbe.Reg.Start.Ln != tbe.Reg.End.Ln {
 			tv.LinesDeleted(tbe)
 		} else {
@@ -492,8 +531,14 @@ func (tv *TextView) LayoutAllLines(inLayout bool) bool {
 	tv.HiStyle()
 	// fmt.Printf("layout all: %v\n", tv.Nm)
 
+	if tv.Buf.IsLarge() {
+		return tv.LayoutLargeLines(inLayout)
+	}
+	tv.lineRenders = nil
+
 	tv.NLines = tv.Buf.NumLines()
 	nln := tv.NLines
+	tv.FoldsStale()
 	if cap(tv.Renders) >= nln {
 		tv.Renders = tv.Renders[:nln]
 	} else {
@@ -518,14 +563,13 @@ func (tv *TextView) LayoutAllLines(inLayout bool) bool {
 	tv.Buf.MarkupMu.RLock()
 	tv.HasLinks = false
 	for ln := 0; ln < nln; ln++ {
-		tv.Renders[ln].SetHTMLPre(tv.Buf.Markup[ln], &fst, &sty.Text, &sty.UnContext, tv.CSS)
+		tv.Renders[ln].SetHTMLPre(tv.Buf.LineMarkup(ln), &fst, &sty.Text, &sty.UnContext, tv.CSS)
 		tv.Renders[ln].LayoutStdLR(&sty.Text, &sty.Font, &sty.UnContext, sz)
 		if !tv.HasLinks && len(tv.Renders[ln].Links) > 0 {
 			tv.HasLinks = true
 		}
 		tv.Offs[ln] = off
-		lsz := gi.Max32(tv.Renders[ln].Size.Y, tv.LineHeight)
-		off += lsz
+		off += tv.lineSize(ln)
 		mxwd = gi.Max32(mxwd, tv.Renders[ln].Size.X)
 	}
 	tv.Buf.MarkupMu.RUnlock()
@@ -601,6 +645,13 @@ func (tv *TextView) LayoutLines(st, ed int, isDel bool) bool {
 	if tv.Buf == nil || tv.Buf.NumLines() == 0 {
 		return false
 	}
+	if tv.lineRenders != nil {
+		for ln := st; ln <= ed; ln++ {
+			delete(tv.lineRenders, ln)
+		}
+		tv.ResizeIfNeeded(tv.LargeLinesSize())
+		return false
+	}
 	sty := &tv.Sty
 	fst := sty.Font
 	fst.BgColor.SetColor(nil)
@@ -610,7 +661,7 @@ func (tv *TextView) LayoutLines(st, ed int, isDel bool) bool {
 	tv.Buf.MarkupMu.RLock()
 	for ln := st; ln <= ed; ln++ {
 		curspans := len(tv.Renders[ln].Spans)
-		tv.Renders[ln].SetHTMLPre(tv.Buf.Markup[ln], &fst, &sty.Text, &sty.UnContext, tv.CSS)
+		tv.Renders[ln].SetHTMLPre(tv.Buf.LineMarkup(ln), &fst, &sty.Text, &sty.UnContext, tv.CSS)
 		tv.Renders[ln].LayoutStdLR(&sty.Text, &sty.Font, &sty.UnContext, tv.RenderSz)
 		if !tv.HasLinks && len(tv.Renders[ln].Links) > 0 {
 			tv.HasLinks = true
@@ -632,8 +683,7 @@ func (tv *TextView) LayoutLines(st, ed int, isDel bool) bool {
 		off := tv.Offs[ofst]
 		for ln := ofst; ln < tv.NLines; ln++ {
 			tv.Offs[ln] = off
-			lsz := gi.Max32(tv.Renders[ln].Size.Y, tv.LineHeight)
-			off += lsz
+			off += tv.lineSize(ln)
 		}
 		extraHalf := tv.LineHeight * 0.5 * float32(tv.VisSize.Y)
 		nwSz := gi.Vec2D{mxwd, off + extraHalf}.ToPointCeil()
@@ -646,6 +696,96 @@ func (tv *TextView) LayoutLines(st, ed int, isDel bool) bool {
 	return rerend
 }
 
+// LineRender returns the render of given line -- for large buffers, lines
+// are only laid out when first used, and freed when no longer visible
+func (tv *TextView) LineRender(ln int) *gi.TextRender {
+	if tv.lineRenders == nil {
+		return &tv.Renders[ln]
+	}
+	if rn, ok := tv.lineRenders[ln]; ok {
+		return rn
+	}
+	rn := &gi.TextRender{}
+	sty := &tv.Sty
+	fst := sty.Font
+	fst.BgColor.SetColor(nil)
+	tst := sty.Text
+	tst.WhiteSpace = gi.WhiteSpacePre // no wrapping, so all lines are LineHeight high
+	rn.SetHTMLPre(tv.Buf.LineMarkup(ln), &fst, &tst, &sty.UnContext, tv.CSS)
+	rn.LayoutStdLR(&tst, &sty.Font, &sty.UnContext, tv.RenderSz)
+	if len(rn.Links) > 0 {
+		tv.HasLinks = true
+	}
+	tv.lineRenders[ln] = rn
+	return rn
+}
+
+// LineOff returns the starting offset for the top of given line
+func (tv *TextView) LineOff(ln int) float32 {
+	if tv.lineRenders == nil {
+		return tv.Offs[ln]
+	}
+	return float32(ln) * tv.LineHeight
+}
+
+// LayoutLargeLines is the version of LayoutAllLines for large buffers,
+// which does not lay out any lines until they are rendered -- lines are not
+// wrapped, so they are all LineHeight high
+func (tv *TextView) LayoutLargeLines(inLayout bool) bool {
+	tv.NLines = tv.Buf.NumLines()
+	tv.Renders = nil
+	tv.Offs = nil
+	tv.FoldsStale()
+	tv.lineRenders = make(map[int]*gi.TextRender)
+	tv.HasLinks = false
+	tv.VisSizes()
+	nwSz := tv.LargeLinesSize()
+	if inLayout {
+		tv.LinesSize = nwSz
+		return tv.SetSize()
+	}
+	return tv.ResizeIfNeeded(nwSz)
+}
+
+// LargeLinesSize returns the size of all the lines of a large buffer, from
+// the number of lines and the length of its longest line
+func (tv *TextView) LargeLinesSize() image.Point {
+	mxwd := tv.RenderSz.X
+	tv.Buf.LinesMu.RLock()
+	if tv.Buf.Pieces != nil {
+		mxwd = gi.Max32(mxwd, float32(tv.Buf.Pieces.MaxLen+1)*tv.Sty.Font.Ch)
+	}
+	tv.Buf.LinesMu.RUnlock()
+	extraHalf := tv.LineHeight * 0.5 * float32(tv.VisSize.Y)
+	return gi.Vec2D{mxwd, float32(tv.NLines)*tv.LineHeight + extraHalf}.ToPointCeil()
+}
+
+// LargeVisLines returns the range of visible lines of a large buffer, end
+// inclusive, directly from their fixed height -- -1 if none
+func (tv *TextView) LargeVisLines() (stln, edln int) {
+	if tv.NLines == 0 || tv.LineHeight <= 0 {
+		return -1, -1
+	}
+	top := float32(tv.VpBBox.Min.Y) - tv.RenderStartPos().Y
+	stln = ints.MaxInt(int(math32.Floor(top/tv.LineHeight)), 0)
+	edln = ints.MinInt(int(math32.Ceil((top+float32(tv.VpBBox.Dy()))/tv.LineHeight)), tv.NLines-1)
+	if stln > edln {
+		return -1, -1
+	}
+	return
+}
+
+// PruneLineRenders frees the renders of the lines of a large buffer that
+// are more than a page away from given range of visible lines
+func (tv *TextView) PruneLineRenders(stln, edln int) {
+	pg := ints.MaxInt(tv.VisSize.Y, 1)
+	for ln := range tv.lineRenders {
+		if ln < stln-pg || ln > edln+pg {
+			delete(tv.lineRenders, ln)
+		}
+	}
+}
+
 ///////////////////////////////////////////////////////////////////////////////
 //  Cursor Navigation
 
@@ -665,20 +805,20 @@ func (tv *TextView) ValidateCursor() {
 
 // WrappedLines returns the number of wrapped lines (spans) for given line number
 func (tv *TextView) WrappedLines(ln int) int {
-	if ln >= len(tv.Renders) {
+	if ln >= tv.NLines {
 		return 0
 	}
-	return len(tv.Renders[ln].Spans)
+	return len(tv.LineRender(ln).Spans)
 }
 
 // WrappedLineNo returns the wrapped line number (span index) and rune index
 // within that span of the given character position within line in position,
 // and false if out of range (last valid position returned in that case -- still usable).
 func (tv *TextView) WrappedLineNo(pos TextPos) (si, ri int, ok bool) {
-	if pos.Ln >= len(tv.Renders) {
+	if pos.Ln >= tv.NLines {
 		return 0, 0, false
 	}
-	return tv.Renders[pos.Ln].RuneSpanPos(pos.Ch)
+	return tv.LineRender(pos.Ln).RuneSpanPos(pos.Ch)
 }
 
 // SetCursor sets a new cursor position, enforcing it in range
@@ -689,6 +829,9 @@ func (tv *TextView) SetCursor(pos TextPos) {
 	}
 	tv.ClearScopelights()
 	tv.CursorPos = tv.Buf.ValidPos(pos)
+	if tv.LineHidden(tv.CursorPos.Ln) {
+		tv.UnfoldLine(tv.CursorPos.Ln) // also shows the cursor
+	}
 	tv.CursorMovedSig()
 	txt := tv.Buf.Line(tv.CursorPos.Ln)
 	ch := tv.CursorPos.Ch
@@ -760,6 +903,9 @@ func (tv *TextView) CursorToHistPrev() bool {
 	tv.PosHistIdx = ints.MinInt(sz-1, tv.PosHistIdx)
 	pos := tv.Buf.PosHistory[tv.PosHistIdx]
 	tv.CursorPos = tv.Buf.ValidPos(pos)
+	if tv.LineHidden(tv.CursorPos.Ln) {
+		tv.UnfoldLine(tv.CursorPos.Ln) // also shows the cursor
+	}
 	tv.CursorMovedSig()
 	tv.ScrollCursorToCenterIfHidden()
 	tv.RenderCursor(true)
@@ -784,6 +930,9 @@ func (tv *TextView) CursorToHistNext() bool {
 	}
 	pos := tv.Buf.PosHistory[tv.PosHistIdx]
 	tv.CursorPos = tv.Buf.ValidPos(pos)
+	if tv.LineHidden(tv.CursorPos.Ln) {
+		tv.UnfoldLine(tv.CursorPos.Ln) // also shows the cursor
+	}
 	tv.CursorMovedSig()
 	tv.ScrollCursorToCenterIfHidden()
 	tv.RenderCursor(true)
@@ -821,9 +970,9 @@ func (tv *TextView) CursorForward(steps int) {
 	for i := 0; i < steps; i++ {
 		tv.CursorPos.Ch++
 		if tv.CursorPos.Ch > tv.Buf.LineLen(tv.CursorPos.Ln) {
-			if tv.CursorPos.Ln < tv.NLines-1 {
+			if nln := tv.VisibleLine(tv.CursorPos.Ln+1, 1); tv.CursorPos.Ln < tv.NLines-1 && nln > tv.CursorPos.Ln {
 				tv.CursorPos.Ch = 0
-				tv.CursorPos.Ln++
+				tv.CursorPos.Ln = nln
 			} else {
 				tv.CursorPos.Ch = tv.Buf.LineLen(tv.CursorPos.Ln)
 			}
@@ -873,9 +1022,9 @@ func (tv *TextView) CursorForwardWord(steps int) {
 			}
 			tv.CursorPos.Ch = ch
 		} else {
-			if tv.CursorPos.Ln < tv.NLines-1 {
+			if nln := tv.VisibleLine(tv.CursorPos.Ln+1, 1); tv.CursorPos.Ln < tv.NLines-1 && nln > tv.CursorPos.Ln {
 				tv.CursorPos.Ch = 0
-				tv.CursorPos.Ln++
+				tv.CursorPos.Ln = nln
 			} else {
 				tv.CursorPos.Ch = tv.Buf.LineLen(tv.CursorPos.Ln)
 			}
@@ -899,13 +1048,13 @@ func (tv *TextView) CursorDown(steps int) {
 			si, ri, _ := tv.WrappedLineNo(pos)
 			if si < wln-1 {
 				si++
-				mxlen := ints.MinInt(len(tv.Renders[pos.Ln].Spans[si].Text), tv.CursorCol)
+				mxlen := ints.MinInt(len(tv.LineRender(pos.Ln).Spans[si].Text), tv.CursorCol)
 				if tv.CursorCol < mxlen {
 					ri = tv.CursorCol
 				} else {
 					ri = mxlen
 				}
-				nwc, _ := tv.Renders[pos.Ln].SpanPosToRuneIdx(si, ri)
+				nwc, _ := tv.LineRender(pos.Ln).SpanPosToRuneIdx(si, ri)
 				if si == wln-1 && ri == mxlen {
 					nwc++
 				}
@@ -915,11 +1064,11 @@ func (tv *TextView) CursorDown(steps int) {
 			}
 		}
 		if !gotwrap {
-			pos.Ln++
-			if pos.Ln >= tv.NLines {
-				pos.Ln = tv.NLines - 1
+			nln := tv.VisibleLine(pos.Ln+1, 1)
+			if nln >= tv.NLines || nln <= pos.Ln {
 				break
 			}
+			pos.Ln = nln
 			mxlen := ints.MinInt(tv.Buf.LineLen(pos.Ln), tv.CursorCol)
 			if tv.CursorCol < mxlen {
 				pos.Ch = tv.CursorCol
@@ -945,6 +1094,7 @@ func (tv *TextView) CursorPageDown(steps int) {
 		if tv.CursorPos.Ln >= tv.NLines {
 			tv.CursorPos.Ln = tv.NLines - 1
 		}
+		tv.CursorPos.Ln = tv.VisibleLine(tv.CursorPos.Ln, -1)
 		tv.CursorPos.Ch = ints.MinInt(tv.Buf.LineLen(tv.CursorPos.Ln), tv.CursorCol)
 		tv.ScrollCursorToTop()
 		tv.RenderCursor(true)
@@ -963,7 +1113,7 @@ func (tv *TextView) CursorBackward(steps int) {
 		tv.CursorPos.Ch--
 		if tv.CursorPos.Ch < 0 {
 			if tv.CursorPos.Ln > 0 {
-				tv.CursorPos.Ln--
+				tv.CursorPos.Ln = tv.VisibleLine(tv.CursorPos.Ln-1, -1)
 				tv.CursorPos.Ch = tv.Buf.LineLen(tv.CursorPos.Ln)
 			} else {
 				tv.CursorPos.Ch = 0
@@ -1018,7 +1168,7 @@ func (tv *TextView) CursorBackwardWord(steps int) {
 			tv.CursorPos.Ch = ch
 		} else {
 			if tv.CursorPos.Ln > 0 {
-				tv.CursorPos.Ln--
+				tv.CursorPos.Ln = tv.VisibleLine(tv.CursorPos.Ln-1, -1)
 				tv.CursorPos.Ch = tv.Buf.LineLen(tv.CursorPos.Ln)
 			} else {
 				tv.CursorPos.Ch = 0
@@ -1044,7 +1194,7 @@ func (tv *TextView) CursorUp(steps int) {
 			if si > 0 {
 				ri = tv.CursorCol
 				// fmt.Printf("up cursorcol: %v\n", tv.CursorCol)
-				nwc, _ := tv.Renders[pos.Ln].SpanPosToRuneIdx(si-1, ri)
+				nwc, _ := tv.LineRender(pos.Ln).SpanPosToRuneIdx(si-1, ri)
 				pos.Ch = nwc
 				gotwrap = true
 			}
@@ -1055,10 +1205,11 @@ func (tv *TextView) CursorUp(steps int) {
 				pos.Ln = 0
 				break
 			}
+			pos.Ln = tv.VisibleLine(pos.Ln, -1)
 			if wln := tv.WrappedLines(pos.Ln); wln > 1 { // just entered end of wrapped line
 				si := wln - 1
 				ri := tv.CursorCol
-				nwc, _ := tv.Renders[pos.Ln].SpanPosToRuneIdx(si, ri)
+				nwc, _ := tv.LineRender(pos.Ln).SpanPosToRuneIdx(si, ri)
 				pos.Ch = nwc
 			} else {
 				mxlen := ints.MinInt(tv.Buf.LineLen(pos.Ln), tv.CursorCol)
@@ -1087,6 +1238,7 @@ func (tv *TextView) CursorPageUp(steps int) {
 		if tv.CursorPos.Ln <= 0 {
 			tv.CursorPos.Ln = 0
 		}
+		tv.CursorPos.Ln = tv.VisibleLine(tv.CursorPos.Ln, -1)
 		tv.CursorPos.Ch = ints.MinInt(tv.Buf.LineLen(tv.CursorPos.Ln), tv.CursorCol)
 		tv.ScrollCursorToBottom()
 		tv.RenderCursor(true)
@@ -1126,7 +1278,7 @@ func (tv *TextView) CursorStartLine() {
 		si, ri, _ := tv.WrappedLineNo(pos)
 		if si > 0 {
 			ri = 0
-			nwc, _ := tv.Renders[pos.Ln].SpanPosToRuneIdx(si, ri)
+			nwc, _ := tv.LineRender(pos.Ln).SpanPosToRuneIdx(si, ri)
 			pos.Ch = nwc
 			tv.CursorPos = pos
 			tv.CursorCol = ri
@@ -1171,9 +1323,9 @@ func (tv *TextView) CursorEndLine() {
 	gotwrap := false
 	if wln := tv.WrappedLines(pos.Ln); wln > 1 {
 		si, ri, _ := tv.WrappedLineNo(pos)
-		ri = len(tv.Renders[pos.Ln].Spans[si].Text) - 1
-		nwc, _ := tv.Renders[pos.Ln].SpanPosToRuneIdx(si, ri)
-		if si == len(tv.Renders[pos.Ln].Spans)-1 { // last span
+		ri = len(tv.LineRender(pos.Ln).Spans[si].Text) - 1
+		nwc, _ := tv.LineRender(pos.Ln).SpanPosToRuneIdx(si, ri)
+		if si == len(tv.LineRender(pos.Ln).Spans)-1 { // last span
 			ri++
 			nwc++
 		}
@@ -1199,7 +1351,7 @@ func (tv *TextView) CursorEndDoc() {
 	defer tv.Viewport.Win.UpdateEnd(updt)
 	tv.ValidateCursor()
 	org := tv.CursorPos
-	tv.CursorPos.Ln = ints.MaxInt(tv.NLines-1, 0)
+	tv.CursorPos.Ln = tv.VisibleLine(ints.MaxInt(tv.NLines-1, 0), -1)
 	tv.CursorPos.Ch = tv.Buf.LineLen(tv.CursorPos.Ln)
 	tv.CursorCol = tv.CursorPos.Ch
 	tv.SetCursor(tv.CursorPos)
@@ -1214,6 +1366,10 @@ func (tv *TextView) CursorEndDoc() {
 
 // CursorBackspace deletes character(s) immediately before cursor
 func (tv *TextView) CursorBackspace(steps int) {
+	if tv.HasCursors() {
+		tv.EditAtCursors(func() { tv.CursorBackspace(steps) })
+		return
+	}
 	updt := tv.Viewport.Win.UpdateStart()
 	defer tv.Viewport.Win.UpdateEnd(updt)
 	tv.ValidateCursor()
@@ -1233,6 +1389,10 @@ func (tv *TextView) CursorBackspace(steps int) {
 
 // CursorDelete deletes character(s) immediately after the cursor
 func (tv *TextView) CursorDelete(steps int) {
+	if tv.HasCursors() {
+		tv.EditAtCursors(func() { tv.CursorDelete(steps) })
+		return
+	}
 	updt := tv.Viewport.Win.UpdateStart()
 	defer tv.Viewport.Win.UpdateEnd(updt)
 	tv.ValidateCursor()
@@ -1249,6 +1409,10 @@ func (tv *TextView) CursorDelete(steps int) {
 
 // CursorBackspaceWord deletes words(s) immediately before cursor
 func (tv *TextView) CursorBackspaceWord(steps int) {
+	if tv.HasCursors() {
+		tv.EditAtCursors(func() { tv.CursorBackspaceWord(steps) })
+		return
+	}
 	updt := tv.Viewport.Win.UpdateStart()
 	defer tv.Viewport.Win.UpdateEnd(updt)
 	tv.ValidateCursor()
@@ -1267,6 +1431,10 @@ func (tv *TextView) CursorBackspaceWord(steps int) {
 
 // CursorDeleteWord deletes word(s) immediately after the cursor
 func (tv *TextView) CursorDeleteWord(steps int) {
+	if tv.HasCursors() {
+		tv.EditAtCursors(func() { tv.CursorDeleteWord(steps) })
+		return
+	}
 	updt := tv.Viewport.Win.UpdateStart()
 	defer tv.Viewport.Win.UpdateEnd(updt)
 	tv.ValidateCursor()
@@ -1283,6 +1451,10 @@ func (tv *TextView) CursorDeleteWord(steps int) {
 
 // CursorKill deletes text from cursor to end of text
 func (tv *TextView) CursorKill() {
+	if tv.HasCursors() {
+		tv.EditAtCursors(func() { tv.CursorKill() })
+		return
+	}
 	updt := tv.Viewport.Win.UpdateStart()
 	defer tv.Viewport.Win.UpdateEnd(updt)
 	tv.ValidateCursor()
@@ -1292,7 +1464,7 @@ func (tv *TextView) CursorKill() {
 	atEnd := false
 	if wln := tv.WrappedLines(pos.Ln); wln > 1 {
 		si, ri, _ := tv.WrappedLineNo(pos)
-		llen := len(tv.Renders[pos.Ln].Spans[si].Text)
+		llen := len(tv.LineRender(pos.Ln).Spans[si].Text)
 		if si == wln-1 {
 			llen--
 		}
@@ -1338,12 +1510,12 @@ func (tv *TextView) JumpToLine(ln int) {
 // FindNextLink finds next link after given position, returns false if no such links
 func (tv *TextView) FindNextLink(pos TextPos) (TextPos, TextRegion, bool) {
 	for ln := pos.Ln; ln < tv.NLines; ln++ {
-		if len(tv.Renders[ln].Links) == 0 {
+		if len(tv.LineRender(ln).Links) == 0 {
 			pos.Ch = 0
 			pos.Ln = ln + 1
 			continue
 		}
-		rend := &tv.Renders[ln]
+		rend := tv.LineRender(ln)
 		si, ri, _ := rend.RuneSpanPos(pos.Ch)
 		for ti := range rend.Links {
 			tl := &rend.Links[ti]
@@ -1364,7 +1536,7 @@ func (tv *TextView) FindNextLink(pos TextPos) (TextPos, TextRegion, bool) {
 // FindPrevLink finds previous link before given position, returns false if no such links
 func (tv *TextView) FindPrevLink(pos TextPos) (TextPos, TextRegion, bool) {
 	for ln := pos.Ln - 1; ln >= 0; ln-- {
-		if len(tv.Renders[ln].Links) == 0 {
+		if len(tv.LineRender(ln).Links) == 0 {
 			if ln-1 >= 0 {
 				pos.Ch = tv.Buf.LineLen(ln-1) - 2
 			} else {
@@ -1373,7 +1545,7 @@ func (tv *TextView) FindPrevLink(pos TextPos) (TextPos, TextRegion, bool) {
 			}
 			continue
 		}
-		rend := &tv.Renders[ln]
+		rend := tv.LineRender(ln)
 		si, ri, _ := rend.RuneSpanPos(pos.Ch)
 		nl := len(rend.Links)
 		for ti := nl - 1; ti >= 0; ti-- {
@@ -1456,6 +1628,7 @@ func (tv *TextView) CursorPrevLink(wraparound bool) bool {
 func (tv *TextView) Undo() {
 	updt := tv.Viewport.Win.UpdateStart()
 	defer tv.Viewport.Win.UpdateEnd(updt)
+	tv.CursorsReset()
 	tbe := tv.Buf.Undo()
 	if tbe != nil {
 		if tbe.Delete { // now an insert
@@ -1474,6 +1647,7 @@ func (tv *TextView) Undo() {
 func (tv *TextView) Redo() {
 	updt := tv.Viewport.Win.UpdateStart()
 	defer tv.Viewport.Win.UpdateEnd(updt)
+	tv.CursorsReset()
 	tbe := tv.Buf.Redo()
 	if tbe != nil {
 		if tbe.Delete {
@@ -1487,6 +1661,19 @@ func (tv *TextView) Redo() {
 	tv.SavePosHistory(tv.CursorPos)
 }
 
+// RedoBranch switches to the last branch of the undo history that was
+// undone and replaced at the current point, and redoes it -- the edits that
+// replaced it become a branch in turn, so undoing and calling this again
+// switches back
+func (tv *TextView) RedoBranch() {
+	bis := tv.Buf.RedoBranches()
+	if len(bis) == 0 {
+		return
+	}
+	tv.Buf.SwitchUndoBranch(bis[len(bis)-1])
+	tv.Redo()
+}
+
 ///////////////////////////////////////////////////////////////////////////////
 //    Search / Find
 
@@ -1937,9 +2124,12 @@ func (tv *TextView) EscPressed() {
 	case tv.QReplace.On:
 		tv.QReplaceCancel()
 		tv.SetCursorShow(tv.ISearch.StartPos)
+	case len(tv.Cursors) > 0:
+		tv.CursorsReset()
 	case tv.HasSelection():
 		tv.SelectReset()
 	default:
+		tv.RectSelect = false
 		tv.Highlights = nil
 		tv.RenderAllLines()
 	}
@@ -2195,6 +2385,7 @@ func (tv *TextView) WordAt() (region TextRegion) {
 // SelectReset resets the selection
 func (tv *TextView) SelectReset() {
 	tv.SelectMode = false
+	tv.RectSelect = false
 	if !tv.HasSelection() {
 		return
 	}
@@ -2290,6 +2481,17 @@ func (tv *TextView) PasteHist() {
 
 // Cut cuts any selected text and adds it to the clipboard, also returns cut text
 func (tv *TextView) Cut() *TextBufEdit {
+	if tv.HasCursors() {
+		cut := tv.CopyCursors(false)
+		if cut != nil {
+			tv.EditAtCursors(func() {
+				if tv.HasSelection() {
+					tv.DeleteSelection()
+				}
+			})
+		}
+		return cut
+	}
 	if !tv.HasSelection() {
 		return nil
 	}
@@ -2318,6 +2520,9 @@ func (tv *TextView) DeleteSelection() *TextBufEdit {
 // Copy copies any selected text to the clipboard, and returns that text,
 // optionally resetting the current selection
 func (tv *TextView) Copy(reset bool) *TextBufEdit {
+	if tv.HasCursors() {
+		return tv.CopyCursors(reset)
+	}
 	tbe := tv.Selection()
 	if tbe == nil {
 		return nil
@@ -2340,13 +2545,21 @@ func (tv *TextView) Paste() {
 	defer tv.Viewport.Win.UpdateEnd(updt)
 	data := oswin.TheApp.ClipBoard(tv.Viewport.Win.OSWin).Read([]string{filecat.TextPlain})
 	if data != nil {
-		tv.InsertAtCursor(data.TypeData(filecat.TextPlain))
+		if tv.HasCursors() {
+			tv.PasteAtCursors(data.TypeData(filecat.TextPlain))
+		} else {
+			tv.InsertAtCursor(data.TypeData(filecat.TextPlain))
+		}
 		tv.SavePosHistory(tv.CursorPos)
 	}
 }
 
 // InsertAtCursor inserts given text at current cursor position
 func (tv *TextView) InsertAtCursor(txt []byte) {
+	if tv.HasCursors() {
+		tv.EditAtCursors(func() { tv.InsertAtCursor(txt) })
+		return
+	}
 	updt := tv.Viewport.Win.UpdateStart()
 	defer tv.Viewport.Win.UpdateEnd(updt)
 	if tv.HasSelection() {
@@ -2403,6 +2616,28 @@ func (tv *TextView) MakeContextMenu(m *gi.Menu) {
 				txf.Paste()
 			})
 		ac.SetInactiveState(oswin.TheApp.ClipBoard(tv.Viewport.Win.OSWin).IsEmpty())
+		ac = m.AddAction(gi.ActOpts{Label: "Redo Other Branch"},
+			tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+				txf := recv.Embed(KiT_TextView).(*TextView)
+				txf.RedoBranch()
+			})
+		ac.SetActiveState(tv.Buf != nil && len(tv.Buf.RedoBranches()) > 0)
+		ac = m.AddAction(gi.ActOpts{Label: "Add Cursor at Next Match", ShortcutKey: gi.KeyFunCursorNextMatch},
+			tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+				txf := recv.Embed(KiT_TextView).(*TextView)
+				txf.AddCursorNextMatch()
+			})
+		ac = m.AddAction(gi.ActOpts{Label: "Split Into Cursors", ShortcutKey: gi.KeyFunSplitCursors},
+			tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+				txf := recv.Embed(KiT_TextView).(*TextView)
+				txf.SplitCursors()
+			})
+		ac.SetActiveState(tv.HasSelection())
+		m.AddAction(gi.ActOpts{Label: "Rectangular Select", ShortcutKey: gi.KeyFunRectSelect},
+			tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+				txf := recv.Embed(KiT_TextView).(*TextView)
+				txf.RectSelectToggle()
+			})
 	} else {
 		ac = m.AddAction(gi.ActOpts{Label: "Clear"},
 			tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
@@ -2410,6 +2645,43 @@ func (tv *TextView) MakeContextMenu(m *gi.Menu) {
 				txf.Clear()
 			})
 	}
+	if tv.Buf == nil {
+		return
+	}
+	if !tv.IsInactive() {
+		m.AddSeparator("sep-encoding")
+		m.AddAction(gi.ActOpts{Label: "Set Line Endings..."},
+			tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+				txf := recv.Embed(KiT_TextView).(*TextView)
+				CallMethod(txf.Buf, "SetLineEnds", txf.Viewport)
+			})
+		m.AddAction(gi.ActOpts{Label: "Set Encoding..."},
+			tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+				txf := recv.Embed(KiT_TextView).(*TextView)
+				CallMethod(txf.Buf, "SetEncoding", txf.Viewport)
+			})
+	}
+	if tv.Buf.IsLarge() || tv.Buf.Opts.FoldMode == FoldNone {
+		return
+	}
+	tv.FoldsNeeded()
+	m.AddSeparator("sep-fold")
+	ac = m.AddAction(gi.ActOpts{Label: "Fold / Unfold"},
+		tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			txf := recv.Embed(KiT_TextView).(*TextView)
+			txf.FoldToggle(txf.CursorPos.Ln)
+		})
+	ac.SetActiveState(tv.FoldIndexAt(tv.CursorPos.Ln) >= 0)
+	m.AddAction(gi.ActOpts{Label: "Fold All"},
+		tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			txf := recv.Embed(KiT_TextView).(*TextView)
+			txf.FoldAll()
+		})
+	m.AddAction(gi.ActOpts{Label: "Unfold All"},
+		tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			txf := recv.Embed(KiT_TextView).(*TextView)
+			txf.UnfoldAll()
+		})
 }
 
 ///////////////////////////////////////////////////////////////////////////////
@@ -2436,7 +2708,10 @@ func (tv *TextView) OfferComplete() {
 	}
 
 	tpos := gotoken.Position{} // text position
-	count := tv.Buf.ByteOffs[tv.CursorPos.Ln] + tv.CursorPos.Ch
+	count := tv.CursorPos.Ch
+	if tv.CursorPos.Ln < len(tv.Buf.ByteOffs) {
+		count += tv.Buf.ByteOffs[tv.CursorPos.Ln]
+	}
 	tpos.Line = tv.CursorPos.Ln
 	tpos.Column = tv.CursorPos.Ch
 	tpos.Offset = count
@@ -2786,20 +3061,20 @@ func (tv *TextView) ScrollCursorToHorizCenter() bool {
 func (tv *TextView) CharStartPos(pos TextPos) gi.Vec2D {
 	spos := tv.RenderStartPos()
 	spos.X += tv.LineNoOff
-	if pos.Ln >= len(tv.Offs) {
-		if len(tv.Offs) > 0 {
-			pos.Ln = len(tv.Offs) - 1
+	if pos.Ln >= tv.NLines {
+		if tv.NLines > 0 {
+			pos.Ln = tv.NLines - 1
 		} else {
 			return spos
 		}
 	} else {
-		spos.Y += tv.Offs[pos.Ln] + gi.FixedToFloat32(tv.Sty.Font.Face.Metrics().Descent)
+		spos.Y += tv.LineOff(pos.Ln) + gi.FixedToFloat32(tv.Sty.Font.Face.Metrics().Descent)
 	}
-	if len(tv.Renders[pos.Ln].Spans) > 0 {
+	if len(tv.LineRender(pos.Ln).Spans) > 0 {
 		// note: Y from rune pos is baseline
-		rrp, _, _, _ := tv.Renders[pos.Ln].RuneRelPos(pos.Ch)
+		rrp, _, _, _ := tv.LineRender(pos.Ln).RuneRelPos(pos.Ch)
 		spos.X += rrp.X
-		spos.Y += rrp.Y - tv.Renders[pos.Ln].Spans[0].RelPos.Y // relative
+		spos.Y += rrp.Y - tv.LineRender(pos.Ln).Spans[0].RelPos.Y // relative
 	}
 	return spos
 }
@@ -2814,13 +3089,13 @@ func (tv *TextView) CharEndPos(pos TextPos) gi.Vec2D {
 		spos.X += tv.LineNoOff
 		return spos
 	}
-	spos.Y += tv.Offs[pos.Ln] + gi.FixedToFloat32(tv.Sty.Font.Face.Metrics().Descent)
+	spos.Y += tv.LineOff(pos.Ln) + gi.FixedToFloat32(tv.Sty.Font.Face.Metrics().Descent)
 	spos.X += tv.LineNoOff
-	if len(tv.Renders[pos.Ln].Spans) > 0 {
+	if len(tv.LineRender(pos.Ln).Spans) > 0 {
 		// note: Y from rune pos is baseline
-		rrp, _, _, _ := tv.Renders[pos.Ln].RuneEndPos(pos.Ch)
+		rrp, _, _, _ := tv.LineRender(pos.Ln).RuneEndPos(pos.Ch)
 		spos.X += rrp.X
-		spos.Y += rrp.Y - tv.Renders[pos.Ln].Spans[0].RelPos.Y // relative
+		spos.Y += rrp.Y - tv.LineRender(pos.Ln).Spans[0].RelPos.Y // relative
 	}
 	spos.Y += tv.LineHeight // end of that line
 	return spos
@@ -2941,7 +3216,7 @@ func (tv *TextView) RenderCursor(on bool) {
 	if !tv.This().(gi.Node2D).IsVisible() {
 		return
 	}
-	if tv.Renders == nil {
+	if tv.Renders == nil && tv.lineRenders == nil {
 		return
 	}
 	tv.CursorMu.Lock()
@@ -3022,13 +3297,16 @@ func (tv *TextView) RenderDepthBg(stln, edln int) {
 	lstdp := 0
 	for ln := stln; ln <= edln; ln++ {
 		lst := tv.CharStartPos(TextPos{Ln: ln}).Y // note: charstart pos includes descent
-		led := lst + math32.Max(tv.Renders[ln].Size.Y, tv.LineHeight)
+		led := lst + tv.lineSize(ln)
 		if int(math32.Ceil(led)) < tv.VpBBox.Min.Y {
 			continue
 		}
 		if int(math32.Floor(lst)) > tv.VpBBox.Max.Y {
 			continue
 		}
+		if tv.LineHidden(ln) {
+			continue
+		}
 		if ln >= len(tv.Buf.HiTags) { // may be out of sync
 			continue
 		}
@@ -3051,12 +3329,19 @@ func (tv *TextView) RenderDepthBg(stln, edln int) {
 	}
 }
 
-// RenderSelect renders the selection region as a selected background color
-// -- always called within context of outer RenderLines or RenderAllLines
+// RenderSelect renders the selection region as a selected background color,
+// along with any extra cursors -- always called within context of outer RenderLines or RenderAllLines
 func (tv *TextView) RenderSelect() {
+	tv.RenderCursors()
 	if !tv.HasSelection() {
 		return
 	}
+	if tv.RectSelect {
+		for _, reg := range tv.RectRegions() {
+			tv.RenderRegionBox(reg, TextViewSel)
+		}
+		return
+	}
 	tv.RenderRegionBox(tv.SelectReg, TextViewSel)
 }
 
@@ -3069,6 +3354,9 @@ func (tv *TextView) RenderHighlights(stln, edln int) {
 		if reg.IsNil() || (stln >= 0 && (reg.Start.Ln > edln || reg.End.Ln < stln)) {
 			continue
 		}
+		if tv.LineHidden(reg.Start.Ln) && tv.LineHidden(reg.End.Ln) {
+			continue // folded
+		}
 		tv.RenderRegionBox(reg, TextViewHighlight)
 	}
 }
@@ -3082,6 +3370,9 @@ func (tv *TextView) RenderScopelights(stln, edln int) {
 		if reg.IsNil() || (stln >= 0 && (reg.Start.Ln > edln || reg.End.Ln < stln)) {
 			continue
 		}
+		if tv.LineHidden(reg.Start.Ln) && tv.LineHidden(reg.End.Ln) {
+			continue // folded
+		}
 		tv.RenderRegionBox(reg, TextViewHighlight)
 	}
 }
@@ -3266,9 +3557,13 @@ func (tv *TextView) RenderAllLinesInBounds() {
 	pos = tv.RenderStartPos()
 	stln := -1
 	edln := -1
-	for ln := 0; ln < tv.NLines; ln++ {
-		lst := pos.Y + tv.Offs[ln]
-		led := lst + math32.Max(tv.Renders[ln].Size.Y, tv.LineHeight)
+	if tv.lineRenders != nil {
+		stln, edln = tv.LargeVisLines()
+		tv.PruneLineRenders(stln, edln)
+	}
+	for ln := 0; ln < tv.NLines && tv.lineRenders == nil; ln++ {
+		lst := pos.Y + tv.LineOff(ln)
+		led := lst + tv.lineSize(ln)
 		if int(math32.Ceil(led)) < tv.VpBBox.Min.Y {
 			continue
 		}
@@ -3306,11 +3601,15 @@ func (tv *TextView) RenderAllLinesInBounds() {
 		rs.Lock()
 	}
 	for ln := stln; ln <= edln; ln++ {
-		lst := pos.Y + tv.Offs[ln]
+		lst := pos.Y + tv.LineOff(ln)
 		lp := pos
 		lp.Y = lst
 		lp.X += tv.LineNoOff
-		tv.Renders[ln].Render(rs, lp) // not top pos -- already has baseline offset
+		if tv.LineHidden(ln) {
+			tv.RenderFoldPlaceholder(ln)
+			continue
+		}
+		tv.LineRender(ln).Render(rs, lp) // not top pos -- already has baseline offset
 	}
 	rs.Unlock()
 	if tv.HasLineNos() {
@@ -3355,7 +3654,7 @@ func (tv *TextView) RenderLineNosBox(st, ed int) {
 
 // RenderLineNo renders given line number -- called within context of other render
 func (tv *TextView) RenderLineNo(ln int) {
-	if !tv.HasLineNos() {
+	if !tv.HasLineNos() || tv.LineHidden(ln) {
 		return
 	}
 	vp := tv.Viewport
@@ -3366,7 +3665,7 @@ func (tv *TextView) RenderLineNo(ln int) {
 	rs := &vp.Render
 	lfmt := fmt.Sprintf("%v", tv.LineNoDigs)
 	lfmt = "%0" + lfmt + "d"
-	lnstr := fmt.Sprintf(lfmt, ln+1)
+	lnstr := fmt.Sprintf(lfmt, ln+1) + " " + tv.FoldMarker(ln)
 	tv.LineNoRender.SetString(lnstr, &fst, &sty.UnContext, &sty.Text, true, 0, 0)
 	pos := tv.RenderStartPos()
 	lst := tv.CharStartPos(TextPos{Ln: ln}).Y // note: charstart pos includes descent
@@ -3404,6 +3703,11 @@ func (tv *TextView) RenderLines(st, ed int) bool {
 	if ed >= tv.NLines {
 		ed = tv.NLines - 1
 	}
+	if tv.lineRenders != nil { // only visible lines are laid out
+		vst, ved := tv.LargeVisLines()
+		st = ints.MaxInt(st, vst)
+		ed = ints.MinInt(ed, ved)
+	}
 	if st > ed {
 		return false
 	}
@@ -3420,7 +3724,7 @@ func (tv *TextView) RenderLines(st, ed int) bool {
 	visEd := -1
 	for ln := st; ln <= ed; ln++ {
 		lst := tv.CharStartPos(TextPos{Ln: ln}).Y // note: charstart pos includes descent
-		led := lst + math32.Max(tv.Renders[ln].Size.Y, tv.LineHeight)
+		led := lst + tv.lineSize(ln)
 		if int(math32.Ceil(led)) < tv.VpBBox.Min.Y {
 			continue
 		}
@@ -3461,11 +3765,15 @@ func (tv *TextView) RenderLines(st, ed int) bool {
 			rs.Lock()
 		}
 		for ln := visSt; ln <= visEd; ln++ {
-			lst := pos.Y + tv.Offs[ln]
+			lst := pos.Y + tv.LineOff(ln)
 			lp := pos
 			lp.Y = lst
 			lp.X += tv.LineNoOff
-			tv.Renders[ln].Render(rs, lp) // not top pos -- already has baseline offset
+			if tv.LineHidden(ln) {
+				tv.RenderFoldPlaceholder(ln)
+				continue
+			}
+			tv.LineRender(ln).Render(rs, lp) // not top pos -- already has baseline offset
 		}
 		rs.Unlock()
 		if tv.HasLineNos() {
@@ -3552,7 +3860,7 @@ func (tv *TextView) PixelToCursor(pt image.Point) TextPos {
 		for ln := stln; ln < tv.NLines; ln++ {
 			ls := tv.CharStartPos(TextPos{Ln: ln}).Y - yoff
 			es := ls
-			es += math32.Max(tv.Renders[ln].Size.Y, tv.LineHeight)
+			es += tv.lineSize(ln)
 			if pt.Y >= int(math32.Floor(ls)) && pt.Y < int(math32.Ceil(es)) {
 				got = true
 				cln = ln
@@ -3578,26 +3886,26 @@ func (tv *TextView) PixelToCursor(pt image.Point) TextPos {
 
 	si := 0
 	spoff := 0
-	nspan := len(tv.Renders[cln].Spans)
+	nspan := len(tv.LineRender(cln).Spans)
 	lstY := tv.CharStartPos(TextPos{Ln: cln}).Y - yoff
 	if nspan > 1 {
 		si = int((float32(pt.Y) - lstY) / tv.LineHeight)
 		si = ints.MinInt(si, nspan-1)
 		si = ints.MaxInt(si, 0)
 		for i := 0; i < si; i++ {
-			spoff += len(tv.Renders[cln].Spans[i].Text)
+			spoff += len(tv.LineRender(cln).Spans[i].Text)
 		}
 		// fmt.Printf("si: %v  spoff: %v\n", si, spoff)
 	}
 
 	ri := sc
-	rsz := len(tv.Renders[cln].Spans[si].Text)
+	rsz := len(tv.LineRender(cln).Spans[si].Text)
 	if rsz == 0 {
 		return TextPos{Ln: cln, Ch: spoff}
 	}
 	// fmt.Printf("sc: %v  rsz: %v\n", sc, rsz)
 
-	c, _ := tv.Renders[cln].SpanPosToRuneIdx(si, rsz-1) // end
+	c, _ := tv.LineRender(cln).SpanPosToRuneIdx(si, rsz-1) // end
 	rsp := math32.Floor(tv.CharStartPos(TextPos{Ln: cln, Ch: c}).X - xoff)
 	rep := math32.Ceil(tv.CharEndPos(TextPos{Ln: cln, Ch: c}).X - xoff)
 	if int(rep) < pt.X { // end of line
@@ -3611,7 +3919,7 @@ func (tv *TextView) PixelToCursor(pt image.Point) TextPos {
 	got := false
 	if ri < rsz {
 		for rii := ri; rii < rsz; rii++ {
-			c, _ := tv.Renders[cln].SpanPosToRuneIdx(si, rii)
+			c, _ := tv.LineRender(cln).SpanPosToRuneIdx(si, rii)
 			rsp = math32.Floor(tv.CharStartPos(TextPos{Ln: cln, Ch: c}).X - xoff)
 			rep = math32.Ceil(tv.CharEndPos(TextPos{Ln: cln, Ch: c}).X - xoff)
 			// fmt.Printf("trying c: %v for pt: %v xoff: %v rsp: %v, rep: %v\n", c, pt, xoff, rsp, rep)
@@ -3633,7 +3941,7 @@ func (tv *TextView) PixelToCursor(pt image.Point) TextPos {
 		ri = rsz - 1
 		// fmt.Printf("too big: %v\n", ri)
 		for rii := ri; rii >= 0; rii-- {
-			c, _ := tv.Renders[cln].SpanPosToRuneIdx(si, rii)
+			c, _ := tv.LineRender(cln).SpanPosToRuneIdx(si, rii)
 			rsp := math32.Floor(tv.CharStartPos(TextPos{Ln: cln, Ch: c}).X - xoff)
 			rep := math32.Ceil(tv.CharEndPos(TextPos{Ln: cln, Ch: c}).X - xoff)
 			// fmt.Printf("too big: trying c: %v for pt: %v rsp: %v, rep: %v\n", c, pt, rsp, rep)
@@ -3782,6 +4090,13 @@ func (tv *TextView) KeyInput(kt *key.ChordEvent) {
 		tv.ClearFlag(int(TextViewLastWasUndo))
 	}
 
+	if KeyFunIsMove(kf) {
+		tv.Buf.UndoBoundary()
+		if len(tv.Cursors) > 0 {
+			tv.CursorsReset()
+		}
+	}
+
 	gotTabAI := false // got auto-indent tab this time
 
 	// first all the keys that work for both inactive and active
@@ -3880,6 +4195,10 @@ func (tv *TextView) KeyInput(kt *key.ChordEvent) {
 		cancelAll()
 		kt.SetProcessed()
 		tv.SelectAll()
+	case gi.KeyFunRectSelect:
+		cancelAll()
+		kt.SetProcessed()
+		tv.RectSelectToggle()
 	case gi.KeyFunCopy:
 		cancelAll()
 		kt.SetProcessed()
@@ -3984,6 +4303,14 @@ func (tv *TextView) KeyInput(kt *key.ChordEvent) {
 		cancelAll()
 		kt.SetProcessed()
 		tv.Redo()
+	case gi.KeyFunCursorNextMatch:
+		cancelAll()
+		kt.SetProcessed()
+		tv.AddCursorNextMatch()
+	case gi.KeyFunSplitCursors:
+		cancelAll()
+		kt.SetProcessed()
+		tv.SplitCursors()
 	case gi.KeyFunComplete:
 		tv.ISearchCancel()
 		kt.SetProcessed()
@@ -3998,18 +4325,20 @@ func (tv *TextView) KeyInput(kt *key.ChordEvent) {
 		cancelAll()
 		if !kt.HasAnyModifier(key.Control, key.Meta) {
 			kt.SetProcessed()
-			if tv.Buf.Opts.AutoIndent {
-				bufUpdt, winUpdt, autoSave := tv.Buf.BatchUpdateStart()
-				tv.InsertAtCursor([]byte("\n"))
-				tbe, _, cpos := tv.Buf.AutoIndent(tv.CursorPos.Ln, DefaultIndentStrings, DefaultUnindentStrings)
-				if tbe != nil {
-					tv.RenderLines(tv.CursorPos.Ln, tv.CursorPos.Ln+1)
-					tv.SetCursorShow(TextPos{Ln: tbe.Reg.End.Ln, Ch: cpos})
+			tv.EditAtCursors(func() {
+				if tv.Buf.Opts.AutoIndent {
+					bufUpdt, winUpdt, autoSave := tv.Buf.BatchUpdateStart()
+					tv.InsertAtCursor([]byte("\n"))
+					tbe, _, cpos := tv.Buf.AutoIndent(tv.CursorPos.Ln, DefaultIndentStrings, DefaultUnindentStrings)
+					if tbe != nil {
+						tv.RenderLines(tv.CursorPos.Ln, tv.CursorPos.Ln+1)
+						tv.SetCursorShow(TextPos{Ln: tbe.Reg.End.Ln, Ch: cpos})
+					}
+					tv.Buf.BatchUpdateEnd(bufUpdt, winUpdt, autoSave)
+				} else {
+					tv.InsertAtCursor([]byte("\n"))
 				}
-				tv.Buf.BatchUpdateEnd(bufUpdt, winUpdt, autoSave)
-			} else {
-				tv.InsertAtCursor([]byte("\n"))
-			}
+			})
 			tv.ISpellKeyInput(kt)
 		}
 		// todo: KeFunFocusPrev -- unindent
@@ -4019,16 +4348,18 @@ func (tv *TextView) KeyInput(kt *key.ChordEvent) {
 			kt.SetProcessed()
 			updt := tv.Viewport.Win.UpdateStart()
 			lasttab := tv.HasFlag(int(TextViewLastWasTabAI))
-			if !lasttab && tv.CursorPos.Ch == 0 && tv.Buf.Opts.AutoIndent { // todo: only at 1st pos now
-				_, _, cpos := tv.Buf.AutoIndent(tv.CursorPos.Ln, DefaultIndentStrings, DefaultUnindentStrings)
-				tv.CursorPos.Ch = cpos
-				tv.RenderLines(tv.CursorPos.Ln, tv.CursorPos.Ln)
-				tv.RenderCursor(true)
-				gotTabAI = true
-			} else {
-				tv.InsertAtCursor(indent.Bytes(tv.Buf.Opts.IndentChar(), 1, tv.Sty.Text.TabSize))
-				tv.RenderLines(tv.CursorPos.Ln, tv.CursorPos.Ln)
-			}
+			tv.EditAtCursors(func() {
+				if !lasttab && tv.CursorPos.Ch == 0 && tv.Buf.Opts.AutoIndent { // todo: only at 1st pos now
+					_, _, cpos := tv.Buf.AutoIndent(tv.CursorPos.Ln, DefaultIndentStrings, DefaultUnindentStrings)
+					tv.CursorPos.Ch = cpos
+					tv.RenderLines(tv.CursorPos.Ln, tv.CursorPos.Ln)
+					tv.RenderCursor(true)
+					gotTabAI = true
+				} else {
+					tv.InsertAtCursor(indent.Bytes(tv.Buf.Opts.IndentChar(), 1, tv.Sty.Text.TabSize))
+					tv.RenderLines(tv.CursorPos.Ln, tv.CursorPos.Ln)
+				}
+			})
 			tv.Viewport.Win.UpdateEnd(updt)
 			tv.ISpellKeyInput(kt)
 		}
@@ -4055,6 +4386,14 @@ func (tv *TextView) KeyInputInsertRune(kt *key.ChordEvent) {
 	} else if tv.QReplace.On { // todo: need this in inactive mode
 		tv.CancelComplete()
 		tv.QReplaceKeyInput(kt)
+	} else if tv.HasCursors() { // no auto-insert or indent at multiple cursors
+		tv.lastAutoInsert = 0
+		tv.InsertAtCursor([]byte(string(kt.Rune)))
+		if kt.Rune == ' ' {
+			tv.CancelComplete()
+		} else {
+			tv.OfferComplete()
+		}
 	} else {
 		if kt.Rune == '{' || kt.Rune == '(' || kt.Rune == '[' {
 			bufUpdt, winUpdt, autoSave := tv.Buf.BatchUpdateStart()
@@ -4140,14 +4479,14 @@ func (tv *TextView) OpenLink(tl *gi.TextLink) {
 // LinkAt returns link at given cursor position, if one exists there --
 // returns true and the link if there is a link, and false otherwise
 func (tv *TextView) LinkAt(pos TextPos) (*gi.TextLink, bool) {
-	if !(pos.Ln < len(tv.Renders) && len(tv.Renders[pos.Ln].Links) > 0) {
+	if !(pos.Ln < tv.NLines && len(tv.LineRender(pos.Ln).Links) > 0) {
 		return nil, false
 	}
 	cpos := tv.CharStartPos(pos).ToPointCeil()
 	cpos.Y += 2
 	cpos.X += 2
 	lpos := tv.CharStartPos(TextPos{Ln: pos.Ln})
-	rend := &tv.Renders[pos.Ln]
+	rend := tv.LineRender(pos.Ln)
 	for ti := range rend.Links {
 		tl := &rend.Links[ti]
 		tlb := tl.Bounds(rend, lpos)
@@ -4163,7 +4502,7 @@ func (tv *TextView) LinkAt(pos TextPos) (*gi.TextLink, bool) {
 func (tv *TextView) OpenLinkAt(pos TextPos) (*gi.TextLink, bool) {
 	tl, ok := tv.LinkAt(pos)
 	if ok {
-		rend := &tv.Renders[pos.Ln]
+		rend := tv.LineRender(pos.Ln)
 		st, _ := rend.SpanPosToRuneIdx(tl.StartSpan, tl.StartIdx)
 		ed, _ := rend.SpanPosToRuneIdx(tl.EndSpan, tl.EndIdx)
 		reg := NewTextRegion(pos.Ln, st, pos.Ln, ed)
@@ -4191,8 +4530,15 @@ func (tv *TextView) MouseEvent(me *mouse.Event) {
 	case mouse.Left:
 		if me.Action == mouse.Press {
 			me.SetProcessed()
-			if _, got := tv.OpenLinkAt(newPos); got {
+			tv.FoldsNeeded()
+			if pt.X < int(tv.LineNoOff) && tv.HasLineNos() && tv.FoldIndex(newPos.Ln) >= 0 {
+				tv.FoldToggle(newPos.Ln)
+			} else if me.HasAnyModifier(key.Alt) && !tv.IsInactive() {
+				tv.AddCursor(newPos)
+			} else if _, got := tv.OpenLinkAt(newPos); got {
 			} else {
+				tv.CursorsReset()
+				tv.Buf.UndoBoundary()
 				tv.SetCursorFromMouse(pt, newPos, me.SelectMode())
 				tv.SavePosHistory(tv.CursorPos)
 			}
@@ -4249,9 +4595,9 @@ func (tv *TextView) MouseMoveEvent() {
 			return
 		}
 		pos := tv.RenderStartPos()
-		pos.Y += tv.Offs[mpos.Ln]
+		pos.Y += tv.LineOff(mpos.Ln)
 		pos.X += tv.LineNoOff
-		rend := &tvv.Renders[mpos.Ln]
+		rend := tvv.LineRender(mpos.Ln)
 		inLink := false
 		for _, tl := range rend.Links {
 			tlb := tl.Bounds(rend, pos)
@@ -4332,8 +4678,10 @@ func (tv *TextView) StyleTextView() {
 	pst := &(tv.Par.(gi.Node2D).AsWidget().Sty)
 	for i := 0; i < int(TextViewStatesN); i++ {
 		tv.StateStyles[i].CopyFrom(&tv.Sty)
+		tv.StateStyles[i].StyleCSS(tv.This().(gi.Node2D), gi.Prefs.DefaultCSS(), TextViewSelectors[i], tv.Viewport)
 		tv.StateStyles[i].SetStyleProps(pst, tv.StyleProps(TextViewSelectors[i]), tv.Viewport)
 		tv.StateStyles[i].StyleCSS(tv.This().(gi.Node2D), tv.CSSAgg, TextViewSelectors[i], tv.Viewport)
+		tv.StateStyles[i].StyleCSS(tv.This().(gi.Node2D), gi.Prefs.OverrideCSS(), TextViewSelectors[i], tv.Viewport)
 		tv.StateStyles[i].CopyUnitContext(&tv.Sty.UnContext)
 	}
 	tv.CursorWidth.SetFmInheritProp("cursor-width", tv.This(), true, true) // inherit and get type defaults
@@ -4400,18 +4748,18 @@ func (tv *TextView) Render2D() {
 		tv.This().(gi.Node2D).ConnectEvents2D()
 		if tv.IsInactive() {
 			if tv.IsSelected() {
-				tv.Sty = tv.StateStyles[TextViewSel]
+				tv.TransitionStyle(&tv.StateStyles[TextViewSel])
 			} else {
-				tv.Sty = tv.StateStyles[TextViewInactive]
+				tv.TransitionStyle(&tv.StateStyles[TextViewInactive])
 			}
 		} else if tv.NLines == 0 {
-			tv.Sty = tv.StateStyles[TextViewInactive]
+			tv.TransitionStyle(&tv.StateStyles[TextViewInactive])
 		} else if tv.HasFocus() {
-			tv.Sty = tv.StateStyles[TextViewFocus]
+			tv.TransitionStyle(&tv.StateStyles[TextViewFocus])
 		} else if tv.IsSelected() {
-			tv.Sty = tv.StateStyles[TextViewSel]
+			tv.TransitionStyle(&tv.StateStyles[TextViewSel])
 		} else {
-			tv.Sty = tv.StateStyles[TextViewActive]
+			tv.TransitionStyle(&tv.StateStyles[TextViewActive])
 		}
 		tv.RenderAllLinesInBounds()
 		if tv.HasFocus() && tv.IsFocusActive() {
@@ -0,0 +1,491 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/chewxy/math32"
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/mimedata"
+	"github.com/goki/ki/ints"
+)
+
+// Multiple cursors: in addition to the main CursorPos and SelectReg, a
+// TextView can have any number of extra Cursors, each with its own
+// selection, and all edits made at the main cursor (typing, deleting,
+// cutting, pasting, completion) are made at each of them too, as one
+// undo group in the TextBuf, so they are undone together.  Cursors are added
+// with Alt+click (AddCursor), at the next match of the selected text
+// (AddCursorNextMatch), or by splitting the selection into one cursor per
+// line (SplitCursors), and moving the main cursor or pressing Esc removes
+// them.  In RectSelect mode, the selection is a rectangular block between
+// the columns of its start and end, which are in characters (not taking
+// tabs into account) -- edits turn it into a cursor on each of its lines.
+
+// HasCursors returns true if there are extra cursors, or a rectangular
+// selection, so that edits are made in multiple places
+func (tv *TextView) HasCursors() bool {
+	return len(tv.Cursors) > 0 || (tv.RectSelect && tv.HasSelection())
+}
+
+// CursorsReset removes all the extra cursors, leaving just the main one
+func (tv *TextView) CursorsReset() {
+	if len(tv.Cursors) == 0 {
+		return
+	}
+	tv.Cursors = nil
+	tv.RenderAllLines()
+}
+
+// AllCursors returns the main cursor, as its selection (or an empty region
+// at CursorPos if none), followed by all the extra Cursors
+func (tv *TextView) AllCursors() []TextRegion {
+	curs := make([]TextRegion, 0, len(tv.Cursors)+1)
+	if tv.HasSelection() {
+		curs = append(curs, tv.SelectReg)
+	} else {
+		curs = append(curs, TextRegion{Start: tv.CursorPos, End: tv.CursorPos})
+	}
+	return append(curs, tv.Cursors...)
+}
+
+// AddCursor adds a cursor at given position, which becomes the main
+// cursor, with the current one becoming an extra cursor -- if there is
+// already an extra cursor there, it is removed instead
+func (tv *TextView) AddCursor(pos TextPos) {
+	if tv.Buf == nil {
+		return
+	}
+	pos = tv.Buf.ValidPos(pos)
+	updt := tv.Viewport.Win.UpdateStart()
+	defer tv.Viewport.Win.UpdateEnd(updt)
+	if tv.RectSelect && tv.HasSelection() {
+		tv.RectToCursors()
+	}
+	for i, c := range tv.Cursors {
+		if c.End == pos {
+			tv.Cursors = append(tv.Cursors[:i], tv.Cursors[i+1:]...)
+			tv.RenderAllLines()
+			return
+		}
+	}
+	if pos == tv.CursorPos && !tv.HasSelection() {
+		return
+	}
+	tv.Cursors = append(tv.Cursors, tv.AllCursors()[0])
+	tv.SelectMode = false
+	tv.SelectReg = TextRegionNil
+	tv.PrevSelectReg = TextRegionNil
+	tv.SetCursorShow(pos)
+	tv.RenderAllLines()
+}
+
+// AddCursorNextMatch adds a cursor selecting the next match of the selected
+// text after the main cursor (wrapping around to the start), which becomes
+// the main cursor -- if nothing is selected, the word at the cursor is
+// selected first
+func (tv *TextView) AddCursorNextMatch() {
+	if tv.Buf == nil {
+		return
+	}
+	updt := tv.Viewport.Win.UpdateStart()
+	defer tv.Viewport.Win.UpdateEnd(updt)
+	if !tv.HasSelection() {
+		if tv.SelectWord() {
+			tv.CursorPos = tv.SelectReg.End
+			tv.RenderSelectLines()
+			tv.RenderCursor(true)
+		}
+		return
+	}
+	sel := tv.Selection()
+	_, matches := tv.Buf.Search(sel.ToBytes(), false)
+	curs := tv.AllCursors()
+	isCur := func(reg TextRegion) bool {
+		for _, c := range curs {
+			if c.Start == reg.Start && c.End == reg.End {
+				return true
+			}
+		}
+		return false
+	}
+	next := -1
+	for i, m := range matches {
+		if isCur(m.Reg) {
+			continue
+		}
+		if next < 0 {
+			next = i // first one, if wrapping around
+		}
+		if !m.Reg.Start.IsLess(tv.SelectReg.End) {
+			next = i
+			break
+		}
+	}
+	if next < 0 {
+		return
+	}
+	reg := matches[next].Reg
+	tv.Cursors = append(tv.Cursors, curs[0])
+	tv.SelectMode = false
+	tv.SelectReg = reg
+	tv.PrevSelectReg = TextRegionNil
+	tv.SetCursorShow(reg.End)
+	tv.RenderAllLines()
+}
+
+// SplitCursors splits the selection into a cursor on each of its lines, at
+// the end of the selected part of the line
+func (tv *TextView) SplitCursors() {
+	if !tv.HasSelection() {
+		return
+	}
+	var regs []TextRegion
+	if tv.RectSelect {
+		regs = tv.RectRegions()
+	} else {
+		regs = tv.Buf.LineRegions(tv.SelectReg)
+	}
+	if len(regs) == 0 {
+		return
+	}
+	updt := tv.Viewport.Win.UpdateStart()
+	defer tv.Viewport.Win.UpdateEnd(updt)
+	tv.SelectReset()
+	for i := range regs {
+		regs[i].Start = regs[i].End
+	}
+	last := len(regs) - 1
+	tv.Cursors = append(tv.Cursors, regs[:last]...)
+	tv.SetCursorShow(regs[last].End)
+	tv.uniqueCursors()
+	tv.RenderAllLines()
+}
+
+// RectSelectToggle toggles the RectSelect mode, in which the selection is a
+// rectangular block of text
+func (tv *TextView) RectSelectToggle() {
+	updt := tv.Viewport.Win.UpdateStart()
+	defer tv.Viewport.Win.UpdateEnd(updt)
+	tv.RectSelect = !tv.RectSelect
+	if tv.HasSelection() {
+		tv.RenderAllLines()
+	}
+}
+
+// RectRegions returns the region of text within the rectangular block
+// selection on each of its lines, which is empty for lines that are too
+// short -- nil if no selection
+func (tv *TextView) RectRegions() []TextRegion {
+	if !tv.HasSelection() {
+		return nil
+	}
+	return tv.Buf.RectRegions(tv.SelectReg)
+}
+
+// RectToCursors turns the rectangular block selection into a cursor on each
+// of its lines, each selecting its part of the block, with the main cursor
+// on the last line, and turns off RectSelect mode
+func (tv *TextView) RectToCursors() {
+	regs := tv.RectRegions()
+	tv.RectSelect = false
+	if len(regs) == 0 {
+		return
+	}
+	last := len(regs) - 1
+	tv.Cursors = append(tv.Cursors, regs[:last]...)
+	tv.SelectReg = regs[last]
+	tv.PrevSelectReg = TextRegionNil
+	tv.CursorPos = regs[last].End
+}
+
+// EditAtCursors calls given edit function, which edits the text at the
+// main cursor and its selection, at each of the cursors in turn (turning
+// any rectangular block selection into cursors first), with all the edits
+// in one undo group -- see TextBuf.EditAtRegions.  If there are no extra
+// cursors, it just calls edit.
+func (tv *TextView) EditAtCursors(edit func()) {
+	if tv.RectSelect && tv.HasSelection() {
+		tv.RectToCursors()
+	}
+	if len(tv.Cursors) == 0 {
+		edit()
+		return
+	}
+	updt := tv.Viewport.Win.UpdateStart()
+	defer tv.Viewport.Win.UpdateEnd(updt)
+	curs := tv.AllCursors()
+	tv.Cursors = nil // edits don't adjust them while we do so here
+	curs = tv.Buf.EditAtRegions(curs, func(reg TextRegion) TextPos {
+		tv.CursorPos = reg.End
+		if reg.Start.IsLess(reg.End) {
+			tv.SelectReg = reg
+		} else {
+			tv.SelectReg = TextRegionNil
+		}
+		edit()
+		tv.SelectReg = TextRegionNil
+		return tv.CursorPos
+	})
+	tv.SelectMode = false
+	tv.PrevSelectReg = TextRegionNil
+	tv.Cursors = curs[1:]
+	tv.SetCursorShow(curs[0].End)
+	tv.uniqueCursors()
+	tv.RenderAllLines()
+}
+
+// uniqueCursors removes any extra cursors at the same position as the main
+// cursor or another one, e.g., after deleting the text between them
+func (tv *TextView) uniqueCursors() {
+	ucs := tv.Cursors[:0]
+	for _, c := range tv.Cursors {
+		dup := c.End == tv.CursorPos
+		for _, uc := range ucs {
+			if uc.End == c.End {
+				dup = true
+				break
+			}
+		}
+		if !dup {
+			ucs = append(ucs, c)
+		}
+	}
+	if len(ucs) == 0 {
+		ucs = nil
+	}
+	tv.Cursors = ucs
+}
+
+// AdjustCursors moves the extra cursors to account for given edit, made
+// elsewhere than at the cursors
+func (tv *TextView) AdjustCursors(tbe *TextBufEdit) {
+	for i, c := range tv.Cursors {
+		c.Start = tbe.AdjustPos(c.Start, AdjustPosDelStart)
+		c.End = tbe.AdjustPos(c.End, AdjustPosDelStart)
+		tv.Cursors[i] = c
+	}
+}
+
+// CursorsText returns the text selected at each of the cursors, in order
+// of position, or within each line of the rectangular block selection, one
+// per line -- nil if none
+func (tv *TextView) CursorsText() [][]rune {
+	var regs []TextRegion
+	if tv.RectSelect && tv.HasSelection() {
+		regs = tv.RectRegions()
+	} else {
+		for _, c := range tv.AllCursors() {
+			if c.Start.IsLess(c.End) {
+				regs = append(regs, c)
+			}
+		}
+		sort.Slice(regs, func(i, j int) bool {
+			return regs[i].Start.IsLess(regs[j].Start)
+		})
+	}
+	if len(regs) == 0 {
+		return nil
+	}
+	txt := make([][]rune, 0, len(regs))
+	for _, reg := range regs {
+		tbe := tv.Buf.Region(reg.Start, reg.End)
+		if tbe == nil {
+			txt = append(txt, nil)
+			continue
+		}
+		txt = append(txt, bytes.Runes(tbe.ToBytes()))
+	}
+	return txt
+}
+
+// CopyCursors copies the text selected at each of the cursors, or within
+// the rectangular block selection, to the clipboard, one per line, and
+// returns that text, optionally resetting the selections
+func (tv *TextView) CopyCursors(reset bool) *TextBufEdit {
+	txt := tv.CursorsText()
+	if txt == nil {
+		return nil
+	}
+	updt := tv.Viewport.Win.UpdateStart()
+	defer tv.Viewport.Win.UpdateEnd(updt)
+	tbe := &TextBufEdit{Reg: tv.SelectReg, Text: txt}
+	cb := tbe.ToBytes()
+	TextViewClipHistAdd(cb)
+	oswin.TheApp.ClipBoard(tv.Viewport.Win.OSWin).Write(mimedata.NewTextBytes(cb))
+	if reset {
+		for i := range tv.Cursors {
+			tv.Cursors[i].Start = tv.Cursors[i].End
+		}
+		tv.SelectReset()
+		tv.RenderAllLines()
+	}
+	tv.SavePosHistory(tv.CursorPos)
+	return tbe
+}
+
+// PasteAtCursors inserts given text at each of the cursors -- if it has
+// as many lines as there are cursors, one line is inserted at each, in
+// order of position
+func (tv *TextView) PasteAtCursors(txt []byte) {
+	if tv.RectSelect && tv.HasSelection() {
+		tv.RectToCursors()
+	}
+	lns := pasteLines(txt, len(tv.Cursors)+1)
+	if lns == nil {
+		tv.EditAtCursors(func() {
+			tv.InsertAtCursor(txt)
+		})
+		return
+	}
+	li := len(lns)
+	tv.EditAtCursors(func() { // visits cursors from the end
+		li--
+		tv.InsertAtCursor(lns[li])
+	})
+}
+
+// completeAtCursor replaces given completion seed before the cursor with
+// given completion text, for completion at each of multiple cursors -- see
+// TextBuf.CompleteText
+func (tv *TextView) completeAtCursor(seed, text string, fwdDel, curAdj int) {
+	pos := tv.CursorPos
+	if fwdDel > 0 {
+		ed := pos
+		ed.Ch = ints.MinInt(ed.Ch+fwdDel, tv.Buf.LineLen(ed.Ln))
+		tv.Buf.DeleteText(pos, ed, true, true)
+	}
+	st := pos
+	sd := []rune(seed)
+	if sn := len(sd); sn > 0 && pos.Ch >= sn && string(tv.Buf.Line(pos.Ln)[pos.Ch-sn:pos.Ch]) == seed {
+		st.Ch -= sn
+		tv.Buf.DeleteText(st, pos, true, true)
+	}
+	tv.Buf.InsertText(st, []byte(text), true, true)
+	ep := st
+	ep.Ch += len([]rune(text)) + curAdj
+	tv.SetCursorShow(tv.Buf.ValidPos(ep))
+}
+
+// RenderCursors renders the selections and cursors of the extra Cursors,
+// as static vertical bars -- always called within context of outer
+// RenderLines or RenderAllLines
+func (tv *TextView) RenderCursors() {
+	if len(tv.Cursors) == 0 {
+		return
+	}
+	rs := &tv.Viewport.Render
+	pc := &rs.Paint
+	var cspec gi.ColorSpec
+	cspec.SetColor(tv.StateStyles[TextViewActive].Font.Color)
+	sz := gi.NewVec2D(math32.Max(tv.CursorWidth.Dots, 2), tv.FontHeight)
+	for _, c := range tv.Cursors {
+		if c.Start.IsLess(c.End) {
+			tv.RenderRegionBox(c, TextViewSel)
+		}
+		pos := tv.CharStartPos(c.End)
+		if int(pos.Y+sz.Y) < tv.VpBBox.Min.Y || int(pos.Y) > tv.VpBBox.Max.Y {
+			continue
+		}
+		pc.FillBox(rs, pos, sz, &cspec)
+	}
+}
+
+// KeyFunIsMove returns true if given key function moves the cursor
+func KeyFunIsMove(kf gi.KeyFuns) bool {
+	switch kf {
+	case gi.KeyFunMoveUp, gi.KeyFunMoveDown, gi.KeyFunMoveRight, gi.KeyFunMoveLeft,
+		gi.KeyFunPageUp, gi.KeyFunPageDown, gi.KeyFunHome, gi.KeyFunEnd,
+		gi.KeyFunDocHome, gi.KeyFunDocEnd, gi.KeyFunWordRight, gi.KeyFunWordLeft:
+		return true
+	}
+	return false
+}
+
+// pasteLines returns the lines of given pasted text if it has one line for
+// each of n cursors, to paste one line at each of them, in order of position
+// -- nil otherwise
+func pasteLines(txt []byte, n int) [][]byte {
+	lns := bytes.Split(bytes.TrimSuffix(txt, []byte("\n")), []byte("\n"))
+	if len(lns) != n {
+		return nil
+	}
+	return lns
+}
+
+// EditAtRegions calls given edit function at each of given cursor regions,
+// each with the cursor at the End of the region and the text from its Start,
+// if any, selected, with all the edits in one undo group -- edit returns the
+// position of the cursor after its edit.  The regions are visited in reverse
+// order of position, from the end of the text, and after each edit, the
+// cursors already visited are moved to account for it.  Returns the
+// resulting cursors, as empty regions, in the order of regs.
+func (tb *TextBuf) EditAtRegions(regs []TextRegion, edit func(reg TextRegion) TextPos) []TextRegion {
+	curs := make([]TextRegion, len(regs))
+	copy(curs, regs)
+	ord := make([]int, len(curs))
+	for i := range ord {
+		ord[i] = i
+	}
+	sort.SliceStable(ord, func(i, j int) bool {
+		return curs[ord[j]].Start.IsLess(curs[ord[i]].Start)
+	})
+	tb.BeginGroup()
+	for oi, ci := range ord {
+		u0 := tb.UndoPos
+		pos := edit(curs[ci])
+		curs[ci] = TextRegion{Start: pos, End: pos}
+		for _, pi := range ord[:oi] {
+			pos := curs[pi].End
+			for _, tbe := range tb.Undos[u0:tb.UndoPos] {
+				pos = tbe.AdjustPos(pos, AdjustPosDelStart)
+			}
+			curs[pi] = TextRegion{Start: pos, End: pos}
+		}
+	}
+	tb.EndGroup()
+	return curs
+}
+
+// LineRegions returns the part of given region on each of its lines, not
+// including the last line if the region ends at its start
+func (tb *TextBuf) LineRegions(reg TextRegion) []TextRegion {
+	st := reg.Start
+	ed := reg.End
+	var regs []TextRegion
+	for ln := st.Ln; ln <= ed.Ln; ln++ {
+		if ln == ed.Ln && ed.Ch == 0 && ln > st.Ln {
+			break
+		}
+		lr := NewTextRegion(ln, 0, ln, tb.LineLen(ln))
+		if ln == st.Ln {
+			lr.Start.Ch = st.Ch
+		}
+		if ln == ed.Ln {
+			lr.End.Ch = ed.Ch
+		}
+		regs = append(regs, lr)
+	}
+	return regs
+}
+
+// RectRegions returns the region of text on each line of given region that
+// is within the rectangular block between the columns of its start and end,
+// which is empty for lines that are too short
+func (tb *TextBuf) RectRegions(reg TextRegion) []TextRegion {
+	st := reg.Start
+	ed := reg.End
+	stc := ints.MinInt(st.Ch, ed.Ch)
+	edc := ints.MaxInt(st.Ch, ed.Ch)
+	regs := make([]TextRegion, 0, ed.Ln-st.Ln+1)
+	for ln := st.Ln; ln <= ed.Ln; ln++ {
+		ll := tb.LineLen(ln)
+		regs = append(regs, NewTextRegion(ln, ints.MinInt(stc, ll), ln, ints.MinInt(edc, ll)))
+	}
+	return regs
+}
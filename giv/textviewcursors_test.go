@@ -0,0 +1,227 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import (
+	"testing"
+)
+
+// insertAtReg returns an edit function for EditAtRegions that replaces the
+// selected text of the region, if any, with given text, as
+// TextView.InsertAtCursor does
+func insertAtReg(tb *TextBuf, txt string) func(reg TextRegion) TextPos {
+	return func(reg TextRegion) TextPos {
+		pos := reg.End
+		if reg.Start.IsLess(reg.End) {
+			tb.DeleteText(reg.Start, reg.End, true, true)
+			pos = reg.Start
+		}
+		return tb.InsertText(pos, []byte(txt), true, true).Reg.End
+	}
+}
+
+// backspaceAtReg returns an edit function for EditAtRegions that deletes the
+// selected text of the region, if any, or otherwise the character before
+// the cursor, joining lines at the start of a line, as
+// TextView.CursorBackspace does
+func backspaceAtReg(tb *TextBuf) func(reg TextRegion) TextPos {
+	return func(reg TextRegion) TextPos {
+		st, ed := reg.Start, reg.End
+		if !st.IsLess(ed) {
+			switch {
+			case ed.Ch > 0:
+				st = TextPos{ed.Ln, ed.Ch - 1}
+			case ed.Ln > 0:
+				st = TextPos{ed.Ln - 1, tb.LineLen(ed.Ln - 1)}
+			default:
+				return ed
+			}
+		}
+		tb.DeleteText(st, ed, true, true)
+		return st
+	}
+}
+
+func testCursors(t *testing.T, desc string, got, want []TextRegion) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Errorf("%v: cursors: %v, want: %v", desc, got, want)
+		return
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			t.Errorf("%v: cursors: %v, want: %v", desc, got, want)
+			return
+		}
+	}
+}
+
+// curs returns empty cursor regions at given positions
+func curs(pos ...TextPos) []TextRegion {
+	regs := make([]TextRegion, len(pos))
+	for i, p := range pos {
+		regs[i] = TextRegion{Start: p, End: p}
+	}
+	return regs
+}
+
+func TestEditAtRegions(t *testing.T) {
+	tests := []struct {
+		name  string
+		orig  string
+		regs  []TextRegion
+		ins   string // text to insert, or backspace if empty
+		want  string
+		wantC []TextRegion
+	}{
+		{"type on one line", "abc def ghi\n",
+			curs(TextPos{0, 4}, TextPos{0, 0}, TextPos{0, 8}), "xy",
+			"xyabc xydef xyghi\n", curs(TextPos{0, 8}, TextPos{0, 2}, TextPos{0, 14})},
+		{"type across lines", "ab\ncd\nef\n",
+			curs(TextPos{0, 2}, TextPos{1, 2}, TextPos{2, 2}), ";",
+			"ab;\ncd;\nef;\n", curs(TextPos{0, 3}, TextPos{1, 3}, TextPos{2, 3})},
+		{"newline on one line", "a b c\n",
+			curs(TextPos{0, 1}, TextPos{0, 3}, TextPos{0, 5}), "\n",
+			"a\n b\n c\n\n", curs(TextPos{1, 0}, TextPos{2, 0}, TextPos{3, 0})},
+		{"replace selections across lines", "one two\nthree four\n",
+			[]TextRegion{NewTextRegion(0, 4, 1, 5), NewTextRegion(0, 0, 0, 1), NewTextRegion(1, 6, 1, 10)}, "X",
+			"Xne X X\n", curs(TextPos{0, 5}, TextPos{0, 1}, TextPos{0, 7})},
+		{"backspace on one line", "abc def ghi\n",
+			curs(TextPos{0, 3}, TextPos{0, 7}, TextPos{0, 11}), "",
+			"ab de gh\n", curs(TextPos{0, 2}, TextPos{0, 5}, TextPos{0, 8})},
+		{"backspace across lines", "ab\ncd\nef\n",
+			curs(TextPos{2, 0}, TextPos{0, 1}, TextPos{1, 0}), "",
+			"bcdef\n", curs(TextPos{0, 3}, TextPos{0, 0}, TextPos{0, 1})},
+		{"delete selections across lines", "one two\nthree four\n",
+			[]TextRegion{NewTextRegion(0, 4, 1, 5), NewTextRegion(0, 0, 0, 1), NewTextRegion(1, 6, 1, 10)}, "",
+			"ne  \n", curs(TextPos{0, 3}, TextPos{0, 0}, TextPos{0, 4})},
+	}
+	for _, tst := range tests {
+		tb := newTestTextBuf(tst.orig)
+		edit := backspaceAtReg(tb)
+		if tst.ins != "" {
+			edit = insertAtReg(tb, tst.ins)
+		}
+		got := tb.EditAtRegions(tst.regs, edit)
+		if txt := string(tb.LinesToBytesCopy()); txt != tst.want {
+			t.Errorf("%v: text is: %q, want: %q", tst.name, txt, tst.want)
+		}
+		testCursors(t, tst.name, got, tst.wantC)
+		tb.Undo()
+		if txt := string(tb.LinesToBytesCopy()); txt != tst.orig {
+			t.Errorf("%v: text after one undo is: %q, want: %q", tst.name, txt, tst.orig)
+		}
+		tb.Redo()
+		if txt := string(tb.LinesToBytesCopy()); txt != tst.want {
+			t.Errorf("%v: text after one redo is: %q, want: %q", tst.name, txt, tst.want)
+		}
+	}
+}
+
+func TestEditAtRegionsTyping(t *testing.T) {
+	// typing one character at a time at 3 cursors, each keystroke made at
+	// each of the cursors in turn, as TextView.KeyInputInsertRune does
+	tb := newTestTextBuf("ab\ncd\nef\n")
+	cs := curs(TextPos{0, 1}, TextPos{1, 1}, TextPos{2, 1})
+	for _, r := range "x\ny" {
+		cs = tb.EditAtRegions(cs, insertAtReg(tb, string(r)))
+	}
+	testBufText(t, tb, "ax\nyb\ncx\nyd\nex\nyf\n")
+	testCursors(t, "typing", cs, curs(TextPos{1, 1}, TextPos{3, 1}, TextPos{5, 1}))
+	for range "x\ny" {
+		cs = tb.EditAtRegions(cs, backspaceAtReg(tb))
+	}
+	testBufText(t, tb, "ab\ncd\nef\n")
+	testCursors(t, "backspace", cs, curs(TextPos{0, 1}, TextPos{1, 1}, TextPos{2, 1}))
+	// each keystroke is its own group
+	tb.Undo()
+	testBufText(t, tb, "axb\ncxd\nexf\n")
+}
+
+func TestRectRegions(t *testing.T) {
+	tb := newTestTextBuf("abcdef\nab\nabcdefgh\n")
+	tests := []struct {
+		sel  TextRegion
+		want []TextRegion
+	}{
+		{NewTextRegion(0, 2, 2, 4), []TextRegion{NewTextRegion(0, 2, 0, 4), NewTextRegion(1, 2, 1, 2), NewTextRegion(2, 2, 2, 4)}},
+		{NewTextRegion(0, 5, 2, 1), []TextRegion{NewTextRegion(0, 1, 0, 5), NewTextRegion(1, 1, 1, 2), NewTextRegion(2, 1, 2, 5)}},
+		{NewTextRegion(1, 3, 2, 7), []TextRegion{NewTextRegion(1, 2, 1, 2), NewTextRegion(2, 3, 2, 7)}},
+		{NewTextRegion(0, 3, 0, 3), []TextRegion{NewTextRegion(0, 3, 0, 3)}},
+	}
+	for _, tst := range tests {
+		testCursors(t, "RectRegions", tb.RectRegions(tst.sel), tst.want)
+	}
+
+	// typing over a rectangular selection replaces each line of it
+	regs := tb.RectRegions(NewTextRegion(0, 2, 2, 4))
+	cs := tb.EditAtRegions(regs, insertAtReg(tb, "X"))
+	testBufText(t, tb, "abXef\nabX\nabXefgh\n")
+	testCursors(t, "rect typing", cs, curs(TextPos{0, 3}, TextPos{1, 3}, TextPos{2, 3}))
+	tb.Undo()
+	testBufText(t, tb, "abcdef\nab\nabcdefgh\n")
+}
+
+func TestLineRegions(t *testing.T) {
+	tb := newTestTextBuf("abcdef\nab\nabcdefgh\n")
+	tests := []struct {
+		sel  TextRegion
+		want []TextRegion
+	}{
+		{NewTextRegion(0, 2, 2, 3), []TextRegion{NewTextRegion(0, 2, 0, 6), NewTextRegion(1, 0, 1, 2), NewTextRegion(2, 0, 2, 3)}},
+		{NewTextRegion(0, 2, 2, 0), []TextRegion{NewTextRegion(0, 2, 0, 6), NewTextRegion(1, 0, 1, 2)}},
+		{NewTextRegion(1, 1, 1, 2), []TextRegion{NewTextRegion(1, 1, 1, 2)}},
+	}
+	for _, tst := range tests {
+		testCursors(t, "LineRegions", tb.LineRegions(tst.sel), tst.want)
+	}
+
+	// split cursors at the end of each line of the selection, as
+	// TextView.SplitCursors does, then type there
+	regs := tb.LineRegions(NewTextRegion(0, 2, 2, 3))
+	for i := range regs {
+		regs[i].Start = regs[i].End
+	}
+	cs := tb.EditAtRegions(regs, insertAtReg(tb, "-"))
+	testBufText(t, tb, "abcdef-\nab-\nabc-defgh\n")
+	testCursors(t, "split typing", cs, curs(TextPos{0, 7}, TextPos{1, 3}, TextPos{2, 4}))
+	tb.Undo()
+	testBufText(t, tb, "abcdef\nab\nabcdefgh\n")
+}
+
+func TestPasteAtRegions(t *testing.T) {
+	tests := []struct {
+		name  string
+		paste string
+		want  string
+	}{
+		{"one line each", "1\n2\n3\n", "a1\nb2\nc3\n"},
+		{"one line each no final lf", "1\n22\n333", "a1\nb22\nc333\n"},
+		{"whole at each", "x\ny", "ax\ny\nbx\ny\ncx\ny\n"},
+		{"single line", "--", "a--\nb--\nc--\n"},
+	}
+	for _, tst := range tests {
+		tb := newTestTextBuf("a\nb\nc\n")
+		regs := curs(TextPos{0, 1}, TextPos{1, 1}, TextPos{2, 1})
+		// as TextView.PasteAtCursors does
+		lns := pasteLines([]byte(tst.paste), len(regs))
+		if lns == nil {
+			tb.EditAtRegions(regs, insertAtReg(tb, tst.paste))
+		} else {
+			li := len(lns)
+			tb.EditAtRegions(regs, func(reg TextRegion) TextPos {
+				li--
+				return insertAtReg(tb, string(lns[li]))(reg)
+			})
+		}
+		if txt := string(tb.LinesToBytesCopy()); txt != tst.want {
+			t.Errorf("%v: text is: %q, want: %q", tst.name, txt, tst.want)
+		}
+		tb.Undo()
+		if txt := string(tb.LinesToBytesCopy()); txt != "a\nb\nc\n" {
+			t.Errorf("%v: text after one undo is: %q", tst.name, txt)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/units"
+	"github.com/goki/ki"
+	"github.com/goki/ki/kit"
+)
+
+// ThemesView opens a view of a themes table
+func ThemesView(th *gi.Themes) {
+	winm := "gogi-themes"
+	width := 800
+	height := 800
+	win := gi.NewWindow2D(winm, "GoGi Themes", width, height, true)
+
+	vp := win.WinViewport2D()
+	updt := vp.UpdateStart()
+
+	mfr := win.SetMainFrame()
+	mfr.Lay = gi.LayoutVert
+
+	title := mfr.AddNewChild(gi.KiT_Label, "title").(*gi.Label)
+	title.SetText("Available Themes: Duplicate an existing theme (using Ctxt Menu) as starting point for creating a custom theme")
+	title.SetProp("width", units.NewValue(30, units.Ch)) // need for wrap
+	title.SetStretchMaxWidth()
+	title.SetProp("white-space", gi.WhiteSpaceNormal) // wrap
+
+	tv := mfr.AddNewChild(KiT_TableView, "tv").(*TableView)
+	tv.Viewport = vp
+	tv.SetSlice(th, nil)
+	tv.SetStretchMaxWidth()
+	tv.SetStretchMaxHeight()
+
+	gi.AvailThemesChanged = false
+	tv.ViewSig.Connect(mfr.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		gi.AvailThemesChanged = true
+	})
+
+	mmen := win.MainMenu
+	MainMenuView(th, win, mmen)
+
+	inClosePrompt := false
+	win.OSWin.SetCloseReqFunc(func(w oswin.Window) {
+		if !gi.AvailThemesChanged || th != &gi.AvailThemes { // only for main avail themes..
+			win.Close()
+			return
+		}
+		if inClosePrompt {
+			return
+		}
+		inClosePrompt = true
+		gi.ChoiceDialog(vp, gi.DlgOpts{Title: "Save Themes Before Closing?",
+			Prompt: "Do you want to save any changes to std preferences themes file before closing, or Cancel the close and do a Save to a different file?"},
+			[]string{"Save and Close", "Discard and Close", "Cancel"},
+			win.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+				switch sig {
+				case 0:
+					th.SavePrefs()
+					fmt.Printf("Preferences Saved to %v\n", gi.PrefsThemesFileName)
+					win.Close()
+				case 1:
+					if th == &gi.AvailThemes {
+						th.OpenPrefs() // revert
+					}
+					win.Close()
+				case 2:
+					inClosePrompt = false
+					// default is to do nothing, i.e., cancel
+				}
+			})
+	})
+
+	win.MainMenuUpdated()
+
+	vp.UpdateEndNoSig(updt)
+	win.GoStartEventLoop()
+}
+
+////////////////////////////////////////////////////////////////////////////////////////
+//  ThemeValueView
+
+// ThemeValueView presents an action for displaying a ThemeName and selecting
+// from the available themes
+type ThemeValueView struct {
+	ValueViewBase
+}
+
+var KiT_ThemeValueView = kit.Types.AddType(&ThemeValueView{}, nil)
+
+func (vv *ThemeValueView) WidgetType() reflect.Type {
+	vv.WidgetTyp = gi.KiT_Action
+	return vv.WidgetTyp
+}
+
+func (vv *ThemeValueView) UpdateWidget() {
+	if vv.Widget == nil {
+		return
+	}
+	ac := vv.Widget.(*gi.Action)
+	txt := kit.ToString(vv.Value.Interface())
+	ac.SetFullReRender()
+	ac.SetText(txt)
+}
+
+func (vv *ThemeValueView) ConfigWidget(widg gi.Node2D) {
+	vv.Widget = widg
+	ac := vv.Widget.(*gi.Action)
+	ac.SetProp("border-radius", units.NewValue(4, units.Px))
+	ac.ActionSig.ConnectOnly(vv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		vvv, _ := recv.Embed(KiT_ThemeValueView).(*ThemeValueView)
+		ac := vvv.Widget.(*gi.Action)
+		vvv.Activate(ac.Viewport, nil, nil)
+	})
+	vv.UpdateWidget()
+}
+
+func (vv *ThemeValueView) HasAction() bool {
+	return true
+}
+
+func (vv *ThemeValueView) Activate(vp *gi.Viewport2D, dlgRecv ki.Ki, dlgFunc ki.RecvFunc) {
+	if vv.IsInactive() {
+		return
+	}
+	cur := kit.ToString(vv.Value.Interface())
+	_, curRow, _ := gi.AvailThemes.ThemeByName(gi.ThemeName(cur))
+	desc, _ := vv.Tag("desc")
+	TableViewSelectDialog(vp, &gi.AvailThemes, DlgOpts{Title: "Select a Theme", Prompt: desc}, curRow, nil,
+		vv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			if sig == int64(gi.DialogAccepted) {
+				ddlg, _ := send.(*gi.Dialog)
+				si := TableViewSelectDialogValue(ddlg)
+				if si >= 0 {
+					th := gi.AvailThemes[si]
+					vv.SetValue(th.Name)
+					vv.UpdateWidget()
+				}
+			}
+			if dlgRecv != nil && dlgFunc != nil {
+				dlgFunc(dlgRecv, send, sig, data)
+			}
+		})
+}
@@ -2127,13 +2127,13 @@ func (tv *TreeView) Render2D() {
 	if tv.PushBounds() {
 		tv.UpdateInactive()
 		if tv.IsSelected() {
-			tv.Sty = tv.StateStyles[TreeViewSel]
+			tv.TransitionStyle(&tv.StateStyles[TreeViewSel])
 		} else if tv.HasFocus() {
-			tv.Sty = tv.StateStyles[TreeViewFocus]
+			tv.TransitionStyle(&tv.StateStyles[TreeViewFocus])
 		} else if tv.IsInactive() {
-			tv.Sty = tv.StateStyles[TreeViewInactive]
+			tv.TransitionStyle(&tv.StateStyles[TreeViewInactive])
 		} else {
-			tv.Sty = tv.StateStyles[TreeViewActive]
+			tv.TransitionStyle(&tv.StateStyles[TreeViewActive])
 		}
 		tv.ConfigPartsIfNeeded()
 		tv.This().(gi.Node2D).ConnectEvents2D()
@@ -118,6 +118,11 @@ func ToValueView(it interface{}, tags string) ValueView {
 		vv.Init(&vv)
 		return &vv
 	}
+	if nptyp == reflect.TypeOf(gi.ThemeName("")) {
+		vv := ThemeValueView{}
+		vv.Init(&vv)
+		return &vv
+	}
 	if nptyp == reflect.TypeOf(key.Chord("")) {
 		vv := KeyChordValueView{}
 		vv.Init(&vv)
@@ -808,6 +813,15 @@ func (vi *ViewIFace) HiStylesView(styles interface{}) {
 	HiStylesView(styles.(*histyle.Styles))
 }
 
+func (vi *ViewIFace) ThemesView(themes *gi.Themes) {
+	ThemesView(themes)
+}
+
+func (vi *ViewIFace) SetHiStyleDefault(style string) {
+	histyle.StyleDefault = histyle.StyleName(style)
+	FileNodeHiStyle = histyle.StyleDefault
+}
+
 func (vi *ViewIFace) PrefsDetDefaults(pf *gi.PrefsDetailed) {
 	pf.TextViewClipHistMax = TextViewClipHistMax
 	pf.MapInlineLen = MapInlineLen
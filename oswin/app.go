@@ -153,6 +153,9 @@ const (
 	// Windows is a Microsoft Windows machine
 	Windows
 
+	// Headless is the in-memory headless driver, with no display at all
+	Headless
+
 	PlatformsN
 )
 
@@ -8,6 +8,7 @@
 // license that can be found in the LICENSE file.
 
 // +build darwin
+// +build !headless
 
 package driver
 
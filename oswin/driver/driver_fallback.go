@@ -12,6 +12,7 @@
 // +build !windows
 // +build !dragonfly
 // +build !openbsd
+// +build !headless
 
 package driver
 
@@ -19,9 +20,9 @@ import (
 	"errors"
 
 	"github.com/goki/gi/oswin"
-	"github.com/goki/gi/oswin/driver/internal/errscreen"
+	"github.com/goki/gi/oswin/driver/internal/errapp"
 )
 
 func main(f func(oswin.App)) {
-	f(errscreen.Stub(errors.New("no driver for accessing a screen")))
+	f(errapp.Stub(errors.New("no driver for accessing a screen")))
 }
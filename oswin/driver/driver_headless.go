@@ -0,0 +1,16 @@
+// Copyright 2018 The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build headless
+
+package driver
+
+import (
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/driver/headless"
+)
+
+func main(f func(oswin.App)) {
+	headless.Main(f)
+}
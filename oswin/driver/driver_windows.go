@@ -7,10 +7,12 @@
 // Use of this source code is governed by a BSD-style
 // license that can be found in the LICENSE file.
 
+// +build !headless
+
 package driver
 
 import (
-        "github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin"
 	"github.com/goki/gi/oswin/driver/windriver"
 )
 
@@ -8,6 +8,7 @@
 // license that can be found in the LICENSE file.
 
 // +build linux,!android dragonfly openbsd
+// +build !headless
 
 package driver
 
@@ -0,0 +1,324 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package headless
+
+import (
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/clip"
+	"github.com/goki/gi/oswin/cursor"
+	"github.com/goki/gi/oswin/window"
+	"github.com/goki/ki/bitflag"
+)
+
+type appImpl struct {
+	mu            sync.Mutex
+	windows       map[*windowImpl]struct{}
+	winlist       []*windowImpl
+	screens       []*oswin.Screen
+	ctxtwin       *windowImpl
+	name          string
+	about         string
+	quitting      bool // set to true when quitting and closing windows
+	quitReqFunc   func()
+	quitCleanFunc func()
+}
+
+var theApp *appImpl
+
+func newAppImpl() *appImpl {
+	app := &appImpl{
+		windows: make(map[*windowImpl]struct{}),
+		winlist: make([]*windowImpl, 0),
+		name:    "GoGi",
+	}
+
+	const mmPerInch = 25.4
+	sc := &oswin.Screen{
+		ScreenNumber:     0,
+		Geometry:         image.Rectangle{Max: ScreenSize},
+		Depth:            32,
+		LogicalDPI:       ScreenDPI,
+		PhysicalDPI:      ScreenDPI,
+		DevicePixelRatio: 1,
+		RefreshRate:      60,
+		PhysicalSize: image.Point{
+			int(float32(ScreenSize.X) * mmPerInch / ScreenDPI),
+			int(float32(ScreenSize.Y) * mmPerInch / ScreenDPI),
+		},
+		Name: "headless",
+	}
+	app.screens = []*oswin.Screen{sc}
+
+	oswin.TheApp = app
+	theApp = app
+	return app
+}
+
+func (app *appImpl) NewImage(size image.Point) (oswin.Image, error) {
+	if size.X < 0 || size.Y < 0 {
+		return nil, fmt.Errorf("headless: invalid image size %v", size)
+	}
+	return &imageImpl{
+		rgba: image.NewRGBA(image.Rectangle{Max: size}),
+		size: size,
+	}, nil
+}
+
+func (app *appImpl) NewTexture(win oswin.Window, size image.Point) (oswin.Texture, error) {
+	if size.X < 0 || size.Y < 0 {
+		return nil, fmt.Errorf("headless: invalid texture size %v", size)
+	}
+	nt := &textureImpl{
+		size: size,
+		rgba: image.NewRGBA(image.Rectangle{Max: size}),
+	}
+	if ww, ok := win.(*windowImpl); ok {
+		nt.w = ww
+		ww.AddTexture(nt)
+	}
+	return nt, nil
+}
+
+func (app *appImpl) NewWindow(opts *oswin.NewWindowOptions) (oswin.Window, error) {
+	if opts == nil {
+		opts = &oswin.NewWindowOptions{}
+	}
+	opts.Fixup()
+
+	sc := app.Screen(0)
+	w := &windowImpl{
+		app:  app,
+		back: image.NewRGBA(image.Rectangle{Max: opts.Size}),
+		WindowBase: oswin.WindowBase{
+			Titl:    opts.GetTitle(),
+			Sz:      opts.Size,
+			Pos:     opts.Pos,
+			PhysDPI: sc.PhysicalDPI,
+			LogDPI:  sc.LogicalDPI,
+			Scrn:    sc,
+			Flag:    opts.Flags,
+		},
+	}
+	w.front = image.NewRGBA(w.back.Rect)
+
+	app.mu.Lock()
+	for _, ow := range app.winlist {
+		ow.mu.Lock()
+		bitflag.Clear(&ow.Flag, int(oswin.Focus))
+		ow.mu.Unlock()
+	}
+	bitflag.Set(&w.Flag, int(oswin.Focus))
+	app.windows[w] = struct{}{}
+	app.winlist = append(app.winlist, w)
+	app.mu.Unlock()
+
+	// there is no window manager, so we just tell the window that it is
+	// mapped, focused and ready to paint, as a real OS would
+	sendWindowEvent(w, window.Focus)
+	sendWindowEvent(w, window.Paint)
+	return w, nil
+}
+
+func (app *appImpl) DeleteWin(w *windowImpl) {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	if _, ok := app.windows[w]; !ok {
+		return
+	}
+	for i, wl := range app.winlist {
+		if wl == w {
+			app.winlist = append(app.winlist[:i], app.winlist[i+1:]...)
+			break
+		}
+	}
+	delete(app.windows, w)
+	if app.ctxtwin == w {
+		app.ctxtwin = nil
+	}
+}
+
+func (app *appImpl) NScreens() int {
+	return len(app.screens)
+}
+
+func (app *appImpl) Screen(scrN int) *oswin.Screen {
+	sz := len(app.screens)
+	if scrN < sz {
+		return app.screens[scrN]
+	}
+	return nil
+}
+
+func (app *appImpl) NWindows() int {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	return len(app.winlist)
+}
+
+func (app *appImpl) Window(win int) oswin.Window {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	sz := len(app.winlist)
+	if win < sz {
+		return app.winlist[win]
+	}
+	return nil
+}
+
+func (app *appImpl) WindowByName(name string) oswin.Window {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	for _, win := range app.winlist {
+		if win.Name() == name {
+			return win
+		}
+	}
+	return nil
+}
+
+func (app *appImpl) WindowInFocus() oswin.Window {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	for _, win := range app.winlist {
+		if win.IsFocus() {
+			return win
+		}
+	}
+	return nil
+}
+
+func (app *appImpl) ContextWindow() oswin.Window {
+	app.mu.Lock()
+	cw := app.ctxtwin
+	app.mu.Unlock()
+	if cw == nil {
+		return nil
+	}
+	return cw
+}
+
+func (app *appImpl) Platform() oswin.Platforms {
+	return oswin.Headless
+}
+
+func (app *appImpl) Name() string {
+	return app.name
+}
+
+func (app *appImpl) SetName(name string) {
+	app.name = name
+}
+
+func (app *appImpl) PrefsDir() string {
+	if PrefsDir != "" {
+		return PrefsDir
+	}
+	return filepath.Join(os.TempDir(), "gogi-headless")
+}
+
+func (app *appImpl) GoGiPrefsDir() string {
+	pdir := filepath.Join(app.PrefsDir(), "GoGi")
+	os.MkdirAll(pdir, 0755)
+	return pdir
+}
+
+func (app *appImpl) AppPrefsDir() string {
+	pdir := filepath.Join(app.PrefsDir(), app.Name())
+	os.MkdirAll(pdir, 0755)
+	return pdir
+}
+
+func (app *appImpl) FontPaths() []string {
+	switch runtime.GOOS {
+	case "darwin":
+		return []string{"/System/Library/Fonts", "/Library/Fonts"}
+	case "windows":
+		return []string{"C:\\Windows\\Fonts"}
+	}
+	return []string{"/usr/share/fonts/truetype"}
+}
+
+func (app *appImpl) setContextWin(win oswin.Window) {
+	ww, ok := win.(*windowImpl)
+	if !ok {
+		return
+	}
+	app.mu.Lock()
+	app.ctxtwin = ww
+	app.mu.Unlock()
+}
+
+func (app *appImpl) ClipBoard(win oswin.Window) clip.Board {
+	app.setContextWin(win)
+	return &theClip
+}
+
+func (app *appImpl) Cursor(win oswin.Window) cursor.Cursor {
+	app.setContextWin(win)
+	return &theCursor
+}
+
+func (app *appImpl) About() string {
+	return app.about
+}
+
+func (app *appImpl) SetAbout(about string) {
+	app.about = about
+}
+
+func (app *appImpl) OpenURL(url string) {
+	// nothing to open a url with
+}
+
+func (app *appImpl) SetQuitReqFunc(fun func()) {
+	app.quitReqFunc = fun
+}
+
+func (app *appImpl) SetQuitCleanFunc(fun func()) {
+	app.quitCleanFunc = fun
+}
+
+func (app *appImpl) QuitReq() {
+	if app.quitting {
+		return
+	}
+	if app.quitReqFunc != nil {
+		app.quitReqFunc()
+	} else {
+		app.Quit()
+	}
+}
+
+func (app *appImpl) IsQuitting() bool {
+	return app.quitting
+}
+
+func (app *appImpl) QuitClean() {
+	app.quitting = true
+	if app.quitCleanFunc != nil {
+		app.quitCleanFunc()
+	}
+	app.mu.Lock()
+	wins := make([]*windowImpl, len(app.winlist))
+	copy(wins, app.winlist)
+	app.mu.Unlock()
+	for i := len(wins) - 1; i >= 0; i-- {
+		wins[i].Close()
+	}
+}
+
+func (app *appImpl) Quit() {
+	app.QuitClean()
+}
+
+// check for interface implementation
+var _ oswin.App = &appImpl{}
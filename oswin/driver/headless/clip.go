@@ -0,0 +1,59 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package headless
+
+import (
+	"sync"
+
+	"github.com/goki/gi/oswin/mimedata"
+)
+
+// clipImpl is a purely in-process clipboard -- it is shared by all windows
+type clipImpl struct {
+	mu   sync.Mutex
+	data mimedata.Mimes
+}
+
+var theClip = clipImpl{}
+
+func (ci *clipImpl) IsEmpty() bool {
+	ci.mu.Lock()
+	defer ci.mu.Unlock()
+	return len(ci.data) == 0
+}
+
+func (ci *clipImpl) Read(types []string) mimedata.Mimes {
+	ci.mu.Lock()
+	defer ci.mu.Unlock()
+	if len(ci.data) == 0 {
+		return nil
+	}
+	if types == nil {
+		return ci.data
+	}
+	var md mimedata.Mimes
+	for _, d := range ci.data {
+		for _, typ := range types {
+			if d.Type == typ {
+				md = append(md, d)
+				break
+			}
+		}
+	}
+	return md
+}
+
+func (ci *clipImpl) Write(data mimedata.Mimes) error {
+	ci.mu.Lock()
+	ci.data = data
+	ci.mu.Unlock()
+	return nil
+}
+
+func (ci *clipImpl) Clear() {
+	ci.mu.Lock()
+	ci.data = nil
+	ci.mu.Unlock()
+}
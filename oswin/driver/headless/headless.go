@@ -0,0 +1,77 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package headless provides a pure-Go, in-memory driver for oswin that does
+// not require any display hardware.  Windows, Images and Textures are all
+// plain *image.RGBA buffers, and the Screen is a fake one whose size and DPI
+// are set by the variables in this package (or the GOGI_HEADLESS_SIZE and
+// GOGI_HEADLESS_DPI environment variables).
+//
+// This is useful for running tests on CI machines, and for server-side
+// rendering of whole gi.Window trees.  It is selected by the driver package
+// when built with the headless build tag, or when the GOGI_DRIVER
+// environment variable is set to headless.
+package headless
+
+import (
+	"fmt"
+	"image"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/goki/gi/oswin"
+)
+
+// ScreenSize is the size of the fake screen, in raw pixels -- can be
+// overridden by the GOGI_HEADLESS_SIZE environment variable, e.g., 1280x800
+var ScreenSize = image.Point{1920, 1080}
+
+// ScreenDPI is the physical DPI of the fake screen -- it is also the initial
+// logical DPI -- can be overridden by the GOGI_HEADLESS_DPI environment variable
+var ScreenDPI = float32(96)
+
+// PrefsDir is the preferences directory returned by App.PrefsDir -- if
+// empty, a directory under os.TempDir is used, so that headless runs are not
+// affected by the user's own preferences.
+var PrefsDir = ""
+
+// Main is called by the program's main function to run the graphical
+// application.
+//
+// It calls f on the App, in the same goroutine.  It returns when f returns.
+func Main(f func(oswin.App)) {
+	if err := screenFromEnv(); err != nil {
+		fmt.Printf("headless: %v\n", err)
+	}
+	app := newAppImpl()
+	f(app)
+}
+
+// screenFromEnv updates ScreenSize and ScreenDPI from the environment, if set
+func screenFromEnv() error {
+	if sz := os.Getenv("GOGI_HEADLESS_SIZE"); sz != "" {
+		wh := strings.Split(strings.ToLower(sz), "x")
+		if len(wh) != 2 {
+			return fmt.Errorf("GOGI_HEADLESS_SIZE must be WxH, got: %v", sz)
+		}
+		w, err := strconv.Atoi(strings.TrimSpace(wh[0]))
+		if err != nil {
+			return err
+		}
+		h, err := strconv.Atoi(strings.TrimSpace(wh[1]))
+		if err != nil {
+			return err
+		}
+		ScreenSize = image.Point{w, h}
+	}
+	if ds := os.Getenv("GOGI_HEADLESS_DPI"); ds != "" {
+		dpi, err := strconv.ParseFloat(strings.TrimSpace(ds), 32)
+		if err != nil {
+			return err
+		}
+		ScreenDPI = float32(dpi)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package headless
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+
+	"github.com/goki/gi/oswin"
+	xdraw "golang.org/x/image/draw"
+	"golang.org/x/image/math/f64"
+)
+
+type imageImpl struct {
+	rgba *image.RGBA
+	size image.Point
+}
+
+func (b *imageImpl) Release()                {}
+func (b *imageImpl) Size() image.Point       { return b.size }
+func (b *imageImpl) Bounds() image.Rectangle { return image.Rectangle{Max: b.size} }
+func (b *imageImpl) RGBA() *image.RGBA       { return b.rgba }
+
+// check for interface implementation
+var _ oswin.Image = &imageImpl{}
+
+// upload copies the sr region of src into dst at dp, using draw.Src
+func upload(dst *image.RGBA, dp image.Point, src oswin.Image, sr image.Rectangle) {
+	dr := image.Rectangle{Min: dp, Max: dp.Add(sr.Size())}
+	draw.Draw(dst, dr, src.RGBA(), sr.Min, draw.Src)
+}
+
+// fill fills the dr region of dst with the uniform src color
+func fill(dst *image.RGBA, dr image.Rectangle, src color.Color, op draw.Op) {
+	draw.Draw(dst, dr, image.NewUniform(src), image.ZP, op)
+}
+
+// isTranslation returns true if the transform is a pure integer translation, in
+// which case it returns that translation
+func isTranslation(src2dst *f64.Aff3) (image.Point, bool) {
+	if src2dst[0] != 1 || src2dst[1] != 0 || src2dst[3] != 0 || src2dst[4] != 1 {
+		return image.ZP, false
+	}
+	tx, ty := int(src2dst[2]), int(src2dst[5])
+	if float64(tx) != src2dst[2] || float64(ty) != src2dst[5] {
+		return image.ZP, false
+	}
+	return image.Point{tx, ty}, true
+}
+
+// drawImage draws the sr region of src onto dst, transformed by src2dst
+func drawImage(dst *image.RGBA, src2dst *f64.Aff3, src image.Image, sr image.Rectangle, op draw.Op) {
+	if sr.Empty() {
+		return
+	}
+	if off, ok := isTranslation(src2dst); ok {
+		dr := sr.Add(off)
+		draw.Draw(dst, dr, src, sr.Min, op)
+		return
+	}
+	xdraw.ApproxBiLinear.Transform(dst, *src2dst, src, sr, op, nil)
+}
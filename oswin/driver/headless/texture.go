@@ -0,0 +1,57 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package headless
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"sync"
+
+	"github.com/goki/gi/oswin"
+)
+
+type textureImpl struct {
+	w    *windowImpl
+	size image.Point
+	rgba *image.RGBA
+
+	mu       sync.Mutex
+	released bool
+}
+
+func (t *textureImpl) degenerate() bool        { return t.size.X == 0 || t.size.Y == 0 }
+func (t *textureImpl) Size() image.Point       { return t.size }
+func (t *textureImpl) Bounds() image.Rectangle { return image.Rectangle{Max: t.size} }
+
+func (t *textureImpl) Release() {
+	t.mu.Lock()
+	t.released = true
+	t.mu.Unlock()
+	if t.w != nil {
+		t.w.DeleteTexture(t)
+	}
+}
+
+func (t *textureImpl) Upload(dp image.Point, src oswin.Image, sr image.Rectangle) {
+	if t.degenerate() {
+		return
+	}
+	t.mu.Lock()
+	upload(t.rgba, dp, src, sr)
+	t.mu.Unlock()
+}
+
+func (t *textureImpl) Fill(dr image.Rectangle, src color.Color, op draw.Op) {
+	if t.degenerate() {
+		return
+	}
+	t.mu.Lock()
+	fill(t.rgba, dr, src, op)
+	t.mu.Unlock()
+}
+
+// check for interface implementation
+var _ oswin.Texture = &textureImpl{}
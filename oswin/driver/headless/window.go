@@ -0,0 +1,374 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package headless
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"sync"
+	"time"
+
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/driver/internal/drawer"
+	"github.com/goki/gi/oswin/driver/internal/event"
+	"github.com/goki/gi/oswin/window"
+	"github.com/goki/ki/bitflag"
+	"golang.org/x/image/math/f64"
+)
+
+type windowImpl struct {
+	oswin.WindowBase
+
+	app *appImpl
+
+	event.Deque
+
+	// back is the back buffer that all Upload / Draw calls render into
+	back *image.RGBA
+
+	// front is the last published image of the window -- see Capture
+	front *image.RGBA
+
+	// textures are the textures created for this window -- they are released
+	// when the window is closed
+	textures map[*textureImpl]struct{}
+
+	// idleMu protects pending and waiting, which track whether the event
+	// loop of the window is blocked waiting for events -- see WaitIdle
+	idleMu  sync.Mutex
+	pending int
+	waiting bool
+
+	mu             sync.Mutex
+	released       bool
+	closeReqFunc   func(win oswin.Window)
+	closeCleanFunc func(win oswin.Window)
+}
+
+// for sending window.Event's
+func sendWindowEvent(w *windowImpl, act window.Actions) {
+	winEv := window.Event{
+		Action: act,
+	}
+	winEv.Init()
+	w.Send(&winEv)
+}
+
+// Capture returns a copy of the most recently published contents of the
+// given window, which must have been created by the headless driver --
+// returns nil otherwise.
+func Capture(win oswin.Window) *image.RGBA {
+	w, ok := win.(*windowImpl)
+	if !ok {
+		return nil
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	img := image.NewRGBA(w.front.Rect)
+	copy(img.Pix, w.front.Pix)
+	return img
+}
+
+// Send implements the oswin.EventDeque interface.
+func (w *windowImpl) Send(event oswin.Event) {
+	w.idleMu.Lock()
+	w.pending++
+	w.idleMu.Unlock()
+	w.Deque.Send(event)
+}
+
+// SendFirst implements the oswin.EventDeque interface.
+func (w *windowImpl) SendFirst(event oswin.Event) {
+	w.idleMu.Lock()
+	w.pending++
+	w.idleMu.Unlock()
+	w.Deque.SendFirst(event)
+}
+
+// NextEvent implements the oswin.EventDeque interface.
+func (w *windowImpl) NextEvent() oswin.Event {
+	w.idleMu.Lock()
+	w.waiting = true
+	w.idleMu.Unlock()
+	ev := w.Deque.NextEvent()
+	w.idleMu.Lock()
+	w.pending--
+	w.waiting = false
+	w.idleMu.Unlock()
+	return ev
+}
+
+// isIdle returns true if the event loop is waiting and there are no pending events
+func (w *windowImpl) isIdle() bool {
+	w.idleMu.Lock()
+	defer w.idleMu.Unlock()
+	return w.waiting && w.pending == 0
+}
+
+// IdlePollMSec is the number of milliseconds between checks in WaitIdle
+var IdlePollMSec = 1
+
+// WaitIdle waits until the event loop of the given window, which must have
+// been created by the headless driver, has processed all pending events and
+// is blocked waiting for new ones, or until the window is closed.  Returns
+// false if that did not happen within the given timeout.
+func WaitIdle(win oswin.Window, timeout time.Duration) bool {
+	w, ok := win.(*windowImpl)
+	if !ok {
+		return false
+	}
+	poll := time.Duration(IdlePollMSec) * time.Millisecond
+	deadline := time.Now().Add(timeout)
+	for {
+		w.mu.Lock()
+		released := w.released
+		w.mu.Unlock()
+		if released || w.isIdle() {
+			return true
+		}
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(poll)
+	}
+}
+
+func (w *windowImpl) Upload(dp image.Point, src oswin.Image, sr image.Rectangle) {
+	w.mu.Lock()
+	upload(w.back, dp, src, sr)
+	w.mu.Unlock()
+}
+
+func (w *windowImpl) Fill(dr image.Rectangle, src color.Color, op draw.Op) {
+	w.mu.Lock()
+	fill(w.back, dr, src, op)
+	w.mu.Unlock()
+}
+
+func (w *windowImpl) DrawUniform(src2dst f64.Aff3, src color.Color, sr image.Rectangle, op draw.Op, opts *oswin.DrawOptions) {
+	w.mu.Lock()
+	drawImage(w.back, &src2dst, image.NewUniform(src), sr, op)
+	w.mu.Unlock()
+}
+
+func (w *windowImpl) Draw(src2dst f64.Aff3, src oswin.Texture, sr image.Rectangle, op draw.Op, opts *oswin.DrawOptions) {
+	t := src.(*textureImpl)
+	t.mu.Lock()
+	w.mu.Lock()
+	drawImage(w.back, &src2dst, t.rgba, sr, op)
+	w.mu.Unlock()
+	t.mu.Unlock()
+}
+
+func (w *windowImpl) Copy(dp image.Point, src oswin.Texture, sr image.Rectangle, op draw.Op, opts *oswin.DrawOptions) {
+	drawer.Copy(w, dp, src, sr, op, opts)
+}
+
+func (w *windowImpl) Scale(dr image.Rectangle, src oswin.Texture, sr image.Rectangle, op draw.Op, opts *oswin.DrawOptions) {
+	drawer.Scale(w, dr, src, sr, op, opts)
+}
+
+func (w *windowImpl) Publish() oswin.PublishResult {
+	w.mu.Lock()
+	copy(w.front.Pix, w.back.Pix)
+	w.mu.Unlock()
+	return oswin.PublishResult{BackImagePreserved: true}
+}
+
+func (w *windowImpl) Screen() *oswin.Screen {
+	w.mu.Lock()
+	if w.Scrn == nil {
+		w.Scrn = theApp.Screen(0)
+	}
+	sc := w.Scrn
+	w.mu.Unlock()
+	return sc
+}
+
+func (w *windowImpl) Size() image.Point {
+	w.mu.Lock()
+	sz := w.Sz
+	w.mu.Unlock()
+	return sz
+}
+
+func (w *windowImpl) Position() image.Point {
+	w.mu.Lock()
+	ps := w.Pos
+	w.mu.Unlock()
+	return ps
+}
+
+func (w *windowImpl) PhysicalDPI() float32 {
+	w.mu.Lock()
+	dpi := w.PhysDPI
+	w.mu.Unlock()
+	return dpi
+}
+
+func (w *windowImpl) LogicalDPI() float32 {
+	w.mu.Lock()
+	dpi := w.LogDPI
+	w.mu.Unlock()
+	return dpi
+}
+
+func (w *windowImpl) SetLogicalDPI(dpi float32) {
+	w.mu.Lock()
+	w.LogDPI = dpi
+	w.mu.Unlock()
+}
+
+func (w *windowImpl) SetTitle(title string) {
+	w.Titl = title
+}
+
+// resize reallocates the buffers for a new size -- must be called under mu
+func (w *windowImpl) resize(sz image.Point) {
+	w.Sz = sz
+	w.back = image.NewRGBA(image.Rectangle{Max: sz})
+	w.front = image.NewRGBA(w.back.Rect)
+}
+
+func (w *windowImpl) SetSize(sz image.Point) {
+	w.mu.Lock()
+	if w.Sz == sz {
+		w.mu.Unlock()
+		return
+	}
+	w.resize(sz)
+	w.mu.Unlock()
+	sendWindowEvent(w, window.Resize)
+}
+
+func (w *windowImpl) SetPos(pos image.Point) {
+	w.mu.Lock()
+	w.Pos = pos
+	w.mu.Unlock()
+	sendWindowEvent(w, window.Move)
+}
+
+func (w *windowImpl) SetGeom(pos image.Point, sz image.Point) {
+	w.mu.Lock()
+	w.Pos = pos
+	resized := w.Sz != sz
+	if resized {
+		w.resize(sz)
+	}
+	w.mu.Unlock()
+	if resized {
+		sendWindowEvent(w, window.Resize)
+	} else {
+		sendWindowEvent(w, window.Move)
+	}
+}
+
+func (w *windowImpl) MainMenu() oswin.MainMenu {
+	return nil
+}
+
+func (w *windowImpl) Raise() {
+	w.app.mu.Lock()
+	for _, ow := range w.app.winlist {
+		if ow == w {
+			continue
+		}
+		ow.mu.Lock()
+		had := bitflag.Has(ow.Flag, int(oswin.Focus))
+		bitflag.Clear(&ow.Flag, int(oswin.Focus))
+		ow.mu.Unlock()
+		if had {
+			sendWindowEvent(ow, window.DeFocus)
+		}
+	}
+	w.app.mu.Unlock()
+
+	w.mu.Lock()
+	wasMin := bitflag.Has(w.Flag, int(oswin.Minimized))
+	bitflag.Clear(&w.Flag, int(oswin.Minimized))
+	bitflag.Set(&w.Flag, int(oswin.Focus))
+	w.mu.Unlock()
+	sendWindowEvent(w, window.Focus)
+	if wasMin {
+		sendWindowEvent(w, window.Paint)
+	}
+}
+
+func (w *windowImpl) Minimize() {
+	w.mu.Lock()
+	bitflag.Set(&w.Flag, int(oswin.Minimized))
+	bitflag.Clear(&w.Flag, int(oswin.Focus))
+	w.mu.Unlock()
+	sendWindowEvent(w, window.Minimize)
+}
+
+func (w *windowImpl) AddTexture(t *textureImpl) {
+	w.mu.Lock()
+	if w.textures == nil {
+		w.textures = make(map[*textureImpl]struct{})
+	}
+	w.textures[t] = struct{}{}
+	w.mu.Unlock()
+}
+
+// DeleteTexture just deletes it from our list -- does not Release -- is called during t.Release
+func (w *windowImpl) DeleteTexture(t *textureImpl) {
+	w.mu.Lock()
+	if w.textures != nil {
+		delete(w.textures, t)
+	}
+	w.mu.Unlock()
+}
+
+func (w *windowImpl) SetCloseReqFunc(fun func(win oswin.Window)) {
+	w.closeReqFunc = fun
+}
+
+func (w *windowImpl) SetCloseCleanFunc(fun func(win oswin.Window)) {
+	w.closeCleanFunc = fun
+}
+
+func (w *windowImpl) CloseReq() {
+	if theApp.quitting {
+		w.Close()
+		return
+	}
+	if w.closeReqFunc != nil {
+		w.closeReqFunc(w)
+	} else {
+		w.Close()
+	}
+}
+
+func (w *windowImpl) CloseClean() {
+	if w.closeCleanFunc != nil {
+		w.closeCleanFunc(w)
+	}
+}
+
+func (w *windowImpl) Close() {
+	w.mu.Lock()
+	released := w.released
+	w.released = true
+	var texs []*textureImpl
+	for t := range w.textures {
+		texs = append(texs, t)
+	}
+	w.mu.Unlock()
+
+	if released {
+		return
+	}
+	w.CloseClean()
+	sendWindowEvent(w, window.Close)
+	for _, t := range texs {
+		t.Release() // deletes from map
+	}
+	w.app.DeleteWin(w)
+}
+
+// check for interface implementation
+var _ oswin.Window = &windowImpl{}
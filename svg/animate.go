@@ -0,0 +1,876 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package svg
+
+import (
+	"fmt"
+	"image"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/chewxy/math32"
+	"github.com/goki/gi/gi"
+	"github.com/goki/ki"
+	"github.com/goki/ki/kit"
+)
+
+// see clock.go for the AnimClock that animations are evaluated against
+
+// Animator is implemented by the SMIL animation elements: Animate, Set,
+// AnimateTransform and AnimateMotion
+type Animator interface {
+	gi.Node2D
+
+	// AsAnimateBase returns the AnimateBase with the timing and values of the
+	// animation
+	AsAnimateBase() *AnimateBase
+
+	// SetAnimXMLAttr sets the field of the animation for given svg attribute,
+	// returning false if it is not an attribute of the animation
+	SetAnimXMLAttr(name, val string) bool
+
+	// AnimAttr returns the name of the attribute or property that the
+	// animation sets on its target
+	AnimAttr() string
+
+	// AnimValue returns the value of the animated attribute, given the
+	// progress fraction through the current iteration of the animation and the
+	// number of iterations completed, the base (unanimated) value of the
+	// attribute, and its current value, which includes the effects of any
+	// preceding animations of the same attribute
+	AnimValue(frac float32, iter int, base, cur string) string
+}
+
+// AnimateBase has the timing and values of an animation element, which
+// animates an attribute or property of its target element -- its parent, or
+// the element referred to by Target.  All the values are stored as given in
+// the svg attributes, and evaluated against the Clock of the parent SVG, as
+// the svg is styled -- see SVG.ApplyAnimations.  Event-based begin times are
+// not supported, and such animations are never started.
+type AnimateBase struct {
+	NodeBase
+	Target        string     `xml:"href" desc:"url of the element to animate -- if empty, the parent element is animated"`
+	AttributeName string     `xml:"attributeName" desc:"name of the attribute or property to animate"`
+	Begin         string     `xml:"begin" desc:"time at which the animation begins, as a clock value, e.g., 2s or 500ms -- 0 if empty"`
+	Dur           string     `xml:"dur" desc:"simple duration of the animation, as a clock value -- indefinite if empty"`
+	RepeatCount   string     `xml:"repeatCount" desc:"number of times to repeat the simple duration, or indefinite"`
+	RepeatDur     string     `xml:"repeatDur" desc:"total duration to repeat for, as a clock value, or indefinite"`
+	FillMode      string     `xml:"fill" desc:"what happens at the end of the animation: freeze keeps the final value, and remove (the default) restores the base value"`
+	From          string     `xml:"from" desc:"starting value"`
+	To            string     `xml:"to" desc:"ending value"`
+	By            string     `xml:"by" desc:"change in value, relative to From or the base value"`
+	Values        string     `xml:"values" desc:"semicolon-separated list of values to animate through -- overrides From, To and By"`
+	KeyTimes      string     `xml:"keyTimes" desc:"semicolon-separated list of times, as fractions of the duration, for each of the values"`
+	KeySplines    string     `xml:"keySplines" desc:"semicolon-separated list of cubic bezier control points, x1 y1 x2 y2, that ease each interval between values, for calcMode spline"`
+	CalcMode      string     `xml:"calcMode" desc:"interpolation between values: linear (default), discrete, paced or spline"`
+	Additive      string     `xml:"additive" desc:"sum to add the animated value to the base value, or replace (the default)"`
+	Accumulate    string     `xml:"accumulate" desc:"sum to build on the final value of each previous repeat iteration, or none (the default)"`
+	Base          *AnimValue `json:"-" xml:"-" view:"-" desc:"value of the attribute of the target before animation, saved when it is first animated"`
+}
+
+var KiT_AnimateBase = kit.Types.AddType(&AnimateBase{}, nil)
+
+// AnimValue is the value of an animated attribute
+type AnimValue struct {
+	Val string `desc:"the value"`
+	Set bool   `desc:"whether the attribute was set at all"`
+}
+
+func (g *AnimateBase) AsAnimateBase() *AnimateBase {
+	return g
+}
+
+func (g *AnimateBase) BBox2D() image.Rectangle {
+	return image.ZR
+}
+
+// Render2D does nothing -- animations are applied as the svg is styled
+func (g *AnimateBase) Render2D() {
+}
+
+// SetAnimXMLAttr sets the animation field for given svg attribute, returning
+// false if it is not an animation attribute
+func (g *AnimateBase) SetAnimXMLAttr(name, val string) bool {
+	switch name {
+	case "href":
+		g.Target = val
+	case "attributeName":
+		g.AttributeName = val
+	case "begin":
+		g.Begin = val
+	case "dur":
+		g.Dur = val
+	case "repeatCount":
+		g.RepeatCount = val
+	case "repeatDur":
+		g.RepeatDur = val
+	case "fill":
+		g.FillMode = val
+	case "from":
+		g.From = val
+	case "to":
+		g.To = val
+	case "by":
+		g.By = val
+	case "values":
+		g.Values = val
+	case "keyTimes":
+		g.KeyTimes = val
+	case "keySplines":
+		g.KeySplines = val
+	case "calcMode":
+		g.CalcMode = val
+	case "additive":
+		g.Additive = val
+	case "accumulate":
+		g.Accumulate = val
+	default:
+		return false
+	}
+	return true
+}
+
+// TargetNode returns the element that the animation applies to
+func (g *AnimateBase) TargetNode() gi.Node2D {
+	if g.Target == "" {
+		nii, _ := gi.KiToNode2D(g.Par)
+		return nii
+	}
+	svk := g.ParentByType(KiT_SVG, true)
+	if svk == nil {
+		return nil
+	}
+	return svk.Embed(KiT_SVG).(*SVG).FindNamedElement(g.Target)
+}
+
+// ParseClockValue parses an svg clock value, e.g., 2s, 500ms, 1.5min, 0:01:30
+// or a plain number of seconds, returning the time in seconds -- indefinite is
+// returned as true for an empty string or indefinite
+func ParseClockValue(str string) (secs float32, indefinite bool, err error) {
+	str = strings.TrimSpace(str)
+	if str == "" || str == "indefinite" {
+		return 0, true, nil
+	}
+	if strings.Contains(str, ":") {
+		parts := strings.Split(str, ":")
+		for _, p := range parts {
+			v, err := strconv.ParseFloat(p, 32)
+			if err != nil {
+				return 0, false, fmt.Errorf("svg.ParseClockValue: invalid clock value: %v", str)
+			}
+			secs = secs*60 + float32(v)
+		}
+		return secs, false, nil
+	}
+	mult := float32(1)
+	for _, un := range []struct {
+		sfx  string
+		mult float32
+	}{{"ms", 0.001}, {"min", 60}, {"h", 3600}, {"s", 1}} {
+		if strings.HasSuffix(str, un.sfx) {
+			str = strings.TrimSuffix(str, un.sfx)
+			mult = un.mult
+			break
+		}
+	}
+	v, err := strconv.ParseFloat(strings.TrimSpace(str), 32)
+	if err != nil {
+		return 0, false, fmt.Errorf("svg.ParseClockValue: invalid clock value: %v", str)
+	}
+	return float32(v) * mult, false, nil
+}
+
+// BeginTime returns the time at which the animation begins -- never is true
+// if it is indefinite or uses an event, which is not supported -- the first
+// of multiple semicolon-separated begin values is used
+func (g *AnimateBase) BeginTime() (begin float32, never bool) {
+	bs := strings.TrimSpace(strings.Split(g.Begin, ";")[0])
+	if bs == "" {
+		return 0, false
+	}
+	b, ind, err := ParseClockValue(bs)
+	if ind || err != nil {
+		return 0, true
+	}
+	return b, false
+}
+
+// SimpleDur returns the simple duration of the animation, which is 0 if it
+// is indefinite
+func (g *AnimateBase) SimpleDur() float32 {
+	d, ind, err := ParseClockValue(g.Dur)
+	if ind || err != nil || d < 0 {
+		return 0
+	}
+	return d
+}
+
+// ActiveDur returns the active duration of the animation, from its
+// simple duration and repeat count and duration -- indefinite is returned as
+// -1
+func (g *AnimateBase) ActiveDur() float32 {
+	dur := g.SimpleDur()
+	ad := float32(-1)
+	if dur > 0 {
+		ad = dur
+	}
+	rc := strings.TrimSpace(g.RepeatCount)
+	switch {
+	case rc == "indefinite":
+		ad = -1
+	case rc != "":
+		if n, err := strconv.ParseFloat(rc, 32); err == nil && n > 0 && dur > 0 {
+			ad = dur * float32(n)
+		}
+	}
+	if g.RepeatDur != "" {
+		rd, ind, err := ParseClockValue(g.RepeatDur)
+		switch {
+		case err != nil:
+		case ind:
+			if rc == "" {
+				ad = -1
+			}
+		case ad < 0 || rc == "" || rd < ad:
+			ad = rd
+		}
+	}
+	return ad
+}
+
+// Progress returns the progress fraction through the current iteration of
+// the animation at given time, and the number of iterations completed --
+// active is false if the animation has no effect at that time, because it
+// has not begun yet, or has ended and does not freeze its final value
+func (g *AnimateBase) Progress(t float32) (frac float32, iter int, active bool) {
+	begin, never := g.BeginTime()
+	if never || t < begin {
+		return 0, 0, false
+	}
+	el := t - begin
+	dur := g.SimpleDur()
+	ad := g.ActiveDur()
+	if ad >= 0 && el >= ad {
+		if strings.TrimSpace(g.FillMode) != "freeze" {
+			return 0, 0, false
+		}
+		if dur <= 0 {
+			return 1, 0, true
+		}
+		iter = int(ad / dur)
+		frac = ad/dur - float32(iter)
+		if frac == 0 && iter > 0 {
+			iter--
+			frac = 1
+		}
+		return frac, iter, true
+	}
+	if dur <= 0 {
+		return 0, 0, true
+	}
+	iter = int(el / dur)
+	frac = el/dur - float32(iter)
+	return frac, iter, true
+}
+
+// Finished returns true if the animation has no further changes after given
+// time, i.e., it never begins, or its active duration has ended
+func (g *AnimateBase) Finished(t float32) bool {
+	begin, never := g.BeginTime()
+	if never {
+		return true
+	}
+	ad := g.ActiveDur()
+	return ad >= 0 && t >= begin+ad
+}
+
+// ValueList returns the list of values to animate through, from Values, or
+// From, To and By, given the base value
+func (g *AnimateBase) ValueList(base string) []string {
+	if strings.TrimSpace(g.Values) != "" {
+		return splitList(g.Values)
+	}
+	from, to, by := strings.TrimSpace(g.From), strings.TrimSpace(g.To), strings.TrimSpace(g.By)
+	switch {
+	case from != "" && to != "":
+		return []string{from, to}
+	case from != "" && by != "":
+		return []string{from, AddAnimValues(from, by)}
+	case by != "":
+		return []string{base, AddAnimValues(base, by)}
+	case to != "":
+		return []string{base, to}
+	case from != "":
+		return []string{from}
+	}
+	return nil
+}
+
+// InterpValue returns the value at given progress fraction through the
+// values, according to CalcMode, KeyTimes and KeySplines, with Additive and
+// Accumulate applied
+func (g *AnimateBase) InterpValue(vals []string, frac float32, iter int, base string) string {
+	n := len(vals)
+	if n == 0 {
+		return base
+	}
+	kts := splitFloats(g.KeyTimes)
+	if len(kts) != n {
+		kts = make([]float32, n)
+		for i := range kts {
+			if n > 1 {
+				kts[i] = float32(i) / float32(n-1)
+			}
+		}
+	}
+	var val string
+	switch {
+	case n == 1:
+		val = vals[0]
+	case strings.TrimSpace(g.CalcMode) == "discrete":
+		if len(splitList(g.KeyTimes)) != n { // evenly divided into n intervals
+			idx := int(frac * float32(n))
+			if idx >= n {
+				idx = n - 1
+			}
+			val = vals[idx]
+		} else {
+			idx := 0
+			for i := range kts {
+				if kts[i] <= frac {
+					idx = i
+				}
+			}
+			val = vals[idx]
+		}
+	default:
+		si := 0
+		for si < n-2 && frac > kts[si+1] {
+			si++
+		}
+		u := float32(0)
+		if kts[si+1] > kts[si] {
+			u = (frac - kts[si]) / (kts[si+1] - kts[si])
+		}
+		u = math32.Max(0, math32.Min(1, u))
+		if strings.TrimSpace(g.CalcMode) == "spline" {
+			spls := splitList(g.KeySplines)
+			if si < len(spls) {
+				if cp := gi.ReadPoints(spls[si]); len(cp) == 4 {
+					u = splineEase(u, cp[0], cp[1], cp[2], cp[3])
+				}
+			}
+		}
+		val = InterpAnimValues(vals[si], vals[si+1], u)
+	}
+	if iter > 0 && strings.TrimSpace(g.Accumulate) == "sum" {
+		val = AddAnimValues(ScaleAnimValue(vals[n-1], float32(iter)), val)
+	}
+	if strings.TrimSpace(g.Additive) == "sum" {
+		val = AddAnimValues(base, val)
+	}
+	return val
+}
+
+// splineEase returns the eased value for given fraction through a cubic
+// bezier easing curve from 0,0 to 1,1 with given control points
+func splineEase(u, x1, y1, x2, y2 float32) float32 {
+	bez := func(t, p1, p2 float32) float32 {
+		mt := 1 - t
+		return 3*mt*mt*t*p1 + 3*mt*t*t*p2 + t*t*t
+	}
+	lo, hi := float32(0), float32(1)
+	t := u
+	for i := 0; i < 30; i++ {
+		t = 0.5 * (lo + hi)
+		if bez(t, x1, x2) < u {
+			lo = t
+		} else {
+			hi = t
+		}
+	}
+	return bez(t, y1, y2)
+}
+
+// splitList splits a semicolon-separated list, trimming space, and omitting
+// any empty entries
+func splitList(str string) []string {
+	var vals []string
+	for _, v := range strings.Split(str, ";") {
+		if v = strings.TrimSpace(v); v != "" {
+			vals = append(vals, v)
+		}
+	}
+	return vals
+}
+
+// splitFloats parses a semicolon-separated list of numbers
+func splitFloats(str string) []float32 {
+	var vals []float32
+	for _, v := range splitList(str) {
+		f, err := strconv.ParseFloat(v, 32)
+		if err != nil {
+			return nil
+		}
+		vals = append(vals, float32(f))
+	}
+	return vals
+}
+
+/////////////////////////////////////////////////////////////////////////////
+//   Values
+
+// animNumRe matches the numbers within animation values
+var animNumRe = regexp.MustCompile(`[-+]?(\d+\.?\d*|\.\d+)([eE][-+]?\d+)?`)
+
+// animColor returns the color for given animation value, if it is one
+func animColor(str string) (gi.Color, bool) {
+	str = strings.TrimSpace(str)
+	low := strings.ToLower(str)
+	isClr := strings.HasPrefix(low, "#") || strings.HasPrefix(low, "rgb") || strings.HasPrefix(low, "hsl")
+	if !isClr && str != "" {
+		isClr = true
+		for _, r := range str {
+			if !unicode.IsLetter(r) {
+				isClr = false
+				break
+			}
+		}
+	}
+	if !isClr || low == "none" {
+		return gi.Color{}, false
+	}
+	clr, err := gi.ColorFromString(str, nil)
+	if err != nil {
+		return gi.Color{}, false
+	}
+	return clr, true
+}
+
+// mapAnimNums returns b with each of its numbers replaced by the result of
+// given function on it and the corresponding number in a -- ok is false if
+// a and b do not have the same number of numbers
+func mapAnimNums(a, b string, fun func(na, nb float32) float32) (string, bool) {
+	as := animNumRe.FindAllString(a, -1)
+	bi := animNumRe.FindAllStringIndex(b, -1)
+	if len(as) != len(bi) || len(bi) == 0 {
+		return b, false
+	}
+	var sb strings.Builder
+	last := 0
+	for i, ix := range bi {
+		na, _ := strconv.ParseFloat(as[i], 32)
+		nb, _ := strconv.ParseFloat(b[ix[0]:ix[1]], 32)
+		sb.WriteString(b[last:ix[0]])
+		sb.WriteString(strconv.FormatFloat(float64(fun(float32(na), float32(nb))), 'g', -1, 32))
+		last = ix[1]
+	}
+	sb.WriteString(b[last:])
+	return sb.String(), true
+}
+
+// InterpAnimValues interpolates between two animation values, at given
+// fraction from a to b -- colors are interpolated in RGBA, and other values
+// with the same number of numbers (e.g., lengths, lists of numbers and
+// transforms) have their numbers interpolated -- otherwise the value changes
+// discretely from a to b half way
+func InterpAnimValues(a, b string, frac float32) string {
+	if ca, ok := animColor(a); ok {
+		if cb, ok := animColor(b); ok {
+			lerp := func(x, y uint8) uint8 {
+				return uint8(math32.Floor(float32(x) + frac*(float32(y)-float32(x)) + 0.5))
+			}
+			c := gi.Color{lerp(ca.R, cb.R), lerp(ca.G, cb.G), lerp(ca.B, cb.B), lerp(ca.A, cb.A)}
+			return c.HexString()
+		}
+	}
+	if v, ok := mapAnimNums(a, b, func(na, nb float32) float32 { return na + frac*(nb-na) }); ok {
+		return v
+	}
+	if frac < 0.5 {
+		return a
+	}
+	return b
+}
+
+// AddAnimValues adds two animation values, for additive and accumulating
+// animations -- colors are added by component, and the numbers of other
+// values are added if they have the same number of numbers -- otherwise b is
+// returned
+func AddAnimValues(a, b string) string {
+	if ca, ok := animColor(a); ok {
+		if cb, ok := animColor(b); ok {
+			add := func(x, y uint8) uint8 {
+				if int(x)+int(y) > 255 {
+					return 255
+				}
+				return x + y
+			}
+			c := gi.Color{add(ca.R, cb.R), add(ca.G, cb.G), add(ca.B, cb.B), add(ca.A, cb.A)}
+			return c.HexString()
+		}
+	}
+	v, _ := mapAnimNums(a, b, func(na, nb float32) float32 { return na + nb })
+	return v
+}
+
+// ScaleAnimValue multiplies the numbers in an animation value by given factor
+func ScaleAnimValue(a string, sc float32) string {
+	v, _ := mapAnimNums(a, a, func(na, nb float32) float32 { return na * sc })
+	return v
+}
+
+/////////////////////////////////////////////////////////////////////////////
+//   Animation elements
+
+// Animate animates an attribute or property of its target element, through
+// a list of values -- see AnimateBase
+type Animate struct {
+	AnimateBase
+}
+
+var KiT_Animate = kit.Types.AddType(&Animate{}, nil)
+
+func (g *Animate) AnimAttr() string {
+	return g.AttributeName
+}
+
+func (g *Animate) AnimValue(frac float32, iter int, base, cur string) string {
+	return g.InterpValue(g.ValueList(base), frac, iter, base)
+}
+
+// Set sets an attribute or property of its target element to the To value
+// for the duration of the animation -- see AnimateBase
+type Set struct {
+	AnimateBase
+}
+
+var KiT_Set = kit.Types.AddType(&Set{}, nil)
+
+func (g *Set) AnimAttr() string {
+	return g.AttributeName
+}
+
+func (g *Set) AnimValue(frac float32, iter int, base, cur string) string {
+	return g.To
+}
+
+// AnimateTransform animates the transform of its target element, with
+// values that are the parameters of a transform of given Type -- see
+// AnimateBase
+type AnimateTransform struct {
+	AnimateBase
+	Type string `xml:"type" desc:"type of transform: translate (default), scale, rotate, skewX or skewY"`
+}
+
+var KiT_AnimateTransform = kit.Types.AddType(&AnimateTransform{}, nil)
+
+func (g *AnimateTransform) SetAnimXMLAttr(name, val string) bool {
+	if name == "type" {
+		g.Type = val
+		return true
+	}
+	return g.AnimateBase.SetAnimXMLAttr(name, val)
+}
+
+func (g *AnimateTransform) AnimAttr() string {
+	return "transform"
+}
+
+func (g *AnimateTransform) AnimValue(frac float32, iter int, base, cur string) string {
+	typ := strings.TrimSpace(g.Type)
+	if typ == "" {
+		typ = "translate"
+	}
+	vals := g.ValueList("0")
+	add := g.Additive
+	g.Additive = "" // transforms are added as a list, below
+	val := g.InterpValue(vals, frac, iter, "0")
+	g.Additive = add
+	xf := fmt.Sprintf("%v(%v)", typ, strings.Join(strings.Fields(strings.Replace(val, ",", " ", -1)), ","))
+	if strings.TrimSpace(add) == "sum" && strings.TrimSpace(cur) != "" {
+		return cur + " " + xf
+	}
+	return xf
+}
+
+// AnimateMotion moves its target element along a path, given by Path, or
+// by MPath which refers to a Path element, or by the points in Values, From,
+// To and By -- the motion is applied as a translation on top of the
+// transform of the element, and optionally rotates the element to follow
+// the path -- see AnimateBase
+type AnimateMotion struct {
+	AnimateBase
+	Path   string `xml:"path" desc:"path data for the motion path"`
+	MPath  string `xml:"mpath" desc:"url of the Path element for the motion path, from an mpath child element -- overrides Path"`
+	Rotate string `xml:"rotate" desc:"rotation of the element: auto follows the direction of the path, auto-reverse follows it backwards, or an angle in degrees"`
+}
+
+var KiT_AnimateMotion = kit.Types.AddType(&AnimateMotion{}, nil)
+
+func (g *AnimateMotion) SetAnimXMLAttr(name, val string) bool {
+	switch name {
+	case "path":
+		g.Path = val
+	case "rotate":
+		g.Rotate = val
+	default:
+		return g.AnimateBase.SetAnimXMLAttr(name, val)
+	}
+	return true
+}
+
+func (g *AnimateMotion) AnimAttr() string {
+	return "transform"
+}
+
+// MotionPath returns the path data for the motion path
+func (g *AnimateMotion) MotionPath() []PathData {
+	if g.MPath != "" {
+		svk := g.ParentByType(KiT_SVG, true)
+		if svk != nil {
+			if pn, ok := svk.Embed(KiT_SVG).(*SVG).FindNamedElement(g.MPath).(*Path); ok {
+				return pn.Data
+			}
+		}
+		return nil
+	}
+	ps := strings.TrimSpace(g.Path)
+	if ps == "" {
+		vals := g.ValueList("0,0")
+		for i, v := range vals {
+			if i == 0 {
+				ps = "M " + v
+			} else {
+				ps += " L " + v
+			}
+		}
+	}
+	data, err := PathDataParse(ps)
+	if err != nil || PathDataValidate(&g.Pnt, &data, g.PathUnique()) != nil {
+		return nil
+	}
+	return data
+}
+
+func (g *AnimateMotion) AnimValue(frac float32, iter int, base, cur string) string {
+	data := g.MotionPath()
+	if len(data) < 2 {
+		return cur
+	}
+	pp := NewPathGeom(data, gi.Identity2D())
+	if len(pp.Segs) == 0 {
+		return cur
+	}
+	dist := frac * pp.Length
+	pt := pp.PointAt(dist)
+	xf := fmt.Sprintf("translate(%v,%v)", pt.X, pt.Y)
+	rot := strings.TrimSpace(g.Rotate)
+	switch rot {
+	case "", "0":
+	case "auto", "auto-reverse":
+		ang := pp.AngleAt(dist) * 180 / math32.Pi
+		if rot == "auto-reverse" {
+			ang += 180
+		}
+		xf += fmt.Sprintf(" rotate(%v)", ang)
+	default:
+		xf += fmt.Sprintf(" rotate(%v)", rot)
+	}
+	if strings.TrimSpace(cur) != "" {
+		xf += " " + cur
+	}
+	return xf
+}
+
+/////////////////////////////////////////////////////////////////////////////
+//   Applying animations
+
+// animKey identifies an animated attribute of an element
+type animKey struct {
+	node gi.Node2D
+	attr string
+}
+
+// ApplyAnimations applies all the animations in the svg at the current time
+// of its Clock, setting the animated attributes and properties of their
+// targets -- this is called as the svg is styled, prior to the styling of its
+// elements, so that animated properties take effect
+func (svg *SVG) ApplyAnimations() {
+	var anims []Animator
+	svg.FuncDownMeFirst(0, nil, func(k ki.Ki, level int, d interface{}) bool {
+		if k != svg.This() {
+			if _, ok := k.(*SVG); ok {
+				return false // nested svgs have their own clock
+			}
+		}
+		if an, ok := k.(Animator); ok {
+			anims = append(anims, an)
+		}
+		return true
+	})
+	if len(anims) == 0 {
+		return
+	}
+	t := svg.Clock.Time
+	vals := map[animKey]string{}
+	var keys []animKey
+	for _, an := range anims {
+		ab := an.AsAnimateBase()
+		trg := ab.TargetNode()
+		attr := an.AnimAttr()
+		if trg == nil || attr == "" {
+			continue
+		}
+		key := animKey{trg, attr}
+		if ab.Base == nil {
+			val, set := AnimAttrValue(trg, attr)
+			ab.Base = &AnimValue{Val: val, Set: set}
+		}
+		cur, has := vals[key]
+		if !has {
+			cur = ab.Base.Val
+			keys = append(keys, key)
+		}
+		if frac, iter, active := ab.Progress(t); active {
+			cur = an.AnimValue(frac, iter, ab.Base.Val, cur)
+		}
+		vals[key] = cur
+	}
+	for _, key := range keys {
+		SetAnimAttr(key.node, key.attr, vals[key])
+	}
+}
+
+// ResetAnimations restores the animated attributes and properties of all
+// the animation targets in the svg to their base (unanimated) values, e.g.,
+// for saving the svg -- ApplyAnimations applies the animations again
+func (svg *SVG) ResetAnimations() {
+	svg.FuncDownMeFirst(0, nil, func(k ki.Ki, level int, d interface{}) bool {
+		if k != svg.This() {
+			if _, ok := k.(*SVG); ok {
+				return false
+			}
+		}
+		an, ok := k.(Animator)
+		if !ok {
+			return true
+		}
+		ab := an.AsAnimateBase()
+		if ab.Base == nil {
+			return true
+		}
+		if trg := ab.TargetNode(); trg != nil && an.AnimAttr() != "" {
+			SetAnimAttr(trg, an.AnimAttr(), ab.Base.Val)
+		}
+		return true
+	})
+}
+
+// AnimationsActive returns true if any of the animations in the svg have
+// further changes at or after the current time of the Clock
+func (svg *SVG) AnimationsActive() bool {
+	active := false
+	svg.FuncDownMeFirst(0, nil, func(k ki.Ki, level int, d interface{}) bool {
+		if active {
+			return false
+		}
+		if k != svg.This() {
+			if _, ok := k.(*SVG); ok {
+				return false
+			}
+		}
+		if an, ok := k.(Animator); ok && !an.AsAnimateBase().Finished(svg.Clock.Time) {
+			active = true
+			return false
+		}
+		return true
+	})
+	return active
+}
+
+// AnimAttrValue returns the value of given attribute of an element, as a
+// string: fields of the element with a corresponding xml tag (e.g., x or
+// width of a Rect), the d attribute of a Path, or otherwise its property of
+// that name -- set is false if it is a property that is not set
+func AnimAttrValue(nii gi.Node2D, attr string) (val string, set bool) {
+	if fp := attrField(nii, attr); fp != nil {
+		return xmlFloat(*fp), true
+	}
+	if pn, ok := nii.(*Path); ok && attr == "d" {
+		return PathDataString(pn.Data), true
+	}
+	pv, ok := nii.Prop(attr)
+	if !ok {
+		return "", false
+	}
+	return xmlPropString(pv), true
+}
+
+// SetAnimAttr sets given attribute of an element to given value -- see
+// AnimAttrValue for the attributes that are supported -- an empty value
+// removes a property
+func SetAnimAttr(nii gi.Node2D, attr, val string) {
+	if fp := attrField(nii, attr); fp != nil {
+		if f, err := strconv.ParseFloat(strings.TrimSpace(animNumRe.FindString(val)), 32); err == nil {
+			*fp = float32(f)
+		}
+		return
+	}
+	if pn, ok := nii.(*Path); ok && attr == "d" {
+		pn.SetData(val)
+		return
+	}
+	if attr == "transform" {
+		SetNodeXForm(nii, val)
+		return
+	}
+	if val == "" {
+		nii.DeleteProp(attr)
+		return
+	}
+	nii.SetProp(attr, val)
+}
+
+// attrField returns a pointer to the float32 field of an element that holds
+// given svg attribute, based on the xml tags of its fields -- either the tag
+// of a float32 field (e.g., `xml:"r"`) or one element of the tag of a Vec2D
+// field (e.g., `xml:"{x,y}"`) -- nil if there is no such field
+func attrField(nii gi.Node2D, attr string) *float32 {
+	v := reflect.ValueOf(nii)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+	v = v.Elem()
+	typ := v.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		sf := typ.Field(i)
+		if sf.Anonymous {
+			continue // embedded base types have no geometry
+		}
+		tag := sf.Tag.Get("xml")
+		fv := v.Field(i)
+		switch {
+		case tag == attr && sf.Type.Kind() == reflect.Float32:
+			return fv.Addr().Interface().(*float32)
+		case strings.HasPrefix(tag, "{") && sf.Type == reflect.TypeOf(gi.Vec2D{}):
+			nms := strings.Split(strings.Trim(tag, "{}"), ",")
+			if len(nms) != 2 {
+				continue
+			}
+			vec := fv.Addr().Interface().(*gi.Vec2D)
+			if nms[0] == attr {
+				return &vec.X
+			}
+			if nms[1] == attr {
+				return &vec.Y
+			}
+		}
+	}
+	return nil
+}
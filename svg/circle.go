@@ -35,3 +35,8 @@ func (g *Circle) Render2D() {
 
 	rs.PopXFormLock()
 }
+
+// ShapeData returns the path of the circle, for hit testing -- see Shape
+func (g *Circle) ShapeData() []PathData {
+	return PathDataEllipse(g.Pos, gi.Vec2D{g.Radius, g.Radius})
+}
@@ -5,14 +5,269 @@
 package svg
 
 import (
+	"image"
+	"image/color"
+	"image/draw"
+	"log"
+	"strings"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/ki"
 	"github.com/goki/ki/kit"
 )
 
-// todo: needs to be impl
-
-// ClipPath is used for holding a path that renders as a clip path
+// ClipPath is used for holding a path that renders as a clip path -- any
+// node with a clip-path = url(#id) property referring to it only renders
+// within the filled area of its children -- see Render2DEffects
 type ClipPath struct {
 	NodeBase
 }
 
 var KiT_ClipPath = kit.Types.AddType(&ClipPath{}, nil)
+
+// Render2D does nothing -- a clip path is only rendered as a clip, via
+// RenderClip
+func (g *ClipPath) Render2D() {
+}
+
+// RenderClip renders the clip path for given node into an alpha mask the
+// size of the render image, in the user space of the node -- children are
+// all rendered as opaque fills, regardless of their fill and stroke styles.
+func (g *ClipPath) RenderClip(node gi.Node2D) *image.Alpha {
+	restore := setClipPaint(g.This())
+	layer := renderLayer(node, &g.NodeBase, g.Props["clipPathUnits"])
+	restore()
+	if layer == nil {
+		return nil
+	}
+	mask := image.NewAlpha(layer.Bounds())
+	draw.Draw(mask, mask.Bounds(), layer, image.ZP, draw.Src)
+	return mask
+}
+
+/////////////////////////////////////////////////////////////////////////////
+//   Clip and Mask rendering
+
+// Render2DEffects renders given node, applying its filter, clip-path and
+// mask properties, if set -- these must refer to Filter, ClipPath and Mask
+// elements, found using FindSVGURL.  The node is rendered into a separate
+// raster layer, which is filtered and then drawn through the clip and mask
+// via the paint backend (see gi.RenderState.DrawLayer), so it is embedded as
+// an image in vector output.  Any pattern fill or stroke is prepared first
+// (see PreparePatterns).  This is used for rendering all the children of svg
+// nodes.
+func Render2DEffects(kid ki.Ki) {
+	nii, ni := gi.KiToNode2D(kid)
+	if nii == nil {
+		return
+	}
+	PreparePatterns(nii)
+	fl := FilterOf(nii)
+	cp, mk := ClipMask(nii)
+	if fl == nil && cp == nil && mk == nil {
+		nii.Render2D()
+		return
+	}
+	if ni.Viewport == nil {
+		nii.Init2D()
+	}
+	rs := &ni.Viewport.Render
+	layer := image.NewRGBA(rs.Image.Bounds())
+	rs.PushImage(layer)
+	nii.Render2D()
+	rs.PopImage()
+
+	if fl != nil {
+		rs.Lock()
+		xf := nodeXForm(nii, rs)
+		rs.Unlock()
+		layer = fl.ApplyFilter(layer, ni.BBox, xf, ViewportSize(nii))
+	}
+
+	var alpha *image.Alpha
+	if cp != nil {
+		alpha = cp.RenderClip(nii)
+	}
+	if mk != nil {
+		ma := mk.RenderMask(nii)
+		if alpha == nil {
+			alpha = ma
+		} else if ma != nil {
+			for i := range alpha.Pix {
+				alpha.Pix[i] = uint8((uint32(alpha.Pix[i]) * uint32(ma.Pix[i])) / 255)
+			}
+		}
+	}
+	rs.Lock()
+	rs.DrawLayer(layer, alpha)
+	rs.Unlock()
+}
+
+// nodeXForm returns the transform for the user space of given node, i.e.,
+// its own transform on top of the current one in the render state -- must
+// be called under the render lock
+func nodeXForm(node gi.Node2D, rs *gi.RenderState) gi.Matrix2D {
+	if pntr, ok := node.(gi.Painter); ok {
+		return pntr.Paint().XForm.Multiply(rs.XForm)
+	}
+	return rs.XForm
+}
+
+// ClipMask returns the ClipPath and Mask referred to by the clip-path and
+// mask properties of given node, if set and found
+func ClipMask(nii gi.Node2D) (*ClipPath, *Mask) {
+	var cp *ClipPath
+	var mk *Mask
+	ni := nii.AsNode2D()
+	if cpp, ok := ni.Props["clip-path"]; ok {
+		switch cpv := cpp.(type) {
+		case *ClipPath:
+			cp = cpv
+		case string:
+			if cpn := findURL(nii, cpv); cpn != nil {
+				cp, ok = cpn.(*ClipPath)
+				if !ok {
+					log.Printf("gi.svg Found element named: %v but isn't a ClipPath type, instead is: %T", cpv, cpn)
+				}
+			}
+		}
+	}
+	if mkp, ok := ni.Props["mask"]; ok {
+		switch mkv := mkp.(type) {
+		case *Mask:
+			mk = mkv
+		case string:
+			if mkn := findURL(nii, mkv); mkn != nil {
+				mk, ok = mkn.(*Mask)
+				if !ok {
+					log.Printf("gi.svg Found element named: %v but isn't a Mask type, instead is: %T", mkv, mkn)
+				}
+			}
+		}
+	}
+	return cp, mk
+}
+
+// findURL finds a url element for given node, using FindSVGURL for svg
+// nodes, and FindNamedElement otherwise
+func findURL(nii gi.Node2D, url string) gi.Node2D {
+	url = strings.TrimSpace(url)
+	if url == "" || url == "none" {
+		return nil
+	}
+	if sn, ok := nii.Embed(KiT_NodeBase).(*NodeBase); ok {
+		return sn.FindSVGURL(url)
+	}
+	url = strings.TrimSuffix(strings.TrimPrefix(url, "url("), ")")
+	return nii.FindNamedElement(strings.TrimPrefix(url, "#"))
+}
+
+// renderLayer renders the children of given clip path or mask element par
+// into a new layer the size of the render image, in the user space of given
+// node -- if units is objectBoundingBox, then coordinates are relative to
+// the bounding box of the node, which must already have been rendered.
+func renderLayer(node gi.Node2D, par *NodeBase, units interface{}) *image.RGBA {
+	ni := node.AsNode2D()
+	if ni.Viewport == nil {
+		return nil
+	}
+	rs := &ni.Viewport.Render
+	initDefNodes(par.This())
+
+	rs.Lock()
+	rs.PushXForm(gi.Identity2D()) // saves current
+	if un, _ := units.(string); un == "objectBoundingBox" {
+		bb := ni.BBox
+		rs.XForm = gi.Matrix2D{XX: float32(bb.Dx()), YY: float32(bb.Dy()), X0: float32(bb.Min.X), Y0: float32(bb.Min.Y)}
+	} else {
+		rs.XForm = nodeXForm(node, rs)
+	}
+	rs.PushXForm(par.Pnt.XForm)
+	rs.Unlock()
+
+	layer := image.NewRGBA(rs.Image.Bounds())
+	rs.PushImage(layer)
+	par.Render2DChildren()
+	rs.PopImage()
+
+	rs.Lock()
+	rs.PopXForm()
+	rs.PopXForm()
+	rs.Unlock()
+	return layer
+}
+
+// initDefNodes initializes and styles any nodes under par that have not
+// yet been initialized -- e.g., those held in Defs, which are not rendered
+// as part of the regular tree
+func initDefNodes(par ki.Ki) {
+	par.FuncDownMeFirst(0, nil, func(k ki.Ki, level int, d interface{}) bool {
+		nii, ni := gi.KiToNode2D(k)
+		if nii == nil {
+			return false
+		}
+		if ni.Viewport == nil {
+			nii.Init2D()
+			nii.Style2D()
+		}
+		return true
+	})
+}
+
+// clipRule returns the rule for filling given node in a clip path, from its
+// clip-rule property, which is inherited -- the default is nonzero, and its
+// own fill-rule is not used
+func clipRule(k ki.Ki) gi.FillRule {
+	rule := gi.FillRuleNonZero
+	pv, ok := k.PropInherit("clip-rule", true, true)
+	if !ok {
+		return rule
+	}
+	switch v := pv.(type) {
+	case gi.FillRule:
+		rule = v
+	case string:
+		kit.Enums.SetAnyEnumIfaceFromString(&rule, strings.Replace(strings.TrimSpace(v), "-", "", -1))
+	}
+	return rule
+}
+
+// setClipPaint sets the paint of all the nodes under par to an opaque
+// fill with no stroke, using their clip-rule as the fill rule, as used for
+// rendering clip paths, returning a function that restores their original
+// paint
+func setClipPaint(par ki.Ki) (restore func()) {
+	type savedPaint struct {
+		pc      *gi.Paint
+		fill    gi.FillStyle
+		stroke  bool
+		opacity float32
+	}
+	var saved []savedPaint
+	initDefNodes(par)
+	par.FuncDownMeFirst(0, nil, func(k ki.Ki, level int, d interface{}) bool {
+		if k == par {
+			return true
+		}
+		pntr, ok := k.(gi.Painter)
+		if !ok {
+			return true
+		}
+		pc := pntr.Paint()
+		saved = append(saved, savedPaint{pc: pc, fill: pc.FillStyle, stroke: pc.StrokeStyle.On, opacity: pc.FontStyle.Opacity})
+		pc.FillStyle.On = true
+		pc.FillStyle.Color.SetColor(color.Black)
+		pc.FillStyle.Opacity = 1
+		pc.FillStyle.Rule = clipRule(k)
+		pc.StrokeStyle.On = false
+		pc.FontStyle.Opacity = 1
+		return true
+	})
+	return func() {
+		for _, sp := range saved {
+			sp.pc.FillStyle = sp.fill
+			sp.pc.StrokeStyle.On = sp.stroke
+			sp.pc.FontStyle.Opacity = sp.opacity
+		}
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package svg_test
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"strings"
+	"testing"
+
+	"github.com/goki/gi/gitest"
+	"github.com/goki/gi/svg"
+)
+
+func TestMain(m *testing.M) {
+	gitest.Main(m)
+}
+
+// testRenderSVG returns the rendering of given svg elements, in a 100x100
+// svg with the same viewbox
+func testRenderSVG(t *testing.T, name, els string) *image.RGBA {
+	src := `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 100 100">` + els + `</svg>`
+	ts, sv := gitest.NewSVG(t, name, 100, 100, func(sv *svg.SVG) {
+		if err := sv.ReadXML(strings.NewReader(src)); err != nil {
+			t.Fatalf("%v: %v", name, err)
+		}
+	})
+	defer ts.Close()
+	img := image.NewRGBA(sv.Pixels.Bounds())
+	draw.Draw(img, img.Bounds(), sv.Pixels, sv.Pixels.Bounds().Min, draw.Src)
+	return img
+}
+
+// testSquares is path data for two overlapping squares drawn in the same
+// direction, whose overlap at 40..60 is only inside under the nonzero rule
+const testSquares = "M 10 10 H 60 V 60 H 10 Z M 40 40 H 90 V 90 H 40 Z"
+
+func TestClipRule(t *testing.T) {
+	tests := []struct {
+		name    string
+		clipAtt string // attributes of the clipPath element
+		pathAtt string // attributes of the path in it
+		rule    string // fill-rule of the equivalent filled path
+	}{
+		{"default", ``, ``, "nonzero"},
+		{"evenodd", `clip-rule="evenodd"`, ``, "evenodd"},
+		{"evenodd on path", ``, `clip-rule="evenodd"`, "evenodd"},
+		{"nonzero on path", `clip-rule="evenodd"`, `clip-rule="nonzero"`, "nonzero"},
+		{"fill-rule ignored", ``, `fill-rule="evenodd"`, "nonzero"},
+	}
+	blue := color.RGBA{0, 0, 255, 255}
+	white := color.RGBA{255, 255, 255, 255}
+	for i, tst := range tests {
+		clip := testRenderSVG(t, fmt.Sprintf("clip-rule-%v", i), fmt.Sprintf(
+			`<defs><clipPath id="clip" %v><path d="%v" %v/></clipPath></defs>`+
+				`<rect x="0" y="0" width="100" height="100" fill="#00f" clip-path="url(#clip)"/>`,
+			tst.clipAtt, testSquares, tst.pathAtt))
+		want := testRenderSVG(t, fmt.Sprintf("clip-rule-want-%v", i), fmt.Sprintf(
+			`<path d="%v" fill="#00f" fill-rule="%v"/>`, testSquares, tst.rule))
+		if ndiff, _ := gitest.CompareImages(clip, want, gitest.DefaultTolerance); ndiff != 0 {
+			t.Errorf("%v: %v pixels differ from a path filled with fill-rule %v", tst.name, ndiff, tst.rule)
+		}
+		overlap := blue
+		if tst.rule == "evenodd" {
+			overlap = white
+		}
+		for _, px := range []struct {
+			pt   image.Point
+			want color.RGBA
+		}{
+			{image.Point{20, 20}, blue},
+			{image.Point{80, 80}, blue},
+			{image.Point{50, 50}, overlap},
+			{image.Point{5, 5}, white},
+			{image.Point{80, 20}, white},
+		} {
+			if c := clip.RGBAAt(px.pt.X, px.pt.Y); c != px.want {
+				t.Errorf("%v: pixel at %v: %v, want: %v", tst.name, px.pt, c, px.want)
+			}
+		}
+	}
+}
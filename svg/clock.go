@@ -0,0 +1,108 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package svg
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/goki/gi/gi"
+)
+
+// AnimClockMu is mutex protecting the updating of animation clocks
+var AnimClockMu sync.Mutex
+
+// animClockDur is the duration of the window animation that runs an
+// animation clock -- it is stopped when the svg animations have finished
+const animClockDur = time.Duration(math.MaxInt64)
+
+// AnimClock is the clock that the animations in an SVG are evaluated
+// against -- it starts running automatically when the svg has active
+// animations and is in a window, and is advanced, and the svg re-rendered,
+// on each frame of the animations of the window (see gi.Window.StartAnim),
+// until all the animations have finished
+type AnimClock struct {
+	Time    float32   `desc:"current time, in seconds since the start of the animations"`
+	Running bool      `desc:"whether the clock is currently running"`
+	Paused  bool      `desc:"whether the clock has been paused -- it does not start running automatically when paused"`
+	start   time.Time `desc:"wall-clock time corresponding to startT"`
+	startT  float32   `desc:"clock time when the clock last started running"`
+	anim    *gi.Anim  `desc:"window animation that advances the clock"`
+}
+
+// StartAnimations starts the animation clock running from its current time,
+// and clears any pause -- it only runs while the svg is in a window
+func (svg *SVG) StartAnimations() {
+	AnimClockMu.Lock()
+	defer AnimClockMu.Unlock()
+	ck := &svg.Clock
+	ck.Paused = false
+	if ck.Running {
+		return
+	}
+	win := svg.ParentWindow()
+	if win == nil {
+		return
+	}
+	ck.Running = true
+	ck.start = time.Now()
+	ck.startT = ck.Time
+	ck.anim = &gi.Anim{Node: svg.This().(gi.Node2D), Prop: "svg-clock", Start: ck.start, Dur: animClockDur, Step: svg.clockStep}
+	win.StartAnim(ck.anim)
+}
+
+// PauseAnimations stops the animation clock at its current time, until
+// StartAnimations is called
+func (svg *SVG) PauseAnimations() {
+	AnimClockMu.Lock()
+	svg.Clock.Paused = true
+	svg.stopClock()
+	AnimClockMu.Unlock()
+}
+
+// SetAnimTime sets the time of the animation clock, in seconds, and updates
+// the svg to show the animations at that time -- the clock keeps running
+// from that time if it is running
+func (svg *SVG) SetAnimTime(t float32) {
+	AnimClockMu.Lock()
+	ck := &svg.Clock
+	ck.Time = t
+	ck.start = time.Now()
+	ck.startT = t
+	AnimClockMu.Unlock()
+	svg.SetFullReRender()
+	svg.UpdateSig()
+}
+
+// stopClock stops the window animation that advances the animation clock
+// -- must be called under AnimClockMu
+func (svg *SVG) stopClock() {
+	ck := &svg.Clock
+	if ck.anim != nil {
+		ck.anim.Stop()
+		ck.anim = nil
+	}
+	ck.Running = false
+}
+
+// clockStep is the Step of the window animation that advances the
+// animation clock, on each frame, and re-renders the svg -- it stops once
+// the svg animations have finished
+func (svg *SVG) clockStep(t float32) {
+	AnimClockMu.Lock()
+	ck := &svg.Clock
+	if !ck.Running {
+		AnimClockMu.Unlock()
+		return
+	}
+	ck.Time = ck.startT + float32(time.Since(ck.start).Seconds())
+	if !svg.AnimationsActive() {
+		svg.stopClock()
+	}
+	AnimClockMu.Unlock()
+	svg.SetFullReRender()
+	svg.UpdateSig()
+}
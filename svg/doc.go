@@ -11,8 +11,9 @@ SVG currently supports most of SVG, but not:
 	* Filter Effects
 	* 3D Perspective transforms
 
-See gi/examples/svg for a basic SVG viewer app, using the svg.Editor, which
-will ultimately be expanded to support more advanced editing.  Also in that
+See gi/examples/svg for a basic SVG viewer and editor app, using the
+svg.Editor, which supports selecting, moving, resizing and rotating
+elements, and editing the nodes of paths, with undo.  Also in that
 directory are a number of test files that stress different aspects of
 rendering.
 
@@ -25,7 +26,16 @@ It uses srwiley/rasterx for SVG-compatible rasterization, and the gi.Paint
 interface for drawing.
 
 The Path element uses a compiled bytecode version of the Data path for
-increased speed.
+increased speed.  The PathGeom type and the PathData functions in pathgeom.go
+flatten paths into line segments, measure them, test points against their
+fill and stroke, and compute the union, intersection and difference of the
+areas of two paths.  Elements are picked exactly with these tests, e.g., by
+SVG.ElementAt -- see hittest.go.
+
+SMIL animation elements (animate, set, animateTransform and animateMotion)
+are evaluated against the Clock of their SVG as it is styled, and the clock
+runs automatically while the svg is in a window and has active animations --
+see animate.go and clock.go.
 
 */
 package svg
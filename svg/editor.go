@@ -6,33 +6,131 @@ package svg
 
 import (
 	"fmt"
+	"image"
+	"image/color"
 
+	"github.com/chewxy/math32"
 	"github.com/goki/gi/gi"
 	"github.com/goki/gi/giv"
 	"github.com/goki/gi/oswin"
 	"github.com/goki/gi/oswin/cursor"
+	"github.com/goki/gi/oswin/key"
 	"github.com/goki/gi/oswin/mouse"
 	"github.com/goki/ki"
 	"github.com/goki/ki/kit"
 )
 
-// Editor supports editing of SVG elements
+// Editor supports editing of SVG elements.  In EditPan mode, dragging pans
+// the view.  In EditSelect mode, elements are selected by clicking on them
+// or dragging a rubber-band box around them, and the selection is moved,
+// resized and rotated by dragging it or its handles, which writes the
+// transform property of each element.  In EditNodes mode, the end points and
+// curve control points of a selected path are dragged to edit its path
+// data.  In all modes, the scroll wheel zooms.  Dragging snaps to the edges
+// and centers of other elements, showing alignment guides, and to SnapGrid
+// if set.  Edits can be undone and redone -- see Undo, Redo.
 type Editor struct {
 	SVG
-	Trans         gi.Vec2D `desc:"view translation offset (from dragging)"`
-	Scale         float32  `desc:"view scaling (from zooming)"`
-	SetDragCursor bool     `desc:"has dragging cursor been set yet?"`
+	Trans         gi.Vec2D     `desc:"view translation offset (from dragging)"`
+	Scale         float32      `desc:"view scaling (from zooming)"`
+	SetDragCursor bool         `desc:"has dragging cursor been set yet?"`
+	Mode          EditorModes  `desc:"editing mode -- determines what mouse clicks and drags do"`
+	Selected      []gi.Node2D  `json:"-" xml:"-" desc:"currently selected elements"`
+	Snap          bool         `desc:"snap dragged elements and path nodes to the edges and centers of other elements, and to SnapGrid if set -- holding Alt while dragging turns snapping off"`
+	SnapDist      float32      `desc:"distance in pixels within which dragging snaps to other elements -- EditorSnapDist is used if 0"`
+	SnapGrid      float32      `desc:"spacing of the grid to snap to, in svg user units -- 0 for no grid"`
+	Undos         []EditorUndo `json:"-" xml:"-" desc:"undo stack of edits"`
+	UndoPos       int          `json:"-" xml:"-" desc:"position in the undo stack -- edits before it can be undone, and those at and after it redone"`
+	Drag          EditorDrag   `json:"-" xml:"-" view:"-" desc:"state of the current drag"`
 }
 
 var KiT_Editor = kit.Types.AddType(&Editor{}, nil)
 
+// EditorModes are the editing modes of the Editor
+type EditorModes int32
+
+const (
+	// EditPan pans the view by dragging
+	EditPan EditorModes = iota
+
+	// EditSelect selects elements, and moves, resizes and rotates them
+	EditSelect
+
+	// EditNodes edits the nodes and control points of paths
+	EditNodes
+
+	EditorModesN
+)
+
+//go:generate stringer -type=EditorModes
+
+var KiT_EditorModes = kit.Enums.AddEnumAltLower(EditorModesN, false, nil, "Edit")
+
+func (ev EditorModes) MarshalJSON() ([]byte, error)  { return kit.EnumMarshalJSON(ev) }
+func (ev *EditorModes) UnmarshalJSON(b []byte) error { return kit.EnumUnmarshalJSON(ev, b) }
+
+var (
+	// EditorHandleSize is the size of the selection handles and path node
+	// markers, in pixels
+	EditorHandleSize = float32(8)
+
+	// EditorRotateDist is the distance of the rotate handle above the
+	// selection, in pixels
+	EditorRotateDist = float32(24)
+
+	// EditorSnapDist is the default distance in pixels within which dragging
+	// snaps to other elements
+	EditorSnapDist = float32(6)
+
+	// EditorSelectColor is the color of the selection box and handles
+	EditorSelectColor = color.RGBA{0, 120, 215, 255}
+
+	// EditorGuideColor is the color of alignment guides
+	EditorGuideColor = color.RGBA{230, 0, 140, 255}
+)
+
+// the actions that a drag can perform
+const (
+	dragNone = iota
+	dragMove
+	dragScale
+	dragRotate
+	dragNode
+	dragRubber
+)
+
+// EditorDrag is the state of a drag in the Editor, starting when the mouse
+// button is pressed -- positions are in svg image pixels
+type EditorDrag struct {
+	Action    int             `desc:"what the drag does"`
+	Start     gi.Vec2D        `desc:"position at start of drag"`
+	Cur       gi.Vec2D        `desc:"current position"`
+	Handle    int             `desc:"index of the handle being dragged, for resizing"`
+	BBox      image.Rectangle `desc:"bounding box of the selection at start of drag"`
+	XForms    []gi.Matrix2D   `desc:"transforms of the selected elements at start of drag"`
+	ParXForms []gi.Matrix2D   `desc:"transforms from the user space of the parent of each selected element to svg image pixels"`
+	Before    []EditorState   `desc:"state of the selected elements at start of drag, for undo"`
+	Path      *Path           `desc:"path whose node is being dragged"`
+	Data      []PathData      `desc:"path data, in absolute form, at start of node drag"`
+	Nodes     []PathNode      `desc:"path nodes at start of node drag"`
+	Node      int             `desc:"index of the path node being dragged"`
+	SnapXs    []float32       `desc:"x positions of vertical lines that the drag can snap to"`
+	SnapYs    []float32       `desc:"y positions of horizontal lines that the drag can snap to"`
+	GuideXs   []float32       `desc:"x positions of vertical alignment guides currently snapped to"`
+	GuideYs   []float32       `desc:"y positions of horizontal alignment guides currently snapped to"`
+}
+
 // EditorEvents handles svg editing events
 func (svg *Editor) EditorEvents() {
 	svg.ConnectEvent(oswin.MouseDragEvent, gi.RegPri, func(recv, send ki.Ki, sig int64, d interface{}) {
 		me := d.(*mouse.DragEvent)
 		me.SetProcessed()
 		ssvg := recv.Embed(KiT_Editor).(*Editor)
-		if ssvg.IsDragging() {
+		if ssvg.Drag.Action != dragNone {
+			ssvg.DragTo(ssvg.ImagePos(me.Where), me.Modifiers)
+			return
+		}
+		if ssvg.IsDragging() && ssvg.Mode == EditPan {
 			if !ssvg.SetDragCursor {
 				oswin.TheApp.Cursor(ssvg.Viewport.Win.OSWin).Push(cursor.HandOpen)
 				ssvg.SetDragCursor = true
@@ -75,7 +173,19 @@ func (svg *Editor) EditorEvents() {
 			oswin.TheApp.Cursor(ssvg.Viewport.Win.OSWin).Pop()
 			ssvg.SetDragCursor = false
 		}
-		obj := ssvg.FirstContainingPoint(me.Where, true)
+		if me.Button == mouse.Left && ssvg.Mode != EditPan {
+			switch me.Action {
+			case mouse.Press:
+				me.SetProcessed()
+				ssvg.GrabFocus()
+				ssvg.DragStart(ssvg.ImagePos(me.Where), me)
+			case mouse.Release:
+				me.SetProcessed()
+				ssvg.DragEnd(me.Modifiers)
+			}
+			return
+		}
+		obj := ssvg.ElementAt(ssvg.ImagePos(me.Where), true)
 		if me.Action == mouse.Release && me.Button == mouse.Right {
 			me.SetProcessed()
 			if obj != nil {
@@ -87,13 +197,38 @@ func (svg *Editor) EditorEvents() {
 		me := d.(*mouse.HoverEvent)
 		me.SetProcessed()
 		ssvg := recv.Embed(KiT_Editor).(*Editor)
-		obj := ssvg.FirstContainingPoint(me.Where, true)
+		obj := ssvg.ElementAt(ssvg.ImagePos(me.Where), true)
 		if obj != nil {
 			pos := me.Where
 			ttxt := fmt.Sprintf("element name: %v -- use right mouse click to edit", obj.Name())
 			gi.PopupTooltip(obj.Name(), pos.X, pos.Y, svg.Viewport, ttxt)
 		}
 	})
+	svg.ConnectEvent(oswin.KeyChordEvent, gi.RegPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		ssvg := recv.Embed(KiT_Editor).(*Editor)
+		kt := d.(*key.ChordEvent)
+		ssvg.KeyInput(kt)
+	})
+}
+
+// KeyInput handles keyboard input: undo, redo, select all, and abort which
+// clears the selection
+func (svg *Editor) KeyInput(kt *key.ChordEvent) {
+	kf := gi.KeyFun(kt.Chord())
+	switch kf {
+	case gi.KeyFunUndo:
+		kt.SetProcessed()
+		svg.Undo()
+	case gi.KeyFunRedo:
+		kt.SetProcessed()
+		svg.Redo()
+	case gi.KeyFunSelectAll:
+		kt.SetProcessed()
+		svg.SelectAll()
+	case gi.KeyFunAbort:
+		kt.SetProcessed()
+		svg.ClearSelected()
+	}
 }
 
 // InitScale ensures that Scale is initialized and non-zero
@@ -113,6 +248,521 @@ func (svg *Editor) SetTransform() {
 	svg.SetProp("transform", fmt.Sprintf("translate(%v,%v) scale(%v,%v)", svg.Trans.X, svg.Trans.Y, svg.Scale, svg.Scale))
 }
 
+// SetMode sets the editing mode, ending any current drag
+func (svg *Editor) SetMode(mode EditorModes) {
+	svg.Drag.Action = dragNone
+	svg.Mode = mode
+	svg.SetFullReRender()
+	svg.UpdateSig()
+}
+
+// OpenXML opens svg input from given file, clearing the selection and the
+// undo stack
+func (svg *Editor) OpenXML(filename string) error {
+	svg.Selected = nil
+	svg.Drag = EditorDrag{}
+	svg.ResetUndo()
+	return svg.SVG.OpenXML(filename)
+}
+
+/////////////////////////////////////////////////////////////////////////////
+//   Selection
+
+// IsSelected returns true if given element is selected
+func (svg *Editor) IsSelected(nii gi.Node2D) bool {
+	for _, s := range svg.Selected {
+		if s.This() == nii.This() {
+			return true
+		}
+	}
+	return false
+}
+
+// Select selects given element -- if extend is true, it is added to the
+// selection, or removed if it is already selected
+func (svg *Editor) Select(nii gi.Node2D, extend bool) {
+	if !extend {
+		svg.Selected = append(svg.Selected[:0], nii)
+		return
+	}
+	for i, s := range svg.Selected {
+		if s.This() == nii.This() {
+			svg.Selected = append(svg.Selected[:i], svg.Selected[i+1:]...)
+			return
+		}
+	}
+	svg.Selected = append(svg.Selected, nii)
+}
+
+// ClearSelected clears the selection
+func (svg *Editor) ClearSelected() {
+	if len(svg.Selected) == 0 {
+		return
+	}
+	svg.Selected = svg.Selected[:0]
+	svg.SetFullReRender()
+	svg.UpdateSig()
+}
+
+// SelectAll selects all the top-level elements
+func (svg *Editor) SelectAll() {
+	svg.Selected = svg.Selected[:0]
+	for _, kid := range svg.Kids {
+		if nii, _ := gi.KiToNode2D(kid); nii != nil {
+			svg.Selected = append(svg.Selected, nii)
+		}
+	}
+	svg.SetFullReRender()
+	svg.UpdateSig()
+}
+
+// SelectRect selects the elements whose bounding boxes are entirely within
+// given rectangle in svg image pixels -- if leaf is true, these are the
+// innermost elements within any groups, and otherwise top-level elements
+func (svg *Editor) SelectRect(r image.Rectangle, leaf, extend bool) {
+	if !extend {
+		svg.Selected = svg.Selected[:0]
+	}
+	svg.FuncDownMeFirst(0, nil, func(k ki.Ki, level int, d interface{}) bool {
+		if k == svg.This() {
+			return true
+		}
+		nii, ni := gi.KiToNode2D(k)
+		if nii == nil {
+			return false
+		}
+		if leaf && nii.HasChildren() {
+			return true
+		}
+		if !ni.BBox.Empty() && ni.BBox.In(r) && !svg.IsSelected(nii) {
+			svg.Selected = append(svg.Selected, nii)
+		}
+		return false
+	})
+}
+
+// SelectionBBox returns the bounding box of the selection, in svg image
+// pixels
+func (svg *Editor) SelectionBBox() image.Rectangle {
+	bb := image.ZR
+	for i, nii := range svg.Selected {
+		if i == 0 {
+			bb = nii.AsNode2D().BBox
+		} else {
+			bb = bb.Union(nii.AsNode2D().BBox)
+		}
+	}
+	return bb
+}
+
+// SelectedPath returns the first selected Path, which is the one whose nodes
+// are edited in EditNodes mode, or nil if none
+func (svg *Editor) SelectedPath() *Path {
+	for _, nii := range svg.Selected {
+		if pn, ok := nii.(*Path); ok {
+			return pn
+		}
+	}
+	return nil
+}
+
+// pruneSelected removes any selected elements that are no longer in the svg
+func (svg *Editor) pruneSelected() {
+	sel := svg.Selected[:0]
+	for _, nii := range svg.Selected {
+		if isAncestor(svg.This(), nii) {
+			sel = append(sel, nii)
+		}
+	}
+	svg.Selected = sel
+}
+
+// isAncestor returns true if par is an ancestor of k
+func isAncestor(par, k ki.Ki) bool {
+	for p := k.Parent(); p != nil; p = p.Parent() {
+		if p == par {
+			return true
+		}
+	}
+	return false
+}
+
+// SelectionHandles returns the positions of the handles for given selection
+// bounding box, in svg image pixels: the 8 resize handles, clockwise from the
+// top-left corner, followed by the rotate handle above the top edge
+func SelectionHandles(bb image.Rectangle) []gi.Vec2D {
+	mn := gi.NewVec2DFmPoint(bb.Min)
+	mx := gi.NewVec2DFmPoint(bb.Max)
+	c := mn.Add(mx).MulVal(0.5)
+	return []gi.Vec2D{
+		{mn.X, mn.Y}, {c.X, mn.Y}, {mx.X, mn.Y}, {mx.X, c.Y},
+		{mx.X, mx.Y}, {c.X, mx.Y}, {mn.X, mx.Y}, {mn.X, c.Y},
+		{c.X, mn.Y - EditorRotateDist},
+	}
+}
+
+// handleAt returns the index of the handle at given point, or -1 if none
+func handleAt(hs []gi.Vec2D, pt gi.Vec2D) int {
+	for i := len(hs) - 1; i >= 0; i-- {
+		if hs[i].Distance(pt) <= EditorHandleSize {
+			return i
+		}
+	}
+	return -1
+}
+
+/////////////////////////////////////////////////////////////////////////////
+//   Dragging
+
+// DragStart starts a drag from given point in svg image pixels, according
+// to the mode and what is at that point
+func (svg *Editor) DragStart(pt gi.Vec2D, me *mouse.Event) {
+	dr := &svg.Drag
+	*dr = EditorDrag{Start: pt, Cur: pt, GuideXs: dr.GuideXs[:0], GuideYs: dr.GuideYs[:0]}
+	svg.pruneSelected()
+	extend := me.SelectMode() != mouse.SelectOne
+	leaf := svg.Mode == EditNodes || me.HasAnyModifier(key.Control)
+	switch {
+	case svg.Mode == EditNodes && svg.nodeDragStart(pt):
+	case svg.Mode == EditSelect && len(svg.Selected) > 0 && !extend && svg.handleDragStart(pt):
+	default:
+		if el := svg.ElementAt(pt, leaf); el != nil {
+			if extend {
+				svg.Select(el, true)
+			} else if !svg.IsSelected(el) {
+				svg.Select(el, false)
+			}
+			if svg.IsSelected(el) {
+				dr.Action = dragMove
+			}
+		} else {
+			if !extend {
+				svg.Selected = svg.Selected[:0]
+			}
+			dr.Action = dragRubber
+		}
+	}
+	if dr.Action != dragRubber && dr.Action != dragNone {
+		svg.dragInit()
+	}
+	svg.SetFullReRender()
+	svg.UpdateSig()
+}
+
+// handleDragStart starts a resize or rotate drag if given point is on a
+// selection handle, returning true if so
+func (svg *Editor) handleDragStart(pt gi.Vec2D) bool {
+	hi := handleAt(SelectionHandles(svg.SelectionBBox()), pt)
+	if hi < 0 {
+		return false
+	}
+	dr := &svg.Drag
+	dr.Handle = hi
+	if hi == 8 {
+		dr.Action = dragRotate
+	} else {
+		dr.Action = dragScale
+	}
+	return true
+}
+
+// nodeDragStart starts a node drag if given point is on a node of the
+// selected path, returning true if so -- the path data is converted to the
+// absolute form used for editing (see PathDataAbs)
+func (svg *Editor) nodeDragStart(pt gi.Vec2D) bool {
+	pn := svg.SelectedPath()
+	if pn == nil {
+		return false
+	}
+	data := PathDataAbs(pn.Data)
+	nodes := PathDataNodes(data)
+	fxf := pn.Pnt.XForm.Multiply(svg.ParentXForm(pn))
+	hs := make([]gi.Vec2D, len(nodes))
+	for i := range nodes {
+		hs[i] = fxf.TransformPointVec2D(nodes[i].Pt)
+	}
+	ni := handleAt(hs, pt)
+	if ni < 0 {
+		return false
+	}
+	dr := &svg.Drag
+	dr.Before = SaveState([]gi.Node2D{pn})
+	pn.Data = data
+	dr.Action = dragNode
+	dr.Path = pn
+	dr.Data = append([]PathData{}, data...)
+	dr.Nodes = nodes
+	dr.Node = ni
+	dr.Start = hs[ni] // drag the node itself, not the point clicked
+	dr.Cur = dr.Start
+	return true
+}
+
+// dragInit saves the state of the selection at the start of a drag, and
+// the positions that it can snap to
+func (svg *Editor) dragInit() {
+	dr := &svg.Drag
+	dr.BBox = svg.SelectionBBox()
+	for _, nii := range svg.Selected {
+		xf := gi.Identity2D()
+		if pntr, ok := nii.(gi.Painter); ok {
+			xf = pntr.Paint().XForm
+		}
+		dr.XForms = append(dr.XForms, xf)
+		dr.ParXForms = append(dr.ParXForms, svg.ParentXForm(nii))
+	}
+	if dr.Before == nil {
+		dr.Before = SaveState(svg.Selected)
+	}
+	svg.FuncDownMeFirst(0, nil, func(k ki.Ki, level int, d interface{}) bool {
+		if k == svg.This() {
+			return true
+		}
+		nii, ni := gi.KiToNode2D(k)
+		if nii == nil || svg.IsSelected(nii) {
+			return false
+		}
+		for _, s := range svg.Selected {
+			if isAncestor(k, s) { // contains the selection, so moves with it
+				return true
+			}
+		}
+		if !ni.BBox.Empty() {
+			bb := ni.BBox
+			dr.SnapXs = append(dr.SnapXs, float32(bb.Min.X), 0.5*float32(bb.Min.X+bb.Max.X), float32(bb.Max.X))
+			dr.SnapYs = append(dr.SnapYs, float32(bb.Min.Y), 0.5*float32(bb.Min.Y+bb.Max.Y), float32(bb.Max.Y))
+		}
+		return true
+	})
+	if svg.ViewBox.Size.X > 0 && svg.ViewBox.Size.Y > 0 {
+		mn := svg.Pnt.XForm.TransformPointVec2D(svg.ViewBox.Min)
+		mx := svg.Pnt.XForm.TransformPointVec2D(svg.ViewBox.Min.Add(svg.ViewBox.Size))
+		dr.SnapXs = append(dr.SnapXs, mn.X, 0.5*(mn.X+mx.X), mx.X)
+		dr.SnapYs = append(dr.SnapYs, mn.Y, 0.5*(mn.Y+mx.Y), mx.Y)
+	}
+}
+
+// DragTo continues the current drag to given point in svg image pixels,
+// with given modifier keys: Shift keeps the aspect ratio when resizing and
+// rotates in 15 degree steps, and Alt turns off snapping
+func (svg *Editor) DragTo(pt gi.Vec2D, mods int32) {
+	dr := &svg.Drag
+	dr.Cur = pt
+	dr.GuideXs = dr.GuideXs[:0]
+	dr.GuideYs = dr.GuideYs[:0]
+	shift := key.HasAnyModifierBits(mods, key.Shift)
+	switch dr.Action {
+	case dragMove:
+		del := pt.Sub(dr.Start)
+		mn := gi.NewVec2DFmPoint(dr.BBox.Min).Add(del)
+		mx := gi.NewVec2DFmPoint(dr.BBox.Max).Add(del)
+		c := mn.Add(mx).MulVal(0.5)
+		del = del.Add(svg.snapOffset([]float32{mn.X, c.X, mx.X}, []float32{mn.Y, c.Y, mx.Y}, mods))
+		svg.applyXForm(gi.Translate2D(del.X, del.Y))
+	case dragScale:
+		hs := SelectionHandles(dr.BBox)
+		h := hs[dr.Handle]
+		anc := hs[(dr.Handle+4)%8]
+		xax := dr.Handle != 1 && dr.Handle != 5
+		yax := dr.Handle != 3 && dr.Handle != 7
+		var xs, ys []float32
+		if xax {
+			xs = []float32{pt.X}
+		}
+		if yax {
+			ys = []float32{pt.Y}
+		}
+		pt = pt.Add(svg.snapOffset(xs, ys, mods))
+		sx, sy := float32(1), float32(1)
+		if xax && h.X != anc.X {
+			sx = (pt.X - anc.X) / (h.X - anc.X)
+		}
+		if yax && h.Y != anc.Y {
+			sy = (pt.Y - anc.Y) / (h.Y - anc.Y)
+		}
+		if shift {
+			switch {
+			case xax && yax:
+				if math32.Abs(sx) > math32.Abs(sy) {
+					sy = math32.Copysign(sx, sy)
+				} else {
+					sx = math32.Copysign(sy, sx)
+				}
+			case xax:
+				sy = math32.Abs(sx)
+			default:
+				sx = math32.Abs(sy)
+			}
+		}
+		sx = minScale(sx)
+		sy = minScale(sy)
+		svg.applyXForm(gi.Translate2D(-anc.X, -anc.Y).Multiply(gi.Scale2D(sx, sy)).Multiply(gi.Translate2D(anc.X, anc.Y)))
+	case dragRotate:
+		bb := dr.BBox
+		c := gi.NewVec2DFmPoint(bb.Min.Add(bb.Max)).MulVal(0.5)
+		ang := math32.Atan2(pt.Y-c.Y, pt.X-c.X) - math32.Atan2(dr.Start.Y-c.Y, dr.Start.X-c.X)
+		if shift {
+			step := math32.Pi / 12
+			ang = math32.Floor(ang/step+0.5) * step
+		}
+		svg.applyXForm(gi.Translate2D(-c.X, -c.Y).Multiply(gi.Rotate2D(ang)).Multiply(gi.Translate2D(c.X, c.Y)))
+	case dragNode:
+		pt = pt.Add(svg.snapOffset([]float32{pt.X}, []float32{pt.Y}, mods))
+		pn := dr.Path
+		fxf := pn.Pnt.XForm.Multiply(svg.ParentXForm(pn))
+		nd := dr.Nodes[dr.Node]
+		del := fxf.Inverse().TransformPointVec2D(pt).Sub(nd.Pt)
+		data := append(pn.Data[:0], dr.Data...)
+		moveNode := func(n PathNode) {
+			data[n.Idx] = PathData(n.Pt.X + del.X)
+			data[n.Idx+1] = PathData(n.Pt.Y + del.Y)
+		}
+		moveNode(nd)
+		if !nd.Ctrl {
+			for _, n := range dr.Nodes {
+				if n.Ctrl && n.Node == dr.Node {
+					moveNode(n)
+				}
+			}
+		}
+		pn.Data = data
+		pn.DataStr = PathDataString(data)
+	}
+	svg.SetFullReRender()
+	svg.UpdateSig()
+}
+
+// DragEnd ends the current drag, selecting the elements within the
+// rubber-band box, or recording the edit for undo
+func (svg *Editor) DragEnd(mods int32) {
+	dr := &svg.Drag
+	switch dr.Action {
+	case dragRubber:
+		r := image.Rectangle{dr.Start.ToPoint(), dr.Cur.ToPoint()}.Canon()
+		svg.SelectRect(r, svg.Mode == EditNodes, key.HasAnyModifierBits(mods, key.Shift, key.Meta))
+	case dragMove:
+		svg.SaveUndo("Move", dr.Before)
+	case dragScale:
+		svg.SaveUndo("Resize", dr.Before)
+	case dragRotate:
+		svg.SaveUndo("Rotate", dr.Before)
+	case dragNode:
+		svg.SaveUndo("Edit Node", dr.Before)
+	}
+	dr.Action = dragNone
+	dr.GuideXs = dr.GuideXs[:0]
+	dr.GuideYs = dr.GuideYs[:0]
+	svg.SetFullReRender()
+	svg.UpdateSig()
+}
+
+// minScale keeps a scaling factor away from zero, so that the transform
+// stays invertible
+func minScale(s float32) float32 {
+	if math32.Abs(s) < 0.001 {
+		return math32.Copysign(0.001, s)
+	}
+	return s
+}
+
+// ParentXForm returns the transform from the user space of the parent of
+// given element to svg image pixels
+func (svg *Editor) ParentXForm(nii gi.Node2D) gi.Matrix2D {
+	xf := gi.Identity2D()
+	for p := nii.Parent(); p != nil && p != svg.This(); p = p.Parent() {
+		if pntr, ok := p.(gi.Painter); ok {
+			xf = xf.Multiply(pntr.Paint().XForm)
+		}
+	}
+	return xf.Multiply(svg.Pnt.XForm)
+}
+
+// applyXForm sets the transform of each selected element to its transform
+// at the start of the drag, followed by given transform in svg image pixels
+func (svg *Editor) applyXForm(pxf gi.Matrix2D) {
+	dr := &svg.Drag
+	for i, nii := range svg.Selected {
+		if i >= len(dr.XForms) {
+			break
+		}
+		pm := dr.ParXForms[i]
+		xf := dr.XForms[i].Multiply(pm).Multiply(pxf).Multiply(pm.Inverse())
+		SetNodeXForm(nii, fmt.Sprintf("matrix(%v,%v,%v,%v,%v,%v)", xf.XX, xf.YX, xf.XY, xf.YY, xf.X0, xf.Y0))
+	}
+}
+
+// snapOffset returns the offset to add to a dragged object with given
+// positions along each axis in svg image pixels (e.g., its edges and
+// center) to snap it to the edges and centers of other elements, or to the
+// grid, and sets the alignment guides for any edges snapped to
+func (svg *Editor) snapOffset(xs, ys []float32, mods int32) gi.Vec2D {
+	var off gi.Vec2D
+	if !svg.Snap || key.HasAnyModifierBits(mods, key.Alt) {
+		return off
+	}
+	dr := &svg.Drag
+	dist := svg.SnapDist
+	if dist <= 0 {
+		dist = EditorSnapDist
+	}
+	snx, sny := false, false
+	if o, t, ok := snapAxis(xs, dr.SnapXs, dist); ok {
+		off.X = o
+		dr.GuideXs = append(dr.GuideXs, t)
+		snx = true
+	}
+	if o, t, ok := snapAxis(ys, dr.SnapYs, dist); ok {
+		off.Y = o
+		dr.GuideYs = append(dr.GuideYs, t)
+		sny = true
+	}
+	if svg.SnapGrid > 0 {
+		var ref gi.Vec2D
+		if len(xs) > 0 {
+			ref.X = xs[0]
+		}
+		if len(ys) > 0 {
+			ref.Y = ys[0]
+		}
+		gp := svg.Pnt.XForm.Inverse().TransformPointVec2D(ref)
+		gp.X = math32.Floor(gp.X/svg.SnapGrid+0.5) * svg.SnapGrid
+		gp.Y = math32.Floor(gp.Y/svg.SnapGrid+0.5) * svg.SnapGrid
+		gp = svg.Pnt.XForm.TransformPointVec2D(gp)
+		if !snx && len(xs) > 0 {
+			off.X = gp.X - ref.X
+		}
+		if !sny && len(ys) > 0 {
+			off.Y = gp.Y - ref.Y
+		}
+	}
+	return off
+}
+
+// snapAxis returns the offset that brings the closest of vals onto one of
+// targs, and that target, if it is within dist
+func snapAxis(vals, targs []float32, dist float32) (off, targ float32, ok bool) {
+	best := dist
+	for _, v := range vals {
+		for _, t := range targs {
+			if d := math32.Abs(t - v); d <= best {
+				best = d
+				off = t - v
+				targ = t
+				ok = true
+			}
+		}
+	}
+	return
+}
+
+/////////////////////////////////////////////////////////////////////////////
+//   Render
+
+func (svg *Editor) Style2D() {
+	svg.SetCanFocusIfActive()
+	svg.SVG.Style2D()
+}
+
 func (svg *Editor) Render2D() {
 	if svg.PushBounds() {
 		rs := &svg.Render
@@ -122,8 +772,98 @@ func (svg *Editor) Render2D() {
 		}
 		rs.PushXForm(svg.Pnt.XForm)
 		svg.Render2DChildren() // we must do children first, then us!
-		svg.PopBounds()
 		rs.PopXForm()
+		svg.RenderSelection()
+		svg.PopBounds()
 		svg.RenderViewport2D() // update our parent image
 	}
 }
+
+// RenderSelection renders the selection box and handles, the nodes of the
+// selected path in EditNodes mode, the rubber-band box, and any alignment
+// guides, over the svg image
+func (svg *Editor) RenderSelection() {
+	if svg.Mode == EditPan {
+		return
+	}
+	svg.pruneSelected()
+	rs := &svg.Render
+	pc := gi.NewPaint()
+	pc.StrokeStyle.Width.Dots = 1
+	dr := &svg.Drag
+	rs.Lock()
+	defer rs.Unlock()
+
+	if dr.Action == dragRubber {
+		r := image.Rectangle{dr.Start.ToPoint(), dr.Cur.ToPoint()}.Canon()
+		pc.FillStyle.SetColor(nil)
+		pc.StrokeStyle.SetColor(EditorSelectColor)
+		pc.DrawRectangle(rs, float32(r.Min.X), float32(r.Min.Y), float32(r.Dx()), float32(r.Dy()))
+		pc.FillStrokeClear(rs)
+	}
+
+	if len(dr.GuideXs) > 0 || len(dr.GuideYs) > 0 {
+		sz := gi.NewVec2DFmPoint(rs.Image.Bounds().Size())
+		pc.StrokeStyle.SetColor(EditorGuideColor)
+		for _, x := range dr.GuideXs {
+			pc.DrawLine(rs, x, 0, x, sz.Y)
+		}
+		for _, y := range dr.GuideYs {
+			pc.DrawLine(rs, 0, y, sz.X, y)
+		}
+		pc.Stroke(rs)
+	}
+
+	if len(svg.Selected) == 0 {
+		return
+	}
+	pc.FillStyle.SetColor(nil)
+	pc.StrokeStyle.SetColor(EditorSelectColor)
+	for _, nii := range svg.Selected {
+		bb := nii.AsNode2D().BBox
+		pc.DrawRectangle(rs, float32(bb.Min.X), float32(bb.Min.Y), float32(bb.Dx()), float32(bb.Dy()))
+	}
+	pc.Stroke(rs)
+
+	hsz := EditorHandleSize
+	if svg.Mode == EditNodes {
+		pn := svg.SelectedPath()
+		if pn == nil {
+			return
+		}
+		nodes := PathDataNodes(PathDataAbs(pn.Data))
+		fxf := pn.Pnt.XForm.Multiply(svg.ParentXForm(pn))
+		for _, n := range nodes {
+			if n.Ctrl && n.Node >= 0 && n.Node < len(nodes) {
+				p := fxf.TransformPointVec2D(n.Pt)
+				a := fxf.TransformPointVec2D(nodes[n.Node].Pt)
+				pc.DrawLine(rs, a.X, a.Y, p.X, p.Y)
+			}
+		}
+		pc.Stroke(rs)
+		pc.FillStyle.SetColor(color.White)
+		for _, n := range nodes {
+			p := fxf.TransformPointVec2D(n.Pt)
+			if n.Ctrl {
+				pc.DrawCircle(rs, p.X, p.Y, 0.4*hsz)
+			} else {
+				pc.DrawRectangle(rs, p.X-0.5*hsz, p.Y-0.5*hsz, hsz, hsz)
+			}
+			pc.FillStrokeClear(rs)
+		}
+		return
+	}
+
+	hs := SelectionHandles(svg.SelectionBBox())
+	pc.DrawLine(rs, hs[1].X, hs[1].Y, hs[8].X, hs[8].Y)
+	pc.Stroke(rs)
+	pc.FillStyle.SetColor(color.White)
+	for i, h := range hs {
+		if i == 8 {
+			pc.DrawCircle(rs, h.X, h.Y, 0.5*hsz)
+		} else {
+			pc.DrawRectangle(rs, h.X-0.5*hsz, h.Y-0.5*hsz, hsz, hsz)
+		}
+		pc.FillStrokeClear(rs)
+	}
+}
@@ -0,0 +1,156 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package svg
+
+import (
+	"github.com/goki/gi/gi"
+)
+
+// EditorState is the editable state of one node in the Editor -- its
+// transform property and, for paths, its path data -- as saved for undo
+type EditorState struct {
+	Node  gi.Node2D `desc:"the node"`
+	XForm string    `desc:"transform property of the node -- empty if it has none"`
+	Data  string    `desc:"for Path nodes, the path data"`
+}
+
+// EditorUndo records one edit in the Editor, as the state of the nodes that
+// it changed, before and after the edit
+type EditorUndo struct {
+	Action string        `desc:"description of the edit"`
+	Before []EditorState `desc:"state of the nodes before the edit"`
+	After  []EditorState `desc:"state of the nodes after the edit"`
+}
+
+// SaveState returns the current editable state of given nodes
+func SaveState(nodes []gi.Node2D) []EditorState {
+	sts := make([]EditorState, len(nodes))
+	for i, nii := range nodes {
+		st := &sts[i]
+		st.Node = nii
+		if xf, ok := nii.Prop("transform"); ok {
+			st.XForm, _ = xf.(string)
+		}
+		if pn, ok := nii.(*Path); ok {
+			st.Data = PathDataString(pn.Data)
+		}
+	}
+	return sts
+}
+
+// RestoreState restores the editable state of nodes from given saved state
+func RestoreState(sts []EditorState) {
+	for _, st := range sts {
+		SetNodeXForm(st.Node, st.XForm)
+		if pn, ok := st.Node.(*Path); ok {
+			pn.SetData(st.Data)
+		}
+	}
+}
+
+// StatesEqual returns true if the two saved states are the same
+func StatesEqual(a, b []EditorState) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// SetNodeXForm sets the transform property of given node to given svg
+// transform string, and also its current transform, so that it takes effect
+// without restyling -- an empty string removes the transform
+func SetNodeXForm(nii gi.Node2D, xf string) {
+	pntr, ok := nii.(gi.Painter)
+	if !ok {
+		return
+	}
+	pc := pntr.Paint()
+	if xf == "" {
+		nii.DeleteProp("transform")
+		pc.XForm = gi.Identity2D()
+		return
+	}
+	nii.SetProp("transform", xf)
+	pc.XForm.SetString(xf)
+}
+
+// SaveUndo adds an undo record for an edit with given description, which
+// changed the given nodes from the given before state to their current state
+// -- any edits that had been undone can no longer be redone.  Returns false
+// if the edit did not actually change anything, in which case it is not
+// recorded.
+func (svg *Editor) SaveUndo(action string, before []EditorState) bool {
+	nodes := make([]gi.Node2D, len(before))
+	for i := range before {
+		nodes[i] = before[i].Node
+	}
+	after := SaveState(nodes)
+	if StatesEqual(before, after) {
+		return false
+	}
+	svg.Undos = append(svg.Undos[:svg.UndoPos], EditorUndo{Action: action, Before: before, After: after})
+	svg.UndoPos = len(svg.Undos)
+	return true
+}
+
+// CanUndo returns true if there is an edit that can be undone
+func (svg *Editor) CanUndo() bool {
+	return svg.UndoPos > 0
+}
+
+// CanRedo returns true if there is an undone edit that can be redone
+func (svg *Editor) CanRedo() bool {
+	return svg.UndoPos < len(svg.Undos)
+}
+
+// Undo undoes the last edit -- returns its description, or "" if there was
+// nothing to undo
+func (svg *Editor) Undo() string {
+	if !svg.CanUndo() {
+		return ""
+	}
+	svg.UndoPos--
+	ud := &svg.Undos[svg.UndoPos]
+	RestoreState(ud.Before)
+	svg.selectStates(ud.Before)
+	svg.SetFullReRender()
+	svg.UpdateSig()
+	return ud.Action
+}
+
+// Redo redoes the last undone edit -- returns its description, or "" if
+// there was nothing to redo
+func (svg *Editor) Redo() string {
+	if !svg.CanRedo() {
+		return ""
+	}
+	ud := &svg.Undos[svg.UndoPos]
+	svg.UndoPos++
+	RestoreState(ud.After)
+	svg.selectStates(ud.After)
+	svg.SetFullReRender()
+	svg.UpdateSig()
+	return ud.Action
+}
+
+// ResetUndo clears the undo stack
+func (svg *Editor) ResetUndo() {
+	svg.Undos = nil
+	svg.UndoPos = 0
+}
+
+// selectStates selects the nodes of given states, so that the result of an
+// undo or redo is visible
+func (svg *Editor) selectStates(sts []EditorState) {
+	svg.Selected = svg.Selected[:0]
+	for _, st := range sts {
+		svg.Selected = append(svg.Selected, st.Node)
+	}
+}
@@ -35,3 +35,8 @@ func (g *Ellipse) Render2D() {
 
 	rs.PopXFormLock()
 }
+
+// ShapeData returns the path of the ellipse, for hit testing -- see Shape
+func (g *Ellipse) ShapeData() []PathData {
+	return PathDataEllipse(g.Pos, g.Radii)
+}
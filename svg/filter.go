@@ -5,13 +5,498 @@
 package svg
 
 import (
+	"image"
+	"image/draw"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/chewxy/math32"
+	"github.com/goki/gi/gi"
 	"github.com/goki/ki/kit"
 )
 
-// Filter represents SVG filter* elements
+// Filter represents SVG filter* elements -- the filter element itself has
+// FilterType = "filter", and its children are the filter primitives, with
+// FilterType = the element name, e.g., feGaussianBlur -- all attributes are
+// stored as properties.  Any node with a filter = url(#id) property referring
+// to a filter element is rendered through the filter -- see Render2DEffects.
 type Filter struct {
 	NodeBase
 	FilterType string
 }
 
 var KiT_Filter = kit.Types.AddType(&Filter{}, nil)
+
+// Render2D does nothing -- filters are only applied to other nodes, via
+// ApplyFilter
+func (g *Filter) Render2D() {
+}
+
+// FilterRegion returns the region of the filter, in render image pixels, for
+// a node with given bounding box (in pixels) rendered with given transform --
+// this is the x, y, width, height properties in filterUnits (default
+// -10%, -10%, 120%, 120% of the bounding box).  For userSpaceOnUse, the
+// percentages (including the defaults) are relative to given viewport size,
+// in user space (see ViewportSize).
+func (g *Filter) FilterRegion(bbox image.Rectangle, xform gi.Matrix2D, vpsz gi.Vec2D) image.Rectangle {
+	if un, _ := g.Props["filterUnits"].(string); un == "userSpaceOnUse" {
+		x := filterLength(g.Props["x"], -0.1, vpsz.X)
+		y := filterLength(g.Props["y"], -0.1, vpsz.Y)
+		w := filterLength(g.Props["width"], 1.2, vpsz.X)
+		h := filterLength(g.Props["height"], 1.2, vpsz.Y)
+		x0, y0 := xform.TransformPoint(x, y)
+		x1, y1 := xform.TransformPoint(x+w, y+h)
+		return filterRect(x0, y0, x1, y1)
+	}
+	x := filterFrac(g.Props["x"], -0.1)
+	y := filterFrac(g.Props["y"], -0.1)
+	w := filterFrac(g.Props["width"], 1.2)
+	h := filterFrac(g.Props["height"], 1.2)
+	bw := float32(bbox.Dx())
+	bh := float32(bbox.Dy())
+	x0 := float32(bbox.Min.X) + x*bw
+	y0 := float32(bbox.Min.Y) + y*bh
+	return filterRect(x0, y0, x0+w*bw, y0+h*bh)
+}
+
+// filterRect returns the pixel rectangle covering given region, ignoring
+// float rounding errors of less than a thousandth of a pixel
+func filterRect(x0, y0, x1, y1 float32) image.Rectangle {
+	const eps = 0.001
+	if x1 < x0 {
+		x0, x1 = x1, x0
+	}
+	if y1 < y0 {
+		y0, y1 = y1, y0
+	}
+	return image.Rect(int(math32.Floor(x0+eps)), int(math32.Floor(y0+eps)), int(math32.Ceil(x1-eps)), int(math32.Ceil(y1-eps)))
+}
+
+// ApplyFilter runs the filter primitives on given source image, which is the
+// rendering of a node with given bounding box (in pixels) using given
+// transform, in a viewport of given size in user space, returning the
+// filtered result, restricted to the FilterRegion.
+// Each primitive takes its input(s) from the in (and in2) property, which can
+// be SourceGraphic, SourceAlpha, or the result name of a prior primitive --
+// the default is the result of the previous primitive.  All operations are
+// done in sRGB color space.  Supported primitives are: feGaussianBlur,
+// feOffset, feFlood, feMerge, feBlend, feColorMatrix and feComposite -- others
+// just pass their input through.
+func (g *Filter) ApplyFilter(src *image.RGBA, bbox image.Rectangle, xform gi.Matrix2D, vpsz gi.Vec2D) *image.RGBA {
+	reg := g.FilterRegion(bbox, xform, vpsz).Intersect(src.Bounds())
+	scx, scy := xform.ExtractScale()
+	scx, scy = math32.Abs(scx), math32.Abs(scy)
+
+	results := map[string]*image.RGBA{}
+	var last *image.RGBA
+	srcAlpha := (*image.RGBA)(nil)
+	input := func(nm interface{}) *image.RGBA {
+		nms, _ := nm.(string)
+		switch nms {
+		case "", "SourceGraphic":
+			if nms == "" && last != nil {
+				return last
+			}
+			return src
+		case "SourceAlpha":
+			if srcAlpha == nil {
+				srcAlpha = filterSourceAlpha(src, reg)
+			}
+			return srcAlpha
+		}
+		if rs, has := results[nms]; has {
+			return rs
+		}
+		log.Printf("gi.svg Filter: %v input not found: %v\n", g.Nm, nms)
+		return src
+	}
+
+	for _, kid := range g.Kids {
+		fp, ok := kid.(*Filter)
+		if !ok {
+			continue
+		}
+		in := input(fp.Props["in"])
+		var res *image.RGBA
+		switch fp.FilterType {
+		case "feGaussianBlur":
+			sd := filterNums(fp.Props["stdDeviation"])
+			sdx, sdy := float32(0), float32(0)
+			if len(sd) > 0 {
+				sdx, sdy = sd[0], sd[0]
+			}
+			if len(sd) > 1 {
+				sdy = sd[1]
+			}
+			res = filterBlur(in, reg, sdx*scx, sdy*scy)
+		case "feOffset":
+			dx := filterNum(fp.Props["dx"], 0)
+			dy := filterNum(fp.Props["dy"], 0)
+			tdx, tdy := xform.TransformVector(dx, dy)
+			res = filterOffset(in, reg, int(math32.Floor(tdx+0.5)), int(math32.Floor(tdy+0.5)))
+		case "feFlood":
+			res = image.NewRGBA(src.Bounds())
+			clr := gi.Color{}
+			if fc, ok := fp.Props["flood-color"].(string); ok {
+				clr.SetString(fc, nil)
+			} else {
+				clr.SetUInt8(0, 0, 0, 255)
+			}
+			op := filterNum(fp.Props["flood-opacity"], 1)
+			r, gg, b, a := clr.ToNPFloat32()
+			a *= op
+			fc := gi.Color{}
+			fc.SetNPFloat32(r, gg, b, a)
+			draw.Draw(res, reg, image.NewUniform(fc), image.ZP, draw.Src)
+		case "feMerge":
+			res = image.NewRGBA(src.Bounds())
+			for _, mk := range fp.Kids {
+				mn, ok := mk.(*Filter)
+				if !ok || mn.FilterType != "feMergeNode" {
+					continue
+				}
+				draw.Draw(res, reg, input(mn.Props["in"]), reg.Min, draw.Over)
+			}
+		case "feBlend":
+			mode, _ := fp.Props["mode"].(string)
+			res = filterBlend(in, input(fp.Props["in2"]), reg, mode)
+		case "feColorMatrix":
+			typ, _ := fp.Props["type"].(string)
+			res = filterColorMatrix(in, reg, typ, filterNums(fp.Props["values"]))
+		case "feComposite":
+			op, _ := fp.Props["operator"].(string)
+			var ks [4]float32
+			for i := range ks {
+				ks[i] = filterNum(fp.Props["k"+strconv.Itoa(i+1)], 0)
+			}
+			res = filterComposite(in, input(fp.Props["in2"]), reg, op, ks)
+		default:
+			res = in
+		}
+		last = res
+		if rn, ok := fp.Props["result"].(string); ok && rn != "" {
+			results[rn] = res
+		}
+	}
+	if last == nil {
+		last = src
+	}
+	out := image.NewRGBA(src.Bounds())
+	draw.Draw(out, reg, last, reg.Min, draw.Src)
+	return out
+}
+
+// FilterOf returns the Filter referred to by the filter property of given
+// node, if set and found
+func FilterOf(nii gi.Node2D) *Filter {
+	ni := nii.AsNode2D()
+	fp, ok := ni.Props["filter"]
+	if !ok {
+		return nil
+	}
+	switch fv := fp.(type) {
+	case *Filter:
+		return fv
+	case string:
+		if fn := findURL(nii, fv); fn != nil {
+			fl, ok := fn.(*Filter)
+			if !ok {
+				log.Printf("gi.svg Found element named: %v but isn't a Filter type, instead is: %T", fv, fn)
+			}
+			return fl
+		}
+	}
+	return nil
+}
+
+// ViewportSize returns the size of the viewport of the svg that given node
+// is rendered in, in its user space -- userSpaceOnUse percentages are
+// relative to this
+func ViewportSize(nii gi.Node2D) gi.Vec2D {
+	for pvp := nii.AsNode2D().ParentViewport(); pvp != nil; pvp = pvp.ParentViewport() {
+		if !pvp.IsSVG() {
+			continue
+		}
+		if sv, ok := pvp.This().Embed(KiT_SVG).(*SVG); ok {
+			if sv.ViewBox.Size != gi.Vec2DZero {
+				return sv.ViewBox.Size
+			}
+			return gi.NewVec2DFmPoint(sv.Geom.Size)
+		}
+	}
+	return gi.Vec2DZero
+}
+
+/////////////////////////////////////////////////////////////////////////////
+//   Filter primitives -- all operate on alpha-premultiplied RGBA images
+
+// filterNums returns the numbers in given property value
+func filterNums(pv interface{}) []float32 {
+	switch v := pv.(type) {
+	case string:
+		return gi.ReadPoints(v)
+	case float32:
+		return []float32{v}
+	case float64:
+		return []float32{float32(v)}
+	}
+	return nil
+}
+
+// filterNum returns the number in given property value, or def if none
+func filterNum(pv interface{}, def float32) float32 {
+	ns := filterNums(pv)
+	if len(ns) == 0 {
+		return def
+	}
+	return ns[0]
+}
+
+// filterFrac returns the fraction in given property value, which can be a
+// percent, or def if none
+func filterFrac(pv interface{}, def float32) float32 {
+	if s, ok := pv.(string); ok && strings.HasSuffix(strings.TrimSpace(s), "%") {
+		return filterNum(strings.TrimSuffix(strings.TrimSpace(s), "%"), def*100) / 100
+	}
+	return filterNum(pv, def)
+}
+
+// filterLength returns the user space length in given property value, where
+// percentages, and the default fraction def, are relative to given size
+func filterLength(pv interface{}, def, size float32) float32 {
+	if s, ok := pv.(string); ok && strings.HasSuffix(strings.TrimSpace(s), "%") {
+		return filterFrac(pv, def) * size
+	}
+	return filterNum(pv, def*size)
+}
+
+// filterSourceAlpha returns the alpha channel of src, as black
+func filterSourceAlpha(src *image.RGBA, reg image.Rectangle) *image.RGBA {
+	dst := image.NewRGBA(src.Bounds())
+	for y := reg.Min.Y; y < reg.Max.Y; y++ {
+		for x := reg.Min.X; x < reg.Max.X; x++ {
+			pi := src.PixOffset(x, y)
+			dst.Pix[pi+3] = src.Pix[pi+3]
+		}
+	}
+	return dst
+}
+
+// filterBlur does a gaussian blur with given standard deviations, using
+// three box blurs as recommended in the SVG spec
+func filterBlur(src *image.RGBA, reg image.Rectangle, sdx, sdy float32) *image.RGBA {
+	boxSize := func(sd float32) int {
+		return int(sd*3*math32.Sqrt(2*math32.Pi)/4 + 0.5)
+	}
+	dx := boxSize(sdx)
+	dy := boxSize(sdy)
+	cur := image.NewRGBA(src.Bounds())
+	draw.Draw(cur, reg, src, reg.Min, draw.Src)
+	if dx <= 0 && dy <= 0 {
+		return cur
+	}
+	tmp := image.NewRGBA(src.Bounds())
+	for i := 0; i < 3; i++ {
+		if dx > 0 {
+			filterBoxBlur(tmp, cur, reg, dx, true)
+			cur, tmp = tmp, cur
+		}
+		if dy > 0 {
+			filterBoxBlur(tmp, cur, reg, dy, false)
+			cur, tmp = tmp, cur
+		}
+	}
+	return cur
+}
+
+// filterBoxBlur does a box blur of size d from src into dst, horizontally or
+// vertically, within region
+func filterBoxBlur(dst, src *image.RGBA, reg image.Rectangle, d int, horiz bool) {
+	lo := d / 2 // pixels before
+	hi := d - lo - 1
+	n := reg.Dx()
+	m := reg.Dy()
+	step := 4
+	if !horiz {
+		n, m = m, n
+		step = src.Stride
+	}
+	var sum [4]int
+	for j := 0; j < m; j++ {
+		var start int
+		if horiz {
+			start = src.PixOffset(reg.Min.X, reg.Min.Y+j)
+		} else {
+			start = src.PixOffset(reg.Min.X+j, reg.Min.Y)
+		}
+		sum = [4]int{}
+		for i := 0; i < hi && i < n; i++ {
+			pi := start + i*step
+			for c := 0; c < 4; c++ {
+				sum[c] += int(src.Pix[pi+c])
+			}
+		}
+		for i := 0; i < n; i++ {
+			if ai := i + hi; ai < n {
+				pi := start + ai*step
+				for c := 0; c < 4; c++ {
+					sum[c] += int(src.Pix[pi+c])
+				}
+			}
+			if ri := i - lo - 1; ri >= 0 {
+				pi := start + ri*step
+				for c := 0; c < 4; c++ {
+					sum[c] -= int(src.Pix[pi+c])
+				}
+			}
+			pi := start + i*step
+			for c := 0; c < 4; c++ {
+				dst.Pix[pi+c] = uint8((sum[c] + d/2) / d)
+			}
+		}
+	}
+}
+
+// filterOffset offsets src by given amount
+func filterOffset(src *image.RGBA, reg image.Rectangle, dx, dy int) *image.RGBA {
+	dst := image.NewRGBA(src.Bounds())
+	sr := reg.Add(image.Point{dx, dy}).Intersect(reg)
+	draw.Draw(dst, sr, src, sr.Min.Sub(image.Point{dx, dy}), draw.Src)
+	return dst
+}
+
+// filterPixFunc calls fun for each pixel in region, with values of a and b
+// (which can be nil) as normalized floats (still alpha-premultiplied), and
+// sets the result in a new image
+func filterPixFunc(a, b *image.RGBA, reg image.Rectangle, fun func(ca, cb [4]float32) [4]float32) *image.RGBA {
+	dst := image.NewRGBA(a.Bounds())
+	var ca, cb [4]float32
+	for y := reg.Min.Y; y < reg.Max.Y; y++ {
+		for x := reg.Min.X; x < reg.Max.X; x++ {
+			pi := a.PixOffset(x, y)
+			for c := 0; c < 4; c++ {
+				ca[c] = float32(a.Pix[pi+c]) / 255
+				if b != nil {
+					cb[c] = float32(b.Pix[pi+c]) / 255
+				}
+			}
+			cr := fun(ca, cb)
+			al := math32.Min(math32.Max(cr[3], 0), 1)
+			for c := 0; c < 3; c++ {
+				dst.Pix[pi+c] = uint8(math32.Min(math32.Max(cr[c], 0), al)*255 + 0.5)
+			}
+			dst.Pix[pi+3] = uint8(al*255 + 0.5)
+		}
+	}
+	return dst
+}
+
+// filterBlend blends a on top of b using given mode: normal, multiply,
+// screen, darken or lighten
+func filterBlend(a, b *image.RGBA, reg image.Rectangle, mode string) *image.RGBA {
+	return filterPixFunc(a, b, reg, func(ca, cb [4]float32) [4]float32 {
+		qa, qb := ca[3], cb[3]
+		var cr [4]float32
+		cr[3] = 1 - (1-qa)*(1-qb)
+		for c := 0; c < 3; c++ {
+			switch mode {
+			case "multiply":
+				cr[c] = (1-qa)*cb[c] + (1-qb)*ca[c] + ca[c]*cb[c]
+			case "screen":
+				cr[c] = cb[c] + ca[c] - ca[c]*cb[c]
+			case "darken":
+				cr[c] = math32.Min((1-qa)*cb[c]+ca[c], (1-qb)*ca[c]+cb[c])
+			case "lighten":
+				cr[c] = math32.Max((1-qa)*cb[c]+ca[c], (1-qb)*ca[c]+cb[c])
+			default: // normal
+				cr[c] = (1-qa)*cb[c] + ca[c]
+			}
+		}
+		return cr
+	})
+}
+
+// filterColorMatrix applies a color matrix of given type: matrix (20
+// values), saturate, hueRotate or luminanceToAlpha
+func filterColorMatrix(src *image.RGBA, reg image.Rectangle, typ string, vals []float32) *image.RGBA {
+	var mx [20]float32
+	mx[0], mx[6], mx[12], mx[18] = 1, 1, 1, 1 // identity
+	switch typ {
+	case "saturate":
+		s := float32(1)
+		if len(vals) > 0 {
+			s = vals[0]
+		}
+		copy(mx[:], []float32{
+			0.213 + 0.787*s, 0.715 - 0.715*s, 0.072 - 0.072*s, 0, 0,
+			0.213 - 0.213*s, 0.715 + 0.285*s, 0.072 - 0.072*s, 0, 0,
+			0.213 - 0.213*s, 0.715 - 0.715*s, 0.072 + 0.928*s, 0, 0,
+			0, 0, 0, 1, 0})
+	case "hueRotate":
+		var ang float32
+		if len(vals) > 0 {
+			ang = vals[0] * math32.Pi / 180
+		}
+		cs, sn := math32.Cos(ang), math32.Sin(ang)
+		copy(mx[:], []float32{
+			0.213 + cs*0.787 - sn*0.213, 0.715 - cs*0.715 - sn*0.715, 0.072 - cs*0.072 + sn*0.928, 0, 0,
+			0.213 - cs*0.213 + sn*0.143, 0.715 + cs*0.285 + sn*0.140, 0.072 - cs*0.072 - sn*0.283, 0, 0,
+			0.213 - cs*0.213 - sn*0.787, 0.715 - cs*0.715 + sn*0.715, 0.072 + cs*0.928 + sn*0.072, 0, 0,
+			0, 0, 0, 1, 0})
+	case "luminanceToAlpha":
+		copy(mx[:], []float32{
+			0, 0, 0, 0, 0,
+			0, 0, 0, 0, 0,
+			0, 0, 0, 0, 0,
+			0.2125, 0.7154, 0.0721, 0, 0})
+	default: // matrix
+		if len(vals) == 20 {
+			copy(mx[:], vals)
+		}
+	}
+	return filterPixFunc(src, nil, reg, func(ca, cb [4]float32) [4]float32 {
+		a := ca[3]
+		var uc [4]float32 // un-premultiplied
+		if a > 0 {
+			uc = [4]float32{ca[0] / a, ca[1] / a, ca[2] / a, a}
+		}
+		var cr [4]float32
+		for r := 0; r < 4; r++ {
+			ri := r * 5
+			cr[r] = mx[ri]*uc[0] + mx[ri+1]*uc[1] + mx[ri+2]*uc[2] + mx[ri+3]*uc[3] + mx[ri+4]
+		}
+		na := math32.Min(math32.Max(cr[3], 0), 1)
+		for c := 0; c < 3; c++ {
+			cr[c] = math32.Min(math32.Max(cr[c], 0), 1) * na
+		}
+		cr[3] = na
+		return cr
+	})
+}
+
+// filterComposite composites a with b using given Porter-Duff operator:
+// over, in, out, atop, xor, or arithmetic (using ks = k1..k4)
+func filterComposite(a, b *image.RGBA, reg image.Rectangle, op string, ks [4]float32) *image.RGBA {
+	return filterPixFunc(a, b, reg, func(ca, cb [4]float32) [4]float32 {
+		qa, qb := ca[3], cb[3]
+		var cr [4]float32
+		for c := 0; c < 4; c++ {
+			switch op {
+			case "in":
+				cr[c] = ca[c] * qb
+			case "out":
+				cr[c] = ca[c] * (1 - qb)
+			case "atop":
+				cr[c] = ca[c]*qb + cb[c]*(1-qa)
+			case "xor":
+				cr[c] = ca[c]*(1-qb) + cb[c]*(1-qa)
+			case "arithmetic":
+				cr[c] = ks[0]*ca[c]*cb[c] + ks[1]*ca[c] + ks[2]*cb[c] + ks[3]
+			default: // over
+				cr[c] = ca[c] + cb[c]*(1-qa)
+			}
+		}
+		return cr
+	})
+}
@@ -0,0 +1,237 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package svg
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/ki"
+)
+
+// testFilter returns a new filter element with given properties, with
+// primitives of given types, as children, each with the given properties
+func testFilter(props ki.Props, prims ...interface{}) *Filter {
+	fl := &Filter{FilterType: "filter"}
+	fl.InitName(fl, "flt")
+	for pk, pv := range props {
+		fl.SetProp(pk, pv)
+	}
+	for i := 0; i+1 < len(prims); i += 2 {
+		typ := prims[i].(string)
+		fp := fl.AddNewChild(KiT_Filter, typ).(*Filter)
+		fp.FilterType = typ
+		for pk, pv := range prims[i+1].(ki.Props) {
+			fp.SetProp(pk, pv)
+		}
+	}
+	return fl
+}
+
+// testPixImage returns a transparent image of given size, with given
+// (alpha-premultiplied) pixels set
+func testPixImage(w, h int, pix map[image.Point]color.RGBA) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for pt, c := range pix {
+		img.SetRGBA(pt.X, pt.Y, c)
+	}
+	return img
+}
+
+// testPixNear reports an error if the pixel at given point in img is not
+// within 1 of want in each channel
+func testPixNear(t *testing.T, desc string, img *image.RGBA, pt image.Point, want color.RGBA) {
+	t.Helper()
+	got := img.RGBAAt(pt.X, pt.Y)
+	near := func(a, b uint8) bool { return int(a)-int(b) <= 1 && int(b)-int(a) <= 1 }
+	if !near(got.R, want.R) || !near(got.G, want.G) || !near(got.B, want.B) || !near(got.A, want.A) {
+		t.Errorf("%v: pixel at %v: %v, want: %v", desc, pt, got, want)
+	}
+}
+
+func TestFilterRegion(t *testing.T) {
+	bbox := image.Rect(10, 20, 110, 70)
+	vpsz := gi.Vec2D{200, 100}
+	tests := []struct {
+		name  string
+		props ki.Props
+		xform gi.Matrix2D
+		want  image.Rectangle
+	}{
+		{"bbox defaults", ki.Props{}, gi.Identity2D(), image.Rect(0, 15, 120, 75)},
+		{"bbox fractions", ki.Props{"x": "0", "y": "0", "width": "1", "height": "1"}, gi.Identity2D(), bbox},
+		{"bbox percents", ki.Props{"x": "-50%", "width": "200%"}, gi.Identity2D(), image.Rect(-40, 15, 160, 75)},
+		{"user defaults", ki.Props{"filterUnits": "userSpaceOnUse"}, gi.Identity2D(), image.Rect(-20, -10, 220, 110)},
+		{"user defaults scaled", ki.Props{"filterUnits": "userSpaceOnUse"}, gi.Scale2D(2, 2), image.Rect(-40, -20, 440, 220)},
+		{"user values", ki.Props{"filterUnits": "userSpaceOnUse", "x": "5", "y": "5", "width": "50", "height": "25"},
+			gi.Identity2D(), image.Rect(5, 5, 55, 30)},
+		{"user percents", ki.Props{"filterUnits": "userSpaceOnUse", "x": "10%", "width": "50%"},
+			gi.Identity2D(), image.Rect(20, -10, 120, 110)},
+		{"user values translated", ki.Props{"filterUnits": "userSpaceOnUse", "x": "0", "y": "0", "width": "10", "height": "10"},
+			gi.Translate2D(30, 40), image.Rect(30, 40, 40, 50)},
+	}
+	for _, tst := range tests {
+		fl := testFilter(tst.props)
+		if got := fl.FilterRegion(bbox, tst.xform, vpsz); got != tst.want {
+			t.Errorf("%v: region: %v, want: %v", tst.name, got, tst.want)
+		}
+	}
+}
+
+func TestFilterOffset(t *testing.T) {
+	red := color.RGBA{255, 0, 0, 255}
+	src := testPixImage(6, 6, map[image.Point]color.RGBA{{1, 1}: red, {4, 4}: red})
+	dst := filterOffset(src, image.Rect(0, 0, 5, 5), 2, 1)
+	testPixNear(t, "moved", dst, image.Point{3, 2}, red)
+	testPixNear(t, "vacated", dst, image.Point{1, 1}, color.RGBA{})
+	// the pixel at 4,4 is moved out of the region
+	testPixNear(t, "outside region", dst, image.Point{5, 5}, color.RGBA{})
+}
+
+func TestFilterBlur(t *testing.T) {
+	white := color.RGBA{255, 255, 255, 255}
+	src := testPixImage(21, 21, map[image.Point]color.RGBA{{10, 10}: white, {0, 0}: white})
+	reg := image.Rect(2, 2, 19, 19)
+
+	same := filterBlur(src, reg, 0, 0)
+	testPixNear(t, "no blur", same, image.Point{10, 10}, white)
+	testPixNear(t, "no blur outside region", same, image.Point{0, 0}, color.RGBA{})
+
+	dst := filterBlur(src, reg, 2, 2)
+	if c := dst.RGBAAt(10, 10); c.A == 0 || c.A >= 128 {
+		t.Errorf("blur center alpha: %v, want some but less than half", c.A)
+	}
+	for _, pt := range []image.Point{{8, 10}, {12, 10}, {10, 8}, {10, 12}, {9, 9}} {
+		if c := dst.RGBAAt(pt.X, pt.Y); c.A == 0 || c.R != c.A {
+			t.Errorf("blur near center at %v: %v, want some white", pt, c)
+		}
+	}
+	for _, pt := range []image.Point{{10, 3}, {17, 10}, {3, 3}} {
+		if c := dst.RGBAAt(pt.X, pt.Y); c.A != 0 {
+			t.Errorf("blur far from center at %v: %v, want none", pt, c)
+		}
+	}
+	// total alpha is about the same, within rounding of each pass
+	sum := 0
+	for y := reg.Min.Y; y < reg.Max.Y; y++ {
+		for x := reg.Min.X; x < reg.Max.X; x++ {
+			sum += int(dst.RGBAAt(x, y).A)
+		}
+	}
+	if sum < 220 || sum > 290 {
+		t.Errorf("blur total alpha: %v, want about 255", sum)
+	}
+
+	horiz := filterBlur(src, reg, 2, 0)
+	if horiz.RGBAAt(12, 10).A == 0 || horiz.RGBAAt(10, 11).A != 0 {
+		t.Errorf("horizontal blur: %v at 12,10 and %v at 10,11", horiz.RGBAAt(12, 10), horiz.RGBAAt(10, 11))
+	}
+}
+
+func TestFilterComposite(t *testing.T) {
+	reg := image.Rect(0, 0, 1, 1)
+	a := testPixImage(1, 1, map[image.Point]color.RGBA{{0, 0}: {255, 0, 0, 255}})
+	b := testPixImage(1, 1, map[image.Point]color.RGBA{{0, 0}: {0, 0, 128, 128}})
+	tests := []struct {
+		op   string
+		ks   [4]float32
+		want color.RGBA
+	}{
+		{"over", [4]float32{}, color.RGBA{255, 0, 0, 255}},
+		{"", [4]float32{}, color.RGBA{255, 0, 0, 255}},
+		{"in", [4]float32{}, color.RGBA{128, 0, 0, 128}},
+		{"out", [4]float32{}, color.RGBA{127, 0, 0, 127}},
+		{"atop", [4]float32{}, color.RGBA{128, 0, 0, 128}},
+		{"xor", [4]float32{}, color.RGBA{127, 0, 0, 127}},
+		{"arithmetic", [4]float32{0, 0.5, 0.5, 0}, color.RGBA{128, 0, 64, 192}},
+		{"arithmetic", [4]float32{0, 1, 1, 0}, color.RGBA{255, 0, 128, 255}},
+	}
+	for _, tst := range tests {
+		dst := filterComposite(a, b, reg, tst.op, tst.ks)
+		testPixNear(t, "composite "+tst.op, dst, image.Point{}, tst.want)
+	}
+	// b over a shows a through the half transparent b
+	testPixNear(t, "composite b over a", filterComposite(b, a, reg, "over", [4]float32{}), image.Point{}, color.RGBA{127, 0, 128, 255})
+}
+
+func TestFilterColorMatrix(t *testing.T) {
+	reg := image.Rect(0, 0, 2, 1)
+	src := testPixImage(2, 1, map[image.Point]color.RGBA{{0, 0}: {255, 0, 0, 255}, {1, 0}: {128, 0, 0, 128}})
+	tests := []struct {
+		typ  string
+		vals []float32
+		want [2]color.RGBA
+	}{
+		{"saturate", []float32{1}, [2]color.RGBA{{255, 0, 0, 255}, {128, 0, 0, 128}}},
+		{"saturate", []float32{0}, [2]color.RGBA{{54, 54, 54, 255}, {27, 27, 27, 128}}},
+		{"hueRotate", []float32{0}, [2]color.RGBA{{255, 0, 0, 255}, {128, 0, 0, 128}}},
+		{"luminanceToAlpha", nil, [2]color.RGBA{{0, 0, 0, 54}, {0, 0, 0, 54}}},
+		{"matrix", []float32{0, 1, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 1, 0, 0, 0, 0, 0, 1, 0},
+			[2]color.RGBA{{0, 255, 0, 255}, {0, 128, 0, 128}}},
+		{"matrix", []float32{1, 0, 0, 0, 0, 0, 1, 0, 0, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0.5, 0},
+			[2]color.RGBA{{128, 0, 0, 128}, {64, 0, 0, 64}}},
+		{"matrix", []float32{1, 2, 3}, [2]color.RGBA{{255, 0, 0, 255}, {128, 0, 0, 128}}},
+	}
+	for _, tst := range tests {
+		dst := filterColorMatrix(src, reg, tst.typ, tst.vals)
+		testPixNear(t, "colormatrix "+tst.typ, dst, image.Point{0, 0}, tst.want[0])
+		testPixNear(t, "colormatrix "+tst.typ, dst, image.Point{1, 0}, tst.want[1])
+	}
+}
+
+func TestApplyFilter(t *testing.T) {
+	// a node with a 10x10 bounding box at 10,10, so the default filter
+	// region is 9,9 .. 21,21
+	bbox := image.Rect(10, 10, 20, 20)
+	red := color.RGBA{255, 0, 0, 255}
+	src := testPixImage(30, 30, map[image.Point]color.RGBA{{12, 12}: red, {2, 2}: red})
+
+	flood := testFilter(nil, "feFlood", ki.Props{"flood-color": "#00f", "flood-opacity": "0.5"})
+	dst := flood.ApplyFilter(src, bbox, gi.Identity2D(), gi.Vec2D{30, 30})
+	half := color.RGBA{0, 0, 128, 128}
+	testPixNear(t, "flood inside", dst, image.Point{12, 12}, half)
+	testPixNear(t, "flood region min", dst, image.Point{9, 9}, half)
+	testPixNear(t, "flood region max", dst, image.Point{20, 20}, half)
+	testPixNear(t, "flood outside", dst, image.Point{21, 21}, color.RGBA{})
+	testPixNear(t, "flood outside source", dst, image.Point{2, 2}, color.RGBA{})
+
+	// a drop shadow: the offset alpha, merged under the source graphic
+	shadow := testFilter(nil,
+		"feOffset", ki.Props{"in": "SourceAlpha", "dx": "3", "dy": "2", "result": "off"},
+		"feFlood", ki.Props{"flood-color": "#fff"},
+		"feMerge", ki.Props{})
+	mrg := shadow.KnownChild(2).(*Filter)
+	for _, in := range []string{"off", "SourceGraphic"} {
+		mn := mrg.AddNewChild(KiT_Filter, "feMergeNode").(*Filter)
+		mn.FilterType = "feMergeNode"
+		mn.SetProp("in", in)
+	}
+	dst = shadow.ApplyFilter(src, bbox, gi.Identity2D(), gi.Vec2D{30, 30})
+	testPixNear(t, "merge source", dst, image.Point{12, 12}, red)
+	testPixNear(t, "merge shadow", dst, image.Point{15, 14}, color.RGBA{0, 0, 0, 255})
+	testPixNear(t, "merge empty", dst, image.Point{13, 13}, color.RGBA{})
+	testPixNear(t, "merge outside", dst, image.Point{2, 2}, color.RGBA{})
+
+	// the offset is in user space
+	dst = shadow.ApplyFilter(src, bbox, gi.Scale2D(2, 2), gi.Vec2D{30, 30})
+	testPixNear(t, "merge shadow scaled", dst, image.Point{18, 16}, color.RGBA{0, 0, 0, 255})
+
+	// a blur of the source, composited in the source, within a user space
+	// region with default size, which includes the pixel at 2,2
+	blur := testFilter(ki.Props{"filterUnits": "userSpaceOnUse"},
+		"feGaussianBlur", ki.Props{"stdDeviation": "1"},
+		"feComposite", ki.Props{"in2": "SourceGraphic", "operator": "in"})
+	dst = blur.ApplyFilter(src, bbox, gi.Identity2D(), gi.Vec2D{30, 30})
+	for _, pt := range []image.Point{{12, 12}, {2, 2}} {
+		if c := dst.RGBAAt(pt.X, pt.Y); c.A == 0 || c.A == 255 || c.G != 0 {
+			t.Errorf("blur composite at %v: %v, want some red", pt, c)
+		}
+	}
+	if c := dst.RGBAAt(13, 12); c.A != 0 {
+		t.Errorf("blur composite next to source: %v, want none", c)
+	}
+}
@@ -0,0 +1,115 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package svg
+
+import (
+	"image"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/ki"
+)
+
+// PickTol is the distance in pixels beyond the edges of the stroke of an
+// element within which a point is still on the element, when picking
+// elements, e.g., with the mouse
+var PickTol = float32(2)
+
+// Shape is implemented by the svg elements that draw a single path: Path,
+// Rect, Circle, Ellipse, Line, Polygon and Polyline -- ShapeData returns
+// that path, in the user coordinates of the element, which is used to test
+// points exactly against its fill and stroke
+type Shape interface {
+	gi.Node2D
+
+	// AsSVGNode returns the svg NodeBase of the element
+	AsSVGNode() *NodeBase
+
+	// ShapeData returns the path drawn by the element
+	ShapeData() []PathData
+}
+
+// ShapeGeom returns the geometry of given shape, in svg image pixels, as of
+// its last render
+func ShapeGeom(sh Shape) *PathGeom {
+	return NewPathGeom(sh.ShapeData(), sh.AsSVGNode().RenderXForm)
+}
+
+// ShapeContainsPoint returns true if given point, in svg image pixels, is on
+// given shape as it was last rendered: inside its fill, if it is filled, or
+// within half its stroke width plus tol of its outline, if it is stroked
+func ShapeContainsPoint(sh Shape, pt gi.Vec2D, tol float32) bool {
+	g := sh.AsSVGNode()
+	pc := &g.Pnt
+	if pc.Off {
+		return false
+	}
+	pg := ShapeGeom(sh)
+	if pc.FillStyle.On && pg.Contains(pt, pc.FillStyle.Rule) {
+		return true
+	}
+	if pc.StrokeStyle.On {
+		rs := &gi.RenderState{}
+		rs.XForm = g.RenderXForm
+		return pg.NearStroke(pt, 0.5*pc.StrokeWidth(rs)+tol)
+	}
+	return false
+}
+
+// ElementContainsPoint returns true if given point, in svg image pixels, is
+// on given element as it was last rendered -- shapes are tested exactly (see
+// ShapeContainsPoint), groups contain the points that any of their children
+// contain, and other elements, e.g., text and images, contain the points
+// within their bounding box
+func ElementContainsPoint(nii gi.Node2D, pt gi.Vec2D, tol float32) bool {
+	if _, ok := nii.(*Group); ok {
+		for _, kid := range *nii.Children() {
+			if kii, _ := gi.KiToNode2D(kid); kii != nil && ElementContainsPoint(kii, pt, tol) {
+				return true
+			}
+		}
+		return false
+	}
+	bb := nii.AsNode2D().BBox
+	if bb.Empty() {
+		return false
+	}
+	if pt.X < float32(bb.Min.X)-tol || pt.X > float32(bb.Max.X)+tol || pt.Y < float32(bb.Min.Y)-tol || pt.Y > float32(bb.Max.Y)+tol {
+		return false
+	}
+	if sh, ok := nii.(Shape); ok {
+		return ShapeContainsPoint(sh, pt, tol)
+	}
+	return true
+}
+
+// ImagePos returns the position in svg image pixels of given window position
+func (svg *SVG) ImagePos(pt image.Point) gi.Vec2D {
+	return gi.NewVec2DFmPoint(pt.Sub(svg.WinBBox.Min))
+}
+
+// ElementAt returns the topmost element that contains given point in svg
+// image pixels, within PickTol (see ElementContainsPoint) -- if leaf is
+// true, it is the innermost such element within any groups, and otherwise
+// it is a top-level element
+func (svg *SVG) ElementAt(pt gi.Vec2D, leaf bool) gi.Node2D {
+	return elementAt(svg.This(), pt, leaf)
+}
+
+func elementAt(par ki.Ki, pt gi.Vec2D, leaf bool) gi.Node2D {
+	kids := *par.Children()
+	for i := len(kids) - 1; i >= 0; i-- {
+		nii, _ := gi.KiToNode2D(kids[i])
+		if nii == nil || !ElementContainsPoint(nii, pt, PickTol) {
+			continue
+		}
+		if _, isShape := nii.(Shape); leaf && !isShape && nii.HasChildren() {
+			if el := elementAt(nii, pt, leaf); el != nil {
+				return el
+			}
+		}
+		return nii
+	}
+	return nil
+}
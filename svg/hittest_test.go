@@ -0,0 +1,65 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package svg
+
+import (
+	"testing"
+
+	"github.com/goki/gi/gi"
+)
+
+func TestShapeContainsPoint(t *testing.T) {
+	// a rect at 10,10 of size 20x10, rendered at twice its size, so it covers
+	// 20..60, 20..40 in image pixels, with a 4 pixel wide stroke
+	rect := &Rect{Pos: gi.Vec2D{10, 10}, Size: gi.Vec2D{20, 10}}
+	rect.Pnt.Defaults()
+	rect.Pnt.StrokeStyle.Width.Dots = 2
+	rect.RenderXForm = gi.Scale2D(2, 2)
+	// a path of two overlapping squares in the same direction, whose overlap
+	// is only filled under the nonzero fill rule
+	path := &Path{Data: append(testRect(0, 0, 10, 10), testRect(5, 5, 10, 10)...)}
+	path.Pnt.Defaults()
+	path.RenderXForm = gi.Identity2D()
+
+	tests := []struct {
+		name   string
+		sh     Shape
+		fill   bool
+		stroke bool
+		rule   gi.FillRule
+		pt     gi.Vec2D
+		want   bool
+	}{
+		{"rect fill inside", rect, true, false, gi.FillRuleNonZero, gi.Vec2D{40, 30}, true},
+		{"rect fill user coords", rect, true, false, gi.FillRuleNonZero, gi.Vec2D{15, 15}, false},
+		{"rect fill outside", rect, true, false, gi.FillRuleNonZero, gi.Vec2D{61, 30}, false},
+		{"rect stroke inside", rect, false, true, gi.FillRuleNonZero, gi.Vec2D{40, 30}, false},
+		{"rect stroke edge", rect, false, true, gi.FillRuleNonZero, gi.Vec2D{40, 21.5}, true},
+		{"rect stroke outside edge", rect, false, true, gi.FillRuleNonZero, gi.Vec2D{40, 18.5}, true},
+		{"rect stroke tol", rect, false, true, gi.FillRuleNonZero, gi.Vec2D{62.5, 30}, true},
+		{"rect stroke beyond tol", rect, false, true, gi.FillRuleNonZero, gi.Vec2D{63.5, 30}, false},
+		{"rect both inside", rect, true, true, gi.FillRuleNonZero, gi.Vec2D{40, 30}, true},
+		{"rect neither", rect, false, false, gi.FillRuleNonZero, gi.Vec2D{40, 20}, false},
+		{"path overlap nonzero", path, true, false, gi.FillRuleNonZero, gi.Vec2D{7, 7}, true},
+		{"path overlap evenodd", path, true, false, gi.FillRuleEvenOdd, gi.Vec2D{7, 7}, false},
+		{"path single evenodd", path, true, false, gi.FillRuleEvenOdd, gi.Vec2D{2, 2}, true},
+		{"path overlap evenodd stroke", path, true, true, gi.FillRuleEvenOdd, gi.Vec2D{7, 7}, false},
+		{"path edge evenodd stroke", path, true, true, gi.FillRuleEvenOdd, gi.Vec2D{7, 5.5}, true},
+	}
+	for _, tst := range tests {
+		pc := &tst.sh.AsSVGNode().Pnt
+		pc.FillStyle.On = tst.fill
+		pc.FillStyle.Rule = tst.rule
+		pc.StrokeStyle.On = tst.stroke
+		if got := ShapeContainsPoint(tst.sh, tst.pt, 1); got != tst.want {
+			t.Errorf("%v: contains %v = %v, want: %v", tst.name, tst.pt, got, tst.want)
+		}
+	}
+	rect.Pnt.Off = true
+	rect.Pnt.FillStyle.On = true
+	if ShapeContainsPoint(rect, gi.Vec2D{40, 30}, 1) {
+		t.Errorf("rect that is off contains a point")
+	}
+}
@@ -0,0 +1,188 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package svg
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	"image/png"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/ki/kit"
+)
+
+// Image is an SVG image (bitmap) -- the href can be a file path (relative
+// to the file of the parent SVG), or a data: url with base64-encoded image
+// data -- PNG, JPEG and GIF formats are supported
+type Image struct {
+	NodeBase
+	Pos                 gi.Vec2D                   `xml:"{x,y}" desc:"position of the top-left of the image"`
+	Size                gi.Vec2D                   `xml:"{width,height}" desc:"size of the image to render -- if zero, the size of the image itself is used"`
+	Href                string                     `xml:"href" desc:"link to the image data -- file path or data: url"`
+	PreserveAspectRatio ViewBoxPreserveAspectRatio `xml:"preserveAspectRatio" desc:"how to scale the image within Size"`
+	Pixels              image.Image                `json:"-" xml:"-" desc:"the image, as loaded from Href"`
+	LoadErr             error                      `json:"-" xml:"-" view:"-" desc:"the error from loading the image from Href, if it failed -- it is not loaded again when rendering until Href changes"`
+	errHref             string                     // href that LoadErr is for
+}
+
+var KiT_Image = kit.Types.AddType(&Image{}, nil)
+
+// SetHref sets the href and loads the image from it -- see OpenImage -- any
+// error is logged and recorded in LoadErr
+func (g *Image) SetHref(href string) error {
+	g.Href = href
+	img, err := g.OpenImage(href)
+	if err != nil {
+		log.Println(err)
+		g.Pixels = nil
+		g.LoadErr = err
+		g.errHref = href
+		return err
+	}
+	g.Pixels = img
+	g.LoadErr = nil
+	g.errHref = ""
+	return nil
+}
+
+// OpenImage opens the image from given href, which is either a data: url
+// or a file path, which if relative is relative to the directory of the
+// file of the parent SVG
+func (g *Image) OpenImage(href string) (image.Image, error) {
+	href = strings.TrimSpace(href)
+	if strings.HasPrefix(href, "data:") {
+		return decodeDataURL(href)
+	}
+	fpath := href
+	if strings.HasPrefix(fpath, "file://") {
+		fpath = strings.TrimPrefix(fpath, "file://")
+	}
+	if fp, err := url.PathUnescape(fpath); err == nil {
+		fpath = fp
+	}
+	if !filepath.IsAbs(fpath) {
+		if svk := g.ParentByType(KiT_SVG, true); svk != nil {
+			if sv := svk.Embed(KiT_SVG).(*SVG); sv.Filename != "" {
+				fpath = filepath.Join(filepath.Dir(sv.Filename), fpath)
+			}
+		}
+	}
+	f, err := os.Open(fpath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("svg.Image: could not decode image: %v: %v", fpath, err)
+	}
+	return img, nil
+}
+
+// decodeDataURL decodes an image from a data: url, which must use base64
+// encoding
+func decodeDataURL(href string) (image.Image, error) {
+	ci := strings.IndexByte(href, ',')
+	if ci < 0 {
+		return nil, fmt.Errorf("svg.Image: data url has no data")
+	}
+	hdr := href[:ci]
+	if !strings.HasSuffix(hdr, ";base64") {
+		return nil, fmt.Errorf("svg.Image: only base64 data urls are supported: %v", hdr)
+	}
+	data := strings.Map(func(r rune) rune {
+		if r == ' ' || r == '\n' || r == '\r' || r == '\t' {
+			return -1
+		}
+		return r
+	}, href[ci+1:])
+	b, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return nil, fmt.Errorf("svg.Image: could not decode base64 data: %v", err)
+	}
+	img, _, err := image.Decode(bytes.NewReader(b))
+	if err != nil {
+		return nil, fmt.Errorf("svg.Image: could not decode %v image: %v", hdr, err)
+	}
+	return img, nil
+}
+
+// ImageDataURL returns a data: url with the given image encoded as base64 PNG
+// data, as used for writing images that were not loaded from an href
+func ImageDataURL(img image.Image) string {
+	var b bytes.Buffer
+	if err := png.Encode(&b, img); err != nil {
+		log.Println(err)
+		return ""
+	}
+	return "data:image/png;base64," + base64.StdEncoding.EncodeToString(b.Bytes())
+}
+
+// RenderSize returns the size the image renders at -- Size, or the size of
+// the image itself if that is not set
+func (g *Image) RenderSize() gi.Vec2D {
+	sz := g.Size
+	if g.Pixels != nil {
+		isz := g.Pixels.Bounds().Size()
+		if sz.X == 0 {
+			sz.X = float32(isz.X)
+		}
+		if sz.Y == 0 {
+			sz.Y = float32(isz.Y)
+		}
+	}
+	return sz
+}
+
+func (g *Image) BBox2D() image.Rectangle {
+	rs := &g.Viewport.Render
+	sz := g.RenderSize()
+	return g.Pnt.BoundingBox(rs, g.Pos.X, g.Pos.Y, g.Pos.X+sz.X, g.Pos.Y+sz.Y)
+}
+
+func (g *Image) Render2D() {
+	if g.Viewport == nil {
+		g.This().(gi.Node2D).Init2D()
+	}
+	if g.Pixels == nil && g.Href != "" && (g.LoadErr == nil || g.errHref != g.Href) {
+		g.SetHref(g.Href)
+	}
+	pc := &g.Pnt
+	rs := &g.Viewport.Render
+	rs.PushXForm(pc.XForm)
+	if g.Pixels != nil {
+		isz := g.Pixels.Bounds().Size()
+		vb := ViewBox{Size: gi.Vec2D{float32(isz.X), float32(isz.Y)}, PreserveAspectRatio: g.PreserveAspectRatio}
+		sz := g.RenderSize()
+		xf := vb.Transform(g.Pos, sz)
+		img := g.Pixels
+		imin := img.Bounds().Min
+		if g.PreserveAspectRatio.MeetOrSlice == Slice && xf.XX != 0 && xf.YY != 0 {
+			// only the part of the image within Pos, Size is visible
+			vis := image.Rect(int((g.Pos.X-xf.X0)/xf.XX), int((g.Pos.Y-xf.Y0)/xf.YY),
+				int((g.Pos.X+sz.X-xf.X0)/xf.XX+0.5), int((g.Pos.Y+sz.Y-xf.Y0)/xf.YY+0.5))
+			if si, ok := img.(interface {
+				SubImage(r image.Rectangle) image.Image
+			}); ok {
+				img = si.SubImage(vis.Add(imin))
+			}
+		}
+		rs.PushXForm(xf.Translate(float32(-imin.X), float32(-imin.Y)))
+		pc.DrawImage(rs, img, 0, 0)
+		rs.PopXForm()
+		g.ComputeBBoxSVG()
+	}
+	g.Render2DChildren()
+	rs.PopXForm()
+}
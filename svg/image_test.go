@@ -0,0 +1,40 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package svg
+
+import (
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestImageSetHref(t *testing.T) {
+	g := &Image{}
+	bad := filepath.Join(os.TempDir(), "svg-no-such-image.png")
+	if err := g.SetHref(bad); err == nil || g.LoadErr != err || g.Pixels != nil {
+		t.Errorf("SetHref of a missing file: err: %v LoadErr: %v", err, g.LoadErr)
+	}
+	if g.errHref != bad {
+		t.Errorf("LoadErr is for href: %q, want: %q", g.errHref, bad)
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, 3, 2))
+	img.SetRGBA(1, 1, color.RGBA{255, 0, 0, 255})
+	if err := g.SetHref(ImageDataURL(img)); err != nil || g.LoadErr != nil {
+		t.Errorf("SetHref of a data url: err: %v LoadErr: %v", err, g.LoadErr)
+	}
+	if g.Pixels == nil || g.Pixels.Bounds().Size() != (image.Point{3, 2}) {
+		t.Fatalf("SetHref of a data url did not load the image")
+	}
+	if r, _, _, _ := g.Pixels.At(1, 1).RGBA(); r != 0xffff {
+		t.Errorf("data url image pixel: %v", g.Pixels.At(1, 1))
+	}
+
+	if err := g.SetHref("data:image/png,abc"); err == nil || g.LoadErr == nil || g.Pixels != nil {
+		t.Errorf("SetHref of a data url without base64 did not fail")
+	}
+}
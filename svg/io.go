@@ -11,16 +11,21 @@
 package svg
 
 import (
+	"bytes"
 	"encoding/xml"
 	"errors"
 	"fmt"
 	"io"
 	"log"
 	"os"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/goki/gi/gi"
 	"github.com/goki/gi/units"
+	"github.com/goki/ki"
+	"github.com/goki/ki/kit"
 	"golang.org/x/net/html/charset"
 )
 
@@ -54,6 +59,7 @@ func (svg *SVG) OpenXML(filename string) error {
 		log.Println(err)
 		return err
 	}
+	svg.Filename = filename
 	return svg.ReadXML(fp)
 }
 
@@ -101,11 +107,10 @@ func (svg *SVG) UnmarshalXML(decoder *xml.Decoder, se xml.StartElement) error {
 	inDef := false
 	inCSS := false
 	var curCSS *gi.StyleSheet
-	inTxt := false
-	var curTxt *Text
-	inTspn := false
-	var curTspn *Text
+	var txtStack []*Text     // current text, tspan and textPath elements
 	var defPrevPar gi.Node2D // previous parent before a def encountered
+	var animPar gi.Node2D    // open shape element that animation elements are added to
+	var curMotion *AnimateMotion
 
 	for {
 		var t xml.Token
@@ -126,6 +131,7 @@ func (svg *SVG) UnmarshalXML(decoder *xml.Decoder, se xml.StartElement) error {
 		switch se := t.(type) {
 		case xml.StartElement:
 			nm := se.Name.Local
+			nkids := len(curPar.AsNode2D().Kids)
 			switch {
 			case nm == "svg":
 				if curPar != svg.This() {
@@ -359,21 +365,23 @@ func (svg *SVG) UnmarshalXML(decoder *xml.Decoder, se xml.StartElement) error {
 				}
 			case nm == "tspan":
 				fallthrough
+			case nm == "textPath":
+				fallthrough
 			case nm == "text":
 				var txt *Text
-				if se.Name.Local == "text" {
+				switch {
+				case se.Name.Local == "text":
 					txt = curPar.AddNewChild(KiT_Text, "txt").(*Text)
-					inTxt = true
-					curTxt = txt
-				} else {
-					if inTxt && curTxt != nil {
-						txt = curTxt.AddNewChild(KiT_Text, "tspan").(*Text)
-						txt.Pos = curTxt.Pos
-					} else {
-						txt = curPar.AddNewChild(KiT_Text, "tspan").(*Text)
-					}
-					inTspn = true
-					curTspn = txt
+					txtStack = []*Text{txt}
+				case len(txtStack) > 0:
+					txt = txtStack[len(txtStack)-1].AddNewChild(KiT_Text, nm).(*Text)
+					txtStack = append(txtStack, txt)
+				default:
+					txt = curPar.AddNewChild(KiT_Text, nm).(*Text)
+					txtStack = []*Text{txt}
+				}
+				if nm == "textPath" && txt.TextPath == "" {
+					txt.TextPath = "#" // marks as textPath even if href is missing
 				}
 				for _, attr := range se.Attr {
 					if txt.SetStdXMLAttr(attr.Name.Local, attr.Value) {
@@ -381,19 +389,28 @@ func (svg *SVG) UnmarshalXML(decoder *xml.Decoder, se xml.StartElement) error {
 					}
 					switch attr.Name.Local {
 					case "x":
+						// tspans always use CharPosX, so that it is clear if they are positioned
 						pts := gi.ReadPoints(attr.Value)
-						if len(pts) > 1 {
+						if len(pts) > 1 || (len(pts) == 1 && !txt.IsRoot()) {
 							txt.CharPosX = pts
 						} else if len(pts) == 1 {
 							txt.Pos.X = pts[0]
 						}
 					case "y":
 						pts := gi.ReadPoints(attr.Value)
-						if len(pts) > 1 {
+						if len(pts) > 1 || (len(pts) == 1 && !txt.IsRoot()) {
 							txt.CharPosY = pts
 						} else if len(pts) == 1 {
 							txt.Pos.Y = pts[0]
 						}
+					case "href":
+						if nm == "textPath" {
+							txt.TextPath = attr.Value
+						} else {
+							txt.SetProp(attr.Name.Local, attr.Value)
+						}
+					case "startOffset":
+						txt.StartOffset.SetString(attr.Value)
 					case "dx":
 						pts := gi.ReadPoints(attr.Value)
 						if len(pts) > 0 {
@@ -410,10 +427,7 @@ func (svg *SVG) UnmarshalXML(decoder *xml.Decoder, se xml.StartElement) error {
 							txt.CharRots = pts
 						}
 					case "textLength":
-						tl, err := gi.ParseFloat32(attr.Value)
-						if err != nil {
-							txt.TextLength = tl
-						}
+						txt.TextLength, err = gi.ParseFloat32(attr.Value)
 					case "lengthAdjust":
 						if attr.Value == "spacingAndGlyphs" {
 							txt.AdjustGlyphs = true
@@ -499,6 +513,124 @@ func (svg *SVG) UnmarshalXML(decoder *xml.Decoder, se xml.StartElement) error {
 						cp.SetProp(attr.Name.Local, attr.Value)
 					}
 				}
+			case nm == "mask":
+				curPar = curPar.AddNewChild(KiT_Mask, "mask").(gi.Node2D)
+				mk := curPar.(*Mask)
+				for _, attr := range se.Attr {
+					if mk.SetStdXMLAttr(attr.Name.Local, attr.Value) {
+						continue
+					}
+					switch attr.Name.Local {
+					default:
+						mk.SetProp(attr.Name.Local, attr.Value)
+					}
+				}
+			case nm == "image":
+				img := curPar.AddNewChild(KiT_Image, "image").(*Image)
+				var x, y, w, h float32
+				href := ""
+				for _, attr := range se.Attr {
+					if img.SetStdXMLAttr(attr.Name.Local, attr.Value) {
+						continue
+					}
+					switch attr.Name.Local {
+					case "x":
+						x, err = gi.ParseFloat32(attr.Value)
+					case "y":
+						y, err = gi.ParseFloat32(attr.Value)
+					case "width":
+						w, err = gi.ParseFloat32(attr.Value)
+					case "height":
+						h, err = gi.ParseFloat32(attr.Value)
+					case "href":
+						href = attr.Value
+					case "preserveAspectRatio":
+						img.PreserveAspectRatio.SetString(attr.Value)
+					default:
+						img.SetProp(attr.Name.Local, attr.Value)
+					}
+					if err != nil {
+						return err
+					}
+				}
+				img.Pos.Set(x, y)
+				img.Size.Set(w, h)
+				if href != "" {
+					img.SetHref(href) // errors are logged, and image is just not rendered
+				}
+			case nm == "symbol":
+				curPar = curPar.AddNewChild(KiT_Symbol, "symbol").(gi.Node2D)
+				sym := curPar.(*Symbol)
+				for _, attr := range se.Attr {
+					if sym.SetStdXMLAttr(attr.Name.Local, attr.Value) {
+						continue
+					}
+					switch attr.Name.Local {
+					case "viewBox":
+						pts := gi.ReadPoints(attr.Value)
+						if len(pts) != 4 {
+							return paramMismatchError
+						}
+						sym.ViewBox.Min.Set(pts[0], pts[1])
+						sym.ViewBox.Size.Set(pts[2], pts[3])
+					case "preserveAspectRatio":
+						sym.ViewBox.PreserveAspectRatio.SetString(attr.Value)
+					default:
+						sym.SetProp(attr.Name.Local, attr.Value)
+					}
+				}
+			case nm == "pattern":
+				curPar = curPar.AddNewChild(KiT_Pattern, "pattern").(gi.Node2D)
+				pat := curPar.(*Pattern)
+				var x, y, w, h float32
+				for _, attr := range se.Attr {
+					if pat.SetStdXMLAttr(attr.Name.Local, attr.Value) {
+						continue
+					}
+					switch attr.Name.Local {
+					case "x":
+						x, err = gi.ParseFloat32(attr.Value)
+					case "y":
+						y, err = gi.ParseFloat32(attr.Value)
+					case "width":
+						w, err = gi.ParseFloat32(attr.Value)
+					case "height":
+						h, err = gi.ParseFloat32(attr.Value)
+					case "patternUnits":
+						pat.Units = attr.Value
+					case "patternContentUnits":
+						pat.ContentUnits = attr.Value
+					case "patternTransform":
+						pat.SetProp("transform", attr.Value)
+					case "viewBox":
+						pts := gi.ReadPoints(attr.Value)
+						if len(pts) != 4 {
+							return paramMismatchError
+						}
+						pat.ViewBox.Min.Set(pts[0], pts[1])
+						pat.ViewBox.Size.Set(pts[2], pts[3])
+					case "preserveAspectRatio":
+						pat.ViewBox.PreserveAspectRatio.SetString(attr.Value)
+					default:
+						pat.SetProp(attr.Name.Local, attr.Value)
+					}
+					if err != nil {
+						return err
+					}
+				}
+				pat.Pos.Set(x, y)
+				pat.Size.Set(w, h)
+			case nm == "switch":
+				curPar = curPar.AddNewChild(KiT_Switch, "switch").(gi.Node2D)
+				for _, attr := range se.Attr {
+					if curPar.AsNode2D().SetStdXMLAttr(attr.Name.Local, attr.Value) {
+						continue
+					}
+					switch attr.Name.Local {
+					default:
+						curPar.SetProp(attr.Name.Local, attr.Value)
+					}
+				}
 			case nm == "marker":
 				curPar = curPar.AddNewChild(KiT_Marker, "marker").(gi.Node2D)
 				mrk := curPar.(*Marker)
@@ -518,7 +650,7 @@ func (svg *SVG) UnmarshalXML(decoder *xml.Decoder, se xml.StartElement) error {
 						szx, err = gi.ParseFloat32(attr.Value)
 					case "markerHeight":
 						szy, err = gi.ParseFloat32(attr.Value)
-					case "matrixUnits":
+					case "markerUnits", "matrixUnits":
 						if attr.Value == "strokeWidth" {
 							mrk.Units = StrokeWidth
 						} else {
@@ -547,21 +679,79 @@ func (svg *SVG) UnmarshalXML(decoder *xml.Decoder, se xml.StartElement) error {
 			case nm == "use":
 				link := gi.XMLAttr("href", se.Attr)
 				itm := curPar.FindNamedElement(link)
-				if itm != nil {
-					cln := itm.Clone().(gi.Node2D)
-					if cln != nil {
-						curPar.AddChild(cln)
-						for _, attr := range se.Attr {
-							if cln.AsNode2D().SetStdXMLAttr(attr.Name.Local, attr.Value) {
-								continue
-							}
-							switch attr.Name.Local {
-							default:
-								cln.SetProp(attr.Name.Local, attr.Value)
-							}
-						}
+				if itm == nil {
+					break
+				}
+				var x, y, w, h float32
+				xform := ""
+				var attrs []xml.Attr
+				for _, attr := range se.Attr {
+					switch attr.Name.Local {
+					case "x":
+						x, err = gi.ParseFloat32(attr.Value)
+					case "y":
+						y, err = gi.ParseFloat32(attr.Value)
+					case "width":
+						w, err = gi.ParseFloat32(attr.Value)
+					case "height":
+						h, err = gi.ParseFloat32(attr.Value)
+					case "transform":
+						xform = attr.Value
+					case "href":
+					default:
+						attrs = append(attrs, attr)
+					}
+					if err != nil {
+						return err
+					}
+				}
+				var cln gi.Node2D
+				if sym, ok := itm.(*Symbol); ok {
+					// symbol is instantiated as a group of its children, with its
+					// viewbox mapped into the region of the use element
+					grp := curPar.AddNewChild(KiT_Group, sym.Name()).(*Group)
+					for pk, pv := range sym.Props {
+						grp.SetProp(pk, pv)
+					}
+					for _, sk := range sym.Kids {
+						grp.AddChild(sk.Clone())
+					}
+					sz := gi.Vec2D{w, h}
+					if sz.X == 0 {
+						sz.X = sym.ViewBox.Size.X
+					}
+					if sz.Y == 0 {
+						sz.Y = sym.ViewBox.Size.Y
+					}
+					vxf := sym.ViewBox.Transform(gi.Vec2D{x, y}, sz)
+					xform += fmt.Sprintf(" matrix(%v,%v,%v,%v,%v,%v)", vxf.XX, vxf.YX, vxf.XY, vxf.YY, vxf.X0, vxf.Y0)
+					cln = grp
+				} else {
+					cln = itm.Clone().(gi.Node2D)
+					curPar.AddChild(cln)
+					if x != 0 || y != 0 {
+						xform += fmt.Sprintf(" translate(%v,%v)", x, y)
+					}
+					if otf, ok := cln.Properties()["transform"].(string); ok {
+						xform += " " + otf
+					}
+				}
+				if xform = strings.TrimSpace(xform); xform != "" {
+					cln.SetProp("transform", xform)
+				}
+				for _, attr := range attrs {
+					if cln.AsNode2D().SetStdXMLAttr(attr.Name.Local, attr.Value) {
+						continue
+					}
+					switch attr.Name.Local {
+					default:
+						cln.SetProp(attr.Name.Local, attr.Value)
 					}
 				}
+				if nb, ok := cln.Embed(KiT_NodeBase).(*NodeBase); ok {
+					nb.Use = NewUseRef(se.Attr)
+					nb.Use.Copy = useCopyXML(cln, nb)
+				}
 			case nm == "Work":
 				fallthrough
 			case nm == "RDF":
@@ -623,13 +813,68 @@ func (svg *SVG) UnmarshalXML(decoder *xml.Decoder, se xml.StartElement) error {
 						curPar.SetProp(attr.Name.Local, attr.Value)
 					}
 				}
+			case nm == "animate" || nm == "set" || nm == "animateTransform" || nm == "animateMotion":
+				anPar := curPar
+				switch {
+				case len(txtStack) > 0:
+					anPar = txtStack[len(txtStack)-1]
+				case animPar != nil:
+					anPar = animPar
+				}
+				var an Animator
+				switch nm {
+				case "animate":
+					an = anPar.AddNewChild(KiT_Animate, nm).(*Animate)
+				case "set":
+					an = anPar.AddNewChild(KiT_Set, nm).(*Set)
+				case "animateTransform":
+					an = anPar.AddNewChild(KiT_AnimateTransform, nm).(*AnimateTransform)
+				case "animateMotion":
+					curMotion = anPar.AddNewChild(KiT_AnimateMotion, nm).(*AnimateMotion)
+					an = curMotion
+				}
+				ab := an.AsAnimateBase()
+				for _, attr := range se.Attr {
+					if an.SetAnimXMLAttr(attr.Name.Local, attr.Value) {
+						continue
+					}
+					if ab.SetStdXMLAttr(attr.Name.Local, attr.Value) {
+						continue
+					}
+					switch attr.Name.Local {
+					default:
+						ab.SetProp(attr.Name.Local, attr.Value)
+					}
+				}
+			case nm == "mpath":
+				if curMotion != nil {
+					curMotion.MPath = gi.XMLAttr("href", se.Attr)
+				}
 			default:
 				errStr := "gi.SVG Cannot process svg element " + se.Name.Local
 				log.Println(errStr)
 				IconAutoOpen = false
 			}
+			switch nm {
+			case "rect", "circle", "ellipse", "line", "polygon", "polyline", "path", "use", "image":
+				animPar = nil
+				if kids := curPar.AsNode2D().Kids; len(kids) > nkids {
+					animPar, _ = gi.KiToNode2D(kids[len(kids)-1])
+				}
+			case "animate", "set", "animateTransform", "animateMotion", "mpath":
+			default:
+				animPar = nil
+			}
 		case xml.EndElement:
 			switch se.Name.Local {
+			case "animate", "set", "animateTransform", "animateMotion", "mpath":
+			default:
+				animPar = nil
+			}
+			switch se.Name.Local {
+			case "animateMotion":
+				curMotion = nil
+			case "animate", "set", "animateTransform", "mpath":
 			case "title":
 				inTitle = false
 			case "desc":
@@ -637,12 +882,13 @@ func (svg *SVG) UnmarshalXML(decoder *xml.Decoder, se xml.StartElement) error {
 			case "style":
 				inCSS = false
 				curCSS = nil
-			case "text":
-				inTxt = false
-				curTxt = nil
-			case "tspan":
-				inTspn = false
-				curTspn = nil
+			case "text", "tspan", "textPath":
+				if len(txtStack) > 0 {
+					if len(txtStack) == 1 {
+						txtStack[0].TrimSpace()
+					}
+					txtStack = txtStack[:len(txtStack)-1]
+				}
 			case "defs":
 				if inDef {
 					inDef = false
@@ -656,6 +902,7 @@ func (svg *SVG) UnmarshalXML(decoder *xml.Decoder, se xml.StartElement) error {
 			case "polyline":
 			case "path":
 			case "use":
+			case "image":
 			case "linearGradient":
 			case "radialGradient":
 			default:
@@ -684,10 +931,8 @@ func (svg *SVG) UnmarshalXML(decoder *xml.Decoder, se xml.StartElement) error {
 				curSvg.Title += trspc
 			case inDesc:
 				curSvg.Desc += trspc
-			case inTspn && curTspn != nil:
-				curTspn.Text = trspc
-			case inTxt && curTxt != nil:
-				curTxt.Text = trspc
+			case len(txtStack) > 0:
+				txtStack[len(txtStack)-1].AddText(string(se))
 			case inCSS && curCSS != nil:
 				curCSS.ParseString(trspc)
 				cp := curCSS.CSSProps()
@@ -703,3 +948,547 @@ func (svg *SVG) UnmarshalXML(decoder *xml.Decoder, se xml.StartElement) error {
 	}
 	return nil
 }
+
+/////////////////////////////////////////////////////////////////////////////
+//   Writing
+
+// SaveXML saves the svg to a XML-encoded file, using WriteXML
+func (svg *SVG) SaveXML(filename string) error {
+	fp, err := os.Create(filename)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	defer fp.Close()
+	svg.Filename = filename
+	return svg.WriteXML(fp, true)
+}
+
+// WriteXML writes XML-formatted SVG output to io.Writer, optionally with
+// indentation -- every node in the svg (including Defs) is written back as
+// the corresponding element, with the node name as its id, Class as its
+// class, and its properties (which include transform and style props read
+// from attributes) as attributes.  Elements included via use were expanded
+// into copies when read, which are written back as the use elements unless
+// they have been changed since (see UseRef), and as copies otherwise.  The
+// layout properties of the svg as a widget, and the view transform of an
+// Editor, are not written.
+func (svg *SVG) WriteXML(wr io.Writer, indent bool) error {
+	enc := xml.NewEncoder(wr)
+	if indent {
+		enc.Indent("", "  ")
+	}
+	if _, err := io.WriteString(wr, xml.Header); err != nil {
+		return err
+	}
+	err := svg.MarshalXML(enc, xml.StartElement{})
+	if err == nil {
+		err = enc.Flush()
+	}
+	if err == nil && indent {
+		_, err = io.WriteString(wr, "\n")
+	}
+	if err != nil {
+		log.Println(err)
+	}
+	return err
+}
+
+// MarshalXML writes the svg as an svg element using xml.Encoder, including
+// the title, desc and defs, followed by all of its children -- animated
+// elements are written with their unanimated values
+func (svg *SVG) MarshalXML(enc *xml.Encoder, se xml.StartElement) error {
+	svg.ResetAnimations()
+	defer svg.ApplyAnimations()
+	se.Name = xml.Name{Local: "svg"}
+	if svg.ParentByType(KiT_SVG, true) == nil { // top-level
+		se.Attr = append(se.Attr, xml.Attr{Name: xml.Name{Local: "xmlns"}, Value: "http://www.w3.org/2000/svg"})
+	}
+	se.Attr = append(se.Attr, xmlNodeAttrs(svg.This().(gi.Node2D), "svg")...)
+	if svg.ViewBox.Size != gi.Vec2DZero {
+		vb := &svg.ViewBox
+		se.Attr = appendXMLAttr(se.Attr, "viewBox", xmlFloats([]float32{vb.Min.X, vb.Min.Y, vb.Size.X, vb.Size.Y}, " "))
+	}
+	if err := enc.EncodeToken(se); err != nil {
+		return err
+	}
+	if svg.Title != "" {
+		if err := xmlTextElement(enc, "title", svg.Title); err != nil {
+			return err
+		}
+	}
+	if svg.Desc != "" {
+		if err := xmlTextElement(enc, "desc", svg.Desc); err != nil {
+			return err
+		}
+	}
+	if svg.Defs.HasChildren() {
+		dse := xml.StartElement{Name: xml.Name{Local: "defs"}}
+		if err := enc.EncodeToken(dse); err != nil {
+			return err
+		}
+		if err := MarshalXMLChildren(enc, svg.Defs.This()); err != nil {
+			return err
+		}
+		if err := enc.EncodeToken(dse.End()); err != nil {
+			return err
+		}
+	}
+	if err := MarshalXMLChildren(enc, svg.This()); err != nil {
+		return err
+	}
+	return enc.EncodeToken(se.End())
+}
+
+// MarshalXMLChildren writes all the children of given node using
+// MarshalXMLNode
+func MarshalXMLChildren(enc *xml.Encoder, par ki.Ki) error {
+	for _, k := range *par.Children() {
+		if err := MarshalXMLNode(enc, k); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MarshalXMLNode writes the given svg node, and all of its children, as the
+// corresponding XML element -- nodes that have no SVG element form are
+// skipped.
+func MarshalXMLNode(enc *xml.Encoder, k ki.Ki) error {
+	gii, ok := k.(gi.Node2D)
+	if !ok {
+		return nil
+	}
+	if nb, ok := k.Embed(KiT_NodeBase).(*NodeBase); ok && nb.Use != nil {
+		if useCopyXML(k, nb) == nb.Use.Copy {
+			return nb.Use.MarshalXML(enc)
+		}
+		use := nb.Use // changed: written as a copy
+		nb.Use = nil
+		defer func() { nb.Use = use }()
+	}
+	var se xml.StartElement
+	attr := func(nm, val string) {
+		se.Attr = appendXMLAttr(se.Attr, nm, val)
+	}
+	ff := xmlFloat
+	text := ""
+	switch nd := k.(type) {
+	case *SVG:
+		return nd.MarshalXML(enc, se)
+	case *gi.Gradient:
+		if nd.Grad.Gradient == nil || nd.Grad.Source == gi.SolidColor {
+			// e.g., a new gradient that has not been set yet
+			log.Printf("svg.MarshalXMLNode: skipping gradient with no gradient set: %v\n", nd.Name())
+			return nil
+		}
+		se.Attr = xmlNodeAttrs(gii, "", "lin-grad", "rad-grad")
+		return nd.Grad.MarshalXML(enc, se)
+	case *gi.StyleSheet:
+		if nd.Sheet == nil {
+			return nil
+		}
+		se.Name.Local = "style"
+		se.Attr = xmlNodeAttrs(gii, "style")
+		text = nd.Sheet.String()
+	case *Group:
+		se.Name.Local = "g"
+		se.Attr = xmlNodeAttrs(gii, "g")
+	case *Rect:
+		se.Name.Local = "rect"
+		se.Attr = xmlNodeAttrs(gii, "rect")
+		attr("x", ff(nd.Pos.X))
+		attr("y", ff(nd.Pos.Y))
+		attr("width", ff(nd.Size.X))
+		attr("height", ff(nd.Size.Y))
+		if nd.Radius != gi.Vec2DZero {
+			attr("rx", ff(nd.Radius.X))
+			attr("ry", ff(nd.Radius.Y))
+		}
+	case *Circle:
+		se.Name.Local = "circle"
+		se.Attr = xmlNodeAttrs(gii, "circle")
+		attr("cx", ff(nd.Pos.X))
+		attr("cy", ff(nd.Pos.Y))
+		attr("r", ff(nd.Radius))
+	case *Ellipse:
+		se.Name.Local = "ellipse"
+		se.Attr = xmlNodeAttrs(gii, "ellipse")
+		attr("cx", ff(nd.Pos.X))
+		attr("cy", ff(nd.Pos.Y))
+		attr("rx", ff(nd.Radii.X))
+		attr("ry", ff(nd.Radii.Y))
+	case *Line:
+		se.Name.Local = "line"
+		se.Attr = xmlNodeAttrs(gii, "line")
+		attr("x1", ff(nd.Start.X))
+		attr("y1", ff(nd.Start.Y))
+		attr("x2", ff(nd.End.X))
+		attr("y2", ff(nd.End.Y))
+	case *Polygon:
+		se.Name.Local = "polygon"
+		se.Attr = xmlNodeAttrs(gii, "polygon")
+		attr("points", xmlPoints(nd.Points))
+	case *Polyline:
+		se.Name.Local = "polyline"
+		se.Attr = xmlNodeAttrs(gii, "polyline")
+		attr("points", xmlPoints(nd.Points))
+	case *Path:
+		se.Name.Local = "path"
+		se.Attr = xmlNodeAttrs(gii, "path")
+		attr("d", PathDataString(nd.Data))
+	case *Text:
+		switch {
+		case nd.IsAnon():
+			return enc.EncodeToken(xml.CharData(nd.Text))
+		case nd.IsTextPath():
+			se.Name.Local = "textPath"
+		case nd.IsRoot():
+			se.Name.Local = "text"
+		default:
+			se.Name.Local = "tspan"
+		}
+		se.Attr = xmlNodeAttrs(gii, "txt", "tspan", "textPath")
+		if len(nd.CharPosX) > 0 {
+			attr("x", xmlFloats(nd.CharPosX, " "))
+		} else if nd.IsRoot() {
+			attr("x", ff(nd.Pos.X))
+		}
+		if len(nd.CharPosY) > 0 {
+			attr("y", xmlFloats(nd.CharPosY, " "))
+		} else if nd.IsRoot() {
+			attr("y", ff(nd.Pos.Y))
+		}
+		if nd.IsTextPath() {
+			if nd.TextPath != "#" {
+				attr("href", nd.TextPath)
+			}
+			if nd.StartOffset.Val != 0 {
+				so := ff(nd.StartOffset.Val)
+				if nd.StartOffset.Un == units.Pct {
+					so += "%"
+				}
+				attr("startOffset", so)
+			}
+		}
+		if len(nd.CharPosDX) > 0 {
+			attr("dx", xmlFloats(nd.CharPosDX, " "))
+		}
+		if len(nd.CharPosDY) > 0 {
+			attr("dy", xmlFloats(nd.CharPosDY, " "))
+		}
+		if len(nd.CharRots) > 0 {
+			attr("rotate", xmlFloats(nd.CharRots, " "))
+		}
+		if nd.TextLength > 0 {
+			attr("textLength", ff(nd.TextLength))
+			if nd.AdjustGlyphs {
+				attr("lengthAdjust", "spacingAndGlyphs")
+			}
+		}
+		text = nd.Text
+	case *ClipPath:
+		se.Name.Local = "clipPath"
+		se.Attr = xmlNodeAttrs(gii, "clip-path")
+	case *Mask:
+		se.Name.Local = "mask"
+		se.Attr = xmlNodeAttrs(gii, "mask")
+	case *Marker:
+		se.Name.Local = "marker"
+		se.Attr = xmlNodeAttrs(gii, "marker")
+		attr("refX", ff(nd.RefPos.X))
+		attr("refY", ff(nd.RefPos.Y))
+		attr("markerWidth", ff(nd.Size.X))
+		attr("markerHeight", ff(nd.Size.Y))
+		if nd.Units == UserSpaceOnUse {
+			attr("markerUnits", "userSpaceOnUse")
+		}
+		if nd.ViewBox.Size != gi.Vec2DZero {
+			vb := &nd.ViewBox
+			attr("viewBox", xmlFloats([]float32{vb.Min.X, vb.Min.Y, vb.Size.X, vb.Size.Y}, " "))
+		}
+		if nd.Orient != "" {
+			attr("orient", nd.Orient)
+		}
+	case *Image:
+		se.Name.Local = "image"
+		se.Attr = xmlNodeAttrs(gii, "image")
+		attr("x", ff(nd.Pos.X))
+		attr("y", ff(nd.Pos.Y))
+		if nd.Size != gi.Vec2DZero {
+			attr("width", ff(nd.Size.X))
+			attr("height", ff(nd.Size.Y))
+		}
+		if nd.PreserveAspectRatio.Align != 0 {
+			attr("preserveAspectRatio", nd.PreserveAspectRatio.String())
+		}
+		href := nd.Href
+		if href == "" && nd.Pixels != nil {
+			href = ImageDataURL(nd.Pixels)
+		}
+		attr("href", href)
+	case *Symbol:
+		se.Name.Local = "symbol"
+		se.Attr = xmlNodeAttrs(gii, "symbol")
+		if nd.ViewBox.Size != gi.Vec2DZero {
+			vb := &nd.ViewBox
+			attr("viewBox", xmlFloats([]float32{vb.Min.X, vb.Min.Y, vb.Size.X, vb.Size.Y}, " "))
+			if vb.PreserveAspectRatio.Align != 0 {
+				attr("preserveAspectRatio", vb.PreserveAspectRatio.String())
+			}
+		}
+	case *Pattern:
+		se.Name.Local = "pattern"
+		se.Attr = xmlNodeAttrs(gii, "pattern")
+		for i := range se.Attr {
+			if se.Attr[i].Name.Local == "transform" {
+				se.Attr[i].Name.Local = "patternTransform"
+			}
+		}
+		attr("x", ff(nd.Pos.X))
+		attr("y", ff(nd.Pos.Y))
+		attr("width", ff(nd.Size.X))
+		attr("height", ff(nd.Size.Y))
+		if nd.Units != "" {
+			attr("patternUnits", nd.Units)
+		}
+		if nd.ContentUnits != "" {
+			attr("patternContentUnits", nd.ContentUnits)
+		}
+		if nd.ViewBox.Size != gi.Vec2DZero {
+			vb := &nd.ViewBox
+			attr("viewBox", xmlFloats([]float32{vb.Min.X, vb.Min.Y, vb.Size.X, vb.Size.Y}, " "))
+			if vb.PreserveAspectRatio.Align != 0 {
+				attr("preserveAspectRatio", vb.PreserveAspectRatio.String())
+			}
+		}
+	case *Switch:
+		se.Name.Local = "switch"
+		se.Attr = xmlNodeAttrs(gii, "switch")
+	case *Flow:
+		se.Name.Local = nd.FlowType
+		se.Attr = xmlNodeAttrs(gii, nd.FlowType)
+	case *Filter:
+		se.Name.Local = nd.FilterType
+		se.Attr = xmlNodeAttrs(gii, nd.FilterType)
+	case *gi.MetaData2D:
+		se.Name.Local = nd.Class
+		se.Attr = xmlNodeAttrs(gii, nd.Class)
+	case *Animate:
+		se.Name.Local = "animate"
+		se.Attr = xmlAnimAttrs(gii, &nd.AnimateBase, "animate")
+	case *Set:
+		se.Name.Local = "set"
+		se.Attr = xmlAnimAttrs(gii, &nd.AnimateBase, "set")
+	case *AnimateTransform:
+		se.Name.Local = "animateTransform"
+		se.Attr = xmlAnimAttrs(gii, &nd.AnimateBase, "animateTransform")
+		if nd.Type != "" {
+			attr("type", nd.Type)
+		}
+	case *AnimateMotion:
+		se.Name.Local = "animateMotion"
+		se.Attr = xmlAnimAttrs(gii, &nd.AnimateBase, "animateMotion")
+		if nd.Path != "" {
+			attr("path", nd.Path)
+		}
+		if nd.Rotate != "" {
+			attr("rotate", nd.Rotate)
+		}
+	default:
+		return nil
+	}
+	if se.Name.Local == "" {
+		return nil
+	}
+	if err := enc.EncodeToken(se); err != nil {
+		return err
+	}
+	if text != "" {
+		if err := enc.EncodeToken(xml.CharData(text)); err != nil {
+			return err
+		}
+	}
+	if am, ok := k.(*AnimateMotion); ok && am.MPath != "" {
+		mse := xml.StartElement{Name: xml.Name{Local: "mpath"}, Attr: appendXMLAttr(nil, "href", am.MPath)}
+		if err := enc.EncodeToken(mse); err != nil {
+			return err
+		}
+		if err := enc.EncodeToken(mse.End()); err != nil {
+			return err
+		}
+	}
+	if err := MarshalXMLChildren(enc, k); err != nil {
+		return err
+	}
+	return enc.EncodeToken(se.End())
+}
+
+// xmlNodeAttrs returns the standard XML attributes for given node: id (if
+// the name is not one of the default names given when reading), class, and
+// all of its properties, in sorted order
+func xmlNodeAttrs(gii gi.Node2D, defNms ...string) []xml.Attr {
+	nb := gii.AsNode2D()
+	var attrs []xml.Attr
+	nm := nb.Name()
+	isDef := nm == ""
+	for _, dn := range defNms {
+		if nm == dn {
+			isDef = true
+			break
+		}
+	}
+	if !isDef {
+		attrs = appendXMLAttr(attrs, "id", nm)
+	}
+	if nb.Class != "" {
+		attrs = appendXMLAttr(attrs, "class", nb.Class)
+	}
+	isSVG := gii.Embed(KiT_SVG) != nil
+	isEditor := gii.Embed(KiT_Editor) != nil
+	pnms := make([]string, 0, len(nb.Props))
+	for pk, pv := range nb.Props {
+		if _, isProps := pv.(ki.Props); isProps {
+			continue // sub-selector props, e.g., from css
+		}
+		if isSVG && svgWidgetProps[pk] {
+			continue // layout props, not svg attrs
+		}
+		if isEditor && pk == "transform" {
+			continue // view transform, see Editor.SetTransform
+		}
+		pnms = append(pnms, pk)
+	}
+	sort.Strings(pnms)
+	for _, pk := range pnms {
+		attrs = appendXMLAttr(attrs, pk, xmlPropString(nb.Props[pk]))
+	}
+	return attrs
+}
+
+// svgWidgetProps are the properties of an svg that are used for its layout
+// as a widget, which are not written as attributes
+var svgWidgetProps = map[string]bool{
+	"width": true, "height": true, "xmlns": true,
+	"min-width": true, "min-height": true, "max-width": true, "max-height": true,
+	"background-color": true, "background": true,
+}
+
+// UseRef is a use element that was read, which is instantiated as a copy of
+// the element it refers to -- the copy is written back as the use element
+// as long as it is unchanged
+type UseRef struct {
+	Attrs []xml.Attr `desc:"attributes of the use element, including its href"`
+	Copy  string     `desc:"XML of the copy as it was made, to which it is compared when written"`
+}
+
+// NewUseRef returns a new UseRef for a use element with given attributes
+func NewUseRef(attrs []xml.Attr) *UseRef {
+	ur := &UseRef{}
+	for _, attr := range attrs {
+		ur.Attrs = appendXMLAttr(ur.Attrs, attr.Name.Local, attr.Value)
+	}
+	return ur
+}
+
+// MarshalXML writes the use element using xml.Encoder
+func (ur *UseRef) MarshalXML(enc *xml.Encoder) error {
+	se := xml.StartElement{Name: xml.Name{Local: "use"}, Attr: ur.Attrs}
+	if err := enc.EncodeToken(se); err != nil {
+		return err
+	}
+	return enc.EncodeToken(se.End())
+}
+
+// useCopyXML returns the XML of given node, which is a copy of a used
+// element, as written as a copy
+func useCopyXML(k ki.Ki, nb *NodeBase) string {
+	use := nb.Use
+	nb.Use = nil
+	defer func() { nb.Use = use }()
+	var b bytes.Buffer
+	enc := xml.NewEncoder(&b)
+	if err := MarshalXMLNode(enc, k); err != nil {
+		return ""
+	}
+	if err := enc.Flush(); err != nil {
+		return ""
+	}
+	return b.String()
+}
+
+// xmlAnimAttrs returns the XML attributes for given animation node: the
+// standard attributes, followed by the animation attributes that are set
+func xmlAnimAttrs(gii gi.Node2D, ab *AnimateBase, defNms ...string) []xml.Attr {
+	attrs := xmlNodeAttrs(gii, defNms...)
+	for _, av := range []struct{ nm, val string }{
+		{"href", ab.Target}, {"attributeName", ab.AttributeName},
+		{"begin", ab.Begin}, {"dur", ab.Dur}, {"repeatCount", ab.RepeatCount},
+		{"repeatDur", ab.RepeatDur}, {"fill", ab.FillMode}, {"from", ab.From},
+		{"to", ab.To}, {"by", ab.By}, {"values", ab.Values},
+		{"keyTimes", ab.KeyTimes}, {"keySplines", ab.KeySplines},
+		{"calcMode", ab.CalcMode}, {"additive", ab.Additive},
+		{"accumulate", ab.Accumulate},
+	} {
+		if av.val != "" {
+			attrs = appendXMLAttr(attrs, av.nm, av.val)
+		}
+	}
+	return attrs
+}
+
+// xmlPropString returns the string representation of given property value
+func xmlPropString(pv interface{}) string {
+	switch v := pv.(type) {
+	case string:
+		return v
+	case gi.Color:
+		return v.HexString()
+	case *gi.Color:
+		return v.HexString()
+	case float32:
+		return xmlFloat(v)
+	case units.Value:
+		return v.String()
+	}
+	return kit.ToString(pv)
+}
+
+// appendXMLAttr appends an attribute with given name and value
+func appendXMLAttr(attrs []xml.Attr, nm, val string) []xml.Attr {
+	return append(attrs, xml.Attr{Name: xml.Name{Local: nm}, Value: val})
+}
+
+// xmlTextElement writes an element with given name containing given text
+func xmlTextElement(enc *xml.Encoder, nm, text string) error {
+	se := xml.StartElement{Name: xml.Name{Local: nm}}
+	if err := enc.EncodeToken(se); err != nil {
+		return err
+	}
+	if err := enc.EncodeToken(xml.CharData(text)); err != nil {
+		return err
+	}
+	return enc.EncodeToken(se.End())
+}
+
+func xmlFloat(f float32) string {
+	return strconv.FormatFloat(float64(f), 'g', -1, 32)
+}
+
+// xmlFloats returns the floats as strings separated by sep
+func xmlFloats(fs []float32, sep string) string {
+	strs := make([]string, len(fs))
+	for i, f := range fs {
+		strs[i] = xmlFloat(f)
+	}
+	return strings.Join(strs, sep)
+}
+
+// xmlPoints returns the points in the svg points format: x,y x,y ...
+func xmlPoints(pts []gi.Vec2D) string {
+	strs := make([]string, len(pts))
+	for i, pt := range pts {
+		strs[i] = xmlFloat(pt.X) + "," + xmlFloat(pt.Y)
+	}
+	return strings.Join(strs, " ")
+}
@@ -0,0 +1,178 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package svg
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/ki"
+)
+
+const testRoundTripSVG = `<?xml version="1.0" encoding="UTF-8"?>
+<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink" width="200" height="100" viewBox="0 0 200 100">
+  <title>round trip</title>
+  <defs>
+    <linearGradient id="lin" x1="0" y1="0" x2="1" y2="0" gradientTransform="rotate(45)">
+      <stop offset="0" stop-color="#ff0000"/>
+      <stop offset="1" stop-color="#0000ff" stop-opacity="0.5"/>
+    </linearGradient>
+    <radialGradient id="rad" cx="0.5" cy="0.5" r="0.4" gradientUnits="userSpaceOnUse" spreadMethod="reflect">
+      <stop offset="0.2" stop-color="#00ff00"/>
+      <stop offset="0.9" stop-color="#000000"/>
+    </radialGradient>
+    <path id="tri" d="M 0 0 L 10 0 L 5 8 Z" fill="url(#rad)"/>
+  </defs>
+  <g id="grp" transform="translate(20,10) scale(2)" stroke="#000000">
+    <rect id="box" x="1" y="2" width="30" height="20" fill="url(#lin)"/>
+    <path id="curve" d="M 0 0 C 10 20 30 20 40 0 Q 50 -10 60 0" fill="none"/>
+    <g class="inner">
+      <use xlink:href="#tri" x="5" y="6" stroke-width="2"/>
+    </g>
+  </g>
+  <path d="M 100 10 L 190 10 L 190 90 Z" transform="rotate(10 150 50)"/>
+</svg>
+`
+
+// testSVG returns a new svg for testing, not in any window
+func testSVG() *SVG {
+	sv := &SVG{}
+	sv.InitName(sv, "svg")
+	return sv
+}
+
+// testSameTree reports any differences between the two svg trees, in the
+// types, names, classes and properties of the nodes, and in the data of
+// paths, rects, gradients and use elements
+func testSameTree(t *testing.T, a, b ki.Ki) {
+	t.Helper()
+	pth := a.PathUnique()
+	if a.Type() != b.Type() || a.Name() != b.Name() {
+		t.Errorf("%v: node is %v %v, want: %v %v", pth, b.Type().Name(), b.Name(), a.Type().Name(), a.Name())
+		return
+	}
+	if !reflect.DeepEqual(*a.Properties(), *b.Properties()) {
+		t.Errorf("%v: props are %v, want: %v", pth, *b.Properties(), *a.Properties())
+	}
+	if ga, ok := a.(gi.Node2D); ok {
+		if ca, cb := ga.AsNode2D().Class, b.(gi.Node2D).AsNode2D().Class; ca != cb {
+			t.Errorf("%v: class is %q, want: %q", pth, cb, ca)
+		}
+	}
+	switch na := a.(type) {
+	case *Path:
+		if da, db := PathDataString(na.Data), PathDataString(b.(*Path).Data); da != db {
+			t.Errorf("%v: path data is %q, want: %q", pth, db, da)
+		}
+	case *Rect:
+		nb := b.(*Rect)
+		if na.Pos != nb.Pos || na.Size != nb.Size {
+			t.Errorf("%v: rect is %v %v, want: %v %v", pth, nb.Pos, nb.Size, na.Pos, na.Size)
+		}
+	case *gi.Gradient:
+		if !reflect.DeepEqual(na.Grad, b.(*gi.Gradient).Grad) {
+			t.Errorf("%v: gradient is %+v, want: %+v", pth, *b.(*gi.Gradient).Grad.Gradient, *na.Grad.Gradient)
+		}
+	}
+	if ua, ok := a.Embed(KiT_NodeBase).(*NodeBase); ok {
+		ub := b.Embed(KiT_NodeBase).(*NodeBase)
+		if (ua.Use == nil) != (ub.Use == nil) || (ua.Use != nil && !reflect.DeepEqual(ua.Use.Attrs, ub.Use.Attrs)) {
+			t.Errorf("%v: use is %+v, want: %+v", pth, ub.Use, ua.Use)
+		}
+	}
+	ka, kb := *a.Children(), *b.Children()
+	if len(ka) != len(kb) {
+		t.Errorf("%v: has %v children, want: %v", pth, len(kb), len(ka))
+		return
+	}
+	for i := range ka {
+		testSameTree(t, ka[i], kb[i])
+	}
+}
+
+func TestXMLRoundTrip(t *testing.T) {
+	dir, err := ioutil.TempDir("", "svg-io-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	orig := filepath.Join(dir, "orig.svg")
+	if err := ioutil.WriteFile(orig, []byte(testRoundTripSVG), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	sv1 := testSVG()
+	if err := sv1.OpenXML(orig); err != nil {
+		t.Fatalf("OpenXML of original: %v", err)
+	}
+	if sv1.Title != "round trip" || sv1.ViewBox.Size != (gi.Vec2D{200, 100}) {
+		t.Errorf("title: %q viewbox: %v", sv1.Title, sv1.ViewBox.Size)
+	}
+	if len(sv1.Defs.Kids) != 3 || len(sv1.Kids) != 2 {
+		t.Fatalf("read %v defs and %v children, want: 3 and 2", len(sv1.Defs.Kids), len(sv1.Kids))
+	}
+	// the use element is read as a copy of its def, in the inner group
+	use := sv1.Kids[0].KnownChild(2).KnownChild(0)
+	if _, ok := use.(*Path); !ok || use.Name() != "tri" {
+		t.Errorf("use copy is %v %v, want: Path tri", use.Type().Name(), use.Name())
+	}
+	pv, _ := use.Prop("transform")
+	if xf, _ := pv.(string); xf != "translate(5,6)" {
+		t.Errorf("use copy transform: %q, want: %q", xf, "translate(5,6)")
+	}
+
+	var buf bytes.Buffer
+	if err := sv1.WriteXML(&buf, true); err != nil {
+		t.Fatalf("WriteXML: %v", err)
+	}
+	out := buf.String()
+	if n := strings.Count(out, "<use "); n != 1 {
+		t.Errorf("written svg has %v use elements, want: 1:\n%v", n, out)
+	}
+	saved := filepath.Join(dir, "saved.svg")
+	if err := ioutil.WriteFile(saved, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	sv2 := testSVG()
+	if err := sv2.OpenXML(saved); err != nil {
+		t.Fatalf("OpenXML of written svg: %v\n%v", err, out)
+	}
+	if sv2.Title != sv1.Title || sv2.ViewBox != sv1.ViewBox {
+		t.Errorf("title: %q viewbox: %v, want: %q %v", sv2.Title, sv2.ViewBox, sv1.Title, sv1.ViewBox)
+	}
+	testSameTree(t, sv1.Defs.This(), sv2.Defs.This())
+	testSameTree(t, sv1.This(), sv2.This())
+
+	// writing again gives the same svg
+	var buf2 bytes.Buffer
+	if err := sv2.WriteXML(&buf2, true); err != nil {
+		t.Fatalf("WriteXML of written svg: %v", err)
+	}
+	if buf2.String() != out {
+		t.Errorf("svg written again is:\n%v\nwant:\n%v", buf2.String(), out)
+	}
+}
+
+func TestXMLWriteUnsetGradient(t *testing.T) {
+	sv := testSVG()
+	if err := sv.ReadXML(strings.NewReader(testRoundTripSVG)); err != nil {
+		t.Fatal(err)
+	}
+	sv.Defs.AddNewChild(gi.KiT_Gradient, "unset")
+	var buf bytes.Buffer
+	if err := sv.WriteXML(&buf, false); err != nil {
+		t.Fatalf("WriteXML with an unset gradient: %v", err)
+	}
+	if out := buf.String(); strings.Contains(out, "unset") || !strings.Contains(out, `id="lin"`) {
+		t.Errorf("unset gradient was not skipped, or the others were:\n%v", out)
+	}
+}
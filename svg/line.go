@@ -44,3 +44,8 @@ func (g *Line) Render2D() {
 	g.Render2DChildren()
 	rs.PopXFormLock()
 }
+
+// ShapeData returns the path of the line, for hit testing -- see Shape
+func (g *Line) ShapeData() []PathData {
+	return PathDataPoly([]gi.Vec2D{g.Start, g.End}, false)
+}
@@ -0,0 +1,44 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package svg
+
+import (
+	"image"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/ki/kit"
+)
+
+// Mask is used for holding elements that render as a luminance mask -- any
+// node with a mask = url(#id) property referring to it renders with an
+// opacity given by the luminance of the rendered children of the mask --
+// see Render2DEffects
+type Mask struct {
+	NodeBase
+}
+
+var KiT_Mask = kit.Types.AddType(&Mask{}, nil)
+
+// Render2D does nothing -- a mask is only rendered as a mask, via RenderMask
+func (g *Mask) Render2D() {
+}
+
+// RenderMask renders the mask for given node into an alpha mask the size of
+// the render image, in the user space of the node, with the alpha given by
+// the luminance of the rendered children times their alpha
+func (g *Mask) RenderMask(node gi.Node2D) *image.Alpha {
+	layer := renderLayer(node, &g.NodeBase, g.Props["maskContentUnits"])
+	if layer == nil {
+		return nil
+	}
+	mask := image.NewAlpha(layer.Bounds())
+	// layer is alpha-premultiplied, so luminance already includes alpha
+	for i := range mask.Pix {
+		pi := i * 4
+		r, g, b := float32(layer.Pix[pi]), float32(layer.Pix[pi+1]), float32(layer.Pix[pi+2])
+		mask.Pix[i] = uint8(0.2125*r + 0.7154*g + 0.0721*b + 0.5)
+	}
+	return mask
+}
@@ -19,7 +19,9 @@ import (
 // layout logic -- just renders into parent SVG viewport
 type NodeBase struct {
 	gi.Node2DBase
-	Pnt gi.Paint `json:"-" xml:"-" desc:"full paint information for this node"`
+	Pnt         gi.Paint    `json:"-" xml:"-" desc:"full paint information for this node"`
+	RenderXForm gi.Matrix2D `json:"-" xml:"-" view:"-" desc:"full transform from the user coordinates of this node to the image pixels of the svg, as of the last render -- used for hit testing"`
+	Use         *UseRef     `json:"-" xml:"-" view:"-" desc:"if this node is a copy of the element referred to by a use element that was read, the use element, so that the copy can be written back as it"`
 }
 
 var KiT_NodeBase = kit.Types.AddType(&NodeBase{}, NodeBaseProps)
@@ -95,46 +97,46 @@ func StyleSVG(gii gi.Node2D) {
 }
 
 // ApplyCSSSVG applies css styles to given node, using key to select sub-props
-// from overall properties list
+// from overall properties list -- the rule of key only applies if its
+// selector matches the node (see gi.CSSMatchingProps)
 func ApplyCSSSVG(node gi.Node2D, key string, css ki.Props) bool {
-	pntr, ok := node.(gi.Painter)
-	if !ok {
-		return false
-	}
 	pp, got := css[key]
 	if !got {
 		return false
 	}
-	pmap, ok := pp.(ki.Props) // must be a props map
-	if !ok {
+	return styleCSSProps(node, gi.CSSMatchingProps(node, ki.Props{key: pp}, ""))
+}
+
+// StyleCSS applies css style properties to given SVG node, from the rules
+// whose selectors match it (see gi.CSSSelector), in order of specificity
+func StyleCSS(node gi.Node2D, css ki.Props) {
+	if len(css) == 0 {
+		return
+	}
+	styleCSSProps(node, gi.CSSMatchingProps(node, css, ""))
+}
+
+// styleCSSProps applies given css style properties to given SVG node, in
+// order -- returns false if there are none or the node has no Paint
+func styleCSSProps(node gi.Node2D, pmaps []ki.Props) bool {
+	pntr, ok := node.(gi.Painter)
+	if !ok || len(pmaps) == 0 {
 		return false
 	}
 	nb := node.AsNode2D()
 	pc := pntr.Paint()
-
+	var parPc *gi.Paint
 	if pgi, _ := gi.KiToNode2D(node.Parent()); pgi != nil {
 		if pp, ok := pgi.(gi.Painter); ok {
-			pc.SetStyleProps(pp.Paint(), pmap, nb.Viewport)
-		} else {
-			pc.SetStyleProps(nil, pmap, nb.Viewport)
+			parPc = pp.Paint()
 		}
-	} else {
-		pc.SetStyleProps(nil, pmap, nb.Viewport)
+	}
+	for _, pmap := range pmaps {
+		pc.SetStyleProps(parPc, pmap, nb.Viewport)
 	}
 	return true
 }
 
-// StyleCSS applies css style properties to given SVG node, parsing
-// out type, .class, and #name selectors
-func StyleCSS(node gi.Node2D, css ki.Props) {
-	tyn := strings.ToLower(node.Type().Name()) // type is most general, first
-	ApplyCSSSVG(node, tyn, css)
-	cln := "." + strings.ToLower(node.AsNode2D().Class) // then class
-	ApplyCSSSVG(node, cln, css)
-	idnm := "#" + strings.ToLower(node.Name()) // then name
-	ApplyCSSSVG(node, idnm, css)
-}
-
 func (g *NodeBase) Style2D() {
 	StyleSVG(g.This().(gi.Node2D))
 }
@@ -174,6 +176,7 @@ func (g *NodeBase) ChildrenBBox2D() image.Rectangle {
 // gui interaction -- can only be done in rendering because that is when all
 // the proper xforms are all in place -- VpBBox is intersected with parent SVG
 func (g *NodeBase) ComputeBBoxSVG() {
+	g.RenderXForm = g.Viewport.Render.XForm
 	g.BBox = g.This().(gi.Node2D).BBox2D()
 	g.ObjBBox = g.BBox // no diff
 	pbbox := g.Viewport.This().(gi.Node2D).ChildrenBBox2D()
@@ -198,6 +201,14 @@ func (g *NodeBase) Render2D() {
 	rs.PopXFormLock()
 }
 
+// Render2DChildren renders the children, applying any filter, clip-path or
+// mask properties they have -- see Render2DEffects
+func (g *NodeBase) Render2DChildren() {
+	for _, kid := range g.Kids {
+		Render2DEffects(kid)
+	}
+}
+
 func (g *NodeBase) Move2D(delta image.Point, parBBox image.Rectangle) {
 }
 
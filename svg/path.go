@@ -9,6 +9,7 @@ import (
 	"log"
 	"math"
 	"strconv"
+	"strings"
 	"unicode"
 
 	"github.com/chewxy/math32"
@@ -40,6 +41,11 @@ func (g *Path) SetData(data string) error {
 	return err
 }
 
+// ShapeData returns the path data, for hit testing -- see Shape
+func (g *Path) ShapeData() []PathData {
+	return g.Data
+}
+
 func (g *Path) Render2D() {
 	if g.Viewport == nil {
 		g.This().(gi.Node2D).Init2D()
@@ -613,6 +619,256 @@ func PathDataEnd(data []PathData) (vec gi.Vec2D, ang float32) {
 	return
 }
 
+// PathDataAbs returns a copy of the path data with all commands converted to
+// absolute M, L, C, Q, A and Z commands -- H and V become L, and the smooth S
+// and T commands become C and Q with their reflected control points made
+// explicit -- this is the form used for editing the nodes of a path, as
+// every point is then stored directly in the data (see PathDataNodes)
+func PathDataAbs(data []PathData) []PathData {
+	var ad []PathData
+	add := func(cmd PathCmds, vals ...float32) {
+		ad = append(ad, cmd.EncCmd(len(vals)))
+		for _, v := range vals {
+			ad = append(ad, PathData(v))
+		}
+	}
+	sz := len(data)
+	lastCmd := PcErr
+	var stx, sty, cx, cy, x1, y1, ctrlx, ctrly float32
+	for i := 0; i < sz; {
+		cmd, n := PathDataNextCmd(data, &i)
+		rel := false
+		switch cmd {
+		case Pcm:
+			rel = true
+			fallthrough
+		case PcM:
+			for np := 0; np < n/2; np++ {
+				x := PathDataNext(data, &i)
+				y := PathDataNext(data, &i)
+				if rel {
+					cx, cy = cx+x, cy+y
+				} else {
+					cx, cy = x, y
+				}
+				if np == 0 {
+					add(PcM, cx, cy)
+					stx, sty = cx, cy
+				} else {
+					add(PcL, cx, cy)
+				}
+			}
+		case Pcl:
+			rel = true
+			fallthrough
+		case PcL:
+			for np := 0; np < n/2; np++ {
+				x := PathDataNext(data, &i)
+				y := PathDataNext(data, &i)
+				if rel {
+					cx, cy = cx+x, cy+y
+				} else {
+					cx, cy = x, y
+				}
+				add(PcL, cx, cy)
+			}
+		case Pch:
+			rel = true
+			fallthrough
+		case PcH:
+			for np := 0; np < n; np++ {
+				x := PathDataNext(data, &i)
+				if rel {
+					cx += x
+				} else {
+					cx = x
+				}
+				add(PcL, cx, cy)
+			}
+		case Pcv:
+			rel = true
+			fallthrough
+		case PcV:
+			for np := 0; np < n; np++ {
+				y := PathDataNext(data, &i)
+				if rel {
+					cy += y
+				} else {
+					cy = y
+				}
+				add(PcL, cx, cy)
+			}
+		case Pcc:
+			rel = true
+			fallthrough
+		case PcC:
+			for np := 0; np < n/6; np++ {
+				x1 = PathDataNext(data, &i)
+				y1 = PathDataNext(data, &i)
+				ctrlx = PathDataNext(data, &i)
+				ctrly = PathDataNext(data, &i)
+				x := PathDataNext(data, &i)
+				y := PathDataNext(data, &i)
+				if rel {
+					x1, y1, ctrlx, ctrly = cx+x1, cy+y1, cx+ctrlx, cy+ctrly
+					cx, cy = cx+x, cy+y
+				} else {
+					cx, cy = x, y
+				}
+				add(PcC, x1, y1, ctrlx, ctrly, cx, cy)
+			}
+		case Pcs:
+			rel = true
+			fallthrough
+		case PcS:
+			for np := 0; np < n/4; np++ {
+				switch lastCmd {
+				case Pcc, PcC, Pcs, PcS:
+					ctrlx, ctrly = reflectPt(cx, cy, ctrlx, ctrly)
+				default:
+					ctrlx, ctrly = cx, cy
+				}
+				x1 = PathDataNext(data, &i)
+				y1 = PathDataNext(data, &i)
+				x := PathDataNext(data, &i)
+				y := PathDataNext(data, &i)
+				if rel {
+					x1, y1 = cx+x1, cy+y1
+					cx, cy = cx+x, cy+y
+				} else {
+					cx, cy = x, y
+				}
+				add(PcC, ctrlx, ctrly, x1, y1, cx, cy)
+				lastCmd = cmd
+				ctrlx, ctrly = x1, y1
+			}
+		case Pcq:
+			rel = true
+			fallthrough
+		case PcQ:
+			for np := 0; np < n/4; np++ {
+				ctrlx = PathDataNext(data, &i)
+				ctrly = PathDataNext(data, &i)
+				x := PathDataNext(data, &i)
+				y := PathDataNext(data, &i)
+				if rel {
+					ctrlx, ctrly = cx+ctrlx, cy+ctrly
+					cx, cy = cx+x, cy+y
+				} else {
+					cx, cy = x, y
+				}
+				add(PcQ, ctrlx, ctrly, cx, cy)
+			}
+		case Pct:
+			rel = true
+			fallthrough
+		case PcT:
+			for np := 0; np < n/2; np++ {
+				switch lastCmd {
+				case Pcq, PcQ, PcT, Pct:
+					ctrlx, ctrly = reflectPt(cx, cy, ctrlx, ctrly)
+				default:
+					ctrlx, ctrly = cx, cy
+				}
+				x := PathDataNext(data, &i)
+				y := PathDataNext(data, &i)
+				if rel {
+					cx, cy = cx+x, cy+y
+				} else {
+					cx, cy = x, y
+				}
+				add(PcQ, ctrlx, ctrly, cx, cy)
+				lastCmd = cmd
+			}
+		case Pca:
+			rel = true
+			fallthrough
+		case PcA:
+			for np := 0; np < n/7; np++ {
+				rx := PathDataNext(data, &i)
+				ry := PathDataNext(data, &i)
+				ang := PathDataNext(data, &i)
+				largeArc := PathDataNext(data, &i)
+				sweep := PathDataNext(data, &i)
+				x := PathDataNext(data, &i)
+				y := PathDataNext(data, &i)
+				if rel {
+					cx, cy = cx+x, cy+y
+				} else {
+					cx, cy = x, y
+				}
+				add(PcA, rx, ry, ang, largeArc, sweep, cx, cy)
+			}
+		case PcZ, Pcz:
+			add(PcZ)
+			cx, cy = stx, sty
+		default:
+			i += n
+		}
+		lastCmd = cmd
+	}
+	return ad
+}
+
+// PathNode is one editable point of a path, as returned by PathDataNodes
+type PathNode struct {
+	Idx  int      `desc:"index of the x coordinate of the point in the path data -- the y coordinate follows it"`
+	Pt   gi.Vec2D `desc:"position of the point"`
+	Ctrl bool     `desc:"true if this is a control point of a curve, false if it is an end point"`
+	Node int      `desc:"for control points, the index within the nodes of the end point that it is attached to -- moving that end point also moves the control point"`
+}
+
+// PathDataNodes returns the end points and curve control points of the path
+// data, which must be in the absolute form returned by PathDataAbs
+func PathDataNodes(data []PathData) []PathNode {
+	var nodes []PathNode
+	pt := func(i int) gi.Vec2D {
+		return gi.Vec2D{float32(data[i]), float32(data[i+1])}
+	}
+	last := -1 // last end point
+	sz := len(data)
+	for i := 0; i < sz; {
+		cmd, n := PathDataNextCmd(data, &i)
+		if i+n > sz {
+			break
+		}
+		switch cmd {
+		case PcM, PcL:
+			for np := 0; np < n/2; np++ {
+				last = len(nodes)
+				nodes = append(nodes, PathNode{Idx: i, Pt: pt(i)})
+				i += 2
+			}
+		case PcC:
+			for np := 0; np < n/6; np++ {
+				end := len(nodes) + 2
+				nodes = append(nodes, PathNode{Idx: i, Pt: pt(i), Ctrl: true, Node: last})
+				nodes = append(nodes, PathNode{Idx: i + 2, Pt: pt(i + 2), Ctrl: true, Node: end})
+				nodes = append(nodes, PathNode{Idx: i + 4, Pt: pt(i + 4)})
+				last = end
+				i += 6
+			}
+		case PcQ:
+			for np := 0; np < n/4; np++ {
+				end := len(nodes) + 1
+				nodes = append(nodes, PathNode{Idx: i, Pt: pt(i), Ctrl: true, Node: end})
+				nodes = append(nodes, PathNode{Idx: i + 2, Pt: pt(i + 2)})
+				last = end
+				i += 4
+			}
+		case PcA:
+			for np := 0; np < n/7; np++ {
+				last = len(nodes)
+				nodes = append(nodes, PathNode{Idx: i + 5, Pt: pt(i + 5)})
+				i += 7
+			}
+		default:
+			i += n
+		}
+	}
+	return nodes
+}
+
 // PathCmdNMap gives the number of points per each command
 var PathCmdNMap = map[PathCmds]int{
 	PcM: 2,
@@ -786,3 +1042,24 @@ func PathDataParse(d string) ([]PathData, error) {
 	return pd, nil
 	// todo: add some error checking..
 }
+
+// PathDataString returns the string representation of the path data, in
+// standard SVG path syntax -- the inverse of PathDataParse
+func PathDataString(data []PathData) string {
+	var sb strings.Builder
+	sz := len(data)
+	for i := 0; i < sz; {
+		cmd, n := PathDataNextCmd(data, &i)
+		if sb.Len() > 0 {
+			sb.WriteByte(' ')
+		}
+		sb.WriteString(strings.TrimPrefix(cmd.String(), "Pc"))
+		for np := 0; np < n && i < sz; np++ {
+			if np > 0 {
+				sb.WriteByte(',')
+			}
+			sb.WriteString(strconv.FormatFloat(float64(PathDataNext(data, &i)), 'g', -1, 32))
+		}
+	}
+	return sb.String()
+}
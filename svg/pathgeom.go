@@ -0,0 +1,675 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package svg
+
+import (
+	"math"
+	"sort"
+
+	"github.com/chewxy/math32"
+	"github.com/goki/gi/gi"
+)
+
+// PathFlatTol is the maximum distance, in transformed coordinates, between
+// the curves and arcs of path data and the line segments that approximate
+// them when the path is flattened
+var PathFlatTol = float32(0.05)
+
+// pathFlatMaxSegs is the maximum number of line segments that one curve or
+// arc is flattened into
+const pathFlatMaxSegs = 1024
+
+// PathPoly is one subpath of path data, flattened into a polyline
+type PathPoly struct {
+	Pts    []gi.Vec2D `desc:"points of the polyline"`
+	Closed bool       `desc:"whether the subpath is closed by a Z command, which joins its last point back to its first"`
+}
+
+func vecCross(a, b gi.Vec2D) float32 {
+	return a.X*b.Y - a.Y*b.X
+}
+
+func vecDot(a, b gi.Vec2D) float32 {
+	return a.X*b.X + a.Y*b.Y
+}
+
+func vecLen(a gi.Vec2D) float32 {
+	return math32.Sqrt(a.X*a.X + a.Y*a.Y)
+}
+
+// flatSegs returns the number of segments needed to flatten a curve whose
+// second derivative is at most dd, for given tolerance
+func flatSegs(dd, tol float32) int {
+	n := int(math32.Ceil(math32.Sqrt(dd / tol)))
+	if n < 1 {
+		return 1
+	}
+	if n > pathFlatMaxSegs {
+		return pathFlatMaxSegs
+	}
+	return n
+}
+
+// PathDataFlatten flattens path data, transformed by given transform, into
+// polylines, one per subpath -- curves and arcs are approximated by line
+// segments to within PathFlatTol
+func PathDataFlatten(data []PathData, xform gi.Matrix2D) []PathPoly {
+	tol := PathFlatTol
+	if tol <= 0 {
+		tol = 0.05
+	}
+	scx, scy := xform.ExtractScale()
+	sc := math32.Max(math32.Abs(scx), math32.Abs(scy))
+
+	ad := PathDataAbs(data)
+	var polys []PathPoly
+	cp := -1             // current poly, -1 if none
+	var st, cur gi.Vec2D // start of subpath and current point, untransformed
+	xf := func(p gi.Vec2D) gi.Vec2D {
+		return xform.TransformPointVec2D(p)
+	}
+	add := func(p gi.Vec2D) {
+		if cp < 0 {
+			polys = append(polys, PathPoly{Pts: []gi.Vec2D{xf(cur)}})
+			cp = len(polys) - 1
+		}
+		polys[cp].Pts = append(polys[cp].Pts, p)
+	}
+	pt := func(i int) gi.Vec2D {
+		return gi.Vec2D{float32(ad[i]), float32(ad[i+1])}
+	}
+	sz := len(ad)
+	for i := 0; i < sz; {
+		cmd, n := PathDataNextCmd(ad, &i)
+		if i+n > sz {
+			break
+		}
+		switch cmd {
+		case PcM:
+			st = pt(i)
+			cur = st
+			polys = append(polys, PathPoly{Pts: []gi.Vec2D{xf(st)}})
+			cp = len(polys) - 1
+		case PcL:
+			cur = pt(i)
+			add(xf(cur))
+		case PcQ:
+			p0, p1, p2 := xf(cur), xf(pt(i)), xf(pt(i+2))
+			dd := vecLen(p0.Sub(p1.MulVal(2)).Add(p2))
+			ns := flatSegs(0.25*dd, tol)
+			for k := 1; k <= ns; k++ {
+				t := float32(k) / float32(ns)
+				mt := 1 - t
+				add(p0.MulVal(mt * mt).Add(p1.MulVal(2 * mt * t)).Add(p2.MulVal(t * t)))
+			}
+			cur = pt(i + 2)
+		case PcC:
+			p0, p1, p2, p3 := xf(cur), xf(pt(i)), xf(pt(i+2)), xf(pt(i+4))
+			dd := math32.Max(vecLen(p0.Sub(p1.MulVal(2)).Add(p2)), vecLen(p1.Sub(p2.MulVal(2)).Add(p3)))
+			ns := flatSegs(0.75*dd, tol)
+			for k := 1; k <= ns; k++ {
+				t := float32(k) / float32(ns)
+				mt := 1 - t
+				add(p0.MulVal(mt * mt * mt).Add(p1.MulVal(3 * mt * mt * t)).Add(p2.MulVal(3 * mt * t * t)).Add(p3.MulVal(t * t * t)))
+			}
+			cur = pt(i + 4)
+		case PcA:
+			end := pt(i + 5)
+			flattenArc(cur, end, float32(ad[i]), float32(ad[i+1]), float32(ad[i+2]), ad[i+3] != 0, ad[i+4] != 0, sc, tol, func(p gi.Vec2D) {
+				add(xf(p))
+			})
+			cur = end
+		case PcZ:
+			if cp >= 0 {
+				polys[cp].Closed = true
+			}
+			cur = st
+			cp = -1
+		}
+		i += n
+	}
+	return polys
+}
+
+// flattenArc flattens the svg elliptical arc from st to end, calling add for
+// each point after st -- sc is the scaling of the transform that the points
+// are subject to
+func flattenArc(st, end gi.Vec2D, rx, ry, ang float32, largeArc, sweep bool, sc, tol float32, add func(p gi.Vec2D)) {
+	if st == end {
+		return
+	}
+	rx, ry = math32.Abs(rx), math32.Abs(ry)
+	if rx == 0 || ry == 0 {
+		add(end)
+		return
+	}
+	phi := ang * math.Pi / 180
+	cx, cy := gi.FindEllipseCenter(&rx, &ry, phi, st.X, st.Y, end.X, end.Y, sweep, largeArc)
+	cos, sin := math32.Cos(phi), math32.Sin(phi)
+	eta := func(p gi.Vec2D) float32 {
+		dx, dy := p.X-cx, p.Y-cy
+		ux, uy := dx*cos+dy*sin, -dx*sin+dy*cos
+		return math32.Atan2(uy/ry, ux/rx)
+	}
+	e0 := eta(st)
+	de := eta(end) - e0
+	if sweep && de < 0 {
+		de += 2 * math.Pi
+	} else if !sweep && de > 0 {
+		de -= 2 * math.Pi
+	}
+	r := math32.Max(rx, ry) * sc
+	step := float32(math.Pi / 2)
+	if tol < r {
+		step = math32.Min(step, 2*math32.Acos(1-tol/r))
+	}
+	ns := int(math32.Ceil(math32.Abs(de) / step))
+	if ns < 1 {
+		ns = 1
+	} else if ns > pathFlatMaxSegs {
+		ns = pathFlatMaxSegs
+	}
+	for k := 1; k < ns; k++ {
+		e := e0 + de*float32(k)/float32(ns)
+		ex, ey := rx*math32.Cos(e), ry*math32.Sin(e)
+		add(gi.Vec2D{cx + ex*cos - ey*sin, cy + ex*sin + ey*cos})
+	}
+	add(end)
+}
+
+/////////////////////////////////////////////////////////////////////////////
+//   PathGeom
+
+// PathGeom is the geometry of path data flattened into line segments, for
+// measuring the path and testing points against it
+type PathGeom struct {
+	Polys  []PathPoly    `desc:"the subpaths, flattened into polylines"`
+	Segs   []PathGeomSeg `desc:"the non-empty line segments of the polylines, in order, including the segments that close closed subpaths"`
+	Length float32       `desc:"total length of the path -- the sum of the lengths of the segments"`
+}
+
+// PathGeomSeg is one line segment of a PathGeom
+type PathGeomSeg struct {
+	Start, End gi.Vec2D
+	Dist       float32 `desc:"distance along the path at Start"`
+	Poly       int     `desc:"index of the polyline that the segment is part of"`
+}
+
+// NewPathGeom returns the geometry of given path data, transformed by given
+// transform
+func NewPathGeom(data []PathData, xform gi.Matrix2D) *PathGeom {
+	pg := &PathGeom{Polys: PathDataFlatten(data, xform)}
+	for pi := range pg.Polys {
+		pp := &pg.Polys[pi]
+		np := len(pp.Pts)
+		for i := 1; i < np; i++ {
+			pg.addSeg(pp.Pts[i-1], pp.Pts[i], pi)
+		}
+		if pp.Closed && np > 1 {
+			pg.addSeg(pp.Pts[np-1], pp.Pts[0], pi)
+		}
+	}
+	return pg
+}
+
+func (pg *PathGeom) addSeg(st, ed gi.Vec2D, poly int) {
+	ln := st.Distance(ed)
+	if ln == 0 {
+		return
+	}
+	pg.Segs = append(pg.Segs, PathGeomSeg{Start: st, End: ed, Dist: pg.Length, Poly: poly})
+	pg.Length += ln
+}
+
+// segAt returns the segment at given distance along the path -- must have
+// some segments
+func (pg *PathGeom) segAt(dist float32) *PathGeomSeg {
+	i := sort.Search(len(pg.Segs), func(i int) bool {
+		return pg.Segs[i].Dist > dist
+	}) - 1
+	if i < 0 {
+		i = 0
+	}
+	return &pg.Segs[i]
+}
+
+// PointAt returns the point at given distance along the path -- distances
+// beyond the ends of the path extend its first or last segment
+func (pg *PathGeom) PointAt(dist float32) gi.Vec2D {
+	if len(pg.Segs) == 0 {
+		if len(pg.Polys) > 0 && len(pg.Polys[0].Pts) > 0 {
+			return pg.Polys[0].Pts[0]
+		}
+		return gi.Vec2DZero
+	}
+	sg := pg.segAt(dist)
+	ln := sg.Start.Distance(sg.End)
+	return sg.Start.Add(sg.End.Sub(sg.Start).MulVal((dist - sg.Dist) / ln))
+}
+
+// AngleAt returns the angle of the direction of the path at given distance
+// along it, in radians
+func (pg *PathGeom) AngleAt(dist float32) float32 {
+	if len(pg.Segs) == 0 {
+		return 0
+	}
+	sg := pg.segAt(dist)
+	d := sg.End.Sub(sg.Start)
+	return math32.Atan2(d.Y, d.X)
+}
+
+// DistAt returns the distance along the path of the point on the path that
+// is nearest to given point, and the distance of given point from it --
+// the latter is infinite if the path has no segments
+func (pg *PathGeom) DistAt(pt gi.Vec2D) (dist, d float32) {
+	d = float32(math.Inf(1))
+	for i := range pg.Segs {
+		sg := &pg.Segs[i]
+		sd := sg.End.Sub(sg.Start)
+		ln := vecLen(sd)
+		t := vecDot(pt.Sub(sg.Start), sd) / (ln * ln)
+		if t < 0 {
+			t = 0
+		} else if t > 1 {
+			t = 1
+		}
+		sdist := pt.Distance(sg.Start.Add(sd.MulVal(t)))
+		if sdist < d {
+			d = sdist
+			dist = sg.Dist + t*ln
+		}
+	}
+	return
+}
+
+// NearStroke returns true if given point is within given distance of the
+// outline of the path -- e.g., half the stroke width for a point on the
+// stroke
+func (pg *PathGeom) NearStroke(pt gi.Vec2D, dist float32) bool {
+	_, d := pg.DistAt(pt)
+	return d <= dist
+}
+
+// Contains returns true if given point is inside the area filled by the path,
+// with given fill rule -- as for filling, open subpaths are implicitly
+// closed
+func (pg *PathGeom) Contains(pt gi.Vec2D, rule gi.FillRule) bool {
+	wn, cn := 0, 0
+	pg.fillEdges(func(a, b gi.Vec2D) {
+		if a.Y <= pt.Y {
+			if b.Y > pt.Y && vecCross(b.Sub(a), pt.Sub(a)) > 0 {
+				wn++
+				cn++
+			}
+		} else if b.Y <= pt.Y && vecCross(b.Sub(a), pt.Sub(a)) < 0 {
+			wn--
+			cn++
+		}
+	})
+	if rule == gi.FillRuleEvenOdd {
+		return cn%2 == 1
+	}
+	return wn != 0
+}
+
+// fillEdges calls fun for each edge of the area filled by the path, which
+// includes the edges that implicitly close open subpaths
+func (pg *PathGeom) fillEdges(fun func(a, b gi.Vec2D)) {
+	for _, pp := range pg.Polys {
+		np := len(pp.Pts)
+		if np < 2 {
+			continue
+		}
+		for i := 1; i < np; i++ {
+			if pp.Pts[i-1] != pp.Pts[i] {
+				fun(pp.Pts[i-1], pp.Pts[i])
+			}
+		}
+		if pp.Pts[np-1] != pp.Pts[0] {
+			fun(pp.Pts[np-1], pp.Pts[0])
+		}
+	}
+}
+
+// PathDataLength returns the total length of the path
+func PathDataLength(data []PathData) float32 {
+	return NewPathGeom(data, gi.Identity2D()).Length
+}
+
+// PathDataLengthTo returns the length of the path up to the path command at
+// given index in the data, i.e., the distance along the path of the point
+// that the previous command ends at -- an index within the values of a
+// command is rounded up to the start of the next command
+func PathDataLengthTo(data []PathData, idx int) float32 {
+	sz := len(data)
+	i := 0
+	for i < idx && i < sz {
+		_, n := PathDataNextCmd(data, &i)
+		i += n
+	}
+	if i > sz {
+		i = sz
+	}
+	return PathDataLength(data[:i])
+}
+
+// PathDataPointAt returns the point at given distance along the path, and the
+// angle of the direction of the path there, in radians
+func PathDataPointAt(data []PathData, dist float32) (gi.Vec2D, float32) {
+	pg := NewPathGeom(data, gi.Identity2D())
+	return pg.PointAt(dist), pg.AngleAt(dist)
+}
+
+// PathDataContains returns true if given point is inside the area filled by
+// the path, with given fill rule
+func PathDataContains(data []PathData, pt gi.Vec2D, rule gi.FillRule) bool {
+	return NewPathGeom(data, gi.Identity2D()).Contains(pt, rule)
+}
+
+// PathDataNearStroke returns true if given point is within given distance of
+// the outline of the path
+func PathDataNearStroke(data []PathData, pt gi.Vec2D, dist float32) bool {
+	return NewPathGeom(data, gi.Identity2D()).NearStroke(pt, dist)
+}
+
+/////////////////////////////////////////////////////////////////////////////
+//   Building path data
+
+// PathDataAdd appends given command and values to the path data
+func PathDataAdd(data []PathData, cmd PathCmds, vals ...float32) []PathData {
+	data = append(data, cmd.EncCmd(len(vals)))
+	for _, v := range vals {
+		data = append(data, PathData(v))
+	}
+	return data
+}
+
+// PathDataPoly returns path data for the polyline through given points,
+// closed if closed is true
+func PathDataPoly(pts []gi.Vec2D, closed bool) []PathData {
+	if len(pts) == 0 {
+		return nil
+	}
+	data := PathDataAdd(nil, PcM, pts[0].X, pts[0].Y)
+	if len(pts) > 1 {
+		vals := make([]float32, 0, 2*(len(pts)-1))
+		for _, p := range pts[1:] {
+			vals = append(vals, p.X, p.Y)
+		}
+		data = PathDataAdd(data, PcL, vals...)
+	}
+	if closed {
+		data = PathDataAdd(data, PcZ)
+	}
+	return data
+}
+
+// PathDataRect returns path data for the rectangle with given position and
+// size, with corners rounded with radius r if it is non-zero
+func PathDataRect(pos, size gi.Vec2D, r float32) []PathData {
+	x0, y0, x1, y1 := pos.X, pos.Y, pos.X+size.X, pos.Y+size.Y
+	r = math32.Min(r, math32.Min(math32.Abs(size.X), math32.Abs(size.Y))/2)
+	if r <= 0 {
+		return PathDataPoly([]gi.Vec2D{{x0, y0}, {x1, y0}, {x1, y1}, {x0, y1}}, true)
+	}
+	data := PathDataAdd(nil, PcM, x0+r, y0)
+	data = PathDataAdd(data, PcL, x1-r, y0)
+	data = PathDataAdd(data, PcA, r, r, 0, 0, 1, x1, y0+r)
+	data = PathDataAdd(data, PcL, x1, y1-r)
+	data = PathDataAdd(data, PcA, r, r, 0, 0, 1, x1-r, y1)
+	data = PathDataAdd(data, PcL, x0+r, y1)
+	data = PathDataAdd(data, PcA, r, r, 0, 0, 1, x0, y1-r)
+	data = PathDataAdd(data, PcL, x0, y0+r)
+	data = PathDataAdd(data, PcA, r, r, 0, 0, 1, x0+r, y0)
+	return PathDataAdd(data, PcZ)
+}
+
+// PathDataEllipse returns path data for the ellipse with given center and
+// radii
+func PathDataEllipse(c, radii gi.Vec2D) []PathData {
+	data := PathDataAdd(nil, PcM, c.X+radii.X, c.Y)
+	data = PathDataAdd(data, PcA, radii.X, radii.Y, 0, 1, 1, c.X-radii.X, c.Y)
+	data = PathDataAdd(data, PcA, radii.X, radii.Y, 0, 1, 1, c.X+radii.X, c.Y)
+	return PathDataAdd(data, PcZ)
+}
+
+/////////////////////////////////////////////////////////////////////////////
+//   Boolean operations
+
+// PathDataUnion returns path data for the union of the areas filled by paths
+// a and b, each filled with given fill rule
+func PathDataUnion(a, b []PathData, rule gi.FillRule) []PathData {
+	return pathDataBool(a, b, rule, func(ina, inb bool) bool { return ina || inb })
+}
+
+// PathDataIntersect returns path data for the intersection of the areas
+// filled by paths a and b, each filled with given fill rule
+func PathDataIntersect(a, b []PathData, rule gi.FillRule) []PathData {
+	return pathDataBool(a, b, rule, func(ina, inb bool) bool { return ina && inb })
+}
+
+// PathDataDifference returns path data for the area filled by path a that is
+// not filled by path b, each filled with given fill rule
+func PathDataDifference(a, b []PathData, rule gi.FillRule) []PathData {
+	return pathDataBool(a, b, rule, func(ina, inb bool) bool { return ina && !inb })
+}
+
+// pathEdge is a directed edge of the outline of an area
+type pathEdge struct {
+	A, B gi.Vec2D
+}
+
+// pathEdgeSplit is a point where a pathEdge is split, at given fraction t
+// along it
+type pathEdgeSplit struct {
+	t  float32
+	pt gi.Vec2D
+}
+
+// pathDataBool computes a boolean operation on the filled areas of paths a
+// and b: both are flattened, all their edges are split where they cross,
+// and each piece is kept where the result of op differs on its two sides,
+// oriented with the resulting area on its left -- the pieces are then
+// joined into closed subpaths.  The result fills correctly with either fill
+// rule, and curves are returned as flattened line segments.
+func pathDataBool(a, b []PathData, rule gi.FillRule, op func(ina, inb bool) bool) []PathData {
+	ga := NewPathGeom(a, gi.Identity2D())
+	gb := NewPathGeom(b, gi.Identity2D())
+	var edges []pathEdge
+	addEdge := func(p, q gi.Vec2D) {
+		edges = append(edges, pathEdge{p, q})
+	}
+	ga.fillEdges(addEdge)
+	gb.fillEdges(addEdge)
+	if len(edges) == 0 {
+		return nil
+	}
+	var min, max gi.Vec2D
+	for i, e := range edges {
+		if i == 0 {
+			min, max = e.A, e.A
+		}
+		min.SetMin(e.A.Min(e.B))
+		max.SetMax(e.A.Max(e.B))
+	}
+	diag := min.Distance(max)
+
+	splits := pathEdgeSplits(edges)
+	off := 1.0e-4 * (1 + diag)
+	in := func(p gi.Vec2D) bool {
+		return op(ga.Contains(p, rule), gb.Contains(p, rule))
+	}
+	var res []pathEdge
+	have := map[pathEdge]bool{}
+	for i, e := range edges {
+		sp := splits[i]
+		prv := e.A
+		for k := 0; k <= len(sp); k++ {
+			nxt := e.B
+			if k < len(sp) {
+				nxt = sp[k].pt
+			}
+			if nxt == prv {
+				continue
+			}
+			d := nxt.Sub(prv)
+			nrm := gi.Vec2D{-d.Y, d.X}.MulVal(off / vecLen(d))
+			mid := prv.Add(nxt).MulVal(0.5)
+			l, r := in(mid.Add(nrm)), in(mid.Sub(nrm))
+			if l != r {
+				pe := pathEdge{prv, nxt}
+				if r {
+					pe = pathEdge{nxt, prv}
+				}
+				if !have[pe] {
+					have[pe] = true
+					res = append(res, pe)
+				}
+			}
+			prv = nxt
+		}
+	}
+	return pathEdgesChain(res, 1.0e-5*(1+diag))
+}
+
+// pathEdgeSplits returns, for each edge, the points where other edges cross
+// or touch it, sorted along it
+func pathEdgeSplits(edges []pathEdge) [][]pathEdgeSplit {
+	const teps = 1.0e-6
+	splits := make([][]pathEdgeSplit, len(edges))
+	addSplit := func(i int, t float32, pt gi.Vec2D) {
+		if t > teps && t < 1-teps {
+			splits[i] = append(splits[i], pathEdgeSplit{t, pt})
+		}
+	}
+	// param returns the fraction along edge e of point p on it
+	param := func(e pathEdge, p gi.Vec2D) float32 {
+		d := e.B.Sub(e.A)
+		return vecDot(p.Sub(e.A), d) / vecDot(d, d)
+	}
+	for i := range edges {
+		ei := edges[i]
+		ri := ei.B.Sub(ei.A)
+		imin, imax := ei.A.Min(ei.B), ei.A.Max(ei.B)
+		for j := i + 1; j < len(edges); j++ {
+			ej := edges[j]
+			jmin, jmax := ej.A.Min(ej.B), ej.A.Max(ej.B)
+			if jmin.X > imax.X || jmax.X < imin.X || jmin.Y > imax.Y || jmax.Y < imin.Y {
+				continue
+			}
+			rj := ej.B.Sub(ej.A)
+			den := vecCross(ri, rj)
+			dab := ej.A.Sub(ei.A)
+			if math32.Abs(den) <= 1.0e-6*vecLen(ri)*vecLen(rj) { // parallel
+				if math32.Abs(vecCross(dab, ri)) > 1.0e-6*vecLen(ri)*vecLen(dab) {
+					continue
+				}
+				// collinear: split each at the end points of the other
+				addSplit(i, param(ei, ej.A), ej.A)
+				addSplit(i, param(ei, ej.B), ej.B)
+				addSplit(j, param(ej, ei.A), ei.A)
+				addSplit(j, param(ej, ei.B), ei.B)
+				continue
+			}
+			t := vecCross(dab, rj) / den
+			u := vecCross(dab, ri) / den
+			if t < -teps || t > 1+teps || u < -teps || u > 1+teps {
+				continue
+			}
+			// use the end points themselves where they are on the other edge,
+			// so that the pieces join exactly
+			pt := ei.A.Add(ri.MulVal(t))
+			switch {
+			case t <= teps:
+				pt = ei.A
+			case t >= 1-teps:
+				pt = ei.B
+			case u <= teps:
+				pt = ej.A
+			case u >= 1-teps:
+				pt = ej.B
+			}
+			addSplit(i, t, pt)
+			addSplit(j, u, pt)
+		}
+	}
+	for _, sp := range splits {
+		sort.Slice(sp, func(a, b int) bool { return sp[a].t < sp[b].t })
+	}
+	return splits
+}
+
+// pathEdgesChain joins directed edges into closed subpaths, returned as path
+// data -- end points within eps of each other are joined where there is no
+// exact match
+func pathEdgesChain(edges []pathEdge, eps float32) []PathData {
+	from := make(map[gi.Vec2D][]int, len(edges))
+	for i, e := range edges {
+		from[e.A] = append(from[e.A], i)
+	}
+	used := make([]bool, len(edges))
+	next := func(p gi.Vec2D) int {
+		for _, i := range from[p] {
+			if !used[i] {
+				return i
+			}
+		}
+		best, bd := -1, eps
+		for i, e := range edges {
+			if !used[i] {
+				if d := e.A.Distance(p); d <= bd {
+					best, bd = i, d
+				}
+			}
+		}
+		return best
+	}
+	var data []PathData
+	for i, e := range edges {
+		if used[i] {
+			continue
+		}
+		used[i] = true
+		pts := []gi.Vec2D{e.A}
+		cur := e.B
+		for cur.Distance(e.A) > eps {
+			j := next(cur)
+			if j < 0 {
+				break
+			}
+			used[j] = true
+			pts = append(pts, cur)
+			cur = edges[j].B
+		}
+		pts = pathPolySimplify(pts)
+		if len(pts) >= 3 {
+			data = append(data, PathDataPoly(pts, true)...)
+		}
+	}
+	return data
+}
+
+// pathPolySimplify removes the points of a closed polygon that are on the
+// straight line between their neighbors
+func pathPolySimplify(pts []gi.Vec2D) []gi.Vec2D {
+	straight := func(p, c, n gi.Vec2D) bool {
+		d1, d2 := c.Sub(p), n.Sub(c)
+		return math32.Abs(vecCross(d1, d2)) <= 1.0e-6*vecLen(d1)*vecLen(d2) && vecDot(d1, d2) >= 0
+	}
+	out := make([]gi.Vec2D, 0, len(pts))
+	for _, p := range pts {
+		for len(out) >= 2 && straight(out[len(out)-2], out[len(out)-1], p) {
+			out = out[:len(out)-1]
+		}
+		out = append(out, p)
+	}
+	for len(out) >= 3 && straight(out[len(out)-2], out[len(out)-1], out[0]) {
+		out = out[:len(out)-1]
+	}
+	for len(out) >= 3 && straight(out[len(out)-1], out[0], out[1]) {
+		out = out[1:]
+	}
+	return out
+}
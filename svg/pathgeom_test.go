@@ -0,0 +1,171 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package svg
+
+import (
+	"math"
+	"testing"
+
+	"github.com/chewxy/math32"
+	"github.com/goki/gi/gi"
+)
+
+// testPathArea returns the area filled by given path data, which must not
+// have subpaths that overlap other than as holes, as returned by the boolean
+// operations
+func testPathArea(data []PathData) float32 {
+	var area float32
+	for _, pp := range PathDataFlatten(data, gi.Identity2D()) {
+		np := len(pp.Pts)
+		for i := range pp.Pts {
+			a, b := pp.Pts[i], pp.Pts[(i+1)%np]
+			area += vecCross(a, b) / 2
+		}
+	}
+	return math32.Abs(area)
+}
+
+func testRect(x, y, w, h float32) []PathData {
+	return PathDataRect(gi.Vec2D{x, y}, gi.Vec2D{w, h}, 0)
+}
+
+func TestPathDataBool(t *testing.T) {
+	a := testRect(0, 0, 10, 10)
+	over := testRect(5, 5, 10, 10)
+	disj := testRect(20, 0, 10, 10)
+	union := func(a, b []PathData) []PathData { return PathDataUnion(a, b, gi.FillRuleNonZero) }
+	isect := func(a, b []PathData) []PathData { return PathDataIntersect(a, b, gi.FillRuleNonZero) }
+	diff := func(a, b []PathData) []PathData { return PathDataDifference(a, b, gi.FillRuleNonZero) }
+	tests := []struct {
+		name   string
+		res    []PathData
+		area   float32
+		nPolys int
+		in     []gi.Vec2D
+		out    []gi.Vec2D
+	}{
+		{"union overlapping", union(a, over), 175, 1,
+			[]gi.Vec2D{{2, 2}, {7, 7}, {12, 12}}, []gi.Vec2D{{12, 2}, {2, 12}, {20, 20}}},
+		{"intersect overlapping", isect(a, over), 25, 1,
+			[]gi.Vec2D{{7, 7}, {9, 6}}, []gi.Vec2D{{2, 2}, {12, 12}, {12, 2}}},
+		{"difference overlapping", diff(a, over), 75, 1,
+			[]gi.Vec2D{{2, 2}, {8, 2}, {2, 8}}, []gi.Vec2D{{7, 7}, {12, 12}}},
+		{"difference reversed", diff(over, a), 75, 1,
+			[]gi.Vec2D{{12, 12}, {12, 7}}, []gi.Vec2D{{7, 7}, {2, 2}}},
+		{"union disjoint", union(a, disj), 200, 2,
+			[]gi.Vec2D{{5, 5}, {25, 5}}, []gi.Vec2D{{15, 5}, {35, 5}}},
+		{"intersect disjoint", isect(a, disj), 0, 0,
+			nil, []gi.Vec2D{{5, 5}, {25, 5}}},
+		{"difference disjoint", diff(a, disj), 100, 1,
+			[]gi.Vec2D{{5, 5}}, []gi.Vec2D{{25, 5}}},
+		{"difference inside", diff(a, testRect(2, 2, 4, 4)), 84, 2,
+			[]gi.Vec2D{{1, 1}, {8, 8}}, []gi.Vec2D{{4, 4}}},
+	}
+	for _, tst := range tests {
+		if area := testPathArea(tst.res); math32.Abs(area-tst.area) > 1.0e-3 {
+			t.Errorf("%v: area = %v, want: %v", tst.name, area, tst.area)
+		}
+		if np := len(PathDataFlatten(tst.res, gi.Identity2D())); np != tst.nPolys {
+			t.Errorf("%v: %v subpaths, want: %v", tst.name, np, tst.nPolys)
+		}
+		for _, rule := range []gi.FillRule{gi.FillRuleNonZero, gi.FillRuleEvenOdd} {
+			for _, pt := range tst.in {
+				if !PathDataContains(tst.res, pt, rule) {
+					t.Errorf("%v: %v does not contain %v", tst.name, rule, pt)
+				}
+			}
+			for _, pt := range tst.out {
+				if PathDataContains(tst.res, pt, rule) {
+					t.Errorf("%v: %v contains %v", tst.name, rule, pt)
+				}
+			}
+		}
+	}
+}
+
+func TestPathDataLength(t *testing.T) {
+	tests := []struct {
+		name string
+		data []PathData
+		want float32
+		tol  float32
+	}{
+		{"line", PathDataPoly([]gi.Vec2D{{1, 2}, {31, 42}}, false), 50, 1.0e-4},
+		{"polyline", PathDataPoly([]gi.Vec2D{{0, 0}, {3, 4}, {3, 10}}, false), 11, 1.0e-4},
+		{"rect", testRect(5, 5, 10, 20), 60, 1.0e-4},
+		{"circle", PathDataEllipse(gi.Vec2D{50, 50}, gi.Vec2D{10, 10}), 2 * math.Pi * 10, 0.3},
+		{"rounded rect", PathDataRect(gi.Vec2D{0, 0}, gi.Vec2D{20, 20}, 5), 40 + 2*math.Pi*5, 0.2},
+	}
+	for _, tst := range tests {
+		if got := PathDataLength(tst.data); math32.Abs(got-tst.want) > tst.tol {
+			t.Errorf("%v: length = %v, want: %v", tst.name, got, tst.want)
+		}
+	}
+	// points along the line
+	line := PathDataPoly([]gi.Vec2D{{0, 0}, {30, 40}}, false)
+	if pt, ang := PathDataPointAt(line, 25); pt.Distance(gi.Vec2D{15, 20}) > 1.0e-4 || math32.Abs(ang-math32.Atan2(4, 3)) > 1.0e-4 {
+		t.Errorf("point at 25 along line = %v angle: %v, want: (15, 20) angle: %v", pt, ang, math32.Atan2(4, 3))
+	}
+}
+
+func TestPathDataContains(t *testing.T) {
+	// a pentagram, drawn as one self-intersecting path, whose center is
+	// wound twice
+	var star []gi.Vec2D
+	for i := 0; i < 5; i++ {
+		a := -math.Pi/2 + float64(i)*4*math.Pi/5
+		star = append(star, gi.Vec2D{float32(50 + 40*math.Cos(a)), float32(50 + 40*math.Sin(a))})
+	}
+	starData := PathDataPoly(star, true)
+	// two overlapping squares in the same direction, whose overlap is also
+	// wound twice
+	squares := append(testRect(0, 0, 10, 10), testRect(5, 5, 10, 10)...)
+	// an open path is implicitly closed
+	open := PathDataPoly([]gi.Vec2D{{0, 0}, {10, 0}, {10, 10}}, false)
+	tests := []struct {
+		name    string
+		data    []PathData
+		pt      gi.Vec2D
+		nonZero bool
+		evenOdd bool
+	}{
+		{"star center", starData, gi.Vec2D{50, 50}, true, false},
+		{"star point", starData, gi.Vec2D{50, 15}, true, true},
+		{"star outside", starData, gi.Vec2D{15, 15}, false, false},
+		{"squares overlap", squares, gi.Vec2D{7, 7}, true, false},
+		{"squares single", squares, gi.Vec2D{2, 2}, true, true},
+		{"squares outside", squares, gi.Vec2D{12, 2}, false, false},
+		{"open inside", open, gi.Vec2D{8, 2}, true, true},
+		{"open outside", open, gi.Vec2D{2, 8}, false, false},
+	}
+	for _, tst := range tests {
+		if got := PathDataContains(tst.data, tst.pt, gi.FillRuleNonZero); got != tst.nonZero {
+			t.Errorf("%v: nonzero contains %v = %v, want: %v", tst.name, tst.pt, got, tst.nonZero)
+		}
+		if got := PathDataContains(tst.data, tst.pt, gi.FillRuleEvenOdd); got != tst.evenOdd {
+			t.Errorf("%v: evenodd contains %v = %v, want: %v", tst.name, tst.pt, got, tst.evenOdd)
+		}
+	}
+}
+
+func TestPathDataNearStroke(t *testing.T) {
+	rect := testRect(0, 0, 10, 10)
+	tests := []struct {
+		pt   gi.Vec2D
+		dist float32
+		want bool
+	}{
+		{gi.Vec2D{5, 0}, 0.1, true},
+		{gi.Vec2D{5, -1}, 1, true},
+		{gi.Vec2D{5, -1.5}, 1, false},
+		{gi.Vec2D{5, 5}, 1, false},
+		{gi.Vec2D{11, 11}, 1.5, true}, // corner
+	}
+	for _, tst := range tests {
+		if got := PathDataNearStroke(rect, tst.pt, tst.dist); got != tst.want {
+			t.Errorf("near stroke %v within %v = %v, want: %v", tst.pt, tst.dist, got, tst.want)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package svg
+
+import (
+	"image"
+	"image/draw"
+
+	"github.com/chewxy/math32"
+	"github.com/goki/gi/gi"
+	"github.com/goki/ki/kit"
+)
+
+// Pattern is a paint server that renders its children as a tile that is
+// repeated to fill or stroke any node with a fill or stroke = url(#id)
+// property referring to it -- the tile is rendered just prior to rendering
+// each such node, see Render2DEffects.  The tile is rendered in render image
+// pixels, so rotation and skew in transforms are not applied to the tiling.
+type Pattern struct {
+	NodeBase
+	Pos          gi.Vec2D `xml:"{x,y}" desc:"position of the first tile, in Units"`
+	Size         gi.Vec2D `xml:"{width,height}" desc:"size of the tiles, in Units"`
+	Units        string   `xml:"patternUnits" desc:"units for Pos and Size: objectBoundingBox (default) or userSpaceOnUse"`
+	ContentUnits string   `xml:"patternContentUnits" desc:"units for the children if there is no ViewBox: userSpaceOnUse (default) or objectBoundingBox"`
+	ViewBox      ViewBox  `desc:"viewbox defines the coordinate system for the children within the tile, if set"`
+}
+
+var KiT_Pattern = kit.Types.AddType(&Pattern{}, nil)
+
+// Render2D does nothing -- a pattern is only rendered as a paint server,
+// via RenderPattern
+func (g *Pattern) Render2D() {
+}
+
+// RenderPattern renders the pattern tile for given node, with given
+// bounding box and transform, into pat -- satisfies gi.PatternServer
+func (g *Pattern) RenderPattern(pat *gi.TilePattern, node gi.Node2D, bbox image.Rectangle, xform gi.Matrix2D) {
+	pat.Image = nil
+	ni := node.AsNode2D()
+	if ni.Viewport == nil || g.Size.X <= 0 || g.Size.Y <= 0 {
+		return
+	}
+	rs := &ni.Viewport.Render
+	bbsz := gi.NewVec2DFmPoint(bbox.Size())
+	scx, scy := xform.ExtractScale()
+
+	// tile origin and size, in render image pixels
+	var org, tsz gi.Vec2D
+	if g.Units == "userSpaceOnUse" {
+		org = xform.TransformPointVec2D(g.Pos)
+		tsz = gi.Vec2D{g.Size.X * scx, g.Size.Y * scy}
+	} else {
+		org = gi.NewVec2DFmPoint(bbox.Min).Add(g.Pos.Mul(bbsz))
+		tsz = g.Size.Mul(bbsz)
+	}
+	tw := int(math32.Ceil(tsz.X))
+	th := int(math32.Ceil(tsz.Y))
+	if tw <= 0 || th <= 0 {
+		return
+	}
+
+	// content transform, relative to the tile origin
+	var cxf gi.Matrix2D
+	switch {
+	case g.ViewBox.Size.X > 0 && g.ViewBox.Size.Y > 0:
+		cxf = g.ViewBox.Transform(gi.Vec2DZero, tsz)
+	case g.ContentUnits == "objectBoundingBox":
+		cxf = gi.Scale2D(bbsz.X, bbsz.Y)
+	default:
+		cxf = gi.Scale2D(scx, scy)
+	}
+
+	initDefNodes(g.This())
+	rs.Lock()
+	rs.PushXForm(gi.Identity2D()) // saves current
+	rs.XForm = cxf
+	rs.PushXForm(g.Pnt.XForm)
+	rs.Unlock()
+
+	layer := image.NewRGBA(rs.Image.Bounds())
+	rs.PushImage(layer)
+	g.Render2DChildren()
+	rs.PopImage()
+
+	rs.Lock()
+	rs.PopXForm()
+	rs.PopXForm()
+	rs.Unlock()
+
+	tile := image.NewRGBA(image.Rect(0, 0, tw, th))
+	draw.Draw(tile, tile.Bounds(), layer, image.ZP, draw.Src)
+	pat.Image = tile
+	pat.Origin = image.Point{int(math32.Floor(org.X + 0.5)), int(math32.Floor(org.Y + 0.5))}
+}
+
+// PreparePatterns renders the tiles of any patterns used by the fill or
+// stroke of given node, prior to it being rendered -- this requires the
+// bounding box of the node, so if it has not yet been rendered, it is first
+// rendered into a scratch layer to obtain it
+func PreparePatterns(nii gi.Node2D) {
+	pntr, ok := nii.(gi.Painter)
+	if !ok {
+		return
+	}
+	pc := pntr.Paint()
+	fp := pc.FillStyle.Color.Pattern
+	sp := pc.StrokeStyle.Color.Pattern
+	if pc.FillStyle.Color.Source != gi.ImagePattern || fp == nil || fp.Server == nil {
+		fp = nil
+	}
+	if pc.StrokeStyle.Color.Source != gi.ImagePattern || sp == nil || sp.Server == nil {
+		sp = nil
+	}
+	if fp == nil && sp == nil {
+		return
+	}
+	ni := nii.AsNode2D()
+	if ni.Viewport == nil {
+		nii.Init2D()
+	}
+	rs := &ni.Viewport.Render
+	if ni.BBox.Empty() {
+		rs.PushImage(image.NewRGBA(rs.Image.Bounds()))
+		nii.Render2D()
+		rs.PopImage()
+	}
+	rs.Lock()
+	xf := nodeXForm(nii, rs)
+	rs.Unlock()
+	if fp != nil {
+		fp.Server.RenderPattern(fp, nii, ni.BBox, xf)
+	}
+	if sp != nil {
+		sp.Server.RenderPattern(sp, nii, ni.BBox, xf)
+	}
+}
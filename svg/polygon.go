@@ -58,3 +58,8 @@ func (g *Polygon) Render2D() {
 	g.Render2DChildren()
 	rs.PopXForm()
 }
+
+// ShapeData returns the path of the polygon, for hit testing -- see Shape
+func (g *Polygon) ShapeData() []PathData {
+	return PathDataPoly(g.Points, true)
+}
@@ -58,3 +58,8 @@ func (g *Polyline) Render2D() {
 	g.Render2DChildren()
 	rs.PopXForm()
 }
+
+// ShapeData returns the path of the polyline, for hit testing -- see Shape
+func (g *Polyline) ShapeData() []PathData {
+	return PathDataPoly(g.Points, false)
+}
@@ -37,3 +37,8 @@ func (g *Rect) Render2D() {
 	g.Render2DChildren()
 	rs.PopXForm()
 }
+
+// ShapeData returns the path of the rectangle, for hit testing -- see Shape
+func (g *Rect) ShapeData() []PathData {
+	return PathDataRect(g.Pos, g.Size, g.Radius.X)
+}
@@ -11,6 +11,7 @@ import (
 	"strings"
 
 	"github.com/goki/gi/gi"
+	"github.com/goki/ki"
 	"github.com/goki/ki/kit"
 )
 
@@ -20,13 +21,15 @@ import (
 // svg tag in html -- it provides its own bitmap for drawing into
 type SVG struct {
 	gi.Viewport2D
-	ViewBox ViewBox  `desc:"viewbox defines the coordinate system for the drawing"`
-	Norm    bool     `desc:"prop: norm = install a transform that renormalizes so that the specified ViewBox exactly fits within the allocated SVG size"`
-	InvertY bool     `desc:"prop: invert-y = when doing Norm transform, also flip the Y axis so that the smallest Y value is at the bottom of the SVG box, instead of being at the top as it is by default"`
-	Pnt     gi.Paint `json:"-" xml:"-" desc:"paint styles -- inherited by nodes"`
-	Defs    Group    `desc:"all defs defined elements go here (gradients, symbols, etc)"`
-	Title   string   `xml:"title" desc:"the title of the svg"`
-	Desc    string   `xml:"desc" desc:"the description of the svg"`
+	ViewBox  ViewBox   `desc:"viewbox defines the coordinate system for the drawing"`
+	Norm     bool      `desc:"prop: norm = install a transform that renormalizes so that the specified ViewBox exactly fits within the allocated SVG size"`
+	InvertY  bool      `desc:"prop: invert-y = when doing Norm transform, also flip the Y axis so that the smallest Y value is at the bottom of the SVG box, instead of being at the top as it is by default"`
+	Pnt      gi.Paint  `json:"-" xml:"-" desc:"paint styles -- inherited by nodes"`
+	Defs     Group     `desc:"all defs defined elements go here (gradients, symbols, etc)"`
+	Title    string    `xml:"title" desc:"the title of the svg"`
+	Desc     string    `xml:"desc" desc:"the description of the svg"`
+	Filename string    `json:"-" xml:"-" desc:"file that the svg was last opened from or saved to -- relative image links are resolved relative to it"`
+	Clock    AnimClock `json:"-" xml:"-" view:"-" desc:"clock that the animations in the svg are evaluated against -- see clock.go"`
 }
 
 var KiT_SVG = kit.Types.AddType(&SVG{}, nil)
@@ -105,6 +108,10 @@ func (svg *SVG) Style2D() {
 	if iv, ok := svg.Prop("invert-y"); ok {
 		svg.InvertY, _ = kit.ToBool(iv)
 	}
+	svg.ApplyAnimations()
+	if !svg.Clock.Running && !svg.Clock.Paused && svg.ParentWindow() != nil && svg.AnimationsActive() {
+		svg.StartAnimations()
+	}
 }
 
 func (svg *SVG) Layout2D(parBBox image.Rectangle, iter int) bool {
@@ -132,8 +139,20 @@ func (svg *SVG) Render2D() {
 	}
 }
 
+// Render2DChildren renders the children, applying any filter, clip-path or
+// mask properties they have -- see Render2DEffects
+func (svg *SVG) Render2DChildren() {
+	for _, kid := range svg.Kids {
+		Render2DEffects(kid)
+	}
+}
+
+// FindNamedElement returns the element with given name (id), which may
+// have a leading #, searching the Defs first, then all the elements of this
+// svg, and then its parents -- used for url(#name) references, and the
+// targets of animations etc -- returns nil if not found
 func (svg *SVG) FindNamedElement(name string) gi.Node2D {
-	name = strings.TrimPrefix(name, "#")
+	name = strings.TrimPrefix(strings.TrimSpace(name), "#")
 	if name == "" {
 		log.Printf("gi.SVG FindNamedElement: name is empty\n")
 		return nil
@@ -147,14 +166,27 @@ func (svg *SVG) FindNamedElement(name string) gi.Node2D {
 		return def.(gi.Node2D)
 	}
 
+	var el gi.Node2D
+	svg.FuncDownMeFirst(0, nil, func(k ki.Ki, level int, d interface{}) bool {
+		if el != nil {
+			return false
+		}
+		if k.Name() == name {
+			el, _ = gi.KiToNode2D(k)
+			return el == nil
+		}
+		return true
+	})
+	if el != nil {
+		return el
+	}
+
 	if svg.Par == nil {
-		log.Printf("gi.SVG FindNamedElement: could not find name: %v\n", name)
 		return nil
 	}
 	pgi, _ := gi.KiToNode2D(svg.Par)
 	if pgi != nil {
 		return pgi.FindNamedElement(name)
 	}
-	log.Printf("gi.SVG FindNamedElement: could not find name: %v\n", name)
 	return nil
 }
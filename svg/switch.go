@@ -0,0 +1,104 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package svg
+
+import (
+	"image"
+	"os"
+	"strings"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/ki"
+	"github.com/goki/ki/kit"
+)
+
+// Switch renders only the first of its children whose conditional
+// processing attributes (requiredExtensions, systemLanguage) evaluate to
+// true -- requiredFeatures is always treated as true, as in SVG 2
+type Switch struct {
+	NodeBase
+}
+
+var KiT_Switch = kit.Types.AddType(&Switch{}, nil)
+
+// SystemLanguage is the language used for evaluating systemLanguage
+// conditions in switch elements -- it defaults to the LANG environment
+// variable, e.g., en_US.UTF-8, and is matched on its language prefix
+var SystemLanguage = os.Getenv("LANG")
+
+// Active returns the child that the switch renders, or nil if none
+func (g *Switch) Active() gi.Node2D {
+	for _, kid := range g.Kids {
+		nii, _ := gi.KiToNode2D(kid)
+		if nii == nil {
+			continue
+		}
+		if SwitchCondition(kid) {
+			return nii
+		}
+	}
+	return nil
+}
+
+// SwitchCondition returns true if the conditional processing attributes
+// of given node are all satisfied
+func SwitchCondition(k ki.Ki) bool {
+	props := *k.Properties()
+	if _, ok := props["requiredExtensions"]; ok {
+		return false // no extensions are supported
+	}
+	if lng, ok := props["systemLanguage"]; ok {
+		ls, _ := lng.(string)
+		return matchLanguage(ls, SystemLanguage)
+	}
+	return true
+}
+
+// matchLanguage returns true if any of the comma-separated languages in
+// langs matches the system language sys -- a language matches if it is
+// equal to, or a prefix (ending in -) of, the system language
+func matchLanguage(langs, sys string) bool {
+	sys = strings.ToLower(sys)
+	if ci := strings.IndexAny(sys, ".@"); ci >= 0 {
+		sys = sys[:ci]
+	}
+	sys = strings.Replace(sys, "_", "-", -1)
+	if sys == "" || sys == "c" || sys == "posix" {
+		sys = "en"
+	}
+	for _, l := range strings.Split(langs, ",") {
+		l = strings.ToLower(strings.TrimSpace(l))
+		if l == "" {
+			continue
+		}
+		if l == sys || strings.HasPrefix(sys, l+"-") {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *Switch) BBox2D() image.Rectangle {
+	if act := g.Active(); act != nil {
+		return act.AsNode2D().BBox
+	}
+	return image.ZR
+}
+
+func (g *Switch) Render2D() {
+	if g.Viewport == nil {
+		g.This().(gi.Node2D).Init2D()
+	}
+	pc := &g.Pnt
+	rs := &g.Viewport.Render
+	rs.PushXFormLock(pc.XForm)
+
+	if act := g.Active(); act != nil {
+		Render2DEffects(act)
+	}
+	g.ComputeBBoxSVG()
+
+	rs.PopXFormLock()
+}
@@ -0,0 +1,23 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package svg
+
+import (
+	"github.com/goki/ki/kit"
+)
+
+// Symbol is a template for elements that are only rendered where they are
+// instantiated by a use element -- the use element's x, y, width and height
+// establish the region that the ViewBox of the symbol is mapped into
+type Symbol struct {
+	NodeBase
+	ViewBox ViewBox `desc:"viewbox defines the internal coordinate system for the drawing elements within the symbol"`
+}
+
+var KiT_Symbol = kit.Types.AddType(&Symbol{}, nil)
+
+// Render2D does nothing -- a symbol is only rendered where it is used
+func (g *Symbol) Render2D() {
+}
@@ -6,21 +6,26 @@ package svg
 
 import (
 	"image"
+	"strings"
+	"unicode"
 
+	"github.com/chewxy/math32"
 	"github.com/goki/gi/gi"
 	"github.com/goki/gi/units"
-	"github.com/goki/ki/ints"
+	"github.com/goki/ki"
 	"github.com/goki/ki/kit"
 )
 
-// Text renders SVG text-- it handles both text and tspan elements (a tspan is
-// just nested under a parent text)
+// Text renders SVG text -- it handles text, tspan and textPath elements:
+// tspan and textPath elements are nested under a parent text, which lays
+// out and renders all of the text within it, with each element using its
+// own styling, positioning and textLength -- see LayoutText
 type Text struct {
 	NodeBase
-	Pos          gi.Vec2D      `xml:"{x,y}" desc:"position of the left, baseline of the text"`
+	Pos          gi.Vec2D      `xml:"{x,y}" desc:"position of the left, baseline of the text -- only used for the top-level text element -- tspans use CharPosX, CharPosY"`
 	Width        float32       `xml:"width" desc:"width of text to render if using word-wrapping"`
 	Text         string        `xml:"text" desc:"text string to render"`
-	Render       gi.TextRender `xml:"-" json:"-" desc:"render version of text"`
+	Render       gi.TextRender `xml:"-" json:"-" desc:"render version of text -- for the top-level text element, this includes all of the text within it"`
 	CharPosX     []float32     `desc:"character positions along X axis, if specified"`
 	CharPosY     []float32     `desc:"character positions along Y axis, if specified"`
 	CharPosDX    []float32     `desc:"character delta-positions along X axis, if specified"`
@@ -28,92 +33,461 @@ type Text struct {
 	CharRots     []float32     `desc:"character rotations, if specified"`
 	TextLength   float32       `desc:"author's computed text length, if specified -- we attempt to match"`
 	AdjustGlyphs bool          `desc:"in attempting to match TextLength, should we adjust glyphs in addition to spacing?"`
+	TextPath     string        `xml:"href" desc:"for textPath elements: url of the Path that the text is laid out along"`
+	StartOffset  units.Value   `xml:"startOffset" desc:"for textPath elements: distance along the path at which the text starts -- percent is relative to the length of the path"`
 }
 
 var KiT_Text = kit.Types.AddType(&Text{}, nil)
 
+// IsRoot returns true if this is a top-level text element, which lays out
+// and renders all of the tspan and textPath elements within it
+func (g *Text) IsRoot() bool {
+	_, ok := g.Parent().(*Text)
+	return !ok
+}
+
+// IsTextPath returns true if this is a textPath element
+func (g *Text) IsTextPath() bool {
+	return g.TextPath != ""
+}
+
+// AddText adds given character data from an svg file to the text, using
+// the default xml:space handling: newlines and tabs are converted to
+// spaces, and runs of spaces are collapsed -- if the text already has
+// tspan children, it is added as a new anonymous tspan after them, so
+// that the order of the text is preserved
+func (g *Text) AddText(str string) {
+	str = strings.Map(func(r rune) rune {
+		if r == '\n' || r == '\r' || r == '\t' {
+			return ' '
+		}
+		return r
+	}, str)
+	for strings.Contains(str, "  ") {
+		str = strings.Replace(str, "  ", " ", -1)
+	}
+	if str == "" {
+		return
+	}
+	root := g
+	for !root.IsRoot() {
+		root = root.Parent().(*Text)
+	}
+	if str[0] == ' ' && strings.HasSuffix(root.AllText(), " ") {
+		str = str[1:]
+		if str == "" {
+			return
+		}
+	}
+	if len(g.Kids) == 0 {
+		g.Text += str
+		return
+	}
+	if lt, ok := g.Kids[len(g.Kids)-1].(*Text); ok && lt.IsAnon() {
+		lt.Text += str
+		return
+	}
+	ts := g.AddNewChild(KiT_Text, "tspan").(*Text)
+	ts.Text = str
+}
+
+// IsAnon returns true if this is an anonymous tspan holding text that
+// follows other tspans, without any styling or positioning of its own --
+// see AddText
+func (g *Text) IsAnon() bool {
+	return !g.IsRoot() && g.Nm == "tspan" && len(g.Kids) == 0 && len(g.Props) == 0 &&
+		g.Class == "" && !g.IsTextPath() && len(g.CharPosX) == 0 && len(g.CharPosY) == 0 &&
+		len(g.CharPosDX) == 0 && len(g.CharPosDY) == 0 && len(g.CharRots) == 0 && g.TextLength == 0
+}
+
+// AllText returns all of the text in this element, including its tspan and
+// textPath children
+func (g *Text) AllText() string {
+	str := g.Text
+	for _, kid := range g.Kids {
+		if kt, ok := kid.(*Text); ok {
+			str += kt.AllText()
+		}
+	}
+	return str
+}
+
+// TrimSpace removes leading and trailing space from the text of this
+// element and its children, as per default svg xml:space handling
+func (g *Text) TrimSpace() {
+	var els []*Text
+	g.FuncDownMeFirst(0, nil, func(k ki.Ki, level int, d interface{}) bool {
+		if kt, ok := k.(*Text); ok && kt.Text != "" {
+			els = append(els, kt)
+		}
+		return true
+	})
+	if len(els) == 0 {
+		return
+	}
+	els[0].Text = strings.TrimLeft(els[0].Text, " ")
+	els[len(els)-1].Text = strings.TrimRight(els[len(els)-1].Text, " ")
+}
+
 func (g *Text) BBox2D() image.Rectangle {
-	rs := &g.Viewport.Render
-	// todo: could be much more accurate..
-	return g.Pnt.BoundingBox(rs, g.Pos.X, g.Pos.Y, g.Pos.X+g.Render.Size.X, g.Pos.Y+g.Render.Size.Y)
+	bb := image.ZR
+	first := true
+	for si := range g.Render.Spans {
+		sr := &g.Render.Spans[si]
+		for i := range sr.Render {
+			if i >= len(sr.Text) || !unicode.IsPrint(sr.Text[i]) {
+				continue
+			}
+			rr := &sr.Render[i]
+			rp := sr.RelPos.Add(rr.RelPos)
+			var rb image.Rectangle
+			if rr.RotRad == 0 {
+				rb = image.Rect(int(math32.Floor(rp.X)), int(math32.Floor(rp.Y-0.8*rr.Size.Y)),
+					int(math32.Ceil(rp.X+rr.Size.X)), int(math32.Ceil(rp.Y+0.2*rr.Size.Y)))
+			} else {
+				r := math32.Max(rr.Size.X, rr.Size.Y)
+				rb = image.Rect(int(math32.Floor(rp.X-r)), int(math32.Floor(rp.Y-r)),
+					int(math32.Ceil(rp.X+r)), int(math32.Ceil(rp.Y+r)))
+			}
+			if first {
+				bb = rb
+				first = false
+			} else {
+				bb = bb.Union(rb)
+			}
+		}
+	}
+	return bb
 }
 
 func (g *Text) Render2D() {
 	if g.Viewport == nil {
 		g.This().(gi.Node2D).Init2D()
 	}
+	if !g.IsRoot() {
+		return // laid out and rendered by the top-level text
+	}
 	pc := &g.Pnt
 	rs := &g.Viewport.Render
 	rs.PushXForm(pc.XForm)
-	if len(g.Text) > 0 {
-		orgsz := pc.FontStyle.Size
-		pos := rs.XForm.TransformPointVec2D(gi.Vec2D{g.Pos.X, g.Pos.Y})
-		rot := rs.XForm.ExtractRot()
-		scx, scy := rs.XForm.ExtractScale()
-		scalex := scx / scy
-		if scalex == 1 {
-			scalex = 0
-		}
-		pc.FontStyle.OpenFont(&pc.UnContext) // use original size font
-		if !pc.FillStyle.Color.IsNil() {
-			pc.FontStyle.Color = pc.FillStyle.Color.Color
-		}
-		g.Render.SetString(g.Text, &pc.FontStyle, &pc.UnContext, &pc.TextStyle, true, rot, scalex)
-		g.Render.Size = g.Render.Size.Mul(gi.Vec2D{scx, scy})
-		if gi.IsAlignMiddle(pc.TextStyle.Align) || pc.TextStyle.Anchor == gi.AnchorMiddle {
-			pos.X -= g.Render.Size.X * .5
-		} else if gi.IsAlignEnd(pc.TextStyle.Align) || pc.TextStyle.Anchor == gi.AnchorEnd {
-			pos.X -= g.Render.Size.X
-		}
-		pc.FontStyle.Size = units.Value{orgsz.Val * scy, orgsz.Un, orgsz.Dots * scy} // rescale by y
-		pc.FontStyle.OpenFont(&pc.UnContext)
-		sr := &(g.Render.Spans[0])
-		sr.Render[0].Face = pc.FontStyle.Face // upscale
-		for i := range sr.Render {
-			sr.Render[i].RelPos = rs.XForm.TransformVectorVec2D(sr.Render[i].RelPos)
-			sr.Render[i].Size.Y *= scy
-			sr.Render[i].Size.X *= scx
-		}
-		pc.FontStyle.Size = orgsz
-		if len(g.CharPosX) > 0 {
-			mx := ints.MinInt(len(g.CharPosX), len(sr.Render))
-			for i := 0; i < mx; i++ {
-				// todo: this may not be fully correct, given relativity constraints
-				sr.Render[i].RelPos.X, _ = rs.XForm.TransformVector(g.CharPosX[i], 0)
+	g.LayoutText(rs)
+	if len(g.Render.Spans) > 0 {
+		g.Render.Render(rs, gi.Vec2DZero)
+		g.ComputeBBoxSVG()
+	}
+	rs.PopXForm()
+}
+
+/////////////////////////////////////////////////////////////////////////////
+//   Layout
+
+// textChar is the layout state for one character within a text element
+type textChar struct {
+	run    int       // index of run containing the char
+	ri     int       // rune index within run
+	adv    float32   // advance, in user units
+	scaleX float32   // glyph scaling from textLength
+	pos    gi.Vec2D  // position of baseline start, in user units -- for chars on a path, X is the distance along the path and Y the offset from it
+	rot    float32   // rotation from rotate attribute, in radians
+	chunk  bool      // starts a new text chunk, which text-anchor applies to
+	path   *PathGeom // path that the char is laid out along, if any
+}
+
+// textRun is the text of one element within a text element
+type textRun struct {
+	node  *Text
+	chain []*Text // the node and its parents up to the top-level text, innermost first
+	sr    gi.SpanRender
+}
+
+// LayoutText lays out the text of this top-level text element and all the
+// tspan and textPath elements within it, into Render, with all positions in
+// absolute render coordinates, using the current transform in rs.  Each
+// character's position is given by the x, y, dx, dy and rotate values of
+// the innermost element that has a value for it, text-anchor is applied to
+// each chunk of text that starts at an absolute position, textLength is
+// applied to each element that has it, and textPath elements lay out their
+// text along their path.
+func (g *Text) LayoutText(rs *gi.RenderState) {
+	var runs []*textRun
+	starts := map[*Text]int{}
+	ends := map[*Text]int{}
+	var chars []textChar
+	var collect func(t *Text, chain []*Text)
+	collect = func(t *Text, chain []*Text) {
+		chain = append([]*Text{t}, chain...)
+		starts[t] = len(chars)
+		if t.Viewport == nil {
+			t.This().(gi.Node2D).Init2D()
+		}
+		if t.Text != "" {
+			run := &textRun{node: t, chain: chain}
+			pc := &t.Pnt
+			pc.FontStyle.OpenFont(&pc.UnContext)
+			run.sr.SetString(t.Text, &pc.FontStyle, &pc.UnContext, true, 0, 0)
+			run.sr.SetRunePosLR(pc.TextStyle.LetterSpacing.Dots, pc.TextStyle.WordSpacing.Dots, pc.FontStyle.Ch, pc.TextStyle.TabSize)
+			rn := len(run.sr.Text)
+			for i := 0; i < rn; i++ {
+				adv := run.sr.LastPos.X - run.sr.Render[i].RelPos.X
+				if i < rn-1 {
+					adv = run.sr.Render[i+1].RelPos.X - run.sr.Render[i].RelPos.X
+				}
+				chars = append(chars, textChar{run: len(runs), adv: adv, scaleX: 1})
 			}
+			runs = append(runs, run)
 		}
-		if len(g.CharPosY) > 0 {
-			mx := ints.MinInt(len(g.CharPosY), len(sr.Render))
-			for i := 0; i < mx; i++ {
-				_, sr.Render[i].RelPos.Y = rs.XForm.TransformPoint(g.CharPosY[i], 0)
+		for _, kid := range t.Kids {
+			if kt, ok := kid.(*Text); ok {
+				collect(kt, chain)
 			}
 		}
-		if len(g.CharPosDX) > 0 {
-			mx := ints.MinInt(len(g.CharPosDX), len(sr.Render))
-			for i := 0; i < mx; i++ {
-				dx, _ := rs.XForm.TransformVector(g.CharPosDX[i], 0)
-				if i > 0 {
-					sr.Render[i].RelPos.X = sr.Render[i-1].RelPos.X + dx
-				} else {
-					sr.Render[i].RelPos.X = dx // todo: not sure this is right
+		ends[t] = len(chars)
+	}
+	collect(g, nil)
+	g.Render.Spans = g.Render.Spans[:0]
+	g.Render.Links = nil
+	if len(chars) == 0 {
+		return
+	}
+
+	// textLength, innermost elements first
+	var adjust func(t *Text)
+	adjust = func(t *Text) {
+		for _, kid := range t.Kids {
+			if kt, ok := kid.(*Text); ok {
+				adjust(kt)
+			}
+		}
+		st, ed := starts[t], ends[t]
+		if t.TextLength <= 0 || ed <= st {
+			return
+		}
+		var nat float32
+		for ci := st; ci < ed; ci++ {
+			nat += chars[ci].adv
+		}
+		if nat <= 0 {
+			return
+		}
+		if t.AdjustGlyphs {
+			sc := t.TextLength / nat
+			for ci := st; ci < ed; ci++ {
+				chars[ci].adv *= sc
+				chars[ci].scaleX *= sc
+			}
+		} else if ed-st > 1 {
+			extra := (t.TextLength - nat) / float32(ed-st-1)
+			for ci := st; ci < ed-1; ci++ {
+				chars[ci].adv += extra
+			}
+		}
+	}
+	adjust(g)
+
+	// positions
+	var cur gi.Vec2D
+	var curPath *PathGeom
+	var pathOrg gi.Vec2D // current position at start of path, relative to which path chars are positioned
+	var curPathEl *Text
+	for ci := range chars {
+		ch := &chars[ci]
+		run := runs[ch.run]
+		pel := pathElement(run.chain)
+		if pel != curPathEl {
+			if curPathEl != nil && curPath != nil {
+				// continue from end of path
+				cur = curPath.PointAt(cur.X - pathOrg.X)
+			}
+			curPathEl = pel
+			curPath = nil
+			ch.chunk = true
+			if pel != nil {
+				curPath = pel.pathPoly()
+				pathOrg = cur
+				if curPath != nil {
+					off := pel.StartOffset.Val
+					if pel.StartOffset.Un == units.Pct {
+						off = 0.01 * off * curPath.Length
+					}
+					pathOrg.X -= off
 				}
 			}
 		}
-		if len(g.CharPosDY) > 0 {
-			mx := ints.MinInt(len(g.CharPosDY), len(sr.Render))
-			for i := 0; i < mx; i++ {
-				dy, _ := rs.XForm.TransformVector(g.CharPosDY[i], 0)
-				if i > 0 {
-					sr.Render[i].RelPos.Y = sr.Render[i-1].RelPos.Y + dy
-				} else {
-					sr.Render[i].RelPos.Y = dy // todo: not sure this is right
+		if x, ok := charVal(ci, run.chain, starts, func(t *Text) []float32 { return t.charPosX() }, false); ok && pel == nil {
+			cur.X = x
+			ch.chunk = true
+		}
+		if y, ok := charVal(ci, run.chain, starts, func(t *Text) []float32 { return t.charPosY() }, false); ok && pel == nil {
+			cur.Y = y
+			ch.chunk = true
+		}
+		if dx, ok := charVal(ci, run.chain, starts, func(t *Text) []float32 { return t.CharPosDX }, false); ok {
+			cur.X += dx
+		}
+		if dy, ok := charVal(ci, run.chain, starts, func(t *Text) []float32 { return t.CharPosDY }, false); ok {
+			cur.Y += dy
+		}
+		if rot, ok := charVal(ci, run.chain, starts, func(t *Text) []float32 { return t.CharRots }, true); ok {
+			ch.rot = rot * math32.Pi / 180
+		}
+		if ci == 0 {
+			ch.chunk = true
+		}
+		ch.pos = cur
+		if curPath != nil {
+			ch.path = curPath
+			ch.pos = cur.Sub(pathOrg)
+		}
+		cur.X += ch.adv
+	}
+
+	// text-anchor for each chunk
+	for cs := 0; cs < len(chars); {
+		ce := cs + 1
+		for ce < len(chars) && !chars[ce].chunk {
+			ce++
+		}
+		anc := runs[chars[cs].run].node.Pnt.TextStyle.Anchor
+		if anc != gi.AnchorStart {
+			wd := chars[ce-1].pos.X + chars[ce-1].adv - chars[cs].pos.X
+			if anc == gi.AnchorMiddle {
+				wd *= 0.5
+			}
+			for ci := cs; ci < ce; ci++ {
+				chars[ci].pos.X -= wd
+			}
+		}
+		cs = ce
+	}
+
+	// render spans, in render coordinates
+	xrot := rs.XForm.ExtractRot()
+	scx, scy := rs.XForm.ExtractScale()
+	scalex := scx / scy
+	ci := 0
+	for _, run := range runs {
+		pc := &run.node.Pnt
+		fs := &pc.FontStyle
+		met := fs.Face.Metrics()
+		boff := pc.TextStyle.DominantBaseline.BaselineOffset(gi.FixedToFloat32(met.Ascent), gi.FixedToFloat32(met.Descent))
+		if !pc.FillStyle.Color.IsNil() {
+			fs.Color = pc.FillStyle.Color.Color
+		}
+		orgsz := fs.Size
+		fs.Size = units.Value{Val: orgsz.Val * scy, Un: orgsz.Un, Dots: orgsz.Dots * scy} // rescale by y
+		fs.OpenFont(&pc.UnContext)
+		var sr gi.SpanRender
+		sr.SetRunes(run.sr.Text, fs, &pc.UnContext, true, 0, 0)
+		fs.Size = orgsz
+		fs.OpenFont(&pc.UnContext)
+		for i := range sr.Render {
+			ch := &chars[ci]
+			ci++
+			rr := &sr.Render[i]
+			urr := &run.sr.Render[i]
+			up := ch.pos
+			up.Y += boff
+			rot := ch.rot
+			if ch.path != nil {
+				mid := up.X + 0.5*ch.adv
+				if mid < 0 || mid > ch.path.Length {
+					sr.Text[i] = 0 // not rendered
+					continue
 				}
+				ang := ch.path.AngleAt(mid)
+				dir := gi.Vec2D{math32.Cos(ang), math32.Sin(ang)}
+				nrm := gi.Vec2D{-dir.Y, dir.X}
+				up = ch.path.PointAt(mid).Sub(dir.MulVal(0.5 * ch.adv)).Add(nrm.MulVal(up.Y))
+				rot += ang
+			}
+			rr.RelPos = rs.XForm.TransformPointVec2D(up)
+			rr.RelPos.Y += urr.RelPos.Y * scy // super / subscript
+			rr.RotRad = xrot + rot
+			sx := scalex * ch.scaleX
+			if sx != 1 {
+				rr.ScaleX = sx
 			}
+			rr.Size = gi.Vec2D{ch.adv * scx, urr.Size.Y * scy}
 		}
-		// todo: TextLength, AdjustGlyphs -- also svg2 at least supports word wrapping!
-		g.Render.Render(rs, pos)
-		g.ComputeBBoxSVG()
+		g.Render.Spans = append(g.Render.Spans, sr)
 	}
-	g.Render2DChildren()
-	rs.PopXForm()
+	g.Render.Size = gi.NewVec2DFmPoint(g.BBox2D().Size())
+}
+
+// charVal returns the value for given char index from the innermost
+// element in chain that has one, using given function to get the values
+// of an element -- if last is true, the last value of an element applies to
+// all of its chars beyond the number of values
+func charVal(ci int, chain []*Text, starts map[*Text]int, vals func(t *Text) []float32, last bool) (float32, bool) {
+	for _, t := range chain {
+		vs := vals(t)
+		if len(vs) == 0 {
+			continue
+		}
+		idx := ci - starts[t]
+		if idx < len(vs) {
+			return vs[idx], true
+		}
+		if last {
+			return vs[len(vs)-1], true
+		}
+	}
+	return 0, false
+}
+
+// charPosX returns the x positions for the chars of the element -- for
+// the top-level text, Pos is used if CharPosX is not set
+func (g *Text) charPosX() []float32 {
+	if len(g.CharPosX) == 0 && g.IsRoot() {
+		return []float32{g.Pos.X}
+	}
+	return g.CharPosX
+}
+
+// charPosY returns the y positions for the chars of the element -- for
+// the top-level text, Pos is used if CharPosY is not set
+func (g *Text) charPosY() []float32 {
+	if len(g.CharPosY) == 0 && g.IsRoot() {
+		return []float32{g.Pos.Y}
+	}
+	return g.CharPosY
+}
+
+// pathElement returns the innermost textPath element in chain, if any
+func pathElement(chain []*Text) *Text {
+	for _, t := range chain {
+		if t.IsTextPath() {
+			return t
+		}
+	}
+	return nil
+}
+
+// PathNode returns the Path that this textPath element refers to, or nil
+// if not found
+func (g *Text) PathNode() *Path {
+	id := strings.TrimPrefix(strings.TrimSpace(g.TextPath), "#")
+	if id == "" {
+		return nil
+	}
+	svk := g.ParentByType(KiT_SVG, true)
+	if svk == nil {
+		return nil
+	}
+	pn, _ := svk.Embed(KiT_SVG).(*SVG).FindNamedElement(id).(*Path)
+	return pn
+}
+
+// pathPoly returns the flattened path of this textPath element, in the
+// user space of the text, or nil if the path is not found
+func (g *Text) pathPoly() *PathGeom {
+	pn := g.PathNode()
+	if pn == nil || len(pn.Data) < 2 {
+		return nil
+	}
+	pg := NewPathGeom(pn.Data, pn.Pnt.XForm)
+	if len(pg.Segs) == 0 {
+		return nil
+	}
+	return pg
 }
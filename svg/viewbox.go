@@ -4,7 +4,11 @@
 
 package svg
 
-import "github.com/goki/gi/gi"
+import (
+	"strings"
+
+	"github.com/goki/gi/gi"
+)
 
 ////////////////////////////////////////////////////////////////////////////////////////
 // ViewBox defines the SVG viewbox
@@ -16,8 +20,6 @@ type ViewBox struct {
 	PreserveAspectRatio ViewBoxPreserveAspectRatio `desc:"how to scale the view box within parent Viewport2D"`
 }
 
-// todo: need to implement the viewbox preserve aspect ratio logic!
-
 // Defaults returns viewbox to defaults
 func (vb *ViewBox) Defaults() {
 	vb.Min = gi.Vec2DZero
@@ -62,3 +64,102 @@ type ViewBoxPreserveAspectRatio struct {
 	Align       ViewBoxAlign       `svg:"align" desc:"how to align x,y coordinates within viewbox"`
 	MeetOrSlice ViewBoxMeetOrSlice `svg:"meetOrSlice" desc:"how to scale the view box relative to the viewport"`
 }
+
+// SetString sets the preserve aspect ratio from an svg preserveAspectRatio
+// attribute value, e.g., "xMidYMid meet" or "none"
+func (pa *ViewBoxPreserveAspectRatio) SetString(s string) {
+	pa.Align = XMid | YMid
+	pa.MeetOrSlice = Meet
+	for _, f := range strings.Fields(s) {
+		switch {
+		case f == "none":
+			pa.Align = None
+		case f == "meet":
+			pa.MeetOrSlice = Meet
+		case f == "slice":
+			pa.MeetOrSlice = Slice
+		case len(f) == 8:
+			pa.Align = 0
+			switch f[:4] {
+			case "xMin":
+				pa.Align |= XMin
+			case "xMax":
+				pa.Align |= XMax
+			default:
+				pa.Align |= XMid
+			}
+			switch f[4:] {
+			case "YMin":
+				pa.Align |= YMin
+			case "YMax":
+				pa.Align |= YMax
+			default:
+				pa.Align |= YMid
+			}
+		}
+	}
+}
+
+// String returns the svg preserveAspectRatio attribute value
+func (pa *ViewBoxPreserveAspectRatio) String() string {
+	if pa.Align&None != 0 {
+		return "none"
+	}
+	s := "xMid"
+	switch {
+	case pa.Align&XMin != 0:
+		s = "xMin"
+	case pa.Align&XMax != 0:
+		s = "xMax"
+	}
+	switch {
+	case pa.Align&YMin != 0:
+		s += "YMin"
+	case pa.Align&YMax != 0:
+		s += "YMax"
+	default:
+		s += "YMid"
+	}
+	if pa.MeetOrSlice == Slice {
+		s += " slice"
+	}
+	return s
+}
+
+// Transform returns the transform that maps the viewbox into the region of
+// given position and size, according to the PreserveAspectRatio -- if the
+// viewbox has no size, it just translates to pos
+func (vb *ViewBox) Transform(pos, size gi.Vec2D) gi.Matrix2D {
+	if vb.Size.X == 0 || vb.Size.Y == 0 {
+		return gi.Translate2D(pos.X, pos.Y)
+	}
+	sx := size.X / vb.Size.X
+	sy := size.Y / vb.Size.Y
+	pa := &vb.PreserveAspectRatio
+	tx := pos.X
+	ty := pos.Y
+	if pa.Align&None == 0 {
+		if (pa.MeetOrSlice == Meet) == (sx < sy) {
+			sy = sx
+		} else {
+			sx = sy
+		}
+		ex := size.X - vb.Size.X*sx
+		ey := size.Y - vb.Size.Y*sy
+		switch {
+		case pa.Align&XMin != 0:
+		case pa.Align&XMax != 0:
+			tx += ex
+		default:
+			tx += 0.5 * ex
+		}
+		switch {
+		case pa.Align&YMin != 0:
+		case pa.Align&YMax != 0:
+			ty += ey
+		default:
+			ty += 0.5 * ey
+		}
+	}
+	return gi.Translate2D(tx-vb.Min.X*sx, ty-vb.Min.Y*sy).Scale(sx, sy)
+}
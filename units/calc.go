@@ -0,0 +1,321 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package units
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Calc is a parsed CSS math expression, e.g., calc(100% - 2em), which can mix
+// values in different units, and is evaluated in a given Context -- the
+// calc, min, max and clamp functions are supported, with + - * / operators
+// and parentheses -- as in CSS, the terms of a sum must either all be lengths
+// or all be plain numbers, and products and quotients must have a plain
+// number on at least one side (on the right for quotients)
+type Calc struct {
+	Str  string
+	root *calcNode
+}
+
+// calcNode is one node of the expression tree of a Calc
+type calcNode struct {
+	op   string // "" for a value, else + - * / min max clamp
+	val  float32
+	un   Unit
+	num  bool // true if a plain number, without units
+	args []*calcNode
+}
+
+// IsCalc returns true if given string is a CSS math expression that should
+// be parsed with ParseCalc
+func IsCalc(str string) bool {
+	str = strings.ToLower(strings.TrimSpace(str))
+	for _, fn := range calcFuncs {
+		if strings.HasPrefix(str, fn+"(") {
+			return true
+		}
+	}
+	return false
+}
+
+var calcFuncs = []string{"calc", "min", "max", "clamp"}
+
+// ParseCalc parses given CSS math expression, e.g., "calc(100% - 2em)" or
+// "min(50vw, 20em)"
+func ParseCalc(str string) (*Calc, error) {
+	cp := &calcParser{src: strings.ToLower(strings.TrimSpace(str))}
+	nd, err := cp.primary()
+	if err == nil && cp.pos < len(cp.src) {
+		err = fmt.Errorf("unexpected %q", cp.src[cp.pos:])
+	}
+	if err == nil && nd.num {
+		err = fmt.Errorf("result is a number, not a length")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("units.ParseCalc: %v in: %v", err, str)
+	}
+	return &Calc{Str: strings.TrimSpace(str), root: nd}, nil
+}
+
+// ToDots evaluates the expression in given context, returning raw display
+// pixels (dots as in DPI)
+func (c *Calc) ToDots(ctxt *Context) float32 {
+	if c == nil || c.root == nil {
+		return 0
+	}
+	return c.root.eval(ctxt)
+}
+
+// String returns the original expression string
+func (c *Calc) String() string {
+	return c.Str
+}
+
+// eval returns the value of the node, in dots for lengths
+func (nd *calcNode) eval(ctxt *Context) float32 {
+	switch nd.op {
+	case "":
+		if nd.num {
+			return nd.val
+		}
+		return ctxt.ToDots(nd.val, nd.un)
+	case "+":
+		return nd.args[0].eval(ctxt) + nd.args[1].eval(ctxt)
+	case "-":
+		return nd.args[0].eval(ctxt) - nd.args[1].eval(ctxt)
+	case "*":
+		return nd.args[0].eval(ctxt) * nd.args[1].eval(ctxt)
+	case "/":
+		dv := nd.args[1].eval(ctxt)
+		if dv == 0 {
+			return 0
+		}
+		return nd.args[0].eval(ctxt) / dv
+	case "min", "max":
+		rv := nd.args[0].eval(ctxt)
+		for _, a := range nd.args[1:] {
+			av := a.eval(ctxt)
+			if (nd.op == "min" && av < rv) || (nd.op == "max" && av > rv) {
+				rv = av
+			}
+		}
+		return rv
+	case "clamp":
+		mn := nd.args[0].eval(ctxt)
+		rv := nd.args[1].eval(ctxt)
+		mx := nd.args[2].eval(ctxt)
+		if rv > mx {
+			rv = mx
+		}
+		if rv < mn {
+			rv = mn
+		}
+		return rv
+	}
+	return 0
+}
+
+// calcParser is a recursive-descent parser for Calc expressions
+type calcParser struct {
+	src string
+	pos int
+}
+
+func (cp *calcParser) skipSpace() {
+	for cp.pos < len(cp.src) && (cp.src[cp.pos] == ' ' || cp.src[cp.pos] == '\t' || cp.src[cp.pos] == '\n') {
+		cp.pos++
+	}
+}
+
+// peek returns the next non-space character, or 0 at the end
+func (cp *calcParser) peek() byte {
+	cp.skipSpace()
+	if cp.pos >= len(cp.src) {
+		return 0
+	}
+	return cp.src[cp.pos]
+}
+
+func (cp *calcParser) expect(c byte) error {
+	if cp.peek() != c {
+		if cp.pos >= len(cp.src) {
+			return fmt.Errorf("missing %q", c)
+		}
+		return fmt.Errorf("expected %q at %q", c, cp.src[cp.pos:])
+	}
+	cp.pos++
+	return nil
+}
+
+// sum parses terms separated by + and -
+func (cp *calcParser) sum() (*calcNode, error) {
+	nd, err := cp.product()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op := cp.peek()
+		if op != '+' && op != '-' {
+			return nd, nil
+		}
+		cp.pos++
+		rt, err := cp.product()
+		if err != nil {
+			return nil, err
+		}
+		if nd.num != rt.num {
+			return nil, fmt.Errorf("cannot mix numbers and lengths in %q", op)
+		}
+		nd = &calcNode{op: string(op), num: nd.num, args: []*calcNode{nd, rt}}
+	}
+}
+
+// product parses factors separated by * and /
+func (cp *calcParser) product() (*calcNode, error) {
+	nd, err := cp.unary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op := cp.peek()
+		if op != '*' && op != '/' {
+			return nd, nil
+		}
+		cp.pos++
+		rt, err := cp.unary()
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case op == '/' && !rt.num:
+			return nil, fmt.Errorf("cannot divide by a length")
+		case op == '*' && !nd.num && !rt.num:
+			return nil, fmt.Errorf("cannot multiply two lengths")
+		}
+		nd = &calcNode{op: string(op), num: nd.num && rt.num, args: []*calcNode{nd, rt}}
+	}
+}
+
+// unary parses a factor with an optional sign
+func (cp *calcParser) unary() (*calcNode, error) {
+	switch cp.peek() {
+	case '+':
+		cp.pos++
+		return cp.unary()
+	case '-':
+		cp.pos++
+		nd, err := cp.unary()
+		if err != nil {
+			return nil, err
+		}
+		if nd.op == "" {
+			nd.val = -nd.val
+			return nd, nil
+		}
+		return &calcNode{op: "*", num: nd.num, args: []*calcNode{{val: -1, num: true}, nd}}, nil
+	}
+	return cp.primary()
+}
+
+// primary parses a value, a parenthesized sum or a math function
+func (cp *calcParser) primary() (*calcNode, error) {
+	c := cp.peek()
+	switch {
+	case c == 0:
+		return nil, fmt.Errorf("unexpected end")
+	case c == '(':
+		cp.pos++
+		nd, err := cp.sum()
+		if err != nil {
+			return nil, err
+		}
+		return nd, cp.expect(')')
+	case c == '.' || (c >= '0' && c <= '9'):
+		return cp.value()
+	case c >= 'a' && c <= 'z':
+		return cp.function()
+	}
+	return nil, fmt.Errorf("unexpected %q", cp.src[cp.pos:])
+}
+
+// value parses a number with optional units
+func (cp *calcParser) value() (*calcNode, error) {
+	st := cp.pos
+	for cp.pos < len(cp.src) {
+		c := cp.src[cp.pos]
+		if (c >= '0' && c <= '9') || c == '.' {
+			cp.pos++
+			continue
+		}
+		if c == 'e' && cp.pos+1 < len(cp.src) && (cp.src[cp.pos+1] == '-' || (cp.src[cp.pos+1] >= '0' && cp.src[cp.pos+1] <= '9')) {
+			cp.pos += 2
+			continue
+		}
+		break
+	}
+	val, err := strconv.ParseFloat(cp.src[st:cp.pos], 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid number %q", cp.src[st:cp.pos])
+	}
+	ust := cp.pos
+	for cp.pos < len(cp.src) && ((cp.src[cp.pos] >= 'a' && cp.src[cp.pos] <= 'z') || cp.src[cp.pos] == '%') {
+		cp.pos++
+	}
+	unm := cp.src[ust:cp.pos]
+	if unm == "" {
+		return &calcNode{val: float32(val), num: true}, nil
+	}
+	if unm == "%" {
+		unm = "pct"
+	}
+	for i, nm := range UnitNames {
+		if nm == unm {
+			return &calcNode{val: float32(val), un: Unit(i)}, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown units %q", unm)
+}
+
+// function parses calc, min, max or clamp with its arguments
+func (cp *calcParser) function() (*calcNode, error) {
+	st := cp.pos
+	for cp.pos < len(cp.src) && cp.src[cp.pos] >= 'a' && cp.src[cp.pos] <= 'z' {
+		cp.pos++
+	}
+	fn := cp.src[st:cp.pos]
+	if cp.pos >= len(cp.src) || cp.src[cp.pos] != '(' {
+		return nil, fmt.Errorf("unexpected %q", fn)
+	}
+	cp.pos++
+	var args []*calcNode
+	for {
+		nd, err := cp.sum()
+		if err != nil {
+			return nil, err
+		}
+		if len(args) > 0 && nd.num != args[0].num {
+			return nil, fmt.Errorf("cannot mix numbers and lengths in %v()", fn)
+		}
+		args = append(args, nd)
+		if cp.peek() != ',' {
+			break
+		}
+		cp.pos++
+	}
+	if err := cp.expect(')'); err != nil {
+		return nil, err
+	}
+	switch {
+	case fn == "calc" && len(args) == 1:
+		return args[0], nil
+	case (fn == "min" || fn == "max") && len(args) >= 1:
+		return &calcNode{op: fn, num: args[0].num, args: args}, nil
+	case fn == "clamp" && len(args) == 3:
+		return &calcNode{op: fn, num: args[0].num, args: args}, nil
+	}
+	return nil, fmt.Errorf("wrong number of arguments to %v()", fn)
+}
@@ -31,3 +31,35 @@ func TestValCvt(t *testing.T) {
 		t.Errorf("strings don't match: %v != %v\n", s1, s2)
 	}
 }
+
+func TestCalc(t *testing.T) {
+	var ctxt Context
+	ctxt.Defaults()
+	tests := []struct {
+		str  string
+		dots float32
+	}{
+		{"calc(100% - 2em)", 800 - 24},
+		{"calc((10px + 1in) * 2)", 2 * (10 + 96)},
+		{"calc(-10px + 50vw / 2)", -10 + 200},
+		{"min(50vw, 12em)", 144},
+		{"max(50vw, 12em)", 400},
+		{"clamp(10px, 2 * 3em, 1in)", 72},
+		{"CALC(1em+1ex)", 18},
+	}
+	for _, tst := range tests {
+		v := StringToValue(tst.str)
+		if v.Calc == nil {
+			t.Errorf("%v: not parsed as calc\n", tst.str)
+			continue
+		}
+		if d := v.ToDots(&ctxt); d != tst.dots {
+			t.Errorf("%v = %v dots, expected %v\n", tst.str, d, tst.dots)
+		}
+	}
+	for _, str := range []string{"calc(1px * 2px)", "calc(1px + 2)", "calc(2 / 1px)", "calc(1px", "calc(2)", "clamp(1px, 2px)", "calc(1foo)"} {
+		if _, err := ParseCalc(str); err == nil {
+			t.Errorf("%v: expected error\n", str)
+		}
+	}
+}